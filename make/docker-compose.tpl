@@ -188,6 +188,7 @@ services:
       - SETUID
     volumes:
       - /data/job_logs:/var/log/jobs:z
+      - /data/secretkey:/etc/jobservice/key:z
       - ./common/config/jobservice/config.yml:/etc/jobservice/config.yml:z
     networks:
       - harbor
@@ -0,0 +1,13 @@
+/*
+The job logs moved out of the job_log table by the log sweeper
+*/
+create table job_log_archive (
+ log_id int NOT NULL,
+ job_uuid varchar (64) NOT NULL,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ content text,
+ primary key (log_id)
+);
+
+CREATE UNIQUE INDEX job_log_archive_uuid ON job_log_archive (job_uuid);
+CREATE INDEX job_log_archive_creation_time ON job_log_archive (creation_time);
@@ -0,0 +1,33 @@
+/*
+The tag retention policies of the projects, each project has one policy at most
+*/
+CREATE TABLE retention_policy (
+ id SERIAL PRIMARY KEY NOT NULL,
+ project_id int NOT NULL,
+ rules text NOT NULL,
+ cron_str varchar(256),
+ job_uuid varchar(64),
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ CONSTRAINT unique_retention_policy_project UNIQUE (project_id)
+);
+
+CREATE TRIGGER retention_policy_update_time_at_modtime BEFORE UPDATE ON retention_policy FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+CREATE TABLE retention_execution (
+ id SERIAL PRIMARY KEY NOT NULL,
+ policy_id int NOT NULL,
+ project_id int NOT NULL,
+ dry_run boolean DEFAULT false NOT NULL,
+ trigger_type varchar(64) NOT NULL,
+ status varchar(64) NOT NULL,
+ job_uuid varchar(64),
+ report text,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER retention_execution_update_time_at_modtime BEFORE UPDATE ON retention_execution FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+CREATE INDEX retention_execution_policy ON retention_execution (policy_id);
+CREATE INDEX retention_execution_uuid ON retention_execution (job_uuid);
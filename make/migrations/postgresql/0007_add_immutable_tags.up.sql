@@ -0,0 +1,4 @@
+/*
+The immutable tag rules are stored in project metadata as json, which may exceed 255 characters
+*/
+ALTER TABLE project_metadata ALTER COLUMN value TYPE text;
@@ -0,0 +1,36 @@
+/*
+The hard limits of the projects, the system default quotas are applied to the projects not in this table.
+-1 means unlimited
+*/
+CREATE TABLE project_quota (
+ id SERIAL PRIMARY KEY NOT NULL,
+ project_id int NOT NULL,
+ storage_limit bigint NOT NULL DEFAULT -1,
+ count_limit bigint NOT NULL DEFAULT -1,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ CONSTRAINT unique_project_quota_project UNIQUE (project_id)
+);
+
+CREATE TRIGGER project_quota_update_time_at_modtime BEFORE UPDATE ON project_quota FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+/*
+The tags pushed to the projects, used to account the quota usage.
+The size is the sum of the manifest, config and layers of the image.
+The tags pushed before are backfilled by core when it starts.
+*/
+CREATE TABLE artifact (
+ id SERIAL PRIMARY KEY NOT NULL,
+ project_id int NOT NULL,
+ repo varchar(256) NOT NULL,
+ tag varchar(255) NOT NULL,
+ digest varchar(255) NOT NULL,
+ size bigint NOT NULL DEFAULT 0,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ CONSTRAINT unique_artifact UNIQUE (repo, tag)
+);
+
+CREATE TRIGGER artifact_update_time_at_modtime BEFORE UPDATE ON artifact FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+CREATE INDEX artifact_project_digest ON artifact (project_id, digest);
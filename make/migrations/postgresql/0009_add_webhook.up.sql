@@ -0,0 +1,40 @@
+/*
+The webhook policies of the projects, the events matching the event types of the policy
+are posted to the target address, the auth header and secret are encrypted with the secret key
+*/
+CREATE TABLE webhook_policy (
+ id SERIAL PRIMARY KEY NOT NULL,
+ project_id int NOT NULL,
+ name varchar(256) NOT NULL,
+ description text,
+ address varchar(512) NOT NULL,
+ auth_header text,
+ secret text,
+ skip_cert_verify boolean DEFAULT false NOT NULL,
+ event_types text NOT NULL,
+ enabled boolean DEFAULT true NOT NULL,
+ creator varchar(256),
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ CONSTRAINT unique_webhook_policy_name UNIQUE (project_id, name)
+);
+
+CREATE TRIGGER webhook_policy_update_time_at_modtime BEFORE UPDATE ON webhook_policy FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+/*
+The delivery history of the webhook policies
+*/
+CREATE TABLE webhook_execution (
+ id SERIAL PRIMARY KEY NOT NULL,
+ policy_id int NOT NULL,
+ event_type varchar(64) NOT NULL,
+ status varchar(64) NOT NULL,
+ job_uuid varchar(64),
+ payload text,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER webhook_execution_update_time_at_modtime BEFORE UPDATE ON webhook_execution FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+CREATE INDEX webhook_execution_policy ON webhook_execution (policy_id);
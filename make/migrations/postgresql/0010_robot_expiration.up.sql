@@ -0,0 +1,11 @@
+/*
+The token of a robot is stored as a salted hash, the plain secret is only returned once when it's generated.
+The expires_at is a unix timestamp in seconds, -1 means the token never expires so that the existing
+robots keep working. The access is the JSON encoded list of the resources and actions granted to the
+robot, the system level robots whose access can span multiple projects have no project_id.
+*/
+ALTER TABLE robot ADD COLUMN salt varchar(64);
+ALTER TABLE robot ADD COLUMN expires_at bigint DEFAULT -1 NOT NULL;
+ALTER TABLE robot ADD COLUMN access text;
+ALTER TABLE robot ALTER COLUMN project_id SET DEFAULT 0;
+UPDATE robot SET project_id = 0 WHERE project_id IS NULL;
@@ -0,0 +1,21 @@
+/*
+The users onboarded from the OIDC provider, the subiss is the combination of the subject and the issuer
+of the ID token which identifies the user in the provider. The secret is the salted hash of the CLI secret
+used by docker CLI to login, and the groups are the group names in the latest ID token of the user.
+*/
+CREATE TABLE oidc_user (
+ id SERIAL NOT NULL,
+ user_id int NOT NULL,
+ subiss varchar(255) NOT NULL,
+ secret varchar(255),
+ salt varchar(64),
+ groups text,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ FOREIGN KEY (user_id) REFERENCES harbor_user(user_id),
+ UNIQUE (subiss),
+ UNIQUE (user_id)
+);
+
+CREATE TRIGGER oidc_user_update_time_at_modtime BEFORE UPDATE ON oidc_user FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
@@ -0,0 +1,33 @@
+/*
+The resource of the non-registry operations recorded in the access_log, e.g. the member changes, the policy
+edits, the configuration changes. The resource of the existing logs is populated from the repository and tag.
+*/
+ALTER TABLE access_log ADD COLUMN resource_type varchar(64);
+ALTER TABLE access_log ADD COLUMN resource varchar(1024);
+
+UPDATE access_log SET resource_type = 'project', resource = rtrim(repo_name, '/')
+ WHERE repo_tag = 'N/A' AND repo_name LIKE '%/';
+UPDATE access_log SET resource_type = 'repository',
+ resource = CASE WHEN repo_tag IS NULL OR repo_tag = '' OR repo_tag = 'N/A' THEN repo_name ELSE repo_name || ':' || repo_tag END
+ WHERE resource_type IS NULL;
+
+CREATE INDEX access_log_op_time ON access_log (op_time);
+
+/*
+The access logs moved out of the access_log table by the audit log purge job
+*/
+CREATE TABLE access_log_archive (
+ log_id int NOT NULL,
+ username varchar (255) NOT NULL,
+ project_id int NOT NULL,
+ repo_name varchar (256),
+ repo_tag varchar (128),
+ guid varchar(64),
+ operation varchar(20) NOT NULL,
+ op_time timestamp,
+ resource_type varchar(64),
+ resource varchar(1024),
+ primary key (log_id)
+);
+
+CREATE INDEX access_log_archive_op_time ON access_log_archive (op_time);
@@ -0,0 +1,14 @@
+/*
+The vulnerabilities ignored when checking the severity of the images against the threshold of the project,
+the project_id of the system level allowlist is 0. The expires_at is the unix timestamp after which the
+item is not effective, 0 means the item never expires.
+*/
+CREATE TABLE cve_allowlist (
+ id SERIAL NOT NULL,
+ project_id int NOT NULL,
+ cve_id varchar(255) NOT NULL,
+ expires_at bigint NOT NULL DEFAULT 0,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ UNIQUE (project_id, cve_id)
+);
@@ -0,0 +1,32 @@
+/*
+The registrations of the vulnerability scanners, the images are scanned by the scanner picked by the project
+or the default one. The external scanners are called with the scanner adapter protocol via the url, the
+auth_header is sent as the "Authorization" header. The built-in Clair scanner is registered with the url
+"builtin://clair" and is the default scanner.
+*/
+CREATE TABLE scanner_registration (
+ id SERIAL NOT NULL,
+ name varchar(255) NOT NULL,
+ description text,
+ url varchar(256) NOT NULL,
+ auth_header varchar(1024),
+ skip_cert_verify boolean NOT NULL DEFAULT false,
+ disabled boolean NOT NULL DEFAULT false,
+ is_default boolean NOT NULL DEFAULT false,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ UNIQUE (name),
+ UNIQUE (url)
+);
+
+CREATE TRIGGER scanner_registration_update_time_at_modtime BEFORE UPDATE ON scanner_registration FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+INSERT INTO scanner_registration (name, description, url, is_default)
+ VALUES ('Clair', 'The built-in Clair scanner', 'builtin://clair', true);
+
+/*
+The scanner which generated the report, the details_key is the ID of the report in the scanner
+*/
+ALTER TABLE img_scan_overview ADD COLUMN scanner_id int NOT NULL DEFAULT 0;
+UPDATE img_scan_overview SET scanner_id = (SELECT id FROM scanner_registration WHERE url = 'builtin://clair');
@@ -0,0 +1,18 @@
+/*
+The tags cached by the proxy-cache projects, which pull the images from the replication target set in the
+project metadata "proxy_cache_target" on a pull miss. The digest is the one resolved from the upstream
+registry when the tag was cached or revalidated at the update_time, the tag is revalidated against the
+upstream registry after the TTL set in the project metadata "proxy_cache_ttl" has elapsed.
+*/
+CREATE TABLE proxy_cache_tag (
+ id SERIAL NOT NULL,
+ project_id int NOT NULL,
+ repository varchar(256) NOT NULL,
+ tag varchar(128) NOT NULL,
+ digest varchar(128) NOT NULL,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ FOREIGN KEY (project_id) REFERENCES project(project_id),
+ UNIQUE (repository, tag)
+);
@@ -0,0 +1,17 @@
+/*
+The custom project roles, the built-in roles projectAdmin, developer and guest keep the IDs 1, 2 and 3,
+the policies of the custom roles are the JSON array of the actions allowed on the resources of the project,
+e.g. [{"resource":"repository-tag","action":"delete"}], the resource "" is the project itself.
+*/
+ALTER TABLE role ALTER COLUMN name TYPE varchar(255);
+ALTER TABLE role ADD COLUMN description text;
+ALTER TABLE role ADD COLUMN policies text;
+ALTER TABLE role ADD COLUMN creation_time timestamp default CURRENT_TIMESTAMP;
+ALTER TABLE role ADD COLUMN update_time timestamp default CURRENT_TIMESTAMP;
+ALTER TABLE role ADD CONSTRAINT unique_role_name UNIQUE (name);
+
+CREATE TRIGGER role_update_time_at_modtime BEFORE UPDATE ON role FOR EACH ROW EXECUTE PROCEDURE update_update_time_at_column();
+
+INSERT INTO role (name, description, policies) VALUES
+('maintainer', 'Push images and charts, delete tags and scan images, without the management of the project', '[{"resource":"","action":"read"},{"resource":"image","action":"pull"},{"resource":"member","action":"read"},{"resource":"metadata","action":"read"},{"resource":"log","action":"read"},{"resource":"label","action":"read"},{"resource":"label-resource","action":"read"},{"resource":"repository","action":"read"},{"resource":"repository-tag","action":"read"},{"resource":"helm-chart","action":"read"},{"resource":"helm-repo","action":"read"},{"resource":"robot","action":"read"},{"resource":"scan","action":"read"},{"resource":"scanner","action":"read"},{"resource":"cve-allowlist","action":"read"},{"resource":"retention","action":"read"},{"resource":"image","action":"push"},{"resource":"label-resource","action":"create"},{"resource":"label-resource","action":"delete"},{"resource":"repository","action":"update"},{"resource":"repository-tag","action":"create"},{"resource":"repository-tag","action":"delete"},{"resource":"helm-chart","action":"create"},{"resource":"helm-chart","action":"delete"},{"resource":"scan","action":"create"}]'),
+('scanner', 'Pull and scan images', '[{"resource":"","action":"read"},{"resource":"image","action":"pull"},{"resource":"repository","action":"read"},{"resource":"repository-tag","action":"read"},{"resource":"scan","action":"read"},{"resource":"scan","action":"create"}]');
@@ -0,0 +1,35 @@
+/*
+The time when the password of the user is changed, the password expires after the max age configured
+in the password policy. The existing users start counting from the upgrade.
+*/
+ALTER TABLE harbor_user ADD COLUMN password_changed_time timestamp default CURRENT_TIMESTAMP;
+
+/*
+The previous passwords of the users stored as salted hashes, a new password can't reuse the current one
+and the ones in the history within the count configured in the password policy.
+*/
+CREATE TABLE password_history (
+ id SERIAL NOT NULL,
+ user_id int NOT NULL,
+ password varchar(40) NOT NULL,
+ salt varchar(40),
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ FOREIGN KEY (user_id) REFERENCES harbor_user(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX password_history_user_id ON password_history (user_id);
+
+/*
+The consecutive login failures of the database users, the user is locked once the failures reach the max
+configured in the password policy, until the lockout duration since the last failure elapses or the
+system admin unlocks the user. The record is removed on a successful login.
+*/
+CREATE TABLE user_lockout (
+ user_id int NOT NULL,
+ failures int NOT NULL DEFAULT 0,
+ locked boolean NOT NULL DEFAULT false,
+ last_failure_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (user_id),
+ FOREIGN KEY (user_id) REFERENCES harbor_user(user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+/*
+The TOTP second factor of the users, the secret is encrypted with the secret key of Harbor and the record
+is enabled after the user verifies the first code. The codes of the last used time step and the ones before
+it are rejected to prevent the replay. The recovery codes are stored as the JSON encoded salted hashes and
+each of them can be used once. The CLI secret replaces the password of the users with the second factor
+enabled for the docker CLI and the other basic auth clients, which can't prompt for the code.
+*/
+CREATE TABLE user_totp (
+ user_id int NOT NULL,
+ secret varchar(255) NOT NULL,
+ enabled boolean NOT NULL DEFAULT false,
+ last_used_step bigint NOT NULL DEFAULT 0,
+ recovery_codes text,
+ salt varchar(64),
+ cli_secret varchar(64),
+ cli_salt varchar(64),
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (user_id),
+ FOREIGN KEY (user_id) REFERENCES harbor_user(user_id) ON DELETE CASCADE
+);
@@ -0,0 +1,21 @@
+/*
+The personal access tokens of the users, which can be used as the password for the API and docker CLI. The
+token is stored as the salted hash, and the access is the JSON encoded scope in the same format as the one of
+the robot accounts, the token has all the permissions of the user if it's empty. The last used time is updated
+at most once a minute.
+*/
+CREATE TABLE personal_access_token (
+ id SERIAL NOT NULL,
+ user_id int NOT NULL,
+ name varchar(255) NOT NULL,
+ token varchar(64) NOT NULL,
+ salt varchar(64) NOT NULL,
+ access text,
+ expires_at bigint NOT NULL,
+ last_used_time timestamp,
+ creation_time timestamp default CURRENT_TIMESTAMP,
+ update_time timestamp default CURRENT_TIMESTAMP,
+ PRIMARY KEY (id),
+ FOREIGN KEY (user_id) REFERENCES harbor_user(user_id) ON DELETE CASCADE,
+ CONSTRAINT unique_personal_access_token UNIQUE (user_id, name)
+);
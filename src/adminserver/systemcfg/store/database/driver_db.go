@@ -40,17 +40,31 @@ var (
 		common.CfgExpiration:        true,
 		common.ClairDBPort:          true,
 		common.PostGreSQLPort:       true,
+		common.StoragePerProject:    true,
+		common.CountPerProject:      true,
+		common.RobotTokenDuration:   true,
+		common.PasswordMinLength:    true,
+		common.PasswordHistoryCount: true,
+		common.PasswordMaxAge:       true,
+		common.LoginMaxFailures:     true,
+		common.LoginLockoutDuration: true,
 	}
 	boolKeys = map[string]bool{
-		common.WithClair:        true,
-		common.WithNotary:       true,
-		common.SelfRegistration: true,
-		common.EmailSSL:         true,
-		common.EmailInsecure:    true,
-		common.LDAPVerifyCert:   true,
-		common.UAAVerifyCert:    true,
-		common.ReadOnly:         true,
-		common.WithChartMuseum:  true,
+		common.WithClair:                true,
+		common.WithNotary:               true,
+		common.SelfRegistration:         true,
+		common.EmailSSL:                 true,
+		common.EmailInsecure:            true,
+		common.LDAPVerifyCert:           true,
+		common.UAAVerifyCert:            true,
+		common.OIDCVerifyCert:           true,
+		common.ReadOnly:                 true,
+		common.WithChartMuseum:          true,
+		common.PasswordRequireUppercase: true,
+		common.PasswordRequireLowercase: true,
+		common.PasswordRequireNumber:    true,
+		common.PasswordRequireSpecial:   true,
+		common.SysadminTOTPRequired:     true,
 	}
 	mapKeys = map[string]bool{
 		common.ScanAllPolicy: true,
@@ -53,6 +53,7 @@ var (
 		common.AdminInitialPassword,
 		common.ClairDBPassword,
 		common.UAAClientSecret,
+		common.OIDCClientSecret,
 	}
 
 	// all configurations need read from environment variables
@@ -128,6 +129,58 @@ var (
 			env:   "MAX_JOB_WORKERS",
 			parse: parseStringToInt,
 		},
+		common.StoragePerProject: &parser{
+			env:   "STORAGE_PER_PROJECT",
+			parse: parseStringToQuota,
+		},
+		common.CountPerProject: &parser{
+			env:   "COUNT_PER_PROJECT",
+			parse: parseStringToQuota,
+		},
+		common.RobotTokenDuration: &parser{
+			env:   "ROBOT_TOKEN_DURATION",
+			parse: parseStringToInt,
+		},
+		common.PasswordMinLength: &parser{
+			env:   "PASSWORD_MIN_LENGTH",
+			parse: parseStringToInt,
+		},
+		common.PasswordRequireUppercase: &parser{
+			env:   "PASSWORD_REQUIRE_UPPERCASE",
+			parse: parseStringToBool,
+		},
+		common.PasswordRequireLowercase: &parser{
+			env:   "PASSWORD_REQUIRE_LOWERCASE",
+			parse: parseStringToBool,
+		},
+		common.PasswordRequireNumber: &parser{
+			env:   "PASSWORD_REQUIRE_NUMBER",
+			parse: parseStringToBool,
+		},
+		common.PasswordRequireSpecial: &parser{
+			env:   "PASSWORD_REQUIRE_SPECIAL",
+			parse: parseStringToBool,
+		},
+		common.PasswordHistoryCount: &parser{
+			env:   "PASSWORD_HISTORY_COUNT",
+			parse: parseStringToInt,
+		},
+		common.PasswordMaxAge: &parser{
+			env:   "PASSWORD_MAX_AGE",
+			parse: parseStringToInt,
+		},
+		common.LoginMaxFailures: &parser{
+			env:   "LOGIN_MAX_FAILURES",
+			parse: parseStringToInt,
+		},
+		common.LoginLockoutDuration: &parser{
+			env:   "LOGIN_LOCKOUT_DURATION",
+			parse: parseStringToInt,
+		},
+		common.SysadminTOTPRequired: &parser{
+			env:   "SYSADMIN_TOTP_REQUIRED",
+			parse: parseStringToBool,
+		},
 		common.ProjectCreationRestriction: "PROJECT_CREATION_RESTRICTION",
 		common.AdminInitialPassword:       "HARBOR_ADMIN_PASSWORD",
 		common.AdmiralEndpoint:            "ADMIRAL_URL",
@@ -155,6 +208,17 @@ var (
 			env:   "UAA_VERIFY_CERT",
 			parse: parseStringToBool,
 		},
+		common.OIDCName:         "OIDC_NAME",
+		common.OIDCEndpoint:     "OIDC_ENDPOINT",
+		common.OIDCClientID:     "OIDC_CLIENT_ID",
+		common.OIDCClientSecret: "OIDC_CLIENT_SECRET",
+		common.OIDCScope:        "OIDC_SCOPE",
+		common.OIDCVerifyCert: &parser{
+			env:   "OIDC_VERIFY_CERT",
+			parse: parseStringToBool,
+		},
+		common.OIDCGroupsClaim:             "OIDC_GROUPS_CLAIM",
+		common.OIDCAdminGroup:              "OIDC_ADMIN_GROUP",
 		common.CoreURL:                     "CORE_URL",
 		common.JobServiceURL:               "JOBSERVICE_URL",
 		common.TokenServiceURL:             "TOKEN_SERVICE_URL",
@@ -250,6 +314,14 @@ func parseStringToInt(str string) (interface{}, error) {
 	return strconv.Atoi(str)
 }
 
+// the quota is unlimited if it's not set
+func parseStringToQuota(str string) (interface{}, error) {
+	if len(str) == 0 {
+		return common.QuotaUnlimited, nil
+	}
+	return strconv.Atoi(str)
+}
+
 func parseStringToBool(str string) (interface{}, error) {
 	return strings.ToLower(str) == "true" ||
 		strings.ToLower(str) == "on", nil
@@ -44,6 +44,7 @@ const (
 	LdapGroupGroup = "ldapgroup"
 	EmailGroup     = "email"
 	UAAGroup       = "uaa"
+	OIDCGroup      = "oidc"
 	DatabaseGroup  = "database"
 	// Put all config items do not belong a existing group into basic
 	BasicGroup = "basic"
@@ -71,6 +72,7 @@ var (
 		{Name: "clair_db_sslmode", Scope: SystemScope, Group: ClairGroup, EnvKey: "CLAIR_DB_SSLMODE", DefaultValue: "disable", ItemType: &StringType{}, Editable: false},
 		{Name: "clair_db_username", Scope: SystemScope, Group: ClairGroup, EnvKey: "CLAIR_DB_USERNAME", DefaultValue: "postgres", ItemType: &StringType{}, Editable: false},
 		{Name: "clair_url", Scope: SystemScope, Group: ClairGroup, EnvKey: "CLAIR_URL", DefaultValue: "http://clair:6060", ItemType: &StringType{}, Editable: false},
+		{Name: "count_per_project", Scope: UserScope, Group: BasicGroup, EnvKey: "COUNT_PER_PROJECT", DefaultValue: "-1", ItemType: &Int64Type{}, Editable: false},
 
 		{Name: "core_url", Scope: SystemScope, Group: BasicGroup, EnvKey: "CORE_URL", DefaultValue: "http://core:8080", ItemType: &StringType{}, Editable: false},
 		{Name: "database_type", Scope: SystemScope, Group: BasicGroup, EnvKey: "DATABASE_TYPE", DefaultValue: "postgresql", ItemType: &StringType{}, Editable: false},
@@ -102,9 +104,29 @@ var (
 		{Name: "ldap_url", Scope: UserScope, Group: LdapBasicGroup, EnvKey: "LDAP_URL", DefaultValue: "", ItemType: &StringType{}, Editable: true},
 		{Name: "ldap_verify_cert", Scope: UserScope, Group: LdapBasicGroup, EnvKey: "LDAP_VERIFY_CERT", DefaultValue: "true", ItemType: &BoolType{}, Editable: false},
 
+		{Name: "login_lockout_duration", Scope: UserScope, Group: BasicGroup, EnvKey: "LOGIN_LOCKOUT_DURATION", DefaultValue: "30", ItemType: &IntType{}, Editable: false},
+		{Name: "login_max_failures", Scope: UserScope, Group: BasicGroup, EnvKey: "LOGIN_MAX_FAILURES", DefaultValue: "0", ItemType: &IntType{}, Editable: false},
+
 		{Name: "max_job_workers", Scope: SystemScope, Group: BasicGroup, EnvKey: "MAX_JOB_WORKERS", DefaultValue: "10", ItemType: &IntType{}, Editable: false},
 		{Name: "notary_url", Scope: SystemScope, Group: BasicGroup, EnvKey: "NOTARY_URL", DefaultValue: "http://notary-server:4443", ItemType: &StringType{}, Editable: false},
 
+		{Name: "oidc_admin_group", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_ADMIN_GROUP", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: "oidc_client_id", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_CLIENT_ID", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: "oidc_client_secret", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_CLIENT_SECRET", DefaultValue: "", ItemType: &PasswordType{}, Editable: false},
+		{Name: "oidc_endpoint", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_ENDPOINT", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: "oidc_groups_claim", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_GROUPS_CLAIM", DefaultValue: "groups", ItemType: &StringType{}, Editable: false},
+		{Name: "oidc_name", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_NAME", DefaultValue: "", ItemType: &StringType{}, Editable: false},
+		{Name: "oidc_scope", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_SCOPE", DefaultValue: "openid,profile,email", ItemType: &StringType{}, Editable: false},
+		{Name: "oidc_verify_cert", Scope: UserScope, Group: OIDCGroup, EnvKey: "OIDC_VERIFY_CERT", DefaultValue: "true", ItemType: &BoolType{}, Editable: false},
+
+		{Name: "password_history_count", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_HISTORY_COUNT", DefaultValue: "0", ItemType: &IntType{}, Editable: false},
+		{Name: "password_max_age", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_MAX_AGE", DefaultValue: "0", ItemType: &IntType{}, Editable: false},
+		{Name: "password_min_length", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_MIN_LENGTH", DefaultValue: "8", ItemType: &IntType{}, Editable: false},
+		{Name: "password_require_lowercase", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_REQUIRE_LOWERCASE", DefaultValue: "true", ItemType: &BoolType{}, Editable: false},
+		{Name: "password_require_number", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_REQUIRE_NUMBER", DefaultValue: "true", ItemType: &BoolType{}, Editable: false},
+		{Name: "password_require_special", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_REQUIRE_SPECIAL", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		{Name: "password_require_uppercase", Scope: UserScope, Group: BasicGroup, EnvKey: "PASSWORD_REQUIRE_UPPERCASE", DefaultValue: "true", ItemType: &BoolType{}, Editable: false},
+
 		{Name: "postgresql_database", Scope: SystemScope, Group: DatabaseGroup, EnvKey: "POSTGRESQL_DATABASE", DefaultValue: "registry", ItemType: &StringType{}, Editable: false},
 		{Name: "postgresql_host", Scope: SystemScope, Group: DatabaseGroup, EnvKey: "POSTGRESQL_HOST", DefaultValue: "postgresql", ItemType: &StringType{}, Editable: false},
 		{Name: "postgresql_password", Scope: SystemScope, Group: DatabaseGroup, EnvKey: "POSTGRESQL_PASSWORD", DefaultValue: "root123", ItemType: &PasswordType{}, Editable: false},
@@ -119,6 +141,9 @@ var (
 		{Name: "registry_url", Scope: SystemScope, Group: BasicGroup, EnvKey: "REGISTRY_URL", DefaultValue: "http://registry:5000", ItemType: &StringType{}, Editable: false},
 		{Name: "registry_controller_url", Scope: SystemScope, Group: BasicGroup, EnvKey: "REGISTRY_CONTROLLER_URL", DefaultValue: "http://registryctl:8080", ItemType: &StringType{}, Editable: false},
 		{Name: "self_registration", Scope: UserScope, Group: BasicGroup, EnvKey: "SELF_REGISTRATION", DefaultValue: "true", ItemType: &BoolType{}, Editable: false},
+		{Name: "storage_per_project", Scope: UserScope, Group: BasicGroup, EnvKey: "STORAGE_PER_PROJECT", DefaultValue: "-1", ItemType: &Int64Type{}, Editable: false},
+		{Name: "sysadmin_totp_required", Scope: UserScope, Group: BasicGroup, EnvKey: "SYSADMIN_TOTP_REQUIRED", DefaultValue: "false", ItemType: &BoolType{}, Editable: false},
+		{Name: "robot_token_duration", Scope: UserScope, Group: BasicGroup, EnvKey: "ROBOT_TOKEN_DURATION", DefaultValue: "30", ItemType: &IntType{}, Editable: false},
 		{Name: "token_expiration", Scope: UserScope, Group: BasicGroup, EnvKey: "TOKEN_EXPIRATION", DefaultValue: "30", ItemType: &IntType{}, Editable: false},
 		{Name: "token_service_url", Scope: SystemScope, Group: BasicGroup, EnvKey: "TOKEN_SERVICE_URL", DefaultValue: "", ItemType: &StringType{}, Editable: false},
 
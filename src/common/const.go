@@ -19,6 +19,7 @@ const (
 	DBAuth              = "db_auth"
 	LDAPAuth            = "ldap_auth"
 	UAAAuth             = "uaa_auth"
+	OIDCAuth            = "oidc_auth"
 	ProCrtRestrEveryone = "everyone"
 	ProCrtRestrAdmOnly  = "adminonly"
 	LDAPScopeBase       = 0
@@ -94,6 +95,14 @@ const (
 	UAAClientID                       = "uaa_client_id"
 	UAAClientSecret                   = "uaa_client_secret"
 	UAAVerifyCert                     = "uaa_verify_cert"
+	OIDCName                          = "oidc_name"
+	OIDCEndpoint                      = "oidc_endpoint"
+	OIDCClientID                      = "oidc_client_id"
+	OIDCClientSecret                  = "oidc_client_secret"
+	OIDCScope                         = "oidc_scope"
+	OIDCVerifyCert                    = "oidc_verify_cert"
+	OIDCGroupsClaim                   = "oidc_groups_claim"
+	OIDCAdminGroup                    = "oidc_admin_group"
 	DefaultClairEndpoint              = "http://clair:6060"
 	CfgDriverDB                       = "db"
 	CfgDriverJSON                     = "json"
@@ -109,6 +118,7 @@ const (
 	DefaultCoreEndpoint               = "http://core:8080"
 	DefaultNotaryEndpoint             = "http://notary-server:4443"
 	LdapGroupType                     = 1
+	OIDCGroupType                     = 3
 	ReloadKey                         = "reload_key"
 	LdapGroupAdminDn                  = "ldap_group_admin_dn"
 	DefaultRegistryControllerEndpoint = "http://registryctl:8080"
@@ -118,6 +128,24 @@ const (
 	DefaultPortalURL                  = "http://portal"
 	DefaultRegistryCtlURL             = "http://registryctl:8080"
 	DefaultClairHealthCheckServerURL  = "http://clair:6061"
+	StoragePerProject                 = "storage_per_project"
+	CountPerProject                   = "count_per_project"
+	RobotTokenDuration                = "robot_token_duration"
+	PasswordMinLength                 = "password_min_length"
+	PasswordRequireUppercase          = "password_require_uppercase"
+	PasswordRequireLowercase          = "password_require_lowercase"
+	PasswordRequireNumber             = "password_require_number"
+	PasswordRequireSpecial            = "password_require_special"
+	PasswordHistoryCount              = "password_history_count"
+	PasswordMaxAge                    = "password_max_age"
+	LoginMaxFailures                  = "login_max_failures"
+	LoginLockoutDuration              = "login_lockout_duration"
+	SysadminTOTPRequired              = "sysadmin_totp_required"
+	// RobotPrefix is the prefix of the names of the robot accounts, it contains a specific
+	// character($) so it cannot be registered as a harbor user
+	RobotPrefix = "robot$"
+	// QuotaUnlimited means there is no limit of the quota
+	QuotaUnlimited = -1
 )
 
 // Shared variable, not allowed to modify
@@ -156,7 +184,28 @@ var (
 		UAAClientSecret,
 		UAAEndpoint,
 		UAAVerifyCert,
+		OIDCName,
+		OIDCEndpoint,
+		OIDCClientID,
+		OIDCClientSecret,
+		OIDCScope,
+		OIDCVerifyCert,
+		OIDCGroupsClaim,
+		OIDCAdminGroup,
 		ReadOnly,
+		StoragePerProject,
+		CountPerProject,
+		RobotTokenDuration,
+		PasswordMinLength,
+		PasswordRequireUppercase,
+		PasswordRequireLowercase,
+		PasswordRequireNumber,
+		PasswordRequireSpecial,
+		PasswordHistoryCount,
+		PasswordMaxAge,
+		LoginMaxFailures,
+		LoginLockoutDuration,
+		SysadminTOTPRequired,
 	}
 
 	// value is default value
@@ -180,6 +229,12 @@ var (
 		ProjectCreationRestriction: ProCrtRestrEveryone,
 		UAAClientID:                "",
 		UAAEndpoint:                "",
+		OIDCName:                   "",
+		OIDCEndpoint:               "",
+		OIDCClientID:               "",
+		OIDCScope:                  "openid,profile,email",
+		OIDCGroupsClaim:            "groups",
+		OIDCAdminGroup:             "",
 	}
 
 	HarborNumKeysMap = map[string]int{
@@ -188,20 +243,35 @@ var (
 		LDAPTimeout:          5,
 		LDAPGroupSearchScope: 2,
 		TokenExpiration:      30,
+		StoragePerProject:    QuotaUnlimited,
+		CountPerProject:      QuotaUnlimited,
+		RobotTokenDuration:   30,
+		PasswordMinLength:    8,
+		PasswordHistoryCount: 0,
+		PasswordMaxAge:       0,
+		LoginMaxFailures:     0,
+		LoginLockoutDuration: 30,
 	}
 
 	HarborBoolKeysMap = map[string]bool{
-		EmailSSL:         false,
-		EmailInsecure:    false,
-		SelfRegistration: true,
-		LDAPVerifyCert:   true,
-		UAAVerifyCert:    true,
-		ReadOnly:         false,
+		EmailSSL:                 false,
+		EmailInsecure:            false,
+		SelfRegistration:         true,
+		LDAPVerifyCert:           true,
+		UAAVerifyCert:            true,
+		OIDCVerifyCert:           true,
+		ReadOnly:                 false,
+		PasswordRequireUppercase: true,
+		PasswordRequireLowercase: true,
+		PasswordRequireNumber:    true,
+		PasswordRequireSpecial:   false,
+		SysadminTOTPRequired:     false,
 	}
 
 	HarborPasswordKeys = []string{
 		EmailPassword,
 		LDAPSearchPwd,
 		UAAClientSecret,
+		OIDCClientSecret,
 	}
 )
@@ -15,6 +15,10 @@
 package dao
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/astaxie/beego/orm"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/log"
@@ -27,6 +31,17 @@ func AddAccessLog(accessLog models.AccessLog) error {
 	if len(accessLog.Username) > 255 {
 		accessLog.Username = accessLog.Username[:252] + "..."
 	}
+	// the logs of registry operations are recorded with the repository and tag
+	if len(accessLog.ResourceType) == 0 && len(accessLog.RepoName) > 0 {
+		accessLog.ResourceType = models.LogResourceTypeRepository
+		accessLog.Resource = accessLog.RepoName
+		if len(accessLog.RepoTag) > 0 && accessLog.RepoTag != "N/A" {
+			accessLog.Resource = accessLog.RepoName + ":" + accessLog.RepoTag
+		}
+	}
+	if len(accessLog.Resource) > 1024 {
+		accessLog.Resource = accessLog.Resource[:1021] + "..."
+	}
 
 	o := GetOrmer()
 	_, err := o.Insert(&accessLog)
@@ -35,66 +50,168 @@ func AddAccessLog(accessLog models.AccessLog) error {
 
 // GetTotalOfAccessLogs ...
 func GetTotalOfAccessLogs(query *models.LogQueryParam) (int64, error) {
-	return logQueryConditions(query).Count()
+	sql, params := logQueryConditions(query)
+	var total int64
+	if err := GetOrmer().Raw(`select count(1) `+sql, params).QueryRow(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
 }
 
 // GetAccessLogs gets access logs according to different conditions
 func GetAccessLogs(query *models.LogQueryParam) ([]models.AccessLog, error) {
-	qs := logQueryConditions(query).OrderBy("-op_time")
+	sql, params := logQueryConditions(query)
+	sql = `select * ` + sql + ` order by op_time desc, log_id desc `
 
 	if query != nil && query.Pagination != nil {
 		size := query.Pagination.Size
 		if size > 0 {
-			qs = qs.Limit(size)
+			sql += ` limit ? `
+			params = append(params, size)
 
 			page := query.Pagination.Page
 			if page > 0 {
-				qs = qs.Offset((page - 1) * size)
+				sql += ` offset ? `
+				params = append(params, (page-1)*size)
 			}
 		}
 	}
 
 	logs := []models.AccessLog{}
-	_, err := qs.All(&logs)
+	_, err := GetOrmer().Raw(sql, params).QueryRows(&logs)
 	return logs, err
 }
 
-func logQueryConditions(query *models.LogQueryParam) orm.QuerySeter {
-	qs := GetOrmer().QueryTable(&models.AccessLog{})
+// IterateAccessLogs passes the access logs matching the query to the handler in batches, the logs
+// are ordered by ID descending and the pagination of the query is ignored. It stops when
+// the handler returns an error.
+func IterateAccessLogs(query *models.LogQueryParam, batchSize int, handler func([]models.AccessLog) error) error {
+	sql, params := logQueryConditions(query)
+	lastID := 0
+	for {
+		s := `select * ` + sql
+		p := append([]interface{}{}, params...)
+		if lastID > 0 {
+			s += ` and log_id < ? `
+			p = append(p, lastID)
+		}
+		s += ` order by log_id desc limit ? `
+		p = append(p, batchSize)
+
+		logs := []models.AccessLog{}
+		if _, err := GetOrmer().Raw(s, p).QueryRows(&logs); err != nil {
+			return err
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := handler(logs); err != nil {
+			return err
+		}
+		if len(logs) < batchSize {
+			return nil
+		}
+		lastID = logs[len(logs)-1].LogID
+	}
+}
+
+// PurgeAccessLogsBefore deletes the access logs of the operations done before the time,
+// the logs are moved to the archive table if archive is true
+func PurgeAccessLogsBefore(t time.Time, archive bool) (int64, error) {
+	// begin the transaction on a new ormer, the global one is shared by all the callers
+	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return 0, err
+	}
+
+	if archive {
+		sql := `insert into access_log_archive (log_id, username, project_id, repo_name, repo_tag,
+			guid, operation, op_time, resource_type, resource)
+			select log_id, username, project_id, repo_name, repo_tag,
+			guid, operation, op_time, resource_type, resource from access_log where op_time < ?
+			on conflict (log_id) do nothing`
+		if _, err := o.Raw(sql, t).Exec(); err != nil {
+			o.Rollback()
+			return 0, err
+		}
+	}
+
+	res, err := o.Raw(`delete from access_log where op_time < ?`, t).Exec()
+	if err != nil {
+		o.Rollback()
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		o.Rollback()
+		return 0, err
+	}
+
+	if err := o.Commit(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func logQueryConditions(query *models.LogQueryParam) (string, []interface{}) {
+	sql := `from access_log where 1 = 1 `
+	params := []interface{}{}
 
 	if query == nil {
-		return qs
+		return sql, params
 	}
 
 	if len(query.ProjectIDs) > 0 {
-		qs = qs.Filter("project_id__in", query.ProjectIDs)
+		sql += fmt.Sprintf(`and project_id in ( %s ) `, paramPlaceholder(len(query.ProjectIDs)))
+		params = append(params, query.ProjectIDs)
 	}
 	if len(query.Username) != 0 {
-		qs = qs.Filter("username__contains", query.Username)
+		sql += `and username like ? `
+		params = append(params, "%"+Escape(query.Username)+"%")
 	}
 	if len(query.Repository) != 0 {
-		qs = qs.Filter("repo_name__contains", query.Repository)
+		sql += `and repo_name like ? `
+		params = append(params, "%"+Escape(query.Repository)+"%")
 	}
 	if len(query.Tag) != 0 {
-		qs = qs.Filter("repo_tag__contains", query.Tag)
-	}
-	operations := []string{}
-	for _, operation := range query.Operations {
-		if len(operation) > 0 {
-			operations = append(operations, operation)
-		}
+		sql += `and repo_tag like ? `
+		params = append(params, "%"+Escape(query.Tag)+"%")
 	}
+	operations := nonEmpty(query.Operations)
 	if len(operations) > 0 {
-		qs = qs.Filter("operation__in", operations)
+		sql += fmt.Sprintf(`and operation in ( %s ) `, paramPlaceholder(len(operations)))
+		params = append(params, operations)
+	}
+	resourceTypes := nonEmpty(query.ResourceTypes)
+	if len(resourceTypes) > 0 {
+		sql += fmt.Sprintf(`and resource_type in ( %s ) `, paramPlaceholder(len(resourceTypes)))
+		params = append(params, resourceTypes)
+	}
+	if len(query.Resource) != 0 {
+		sql += `and resource like ? `
+		params = append(params, strings.Replace(Escape(query.Resource), "*", "%", -1))
 	}
 	if query.BeginTime != nil {
-		qs = qs.Filter("op_time__gte", query.BeginTime)
+		sql += `and op_time >= ? `
+		params = append(params, query.BeginTime)
 	}
 	if query.EndTime != nil {
-		qs = qs.Filter("op_time__lte", query.EndTime)
+		sql += `and op_time <= ? `
+		params = append(params, query.EndTime)
 	}
 
-	return qs
+	return sql, params
+}
+
+func nonEmpty(values []string) []string {
+	result := []string{}
+	for _, value := range values {
+		if len(value) > 0 {
+			result = append(result, value)
+		}
+	}
+	return result
 }
 
 // CountPull ...
@@ -107,3 +224,25 @@ func CountPull(repoName string) (int64, error) {
 	}
 	return num, nil
 }
+
+// GetLatestAccessTimeOfTags returns the time of the latest operation done to each tag of the repository,
+// the key of the map is the tag name.
+func GetLatestAccessTimeOfTags(repoName, operation string) (map[string]time.Time, error) {
+	type tagTime struct {
+		RepoTag string    `orm:"column(repo_tag)"`
+		OpTime  time.Time `orm:"column(op_time)"`
+	}
+
+	tts := []*tagTime{}
+	_, err := GetOrmer().Raw(`select repo_tag, max(op_time) as op_time from access_log
+		where repo_name = ? and operation = ? group by repo_tag`, repoName, operation).QueryRows(&tts)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make(map[string]time.Time, len(tts))
+	for _, tt := range tts {
+		times[tt.RepoTag] = tt.OpTime
+	}
+	return times, nil
+}
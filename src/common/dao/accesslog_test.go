@@ -0,0 +1,146 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogResource(t *testing.T) {
+	username := "audit_log_resource_test"
+	defer GetOrmer().Raw(`delete from access_log where username = ?`, username).Exec()
+
+	now := time.Now()
+	logs := []models.AccessLog{
+		{Username: username, ProjectID: 1, RepoName: "library/nginx", RepoTag: "latest", Operation: "push", OpTime: now},
+		{Username: username, ProjectID: 1, ResourceType: models.LogResourceTypeMember, Resource: "library/member/alice", Operation: "create", OpTime: now},
+		{Username: username, ProjectID: 0, ResourceType: models.LogResourceTypeConfiguration, Resource: "auth_mode", Operation: "update", OpTime: now},
+	}
+	for _, l := range logs {
+		require.Nil(t, AddAccessLog(l))
+	}
+
+	// the resource of registry operations is populated from the repository and tag
+	result, err := GetAccessLogs(&models.LogQueryParam{
+		Username:   username,
+		Operations: []string{"push"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(result))
+	assert.Equal(t, models.LogResourceTypeRepository, result[0].ResourceType)
+	assert.Equal(t, "library/nginx:latest", result[0].Resource)
+
+	result, err = GetAccessLogs(&models.LogQueryParam{
+		Username: username,
+		Resource: "library/*",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 2, len(result))
+
+	result, err = GetAccessLogs(&models.LogQueryParam{
+		Username: username,
+		Resource: "*alice",
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(result))
+	assert.Equal(t, models.LogResourceTypeMember, result[0].ResourceType)
+
+	total, err := GetTotalOfAccessLogs(&models.LogQueryParam{
+		Username:      username,
+		ResourceTypes: []string{models.LogResourceTypeConfiguration, models.LogResourceTypeMember},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+
+	// "_" is not a wildcard
+	total, err = GetTotalOfAccessLogs(&models.LogQueryParam{
+		Username: username,
+		Resource: "auth_mod_",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestIterateAccessLogs(t *testing.T) {
+	username := "audit_log_iterate_test"
+	defer GetOrmer().Raw(`delete from access_log where username = ?`, username).Exec()
+
+	for i := 0; i < 5; i++ {
+		require.Nil(t, AddAccessLog(models.AccessLog{
+			Username:     username,
+			ResourceType: models.LogResourceTypeConfiguration,
+			Resource:     "read_only",
+			Operation:    "update",
+			OpTime:       time.Now(),
+		}))
+	}
+
+	batches := 0
+	ids := []int{}
+	err := IterateAccessLogs(&models.LogQueryParam{Username: username}, 2, func(logs []models.AccessLog) error {
+		batches++
+		for _, l := range logs {
+			ids = append(ids, l.LogID)
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 3, batches)
+	require.Equal(t, 5, len(ids))
+	for i := 1; i < len(ids); i++ {
+		assert.True(t, ids[i-1] > ids[i])
+	}
+}
+
+func TestPurgeAccessLogsBefore(t *testing.T) {
+	username := "audit_log_purge_test"
+	defer func() {
+		GetOrmer().Raw(`delete from access_log where username = ?`, username).Exec()
+		GetOrmer().Raw(`delete from access_log_archive where username = ?`, username).Exec()
+	}()
+
+	now := time.Now()
+	for _, opTime := range []time.Time{now.AddDate(0, 0, -40), now.AddDate(0, 0, -35), now} {
+		require.Nil(t, AddAccessLog(models.AccessLog{
+			Username:  username,
+			RepoName:  "library/busybox",
+			RepoTag:   "latest",
+			Operation: "pull",
+			OpTime:    opTime,
+		}))
+	}
+
+	_, err := PurgeAccessLogsBefore(now.AddDate(0, 0, -38), false)
+	require.Nil(t, err)
+	total, err := GetTotalOfAccessLogs(&models.LogQueryParam{Username: username})
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+
+	_, err = PurgeAccessLogsBefore(now.AddDate(0, 0, -30), true)
+	require.Nil(t, err)
+	total, err = GetTotalOfAccessLogs(&models.LogQueryParam{Username: username})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+
+	var archived int64
+	require.Nil(t, GetOrmer().Raw(`select count(1) from access_log_archive where username = ?`, username).QueryRow(&archived))
+	assert.Equal(t, int64(1), archived)
+}
@@ -0,0 +1,60 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// GetCVEAllowlist returns the CVE allowlist of the project, the system level allowlist
+// is returned if the project ID is models.SystemCVEAllowlistProjectID
+func GetCVEAllowlist(projectID int64) (*models.CVEAllowlist, error) {
+	items := []*models.CVEAllowlistItem{}
+	_, err := GetOrmer().QueryTable(&models.CVEAllowlistItem{}).
+		Filter("ProjectID", projectID).
+		OrderBy("CVEID").
+		All(&items)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CVEAllowlist{
+		ProjectID: projectID,
+		Items:     items,
+	}, nil
+}
+
+// SetCVEAllowlist replaces the items of the CVE allowlist of the project
+func SetCVEAllowlist(list *models.CVEAllowlist) error {
+	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return err
+	}
+
+	if _, err := o.QueryTable(&models.CVEAllowlistItem{}).
+		Filter("ProjectID", list.ProjectID).Delete(); err != nil {
+		o.Rollback()
+		return err
+	}
+	for _, item := range list.Items {
+		item.ID = 0
+		item.ProjectID = list.ProjectID
+		if _, err := o.Insert(item); err != nil {
+			o.Rollback()
+			return err
+		}
+	}
+	return o.Commit()
+}
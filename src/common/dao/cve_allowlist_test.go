@@ -0,0 +1,65 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVEAllowlist(t *testing.T) {
+	var projectID int64 = 1
+	defer SetCVEAllowlist(&models.CVEAllowlist{ProjectID: projectID})
+
+	list, err := GetCVEAllowlist(projectID)
+	require.Nil(t, err)
+	assert.Equal(t, projectID, list.ProjectID)
+	assert.Equal(t, 0, len(list.Items))
+
+	err = SetCVEAllowlist(&models.CVEAllowlist{
+		ProjectID: projectID,
+		Items: []*models.CVEAllowlistItem{
+			{CVEID: "CVE-2019-0002", ExpiresAt: 1000},
+			{CVEID: "CVE-2019-0001"},
+		},
+	})
+	require.Nil(t, err)
+	list, err = GetCVEAllowlist(projectID)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(list.Items))
+	assert.Equal(t, "CVE-2019-0001", list.Items[0].CVEID)
+	assert.Equal(t, int64(1000), list.Items[1].ExpiresAt)
+
+	// the system level allowlist is not affected by the project
+	system, err := GetCVEAllowlist(models.SystemCVEAllowlistProjectID)
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(system.Items))
+
+	// replace the items
+	err = SetCVEAllowlist(&models.CVEAllowlist{
+		ProjectID: projectID,
+		Items: []*models.CVEAllowlistItem{
+			{CVEID: "CVE-2019-0003"},
+		},
+	})
+	require.Nil(t, err)
+	list, err = GetCVEAllowlist(projectID)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(list.Items))
+	assert.Equal(t, "CVE-2019-0003", list.Items[0].CVEID)
+}
@@ -38,3 +38,58 @@ func DeleteJobLogsBefore(t time.Time) (int64, error) {
 	}
 	return res.RowsAffected()
 }
+
+// ArchiveJobLogsBefore moves the job logs created before the time to the archive table
+func ArchiveJobLogsBefore(t time.Time) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return 0, err
+	}
+
+	sql := `insert into job_log_archive (log_id, job_uuid, creation_time, content)
+		select log_id, job_uuid, creation_time, content from job_log where creation_time < ?
+		on conflict (job_uuid) do update set creation_time = excluded.creation_time, content = excluded.content`
+	if _, err := o.Raw(sql, t).Exec(); err != nil {
+		o.Rollback()
+		return 0, err
+	}
+
+	res, err := o.Raw(`delete from job_log where creation_time < ?`, t).Exec()
+	if err != nil {
+		o.Rollback()
+		return 0, err
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		o.Rollback()
+		return 0, err
+	}
+
+	if err := o.Commit(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetArchivedJobLog ...
+func GetArchivedJobLog(uuid string) (*models.JobLog, error) {
+	o := GetOrmer()
+	jl := models.JobLog{}
+	sql := `select log_id, job_uuid, creation_time, content from job_log_archive where job_uuid = ?`
+	if err := o.Raw(sql, uuid).QueryRow(&jl); err != nil {
+		return nil, err
+	}
+	return &jl, nil
+}
+
+// DeleteArchivedJobLogsBefore ...
+func DeleteArchivedJobLogsBefore(t time.Time) (int64, error) {
+	o := GetOrmer()
+	sql := `delete from job_log_archive where creation_time < ?`
+	res, err := o.Raw(sql, t).Exec()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -42,3 +42,31 @@ func TestMethodsOfJobLog(t *testing.T) {
 	require.Nil(t, err)
 	assert.Equal(t, int64(1), count)
 }
+
+func TestArchiveJobLogs(t *testing.T) {
+	uuid := "uuid_for_unit_test_archive"
+	content := "content for unit test archive"
+	_, err := CreateOrUpdateJobLog(&models.JobLog{
+		UUID:    uuid,
+		Content: content,
+	})
+	require.Nil(t, err)
+
+	// archive
+	count, err := ArchiveJobLogsBefore(time.Now().Add(time.Duration(time.Minute)))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	log, err := GetJobLog(uuid)
+	assert.NotNil(t, err)
+	assert.Nil(t, log)
+
+	log, err = GetArchivedJobLog(uuid)
+	require.Nil(t, err)
+	assert.Equal(t, content, log.Content)
+
+	// delete
+	count, err = DeleteArchivedJobLogsBefore(time.Now().Add(time.Duration(time.Minute)))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
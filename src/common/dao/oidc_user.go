@@ -0,0 +1,104 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// AddOIDCUser ...
+func AddOIDCUser(user *models.OIDCUser) (int64, error) {
+	if err := marshalOIDCGroups(user); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	user.CreationTime = now
+	user.UpdateTime = now
+	id, err := GetOrmer().Insert(user)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return 0, ErrDupRows
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetOIDCUserBySubIss returns the OIDC user identified by the subject and issuer
+func GetOIDCUserBySubIss(subIss string) (*models.OIDCUser, error) {
+	return getOIDCUser("SubIss", subIss)
+}
+
+// GetOIDCUserByUserID returns the OIDC user linked to the Harbor user
+func GetOIDCUserByUserID(userID int) (*models.OIDCUser, error) {
+	return getOIDCUser("UserID", userID)
+}
+
+func getOIDCUser(key string, value interface{}) (*models.OIDCUser, error) {
+	user := &models.OIDCUser{}
+	if err := GetOrmer().QueryTable(&models.OIDCUser{}).Filter(key, value).One(user); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := unmarshalOIDCGroups(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateOIDCUser updates the specified properties of the OIDC user, all the properties are updated if none is specified
+func UpdateOIDCUser(user *models.OIDCUser, props ...string) error {
+	if err := marshalOIDCGroups(user); err != nil {
+		return err
+	}
+	user.UpdateTime = time.Now()
+	if len(props) > 0 {
+		for i, prop := range props {
+			if prop == "Groups" {
+				props[i] = "GroupsDB"
+			}
+		}
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(user, props...)
+	return err
+}
+
+func marshalOIDCGroups(user *models.OIDCUser) error {
+	if user.Groups == nil {
+		user.Groups = []string{}
+	}
+	data, err := json.Marshal(user.Groups)
+	if err != nil {
+		return err
+	}
+	user.GroupsDB = string(data)
+	return nil
+}
+
+func unmarshalOIDCGroups(user *models.OIDCUser) error {
+	user.Groups = []string{}
+	if len(user.GroupsDB) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(user.GroupsDB), &user.Groups)
+}
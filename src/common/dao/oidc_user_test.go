@@ -0,0 +1,77 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCUser(t *testing.T) {
+	user := models.User{
+		Username: "oidc_user_test",
+		Email:    "oidc_user_test@example.com",
+		Password: "Harbor12345",
+		Realname: "oidc_user_test",
+	}
+	userID, err := Register(user)
+	require.Nil(t, err)
+	defer func() {
+		GetOrmer().Raw(`delete from oidc_user where user_id = ?`, userID).Exec()
+		GetOrmer().Raw(`delete from harbor_user where user_id = ?`, userID).Exec()
+	}()
+
+	oidcUser := &models.OIDCUser{
+		UserID: int(userID),
+		SubIss: "subject-1https://oidc.example.com",
+		Groups: []string{"dev", "ops"},
+	}
+	id, err := AddOIDCUser(oidcUser)
+	require.Nil(t, err)
+	assert.True(t, id > 0)
+
+	// duplicate subiss
+	_, err = AddOIDCUser(&models.OIDCUser{
+		UserID: int(userID),
+		SubIss: "subject-1https://oidc.example.com",
+	})
+	assert.Equal(t, ErrDupRows, err)
+
+	u, err := GetOIDCUserBySubIss("subject-1https://oidc.example.com")
+	require.Nil(t, err)
+	require.NotNil(t, u)
+	assert.Equal(t, int(userID), u.UserID)
+	assert.Equal(t, []string{"dev", "ops"}, u.Groups)
+
+	u.Groups = []string{"qa"}
+	u.Secret = "secret"
+	u.Salt = "salt"
+	require.Nil(t, UpdateOIDCUser(u, "Groups", "Secret", "Salt"))
+
+	u, err = GetOIDCUserByUserID(int(userID))
+	require.Nil(t, err)
+	require.NotNil(t, u)
+	assert.Equal(t, []string{"qa"}, u.Groups)
+	assert.Equal(t, "secret", u.Secret)
+	assert.Equal(t, "salt", u.Salt)
+
+	u, err = GetOIDCUserBySubIss("not-exist")
+	require.Nil(t, err)
+	assert.Nil(t, u)
+}
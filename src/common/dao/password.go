@@ -0,0 +1,113 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+)
+
+// IsPasswordReused checks whether the password is the current one of the user or one of the
+// count previous passwords in the history
+func IsPasswordReused(userID int, password string, count int) (bool, error) {
+	user, err := GetUser(models.User{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	if user != nil && user.Password == utils.Encrypt(password, user.Salt) {
+		return true, nil
+	}
+	if count <= 0 {
+		return false, nil
+	}
+	histories := []*models.PasswordHistory{}
+	if _, err := GetOrmer().QueryTable(&models.PasswordHistory{}).
+		Filter("UserID", userID).
+		OrderBy("-ID").
+		Limit(count).
+		All(&histories); err != nil {
+		return false, err
+	}
+	for _, h := range histories {
+		if h.Password == utils.Encrypt(password, h.Salt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addPasswordHistory saves the current password of the users matched by the condition into the history
+func addPasswordHistory(o orm.Ormer, condition string, params ...interface{}) error {
+	_, err := o.Raw(`insert into password_history (user_id, password, salt)
+		select user_id, password, salt from harbor_user where `+condition, params...).Exec()
+	return err
+}
+
+// PrunePasswordHistory removes the previous passwords of the user except the latest count ones
+func PrunePasswordHistory(userID int, count int) error {
+	_, err := GetOrmer().Raw(`delete from password_history where user_id = ? and id not in (
+		select id from password_history where user_id = ? order by id desc limit ?)`,
+		userID, userID, count).Exec()
+	return err
+}
+
+// GetUserLockout returns the login failures of the user whose username or email is the principal
+func GetUserLockout(principal string) (*models.UserLockout, error) {
+	lockout := &models.UserLockout{}
+	err := GetOrmer().Raw(`select l.user_id, l.failures, l.locked, l.last_failure_time
+		from user_lockout l join harbor_user u on l.user_id = u.user_id
+		where (u.username = ? or u.email = ?) and u.deleted = false`,
+		principal, principal).QueryRow(lockout)
+	if err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lockout, nil
+}
+
+// IncreaseLoginFailures records a login failure of the user whose username or email is the principal
+// and locks the user once the consecutive failures reach the max, nil is returned if the user doesn't exist.
+// The failures recorded are discarded if the last one is more than duration minutes ago, which means
+// the lockout has elapsed, 0 keeps them until they're reset.
+func IncreaseLoginFailures(principal string, max, duration int) (*models.UserLockout, error) {
+	lockout := &models.UserLockout{}
+	err := GetOrmer().Raw(`insert into user_lockout (user_id, failures, locked, last_failure_time)
+		select user_id, 1, 1 >= ?, now() from harbor_user
+		where (username = ? or email = ?) and deleted = false
+		on conflict (user_id) do update set
+			failures = case when ? > 0 and user_lockout.last_failure_time <= now() - ? * interval '1 minute'
+				then 1 else user_lockout.failures + 1 end,
+			locked = case when ? > 0 and user_lockout.last_failure_time <= now() - ? * interval '1 minute'
+				then 1 else user_lockout.failures + 1 end >= ?,
+			last_failure_time = now()
+		returning user_id, failures, locked, last_failure_time`,
+		max, principal, principal, duration, duration, duration, duration, max).QueryRow(lockout)
+	if err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lockout, nil
+}
+
+// ResetLoginFailures clears the login failures of the user, which unlocks the user
+func ResetLoginFailures(userID int) error {
+	_, err := GetOrmer().QueryTable(&models.UserLockout{}).Filter("UserID", userID).Delete()
+	return err
+}
@@ -0,0 +1,122 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordHistory(t *testing.T) {
+	id, err := Register(models.User{
+		Username: "user_for_password_history",
+		Email:    "user_for_password_history@example.com",
+		Password: "Passw0rd1",
+	})
+	require.Nil(t, err)
+	defer CleanUser(id)
+	userID := int(id)
+
+	for _, password := range []string{"Passw0rd2", "Passw0rd3", "Passw0rd4"} {
+		require.Nil(t, ChangeUserPassword(models.User{UserID: userID, Password: password}))
+	}
+
+	cases := []struct {
+		password string
+		count    int
+		reused   bool
+	}{
+		{"Passw0rd4", 0, true},
+		{"Passw0rd3", 0, false},
+		{"Passw0rd3", 1, true},
+		{"Passw0rd1", 2, false},
+		{"Passw0rd1", 3, true},
+		{"Passw0rd5", 3, false},
+	}
+	for _, c := range cases {
+		reused, err := IsPasswordReused(userID, c.password, c.count)
+		require.Nil(t, err)
+		assert.Equal(t, c.reused, reused, "%s with %d previous ones", c.password, c.count)
+	}
+
+	require.Nil(t, PrunePasswordHistory(userID, 1))
+	reused, err := IsPasswordReused(userID, "Passw0rd2", 3)
+	require.Nil(t, err)
+	assert.False(t, reused)
+	reused, err = IsPasswordReused(userID, "Passw0rd3", 3)
+	require.Nil(t, err)
+	assert.True(t, reused)
+
+	user, err := GetUser(models.User{UserID: userID})
+	require.Nil(t, err)
+	require.NotNil(t, user)
+	assert.False(t, user.PasswordChangedTime.IsZero())
+}
+
+func TestUserLockout(t *testing.T) {
+	id, err := Register(models.User{
+		Username: "user_for_lockout",
+		Email:    "user_for_lockout@example.com",
+		Password: "Passw0rd1",
+	})
+	require.Nil(t, err)
+	defer CleanUser(id)
+
+	lockout, err := GetUserLockout("user_for_lockout")
+	require.Nil(t, err)
+	assert.Nil(t, lockout)
+
+	// the user doesn't exist
+	lockout, err = IncreaseLoginFailures("non_existing_user_for_lockout", 2, 30)
+	require.Nil(t, err)
+	assert.Nil(t, lockout)
+
+	lockout, err = IncreaseLoginFailures("user_for_lockout", 2, 30)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.Equal(t, 1, lockout.Failures)
+	assert.False(t, lockout.Locked)
+
+	// by email
+	lockout, err = IncreaseLoginFailures("user_for_lockout@example.com", 2, 30)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.Equal(t, 2, lockout.Failures)
+	assert.True(t, lockout.Locked)
+
+	lockout, err = GetUserLockout("user_for_lockout")
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.Equal(t, int(id), lockout.UserID)
+	assert.True(t, lockout.Locked)
+
+	// the failures are discarded once the lockout has elapsed
+	_, err = GetOrmer().Raw(`update user_lockout set last_failure_time = now() - interval '31 minutes'
+		where user_id = ?`, id).Exec()
+	require.Nil(t, err)
+	lockout, err = IncreaseLoginFailures("user_for_lockout", 2, 30)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.Equal(t, 1, lockout.Failures)
+	assert.False(t, lockout.Locked)
+
+	require.Nil(t, ResetLoginFailures(int(id)))
+	lockout, err = GetUserLockout("user_for_lockout")
+	require.Nil(t, err)
+	assert.Nil(t, lockout)
+}
@@ -0,0 +1,128 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+)
+
+// AddPersonalAccessToken ...
+func AddPersonalAccessToken(token *models.PersonalAccessToken) (int64, error) {
+	if err := marshalTokenAccess(token); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	token.CreationTime = now
+	token.UpdateTime = now
+	id, err := GetOrmer().Insert(token)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return 0, ErrDupRows
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetPersonalAccessToken ...
+func GetPersonalAccessToken(id int64) (*models.PersonalAccessToken, error) {
+	token := &models.PersonalAccessToken{
+		ID: id,
+	}
+	if err := GetOrmer().Read(token); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := unmarshalTokenAccess(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// ListPersonalAccessTokens lists the personal access tokens of the user
+func ListPersonalAccessTokens(userID int) ([]*models.PersonalAccessToken, error) {
+	tokens := []*models.PersonalAccessToken{}
+	if _, err := GetOrmer().QueryTable(&models.PersonalAccessToken{}).
+		Filter("UserID", userID).
+		OrderBy("ID").
+		All(&tokens); err != nil {
+		return nil, err
+	}
+	for _, token := range tokens {
+		if err := unmarshalTokenAccess(token); err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+// DeletePersonalAccessToken ...
+func DeletePersonalAccessToken(id int64) error {
+	_, err := GetOrmer().QueryTable(&models.PersonalAccessToken{}).Filter("ID", id).Delete()
+	return err
+}
+
+// LoginByPersonalAccessToken returns the personal access token of the user which matches the token presented
+// in the format "hpat_<ID>_<secret>", nil is returned if it isn't in the format or doesn't match
+func LoginByPersonalAccessToken(userID int, presented string) (*models.PersonalAccessToken, error) {
+	id, secret, ok := models.ParsePersonalAccessToken(presented)
+	if !ok {
+		return nil, nil
+	}
+	token, err := GetPersonalAccessToken(id)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	if token.UserID != userID || token.Token != utils.Encrypt(secret, token.Salt) {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// TouchPersonalAccessToken updates the last used time of the token, which is updated at most once a minute
+// to avoid writing DB for every request
+func TouchPersonalAccessToken(id int64) error {
+	_, err := GetOrmer().Raw(`update personal_access_token set last_used_time = now()
+		where id = ? and (last_used_time is null or last_used_time < now() - interval '1 minute')`, id).Exec()
+	return err
+}
+
+func marshalTokenAccess(token *models.PersonalAccessToken) error {
+	if token.Access == nil {
+		token.Access = []*models.ResourceActions{}
+	}
+	data, err := json.Marshal(token.Access)
+	if err != nil {
+		return err
+	}
+	token.AccessDB = string(data)
+	return nil
+}
+
+func unmarshalTokenAccess(token *models.PersonalAccessToken) error {
+	token.Access = []*models.ResourceActions{}
+	if len(token.AccessDB) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(token.AccessDB), &token.Access)
+}
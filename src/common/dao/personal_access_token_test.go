@@ -0,0 +1,93 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessToken(t *testing.T) {
+	id, err := Register(models.User{
+		Username: "user_for_personal_access_token",
+		Email:    "user_for_personal_access_token@example.com",
+		Password: "Passw0rd1",
+	})
+	require.Nil(t, err)
+	defer CleanUser(id)
+	userID := int(id)
+
+	salt := utils.GenerateRandomString()
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      "ci",
+		Token:     utils.Encrypt("secret", salt),
+		Salt:      salt,
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Access: []*models.ResourceActions{
+			{Name: "/project/1/image", Actions: []string{"pull"}},
+		},
+	}
+	tokenID, err := AddPersonalAccessToken(token)
+	require.Nil(t, err)
+
+	_, err = AddPersonalAccessToken(&models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      "ci",
+		Token:     "token",
+		Salt:      "salt",
+		ExpiresAt: models.PersonalAccessTokenNeverExpire,
+	})
+	assert.Equal(t, ErrDupRows, err)
+
+	tokens, err := ListPersonalAccessTokens(userID)
+	require.Nil(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "ci", tokens[0].Name)
+	assert.True(t, tokens[0].Scoped())
+	assert.True(t, tokens[0].LastUsedTime.IsZero())
+
+	cases := []struct {
+		userID    int
+		presented string
+		matched   bool
+	}{
+		{userID, models.FormatPersonalAccessToken(tokenID, "secret"), true},
+		{userID, models.FormatPersonalAccessToken(tokenID, "wrong"), false},
+		{userID, models.FormatPersonalAccessToken(tokenID+1, "secret"), false},
+		{userID + 1, models.FormatPersonalAccessToken(tokenID, "secret"), false},
+		{userID, "secret", false},
+	}
+	for _, c := range cases {
+		matched, err := LoginByPersonalAccessToken(c.userID, c.presented)
+		require.Nil(t, err)
+		assert.Equal(t, c.matched, matched != nil, c.presented)
+	}
+
+	require.Nil(t, TouchPersonalAccessToken(tokenID))
+	token, err = GetPersonalAccessToken(tokenID)
+	require.Nil(t, err)
+	assert.False(t, token.LastUsedTime.IsZero())
+
+	require.Nil(t, DeletePersonalAccessToken(tokenID))
+	token, err = GetPersonalAccessToken(tokenID)
+	require.Nil(t, err)
+	assert.Nil(t, token)
+}
@@ -298,21 +298,37 @@ func GetRolesByLDAPGroup(projectID int64, groupDNCondition string) ([]int, error
 		return roles, nil
 	}
 	o := GetOrmer()
-	// Because an LDAP user can be memberof multiple groups,
-	// the role is in descent order (1-admin, 2-developer, 3-guest), use min to select the max privilege role.
+	// Because an LDAP user can be memberof multiple groups, all the roles of the groups are returned,
+	// the built-in roles are in descent order (1-admin, 2-developer, 3-guest) and come before the custom ones.
 	sql := fmt.Sprintf(
-		`select min(pm.role) from project_member pm 
+		`select distinct pm.role from project_member pm 
 		left join user_group ug on pm.entity_type = 'g' and pm.entity_id = ug.id 
-		where ug.ldap_group_dn in ( %s ) and pm.project_id = ? `,
+		where ug.ldap_group_dn in ( %s ) and pm.project_id = ? 
+		order by pm.role`,
 		groupDNCondition)
 	log.Debugf("sql:%v", sql)
 	if _, err := o.Raw(sql, projectID).QueryRows(&roles); err != nil {
 		log.Warningf("Error in GetRolesByLDAPGroup, error: %v", err)
 		return nil, err
 	}
-	// If there is no row selected, the min returns an empty row, to avoid return 0 as role
-	if len(roles) == 1 && roles[0] == 0 {
-		return []int{}, nil
+	return roles, nil
+}
+
+// GetRolesByGroupID - Get Project roles of the specified groups in current project,
+// the groups are identified by their IDs, it's used by the group types which don't have a DN
+func GetRolesByGroupID(projectID int64, groupIDs []int) ([]int, error) {
+	var roles []int
+	if len(groupIDs) == 0 {
+		return roles, nil
+	}
+	sql := fmt.Sprintf(
+		`select distinct pm.role from project_member pm 
+		where pm.entity_type = 'g' and pm.entity_id in ( %s ) and pm.project_id = ? 
+		order by pm.role`,
+		paramPlaceholder(len(groupIDs)))
+	if _, err := GetOrmer().Raw(sql, groupIDs, projectID).QueryRows(&roles); err != nil {
+		log.Warningf("Error in GetRolesByGroupID, error: %v", err)
+		return nil, err
 	}
 	return roles, nil
 }
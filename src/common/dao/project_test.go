@@ -236,6 +236,44 @@ func TestGetRolesByLDAPGroup(t *testing.T) {
 	}
 }
 
+func TestGetRolesByGroupID(t *testing.T) {
+	prepareGroupTest()
+	project, err := GetProjectByName("group_project")
+	if err != nil {
+		t.Fatalf("Error occurred when Get project by name: %v", err)
+	}
+	privateProject, err := GetProjectByName("group_project_private")
+	if err != nil {
+		t.Fatalf("Error occurred when Get project by name: %v", err)
+	}
+	var groupID int
+	if err = GetOrmer().Raw(`select id from user_group where group_name = 'harbor_group_01'`).QueryRow(&groupID); err != nil {
+		t.Fatalf("Error occurred when get user group: %v", err)
+	}
+	tests := []struct {
+		name      string
+		projectID int64
+		groupIDs  []int
+		wantSize  int
+	}{
+		{"Check normal", project.ProjectID, []int{groupID}, 1},
+		{"Check non member", privateProject.ProjectID, []int{groupID}, 0},
+		{"Check no group", project.ProjectID, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetRolesByGroupID(tt.projectID, tt.groupIDs)
+			if err != nil {
+				t.Errorf("GetRolesByGroupID() error = %v", err)
+				return
+			}
+			if len(got) != tt.wantSize {
+				t.Errorf("GetRolesByGroupID() = %v, want %v", len(got), tt.wantSize)
+			}
+		})
+	}
+}
+
 func TestProjetExistsByName(t *testing.T) {
 	name := "project_exist_by_name_test"
 	exist := ProjectExistsByName(name)
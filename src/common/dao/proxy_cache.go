@@ -0,0 +1,54 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// GetProxyCacheTag returns the cached tag of the repository, nil if the tag hasn't been cached
+func GetProxyCacheTag(repository, tag string) (*models.ProxyCacheTag, error) {
+	t := &models.ProxyCacheTag{
+		Repository: repository,
+		Tag:        tag,
+	}
+	if err := GetOrmer().Read(t, "Repository", "Tag"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetProxyCacheTag records the digest of the cached tag, the update time of the record is set to now
+func SetProxyCacheTag(t *models.ProxyCacheTag) error {
+	now := time.Now()
+	sql := `insert into proxy_cache_tag (project_id, repository, tag, digest, creation_time, update_time)
+		values (?, ?, ?, ?, ?, ?)
+		on conflict (repository, tag) do update set project_id = excluded.project_id,
+		digest = excluded.digest, update_time = excluded.update_time`
+	_, err := GetOrmer().Raw(sql, t.ProjectID, t.Repository, t.Tag, t.Digest, now, now).Exec()
+	return err
+}
+
+// DeleteProxyCacheTags deletes the cached tags of the project
+func DeleteProxyCacheTags(projectID int64) error {
+	_, err := GetOrmer().QueryTable(&models.ProxyCacheTag{}).Filter("ProjectID", projectID).Delete()
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyCacheTag(t *testing.T) {
+	defer DeleteProxyCacheTags(1)
+
+	tag, err := GetProxyCacheTag("library/proxy-cache-test", "latest")
+	require.Nil(t, err)
+	assert.Nil(t, tag)
+
+	err = SetProxyCacheTag(&models.ProxyCacheTag{
+		ProjectID:  1,
+		Repository: "library/proxy-cache-test",
+		Tag:        "latest",
+		Digest:     "sha256:1",
+	})
+	require.Nil(t, err)
+	tag, err = GetProxyCacheTag("library/proxy-cache-test", "latest")
+	require.Nil(t, err)
+	require.NotNil(t, tag)
+	assert.Equal(t, "sha256:1", tag.Digest)
+	updated := tag.UpdateTime
+
+	time.Sleep(10 * time.Millisecond)
+	err = SetProxyCacheTag(&models.ProxyCacheTag{
+		ProjectID:  1,
+		Repository: "library/proxy-cache-test",
+		Tag:        "latest",
+		Digest:     "sha256:2",
+	})
+	require.Nil(t, err)
+	tag, err = GetProxyCacheTag("library/proxy-cache-test", "latest")
+	require.Nil(t, err)
+	require.NotNil(t, tag)
+	assert.Equal(t, "sha256:2", tag.Digest)
+	assert.True(t, tag.UpdateTime.After(updated))
+
+	require.Nil(t, DeleteProxyCacheTags(1))
+	tag, err = GetProxyCacheTag("library/proxy-cache-test", "latest")
+	require.Nil(t, err)
+	assert.Nil(t, tag)
+}
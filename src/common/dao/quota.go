@@ -0,0 +1,110 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// GetProjectQuota returns the hard limits of the project, nil if the limits aren't set for the project
+func GetProjectQuota(projectID int64) (*models.ProjectQuota, error) {
+	quota := &models.ProjectQuota{
+		ProjectID: projectID,
+	}
+	if err := GetOrmer().Read(quota, "ProjectID"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return quota, nil
+}
+
+// SetProjectQuota creates or updates the hard limits of the project
+func SetProjectQuota(quota *models.ProjectQuota) error {
+	now := time.Now()
+	sql := `insert into project_quota (project_id, storage_limit, count_limit, creation_time, update_time)
+		values (?, ?, ?, ?, ?)
+		on conflict (project_id) do update set storage_limit = excluded.storage_limit, count_limit = excluded.count_limit`
+	_, err := GetOrmer().Raw(sql, quota.ProjectID, quota.StorageLimit, quota.CountLimit, now, now).Exec()
+	return err
+}
+
+// DeleteProjectQuota deletes the hard limits of the project, the system default quotas will be applied
+func DeleteProjectQuota(projectID int64) error {
+	_, err := GetOrmer().QueryTable(&models.ProjectQuota{}).Filter("ProjectID", projectID).Delete()
+	return err
+}
+
+// AddOrUpdateArtifact adds the artifact, or updates the digest and size of it if the tag is overwritten
+func AddOrUpdateArtifact(artifact *models.Artifact) error {
+	now := time.Now()
+	sql := `insert into artifact (project_id, repo, tag, digest, size, creation_time, update_time)
+		values (?, ?, ?, ?, ?, ?, ?)
+		on conflict (repo, tag) do update set digest = excluded.digest, size = excluded.size`
+	_, err := GetOrmer().Raw(sql, artifact.ProjectID, artifact.Repo, artifact.Tag,
+		artifact.Digest, artifact.Size, now, now).Exec()
+	return err
+}
+
+// GetArtifact returns the artifact specified by repository and tag, nil if not found
+func GetArtifact(repo, tag string) (*models.Artifact, error) {
+	artifact := &models.Artifact{
+		Repo: repo,
+		Tag:  tag,
+	}
+	if err := GetOrmer().Read(artifact, "Repo", "Tag"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// ArtifactDigestExists returns whether there is any artifact in the project referring to the digest
+func ArtifactDigestExists(projectID int64, digest string) bool {
+	return GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("ProjectID", projectID).
+		Filter("Digest", digest).
+		Exist()
+}
+
+// DeleteArtifactsByDigest deletes all the tags of the repository referring to the digest,
+// as all of them are removed when the manifest is deleted from registry
+func DeleteArtifactsByDigest(repo, digest string) (int64, error) {
+	return GetOrmer().QueryTable(&models.Artifact{}).
+		Filter("Repo", repo).
+		Filter("Digest", digest).
+		Delete()
+}
+
+// GetProjectUsage returns the resources used by the project
+func GetProjectUsage(projectID int64) (*models.QuotaResources, error) {
+	usage := &models.QuotaResources{}
+	sql := `select count(*) as count from artifact where project_id = ?`
+	if err := GetOrmer().Raw(sql, projectID).QueryRow(&usage.Count); err != nil {
+		return nil, err
+	}
+	sql = `select coalesce(sum(size), 0) as storage from
+		(select distinct digest, size from artifact where project_id = ?) as images`
+	if err := GetOrmer().Raw(sql, projectID).QueryRow(&usage.Storage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,94 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectQuota(t *testing.T) {
+	quota, err := GetProjectQuota(1)
+	require.Nil(t, err)
+	assert.Nil(t, quota)
+
+	require.Nil(t, SetProjectQuota(&models.ProjectQuota{
+		ProjectID:    1,
+		StorageLimit: 1024,
+		CountLimit:   -1,
+	}))
+	defer DeleteProjectQuota(1)
+
+	// update
+	require.Nil(t, SetProjectQuota(&models.ProjectQuota{
+		ProjectID:    1,
+		StorageLimit: 2048,
+		CountLimit:   10,
+	}))
+	quota, err = GetProjectQuota(1)
+	require.Nil(t, err)
+	require.NotNil(t, quota)
+	assert.Equal(t, int64(2048), quota.StorageLimit)
+	assert.Equal(t, int64(10), quota.CountLimit)
+
+	require.Nil(t, DeleteProjectQuota(1))
+	quota, err = GetProjectQuota(1)
+	require.Nil(t, err)
+	assert.Nil(t, quota)
+}
+
+func TestArtifact(t *testing.T) {
+	repo := "library/quota"
+	artifacts := []*models.Artifact{
+		{ProjectID: 1, Repo: repo, Tag: "v1", Digest: "sha256:1", Size: 100},
+		{ProjectID: 1, Repo: repo, Tag: "latest", Digest: "sha256:1", Size: 100},
+		{ProjectID: 1, Repo: repo, Tag: "v2", Digest: "sha256:2", Size: 200},
+	}
+	for _, artifact := range artifacts {
+		require.Nil(t, AddOrUpdateArtifact(artifact))
+	}
+	defer DeleteArtifactsByDigest(repo, "sha256:1")
+	defer DeleteArtifactsByDigest(repo, "sha256:2")
+	defer DeleteArtifactsByDigest(repo, "sha256:3")
+
+	// the images sharing the same digest are counted once
+	usage, err := GetProjectUsage(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), usage.Count)
+	assert.Equal(t, int64(300), usage.Storage)
+	assert.True(t, ArtifactDigestExists(1, "sha256:2"))
+
+	// overwrite the tag
+	require.Nil(t, AddOrUpdateArtifact(&models.Artifact{
+		ProjectID: 1, Repo: repo, Tag: "latest", Digest: "sha256:3", Size: 300,
+	}))
+	artifact, err := GetArtifact(repo, "latest")
+	require.Nil(t, err)
+	require.NotNil(t, artifact)
+	assert.Equal(t, "sha256:3", artifact.Digest)
+
+	n, err := DeleteArtifactsByDigest(repo, "sha256:1")
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+
+	usage, err = GetProjectUsage(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), usage.Count)
+	assert.Equal(t, int64(500), usage.Storage)
+}
@@ -0,0 +1,174 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// AddRetentionPolicy ...
+func AddRetentionPolicy(policy *models.RetentionPolicy) (int64, error) {
+	now := time.Now()
+	policy.CreationTime = now
+	policy.UpdateTime = now
+	id, err := GetOrmer().Insert(policy)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return 0, ErrDupRows
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetRetentionPolicy ...
+func GetRetentionPolicy(id int64) (*models.RetentionPolicy, error) {
+	policy := &models.RetentionPolicy{
+		ID: id,
+	}
+	if err := GetOrmer().Read(policy); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// GetRetentionPolicyByProject returns the retention policy of the project, nil if the project has no policy
+func GetRetentionPolicyByProject(projectID int64) (*models.RetentionPolicy, error) {
+	policy := &models.RetentionPolicy{
+		ProjectID: projectID,
+	}
+	if err := GetOrmer().Read(policy, "ProjectID"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// UpdateRetentionPolicy updates the specified properties of the policy, all the properties are updated if none is specified
+func UpdateRetentionPolicy(policy *models.RetentionPolicy, props ...string) error {
+	policy.UpdateTime = time.Now()
+	if len(props) > 0 {
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(policy, props...)
+	return err
+}
+
+// DeleteRetentionPolicy deletes the policy with its executions
+func DeleteRetentionPolicy(id int64) error {
+	o := GetOrmer()
+	if _, err := o.QueryTable(&models.RetentionExecution{}).Filter("PolicyID", id).Delete(); err != nil {
+		return err
+	}
+	_, err := o.QueryTable(&models.RetentionPolicy{}).Filter("ID", id).Delete()
+	return err
+}
+
+// AddRetentionExecution ...
+func AddRetentionExecution(execution *models.RetentionExecution) (int64, error) {
+	if len(execution.Status) == 0 {
+		execution.Status = models.JobPending
+	}
+	now := time.Now()
+	execution.CreationTime = now
+	execution.UpdateTime = now
+	return GetOrmer().Insert(execution)
+}
+
+// GetRetentionExecution ...
+func GetRetentionExecution(id int64) (*models.RetentionExecution, error) {
+	execution := &models.RetentionExecution{
+		ID: id,
+	}
+	if err := GetOrmer().Read(execution); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// GetRetentionExecutionByUUID returns the execution run by the specified job, nil if not found
+func GetRetentionExecutionByUUID(uuid string) (*models.RetentionExecution, error) {
+	execution := &models.RetentionExecution{
+		JobUUID: uuid,
+	}
+	if err := GetOrmer().Read(execution, "JobUUID"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// ListRetentionExecutions lists the executions according to the query conditions, the latest first
+func ListRetentionExecutions(query *models.RetentionExecutionQuery) ([]*models.RetentionExecution, error) {
+	qs := getRetentionExecutionQuerySetter(query).OrderBy("-ID")
+	if query != nil && query.Size > 0 {
+		qs = qs.Limit(query.Size)
+		if query.Page > 0 {
+			qs = qs.Offset((query.Page - 1) * query.Size)
+		}
+	}
+	executions := []*models.RetentionExecution{}
+	_, err := qs.All(&executions)
+	return executions, err
+}
+
+// CountRetentionExecutions ...
+func CountRetentionExecutions(query *models.RetentionExecutionQuery) (int64, error) {
+	return getRetentionExecutionQuerySetter(query).Count()
+}
+
+func getRetentionExecutionQuerySetter(query *models.RetentionExecutionQuery) orm.QuerySeter {
+	qs := GetOrmer().QueryTable(&models.RetentionExecution{})
+
+	if query == nil {
+		return qs
+	}
+
+	if query.PolicyID != 0 {
+		qs = qs.Filter("PolicyID", query.PolicyID)
+	}
+	if query.ProjectID != 0 {
+		qs = qs.Filter("ProjectID", query.ProjectID)
+	}
+	return qs
+}
+
+// UpdateRetentionExecution updates the specified properties of the execution, all the properties are updated if none is specified
+func UpdateRetentionExecution(execution *models.RetentionExecution, props ...string) error {
+	execution.UpdateTime = time.Now()
+	if len(props) > 0 {
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(execution, props...)
+	return err
+}
@@ -0,0 +1,127 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	policy := &models.RetentionPolicy{
+		ProjectID: 1,
+		Rules:     `{"keep_latest":10}`,
+	}
+
+	id, err := AddRetentionPolicy(policy)
+	require.Nil(t, err)
+	defer DeleteRetentionPolicy(id)
+
+	// one policy per project
+	_, err = AddRetentionPolicy(&models.RetentionPolicy{
+		ProjectID: 1,
+		Rules:     `{"keep_days":7}`,
+	})
+	assert.Equal(t, ErrDupRows, err)
+
+	p, err := GetRetentionPolicyByProject(1)
+	require.Nil(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, id, p.ID)
+
+	p.JobUUID = "uuid"
+	require.Nil(t, UpdateRetentionPolicy(p, "JobUUID"))
+	p, err = GetRetentionPolicy(id)
+	require.Nil(t, err)
+	assert.Equal(t, "uuid", p.JobUUID)
+	assert.Equal(t, `{"keep_latest":10}`, p.Rules)
+
+	p, err = GetRetentionPolicyByProject(10000)
+	require.Nil(t, err)
+	assert.Nil(t, p)
+}
+
+func TestRetentionExecution(t *testing.T) {
+	id, err := AddRetentionPolicy(&models.RetentionPolicy{
+		ProjectID: 2,
+		Rules:     `{"keep_latest":10}`,
+	})
+	require.Nil(t, err)
+
+	eid, err := AddRetentionExecution(&models.RetentionExecution{
+		PolicyID:  id,
+		ProjectID: 2,
+		DryRun:    true,
+		Trigger:   models.RetentionTriggerManual,
+	})
+	require.Nil(t, err)
+
+	e, err := GetRetentionExecution(eid)
+	require.Nil(t, err)
+	require.NotNil(t, e)
+	assert.Equal(t, models.JobPending, e.Status)
+	assert.True(t, e.DryRun)
+
+	e.JobUUID = "execution-uuid"
+	e.Status = models.JobFinished
+	e.Report = `{"dry_run":true}`
+	require.Nil(t, UpdateRetentionExecution(e, "JobUUID", "Status", "Report"))
+
+	e, err = GetRetentionExecutionByUUID("execution-uuid")
+	require.Nil(t, err)
+	require.NotNil(t, e)
+	assert.Equal(t, eid, e.ID)
+	assert.Equal(t, models.JobFinished, e.Status)
+
+	executions, err := ListRetentionExecutions(&models.RetentionExecutionQuery{PolicyID: id})
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(executions))
+
+	// the executions are deleted with the policy
+	require.Nil(t, DeleteRetentionPolicy(id))
+	total, err := CountRetentionExecutions(&models.RetentionExecutionQuery{PolicyID: id})
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestGetLatestAccessTimeOfTags(t *testing.T) {
+	repoName := "retention/access_time"
+	earlier := time.Now().Add(-48 * time.Hour)
+	later := time.Now().Add(-24 * time.Hour)
+	for _, opTime := range []time.Time{earlier, later} {
+		require.Nil(t, AddAccessLog(models.AccessLog{
+			Username:  "admin",
+			ProjectID: 1,
+			RepoName:  repoName,
+			RepoTag:   "latest",
+			Operation: "pull",
+			OpTime:    opTime,
+		}))
+	}
+
+	times, err := GetLatestAccessTimeOfTags(repoName, "pull")
+	require.Nil(t, err)
+	require.Equal(t, 1, len(times))
+	assert.Equal(t, later.Unix(), times["latest"].Unix())
+
+	times, err = GetLatestAccessTimeOfTags(repoName, "push")
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(times))
+}
@@ -15,14 +15,19 @@
 package dao
 
 import (
+	"encoding/json"
 	"github.com/astaxie/beego/orm"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 	"strings"
 	"time"
 )
 
 // AddRobot ...
 func AddRobot(robot *models.Robot) (int64, error) {
+	if err := marshalRobotAccess(robot); err != nil {
+		return 0, err
+	}
 	now := time.Now()
 	robot.CreationTime = now
 	robot.UpdateTime = now
@@ -48,9 +53,29 @@ func GetRobotByID(id int64) (*models.Robot, error) {
 		return nil, err
 	}
 
+	if err := unmarshalRobotAccess(robot); err != nil {
+		return nil, err
+	}
 	return robot, nil
 }
 
+// LoginByRobot returns the robot whose name and token secret match, the robots are identified by
+// the name and the project, so all the robots with the name are checked against the secret
+func LoginByRobot(name, secret string) (*models.Robot, error) {
+	robots, err := ListRobots(&models.RobotQuery{
+		Name: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, robot := range robots {
+		if len(robot.Salt) > 0 && robot.Token == utils.Encrypt(secret, robot.Salt) {
+			return robot, nil
+		}
+	}
+	return nil, nil
+}
+
 // ListRobots list robots according to the query conditions
 func ListRobots(query *models.RobotQuery) ([]*models.Robot, error) {
 	qs := getRobotQuerySetter(query).OrderBy("Name")
@@ -63,8 +88,15 @@ func ListRobots(query *models.RobotQuery) ([]*models.Robot, error) {
 		}
 	}
 	robots := []*models.Robot{}
-	_, err := qs.All(&robots)
-	return robots, err
+	if _, err := qs.All(&robots); err != nil {
+		return nil, err
+	}
+	for _, robot := range robots {
+		if err := unmarshalRobotAccess(robot); err != nil {
+			return nil, err
+		}
+	}
+	return robots, nil
 }
 
 func getRobotQuerySetter(query *models.RobotQuery) orm.QuerySeter {
@@ -81,7 +113,9 @@ func getRobotQuerySetter(query *models.RobotQuery) orm.QuerySeter {
 			qs = qs.Filter("Name", query.Name)
 		}
 	}
-	if query.ProjectID != 0 {
+	if query.SystemLevel {
+		qs = qs.Filter("ProjectID", 0)
+	} else if query.ProjectID != 0 {
 		qs = qs.Filter("ProjectID", query.ProjectID)
 	}
 	return qs
@@ -92,10 +126,21 @@ func CountRobot(query *models.RobotQuery) (int64, error) {
 	return getRobotQuerySetter(query).Count()
 }
 
-// UpdateRobot ...
-func UpdateRobot(robot *models.Robot) error {
+// UpdateRobot updates the specified properties of the robot, all the properties are updated if none is specified
+func UpdateRobot(robot *models.Robot, props ...string) error {
+	if err := marshalRobotAccess(robot); err != nil {
+		return err
+	}
 	robot.UpdateTime = time.Now()
-	_, err := GetOrmer().Update(robot)
+	if len(props) > 0 {
+		for i, prop := range props {
+			if prop == "Access" {
+				props[i] = "AccessDB"
+			}
+		}
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(robot, props...)
 	return err
 }
 
@@ -104,3 +149,23 @@ func DeleteRobot(id int64) error {
 	_, err := GetOrmer().QueryTable(&models.Robot{}).Filter("ID", id).Delete()
 	return err
 }
+
+func marshalRobotAccess(robot *models.Robot) error {
+	if robot.Access == nil {
+		robot.Access = []*models.ResourceActions{}
+	}
+	data, err := json.Marshal(robot.Access)
+	if err != nil {
+		return err
+	}
+	robot.AccessDB = string(data)
+	return nil
+}
+
+func unmarshalRobotAccess(robot *models.Robot) error {
+	robot.Access = []*models.ResourceActions{}
+	if len(robot.AccessDB) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(robot.AccessDB), &robot.Access)
+}
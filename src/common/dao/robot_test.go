@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -163,3 +164,59 @@ func TestListAllRobot(t *testing.T) {
 	assert.Equal(t, 5, len(robots))
 
 }
+
+func TestSystemRobot(t *testing.T) {
+	robot := &models.Robot{
+		Name:        "test7",
+		Token:       "rKgjKEMpMEK23zqejkWn5GIVvgJps1vKACTa6tnGXXyOlOTsXFESccDvgaJx047q7",
+		Description: "test7 description",
+		ExpiresAt:   models.RobotNeverExpire,
+		Access: []*models.ResourceActions{
+			{Name: "/project/1/image", Actions: []string{"pull"}},
+			{Name: "/project/2/helm-repo", Actions: []string{"read"}},
+		},
+	}
+
+	id, err := AddRobot(robot)
+	require.Nil(t, err)
+	defer DeleteRobot(id)
+
+	robots, err := ListRobots(&models.RobotQuery{
+		SystemLevel: true,
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(robots))
+	assert.True(t, robots[0].IsSystem())
+	require.Equal(t, 2, len(robots[0].Access))
+	assert.Equal(t, "/project/2/helm-repo", robots[0].Access[1].Name)
+
+	robot.ExpiresAt = 100
+	robot.Access = robot.Access[:1]
+	require.Nil(t, UpdateRobot(robot, "ExpiresAt", "Access"))
+	robot, err = GetRobotByID(id)
+	require.Nil(t, err)
+	assert.Equal(t, int64(100), robot.ExpiresAt)
+	assert.Equal(t, 1, len(robot.Access))
+}
+
+func TestLoginByRobot(t *testing.T) {
+	robot := &models.Robot{
+		Name:      "robot$test8",
+		Salt:      "salt",
+		Token:     utils.Encrypt("secret", "salt"),
+		ProjectID: 1,
+		ExpiresAt: models.RobotNeverExpire,
+	}
+	id, err := AddRobot(robot)
+	require.Nil(t, err)
+	defer DeleteRobot(id)
+
+	r, err := LoginByRobot("robot$test8", "invalid")
+	require.Nil(t, err)
+	assert.Nil(t, r)
+
+	r, err = LoginByRobot("robot$test8", "secret")
+	require.Nil(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, id, r.ID)
+}
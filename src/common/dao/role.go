@@ -15,10 +15,14 @@
 package dao
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/astaxie/beego/orm"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 )
 
 // GetUserProjectRoles returns roles that the user has according to the project.
@@ -88,5 +92,115 @@ func GetRoleByID(id int) (*models.Role, error) {
 		}
 		return nil, err
 	}
+	if err := unmarshalRolePolicies(&role); err != nil {
+		return nil, err
+	}
 	return &role, nil
 }
+
+// GetRoleByName ...
+func GetRoleByName(name string) (*models.Role, error) {
+	role := &models.Role{
+		Name: name,
+	}
+	if err := GetOrmer().Read(role, "Name"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := unmarshalRolePolicies(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// ListRoles returns the built-in and the custom roles ordered by ID
+func ListRoles() ([]*models.Role, error) {
+	roles := []*models.Role{}
+	if _, err := GetOrmer().QueryTable(&models.Role{}).OrderBy("RoleID").All(&roles); err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if err := unmarshalRolePolicies(role); err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+// AddRole adds a custom role
+func AddRole(role *models.Role) (int, error) {
+	if err := marshalRolePolicies(role); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	role.CreationTime = now
+	role.UpdateTime = now
+	id, err := GetOrmer().Insert(role)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return 0, ErrDupRows
+		}
+		return 0, err
+	}
+	return int(id), nil
+}
+
+// UpdateRole updates the name, description and policies of the custom role
+func UpdateRole(role *models.Role) error {
+	if err := marshalRolePolicies(role); err != nil {
+		return err
+	}
+	role.UpdateTime = time.Now()
+	_, err := GetOrmer().Update(role, "Name", "Description", "PoliciesDB", "UpdateTime")
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrDupRows
+	}
+	return err
+}
+
+// DeleteRole deletes the custom role
+func DeleteRole(id int) error {
+	_, err := GetOrmer().QueryTable(&models.Role{}).Filter("RoleID", id).Delete()
+	return err
+}
+
+// CountRoleMembers returns the count of the project members the role is assigned to
+func CountRoleMembers(id int) (int64, error) {
+	var count int64
+	err := GetOrmer().Raw(`select count(*) from project_member where role = ?`, id).QueryRow(&count)
+	return count, err
+}
+
+func marshalRolePolicies(role *models.Role) error {
+	if role.Policies == nil {
+		role.Policies = []*models.RolePolicy{}
+	}
+	data, err := json.Marshal(role.Policies)
+	if err != nil {
+		return err
+	}
+	role.PoliciesDB = string(data)
+	return nil
+}
+
+// unmarshalRolePolicies populates the policies of the role, the ones of the built-in roles are
+// defined by the rbac package rather than stored
+func unmarshalRolePolicies(role *models.Role) error {
+	role.Policies = []*models.RolePolicy{}
+	if rbac_project.IsBuiltInRole(role.RoleID) {
+		role.BuiltIn = true
+		for _, policy := range rbac_project.BuiltInRolePolicies(role.RoleID) {
+			role.Policies = append(role.Policies, &models.RolePolicy{
+				Resource: policy.Resource,
+				Action:   policy.Action,
+			})
+		}
+		return nil
+	}
+	if len(role.PoliciesDB) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(role.PoliciesDB), &role.Policies)
+}
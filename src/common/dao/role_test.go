@@ -0,0 +1,93 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomRole(t *testing.T) {
+	role := &models.Role{
+		Name:        "role_for_dao_test",
+		Description: "pull only",
+		Policies: []*models.RolePolicy{
+			{Resource: rbac_project.ResourceImage, Action: rbac_project.ActionPull},
+		},
+	}
+	id, err := AddRole(role)
+	require.Nil(t, err)
+	defer DeleteRole(id)
+
+	_, err = AddRole(&models.Role{Name: "role_for_dao_test"})
+	assert.Equal(t, ErrDupRows, err)
+
+	r, err := GetRoleByID(id)
+	require.Nil(t, err)
+	require.NotNil(t, r)
+	assert.False(t, r.BuiltIn)
+	require.Len(t, r.Policies, 1)
+	assert.Equal(t, rbac_project.ActionPull, r.Policies[0].Action)
+
+	r.Description = "pull and scan"
+	r.Policies = append(r.Policies, &models.RolePolicy{Resource: rbac_project.ResourceScan, Action: rbac_project.ActionCreate})
+	require.Nil(t, UpdateRole(r))
+	r, err = GetRoleByName("role_for_dao_test")
+	require.Nil(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "pull and scan", r.Description)
+	assert.Len(t, r.Policies, 2)
+
+	count, err := CountRoleMembers(id)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), count)
+
+	roles, err := ListRoles()
+	require.Nil(t, err)
+	found := false
+	for _, r := range roles {
+		if r.RoleID == id {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	require.Nil(t, DeleteRole(id))
+	r, err = GetRoleByID(id)
+	require.Nil(t, err)
+	assert.Nil(t, r)
+}
+
+func TestBuiltInRolePolicies(t *testing.T) {
+	r, err := GetRoleByID(common.RoleDeveloper)
+	require.Nil(t, err)
+	require.NotNil(t, r)
+	assert.True(t, r.BuiltIn)
+	assert.Equal(t, len(rbac_project.BuiltInRolePolicies(common.RoleDeveloper)), len(r.Policies))
+
+	// the roles shipped by the migration
+	for _, name := range []string{"maintainer", "scanner"} {
+		r, err := GetRoleByName(name)
+		require.Nil(t, err)
+		require.NotNil(t, r, name)
+		assert.False(t, r.BuiltIn)
+		assert.NotEmpty(t, r.Policies)
+	}
+}
@@ -158,6 +158,16 @@ func GetImgScanOverview(digest string) (*models.ImgScanOverview, error) {
 
 // UpdateImgScanOverview updates the serverity and components status of a record in img_scan_overview
 func UpdateImgScanOverview(digest, detailsKey string, sev models.Severity, compOverview *models.ComponentsOverview) error {
+	return updateImgScanOverview(digest, detailsKey, sev, compOverview)
+}
+
+// UpdateImgScanOverviewByScanner updates the record in img_scan_overview with the report generated by the scanner,
+// the details key is the ID of the report in the scanner
+func UpdateImgScanOverviewByScanner(digest string, scannerID int64, detailsKey string, sev models.Severity, compOverview *models.ComponentsOverview) error {
+	return updateImgScanOverview(digest, detailsKey, sev, compOverview, scannerID)
+}
+
+func updateImgScanOverview(digest, detailsKey string, sev models.Severity, compOverview *models.ComponentsOverview, scannerID ...int64) error {
 	o := GetOrmer()
 	rec, err := GetImgScanOverview(digest)
 	if err != nil {
@@ -174,8 +184,13 @@ func UpdateImgScanOverview(digest, detailsKey string, sev models.Severity, compO
 	rec.CompOverviewStr = string(b)
 	rec.DetailsKey = detailsKey
 	rec.UpdateTime = time.Now()
+	cols := []string{"Sev", "CompOverviewStr", "DetailsKey", "UpdateTime"}
+	if len(scannerID) > 0 {
+		rec.ScannerID = scannerID[0]
+		cols = append(cols, "ScannerID")
+	}
 
-	_, err = o.Update(rec, "Sev", "CompOverviewStr", "DetailsKey", "UpdateTime")
+	_, err = o.Update(rec, cols...)
 	if err != nil {
 		return fmt.Errorf("Failed to update scan overview record with digest: %s, error: %v", digest, err)
 	}
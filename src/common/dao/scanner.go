@@ -0,0 +1,116 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// AddScannerRegistration ...
+func AddScannerRegistration(reg *models.ScannerRegistration) (int64, error) {
+	now := time.Now()
+	reg.CreationTime = now
+	reg.UpdateTime = now
+	id, err := GetOrmer().Insert(reg)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return 0, ErrDupRows
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetScannerRegistration returns the registration, nil if not found
+func GetScannerRegistration(id int64) (*models.ScannerRegistration, error) {
+	reg := &models.ScannerRegistration{
+		ID: id,
+	}
+	if err := GetOrmer().Read(reg); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return reg, nil
+}
+
+// GetDefaultScannerRegistration returns the default registration, nil if no registration is the default one
+func GetDefaultScannerRegistration() (*models.ScannerRegistration, error) {
+	reg := &models.ScannerRegistration{
+		IsDefault: true,
+	}
+	if err := GetOrmer().Read(reg, "IsDefault"); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return reg, nil
+}
+
+// ListScannerRegistrations ...
+func ListScannerRegistrations() ([]*models.ScannerRegistration, error) {
+	regs := []*models.ScannerRegistration{}
+	_, err := GetOrmer().QueryTable(&models.ScannerRegistration{}).
+		OrderBy("ID").
+		All(&regs)
+	return regs, err
+}
+
+// UpdateScannerRegistration updates the specified properties of the registration, all the properties are updated if none is specified
+func UpdateScannerRegistration(reg *models.ScannerRegistration, props ...string) error {
+	reg.UpdateTime = time.Now()
+	if len(props) > 0 {
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(reg, props...)
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrDupRows
+	}
+	return err
+}
+
+// SetDefaultScannerRegistration makes the registration the default one
+func SetDefaultScannerRegistration(id int64) error {
+	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return err
+	}
+	if _, err := o.QueryTable(&models.ScannerRegistration{}).
+		Filter("IsDefault", true).
+		Exclude("ID", id).
+		Update(orm.Params{"IsDefault": false}); err != nil {
+		o.Rollback()
+		return err
+	}
+	if _, err := o.QueryTable(&models.ScannerRegistration{}).
+		Filter("ID", id).
+		Update(orm.Params{"IsDefault": true, "UpdateTime": time.Now()}); err != nil {
+		o.Rollback()
+		return err
+	}
+	return o.Commit()
+}
+
+// DeleteScannerRegistration ...
+func DeleteScannerRegistration(id int64) error {
+	_, err := GetOrmer().QueryTable(&models.ScannerRegistration{}).Filter("ID", id).Delete()
+	return err
+}
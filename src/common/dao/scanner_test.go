@@ -0,0 +1,82 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannerRegistration(t *testing.T) {
+	origin, err := GetDefaultScannerRegistration()
+	require.Nil(t, err)
+
+	reg := &models.ScannerRegistration{
+		Name:       "scanner_for_dao_test",
+		URL:        "http://scanner-for-dao-test:8080",
+		AuthHeader: "Bearer token",
+	}
+	id, err := AddScannerRegistration(reg)
+	require.Nil(t, err)
+	defer DeleteScannerRegistration(id)
+
+	_, err = AddScannerRegistration(&models.ScannerRegistration{
+		Name: "scanner_for_dao_test",
+		URL:  "http://another-scanner-for-dao-test:8080",
+	})
+	assert.Equal(t, ErrDupRows, err)
+
+	r, err := GetScannerRegistration(id)
+	require.Nil(t, err)
+	require.NotNil(t, r)
+	assert.Equal(t, "Bearer token", r.AuthHeader)
+	assert.False(t, r.IsDefault)
+
+	regs, err := ListScannerRegistrations()
+	require.Nil(t, err)
+	found := false
+	for _, r := range regs {
+		if r.ID == id {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	// set as default
+	require.Nil(t, SetDefaultScannerRegistration(id))
+	if origin != nil {
+		defer SetDefaultScannerRegistration(origin.ID)
+	}
+	d, err := GetDefaultScannerRegistration()
+	require.Nil(t, err)
+	require.NotNil(t, d)
+	assert.Equal(t, id, d.ID)
+
+	// update
+	r.Disabled = true
+	require.Nil(t, UpdateScannerRegistration(r, "Disabled"))
+	r, err = GetScannerRegistration(id)
+	require.Nil(t, err)
+	assert.True(t, r.Disabled)
+
+	// delete
+	require.Nil(t, DeleteScannerRegistration(id))
+	r, err = GetScannerRegistration(id)
+	require.Nil(t, err)
+	assert.Nil(t, r)
+}
@@ -0,0 +1,150 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+)
+
+// SavePendingUserTOTP saves the encrypted secret of the user as a pending second factor which is enabled after
+// the first code is verified, the pending one of the user is replaced, ErrDupRows is returned if the user
+// already has an enabled one
+func SavePendingUserTOTP(userID int, secret string) error {
+	result, err := GetOrmer().Raw(`insert into user_totp (user_id, secret, enabled, last_used_step,
+		recovery_codes, salt, cli_secret, cli_salt, creation_time, update_time)
+		values (?, ?, false, 0, '[]', '', '', '', now(), now())
+		on conflict (user_id) do update set
+			secret = excluded.secret,
+			last_used_step = 0,
+			recovery_codes = '[]',
+			salt = '',
+			cli_secret = '',
+			cli_salt = '',
+			creation_time = now(),
+			update_time = now()
+		where user_totp.enabled = false`, userID, secret).Exec()
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDupRows
+	}
+	return nil
+}
+
+// GetUserTOTP returns the second factor of the user, nil is returned if the user doesn't have one
+func GetUserTOTP(userID int) (*models.UserTOTP, error) {
+	t := &models.UserTOTP{}
+	if err := GetOrmer().QueryTable(&models.UserTOTP{}).Filter("UserID", userID).One(t); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	t.RecoveryCodes = []string{}
+	if len(t.RecoveryCodesDB) > 0 {
+		if err := json.Unmarshal([]byte(t.RecoveryCodesDB), &t.RecoveryCodes); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// UpdateUserTOTP updates the specified properties of the second factor, all the properties are updated if none is specified
+func UpdateUserTOTP(t *models.UserTOTP, props ...string) error {
+	if t.RecoveryCodes == nil {
+		t.RecoveryCodes = []string{}
+	}
+	data, err := json.Marshal(t.RecoveryCodes)
+	if err != nil {
+		return err
+	}
+	t.RecoveryCodesDB = string(data)
+	t.UpdateTime = time.Now()
+	if len(props) > 0 {
+		for i, prop := range props {
+			if prop == "RecoveryCodes" {
+				props[i] = "RecoveryCodesDB"
+			}
+		}
+		props = append(props, "UpdateTime")
+	}
+	_, err = GetOrmer().Update(t, props...)
+	return err
+}
+
+// DeleteUserTOTP removes the second factor of the user
+func DeleteUserTOTP(userID int) error {
+	_, err := GetOrmer().QueryTable(&models.UserTOTP{}).Filter("UserID", userID).Delete()
+	return err
+}
+
+// UseTOTPStep marks the time step of a verified code as used, false is returned if the step or a later
+// one has been used, which means the code is replayed
+func UseTOTPStep(userID int, step int64) (bool, error) {
+	result, err := GetOrmer().Raw(`update user_totp set last_used_step = ?, update_time = now()
+		where user_id = ? and last_used_step < ?`, step, userID, step).Exec()
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// UseRecoveryCode removes the recovery code from the unused ones of the user, false is returned if
+// the code doesn't match any of them
+func UseRecoveryCode(userID int, code string) (bool, error) {
+	t, err := GetUserTOTP(userID)
+	if err != nil || t == nil {
+		return false, err
+	}
+	hash := utils.Encrypt(code, t.Salt)
+	left := []string{}
+	for _, c := range t.RecoveryCodes {
+		if c != hash {
+			left = append(left, c)
+		}
+	}
+	if len(left) == len(t.RecoveryCodes) {
+		return false, nil
+	}
+	data, err := json.Marshal(left)
+	if err != nil {
+		return false, err
+	}
+	// the update is conditional on the codes loaded to avoid using the same code concurrently
+	result, err := GetOrmer().Raw(`update user_totp set recovery_codes = ?, update_time = now()
+		where user_id = ? and recovery_codes = ?`, string(data), userID, t.RecoveryCodesDB).Exec()
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,91 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTOTP(t *testing.T) {
+	id, err := Register(models.User{
+		Username: "user_for_totp",
+		Email:    "user_for_totp@example.com",
+		Password: "Passw0rd1",
+	})
+	require.Nil(t, err)
+	defer CleanUser(id)
+	userID := int(id)
+
+	totp, err := GetUserTOTP(userID)
+	require.Nil(t, err)
+	assert.Nil(t, totp)
+
+	// the pending one can be replaced
+	require.Nil(t, SavePendingUserTOTP(userID, "secret1"))
+	require.Nil(t, SavePendingUserTOTP(userID, "secret2"))
+	totp, err = GetUserTOTP(userID)
+	require.Nil(t, err)
+	require.NotNil(t, totp)
+	assert.Equal(t, "secret2", totp.Secret)
+	assert.False(t, totp.Enabled)
+	assert.Empty(t, totp.RecoveryCodes)
+
+	totp.Enabled = true
+	totp.Salt = utils.GenerateRandomString()
+	totp.RecoveryCodes = []string{utils.Encrypt("code1", totp.Salt), utils.Encrypt("code2", totp.Salt)}
+	require.Nil(t, UpdateUserTOTP(totp, "Enabled", "Salt", "RecoveryCodes"))
+	assert.Equal(t, ErrDupRows, SavePendingUserTOTP(userID, "secret3"))
+
+	totp, err = GetUserTOTP(userID)
+	require.Nil(t, err)
+	assert.True(t, totp.Enabled)
+	assert.Equal(t, "secret2", totp.Secret)
+	assert.Len(t, totp.RecoveryCodes, 2)
+
+	// the steps can't be reused
+	used, err := UseTOTPStep(userID, 100)
+	require.Nil(t, err)
+	assert.True(t, used)
+	used, err = UseTOTPStep(userID, 100)
+	require.Nil(t, err)
+	assert.False(t, used)
+	used, err = UseTOTPStep(userID, 99)
+	require.Nil(t, err)
+	assert.False(t, used)
+
+	// the recovery codes can be used once
+	used, err = UseRecoveryCode(userID, "code1")
+	require.Nil(t, err)
+	assert.True(t, used)
+	used, err = UseRecoveryCode(userID, "code1")
+	require.Nil(t, err)
+	assert.False(t, used)
+	used, err = UseRecoveryCode(userID, "code3")
+	require.Nil(t, err)
+	assert.False(t, used)
+	totp, err = GetUserTOTP(userID)
+	require.Nil(t, err)
+	assert.Equal(t, []string{utils.Encrypt("code2", totp.Salt)}, totp.RecoveryCodes)
+
+	require.Nil(t, DeleteUserTOTP(userID))
+	totp, err = GetUserTOTP(userID)
+	require.Nil(t, err)
+	assert.Nil(t, totp)
+}
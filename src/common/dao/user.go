@@ -33,7 +33,7 @@ func GetUser(query models.User) (*models.User, error) {
 	o := GetOrmer()
 
 	sql := `select user_id, username, password, email, realname, comment, reset_uuid, salt,
-		sysadmin_flag, creation_time, update_time
+		sysadmin_flag, creation_time, update_time, password_changed_time
 		from harbor_user u
 		where deleted = false `
 	queryParam := make([]interface{}, 1)
@@ -152,19 +152,31 @@ func ToggleUserAdminRole(userID int, hasAdmin bool) error {
 	return nil
 }
 
-// ChangeUserPassword ...
+// ChangeUserPassword saves the current password into the history and changes it
 func ChangeUserPassword(u models.User) error {
+	o := GetOrmer()
+	if err := addPasswordHistory(o, "user_id = ?", u.UserID); err != nil {
+		return err
+	}
 	u.UpdateTime = time.Now()
+	u.PasswordChangedTime = u.UpdateTime
 	u.Salt = utils.GenerateRandomString()
 	u.Password = utils.Encrypt(u.Password, u.Salt)
-	_, err := GetOrmer().Update(&u, "Password", "Salt", "UpdateTime")
+	_, err := o.Update(&u, "Password", "Salt", "UpdateTime", "PasswordChangedTime")
 	return err
 }
 
-// ResetUserPassword ...
+// ResetUserPassword saves the current password into the history and resets it
 func ResetUserPassword(u models.User) error {
+	if len(u.ResetUUID) == 0 {
+		return errors.New("empty reset uuid")
+	}
 	o := GetOrmer()
-	r, err := o.Raw(`update harbor_user set password=?, reset_uuid=? where reset_uuid=?`, utils.Encrypt(u.Password, u.Salt), "", u.ResetUUID).Exec()
+	if err := addPasswordHistory(o, "reset_uuid = ?", u.ResetUUID); err != nil {
+		return err
+	}
+	r, err := o.Raw(`update harbor_user set password=?, reset_uuid=?, password_changed_time=? where reset_uuid=?`,
+		utils.Encrypt(u.Password, u.Salt), "", time.Now(), u.ResetUUID).Exec()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,203 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// AddWebhookPolicy ...
+func AddWebhookPolicy(policy *models.WebhookPolicy) (int64, error) {
+	if err := marshalEventTypes(policy); err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	policy.CreationTime = now
+	policy.UpdateTime = now
+	id, err := GetOrmer().Insert(policy)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return 0, ErrDupRows
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetWebhookPolicy ...
+func GetWebhookPolicy(id int64) (*models.WebhookPolicy, error) {
+	policy := &models.WebhookPolicy{
+		ID: id,
+	}
+	if err := GetOrmer().Read(policy); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := unmarshalEventTypes(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// GetWebhookPolicies returns the webhook policies of the project
+func GetWebhookPolicies(projectID int64) ([]*models.WebhookPolicy, error) {
+	policies := []*models.WebhookPolicy{}
+	if _, err := GetOrmer().QueryTable(&models.WebhookPolicy{}).
+		Filter("ProjectID", projectID).
+		OrderBy("ID").
+		All(&policies); err != nil {
+		return nil, err
+	}
+
+	for _, policy := range policies {
+		if err := unmarshalEventTypes(policy); err != nil {
+			return nil, err
+		}
+	}
+	return policies, nil
+}
+
+// UpdateWebhookPolicy updates the specified properties of the policy, all the properties are updated if none is specified
+func UpdateWebhookPolicy(policy *models.WebhookPolicy, props ...string) error {
+	if err := marshalEventTypes(policy); err != nil {
+		return err
+	}
+	policy.UpdateTime = time.Now()
+	if len(props) > 0 {
+		for i, prop := range props {
+			if prop == "EventTypes" {
+				props[i] = "EventTypesDB"
+			}
+		}
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(policy, props...)
+	if err != nil && strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+		return ErrDupRows
+	}
+	return err
+}
+
+// DeleteWebhookPolicy deletes the policy with its delivery history
+func DeleteWebhookPolicy(id int64) error {
+	o := GetOrmer()
+	if _, err := o.QueryTable(&models.WebhookExecution{}).Filter("PolicyID", id).Delete(); err != nil {
+		return err
+	}
+	_, err := o.QueryTable(&models.WebhookPolicy{}).Filter("ID", id).Delete()
+	return err
+}
+
+func marshalEventTypes(policy *models.WebhookPolicy) error {
+	if policy.EventTypes == nil {
+		policy.EventTypes = []string{}
+	}
+	data, err := json.Marshal(policy.EventTypes)
+	if err != nil {
+		return err
+	}
+	policy.EventTypesDB = string(data)
+	return nil
+}
+
+func unmarshalEventTypes(policy *models.WebhookPolicy) error {
+	policy.EventTypes = []string{}
+	if len(policy.EventTypesDB) == 0 {
+		return nil
+	}
+	return json.Unmarshal([]byte(policy.EventTypesDB), &policy.EventTypes)
+}
+
+// AddWebhookExecution ...
+func AddWebhookExecution(execution *models.WebhookExecution) (int64, error) {
+	if len(execution.Status) == 0 {
+		execution.Status = models.JobPending
+	}
+	now := time.Now()
+	execution.CreationTime = now
+	execution.UpdateTime = now
+	return GetOrmer().Insert(execution)
+}
+
+// GetWebhookExecution ...
+func GetWebhookExecution(id int64) (*models.WebhookExecution, error) {
+	execution := &models.WebhookExecution{
+		ID: id,
+	}
+	if err := GetOrmer().Read(execution); err != nil {
+		if err == orm.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return execution, nil
+}
+
+// ListWebhookExecutions lists the delivery history according to the query conditions, the latest first
+func ListWebhookExecutions(query *models.WebhookExecutionQuery) ([]*models.WebhookExecution, error) {
+	qs := getWebhookExecutionQuerySetter(query).OrderBy("-ID")
+	if query != nil && query.Size > 0 {
+		qs = qs.Limit(query.Size)
+		if query.Page > 0 {
+			qs = qs.Offset((query.Page - 1) * query.Size)
+		}
+	}
+	executions := []*models.WebhookExecution{}
+	_, err := qs.All(&executions)
+	return executions, err
+}
+
+// CountWebhookExecutions ...
+func CountWebhookExecutions(query *models.WebhookExecutionQuery) (int64, error) {
+	return getWebhookExecutionQuerySetter(query).Count()
+}
+
+func getWebhookExecutionQuerySetter(query *models.WebhookExecutionQuery) orm.QuerySeter {
+	qs := GetOrmer().QueryTable(&models.WebhookExecution{})
+
+	if query == nil {
+		return qs
+	}
+
+	if query.PolicyID != 0 {
+		qs = qs.Filter("PolicyID", query.PolicyID)
+	}
+	if len(query.EventType) > 0 {
+		qs = qs.Filter("EventType", query.EventType)
+	}
+	if len(query.Status) > 0 {
+		qs = qs.Filter("Status", query.Status)
+	}
+	return qs
+}
+
+// UpdateWebhookExecution updates the specified properties of the execution, all the properties are updated if none is specified
+func UpdateWebhookExecution(execution *models.WebhookExecution, props ...string) error {
+	execution.UpdateTime = time.Now()
+	if len(props) > 0 {
+		props = append(props, "UpdateTime")
+	}
+	_, err := GetOrmer().Update(execution, props...)
+	return err
+}
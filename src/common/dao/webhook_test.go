@@ -0,0 +1,126 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dao
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookPolicy(t *testing.T) {
+	policy := &models.WebhookPolicy{
+		ProjectID:  1,
+		Name:       "ci",
+		Address:    "http://ci.example.com/hook",
+		AuthHeader: "Bearer token",
+		Secret:     "secret",
+		EventTypes: []string{models.WebhookEventPushImage},
+		Enabled:    true,
+		Creator:    "admin",
+	}
+	id, err := AddWebhookPolicy(policy)
+	require.Nil(t, err)
+	defer DeleteWebhookPolicy(id)
+
+	// the name is unique in the project
+	_, err = AddWebhookPolicy(&models.WebhookPolicy{
+		ProjectID:  1,
+		Name:       "ci",
+		Address:    "http://ci.example.com/hook2",
+		EventTypes: []string{models.WebhookEventPullImage},
+	})
+	assert.Equal(t, ErrDupRows, err)
+
+	p, err := GetWebhookPolicy(id)
+	require.Nil(t, err)
+	require.NotNil(t, p)
+	assert.Equal(t, "Bearer token", p.AuthHeader)
+	assert.Equal(t, []string{models.WebhookEventPushImage}, p.EventTypes)
+
+	p.EventTypes = []string{models.WebhookEventPushImage, models.WebhookEventDeleteImage}
+	p.Enabled = false
+	require.Nil(t, UpdateWebhookPolicy(p, "EventTypes", "Enabled"))
+
+	policies, err := GetWebhookPolicies(1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(policies))
+	assert.False(t, policies[0].Enabled)
+	assert.Equal(t, []string{models.WebhookEventPushImage, models.WebhookEventDeleteImage}, policies[0].EventTypes)
+
+	p, err = GetWebhookPolicy(10000)
+	require.Nil(t, err)
+	assert.Nil(t, p)
+}
+
+func TestWebhookExecution(t *testing.T) {
+	id, err := AddWebhookPolicy(&models.WebhookPolicy{
+		ProjectID:  2,
+		Name:       "ci",
+		Address:    "http://ci.example.com/hook",
+		EventTypes: []string{models.WebhookEventPushImage},
+		Enabled:    true,
+	})
+	require.Nil(t, err)
+
+	eid, err := AddWebhookExecution(&models.WebhookExecution{
+		PolicyID:  id,
+		EventType: models.WebhookEventPushImage,
+		Payload:   `{"type":"pushImage"}`,
+	})
+	require.Nil(t, err)
+
+	e, err := GetWebhookExecution(eid)
+	require.Nil(t, err)
+	require.NotNil(t, e)
+	assert.Equal(t, models.JobPending, e.Status)
+
+	e.Status = models.JobFinished
+	e.JobUUID = "uuid"
+	require.Nil(t, UpdateWebhookExecution(e, "Status", "JobUUID"))
+
+	_, err = AddWebhookExecution(&models.WebhookExecution{
+		PolicyID:  id,
+		EventType: models.WebhookEventPushImage,
+		Status:    models.JobError,
+	})
+	require.Nil(t, err)
+
+	query := &models.WebhookExecutionQuery{
+		PolicyID: id,
+		Status:   models.JobFinished,
+	}
+	total, err := CountWebhookExecutions(query)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	executions, err := ListWebhookExecutions(query)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(executions))
+	assert.Equal(t, eid, executions[0].ID)
+	assert.Equal(t, "uuid", executions[0].JobUUID)
+
+	total, err = CountWebhookExecutions(&models.WebhookExecutionQuery{PolicyID: id})
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+
+	// the delivery history is deleted with the policy
+	require.Nil(t, DeleteWebhookPolicy(id))
+	total, err = CountWebhookExecutions(&models.WebhookExecutionQuery{PolicyID: id})
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), total)
+}
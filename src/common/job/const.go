@@ -13,6 +13,12 @@ const (
 	ImageReplicate = "IMAGE_REPLICATE"
 	// ImageGC the name of image garbage collection job in job service
 	ImageGC = "IMAGE_GC"
+	// ImageRetention the name of tag retention job in job service
+	ImageRetention = "IMAGE_RETENTION"
+	// WebhookJob the name of the job delivering the events to the addresses of webhook policies
+	WebhookJob = "WEBHOOK"
+	// AuditLogPurge the name of the job purging or archiving the old access logs
+	AuditLogPurge = "AUDIT_LOG_PURGE"
 
 	// JobKindGeneric : Kind of generic job
 	JobKindGeneric = "Generic"
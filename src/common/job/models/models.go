@@ -24,6 +24,7 @@ type JobMetadata struct {
 	ScheduleDelay uint64 `json:"schedule_delay,omitempty"`
 	Cron          string `json:"cron_spec,omitempty"`
 	IsUnique      bool   `json:"unique"`
+	Queue         string `json:"queue,omitempty"` // the default queue of jobservice is used if it's not set
 }
 
 // JobStats keeps the result of job launching.
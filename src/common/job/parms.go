@@ -6,4 +6,6 @@ type ScanJobParms struct {
 	Repository string `json:"repository"`
 	Tag        string `json:"tag"`
 	Digest     string `json:"digest"`
+	// The ID of the scanner registration, 0 means the built-in Clair
+	ScannerID int64 `json:"scanner_id"`
 }
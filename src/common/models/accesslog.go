@@ -18,27 +18,50 @@ import (
 	"time"
 )
 
+// the types of the resources recorded in the access logs
+const (
+	LogResourceTypeProject           = "project"
+	LogResourceTypeRepository        = "repository"
+	LogResourceTypeMember            = "member"
+	LogResourceTypeConfiguration     = "configuration"
+	LogResourceTypeRobot             = "robot"
+	LogResourceTypeRetentionPolicy   = "retention_policy"
+	LogResourceTypeReplicationPolicy = "replication_policy"
+	LogResourceTypeWebhookPolicy     = "webhook_policy"
+	LogResourceTypeMetadata          = "project_metadata"
+	LogResourceTypeQuota             = "quota"
+	LogResourceTypeCVEAllowlist      = "cve_allowlist"
+	LogResourceTypeScanner           = "scanner"
+	LogResourceTypeRole              = "role"
+	LogResourceTypeUser              = "user"
+	LogResourceTypeToken             = "personal_access_token"
+)
+
 // AccessLog holds information about logs which are used to record the actions that user take to the resourses.
 type AccessLog struct {
-	LogID     int       `orm:"pk;auto;column(log_id)" json:"log_id"`
-	Username  string    `orm:"column(username)"  json:"username"`
-	ProjectID int64     `orm:"column(project_id)"  json:"project_id"`
-	RepoName  string    `orm:"column(repo_name)" json:"repo_name"`
-	RepoTag   string    `orm:"column(repo_tag)" json:"repo_tag"`
-	GUID      string    `orm:"column(guid)"  json:"guid"`
-	Operation string    `orm:"column(operation)" json:"operation"`
-	OpTime    time.Time `orm:"column(op_time)" json:"op_time"`
+	LogID        int       `orm:"pk;auto;column(log_id)" json:"log_id"`
+	Username     string    `orm:"column(username)"  json:"username"`
+	ProjectID    int64     `orm:"column(project_id)"  json:"project_id"`
+	RepoName     string    `orm:"column(repo_name)" json:"repo_name"`
+	RepoTag      string    `orm:"column(repo_tag)" json:"repo_tag"`
+	GUID         string    `orm:"column(guid)"  json:"guid"`
+	Operation    string    `orm:"column(operation)" json:"operation"`
+	OpTime       time.Time `orm:"column(op_time)" json:"op_time"`
+	ResourceType string    `orm:"column(resource_type)" json:"resource_type"`
+	Resource     string    `orm:"column(resource)" json:"resource"`
 }
 
 // LogQueryParam is used to set query conditions when listing
 // access logs.
 type LogQueryParam struct {
-	ProjectIDs []int64     // the IDs of projects to which the operation is done
-	Username   string      // the operator's username of the log
-	Repository string      // repository name
-	Tag        string      // tag name
-	Operations []string    // operations
-	BeginTime  *time.Time  // the time after which the operation is done
-	EndTime    *time.Time  // the time before which the operation is doen
-	Pagination *Pagination // pagination information
+	ProjectIDs    []int64     // the IDs of projects to which the operation is done
+	Username      string      // the operator's username of the log
+	Repository    string      // repository name
+	Tag           string      // tag name
+	Operations    []string    // operations
+	ResourceTypes []string    // the types of the resources
+	Resource      string      // the pattern of the resource, "*" matches any characters
+	BeginTime     *time.Time  // the time after which the operation is done
+	EndTime       *time.Time  // the time before which the operation is doen
+	Pagination    *Pagination // pagination information
 }
@@ -38,5 +38,19 @@ func init() {
 		new(UserGroup),
 		new(AdminJob),
 		new(JobLog),
-		new(Robot))
+		new(Robot),
+		new(RetentionPolicy),
+		new(RetentionExecution),
+		new(ProjectQuota),
+		new(Artifact),
+		new(WebhookPolicy),
+		new(WebhookExecution),
+		new(OIDCUser),
+		new(CVEAllowlistItem),
+		new(ScannerRegistration),
+		new(ProxyCacheTag),
+		new(PasswordHistory),
+		new(UserLockout),
+		new(UserTOTP),
+		new(PersonalAccessToken))
 }
@@ -0,0 +1,95 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// CVEAllowlistTable is the name of table in DB that holds the CVE allowlists
+	CVEAllowlistTable = "cve_allowlist"
+	// SystemCVEAllowlistProjectID is the project ID of the system level CVE allowlist
+	SystemCVEAllowlistProjectID int64 = 0
+)
+
+// CVEAllowlistItem is a vulnerability ignored when checking the severity of the images
+type CVEAllowlistItem struct {
+	ID        int64  `orm:"pk;auto;column(id)" json:"-"`
+	ProjectID int64  `orm:"column(project_id)" json:"-"`
+	CVEID     string `orm:"column(cve_id)" json:"cve_id"`
+	// The unix timestamp after which the item is not effective, 0 means never expires
+	ExpiresAt    int64     `orm:"column(expires_at)" json:"expires_at"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+}
+
+// TableName is required by beego orm to map CVEAllowlistItem to table cve_allowlist
+func (c *CVEAllowlistItem) TableName() string {
+	return CVEAllowlistTable
+}
+
+// Expired returns whether the item is expired at the time
+func (c *CVEAllowlistItem) Expired(t time.Time) bool {
+	return c.ExpiresAt > 0 && t.Unix() >= c.ExpiresAt
+}
+
+// CVEAllowlist is the CVE allowlist of the system or a project
+type CVEAllowlist struct {
+	ProjectID int64               `json:"project_id"`
+	Items     []*CVEAllowlistItem `json:"items"`
+}
+
+// Validate the allowlist, the CVE IDs are normalized to upper case
+func (c *CVEAllowlist) Validate() error {
+	ids := map[string]bool{}
+	for _, item := range c.Items {
+		if item == nil {
+			return fmt.Errorf("empty item")
+		}
+		item.CVEID = strings.ToUpper(strings.TrimSpace(item.CVEID))
+		if len(item.CVEID) == 0 {
+			return fmt.Errorf("empty cve_id")
+		}
+		if len(item.CVEID) > 255 {
+			return fmt.Errorf("cve_id %s is too long", item.CVEID)
+		}
+		if item.ExpiresAt < 0 {
+			return fmt.Errorf("invalid expires_at of %s: %d", item.CVEID, item.ExpiresAt)
+		}
+		if ids[item.CVEID] {
+			return fmt.Errorf("duplicate cve_id: %s", item.CVEID)
+		}
+		ids[item.CVEID] = true
+	}
+	return nil
+}
+
+// ActiveCVEs returns the IDs of the items not expired at the time in the allowlists
+func ActiveCVEs(t time.Time, lists ...*CVEAllowlist) map[string]bool {
+	ids := map[string]bool{}
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+		for _, item := range list.Items {
+			if !item.Expired(t) {
+				ids[strings.ToUpper(item.CVEID)] = true
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,66 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCVEAllowlist(t *testing.T) {
+	cases := []struct {
+		items []*CVEAllowlistItem
+		valid bool
+	}{
+		{nil, true},
+		{[]*CVEAllowlistItem{nil}, false},
+		{[]*CVEAllowlistItem{{CVEID: " "}}, false},
+		{[]*CVEAllowlistItem{{CVEID: "CVE-2019-0001", ExpiresAt: -1}}, false},
+		{[]*CVEAllowlistItem{{CVEID: "CVE-2019-0001"}, {CVEID: "cve-2019-0001 "}}, false},
+		{[]*CVEAllowlistItem{{CVEID: "CVE-2019-0001"}, {CVEID: "CVE-2019-0002", ExpiresAt: 1}}, true},
+	}
+	for i, c := range cases {
+		err := (&CVEAllowlist{Items: c.items}).Validate()
+		assert.Equal(t, c.valid, err == nil, "case %d: %v", i, err)
+	}
+
+	list := &CVEAllowlist{Items: []*CVEAllowlistItem{{CVEID: " cve-2019-0001"}}}
+	assert.Nil(t, list.Validate())
+	assert.Equal(t, "CVE-2019-0001", list.Items[0].CVEID)
+}
+
+func TestActiveCVEs(t *testing.T) {
+	now := time.Now()
+	system := &CVEAllowlist{
+		Items: []*CVEAllowlistItem{
+			{CVEID: "CVE-2019-0001"},
+			{CVEID: "CVE-2019-0002", ExpiresAt: now.Add(-time.Hour).Unix()},
+		},
+	}
+	project := &CVEAllowlist{
+		ProjectID: 1,
+		Items: []*CVEAllowlistItem{
+			{CVEID: "CVE-2019-0003", ExpiresAt: now.Add(time.Hour).Unix()},
+		},
+	}
+	ids := ActiveCVEs(now, system, nil, project)
+	assert.Equal(t, map[string]bool{
+		"CVE-2019-0001": true,
+		"CVE-2019-0003": true,
+	}, ids)
+	assert.Equal(t, 0, len(ActiveCVEs(now)))
+}
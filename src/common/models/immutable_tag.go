@@ -0,0 +1,79 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ImmutableTagRule makes the tags matching the patterns immutable: they can't be
+// overwritten by pushing, deleted or retagged onto once they exist.
+// The patterns are in the syntax of path.Match, empty pattern matches anything.
+type ImmutableTagRule struct {
+	// The pattern of the repository name without the project name, e.g. "app", "team/*"
+	Repository string `json:"repository"`
+	// The pattern of the tag, e.g. "v*"
+	Tag string `json:"tag"`
+}
+
+// Validate the patterns of the rule
+func (r *ImmutableTagRule) Validate() error {
+	if len(r.Repository) == 0 && len(r.Tag) == 0 {
+		return fmt.Errorf("at least one of the repository and tag patterns must be set")
+	}
+	for _, pattern := range []string{r.Repository, r.Tag} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %s: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Match returns whether the tag of the repository matches the rule,
+// the repository name passed in doesn't contain the project name
+func (r *ImmutableTagRule) Match(repository, tag string) bool {
+	return matchPattern(r.Repository, repository) && matchPattern(r.Tag, tag)
+}
+
+func matchPattern(pattern, name string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// ParseImmutableTagRules parses and validates the rules stored in project metadata
+func ParseImmutableTagRules(value string) ([]*ImmutableTagRule, error) {
+	rules := []*ImmutableTagRule{}
+	if len(strings.TrimSpace(value)) == 0 {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("invalid immutable tag rules %s: %v", value, err)
+	}
+	for _, rule := range rules {
+		if rule == nil {
+			return nil, fmt.Errorf("invalid immutable tag rules %s: null rule", value)
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,73 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImmutableTagRules(t *testing.T) {
+	rules, err := ParseImmutableTagRules("")
+	require.Nil(t, err)
+	assert.Equal(t, 0, len(rules))
+
+	rules, err = ParseImmutableTagRules(`[{"repository":"app","tag":"v*"},{"tag":"release-*"}]`)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(rules))
+	assert.Equal(t, "app", rules[0].Repository)
+	assert.Equal(t, "release-*", rules[1].Tag)
+
+	// invalid json
+	_, err = ParseImmutableTagRules(`{"tag":"v*"}`)
+	assert.NotNil(t, err)
+
+	// empty rule
+	_, err = ParseImmutableTagRules(`[{}]`)
+	assert.NotNil(t, err)
+
+	// invalid pattern
+	_, err = ParseImmutableTagRules(`[{"tag":"v[1"}]`)
+	assert.NotNil(t, err)
+}
+
+func TestIsTagImmutable(t *testing.T) {
+	project := &Project{
+		Name: "library",
+	}
+	assert.False(t, project.IsTagImmutable("library/app", "v1.0.0"))
+
+	project.SetMetadata(ProMetaImmutableTags, `[{"repository":"app","tag":"v*"},{"repository":"team/*","tag":"latest"}]`)
+	cases := []struct {
+		repository string
+		tag        string
+		immutable  bool
+	}{
+		{"library/app", "v1.0.0", true},
+		{"library/app", "latest", false},
+		{"library/web", "v1.0.0", false},
+		{"library/team/web", "latest", true},
+		{"library/team/web/api", "latest", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.immutable, project.IsTagImmutable(c.repository, c.tag), "%s:%s", c.repository, c.tag)
+	}
+
+	// the invalid rules are ignored
+	project.SetMetadata(ProMetaImmutableTags, "invalid")
+	assert.False(t, project.IsTagImmutable("library/app", "v1.0.0"))
+}
@@ -0,0 +1,56 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+// OIDCUserTable is the name of table in DB that holds the OIDC user object
+const OIDCUserTable = "oidc_user"
+
+// OIDCSetting wraps the configurations to access the OIDC provider
+type OIDCSetting struct {
+	Name         string
+	Endpoint     string
+	ClientID     string
+	ClientSecret string
+	VerifyCert   bool
+	Scope        []string
+	RedirectURL  string
+	GroupsClaim  string
+	AdminGroup   string
+}
+
+// OIDCUser links a Harbor user to the identity of the OIDC provider, the CLI secret is
+// the password of the user for docker CLI as docker can't do the authorization code flow
+type OIDCUser struct {
+	ID     int64 `orm:"pk;auto;column(id)" json:"id"`
+	UserID int   `orm:"column(user_id)" json:"user_id"`
+	// the combination of the subject and issuer of the ID token, unique across providers
+	SubIss string `orm:"column(subiss)" json:"-"`
+	Secret string `orm:"column(secret)" json:"-"`
+	Salt   string `orm:"column(salt)" json:"-"`
+	// the JSON encoded group names from the latest ID token
+	GroupsDB     string    `orm:"column(groups)" json:"-"`
+	Groups       []string  `orm:"-" json:"groups"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (o *OIDCUser) TableName() string {
+	return OIDCUserTable
+}
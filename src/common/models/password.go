@@ -0,0 +1,144 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode"
+)
+
+const (
+	// PasswordHistoryTable is the name of table in DB that holds the previous passwords of the users
+	PasswordHistoryTable = "password_history"
+	// UserLockoutTable is the name of table in DB that holds the login failures of the users
+	UserLockoutTable = "user_lockout"
+	// PasswordMaxLength is the max length of the passwords of the database users
+	PasswordMaxLength = 20
+	// AdminLockoutDuration is the minutes the system admin is locked for when the other users are locked
+	// until they're unlocked, otherwise anyone could lock the admin permanently by the login failures
+	AdminLockoutDuration = 30
+)
+
+// PasswordPolicy is the policy on the passwords and the logins of the database users,
+// the zero values of HistoryCount, MaxAge and MaxLoginFailures disable the related checks
+type PasswordPolicy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireNumber    bool
+	RequireSpecial   bool
+	// the count of the previous passwords can't be reused besides the current one
+	HistoryCount int
+	// in days
+	MaxAge           int
+	MaxLoginFailures int
+	// in minutes, 0 means the user is locked until the system admin unlocks it,
+	// the system admin itself is locked for AdminLockoutDuration in this case
+	LockoutDuration int
+}
+
+// Validate checks the length and the character classes of the password
+func (p *PasswordPolicy) Validate(password string) error {
+	if len(password) < p.MinLength || len(password) > PasswordMaxLength {
+		return fmt.Errorf("the length of the password must be between %d and %d", p.MinLength, PasswordMaxLength)
+	}
+	var upper, lower, number, special bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			upper = true
+		case unicode.IsLower(c):
+			lower = true
+		case unicode.IsDigit(c):
+			number = true
+		default:
+			special = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		return errors.New("the password must contain at least one uppercase letter")
+	}
+	if p.RequireLowercase && !lower {
+		return errors.New("the password must contain at least one lowercase letter")
+	}
+	if p.RequireNumber && !number {
+		return errors.New("the password must contain at least one number")
+	}
+	if p.RequireSpecial && !special {
+		return errors.New("the password must contain at least one special character")
+	}
+	return nil
+}
+
+// PasswordExpired returns whether the password changed at the time is expired at now
+func (p *PasswordPolicy) PasswordExpired(changedTime, now time.Time) bool {
+	if p.MaxAge <= 0 {
+		return false
+	}
+	return now.After(changedTime.AddDate(0, 0, p.MaxAge))
+}
+
+// LockoutDurationOf returns the minutes the user is locked for since the last login failure,
+// 0 means the user is locked until the system admin unlocks it
+func (p *PasswordPolicy) LockoutDurationOf(userID int) int {
+	if p.LockoutDuration <= 0 && userID == 1 {
+		return AdminLockoutDuration
+	}
+	if p.LockoutDuration < 0 {
+		return 0
+	}
+	return p.LockoutDuration
+}
+
+// Locked returns whether the user is locked at now according to the login failures
+func (p *PasswordPolicy) Locked(lockout *UserLockout, now time.Time) bool {
+	if p.MaxLoginFailures <= 0 || lockout == nil || !lockout.Locked {
+		return false
+	}
+	duration := p.LockoutDurationOf(lockout.UserID)
+	if duration == 0 {
+		return true
+	}
+	return now.Before(lockout.LastFailureTime.Add(time.Duration(duration) * time.Minute))
+}
+
+// PasswordHistory is a previous password of the user
+type PasswordHistory struct {
+	ID           int64     `orm:"pk;auto;column(id)"`
+	UserID       int       `orm:"column(user_id)"`
+	Password     string    `orm:"column(password)"`
+	Salt         string    `orm:"column(salt)"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add"`
+}
+
+// TableName ...
+func (p *PasswordHistory) TableName() string {
+	return PasswordHistoryTable
+}
+
+// UserLockout holds the consecutive login failures of the user
+type UserLockout struct {
+	UserID          int       `orm:"pk;column(user_id)" json:"user_id"`
+	Failures        int       `orm:"column(failures)" json:"failures"`
+	Locked          bool      `orm:"column(locked)" json:"locked"`
+	LastFailureTime time.Time `orm:"column(last_failure_time)" json:"last_failure_time"`
+}
+
+// TableName ...
+func (u *UserLockout) TableName() string {
+	return UserLockoutTable
+}
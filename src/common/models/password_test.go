@@ -0,0 +1,83 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePassword(t *testing.T) {
+	policy := &PasswordPolicy{
+		MinLength:        10,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumber:    true,
+	}
+	cases := []struct {
+		password string
+		valid    bool
+	}{
+		{"Harbor123", false},
+		{"Harbor12345678901234X", false},
+		{"harbor12345", false},
+		{"HARBOR12345", false},
+		{"HarborHarbor", false},
+		{"Harbor12345", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.valid, policy.Validate(c.password) == nil, c.password)
+	}
+
+	policy.RequireSpecial = true
+	assert.NotNil(t, policy.Validate("Harbor12345"))
+	assert.Nil(t, policy.Validate("Harbor_12345"))
+}
+
+func TestPasswordExpired(t *testing.T) {
+	now := time.Now()
+	policy := &PasswordPolicy{}
+	assert.False(t, policy.PasswordExpired(now.AddDate(-10, 0, 0), now))
+
+	policy.MaxAge = 90
+	assert.False(t, policy.PasswordExpired(now.AddDate(0, 0, -89), now))
+	assert.True(t, policy.PasswordExpired(now.AddDate(0, 0, -91), now))
+}
+
+func TestLocked(t *testing.T) {
+	now := time.Now()
+	lockout := &UserLockout{Failures: 5, Locked: true, LastFailureTime: now.Add(-10 * time.Minute)}
+
+	// the lockout is disabled
+	policy := &PasswordPolicy{LockoutDuration: 30}
+	assert.False(t, policy.Locked(lockout, now))
+
+	policy.MaxLoginFailures = 5
+	assert.False(t, policy.Locked(nil, now))
+	assert.False(t, policy.Locked(&UserLockout{Failures: 4}, now))
+	assert.True(t, policy.Locked(lockout, now))
+	assert.False(t, policy.Locked(lockout, now.Add(30*time.Minute)))
+
+	// locked until the system admin unlocks the user
+	policy.LockoutDuration = 0
+	assert.True(t, policy.Locked(lockout, now.AddDate(1, 0, 0)))
+
+	// the system admin is never locked permanently
+	lockout.UserID = 1
+	assert.True(t, policy.Locked(lockout, now))
+	assert.False(t, policy.Locked(lockout, now.Add(AdminLockoutDuration*time.Minute)))
+}
@@ -0,0 +1,123 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/validation"
+)
+
+const (
+	// PersonalAccessTokenTable is the name of table in DB that holds the personal access tokens
+	PersonalAccessTokenTable = "personal_access_token"
+	// PersonalAccessTokenPrefix is the prefix of the personal access tokens, the ID and the secret
+	// of the token follow it, e.g. "hpat_1_secret"
+	PersonalAccessTokenPrefix = "hpat_"
+	// PersonalAccessTokenNeverExpire is the expiration of the token which never expires
+	PersonalAccessTokenNeverExpire int64 = -1
+)
+
+// PersonalAccessToken is a token of the user which can be used as the password for the API and
+// docker CLI, the permissions of the user are limited to the access if it's specified
+type PersonalAccessToken struct {
+	ID       int64              `orm:"pk;auto;column(id)" json:"id"`
+	UserID   int                `orm:"column(user_id)" json:"user_id"`
+	Name     string             `orm:"column(name)" json:"name"`
+	Token    string             `orm:"column(token)" json:"-"`
+	Salt     string             `orm:"column(salt)" json:"-"`
+	AccessDB string             `orm:"column(access)" json:"-"`
+	Access   []*ResourceActions `orm:"-" json:"access"`
+	// the unix timestamp when the token expires, -1 means never
+	ExpiresAt int64 `orm:"column(expires_at)" json:"expires_at"`
+	// zero means the token has never been used
+	LastUsedTime time.Time `orm:"column(last_used_time);null" json:"last_used_time"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (p *PersonalAccessToken) TableName() string {
+	return PersonalAccessTokenTable
+}
+
+// Expired returns whether the token is expired at the specified time
+func (p *PersonalAccessToken) Expired(now time.Time) bool {
+	return p.ExpiresAt != PersonalAccessTokenNeverExpire && p.ExpiresAt <= now.Unix()
+}
+
+// Scoped returns whether the permissions of the user are limited to the access of the token
+func (p *PersonalAccessToken) Scoped() bool {
+	return len(p.Access) > 0
+}
+
+// PersonalAccessTokenReq is the request to create a personal access token
+type PersonalAccessTokenReq struct {
+	Name string `json:"name"`
+	// the unix timestamp when the token expires, -1 means never
+	ExpiresAt int64 `json:"expires_at"`
+	// in the same format as the access of the robot accounts, the token has all the permissions
+	// of the user if it's empty
+	Access []*ResourceActions `json:"access"`
+}
+
+// Valid ...
+func (pr *PersonalAccessTokenReq) Valid(v *validation.Validation) {
+	if len(strings.TrimSpace(pr.Name)) == 0 || len(pr.Name) > 255 {
+		v.SetError("name", "the length of the name must be between 1 and 255")
+	}
+	if pr.ExpiresAt != PersonalAccessTokenNeverExpire && pr.ExpiresAt <= time.Now().Unix() {
+		v.SetError("expires_at", "the expiration must be -1 or a time in the future")
+	}
+	for _, access := range pr.Access {
+		if _, _, err := ParseRobotAccess(access); err != nil {
+			v.SetError("access", err.Error())
+			return
+		}
+	}
+}
+
+// PersonalAccessTokenRep is the response of the creation, the token is only returned in it
+type PersonalAccessTokenRep struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// FormatPersonalAccessToken returns the token presented to the user by the ID and the secret
+func FormatPersonalAccessToken(id int64, secret string) string {
+	return fmt.Sprintf("%s%d_%s", PersonalAccessTokenPrefix, id, secret)
+}
+
+// ParsePersonalAccessToken parses the ID and the secret of the token presented by the user,
+// ok is false if the token isn't in the format of the personal access tokens
+func ParsePersonalAccessToken(token string) (id int64, secret string, ok bool) {
+	if !strings.HasPrefix(token, PersonalAccessTokenPrefix) {
+		return 0, "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(token, PersonalAccessTokenPrefix), "_", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return 0, "", false
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, "", false
+	}
+	return id, parts[1], true
+}
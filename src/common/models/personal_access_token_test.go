@@ -0,0 +1,69 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersonalAccessTokenFormat(t *testing.T) {
+	token := FormatPersonalAccessToken(12, "secret_with_underscore")
+	assert.Equal(t, "hpat_12_secret_with_underscore", token)
+	id, secret, ok := ParsePersonalAccessToken(token)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), id)
+	assert.Equal(t, "secret_with_underscore", secret)
+
+	for _, invalid := range []string{"Harbor12345", "hpat_", "hpat_12", "hpat_12_", "hpat_abc_secret", "hpat_0_secret"} {
+		_, _, ok = ParsePersonalAccessToken(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestPersonalAccessTokenExpired(t *testing.T) {
+	now := time.Now()
+	token := &PersonalAccessToken{ExpiresAt: PersonalAccessTokenNeverExpire}
+	assert.False(t, token.Expired(now))
+	token.ExpiresAt = now.Add(time.Hour).Unix()
+	assert.False(t, token.Expired(now))
+	token.ExpiresAt = now.Add(-time.Hour).Unix()
+	assert.True(t, token.Expired(now))
+}
+
+func TestPersonalAccessTokenReqValid(t *testing.T) {
+	future := time.Now().Add(time.Hour).Unix()
+	cases := []struct {
+		req   *PersonalAccessTokenReq
+		valid bool
+	}{
+		{&PersonalAccessTokenReq{Name: "", ExpiresAt: future}, false},
+		{&PersonalAccessTokenReq{Name: "ci", ExpiresAt: 0}, false},
+		{&PersonalAccessTokenReq{Name: "ci", ExpiresAt: time.Now().Add(-time.Hour).Unix()}, false},
+		{&PersonalAccessTokenReq{Name: "ci", ExpiresAt: future,
+			Access: []*ResourceActions{{Name: "/project/1/unknown", Actions: []string{"pull"}}}}, false},
+		{&PersonalAccessTokenReq{Name: "ci", ExpiresAt: PersonalAccessTokenNeverExpire}, true},
+		{&PersonalAccessTokenReq{Name: "ci", ExpiresAt: future,
+			Access: []*ResourceActions{{Name: "/project/1/image", Actions: []string{"pull"}}}}, true},
+	}
+	for i, c := range cases {
+		v := &validation.Validation{}
+		c.req.Valid(v)
+		assert.Equal(t, c.valid, !v.HasErrors(), "case %d", i)
+	}
+}
@@ -25,6 +25,10 @@ const (
 	ProMetaPreventVul         = "prevent_vul" // prevent vulnerable images from being pulled
 	ProMetaSeverity           = "severity"
 	ProMetaAutoScan           = "auto_scan"
+	ProMetaImmutableTags      = "immutable_tags"     // the immutable tag rules in json format
+	ProMetaScanner            = "scanner"            // the ID of the scanner registration picked by the project
+	ProMetaProxyCacheTarget   = "proxy_cache_target" // the ID of the replication target cached by the proxy-cache project
+	ProMetaProxyCacheTTL      = "proxy_cache_ttl"    // the seconds after which the cached tags are revalidated
 	SeverityNone              = "negligible"
 	SeverityLow               = "low"
 	SeverityMedium            = "medium"
@@ -15,6 +15,7 @@
 package models
 
 import (
+	"strconv"
 	"strings"
 	"time"
 )
@@ -101,6 +102,77 @@ func (p *Project) AutoScan() bool {
 	return isTrue(auto)
 }
 
+// ScannerID returns the ID of the scanner registration picked by the project, 0 if the project
+// uses the default scanner
+func (p *Project) ScannerID() int64 {
+	value, exist := p.GetMetadata(ProMetaScanner)
+	if !exist {
+		return 0
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// IsProxyCache returns whether the project is a pull-through cache of a replication target
+func (p *Project) IsProxyCache() bool {
+	return p.ProxyCacheTargetID() > 0
+}
+
+// ProxyCacheTargetID returns the ID of the replication target cached by the project, 0 if the project isn't a proxy cache
+func (p *Project) ProxyCacheTargetID() int64 {
+	value, exist := p.GetMetadata(ProMetaProxyCacheTarget)
+	if !exist {
+		return 0
+	}
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// ProxyCacheTTL returns the duration after which the tags cached by the project are revalidated,
+// DefaultProxyCacheTTL is returned if it isn't set or invalid
+func (p *Project) ProxyCacheTTL() time.Duration {
+	value, exist := p.GetMetadata(ProMetaProxyCacheTTL)
+	if !exist {
+		return DefaultProxyCacheTTL
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return DefaultProxyCacheTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ImmutableTagRules returns the immutable tag rules of the project, the invalid rules are ignored
+func (p *Project) ImmutableTagRules() []*ImmutableTagRule {
+	value, exist := p.GetMetadata(ProMetaImmutableTags)
+	if !exist {
+		return nil
+	}
+	rules, err := ParseImmutableTagRules(value)
+	if err != nil {
+		return nil
+	}
+	return rules
+}
+
+// IsTagImmutable returns whether the tag of the repository is immutable,
+// the repository name passed in contains the project name
+func (p *Project) IsTagImmutable(repository, tag string) bool {
+	repository = strings.TrimPrefix(repository, p.Name+"/")
+	for _, rule := range p.ImmutableTagRules() {
+		if rule.Match(repository, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 func isTrue(value string) bool {
 	return strings.ToLower(value) == "true" ||
 		strings.ToLower(value) == "1"
@@ -0,0 +1,49 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	// ProxyCacheTagTable is the name of table in DB that holds the tags cached by the proxy-cache projects
+	ProxyCacheTagTable = "proxy_cache_tag"
+	// DefaultProxyCacheTTL is the duration after which the cached tags are revalidated if the project doesn't set it
+	DefaultProxyCacheTTL = time.Hour
+)
+
+// ProxyCacheTag is a tag cached from the upstream registry by a proxy-cache project
+type ProxyCacheTag struct {
+	ID         int64  `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID  int64  `orm:"column(project_id)" json:"project_id"`
+	Repository string `orm:"column(repository)" json:"repository"`
+	Tag        string `orm:"column(tag)" json:"tag"`
+	// The digest resolved from the upstream registry when the tag was cached or revalidated
+	Digest       string    `orm:"column(digest)" json:"digest"`
+	CreationTime time.Time `orm:"column(creation_time)" json:"creation_time"`
+	// The time when the tag was cached or revalidated at the last time
+	UpdateTime time.Time `orm:"column(update_time)" json:"update_time"`
+}
+
+// TableName ...
+func (p *ProxyCacheTag) TableName() string {
+	return ProxyCacheTagTable
+}
+
+// Expired returns whether the tag needs to be revalidated at the time
+func (p *ProxyCacheTag) Expired(ttl time.Duration, t time.Time) bool {
+	return !p.UpdateTime.Add(ttl).After(t)
+}
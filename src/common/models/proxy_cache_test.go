@@ -0,0 +1,51 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyCacheTagExpired(t *testing.T) {
+	now := time.Now()
+	tag := &ProxyCacheTag{UpdateTime: now.Add(-30 * time.Minute)}
+	assert.False(t, tag.Expired(time.Hour, now))
+	assert.True(t, tag.Expired(30*time.Minute, now))
+	assert.True(t, tag.Expired(time.Minute, now))
+}
+
+func TestProjectProxyCache(t *testing.T) {
+	p := &Project{}
+	assert.False(t, p.IsProxyCache())
+	assert.Equal(t, DefaultProxyCacheTTL, p.ProxyCacheTTL())
+
+	p.Metadata = map[string]string{
+		ProMetaProxyCacheTarget: "invalid",
+		ProMetaProxyCacheTTL:    "-1",
+	}
+	assert.False(t, p.IsProxyCache())
+	assert.Equal(t, DefaultProxyCacheTTL, p.ProxyCacheTTL())
+
+	p.Metadata = map[string]string{
+		ProMetaProxyCacheTarget: "2",
+		ProMetaProxyCacheTTL:    "600",
+	}
+	assert.True(t, p.IsProxyCache())
+	assert.Equal(t, int64(2), p.ProxyCacheTargetID())
+	assert.Equal(t, 10*time.Minute, p.ProxyCacheTTL())
+}
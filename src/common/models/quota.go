@@ -0,0 +1,91 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+)
+
+const (
+	// ProjectQuotaTable is the name of table in DB that holds the hard limits of projects
+	ProjectQuotaTable = "project_quota"
+	// ArtifactTable is the name of table in DB that holds the tags used to account the quota usage
+	ArtifactTable = "artifact"
+)
+
+// ProjectQuota holds the hard limits of a project, -1 means unlimited
+type ProjectQuota struct {
+	ID           int64     `orm:"pk;auto;column(id)" json:"-"`
+	ProjectID    int64     `orm:"column(project_id)" json:"-"`
+	StorageLimit int64     `orm:"column(storage_limit)" json:"storage"`
+	CountLimit   int64     `orm:"column(count_limit)" json:"count"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"-"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"-"`
+}
+
+// TableName ...
+func (p *ProjectQuota) TableName() string {
+	return ProjectQuotaTable
+}
+
+// Artifact is a tag pushed to the project, the size is the sum of the manifest, config and layers of the image
+type Artifact struct {
+	ID           int64     `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID    int64     `orm:"column(project_id)" json:"project_id"`
+	Repo         string    `orm:"column(repo)" json:"repo"`
+	Tag          string    `orm:"column(tag)" json:"tag"`
+	Digest       string    `orm:"column(digest)" json:"digest"`
+	Size         int64     `orm:"column(size)" json:"size"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (a *Artifact) TableName() string {
+	return ArtifactTable
+}
+
+// QuotaResources holds the amount of the resources limited by quota
+type QuotaResources struct {
+	// The storage in bytes, the images sharing the same digest are counted once
+	Storage int64 `json:"storage"`
+	// The number of tags
+	Count int64 `json:"count"`
+}
+
+// Valid checks whether the hard limits are valid
+func (q *QuotaResources) Valid() error {
+	if q.Storage < common.QuotaUnlimited {
+		return fmt.Errorf("invalid storage limit: %d", q.Storage)
+	}
+	if q.Count < common.QuotaUnlimited {
+		return fmt.Errorf("invalid count limit: %d", q.Count)
+	}
+	return nil
+}
+
+// QuotaSummary holds the hard limits and the usage of a project
+type QuotaSummary struct {
+	Hard *QuotaResources `json:"hard"`
+	Used *QuotaResources `json:"used"`
+}
+
+// ProjectSummary is the summary of a project returned by the API
+type ProjectSummary struct {
+	Quota *QuotaSummary `json:"quota"`
+}
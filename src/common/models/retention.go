@@ -0,0 +1,137 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+const (
+	// RetentionPolicyTable is the name of table in DB that holds the retention policies
+	RetentionPolicyTable = "retention_policy"
+	// RetentionExecutionTable is the name of table in DB that holds the executions of retention policies
+	RetentionExecutionTable = "retention_execution"
+
+	// RetentionTriggerManual : the execution is triggered manually
+	RetentionTriggerManual = "Manual"
+	// RetentionTriggerSchedule : the execution is triggered by the schedule of the policy
+	RetentionTriggerSchedule = "Schedule"
+)
+
+// RetentionRules decide which tags of the repositories in the project are retained,
+// the tags not retained by any of the rules are deleted.
+type RetentionRules struct {
+	// Keep the latest N pushed tags of each repository, 0 means the rule is disabled
+	KeepLatest int `json:"keep_latest"`
+	// Keep the tags pushed or pulled in the last N days, 0 means the rule is disabled
+	KeepDays int `json:"keep_days"`
+	// Always keep the tags matching any of the patterns, e.g. "v*", "release-*"
+	KeepPatterns []string `json:"keep_patterns"`
+	// Always keep the tags attached with any of the labels
+	KeepLabels []int64 `json:"keep_labels"`
+}
+
+// Validate the rules, at least one rule must be set to avoid deleting all the tags by mistake
+func (r *RetentionRules) Validate() error {
+	if r.KeepLatest < 0 {
+		return fmt.Errorf("invalid keep_latest: %d", r.KeepLatest)
+	}
+	if r.KeepDays < 0 {
+		return fmt.Errorf("invalid keep_days: %d", r.KeepDays)
+	}
+	for _, pattern := range r.KeepPatterns {
+		if len(pattern) == 0 {
+			return fmt.Errorf("empty pattern in keep_patterns")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %s in keep_patterns: %v", pattern, err)
+		}
+	}
+	if r.KeepLatest == 0 && r.KeepDays == 0 &&
+		len(r.KeepPatterns) == 0 && len(r.KeepLabels) == 0 {
+		return fmt.Errorf("at least one retention rule must be set")
+	}
+
+	return nil
+}
+
+// RetentionPolicy holds the tag retention policy of a project
+type RetentionPolicy struct {
+	ID        int64 `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID int64 `orm:"column(project_id)" json:"project_id"`
+	// The rules in json format
+	Rules string `orm:"column(rules)" json:"-"`
+	// The schedule in json format, empty if the policy is only run manually
+	Cron string `orm:"column(cron_str)" json:"-"`
+	// The ID of the periodic job in jobservice
+	JobUUID      string    `orm:"column(job_uuid)" json:"-"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (r *RetentionPolicy) TableName() string {
+	return RetentionPolicyTable
+}
+
+// RetentionExecution is one run of the retention policy
+type RetentionExecution struct {
+	ID        int64  `orm:"pk;auto;column(id)" json:"id"`
+	PolicyID  int64  `orm:"column(policy_id)" json:"policy_id"`
+	ProjectID int64  `orm:"column(project_id)" json:"project_id"`
+	DryRun    bool   `orm:"column(dry_run)" json:"dry_run"`
+	Trigger   string `orm:"column(trigger_type)" json:"trigger"`
+	Status    string `orm:"column(status)" json:"status"`
+	JobUUID   string `orm:"column(job_uuid)" json:"-"`
+	// The report in json format checked in by the job
+	Report       string    `orm:"column(report)" json:"-"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (r *RetentionExecution) TableName() string {
+	return RetentionExecutionTable
+}
+
+// RetentionExecutionQuery : query parameters for the executions of retention policies
+type RetentionExecutionQuery struct {
+	PolicyID  int64
+	ProjectID int64
+	Pagination
+}
+
+// RetentionReport is the result of one run of the retention policy
+type RetentionReport struct {
+	DryRun       bool `json:"dry_run"`
+	Repositories int  `json:"repositories"`
+	Tags         int  `json:"tags"`
+	Retained     int  `json:"retained"`
+	// The tags deleted, or the tags would be deleted in dry run
+	Deleted []*RetentionCandidate `json:"deleted"`
+	// The tags failed to be deleted
+	Failed []*RetentionCandidate `json:"failed"`
+}
+
+// RetentionCandidate is the tag not retained by the rules
+type RetentionCandidate struct {
+	Repository string     `json:"repository"`
+	Tag        string     `json:"tag"`
+	PushedAt   *time.Time `json:"pushed_at,omitempty"`
+	PulledAt   *time.Time `json:"pulled_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
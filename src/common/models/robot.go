@@ -15,23 +15,57 @@
 package models
 
 import (
-	"github.com/astaxie/beego/validation"
+	"fmt"
+	"regexp"
+	"strconv"
 	"time"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
 )
 
-// RobotTable is the name of table in DB that holds the robot object
-const RobotTable = "robot"
+const (
+	// RobotTable is the name of table in DB that holds the robot object
+	RobotTable = "robot"
+	// RobotNeverExpire is the expiration of the robot whose token never expires
+	RobotNeverExpire int64 = -1
+)
+
+var (
+	robotAccessPattern = regexp.MustCompile(`^/project/([0-9]+)/([a-z-]+)$`)
+	// the actions can be granted to a robot on the subresources of a project
+	robotAccessActions = map[rbac.Resource][]rbac.Action{
+		project.ResourceImage:     {project.ActionPull, project.ActionPush},
+		project.ResourceHelmChart: {project.ActionRead, project.ActionCreate, project.ActionDelete},
+		project.ResourceHelmRepo:  {project.ActionRead},
+	}
+)
 
 // Robot holds the details of a robot.
 type Robot struct {
-	ID           int64     `orm:"pk;auto;column(id)" json:"id"`
-	Name         string    `orm:"column(name)" json:"name"`
-	Token        string    `orm:"column(token)" json:"token"`
-	Description  string    `orm:"column(description)" json:"description"`
-	ProjectID    int64     `orm:"column(project_id)" json:"project_id"`
-	Disabled     bool      `orm:"column(disabled)" json:"disabled"`
-	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
-	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+	ID           int64              `orm:"pk;auto;column(id)" json:"id"`
+	Name         string             `orm:"column(name)" json:"name"`
+	Token        string             `orm:"column(token)" json:"-"`
+	Salt         string             `orm:"column(salt)" json:"-"`
+	Description  string             `orm:"column(description)" json:"description"`
+	ProjectID    int64              `orm:"column(project_id)" json:"project_id"`
+	Disabled     bool               `orm:"column(disabled)" json:"disabled"`
+	ExpiresAt    int64              `orm:"column(expires_at)" json:"expires_at"`
+	AccessDB     string             `orm:"column(access)" json:"-"`
+	Access       []*ResourceActions `orm:"-" json:"access"`
+	CreationTime time.Time          `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time          `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// IsSystem returns whether the robot is a system level one which isn't bound to a project
+func (r *Robot) IsSystem() bool {
+	return r.ProjectID == 0
+}
+
+// Expired returns whether the token of the robot is expired at the specified time
+func (r *Robot) Expired(now time.Time) bool {
+	return r.ExpiresAt != RobotNeverExpire && r.ExpiresAt <= now.Unix()
 }
 
 // RobotQuery ...
@@ -40,26 +74,99 @@ type RobotQuery struct {
 	ProjectID      int64
 	Disabled       bool
 	FuzzyMatchName bool
+	// only list the system level robots
+	SystemLevel bool
 	Pagination
 }
 
 // RobotReq ...
 type RobotReq struct {
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	Disabled    bool               `json:"disabled"`
-	Access      []*ResourceActions `json:"access"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Disabled    bool   `json:"disabled"`
+	// the unix timestamp when the token expires, -1 means never,
+	// the system default duration is used if it's not set
+	ExpiresAt int64              `json:"expires_at"`
+	Access    []*ResourceActions `json:"access"`
 }
 
 // Valid put request validation
 func (rq *RobotReq) Valid(v *validation.Validation) {
-	// ToDo: add validation for access info.
+	if rq.ExpiresAt < RobotNeverExpire {
+		v.SetError("expires_at", "invalid expiration")
+	}
+	for _, access := range rq.Access {
+		if _, _, err := ParseRobotAccess(access); err != nil {
+			v.SetError("access", err.Error())
+			return
+		}
+	}
+}
+
+// ProjectIDs returns the IDs of the projects the requested access covers
+func (rq *RobotReq) ProjectIDs() []int64 {
+	return ProjectIDsOfAccess(rq.Access)
+}
+
+// ProjectIDsOfAccess returns the IDs of the projects the access in the format of the robot accounts covers
+func ProjectIDsOfAccess(accesses []*ResourceActions) []int64 {
+	ids := []int64{}
+	seen := map[int64]bool{}
+	for _, access := range accesses {
+		id, _, err := ParseRobotAccess(access)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ParseRobotAccess parses the access granted to a robot, the name of the access must be
+// in the format "/project/<project ID>/<resource>", e.g. "/project/1/image"
+func ParseRobotAccess(access *ResourceActions) (int64, rbac.Resource, error) {
+	if access == nil {
+		return 0, "", fmt.Errorf("empty access")
+	}
+	matches := robotAccessPattern.FindStringSubmatch(access.Name)
+	if len(matches) != 3 {
+		return 0, "", fmt.Errorf("invalid resource: %s", access.Name)
+	}
+	projectID, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil || projectID <= 0 {
+		return 0, "", fmt.Errorf("invalid project in resource: %s", access.Name)
+	}
+	resource := rbac.Resource(matches[2])
+	allowed, exist := robotAccessActions[resource]
+	if !exist {
+		return 0, "", fmt.Errorf("unsupported resource: %s", access.Name)
+	}
+	if len(access.Actions) == 0 {
+		return 0, "", fmt.Errorf("no action specified for resource: %s", access.Name)
+	}
+	for _, action := range access.Actions {
+		if !containsAction(allowed, rbac.Action(action)) {
+			return 0, "", fmt.Errorf("unsupported action %s for resource: %s", action, access.Name)
+		}
+	}
+	return projectID, resource, nil
+}
+
+func containsAction(actions []rbac.Action, action rbac.Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
 }
 
 // RobotRep ...
 type RobotRep struct {
-	Name  string
-	Token string
+	Name      string
+	Token     string
+	ExpiresAt int64
 }
 
 // TableName ...
@@ -0,0 +1,69 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRobotAccess(t *testing.T) {
+	cases := []struct {
+		access    *ResourceActions
+		projectID int64
+		valid     bool
+	}{
+		{nil, 0, false},
+		{&ResourceActions{Name: "/project/library/image", Actions: []string{"pull"}}, 0, false},
+		{&ResourceActions{Name: "/project/0/image", Actions: []string{"pull"}}, 0, false},
+		{&ResourceActions{Name: "/project/1/unknown", Actions: []string{"pull"}}, 0, false},
+		{&ResourceActions{Name: "/project/1/image"}, 0, false},
+		{&ResourceActions{Name: "/project/1/image", Actions: []string{"read"}}, 0, false},
+		{&ResourceActions{Name: "/project/1/helm-repo", Actions: []string{"create"}}, 0, false},
+		{&ResourceActions{Name: "/project/1/image", Actions: []string{"pull", "push"}}, 1, true},
+		{&ResourceActions{Name: "/project/2/helm-chart", Actions: []string{"read", "create", "delete"}}, 2, true},
+		{&ResourceActions{Name: "/project/3/helm-repo", Actions: []string{"read"}}, 3, true},
+	}
+	for _, c := range cases {
+		projectID, _, err := ParseRobotAccess(c.access)
+		if !c.valid {
+			assert.NotNil(t, err)
+			continue
+		}
+		require.Nil(t, err)
+		assert.Equal(t, c.projectID, projectID)
+	}
+}
+
+func TestRobotReqProjectIDs(t *testing.T) {
+	req := &RobotReq{
+		Access: []*ResourceActions{
+			{Name: "/project/1/image", Actions: []string{"pull"}},
+			{Name: "/project/2/image", Actions: []string{"pull"}},
+			{Name: "/project/1/helm-repo", Actions: []string{"read"}},
+		},
+	}
+	assert.Equal(t, []int64{1, 2}, req.ProjectIDs())
+}
+
+func TestRobotExpired(t *testing.T) {
+	now := time.Now()
+	assert.False(t, (&Robot{ExpiresAt: RobotNeverExpire}).Expired(now))
+	assert.False(t, (&Robot{ExpiresAt: now.Add(time.Hour).Unix()}).Expired(now))
+	assert.True(t, (&Robot{ExpiresAt: now.Add(-time.Hour).Unix()}).Expired(now))
+}
@@ -14,6 +14,16 @@
 
 package models
 
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+)
+
 const (
 	// PROJECTADMIN project administrator
 	PROJECTADMIN = 1
@@ -23,6 +33,8 @@ const (
 	GUEST = 3
 )
 
+var roleNamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[._-][a-zA-Z0-9]+)*$`)
+
 // Role holds the details of a role.
 type Role struct {
 	RoleID   int    `orm:"pk;auto;column(role_id)" json:"role_id"`
@@ -30,4 +42,53 @@ type Role struct {
 	Name     string `orm:"column(name)" json:"role_name"`
 
 	RoleMask int `orm:"column(role_mask)" json:"role_mask"`
+
+	Description  string        `orm:"column(description)" json:"description"`
+	PoliciesDB   string        `orm:"column(policies)" json:"-"`
+	Policies     []*RolePolicy `orm:"-" json:"policies"`
+	BuiltIn      bool          `orm:"-" json:"built_in"`
+	CreationTime time.Time     `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time     `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// RolePolicy is an action allowed on a subresource of the project, the resource "" is the project itself
+type RolePolicy struct {
+	Resource rbac.Resource `json:"resource"`
+	Action   rbac.Action   `json:"action"`
+}
+
+// RoleReq is the request to create or update a custom role
+type RoleReq struct {
+	Name        string        `json:"role_name"`
+	Description string        `json:"description"`
+	Policies    []*RolePolicy `json:"policies"`
+}
+
+// Valid validates the name and the policies of the role
+func (r *RoleReq) Valid(v *validation.Validation) {
+	if len(r.Name) == 0 || len(r.Name) > 255 || !roleNamePattern.MatchString(r.Name) {
+		v.SetError("role_name", fmt.Sprintf("invalid role name: %s", r.Name))
+	}
+	if len(r.Policies) == 0 {
+		v.SetError("policies", "no policy specified")
+		return
+	}
+	for _, policy := range r.Policies {
+		if policy == nil || !project.IsValidPolicy(policy.Resource, policy.Action) {
+			v.SetError("policies", fmt.Sprintf("invalid policy: %+v", policy))
+			return
+		}
+	}
+}
+
+// RBACPolicies converts the policies of the role to the ones evaluated by the rbac package
+func (r *Role) RBACPolicies() []*rbac.Policy {
+	policies := []*rbac.Policy{}
+	for _, policy := range r.Policies {
+		policies = append(policies, &rbac.Policy{
+			Resource: policy.Resource,
+			Action:   policy.Action,
+		})
+	}
+	return policies
 }
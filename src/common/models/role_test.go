@@ -0,0 +1,56 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidRoleReq(t *testing.T) {
+	pull := &RolePolicy{Resource: project.ResourceImage, Action: project.ActionPull}
+	cases := []struct {
+		req   *RoleReq
+		valid bool
+	}{
+		{&RoleReq{Policies: []*RolePolicy{pull}}, false},
+		{&RoleReq{Name: "-scanner", Policies: []*RolePolicy{pull}}, false},
+		{&RoleReq{Name: strings.Repeat("a", 256), Policies: []*RolePolicy{pull}}, false},
+		{&RoleReq{Name: "scanner"}, false},
+		{&RoleReq{Name: "scanner", Policies: []*RolePolicy{nil}}, false},
+		{&RoleReq{Name: "scanner", Policies: []*RolePolicy{{Resource: project.ResourceImage, Action: project.ActionDelete}}}, false},
+		{&RoleReq{Name: "scanner", Policies: []*RolePolicy{{Resource: "unknown", Action: project.ActionRead}}}, false},
+		{&RoleReq{Name: "scanner", Policies: []*RolePolicy{pull, {Resource: project.ResourceScan, Action: project.ActionCreate}}}, true},
+		{&RoleReq{Name: "release.maintainer", Policies: []*RolePolicy{pull}}, true},
+	}
+	for _, c := range cases {
+		v := &validation.Validation{}
+		c.req.Valid(v)
+		assert.Equal(t, c.valid, !v.HasErrors(), "%+v", c.req)
+	}
+}
+
+func TestRoleRBACPolicies(t *testing.T) {
+	role := &Role{Policies: []*RolePolicy{{Resource: project.ResourceImage, Action: project.ActionPush}}}
+	policies := role.RBACPolicies()
+	if assert.Len(t, policies, 1) {
+		assert.Equal(t, project.ResourceImage, policies[0].Resource)
+		assert.Equal(t, project.ActionPush, policies[0].Action)
+	}
+}
@@ -74,6 +74,7 @@ type ImgScanOverview struct {
 	CompOverviewStr string              `orm:"column(components_overview)" json:"-"`
 	CompOverview    *ComponentsOverview `orm:"-" json:"components,omitempty"`
 	DetailsKey      string              `orm:"column(details_key)" json:"details_key"`
+	ScannerID       int64               `orm:"column(scanner_id)" json:"scanner_id"`
 	CreationTime    time.Time           `orm:"column(creation_time);auto_now_add" json:"creation_time,omitempty"`
 	UpdateTime      time.Time           `orm:"column(update_time);auto_now" json:"update_time,omitempty"`
 }
@@ -0,0 +1,73 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// ScannerRegistrationTable is the name of table in DB that holds the scanner registrations
+	ScannerRegistrationTable = "scanner_registration"
+	// BuiltinClairScannerURL is the URL of the registration of the built-in Clair scanner
+	BuiltinClairScannerURL = "builtin://clair"
+)
+
+// ScannerRegistration is a vulnerability scanner registered by URL
+type ScannerRegistration struct {
+	ID          int64  `orm:"pk;auto;column(id)" json:"id"`
+	Name        string `orm:"column(name)" json:"name"`
+	Description string `orm:"column(description)" json:"description"`
+	// The URL of the scanner adapter service
+	URL string `orm:"column(url)" json:"url"`
+	// The value of the "Authorization" header sent to the scanner adapter, e.g. "Bearer xxx"
+	AuthHeader     string    `orm:"column(auth_header)" json:"-"`
+	SkipCertVerify bool      `orm:"column(skip_cert_verify)" json:"skip_cert_verify"`
+	Disabled       bool      `orm:"column(disabled)" json:"disabled"`
+	IsDefault      bool      `orm:"column(is_default)" json:"is_default"`
+	CreationTime   time.Time `orm:"column(creation_time)" json:"creation_time"`
+	UpdateTime     time.Time `orm:"column(update_time)" json:"update_time"`
+}
+
+// TableName ...
+func (s *ScannerRegistration) TableName() string {
+	return ScannerRegistrationTable
+}
+
+// IsBuiltin returns whether the registration is the built-in Clair scanner
+func (s *ScannerRegistration) IsBuiltin() bool {
+	return s.URL == BuiltinClairScannerURL
+}
+
+// Valid checks whether the name and the URL of the registration are valid,
+// only http and https URLs are accepted for the external scanners
+func (s *ScannerRegistration) Valid() error {
+	if len(s.Name) == 0 {
+		return fmt.Errorf("name is required")
+	}
+	if s.IsBuiltin() {
+		return nil
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url %s: %v", s.URL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("invalid url %s, only http and https URLs are supported", s.URL)
+	}
+	return nil
+}
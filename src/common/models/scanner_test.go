@@ -0,0 +1,47 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidScannerRegistration(t *testing.T) {
+	cases := []struct {
+		reg   *ScannerRegistration
+		valid bool
+	}{
+		{&ScannerRegistration{URL: "http://scanner:8080"}, false},
+		{&ScannerRegistration{Name: "trivy", URL: "scanner:8080"}, false},
+		{&ScannerRegistration{Name: "trivy", URL: "ftp://scanner"}, false},
+		{&ScannerRegistration{Name: "trivy", URL: "http://scanner:8080"}, true},
+		{&ScannerRegistration{Name: "Clair", URL: BuiltinClairScannerURL}, true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.valid, c.reg.Valid() == nil, "%+v", c.reg)
+	}
+	assert.True(t, (&ScannerRegistration{URL: BuiltinClairScannerURL}).IsBuiltin())
+}
+
+func TestProjectScannerID(t *testing.T) {
+	p := &Project{}
+	assert.Equal(t, int64(0), p.ScannerID())
+	p.Metadata = map[string]string{ProMetaScanner: "invalid"}
+	assert.Equal(t, int64(0), p.ScannerID())
+	p.Metadata = map[string]string{ProMetaScanner: "3"}
+	assert.Equal(t, int64(3), p.ScannerID())
+}
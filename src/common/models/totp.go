@@ -0,0 +1,59 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"time"
+)
+
+const (
+	// UserTOTPTable is the name of table in DB that holds the TOTP second factor of the users
+	UserTOTPTable = "user_totp"
+	// RecoveryCodeCount is the count of the recovery codes generated for a user
+	RecoveryCodeCount = 10
+)
+
+// UserTOTP is the TOTP second factor of a user, it takes effect after being enabled
+type UserTOTP struct {
+	UserID int `orm:"pk;column(user_id)" json:"-"`
+	// encrypted with the secret key
+	Secret  string `orm:"column(secret)" json:"-"`
+	Enabled bool   `orm:"column(enabled)" json:"enabled"`
+	// the codes of the last used time step and the ones before it are rejected
+	LastUsedStep int64 `orm:"column(last_used_step)" json:"-"`
+	// the JSON encoded hashes of the unused recovery codes
+	RecoveryCodesDB string   `orm:"column(recovery_codes)" json:"-"`
+	RecoveryCodes   []string `orm:"-" json:"-"`
+	Salt            string   `orm:"column(salt)" json:"-"`
+	// the password of the user for the basic auth clients, as they can't prompt for the code
+	CLISecret    string    `orm:"column(cli_secret)" json:"-"`
+	CLISalt      string    `orm:"column(cli_salt)" json:"-"`
+	CreationTime time.Time `orm:"column(creation_time);auto_now_add" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time);auto_now" json:"update_time"`
+}
+
+// TableName ...
+func (u *UserTOTP) TableName() string {
+	return UserTOTPTable
+}
+
+// TOTPStatus is the second factor status of a user
+type TOTPStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+	CLISecretCreated  bool `json:"cli_secret_created"`
+	// required for the user by the configuration
+	Required bool `json:"required"`
+}
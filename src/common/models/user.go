@@ -41,6 +41,13 @@ type User struct {
 	CreationTime time.Time    `orm:"column(creation_time);auto_now_add" json:"creation_time"`
 	UpdateTime   time.Time    `orm:"column(update_time);auto_now" json:"update_time"`
 	GroupList    []*UserGroup `orm:"-" json:"-"`
+	// the time when the password is changed, the password expires after the max age of the password policy
+	PasswordChangedTime time.Time `orm:"column(password_changed_time);auto_now_add" json:"-"`
+	// the user logged in with an expired password, which must be changed before other operations
+	PasswordExpired bool `orm:"-" json:"password_expired"`
+	// the system admin logged in without the second factor when it's required, which must be enabled
+	// before other operations
+	TOTPEnrollRequired bool `orm:"-" json:"totp_enroll_required"`
 }
 
 // UserQuery ...
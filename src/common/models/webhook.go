@@ -0,0 +1,191 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// WebhookPolicyTable is the name of table in DB that holds the webhook policies
+	WebhookPolicyTable = "webhook_policy"
+	// WebhookExecutionTable is the name of table in DB that holds the delivery history of webhook policies
+	WebhookExecutionTable = "webhook_execution"
+
+	// WebhookEventPushImage : an image is pushed
+	WebhookEventPushImage = "pushImage"
+	// WebhookEventPullImage : an image is pulled
+	WebhookEventPullImage = "pullImage"
+	// WebhookEventDeleteImage : an image is deleted
+	WebhookEventDeleteImage = "deleteImage"
+	// WebhookEventScanningCompleted : the scanning of an image is completed
+	WebhookEventScanningCompleted = "scanningCompleted"
+	// WebhookEventScanningFailed : the scanning of an image is failed
+	WebhookEventScanningFailed = "scanningFailed"
+	// WebhookEventReplicationCompleted : an image is replicated to the remote registry
+	WebhookEventReplicationCompleted = "replicationCompleted"
+	// WebhookEventUploadChart : a chart is uploaded
+	WebhookEventUploadChart = "uploadChart"
+	// WebhookEventDeleteChart : a chart is deleted
+	WebhookEventDeleteChart = "deleteChart"
+)
+
+// WebhookEventTypes are the types of events supported by webhook
+var WebhookEventTypes = []string{
+	WebhookEventPushImage,
+	WebhookEventPullImage,
+	WebhookEventDeleteImage,
+	WebhookEventScanningCompleted,
+	WebhookEventScanningFailed,
+	WebhookEventReplicationCompleted,
+	WebhookEventUploadChart,
+	WebhookEventDeleteChart,
+}
+
+// WebhookPolicy holds the target address and the event types of a webhook of the project
+type WebhookPolicy struct {
+	ID          int64  `orm:"pk;auto;column(id)" json:"id"`
+	ProjectID   int64  `orm:"column(project_id)" json:"project_id"`
+	Name        string `orm:"column(name)" json:"name"`
+	Description string `orm:"column(description)" json:"description"`
+	// The URL that the events are posted to
+	Address string `orm:"column(address)" json:"address"`
+	// The value of the "Authorization" header sent with the events, e.g. "Bearer xxx", encrypted with the secret key
+	AuthHeader string `orm:"column(auth_header)" json:"-"`
+	// The secret used to sign the payloads, encrypted with the secret key
+	Secret         string `orm:"column(secret)" json:"-"`
+	SkipCertVerify bool   `orm:"column(skip_cert_verify)" json:"skip_cert_verify"`
+	// The event types in json format
+	EventTypesDB string    `orm:"column(event_types)" json:"-"`
+	EventTypes   []string  `orm:"-" json:"event_types"`
+	Enabled      bool      `orm:"column(enabled)" json:"enabled"`
+	Creator      string    `orm:"column(creator)" json:"creator"`
+	CreationTime time.Time `orm:"column(creation_time)" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time)" json:"update_time"`
+}
+
+// TableName ...
+func (w *WebhookPolicy) TableName() string {
+	return WebhookPolicyTable
+}
+
+// Valid checks whether the name, address and event types of the policy are valid
+func (w *WebhookPolicy) Valid() error {
+	if len(w.Name) == 0 {
+		return fmt.Errorf("name is required")
+	}
+	u, err := url.Parse(w.Address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %v", w.Address, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("invalid address %s, only http and https URLs are supported", w.Address)
+	}
+	if len(w.EventTypes) == 0 {
+		return fmt.Errorf("at least one event type must be set")
+	}
+	for _, t := range w.EventTypes {
+		if !IsWebhookEventType(t) {
+			return fmt.Errorf("unsupported event type: %s", t)
+		}
+	}
+	return nil
+}
+
+// Subscribes returns whether the policy is enabled and subscribes the event type
+func (w *WebhookPolicy) Subscribes(eventType string) bool {
+	if !w.Enabled {
+		return false
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWebhookEventType returns whether the event type is supported by webhook
+func IsWebhookEventType(eventType string) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookExecution is a delivery of an event to the address of the webhook policy
+type WebhookExecution struct {
+	ID        int64  `orm:"pk;auto;column(id)" json:"id"`
+	PolicyID  int64  `orm:"column(policy_id)" json:"policy_id"`
+	EventType string `orm:"column(event_type)" json:"event_type"`
+	Status    string `orm:"column(status)" json:"status"`
+	JobUUID   string `orm:"column(job_uuid)" json:"-"`
+	// The payload delivered in json format
+	Payload      string    `orm:"column(payload)" json:"payload"`
+	CreationTime time.Time `orm:"column(creation_time)" json:"creation_time"`
+	UpdateTime   time.Time `orm:"column(update_time)" json:"update_time"`
+}
+
+// TableName ...
+func (w *WebhookExecution) TableName() string {
+	return WebhookExecutionTable
+}
+
+// WebhookExecutionQuery holds the query conditions of the delivery history
+type WebhookExecutionQuery struct {
+	PolicyID  int64
+	EventType string
+	Status    string
+	Pagination
+}
+
+// WebhookPayload is the json body posted to the address of the webhook policies
+type WebhookPayload struct {
+	Type      string            `json:"type"`
+	OccurAt   int64             `json:"occur_at"`
+	Operator  string            `json:"operator"`
+	EventData *WebhookEventData `json:"event_data"`
+}
+
+// WebhookEventData describes the resources involved in the event
+type WebhookEventData struct {
+	Resources  []*WebhookResource `json:"resources"`
+	Repository *WebhookRepository `json:"repository"`
+	Custom     map[string]string  `json:"custom_attributes,omitempty"`
+}
+
+// WebhookResource is an image or a chart version involved in the event
+type WebhookResource struct {
+	Digest      string `json:"digest,omitempty"`
+	Tag         string `json:"tag"`
+	ResourceURL string `json:"resource_url,omitempty"`
+}
+
+// WebhookRepository is the repository or chart that the resources belong to
+type WebhookRepository struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	RepoFullName string `json:"repo_full_name"`
+}
+
+// WebhookEvent is published by the components of Harbor to trigger the webhook policies of the project
+type WebhookEvent struct {
+	ProjectID int64
+	Payload   *WebhookPayload
+}
@@ -0,0 +1,55 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookPolicyValid(t *testing.T) {
+	cases := []struct {
+		policy *WebhookPolicy
+		valid  bool
+	}{
+		// no name
+		{&WebhookPolicy{Address: "http://ci.example.com/hook", EventTypes: []string{WebhookEventPushImage}}, false},
+		// invalid address
+		{&WebhookPolicy{Name: "ci", Address: "ci.example.com/hook", EventTypes: []string{WebhookEventPushImage}}, false},
+		{&WebhookPolicy{Name: "ci", Address: "ftp://ci.example.com/hook", EventTypes: []string{WebhookEventPushImage}}, false},
+		// no event type
+		{&WebhookPolicy{Name: "ci", Address: "http://ci.example.com/hook"}, false},
+		// unsupported event type
+		{&WebhookPolicy{Name: "ci", Address: "http://ci.example.com/hook", EventTypes: []string{"unknown"}}, false},
+		{&WebhookPolicy{Name: "ci", Address: "https://ci.example.com/hook", EventTypes: []string{WebhookEventPushImage, WebhookEventDeleteChart}}, true},
+	}
+	for _, c := range cases {
+		err := c.policy.Valid()
+		assert.Equal(t, c.valid, err == nil, "%+v: %v", c.policy, err)
+	}
+}
+
+func TestWebhookPolicySubscribes(t *testing.T) {
+	policy := &WebhookPolicy{
+		EventTypes: []string{WebhookEventPushImage, WebhookEventScanningFailed},
+	}
+	assert.False(t, policy.Subscribes(WebhookEventPushImage))
+
+	policy.Enabled = true
+	assert.True(t, policy.Subscribes(WebhookEventPushImage))
+	assert.True(t, policy.Subscribes(WebhookEventScanningFailed))
+	assert.False(t, policy.Subscribes(WebhookEventPullImage))
+}
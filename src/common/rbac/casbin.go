@@ -17,10 +17,12 @@ package rbac
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/casbin/casbin"
 	"github.com/casbin/casbin/model"
 	"github.com/casbin/casbin/persist"
+	"github.com/casbin/casbin/util"
 )
 
 var (
@@ -47,7 +49,7 @@ e = some(where (p.eft == allow)) && !some(where (p.eft == deny))
 
 # Matchers
 [matchers]
-m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && (r.act == p.act || p.act == '*')
+m = g(r.sub, p.sub) && resourceMatch(r.obj, p.obj) && (r.act == p.act || p.act == '*')
 `
 
 type userAdapter struct {
@@ -131,8 +133,22 @@ func (a *userAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex
 	return errNotImplemented
 }
 
+// resourceMatch is keyMatch2 of casbin with the pattern anchored at the end, the pattern of
+// keyMatch2 matches any resource it is a substring of, so the policy of a resource would
+// match its subresources, e.g. "/project/1" matches "/project/1/member"
+func resourceMatch(args ...interface{}) (interface{}, error) {
+	resource := args[0].(string)
+	pattern := args[1].(string)
+	if !strings.Contains(pattern, "/:") {
+		pattern += "$"
+	}
+	return util.KeyMatch2(resource, pattern), nil
+}
+
 func enforcerForUser(user User) *casbin.Enforcer {
 	m := model.Model{}
 	m.LoadModelFromText(modelText)
-	return casbin.NewEnforcer(m, &userAdapter{User: user})
+	e := casbin.NewEnforcer(m, &userAdapter{User: user})
+	e.AddFunction("resourceMatch", resourceMatch)
+	return e
 }
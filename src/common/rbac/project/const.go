@@ -15,19 +15,94 @@
 package project
 
 import (
+	"net/http"
+
 	"github.com/goharbor/harbor/src/common/rbac"
 )
 
 // const action variables
 const (
-	ActionAll      = rbac.Action("*")
-	ActionPull     = rbac.Action("pull")
-	ActionPush     = rbac.Action("push")
-	ActionPushPull = rbac.Action("push+pull")
+	ActionAll      = rbac.Action("*")         // action match any other actions
+	ActionPull     = rbac.Action("pull")      // pull repository tag
+	ActionPush     = rbac.Action("push")      // push repository tag
+	ActionPushPull = rbac.Action("push+pull") // compatible with security all perm of project
+
+	// create, read, update, delete actions compatible with restful api methods
+	ActionCreate = rbac.Action("create")
+	ActionRead   = rbac.Action("read")
+	ActionUpdate = rbac.Action("update")
+	ActionDelete = rbac.Action("delete")
 )
 
 // const resource variables
 const (
-	ResourceAll   = rbac.Resource("*")
-	ResourceImage = rbac.Resource("image")
+	ResourceAll            = rbac.Resource("*") // resource match any other resources
+	ResourceSelf           = rbac.Resource("")  // subresource for the project itself
+	ResourceImage          = rbac.Resource("image")
+	ResourceHelmChart      = rbac.Resource("helm-chart")
+	ResourceHelmRepo       = rbac.Resource("helm-repo")
+	ResourceMember         = rbac.Resource("member")
+	ResourceMetadata       = rbac.Resource("metadata")
+	ResourceLog            = rbac.Resource("log")
+	ResourceLabel          = rbac.Resource("label")
+	ResourceLabelResource  = rbac.Resource("label-resource") // labels attached to repositories, tags and charts
+	ResourceRepository     = rbac.Resource("repository")
+	ResourceRepositoryTag  = rbac.Resource("repository-tag")
+	ResourceRobot          = rbac.Resource("robot")
+	ResourceReplication    = rbac.Resource("replication")
+	ResourceReplicationJob = rbac.Resource("replication-job")
+	ResourceScan           = rbac.Resource("scan")
+	ResourceScanner        = rbac.Resource("scanner")
+	ResourceCVEAllowlist   = rbac.Resource("cve-allowlist")
+	ResourceRetention      = rbac.Resource("retention")
+	ResourceWebhook        = rbac.Resource("webhook")
+)
+
+var (
+	// the actions can be done on the resources of the project, the custom roles are composed of them
+	resourceActions = map[rbac.Resource][]rbac.Action{
+		ResourceSelf:           {ActionRead, ActionUpdate, ActionDelete},
+		ResourceImage:          {ActionPull, ActionPush},
+		ResourceHelmChart:      {ActionRead, ActionCreate, ActionDelete},
+		ResourceHelmRepo:       {ActionRead},
+		ResourceMember:         {ActionRead, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceMetadata:       {ActionRead, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceLog:            {ActionRead},
+		ResourceLabel:          {ActionRead, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceLabelResource:  {ActionRead, ActionCreate, ActionDelete},
+		ResourceRepository:     {ActionRead, ActionUpdate, ActionDelete},
+		ResourceRepositoryTag:  {ActionRead, ActionCreate, ActionDelete},
+		ResourceRobot:          {ActionRead, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceReplication:    {ActionRead},
+		ResourceReplicationJob: {ActionRead},
+		ResourceScan:           {ActionRead, ActionCreate},
+		ResourceScanner:        {ActionRead, ActionUpdate},
+		ResourceCVEAllowlist:   {ActionRead, ActionUpdate},
+		ResourceRetention:      {ActionRead, ActionCreate, ActionUpdate, ActionDelete},
+		ResourceWebhook:        {ActionRead, ActionCreate, ActionUpdate, ActionDelete},
+	}
 )
+
+// IsValidPolicy returns whether the action can be done on the resource of the project
+func IsValidPolicy(resource rbac.Resource, action rbac.Action) bool {
+	for _, a := range resourceActions[resource] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionOfMethod returns the action the HTTP method performs on a resource
+func ActionOfMethod(method string) rbac.Action {
+	switch method {
+	case http.MethodPost:
+		return ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionRead
+	}
+}
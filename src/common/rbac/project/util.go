@@ -21,11 +21,21 @@ import (
 var (
 	// subresource policies for public project
 	publicProjectPolicies = []*rbac.Policy{
+		{Resource: ResourceSelf, Action: ActionRead},
 		{Resource: ResourceImage, Action: ActionPull},
+		{Resource: ResourceMetadata, Action: ActionRead},
+		{Resource: ResourceLabel, Action: ActionRead},
+		{Resource: ResourceLabelResource, Action: ActionRead},
+		{Resource: ResourceRepository, Action: ActionRead},
+		{Resource: ResourceRepositoryTag, Action: ActionRead},
+		{Resource: ResourceHelmChart, Action: ActionRead},
+		{Resource: ResourceHelmRepo, Action: ActionRead},
+		{Resource: ResourceScan, Action: ActionRead},
 	}
 
 	// subresource policies for system admin visitor
 	systemAdminProjectPolicies = []*rbac.Policy{
+		{Resource: ResourceSelf, Action: ActionAll},
 		{Resource: ResourceAll, Action: ActionAll},
 	}
 )
@@ -28,6 +28,12 @@ type visitorContext interface {
 }
 
 // visitor implement the rbac.User interface for project visitor
+// customRoleContext is implemented by the contexts supporting the custom roles,
+// GetRolePolicies returns the policies on the subresources of the project of the custom role
+type customRoleContext interface {
+	GetRolePolicies(roleID int) []*rbac.Policy
+}
+
 type visitor struct {
 	ctx          visitorContext
 	namespace    rbac.Namespace
@@ -66,7 +72,13 @@ func (v *visitor) GetRoles() []rbac.Role {
 	roles := []rbac.Role{}
 
 	for _, roleID := range v.projectRoles {
-		roles = append(roles, &visitorRole{roleID: roleID, namespace: v.namespace})
+		if IsBuiltInRole(roleID) {
+			roles = append(roles, &visitorRole{roleID: roleID, namespace: v.namespace})
+			continue
+		}
+		if ctx, ok := v.ctx.(customRoleContext); ok {
+			roles = append(roles, &customRole{namespace: v.namespace, roleID: roleID, policies: ctx.GetRolePolicies(roleID)})
+		}
 	}
 
 	return roles
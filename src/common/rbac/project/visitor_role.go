@@ -15,30 +15,81 @@
 package project
 
 import (
+	"fmt"
+
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/rbac"
 )
 
 var (
+	// guestPolicies are the read only access to the project
+	guestPolicies = []*rbac.Policy{
+		{Resource: ResourceSelf, Action: ActionRead},
+		{Resource: ResourceImage, Action: ActionPull},
+		{Resource: ResourceMember, Action: ActionRead},
+		{Resource: ResourceMetadata, Action: ActionRead},
+		{Resource: ResourceLog, Action: ActionRead},
+		{Resource: ResourceLabel, Action: ActionRead},
+		{Resource: ResourceLabelResource, Action: ActionRead},
+		{Resource: ResourceRepository, Action: ActionRead},
+		{Resource: ResourceRepositoryTag, Action: ActionRead},
+		{Resource: ResourceHelmChart, Action: ActionRead},
+		{Resource: ResourceHelmRepo, Action: ActionRead},
+		{Resource: ResourceRobot, Action: ActionRead},
+		{Resource: ResourceScan, Action: ActionRead},
+		{Resource: ResourceScanner, Action: ActionRead},
+		{Resource: ResourceCVEAllowlist, Action: ActionRead},
+		{Resource: ResourceRetention, Action: ActionRead},
+	}
+
+	// developerPolicies adds the access to push and manage the content to the guest ones
+	developerPolicies = append(guestPolicies, []*rbac.Policy{
+		{Resource: ResourceImage, Action: ActionPush},
+		{Resource: ResourceLabelResource, Action: ActionCreate},
+		{Resource: ResourceLabelResource, Action: ActionDelete},
+		{Resource: ResourceRepository, Action: ActionUpdate},
+		{Resource: ResourceRepositoryTag, Action: ActionCreate},
+		{Resource: ResourceHelmChart, Action: ActionCreate},
+	}...)
+
+	// projectAdminPolicies adds the access to manage the project to the developer ones
+	projectAdminPolicies = append(developerPolicies, []*rbac.Policy{
+		{Resource: ResourceImage, Action: ActionPushPull}, // compatible with security all perm of project
+		{Resource: ResourceSelf, Action: ActionUpdate},
+		{Resource: ResourceSelf, Action: ActionDelete},
+		{Resource: ResourceMember, Action: ActionCreate},
+		{Resource: ResourceMember, Action: ActionUpdate},
+		{Resource: ResourceMember, Action: ActionDelete},
+		{Resource: ResourceMetadata, Action: ActionCreate},
+		{Resource: ResourceMetadata, Action: ActionUpdate},
+		{Resource: ResourceMetadata, Action: ActionDelete},
+		{Resource: ResourceLabel, Action: ActionCreate},
+		{Resource: ResourceLabel, Action: ActionUpdate},
+		{Resource: ResourceLabel, Action: ActionDelete},
+		{Resource: ResourceRepository, Action: ActionDelete},
+		{Resource: ResourceRepositoryTag, Action: ActionDelete},
+		{Resource: ResourceHelmChart, Action: ActionDelete},
+		{Resource: ResourceRobot, Action: ActionCreate},
+		{Resource: ResourceRobot, Action: ActionUpdate},
+		{Resource: ResourceRobot, Action: ActionDelete},
+		{Resource: ResourceReplication, Action: ActionRead},
+		{Resource: ResourceReplicationJob, Action: ActionRead},
+		{Resource: ResourceScan, Action: ActionCreate},
+		{Resource: ResourceScanner, Action: ActionUpdate},
+		{Resource: ResourceCVEAllowlist, Action: ActionUpdate},
+		{Resource: ResourceRetention, Action: ActionCreate},
+		{Resource: ResourceRetention, Action: ActionUpdate},
+		{Resource: ResourceRetention, Action: ActionDelete},
+		{Resource: ResourceWebhook, Action: ActionAll},
+	}...)
+
 	rolePoliciesMap = map[string][]*rbac.Policy{
-		"projectAdmin": {
-			{Resource: ResourceImage, Action: ActionPushPull}, // compatible with security all perm of project
-			{Resource: ResourceImage, Action: ActionPush},
-			{Resource: ResourceImage, Action: ActionPull},
-		},
-
-		"developer": {
-			{Resource: ResourceImage, Action: ActionPush},
-			{Resource: ResourceImage, Action: ActionPull},
-		},
-
-		"guest": {
-			{Resource: ResourceImage, Action: ActionPull},
-		},
+		"projectAdmin": projectAdminPolicies,
+		"developer":    developerPolicies,
+		"guest":        guestPolicies,
 	}
 )
 
-// visitorRole implement the rbac.Role interface
 type visitorRole struct {
 	namespace rbac.Namespace
 	roleID    int
@@ -77,3 +128,40 @@ func (role *visitorRole) GetPolicies() []*rbac.Policy {
 
 	return policies
 }
+
+// customRole is a role defined by the system admin with the policies on the subresources of the project
+type customRole struct {
+	namespace rbac.Namespace
+	roleID    int
+	policies  []*rbac.Policy
+}
+
+// GetRoleName returns the identity of the custom role in the policies, the ID rather than the name
+// is used as the name is set by the user and not to collide with the built-in roles
+func (role *customRole) GetRoleName() string {
+	return fmt.Sprintf("role-%d", role.roleID)
+}
+
+// GetPolicies returns policies for the custom role
+func (role *customRole) GetPolicies() []*rbac.Policy {
+	policies := []*rbac.Policy{}
+	for _, policy := range role.policies {
+		policies = append(policies, &rbac.Policy{
+			Resource: role.namespace.Resource(policy.Resource),
+			Action:   policy.Action,
+			Effect:   policy.Effect,
+		})
+	}
+	return policies
+}
+
+// BuiltInRolePolicies returns the policies on the subresources of the project of the built-in role
+func BuiltInRolePolicies(roleID int) []*rbac.Policy {
+	role := &visitorRole{roleID: roleID}
+	return rolePoliciesMap[role.GetRoleName()]
+}
+
+// IsBuiltInRole returns whether the role is one of projectAdmin, developer and guest
+func IsBuiltInRole(roleID int) bool {
+	return roleID == common.RoleProjectAdmin || roleID == common.RoleDeveloper || roleID == common.RoleGuest
+}
@@ -39,6 +39,22 @@ func (ctx *fakeVisitorContext) IsSysAdmin() bool {
 	return ctx.IsAuthenticated() && ctx.isSysAdmin
 }
 
+// fakeCustomRoleContext serves the policies of the custom role 10 as a "scanner" role
+type fakeCustomRoleContext struct {
+	fakeVisitorContext
+}
+
+func (ctx *fakeCustomRoleContext) GetRolePolicies(roleID int) []*rbac.Policy {
+	if roleID != 10 {
+		return nil
+	}
+	return []*rbac.Policy{
+		{Resource: ResourceImage, Action: ActionPull},
+		{Resource: ResourceRepository, Action: ActionRead},
+		{Resource: ResourceScan, Action: ActionCreate},
+	}
+}
+
 var (
 	anonymousCtx     = &fakeVisitorContext{}
 	authenticatedCtx = &fakeVisitorContext{username: "user"}
@@ -86,6 +102,62 @@ func (suite *VisitorTestSuite) TestGetRoles() {
 
 	authenticated = NewUser(authenticatedCtx, namespace, common.RoleProjectAdmin, common.RoleDeveloper)
 	suite.Len(authenticated.GetRoles(), 2)
+
+	// the custom roles are ignored if the context doesn't support them
+	authenticated = NewUser(authenticatedCtx, namespace, 10)
+	suite.Empty(authenticated.GetRoles())
+
+	customRoleCtx := &fakeCustomRoleContext{fakeVisitorContext{username: "user"}}
+	authenticated = NewUser(customRoleCtx, namespace, common.RoleGuest, 10)
+	roles := authenticated.GetRoles()
+	suite.Require().Len(roles, 2)
+	suite.Equal("role-10", roles[1].GetRoleName())
+	suite.Equal(namespace.Resource(ResourceScan), roles[1].GetPolicies()[2].Resource)
+}
+
+func (suite *VisitorTestSuite) TestCustomRolePermission() {
+	namespace := rbac.NewProjectNamespace(int64(1), false)
+	customRoleCtx := &fakeCustomRoleContext{fakeVisitorContext{username: "user"}}
+	user := NewUser(customRoleCtx, namespace, 10)
+
+	suite.True(rbac.HasPermission(user, namespace.Resource(ResourceImage), ActionPull))
+	suite.True(rbac.HasPermission(user, namespace.Resource(ResourceScan), ActionCreate))
+	suite.False(rbac.HasPermission(user, namespace.Resource(ResourceImage), ActionPush))
+	suite.False(rbac.HasPermission(user, namespace.Resource(ResourceMember), ActionRead))
+
+	// the policies of the custom role are scoped to the project of the namespace
+	otherNamespace := rbac.NewProjectNamespace(int64(2), false)
+	suite.False(rbac.HasPermission(user, otherNamespace.Resource(ResourceImage), ActionPull))
+}
+
+func (suite *VisitorTestSuite) TestHasPermission() {
+	namespace := rbac.NewProjectNamespace(int64(1), false)
+	publicNamespace := rbac.NewProjectNamespace(int64(1), true)
+	cases := []struct {
+		user     rbac.User
+		resource rbac.Resource
+		action   rbac.Action
+		want     bool
+	}{
+		{NewUser(anonymousCtx, namespace), ResourceRepository, ActionRead, false},
+		{NewUser(anonymousCtx, publicNamespace), ResourceRepository, ActionRead, true},
+		{NewUser(anonymousCtx, publicNamespace), ResourceMember, ActionRead, false},
+		{NewUser(authenticatedCtx, namespace, common.RoleGuest), ResourceMember, ActionRead, true},
+		{NewUser(authenticatedCtx, namespace, common.RoleGuest), ResourceImage, ActionPush, false},
+		{NewUser(authenticatedCtx, namespace, common.RoleDeveloper), ResourceImage, ActionPush, true},
+		{NewUser(authenticatedCtx, namespace, common.RoleDeveloper), ResourceRepositoryTag, ActionCreate, true},
+		{NewUser(authenticatedCtx, namespace, common.RoleDeveloper), ResourceRepository, ActionDelete, false},
+		{NewUser(authenticatedCtx, namespace, common.RoleDeveloper), ResourceMember, ActionCreate, false},
+		{NewUser(authenticatedCtx, namespace, common.RoleProjectAdmin), ResourceRepository, ActionDelete, true},
+		{NewUser(authenticatedCtx, namespace, common.RoleProjectAdmin), ResourceSelf, ActionUpdate, true},
+		{NewUser(authenticatedCtx, namespace, common.RoleProjectAdmin), ResourceWebhook, ActionCreate, true},
+		{NewUser(sysAdminCtx, namespace), ResourceSelf, ActionDelete, true},
+		{NewUser(sysAdminCtx, namespace), ResourceReplication, ActionRead, true},
+	}
+	for _, c := range cases {
+		suite.Equal(c.want, rbac.HasPermission(c.user, namespace.Resource(c.resource), c.action),
+			"%s %s for %s", c.action, c.resource, c.user.GetUserName())
+	}
 }
 
 func TestVisitorTestSuite(t *testing.T) {
@@ -263,6 +263,24 @@ func TestHasPermissionResourceKeyMatch(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "project member create for project resource",
+			args: args{
+				&userWithoutRoles{Username: "user1", UserPolicies: []*Policy{{Resource: "/project/1", Action: "*"}}},
+				"/project/1/member",
+				"create",
+			},
+			want: false,
+		},
+		{
+			name: "project label-resource create for label resource",
+			args: args{
+				&userWithoutRoles{Username: "user1", UserPolicies: []*Policy{{Resource: "/project/1/label", Action: "*"}}},
+				"/project/1/label-resource",
+				"create",
+			},
+			want: false,
+		},
 		{
 			name: "project repository delete test for resource key match",
 			args: args{
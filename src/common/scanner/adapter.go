@@ -0,0 +1,182 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scanner defines the adapter interface of the vulnerability scanners and the scanner
+// adapter protocol over HTTP, which allows the scanners other than the built-in Clair to be
+// registered by URL.
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/clair"
+)
+
+// ErrReportNotReady is returned by GetReport when the scanning is still in progress
+var ErrReportNotReady = errors.New("the report is not ready")
+
+// Adapter is the interface of the vulnerability scanners
+type Adapter interface {
+	// Metadata returns the information of the scanner
+	Metadata() (*Metadata, error)
+	// Scan submits the artifact to the scanner, it returns the ID of the report
+	// which can be retrieved after the scanning is completed
+	Scan(req *ScanRequest) (string, error)
+	// GetReport returns the report, ErrReportNotReady is returned if the scanning is in progress
+	GetReport(id string) (*Report, error)
+}
+
+// New returns the adapter of the registered scanner, the built-in Clair is called at the clair endpoint
+func New(reg *models.ScannerRegistration, clairEndpoint string) (Adapter, error) {
+	if reg == nil {
+		return nil, errors.New("no scanner registration provided")
+	}
+	if reg.IsBuiltin() {
+		if len(clairEndpoint) == 0 {
+			return nil, fmt.Errorf("the built-in scanner %s is not available", reg.Name)
+		}
+		return NewClairAdapter(clairEndpoint), nil
+	}
+	return NewHTTPAdapter(reg.URL, reg.AuthHeader, reg.SkipCertVerify), nil
+}
+
+// Metadata is the information of the scanner
+type Metadata struct {
+	Name    string `json:"name"`
+	Vendor  string `json:"vendor"`
+	Version string `json:"version"`
+	// Optional, the properties specific to the scanner, e.g. the update time of the vulnerability database
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// ScanRequest is the request to scan an artifact in the registry
+type ScanRequest struct {
+	Registry Registry `json:"registry"`
+	Artifact Artifact `json:"artifact"`
+}
+
+// Registry is the registry the scanner pulls the artifact from
+type Registry struct {
+	URL string `json:"url"`
+	// The value of the "Authorization" header to pull the artifact, e.g. "Bearer xxx"
+	Authorization string `json:"authorization"`
+}
+
+// Artifact is the artifact to scan
+type Artifact struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Digest     string `json:"digest"`
+	MimeType   string `json:"mime_type"`
+}
+
+// ReportID is the response of the scan request
+type ReportID struct {
+	ID string `json:"id"`
+}
+
+// Report is the vulnerabilities found in the artifact
+type Report struct {
+	// The number of the packages scanned, including the ones without vulnerability
+	Packages        int              `json:"packages"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities"`
+}
+
+// Vulnerability is a vulnerability of a package in the artifact
+type Vulnerability struct {
+	ID         string `json:"id"`
+	Package    string `json:"package"`
+	Version    string `json:"version"`
+	FixVersion string `json:"fix_version,omitempty"`
+	// One of "Negligible", "Unknown", "Low", "Medium", "High" and "Critical"
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Link        string `json:"link"`
+}
+
+// Items returns the vulnerabilities in the format of the vulnerability details API
+func (r *Report) Items() []*models.VulnerabilityItem {
+	items := []*models.VulnerabilityItem{}
+	for _, v := range r.Vulnerabilities {
+		items = append(items, &models.VulnerabilityItem{
+			ID:          v.ID,
+			Severity:    clair.ParseClairSev(v.Severity),
+			Pkg:         v.Package,
+			Version:     v.Version,
+			Description: v.Description,
+			Link:        v.Link,
+			Fixed:       v.FixVersion,
+		})
+	}
+	return items
+}
+
+// Overview returns the number of the packages at each severity level and the overall severity
+// of the artifact, the severity of a package is the highest severity of its vulnerabilities.
+func (r *Report) Overview() (*models.ComponentsOverview, models.Severity) {
+	pkgSev := map[string]models.Severity{}
+	for _, v := range r.Vulnerabilities {
+		key := fmt.Sprintf("%s:%s", v.Package, v.Version)
+		if sev := clair.ParseClairSev(v.Severity); sev > pkgSev[key] {
+			pkgSev[key] = sev
+		}
+	}
+	total := r.Packages
+	if total < len(pkgSev) {
+		total = len(pkgSev)
+	}
+
+	counts := map[models.Severity]int{}
+	if total > len(pkgSev) {
+		counts[models.SevNone] = total - len(pkgSev)
+	}
+	overall := models.SevNone
+	for _, sev := range pkgSev {
+		counts[sev]++
+		if sev > overall {
+			overall = sev
+		}
+	}
+	summary := []*models.ComponentsOverviewEntry{}
+	for sev := models.SevHigh; sev >= models.SevNone; sev-- {
+		if counts[sev] > 0 {
+			summary = append(summary, &models.ComponentsOverviewEntry{
+				Sev:   int(sev),
+				Count: counts[sev],
+			})
+		}
+	}
+	return &models.ComponentsOverview{
+		Total:   total,
+		Summary: summary,
+	}, overall
+}
+
+// MaxSeverity returns the highest severity of the vulnerabilities, the vulnerabilities
+// whose upper case IDs are in the ignored set are excluded.
+func (r *Report) MaxSeverity(ignored map[string]bool) models.Severity {
+	sev := models.SevNone
+	for _, v := range r.Vulnerabilities {
+		if ignored[strings.ToUpper(v.ID)] {
+			continue
+		}
+		if temp := clair.ParseClairSev(v.Severity); temp > sev {
+			sev = temp
+		}
+	}
+	return sev
+}
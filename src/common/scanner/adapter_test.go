@@ -0,0 +1,84 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var report = &Report{
+	Packages: 4,
+	Vulnerabilities: []*Vulnerability{
+		{ID: "CVE-2019-0001", Package: "openssl", Version: "1.0", Severity: "High"},
+		{ID: "CVE-2019-0002", Package: "openssl", Version: "1.0", Severity: "Low"},
+		{ID: "cve-2019-0003", Package: "bash", Version: "4.4", Severity: "Medium", FixVersion: "4.5"},
+	},
+}
+
+func TestReportItems(t *testing.T) {
+	items := report.Items()
+	require.Len(t, items, 3)
+	assert.Equal(t, "CVE-2019-0001", items[0].ID)
+	assert.Equal(t, models.SevHigh, items[0].Severity)
+	assert.Equal(t, "bash", items[2].Pkg)
+	assert.Equal(t, "4.5", items[2].Fixed)
+}
+
+func TestReportOverview(t *testing.T) {
+	overview, sev := report.Overview()
+	assert.Equal(t, models.SevHigh, sev)
+	assert.Equal(t, 4, overview.Total)
+	require.Len(t, overview.Summary, 3)
+	assert.Equal(t, int(models.SevHigh), overview.Summary[0].Sev)
+	assert.Equal(t, 1, overview.Summary[0].Count)
+	assert.Equal(t, int(models.SevMedium), overview.Summary[1].Sev)
+	assert.Equal(t, 1, overview.Summary[1].Count)
+	assert.Equal(t, int(models.SevNone), overview.Summary[2].Sev)
+	assert.Equal(t, 2, overview.Summary[2].Count)
+
+	overview, sev = (&Report{}).Overview()
+	assert.Equal(t, models.SevNone, sev)
+	assert.Equal(t, 0, overview.Total)
+	assert.Len(t, overview.Summary, 0)
+}
+
+func TestReportMaxSeverity(t *testing.T) {
+	assert.Equal(t, models.SevHigh, report.MaxSeverity(nil))
+	assert.Equal(t, models.SevMedium, report.MaxSeverity(map[string]bool{"CVE-2019-0001": true}))
+	assert.Equal(t, models.SevLow, report.MaxSeverity(map[string]bool{
+		"CVE-2019-0001": true,
+		"CVE-2019-0003": true,
+	}))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(nil, "")
+	assert.NotNil(t, err)
+
+	builtin := &models.ScannerRegistration{Name: "Clair", URL: models.BuiltinClairScannerURL}
+	_, err = New(builtin, "")
+	assert.NotNil(t, err)
+	a, err := New(builtin, "http://clair:6060")
+	require.Nil(t, err)
+	assert.IsType(t, &clairAdapter{}, a)
+
+	a, err = New(&models.ScannerRegistration{Name: "trivy", URL: "http://trivy:8080"}, "")
+	require.Nil(t, err)
+	assert.IsType(t, &httpAdapter{}, a)
+}
@@ -0,0 +1,157 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/clair"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+)
+
+// clairAdapter is the built-in adapter which scans the artifacts with Clair,
+// the ID of the report is the name of the top layer of the artifact in Clair
+type clairAdapter struct {
+	client *clair.Client
+}
+
+// NewClairAdapter returns the adapter of the built-in Clair at the endpoint,
+// the logger is used to write the output of the Clair client
+func NewClairAdapter(endpoint string, logger ...*log.Logger) Adapter {
+	var l *log.Logger
+	if len(logger) > 0 {
+		l = logger[0]
+	}
+	return &clairAdapter{
+		client: clair.NewClient(endpoint, l),
+	}
+}
+
+func (c *clairAdapter) Metadata() (*Metadata, error) {
+	if _, err := c.client.ListNamespaces(); err != nil {
+		return nil, err
+	}
+	return &Metadata{
+		Name:   "Clair",
+		Vendor: "CoreOS",
+	}, nil
+}
+
+func (c *clairAdapter) Scan(req *ScanRequest) (string, error) {
+	layers, err := c.prepareLayers(req)
+	if err != nil {
+		return "", err
+	}
+	if len(layers) == 0 {
+		return "", fmt.Errorf("no layer found in the artifact %s@%s", req.Artifact.Repository, req.Artifact.Digest)
+	}
+	for _, l := range layers {
+		log.Debugf("scanning layer %s, path: %s", l.Name, l.Path)
+		if err := c.client.ScanLayer(l); err != nil {
+			return "", fmt.Errorf("failed to scan layer %s: %v", l.Name, err)
+		}
+	}
+	return layers[len(layers)-1].Name, nil
+}
+
+func (c *clairAdapter) GetReport(id string) (*Report, error) {
+	res, err := c.client.GetResult(id)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	if res.Layer == nil {
+		return report, nil
+	}
+	report.Packages = len(res.Layer.Features)
+	for _, f := range res.Layer.Features {
+		for _, v := range f.Vulnerabilities {
+			report.Vulnerabilities = append(report.Vulnerabilities, &Vulnerability{
+				ID:          v.Name,
+				Package:     f.Name,
+				Version:     f.Version,
+				FixVersion:  v.FixedBy,
+				Severity:    v.Severity,
+				Description: v.Description,
+				Link:        v.Link,
+			})
+		}
+	}
+	return report, nil
+}
+
+// prepareLayers pulls the manifest of the artifact from the registry and builds the layers to be pushed to Clair
+func (c *clairAdapter) prepareLayers(req *ScanRequest) ([]models.ClairLayer, error) {
+	client := &http.Client{
+		Transport: registry.NewTransport(http.DefaultTransport, &authorizer{header: req.Registry.Authorization}),
+	}
+	repo, err := registry.NewRepository(req.Artifact.Repository, req.Registry.URL, client)
+	if err != nil {
+		return nil, err
+	}
+	reference := req.Artifact.Digest
+	if len(reference) == 0 {
+		reference = req.Artifact.Tag
+	}
+	_, _, payload, err := repo.PullManifest(reference, []string{schema2.MediaTypeManifest})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull the manifest of %s:%s: %v", req.Artifact.Repository, reference, err)
+	}
+	manifest, _, err := distribution.UnmarshalManifest(schema2.MediaTypeManifest, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []models.ClairLayer{}
+	headers := map[string]string{"Connection": "close", "Authorization": req.Registry.Authorization}
+	// form the chain by using the digests of all parent layers in the image, such that if another image is built on top of this image the layer name can be re-used.
+	shaChain := ""
+	for _, d := range manifest.References() {
+		if d.MediaType == schema2.MediaTypeConfig {
+			continue
+		}
+		shaChain += string(d.Digest) + "-"
+		l := models.ClairLayer{
+			Name:    fmt.Sprintf("%x", sha256.Sum256([]byte(shaChain))),
+			Headers: headers,
+			Format:  "Docker",
+			Path:    fmt.Sprintf("%s/v2/%s/blobs/%s", strings.TrimSuffix(req.Registry.URL, "/"), req.Artifact.Repository, d.Digest),
+		}
+		if len(layers) > 0 {
+			l.ParentName = layers[len(layers)-1].Name
+		}
+		layers = append(layers, l)
+	}
+	return layers, nil
+}
+
+// authorizer sets the authorization header of the scan request to the requests sent to the registry
+type authorizer struct {
+	header string
+}
+
+func (a *authorizer) Modify(req *http.Request) error {
+	if len(a.header) > 0 {
+		req.Header.Set("Authorization", a.header)
+	}
+	return nil
+}
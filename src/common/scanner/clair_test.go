@@ -0,0 +1,46 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/clair/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClairAdapter(t *testing.T) {
+	server := test.NewMockServer()
+	defer server.Close()
+
+	a := NewClairAdapter(server.URL)
+	m, err := a.Metadata()
+	require.Nil(t, err)
+	assert.Equal(t, "Clair", m.Name)
+
+	r, err := a.GetReport("03adedf41d4e0ea1b2458546a5b4717bf5f24b23489b25589e20c692aaf84d19")
+	require.Nil(t, err)
+	assert.True(t, r.Packages > 0)
+	assert.True(t, len(r.Vulnerabilities) > 0)
+	assert.Equal(t, len(r.Vulnerabilities), len(r.Items()))
+	overview, _ := r.Overview()
+	assert.Equal(t, r.Packages, overview.Total)
+	assert.True(t, r.MaxSeverity(nil) > models.SevNone)
+
+	_, err = a.GetReport("unknown")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,99 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// handler serves the scanner adapter protocol with an adapter
+type handler struct {
+	adapter Adapter
+}
+
+// NewHandler returns the HTTP handler serving the scanner adapter protocol with the adapter,
+// which wraps the scanners implemented in Go as scanner adapter services
+func NewHandler(adapter Adapter) http.Handler {
+	return &handler{adapter: adapter}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := req.URL.Path
+	switch {
+	case path == metadataPath:
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		m, err := h.adapter.Metadata()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, m)
+	case path == scanPath:
+		if req.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		scanReq := &ScanRequest{}
+		if err := json.NewDecoder(req.Body).Decode(scanReq); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, err := h.adapter.Scan(scanReq)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, &ReportID{ID: id})
+	case strings.HasPrefix(path, scanPath+"/") && strings.HasSuffix(path, "/report"):
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		id, err := url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(path, scanPath+"/"), "/report"))
+		if err != nil || len(id) == 0 {
+			http.Error(w, "invalid report ID", http.StatusBadRequest)
+			return
+		}
+		report, err := h.adapter.GetReport(id)
+		if err == ErrReportNotReady {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
@@ -0,0 +1,141 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The paths of the scanner adapter protocol, relative to the URL of the scanner adapter:
+//
+//	GET  /api/v1/metadata          returns the Metadata
+//	POST /api/v1/scan              accepts the ScanRequest, responds 202 with the ReportID
+//	GET  /api/v1/scan/{id}/report  responds 200 with the Report, or 202 if the scanning is in progress
+const (
+	metadataPath = "/api/v1/metadata"
+	scanPath     = "/api/v1/scan"
+	reportPath   = "/api/v1/scan/%s/report"
+)
+
+// httpAdapter calls the scanner adapter service with the scanner adapter protocol
+type httpAdapter struct {
+	url        string
+	authHeader string
+	client     *http.Client
+}
+
+// NewHTTPAdapter returns the adapter calling the scanner adapter service at the URL,
+// the auth header is sent as the "Authorization" header if it is set
+func NewHTTPAdapter(url, authHeader string, skipCertVerify bool) Adapter {
+	return &httpAdapter{
+		url:        strings.TrimSuffix(url, "/"),
+		authHeader: authHeader,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: skipCertVerify,
+				},
+			},
+		},
+	}
+}
+
+func (h *httpAdapter) Metadata() (*Metadata, error) {
+	m := &Metadata{}
+	if _, err := h.do(http.MethodGet, metadataPath, nil, m, http.StatusOK); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (h *httpAdapter) Scan(req *ScanRequest) (string, error) {
+	id := &ReportID{}
+	if _, err := h.do(http.MethodPost, scanPath, req, id, http.StatusAccepted); err != nil {
+		return "", err
+	}
+	if len(id.ID) == 0 {
+		return "", fmt.Errorf("no report ID is returned by the scanner")
+	}
+	return id.ID, nil
+}
+
+func (h *httpAdapter) GetReport(id string) (*Report, error) {
+	report := &Report{}
+	code, err := h.do(http.MethodGet, fmt.Sprintf(reportPath, url.PathEscape(id)), nil, report,
+		http.StatusOK, http.StatusAccepted)
+	if err != nil {
+		return nil, err
+	}
+	if code == http.StatusAccepted {
+		return nil, ErrReportNotReady
+	}
+	return report, nil
+}
+
+// do sends the request with the body in json, and decodes the response into v if the status code
+// is the first expected one
+func (h *httpAdapter) do(method, path string, body, v interface{}, expectedCodes ...int) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, h.url+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if len(h.authHeader) > 0 {
+		req.Header.Set("Authorization", h.authHeader)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	for i, code := range expectedCodes {
+		if resp.StatusCode != code {
+			continue
+		}
+		if i == 0 && v != nil {
+			if err := json.Unmarshal(data, v); err != nil {
+				return 0, fmt.Errorf("failed to decode the response of %s %s: %v", method, path, err)
+			}
+		}
+		return code, nil
+	}
+	return 0, fmt.Errorf("unexpected status code of %s %s: %d, %s", method, path, resp.StatusCode, string(data))
+}
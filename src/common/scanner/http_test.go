@@ -0,0 +1,98 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdapter returns the report after it is polled once
+type fakeAdapter struct {
+	request *ScanRequest
+	polled  bool
+}
+
+func (f *fakeAdapter) Metadata() (*Metadata, error) {
+	return &Metadata{Name: "fake", Vendor: "Harbor", Version: "1.0"}, nil
+}
+
+func (f *fakeAdapter) Scan(req *ScanRequest) (string, error) {
+	f.request = req
+	return "report/1", nil
+}
+
+func (f *fakeAdapter) GetReport(id string) (*Report, error) {
+	if id != "report/1" {
+		return nil, ErrReportNotReady
+	}
+	if !f.polled {
+		f.polled = true
+		return nil, ErrReportNotReady
+	}
+	return report, nil
+}
+
+func TestHTTPAdapter(t *testing.T) {
+	fake := &fakeAdapter{}
+	var authHeader string
+	handler := NewHandler(fake)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		authHeader = req.Header.Get("Authorization")
+		handler.ServeHTTP(w, req)
+	}))
+	defer server.Close()
+
+	a := NewHTTPAdapter(server.URL+"/", "Bearer secret", false)
+
+	m, err := a.Metadata()
+	require.Nil(t, err)
+	assert.Equal(t, "fake", m.Name)
+	assert.Equal(t, "Bearer secret", authHeader)
+
+	id, err := a.Scan(&ScanRequest{
+		Registry: Registry{URL: "http://registry:5000", Authorization: "Bearer token"},
+		Artifact: Artifact{Repository: "library/hello-world", Tag: "latest", Digest: "sha256:abc"},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, "report/1", id)
+	require.NotNil(t, fake.request)
+	assert.Equal(t, "library/hello-world", fake.request.Artifact.Repository)
+	assert.Equal(t, "Bearer token", fake.request.Registry.Authorization)
+
+	_, err = a.GetReport(id)
+	assert.Equal(t, ErrReportNotReady, err)
+	r, err := a.GetReport(id)
+	require.Nil(t, err)
+	assert.Equal(t, 4, r.Packages)
+	assert.Len(t, r.Vulnerabilities, 3)
+}
+
+func TestHTTPAdapterError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	a := NewHTTPAdapter(server.URL, "", false)
+	_, err := a.Metadata()
+	assert.NotNil(t, err)
+	_, err = a.Scan(&ScanRequest{})
+	assert.NotNil(t, err)
+	_, err = a.GetReport("1")
+	assert.NotNil(t, err)
+}
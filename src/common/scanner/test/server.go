@@ -0,0 +1,73 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/goharbor/harbor/src/common/scanner"
+)
+
+// Adapter is the in-memory scanner adapter for testing, the report of a scan
+// becomes ready after it is retrieved once
+type Adapter struct {
+	sync.Mutex
+	// the report returned for every scan
+	Report *scanner.Report
+	// the requests received
+	Requests []*scanner.ScanRequest
+	polled   map[string]bool
+}
+
+// Metadata ...
+func (a *Adapter) Metadata() (*scanner.Metadata, error) {
+	return &scanner.Metadata{
+		Name:    "Mock",
+		Vendor:  "Harbor",
+		Version: "1.0",
+	}, nil
+}
+
+// Scan ...
+func (a *Adapter) Scan(req *scanner.ScanRequest) (string, error) {
+	a.Lock()
+	defer a.Unlock()
+	a.Requests = append(a.Requests, req)
+	return fmt.Sprintf("report-%d", len(a.Requests)), nil
+}
+
+// GetReport ...
+func (a *Adapter) GetReport(id string) (*scanner.Report, error) {
+	a.Lock()
+	defer a.Unlock()
+	if a.polled == nil {
+		a.polled = map[string]bool{}
+	}
+	if !a.polled[id] {
+		a.polled[id] = true
+		return nil, scanner.ErrReportNotReady
+	}
+	if a.Report == nil {
+		return &scanner.Report{}, nil
+	}
+	return a.Report, nil
+}
+
+// NewMockServer returns the scanner adapter service serving the adapter
+func NewMockServer(adapter *Adapter) *httptest.Server {
+	return httptest.NewServer(scanner.NewHandler(adapter))
+}
@@ -16,6 +16,7 @@ package security
 
 import (
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
 )
 
 // Context abstracts the operations related with authN and authZ
@@ -38,4 +39,6 @@ type Context interface {
 	GetMyProjects() ([]*models.Project, error)
 	// Get user's role in provided project
 	GetProjectRoles(projectIDOrName interface{}) []int
+	// Can returns whether the user can do action on resource
+	Can(action rbac.Action, resource rbac.Resource) bool
 }
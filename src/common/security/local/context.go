@@ -142,6 +142,9 @@ func (s *SecurityContext) GetProjectRoles(projectIDOrName interface{}) []int {
 			roles = append(roles, common.RoleDeveloper)
 		case "RS":
 			roles = append(roles, common.RoleGuest)
+		default:
+			// custom role
+			roles = append(roles, role.RoleID)
 		}
 	}
 	if len(roles) != 0 {
@@ -150,6 +153,20 @@ func (s *SecurityContext) GetProjectRoles(projectIDOrName interface{}) []int {
 	return s.GetRolesByGroup(projectIDOrName)
 }
 
+// GetRolePolicies returns the policies of the custom role
+func (s *SecurityContext) GetRolePolicies(roleID int) []*rbac.Policy {
+	role, err := dao.GetRoleByID(roleID)
+	if err != nil {
+		log.Errorf("failed to get role %d: %v", roleID, err)
+		return nil
+	}
+	if role == nil {
+		log.Debugf("role %d not found", roleID)
+		return nil
+	}
+	return role.RBACPolicies()
+}
+
 // GetRolesByGroup - Get the group role of current user to the project
 func (s *SecurityContext) GetRolesByGroup(projectIDOrName interface{}) []int {
 	var roles []int
@@ -165,7 +182,18 @@ func (s *SecurityContext) GetRolesByGroup(projectIDOrName interface{}) []int {
 	if err != nil {
 		return nil
 	}
-	return roles
+	// Get role by the groups which are not identified by DN, e.g. OIDC group
+	groupIDs := []int{}
+	for _, g := range user.GroupList {
+		if g.GroupType != common.LdapGroupType && g.ID > 0 {
+			groupIDs = append(groupIDs, g.ID)
+		}
+	}
+	idRoles, err := dao.GetRolesByGroupID(project.ProjectID, groupIDs)
+	if err != nil {
+		return nil
+	}
+	return append(roles, idRoles...)
 }
 
 // GetMyProjects ...
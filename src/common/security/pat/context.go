@@ -0,0 +1,109 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pat
+
+import (
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/security"
+	"github.com/goharbor/harbor/src/common/security/robot"
+	"github.com/goharbor/harbor/src/core/promgr"
+)
+
+// SecurityContext implements security.Context interface for the requests authenticated by a personal
+// access token, it has the permissions of the user, which are limited to the access of the token if
+// the token is scoped. The access is checked with the same model as the robot accounts.
+type SecurityContext struct {
+	security.Context
+	token *models.PersonalAccessToken
+	scope *robot.SecurityContext
+}
+
+// NewSecurityContext wraps the security context of the user who owns the token
+func NewSecurityContext(user security.Context, token *models.PersonalAccessToken, pm promgr.ProjectManager) *SecurityContext {
+	s := &SecurityContext{
+		Context: user,
+		token:   token,
+	}
+	if token != nil && token.Scoped() {
+		s.scope = robot.NewSecurityContext(&models.Robot{
+			Name:   user.GetUsername(),
+			Access: token.Access,
+		}, pm)
+	}
+	return s
+}
+
+// GetToken returns the personal access token of the request
+func (s *SecurityContext) GetToken() *models.PersonalAccessToken {
+	return s.token
+}
+
+// IsSysAdmin returns false for the scoped token as its access only covers the resources of the projects
+func (s *SecurityContext) IsSysAdmin() bool {
+	return s.scope == nil && s.Context.IsSysAdmin()
+}
+
+// HasReadPerm returns whether the user has read permission to the project within the scope
+func (s *SecurityContext) HasReadPerm(projectIDOrName interface{}) bool {
+	return s.Context.HasReadPerm(projectIDOrName) && (s.scope == nil || s.scope.HasReadPerm(projectIDOrName))
+}
+
+// HasWritePerm returns whether the user has write permission to the project within the scope
+func (s *SecurityContext) HasWritePerm(projectIDOrName interface{}) bool {
+	return s.Context.HasWritePerm(projectIDOrName) && (s.scope == nil || s.scope.HasWritePerm(projectIDOrName))
+}
+
+// HasAllPerm returns whether the user has all permissions to the project, the scoped token never has
+func (s *SecurityContext) HasAllPerm(projectIDOrName interface{}) bool {
+	return s.scope == nil && s.Context.HasAllPerm(projectIDOrName)
+}
+
+// Can returns whether the user can do action on resource within the scope
+func (s *SecurityContext) Can(action rbac.Action, resource rbac.Resource) bool {
+	return s.Context.Can(action, resource) && (s.scope == nil || s.scope.Can(action, resource))
+}
+
+// GetProjectRoles returns the roles of the user to the project, none is returned for the projects
+// out of the scope
+func (s *SecurityContext) GetProjectRoles(projectIDOrName interface{}) []int {
+	if s.scope != nil && !s.scope.HasReadPerm(projectIDOrName) {
+		return []int{}
+	}
+	return s.Context.GetProjectRoles(projectIDOrName)
+}
+
+// GetMyProjects returns the projects of the user within the scope
+func (s *SecurityContext) GetMyProjects() ([]*models.Project, error) {
+	projects, err := s.Context.GetMyProjects()
+	if err != nil || s.scope == nil {
+		return projects, err
+	}
+	scoped, err := s.scope.GetMyProjects()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[int64]bool{}
+	for _, p := range scoped {
+		ids[p.ProjectID] = true
+	}
+	result := []*models.Project{}
+	for _, p := range projects {
+		if ids[p.ProjectID] {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
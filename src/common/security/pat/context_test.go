@@ -0,0 +1,138 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pat
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/security"
+	"github.com/goharbor/harbor/src/core/promgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePM struct {
+	promgr.ProjectManager
+	projects []*models.Project
+}
+
+func (f *fakePM) Get(projectIDOrName interface{}) (*models.Project, error) {
+	for _, p := range f.projects {
+		if p.ProjectID == projectIDOrName || p.Name == projectIDOrName {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// fakeUserContext is a project admin of all the projects
+type fakeUserContext struct {
+	security.Context
+	pm       *fakePM
+	sysAdmin bool
+}
+
+func (f *fakeUserContext) GetUsername() string {
+	return "user"
+}
+
+func (f *fakeUserContext) IsSysAdmin() bool {
+	return f.sysAdmin
+}
+
+func (f *fakeUserContext) HasReadPerm(projectIDOrName interface{}) bool {
+	return true
+}
+
+func (f *fakeUserContext) HasWritePerm(projectIDOrName interface{}) bool {
+	return true
+}
+
+func (f *fakeUserContext) HasAllPerm(projectIDOrName interface{}) bool {
+	return true
+}
+
+func (f *fakeUserContext) Can(action rbac.Action, resource rbac.Resource) bool {
+	return true
+}
+
+func (f *fakeUserContext) GetProjectRoles(projectIDOrName interface{}) []int {
+	return []int{common.RoleProjectAdmin}
+}
+
+func (f *fakeUserContext) GetMyProjects() ([]*models.Project, error) {
+	return f.pm.projects, nil
+}
+
+var (
+	pm = &fakePM{
+		projects: []*models.Project{
+			{ProjectID: 1, Name: "library", Metadata: map[string]string{models.ProMetaPublic: "true"}},
+			{ProjectID: 2, Name: "team-a"},
+			{ProjectID: 3, Name: "team-b"},
+		},
+	}
+	scoped = &models.PersonalAccessToken{
+		Name: "ci",
+		Access: []*models.ResourceActions{
+			{Name: "/project/2/image", Actions: []string{"pull", "push"}},
+		},
+	}
+)
+
+func TestUnscoped(t *testing.T) {
+	ctx := NewSecurityContext(&fakeUserContext{pm: pm, sysAdmin: true}, &models.PersonalAccessToken{Name: "all"}, pm)
+	assert.True(t, ctx.IsSysAdmin())
+	assert.True(t, ctx.HasAllPerm("team-b"))
+	assert.True(t, ctx.Can(project.ActionPush, rbac.NewProjectNamespace(int64(3), false).Resource(project.ResourceImage)))
+	assert.Equal(t, []int{common.RoleProjectAdmin}, ctx.GetProjectRoles("team-b"))
+	projects, err := ctx.GetMyProjects()
+	require.Nil(t, err)
+	assert.Len(t, projects, 3)
+}
+
+func TestScoped(t *testing.T) {
+	ctx := NewSecurityContext(&fakeUserContext{pm: pm, sysAdmin: true}, scoped, pm)
+	assert.Equal(t, "user", ctx.GetUsername())
+	assert.Equal(t, scoped, ctx.GetToken())
+	assert.False(t, ctx.IsSysAdmin())
+
+	assert.True(t, ctx.HasReadPerm("team-a"))
+	assert.True(t, ctx.HasWritePerm("team-a"))
+	assert.False(t, ctx.HasAllPerm("team-a"))
+	assert.False(t, ctx.HasReadPerm("team-b"))
+	assert.False(t, ctx.HasWritePerm("team-b"))
+	// the public project is readable
+	assert.True(t, ctx.HasReadPerm("library"))
+	assert.False(t, ctx.HasWritePerm("library"))
+
+	teamA := rbac.NewProjectNamespace(int64(2), false)
+	assert.True(t, ctx.Can(project.ActionPush, teamA.Resource(project.ResourceImage)))
+	// the project admin permissions of the user are limited to the scope
+	assert.False(t, ctx.Can(project.ActionCreate, teamA.Resource(project.ResourceMember)))
+	assert.False(t, ctx.Can(project.ActionCreate, teamA.Resource(project.ResourceHelmChart)))
+	assert.False(t, ctx.Can(project.ActionPull, rbac.NewProjectNamespace(int64(3), false).Resource(project.ResourceImage)))
+
+	assert.Equal(t, []int{common.RoleProjectAdmin}, ctx.GetProjectRoles("team-a"))
+	assert.Empty(t, ctx.GetProjectRoles("team-b"))
+	projects, err := ctx.GetMyProjects()
+	require.Nil(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, "team-a", projects[0].Name)
+}
@@ -0,0 +1,178 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"strings"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/promgr"
+)
+
+// SecurityContext implements security.Context interface based on the access granted to a robot account
+type SecurityContext struct {
+	robot *models.Robot
+	pm    promgr.ProjectManager
+}
+
+// NewSecurityContext ...
+func NewSecurityContext(robot *models.Robot, pm promgr.ProjectManager) *SecurityContext {
+	return &SecurityContext{
+		robot: robot,
+		pm:    pm,
+	}
+}
+
+// IsAuthenticated returns true if the robot has been authenticated
+func (s *SecurityContext) IsAuthenticated() bool {
+	return s.robot != nil
+}
+
+// GetUsername returns the name of the authenticated robot
+// It returns null if the robot has not been authenticated
+func (s *SecurityContext) GetUsername() string {
+	if !s.IsAuthenticated() {
+		return ""
+	}
+	return s.robot.Name
+}
+
+// IsSysAdmin robot cannot be a system admin
+func (s *SecurityContext) IsSysAdmin() bool {
+	return false
+}
+
+// IsSolutionUser robot cannot be a solution user
+func (s *SecurityContext) IsSolutionUser() bool {
+	return false
+}
+
+// HasReadPerm returns whether the robot can pull the images of the project
+func (s *SecurityContext) HasReadPerm(projectIDOrName interface{}) bool {
+	return s.Can(project.ActionPull, rbac.NewProjectNamespace(projectIDOrName, false).Resource(project.ResourceImage))
+}
+
+// HasWritePerm returns whether the robot can push images to the project
+func (s *SecurityContext) HasWritePerm(projectIDOrName interface{}) bool {
+	return s.Can(project.ActionPush, rbac.NewProjectNamespace(projectIDOrName, false).Resource(project.ResourceImage))
+}
+
+// HasAllPerm robot cannot be a project admin
+func (s *SecurityContext) HasAllPerm(projectIDOrName interface{}) bool {
+	return false
+}
+
+// Can returns whether the robot can do action on resource, the access of the robot
+// is granted on the project IDs so the project name in resource is resolved first
+func (s *SecurityContext) Can(action rbac.Action, resource rbac.Resource) bool {
+	if !s.IsAuthenticated() {
+		return false
+	}
+	ns, err := resource.GetNamespace()
+	if err != nil || ns.Kind() != "project" {
+		return false
+	}
+	pro, err := s.pm.Get(ns.Identity())
+	if err != nil {
+		log.Errorf("failed to get project %v: %v", ns.Identity(), err)
+		return false
+	}
+	if pro == nil {
+		return false
+	}
+	subresource := strings.TrimPrefix(strings.TrimPrefix(resource.String(), ns.Resource().String()), "/")
+	namespace := rbac.NewProjectNamespace(pro.ProjectID, pro.IsPublic())
+	target := namespace.Resource(rbac.Resource(subresource))
+
+	// the robot has the same permissions with the anonymous user on the public projects
+	if rbac.HasPermission(project.NewUser(s, namespace), target, action) {
+		return true
+	}
+	switch rbac.Resource(subresource) {
+	case project.ResourceImage, project.ResourceHelmChart, project.ResourceHelmRepo:
+		return rbac.HasPermission(&robot{s.robot}, target, action)
+	}
+	// the resources out of the access model of the robot are granted according to
+	// the role the access of the robot is equivalent to
+	return rbac.HasPermission(project.NewUser(s, namespace, s.GetProjectRoles(pro.ProjectID)...), target, action)
+}
+
+// GetProjectRoles returns the role the access of the robot is equivalent to on the project
+func (s *SecurityContext) GetProjectRoles(projectIDOrName interface{}) []int {
+	if s.HasWritePerm(projectIDOrName) {
+		return []int{common.RoleDeveloper}
+	}
+	if s.HasReadPerm(projectIDOrName) {
+		return []int{common.RoleGuest}
+	}
+	return []int{}
+}
+
+// GetMyProjects returns the projects the robot is granted access to
+func (s *SecurityContext) GetMyProjects() ([]*models.Project, error) {
+	projects := []*models.Project{}
+	if !s.IsAuthenticated() {
+		return projects, nil
+	}
+	seen := map[int64]bool{}
+	for _, access := range s.robot.Access {
+		id, _, err := models.ParseRobotAccess(access)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		pro, err := s.pm.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if pro != nil {
+			projects = append(projects, pro)
+		}
+	}
+	return projects, nil
+}
+
+// robot implements the rbac.User interface with the access granted to the robot account
+type robot struct {
+	*models.Robot
+}
+
+// GetUserName returns the name of the robot
+func (r *robot) GetUserName() string {
+	return r.Name
+}
+
+// GetPolicies returns a policy for each action of the access
+func (r *robot) GetPolicies() []*rbac.Policy {
+	policies := []*rbac.Policy{}
+	for _, access := range r.Access {
+		for _, action := range access.Actions {
+			policies = append(policies, &rbac.Policy{
+				Resource: rbac.Resource(access.Name),
+				Action:   rbac.Action(action),
+			})
+		}
+	}
+	return policies
+}
+
+// GetRoles robot has no roles
+func (r *robot) GetRoles() []rbac.Role {
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package robot
+
+import (
+	"testing"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/core/promgr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePM struct {
+	promgr.ProjectManager
+	projects []*models.Project
+}
+
+func (f *fakePM) Get(projectIDOrName interface{}) (*models.Project, error) {
+	for _, p := range f.projects {
+		if p.ProjectID == projectIDOrName || p.Name == projectIDOrName {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+var (
+	pm = &fakePM{
+		projects: []*models.Project{
+			{ProjectID: 1, Name: "library", Metadata: map[string]string{models.ProMetaPublic: "true"}},
+			{ProjectID: 2, Name: "team-a"},
+			{ProjectID: 3, Name: "team-b"},
+		},
+	}
+	ci = &models.Robot{
+		Name: "robot$ci",
+		Access: []*models.ResourceActions{
+			{Name: "/project/2/image", Actions: []string{"pull", "push"}},
+			{Name: "/project/3/image", Actions: []string{"pull"}},
+			{Name: "/project/3/helm-repo", Actions: []string{"read"}},
+		},
+	}
+)
+
+func TestIsAuthenticated(t *testing.T) {
+	assert.False(t, NewSecurityContext(nil, pm).IsAuthenticated())
+	ctx := NewSecurityContext(ci, pm)
+	assert.True(t, ctx.IsAuthenticated())
+	assert.Equal(t, "robot$ci", ctx.GetUsername())
+	assert.False(t, ctx.IsSysAdmin())
+	assert.False(t, ctx.IsSolutionUser())
+}
+
+func TestPerm(t *testing.T) {
+	ctx := NewSecurityContext(ci, pm)
+	// public project
+	assert.True(t, ctx.HasReadPerm("library"))
+	assert.False(t, ctx.HasWritePerm("library"))
+
+	assert.True(t, ctx.HasReadPerm(int64(2)))
+	assert.True(t, ctx.HasWritePerm("team-a"))
+	assert.False(t, ctx.HasAllPerm("team-a"))
+
+	assert.True(t, ctx.HasReadPerm("team-b"))
+	assert.False(t, ctx.HasWritePerm("team-b"))
+
+	// not existing project
+	assert.False(t, ctx.HasReadPerm("team-c"))
+}
+
+func TestCan(t *testing.T) {
+	ctx := NewSecurityContext(ci, pm)
+	assert.True(t, ctx.Can(project.ActionRead, rbac.NewProjectNamespace("team-b", false).Resource(project.ResourceHelmRepo)))
+	assert.False(t, ctx.Can(project.ActionRead, rbac.NewProjectNamespace("team-a", false).Resource(project.ResourceHelmRepo)))
+	assert.False(t, ctx.Can(project.ActionCreate, rbac.NewProjectNamespace("team-b", false).Resource(project.ResourceHelmChart)))
+
+	// the resources out of the access model of the robot
+	assert.True(t, ctx.Can(project.ActionUpdate, rbac.NewProjectNamespace("team-a", false).Resource(project.ResourceRepository)))
+	assert.False(t, ctx.Can(project.ActionDelete, rbac.NewProjectNamespace("team-a", false).Resource(project.ResourceRepository)))
+	assert.True(t, ctx.Can(project.ActionRead, rbac.NewProjectNamespace("team-b", false).Resource(project.ResourceMember)))
+	assert.False(t, ctx.Can(project.ActionUpdate, rbac.NewProjectNamespace("team-b", false).Resource(project.ResourceRepository)))
+	assert.False(t, ctx.Can(project.ActionRead, rbac.NewProjectNamespace("team-c", false).Resource(project.ResourceMember)))
+}
+
+func TestGetProjectRoles(t *testing.T) {
+	ctx := NewSecurityContext(ci, pm)
+	assert.Equal(t, []int{common.RoleDeveloper}, ctx.GetProjectRoles("team-a"))
+	assert.Equal(t, []int{common.RoleGuest}, ctx.GetProjectRoles("team-b"))
+}
+
+func TestGetMyProjects(t *testing.T) {
+	projects, err := NewSecurityContext(ci, pm).GetMyProjects()
+	require.Nil(t, err)
+	require.Equal(t, 2, len(projects))
+	assert.Equal(t, "team-a", projects[0].Name)
+	assert.Equal(t, "team-b", projects[1].Name)
+}
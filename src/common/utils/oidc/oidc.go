@@ -0,0 +1,334 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/goharbor/harbor/src/common/models"
+	"golang.org/x/oauth2"
+)
+
+const wellKnownPath = "/.well-known/openid-configuration"
+
+// metadata is the subset of the discovery document of the provider used by Harbor
+type metadata struct {
+	Issuer   string `json:"issuer"`
+	AuthURL  string `json:"authorization_endpoint"`
+	TokenURL string `json:"token_endpoint"`
+	JWKSURL  string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// Token wraps the token of the provider with the raw ID token
+type Token struct {
+	*oauth2.Token
+	IDToken string
+}
+
+// Claims holds the information of the user extracted from the ID token
+type Claims struct {
+	Subject  string
+	Issuer   string
+	Username string
+	Email    string
+	Name     string
+	Groups   []string
+}
+
+// SubIss returns the combination of the subject and issuer which identifies the user
+func (c *Claims) SubIss() string {
+	return c.Subject + c.Issuer
+}
+
+// Provider communicates with the OIDC provider, the discovery document and the
+// signing keys are loaded lazily and cached
+type Provider struct {
+	sync.Mutex
+	setting models.OIDCSetting
+	client  *http.Client
+	meta    *metadata
+	keys    map[string]*rsa.PublicKey
+}
+
+// NewProvider returns an instance of Provider with the settings
+func NewProvider(setting *models.OIDCSetting) *Provider {
+	return &Provider{
+		setting: *setting,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: !setting.VerifyCert,
+				},
+			},
+			Timeout: 30 * time.Second,
+		},
+		keys: map[string]*rsa.PublicKey{},
+	}
+}
+
+// Setting returns the settings the provider is created with
+func (p *Provider) Setting() models.OIDCSetting {
+	return p.setting
+}
+
+// AuthCodeURL returns the URL of the login page of the provider which redirects
+// back to Harbor with the authorization code and the state
+func (p *Provider) AuthCodeURL(state string) (string, error) {
+	cfg, err := p.oauth2Config()
+	if err != nil {
+		return "", err
+	}
+	return cfg.AuthCodeURL(state), nil
+}
+
+// ExchangeToken exchanges the authorization code for the token
+func (p *Provider) ExchangeToken(ctx context.Context, code string) (*Token, error) {
+	cfg, err := p.oauth2Config()
+	if err != nil {
+		return nil, err
+	}
+	t, err := cfg.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := t.Extra("id_token").(string)
+	if !ok || len(raw) == 0 {
+		return nil, errors.New("no id_token in the token response")
+	}
+	return &Token{
+		Token:   t,
+		IDToken: raw,
+	}, nil
+}
+
+// VerifyToken verifies the signature, issuer, audience and expiration of the raw ID token
+// and extracts the information of the user from it
+func (p *Provider) VerifyToken(ctx context.Context, raw string) (*Claims, error) {
+	meta, err := p.metadata()
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unsupported signing method: %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %v", err)
+	}
+	mc, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid claims of ID token")
+	}
+	if !mc.VerifyIssuer(meta.Issuer, true) {
+		return nil, fmt.Errorf("invalid issuer of ID token: %v", mc["iss"])
+	}
+	if !audienceContains(mc["aud"], p.setting.ClientID) {
+		return nil, fmt.Errorf("invalid audience of ID token: %v", mc["aud"])
+	}
+	if !mc.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("ID token is expired")
+	}
+	return p.claims(mc)
+}
+
+func (p *Provider) claims(mc jwt.MapClaims) (*Claims, error) {
+	c := &Claims{
+		Issuer: stringClaim(mc, "iss"),
+		Email:  stringClaim(mc, "email"),
+		Name:   stringClaim(mc, "name"),
+	}
+	c.Subject = stringClaim(mc, "sub")
+	if len(c.Subject) == 0 {
+		return nil, errors.New("no subject in ID token")
+	}
+	c.Username = stringClaim(mc, "preferred_username")
+	if len(c.Username) == 0 {
+		c.Username = c.Subject
+	}
+	if len(p.setting.GroupsClaim) > 0 {
+		switch groups := mc[p.setting.GroupsClaim].(type) {
+		case string:
+			c.Groups = []string{groups}
+		case []interface{}:
+			for _, g := range groups {
+				if s, ok := g.(string); ok && len(s) > 0 {
+					c.Groups = append(c.Groups, s)
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+func (p *Provider) oauth2Config() (*oauth2.Config, error) {
+	meta, err := p.metadata()
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     p.setting.ClientID,
+		ClientSecret: p.setting.ClientSecret,
+		RedirectURL:  p.setting.RedirectURL,
+		Scopes:       p.setting.Scope,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  meta.AuthURL,
+			TokenURL: meta.TokenURL,
+		},
+	}, nil
+}
+
+func (p *Provider) metadata() (*metadata, error) {
+	p.Lock()
+	defer p.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+	if len(p.setting.Endpoint) == 0 {
+		return nil, errors.New("the endpoint of OIDC provider is not set")
+	}
+	meta := &metadata{}
+	if err := p.get(p.setting.Endpoint+wellKnownPath, meta); err != nil {
+		return nil, fmt.Errorf("failed to read the discovery document of %s: %v", p.setting.Endpoint, err)
+	}
+	if len(meta.AuthURL) == 0 || len(meta.TokenURL) == 0 || len(meta.JWKSURL) == 0 {
+		return nil, fmt.Errorf("incomplete discovery document of %s", p.setting.Endpoint)
+	}
+	p.meta = meta
+	return meta, nil
+}
+
+// key returns the signing key, the key set is reloaded if the key isn't found
+// as the provider may have rotated the keys
+func (p *Provider) key(kid string) (*rsa.PublicKey, error) {
+	if key := p.cachedKey(kid); key != nil {
+		return key, nil
+	}
+	meta, err := p.metadata()
+	if err != nil {
+		return nil, err
+	}
+	set := &jsonWebKeySet{}
+	if err := p.get(meta.JWKSURL, set); err != nil {
+		return nil, fmt.Errorf("failed to read the signing keys: %v", err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		key, err := rsaPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		keys[k.Kid] = key
+	}
+	p.Lock()
+	p.keys = keys
+	p.Unlock()
+
+	if key := p.cachedKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("signing key %s not found", kid)
+}
+
+func (p *Provider) cachedKey(kid string) *rsa.PublicKey {
+	p.Lock()
+	defer p.Unlock()
+	if len(kid) == 0 && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
+
+func (p *Provider) get(url string, v interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d, response: %s", resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, v)
+}
+
+func rsaPublicKey(k jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus of key %s: %v", k.Kid, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent of key %s: %v", k.Kid, err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch a := aud.(type) {
+	case string:
+		return a == clientID
+	case []interface{}:
+		for _, v := range a {
+			if s, ok := v.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringClaim(mc jwt.MapClaims, name string) string {
+	s, _ := mc[name].(string)
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,138 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/oidc/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProvider(t *testing.T, claims map[string]interface{}) (*test.MockServer, *Provider) {
+	server := test.NewMockServer(&test.MockServerConfig{
+		ClientID:     "harbor",
+		ClientSecret: "secret",
+		Claims:       claims,
+	})
+	provider := NewProvider(&models.OIDCSetting{
+		Endpoint:     server.URL,
+		ClientID:     "harbor",
+		ClientSecret: "secret",
+		Scope:        []string{"openid", "profile"},
+		RedirectURL:  "https://harbor.example.com/c/oidc/callback",
+		GroupsClaim:  "groups",
+	})
+	return server, provider
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	server, provider := newProvider(t, nil)
+	defer server.Close()
+
+	u, err := provider.AuthCodeURL("state")
+	require.Nil(t, err)
+	parsed, err := url.Parse(u)
+	require.Nil(t, err)
+	assert.Equal(t, server.URL+"/auth", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "state", parsed.Query().Get("state"))
+	assert.Equal(t, "harbor", parsed.Query().Get("client_id"))
+	assert.Equal(t, "openid profile", parsed.Query().Get("scope"))
+
+	// the mock server redirects back with the code
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(u)
+	require.Nil(t, err)
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.Nil(t, err)
+	assert.Equal(t, test.MockCode, location.Query().Get("code"))
+	assert.Equal(t, "state", location.Query().Get("state"))
+}
+
+func TestExchangeAndVerifyToken(t *testing.T) {
+	server, provider := newProvider(t, map[string]interface{}{
+		"sub":                "1234",
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"name":               "Alice",
+		"groups":             []string{"dev", "harbor-admins"},
+	})
+	defer server.Close()
+
+	_, err := provider.ExchangeToken(context.Background(), "invalid")
+	assert.NotNil(t, err)
+
+	token, err := provider.ExchangeToken(context.Background(), test.MockCode)
+	require.Nil(t, err)
+	claims, err := provider.VerifyToken(context.Background(), token.IDToken)
+	require.Nil(t, err)
+	assert.Equal(t, "1234", claims.Subject)
+	assert.Equal(t, server.URL, claims.Issuer)
+	assert.Equal(t, "1234"+server.URL, claims.SubIss())
+	assert.Equal(t, "alice", claims.Username)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.Equal(t, "Alice", claims.Name)
+	assert.Equal(t, []string{"dev", "harbor-admins"}, claims.Groups)
+}
+
+func TestVerifyInvalidToken(t *testing.T) {
+	server, provider := newProvider(t, nil)
+	defer server.Close()
+
+	cases := []map[string]interface{}{
+		// no subject
+		{},
+		// other audience
+		{"sub": "1234", "aud": "other"},
+		// other issuer
+		{"sub": "1234", "iss": "https://other.example.com"},
+		// expired
+		{"sub": "1234", "exp": time.Now().Add(-time.Minute).Unix()},
+	}
+	for _, c := range cases {
+		_, err := provider.VerifyToken(context.Background(), server.IDToken(c))
+		assert.NotNil(t, err, "claims: %v", c)
+	}
+
+	// signed by another provider
+	other, _ := newProvider(t, nil)
+	defer other.Close()
+	_, err := provider.VerifyToken(context.Background(), other.IDToken(map[string]interface{}{
+		"sub": "1234",
+		"iss": server.URL,
+	}))
+	assert.NotNil(t, err)
+
+	// the audience can be an array and the subject is the username if no preferred_username
+	claims, err := provider.VerifyToken(context.Background(), server.IDToken(map[string]interface{}{
+		"sub":    "1234",
+		"aud":    []string{"other", "harbor"},
+		"groups": "dev",
+	}))
+	require.Nil(t, err)
+	assert.Equal(t, "1234", claims.Username)
+	assert.Equal(t, []string{"dev"}, claims.Groups)
+}
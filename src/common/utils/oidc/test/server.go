@@ -0,0 +1,168 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// MockCode is the only authorization code accepted by the mock server
+	MockCode = "mock-code"
+	mockKID  = "mock-key"
+)
+
+// MockServerConfig ...
+type MockServerConfig struct {
+	ClientID     string
+	ClientSecret string
+	// the claims of the ID token issued for the authorization code, the
+	// "iss", "aud" and "exp" are set by the server if they are absent
+	Claims map[string]interface{}
+}
+
+// MockServer is a minimal OIDC provider which supports the discovery, the
+// authorization code flow and the RS256 signed ID tokens
+type MockServer struct {
+	*httptest.Server
+	cfg *MockServerConfig
+	key *rsa.PrivateKey
+}
+
+// NewMockServer ...
+func NewMockServer(cfg *MockServerConfig) *MockServer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	m := &MockServer{
+		cfg: cfg,
+		key: key,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/keys", m.keys)
+	mux.HandleFunc("/auth", m.auth)
+	mux.HandleFunc("/token", m.token)
+	m.Server = httptest.NewServer(mux)
+	return m
+}
+
+// IDToken returns the ID token signed by the server with the claims
+func (m *MockServer) IDToken(claims map[string]interface{}) string {
+	mc := jwt.MapClaims{
+		"iss": m.URL,
+		"aud": m.cfg.ClientID,
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	for k, v := range claims {
+		mc[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, mc)
+	token.Header["kid"] = mockKID
+	raw, err := token.SignedString(m.key)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func (m *MockServer) discovery(rw http.ResponseWriter, req *http.Request) {
+	serveJSON(rw, map[string]string{
+		"issuer":                 m.URL,
+		"authorization_endpoint": m.URL + "/auth",
+		"token_endpoint":         m.URL + "/token",
+		"jwks_uri":               m.URL + "/keys",
+	})
+}
+
+func (m *MockServer) keys(rw http.ResponseWriter, req *http.Request) {
+	serveJSON(rw, map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kid": mockKID,
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+			},
+		},
+	})
+}
+
+// auth logs the user in without prompt and redirects back with the code
+func (m *MockServer) auth(rw http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	if q.Get("client_id") != m.cfg.ClientID || q.Get("response_type") != "code" {
+		http.Error(rw, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || len(redirect.Host) == 0 {
+		http.Error(rw, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", MockCode)
+	params.Set("state", q.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(rw, req, redirect.String(), http.StatusFound)
+}
+
+func (m *MockServer) token(rw http.ResponseWriter, req *http.Request) {
+	u, p, ok := req.BasicAuth()
+	if !ok || u != m.cfg.ClientID || p != m.cfg.ClientSecret {
+		http.Error(rw, "invalid client id/secret in header", http.StatusUnauthorized)
+		return
+	}
+	if gt := req.FormValue("grant_type"); gt != "authorization_code" {
+		http.Error(rw, fmt.Sprintf("invalid grant_type: %s", gt), http.StatusBadRequest)
+		return
+	}
+	if req.FormValue("code") != MockCode {
+		http.Error(rw, "invalid code", http.StatusBadRequest)
+		return
+	}
+	serveJSON(rw, map[string]interface{}{
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     m.IDToken(m.cfg.Claims),
+	})
+}
+
+func serveJSON(rw http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	if _, err := rw.Write(data); err != nil {
+		panic(err)
+	}
+}
@@ -439,6 +439,52 @@ func (r *Repository) PushBlob(digest string, size int64, data io.Reader) error {
 	return r.monolithicBlobUpload(location, digest, size, data)
 }
 
+// BlobUploadSize returns the size of the data uploaded to the blob upload session
+// located by location, which is the URL returned by registry when initiating the upload
+func (r *Repository) BlobUploadSize(location string) (int64, error) {
+	relative, err := isRelativeURL(location)
+	if err != nil {
+		return 0, err
+	}
+	if relative {
+		location = r.Endpoint.String() + location
+	}
+
+	req, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, parseError(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		// the range is "0-<offset of the last byte>", and "0-0" if nothing is uploaded
+		var start, end int64
+		if _, err := fmt.Sscanf(resp.Header.Get(http.CanonicalHeaderKey("Range")), "%d-%d", &start, &end); err != nil {
+			return 0, fmt.Errorf("malformed range of the blob upload: %v", err)
+		}
+		if end == 0 {
+			return 0, nil
+		}
+		return end + 1, nil
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return 0, &commonhttp.Error{
+		Code:    resp.StatusCode,
+		Message: string(b),
+	}
+}
+
 // DeleteBlob ...
 func (r *Repository) DeleteBlob(digest string) error {
 	req, err := http.NewRequest("DELETE", buildBlobURL(r.Endpoint.String(), r.Name, digest), nil)
@@ -202,6 +202,32 @@ func TestDeleteBlob(t *testing.T) {
 	}
 }
 
+func TestBlobUploadSize(t *testing.T) {
+	handler := test.Handler(&test.Response{
+		StatusCode: http.StatusNoContent,
+		Headers: map[string]string{
+			"Range": "0-1023",
+		},
+	})
+
+	server := test.NewServer(
+		&test.RequestHandlerMapping{
+			Method:  "GET",
+			Pattern: fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uuid),
+			Handler: handler,
+		})
+	defer server.Close()
+
+	client, err := newRepository(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create client for repository: %v", err)
+	}
+
+	size, err := client.BlobUploadSize(fmt.Sprintf("/v2/%s/blobs/uploads/%s?_state=state", repository, uuid))
+	require.Nil(t, err)
+	assert.Equal(t, int64(1024), size)
+}
+
 func TestManifestExist(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
@@ -71,10 +71,31 @@ var adminServerDefaultConfig = map[string]interface{}{
 	common.UAAClientSecret:            "testsecret",
 	common.UAAEndpoint:                "10.192.168.5",
 	common.UAAVerifyCert:              false,
+	common.OIDCName:                   "keycloak",
+	common.OIDCEndpoint:               "https://keycloak.example.com/auth/realms/harbor",
+	common.OIDCClientID:               "harbor",
+	common.OIDCClientSecret:           "secret",
+	common.OIDCScope:                  "openid,profile,email",
+	common.OIDCVerifyCert:             true,
+	common.OIDCGroupsClaim:            "groups",
+	common.OIDCAdminGroup:             "harbor-admins",
 	common.CoreURL:                    "http://myui:8888/",
 	common.JobServiceURL:              "http://myjob:8888/",
 	common.ReadOnly:                   false,
 	common.NotaryURL:                  "http://notary-server:4443",
+	common.StoragePerProject:          common.QuotaUnlimited,
+	common.CountPerProject:            common.QuotaUnlimited,
+	common.RobotTokenDuration:         30,
+	common.PasswordMinLength:          8,
+	common.PasswordRequireUppercase:   true,
+	common.PasswordRequireLowercase:   true,
+	common.PasswordRequireNumber:      true,
+	common.PasswordRequireSpecial:     false,
+	common.PasswordHistoryCount:       0,
+	common.PasswordMaxAge:             0,
+	common.LoginMaxFailures:           0,
+	common.LoginLockoutDuration:       30,
+	common.SysadminTOTPRequired:       false,
 }
 
 // NewAdminserver returns a mock admin server
@@ -0,0 +1,106 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the base32 encoded secret "12345678901234567890" of the SHA1 test vectors in RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, c := range cases {
+		code, err := Code(rfcSecret, Step(time.Unix(c.unix, 0)))
+		require.Nil(t, err)
+		assert.Equal(t, c.code, code, "time %d", c.unix)
+	}
+
+	_, err := Code("not-base32!", 1)
+	assert.NotNil(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, err := Code(rfcSecret, Step(now))
+	require.Nil(t, err)
+
+	step, ok, err := Validate(rfcSecret, code, now)
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// the code of the previous step is accepted within the skew
+	step, ok, err = Validate(rfcSecret, code, now.Add(Period*time.Second))
+	require.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok, err = Validate(rfcSecret, code, now.Add(2*Period*time.Second))
+	require.Nil(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = Validate(rfcSecret, "12345", now)
+	require.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.Nil(t, err)
+	assert.Len(t, secret, 32)
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	require.Nil(t, err)
+	_, ok, err := Validate(secret, code, now)
+	require.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Harbor", "john doe", rfcSecret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Harbor:john%20doe?"))
+	assert.Contains(t, uri, "secret="+rfcSecret)
+	assert.Contains(t, uri, "issuer=Harbor")
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.Nil(t, err)
+	require.Len(t, codes, 10)
+	set := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z0-9]{5}-[a-z0-9]{5}$`, code)
+		set[code] = true
+	}
+	assert.Len(t, set, 10)
+	assert.Equal(t, "abcde-12345", NormalizeRecoveryCode(" ABCDE-12345 "))
+}
@@ -0,0 +1,64 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	common_job "github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/core/api/models"
+)
+
+// AuditLogPurgeAPI handles the requests to run or schedule the job purging the
+// access logs older than the retention days, it shares the handlers of GC
+type AuditLogPurgeAPI struct {
+	GCAPI
+}
+
+// Prepare sets the job handled by the API
+func (a *AuditLogPurgeAPI) Prepare() {
+	a.jobName = common_job.AuditLogPurge
+	a.parameters = auditLogPurgeParameters
+	a.GCAPI.Prepare()
+}
+
+// auditLogPurgeParameters validates the parameters in the request
+func auditLogPurgeParameters(gr *models.GCReq) (map[string]interface{}, error) {
+	var days int
+	switch d := gr.Parameters["retention_days"].(type) {
+	case float64:
+		days = int(d)
+		if float64(days) != d {
+			return nil, fmt.Errorf("invalid retention_days: %v", d)
+		}
+	case nil:
+		return nil, fmt.Errorf("retention_days is required")
+	default:
+		return nil, fmt.Errorf("invalid retention_days: %v", d)
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("invalid retention_days: %d", days)
+	}
+	archive := false
+	if v, ok := gr.Parameters["archive"]; ok {
+		if archive, ok = v.(bool); !ok {
+			return nil, fmt.Errorf("invalid archive: %v", v)
+		}
+	}
+	return map[string]interface{}{
+		"retention_days": days,
+		"archive":        archive,
+	}, nil
+}
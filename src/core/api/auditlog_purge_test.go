@@ -0,0 +1,96 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogPurgeParameters(t *testing.T) {
+	cases := []struct {
+		params map[string]interface{}
+		valid  bool
+	}{
+		{map[string]interface{}{}, false},
+		{map[string]interface{}{"retention_days": float64(0)}, false},
+		{map[string]interface{}{"retention_days": float64(1.5)}, false},
+		{map[string]interface{}{"retention_days": "30"}, false},
+		{map[string]interface{}{"retention_days": float64(30), "archive": "yes"}, false},
+		{map[string]interface{}{"retention_days": float64(30)}, true},
+		{map[string]interface{}{"retention_days": float64(30), "archive": true}, true},
+	}
+	for _, c := range cases {
+		_, err := auditLogPurgeParameters(&models.GCReq{Parameters: c.params})
+		assert.Equal(t, c.valid, err == nil, "%v", c.params)
+	}
+
+	params, err := auditLogPurgeParameters(&models.GCReq{
+		Parameters: map[string]interface{}{"retention_days": float64(7), "archive": true},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, 7, params["retention_days"])
+	assert.Equal(t, true, params["archive"])
+}
+
+func TestAuditLogPurgeAPI(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/purgeaudit",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/purgeaudit/schedule",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, retention days is missing
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/system/purgeaudit/schedule",
+				credential: sysAdmin,
+				bodyJSON: &models.GCReq{
+					Schedule: &models.ScheduleParam{
+						Type: models.ScheduleManual,
+					},
+				},
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/system/purgeaudit",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
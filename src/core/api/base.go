@@ -19,7 +19,9 @@ import (
 
 	yaml "github.com/ghodss/yaml"
 	"github.com/goharbor/harbor/src/common/api"
+	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/security"
+	"github.com/goharbor/harbor/src/common/security/pat"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/filter"
@@ -66,6 +68,33 @@ func (b *BaseController) Prepare() {
 	b.ProjectMgr = pm
 }
 
+// isPersonalAccessToken returns whether the request is authenticated by a personal access token, which
+// can't be used to manage the account, the credentials or the second factor of the user
+func (b *BaseController) isPersonalAccessToken() bool {
+	_, ok := b.SecurityCtx.(*pat.SecurityContext)
+	return ok
+}
+
+// HasProjectPermission returns whether the request can do the action on the subresource of the project
+func (b *BaseController) HasProjectPermission(projectIDOrName interface{}, action rbac.Action, subresource ...rbac.Resource) bool {
+	resource := rbac.NewProjectNamespace(projectIDOrName, false).Resource(subresource...)
+	return b.SecurityCtx.Can(action, resource)
+}
+
+// RequireProjectAccess returns true if the request can do the action on the subresource of the project,
+// otherwise it renders 401 for the anonymous requests and 403 for the others and returns false
+func (b *BaseController) RequireProjectAccess(projectIDOrName interface{}, action rbac.Action, subresource ...rbac.Resource) bool {
+	if b.HasProjectPermission(projectIDOrName, action, subresource...) {
+		return true
+	}
+	if !b.SecurityCtx.IsAuthenticated() {
+		b.HandleUnauthorized()
+	} else {
+		b.HandleForbidden(b.SecurityCtx.GetUsername())
+	}
+	return false
+}
+
 // RenderFormatedError renders errors with well formted style `{"error": "This is an error"}`
 func (b *BaseController) RenderFormatedError(code int, err error) {
 	formatedErr := utils.WrapError(err)
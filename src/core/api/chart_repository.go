@@ -12,7 +12,14 @@ import (
 	"strings"
 
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/security/robot"
 	"github.com/goharbor/harbor/src/core/label"
+	"github.com/goharbor/harbor/src/core/webhook"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
 
 	"github.com/goharbor/harbor/src/chartserver"
 	hlog "github.com/goharbor/harbor/src/common/utils/log"
@@ -237,6 +244,8 @@ func (cra *ChartRepositoryAPI) DeleteChartVersion() {
 		cra.SendInternalServerError(err)
 		return
 	}
+
+	cra.publishChartEvent(models.WebhookEventDeleteChart, chartName, version)
 }
 
 // UploadChartVersion handles POST /api/:repo/charts
@@ -265,8 +274,15 @@ func (cra *ChartRepositoryAPI) UploadChartVersion() {
 		}
 	}
 
+	// Load the chart before proxying as the content is consumed by the backend
+	metadata := cra.chartMetadata()
+
 	// Directly proxy to the backend
 	chartController.ProxyTraffic(cra.Ctx.ResponseWriter, cra.Ctx.Request)
+
+	if metadata != nil && cra.Ctx.ResponseWriter.Status == http.StatusCreated {
+		cra.publishChartEvent(models.WebhookEventUploadChart, metadata.GetName(), metadata.GetVersion())
+	}
 }
 
 // UploadChartProvFile handles POST /api/:repo/prov
@@ -322,6 +338,52 @@ func (cra *ChartRepositoryAPI) DeleteChart() {
 		cra.SendInternalServerError(err)
 		return
 	}
+
+	versions := []string{}
+	for _, chartVersion := range chartVersions {
+		versions = append(versions, chartVersion.GetVersion())
+	}
+	cra.publishChartEvent(models.WebhookEventDeleteChart, chartName, versions...)
+}
+
+// chartMetadata loads the metadata of the chart uploaded, nil is returned if the chart can't be loaded
+func (cra *ChartRepositoryAPI) chartMetadata() *chart.Metadata {
+	var reader io.Reader
+	if isMultipartFormData(cra.Ctx.Request) {
+		file, _, err := cra.GetFile(formFieldNameForChart)
+		if err != nil {
+			hlog.Warningf("Failed to get the chart file uploaded: %v", err)
+			return nil
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		data, err := ioutil.ReadAll(cra.Ctx.Request.Body)
+		if err != nil {
+			hlog.Warningf("Failed to read the chart uploaded: %v", err)
+			return nil
+		}
+		cra.Ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(data))
+		reader = bytes.NewReader(data)
+	}
+
+	c, err := chartutil.LoadArchive(reader)
+	if err != nil {
+		hlog.Warningf("Failed to load the chart uploaded: %v", err)
+		return nil
+	}
+	return c.GetMetadata()
+}
+
+// publishChartEvent publishes the event of the chart versions to the webhook policies of the namespace
+func (cra *ChartRepositoryAPI) publishChartEvent(eventType, chartName string, versions ...string) {
+	project, err := cra.ProjectMgr.Get(cra.namespace)
+	if err != nil || project == nil {
+		hlog.Errorf("Failed to get the project %s, the webhook event is skipped: %v", cra.namespace, err)
+		return
+	}
+	webhook.Publish(project.ProjectID, webhook.NewChartPayload(eventType,
+		cra.SecurityCtx.GetUsername(), cra.namespace, chartName, versions...))
 }
 
 func (cra *ChartRepositoryAPI) removeLabelsFromChart(chartName, version string) error {
@@ -381,28 +443,33 @@ func (cra *ChartRepositoryAPI) requireAccess(namespace string, accessLevel uint)
 
 	var err error
 
-	switch theLevel {
-	// Should be system admin role
-	case accessLevelSystem:
-		if !cra.SecurityCtx.IsSysAdmin() {
-			err = errors.New("permission denied: system admin role is required")
-		}
-	case accessLevelAll:
-		if !cra.SecurityCtx.HasAllPerm(namespace) {
-			err = errors.New("permission denied: project admin or higher role is required")
-		}
-	case accessLevelWrite:
-		if !cra.SecurityCtx.HasWritePerm(namespace) {
-			err = errors.New("permission denied: developer or higher role is required")
-		}
-	case accessLevelRead:
-		if !cra.SecurityCtx.HasReadPerm(namespace) {
-			err = errors.New("permission denied: guest or higher role is required")
+	// the access of the robot accounts is granted per chart resource and action
+	if rc, ok := cra.SecurityCtx.(*robot.SecurityContext); ok && theLevel != accessLevelSystem {
+		err = requireRobotAccess(rc, namespace, cra.Ctx.Request)
+	} else {
+		switch theLevel {
+		// Should be system admin role
+		case accessLevelSystem:
+			if !cra.SecurityCtx.IsSysAdmin() {
+				err = errors.New("permission denied: system admin role is required")
+			}
+		case accessLevelAll:
+			if !cra.HasProjectPermission(namespace, project.ActionDelete, project.ResourceHelmChart) {
+				err = errors.New("permission denied: project admin or higher role is required")
+			}
+		case accessLevelWrite:
+			if !cra.HasProjectPermission(namespace, project.ActionCreate, project.ResourceHelmChart) {
+				err = errors.New("permission denied: developer or higher role is required")
+			}
+		case accessLevelRead:
+			if !cra.HasProjectPermission(namespace, project.ActionRead, project.ResourceHelmChart) {
+				err = errors.New("permission denied: guest or higher role is required")
+			}
+		default:
+			// access rejected for invalid scope
+			cra.SendForbiddenError(errors.New("unrecognized access scope"))
+			return false
 		}
-	default:
-		// access rejected for invalid scope
-		cra.SendForbiddenError(errors.New("unrecognized access scope"))
-		return false
 	}
 
 	// Access is not granted, check if user has authenticated
@@ -421,6 +488,27 @@ func (cra *ChartRepositoryAPI) requireAccess(namespace string, accessLevel uint)
 	return true
 }
 
+// requireRobotAccess checks the access of the robot to the chart resource the request targets,
+// the requests to the helm repository services require "read" on "helm-repo" and the others
+// require the action matching the method on "helm-chart"
+func requireRobotAccess(rc *robot.SecurityContext, namespace string, req *http.Request) error {
+	resource, action := project.ResourceHelmChart, project.ActionRead
+	switch req.Method {
+	case http.MethodPost:
+		action = project.ActionCreate
+	case http.MethodDelete:
+		action = project.ActionDelete
+	default:
+		if strings.HasPrefix(req.URL.Path, "/chartrepo/") {
+			resource = project.ResourceHelmRepo
+		}
+	}
+	if !rc.Can(action, rbac.NewProjectNamespace(namespace, false).Resource(resource)) {
+		return fmt.Errorf("permission denied: %s on %s is not granted to the robot", action, resource)
+	}
+	return nil
+}
+
 // formFile is used to represent the uploaded files in the form
 type formFile struct {
 	// form field key contains the form file
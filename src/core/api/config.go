@@ -118,6 +118,13 @@ func (c *ConfigAPI) Put() {
 		c.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 	}
 
+	// only the keys are recorded, the values may be passwords
+	for _, k := range common.HarborValidKeys {
+		if _, ok := cfg[k]; ok {
+			c.recordAuditLog(0, models.LogResourceTypeConfiguration, k, "update")
+		}
+	}
+
 	// Everything is ok, detect the configurations to confirm if the option we are caring is changed.
 	if err := watchConfigChanges(cfg); err != nil {
 		log.Errorf("Failed to watch configuration change with error: %s\n", err)
@@ -130,6 +137,7 @@ func (c *ConfigAPI) Reset() {
 		log.Errorf("failed to reset configurations: %v", err)
 		c.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 	}
+	c.recordAuditLog(0, models.LogResourceTypeConfiguration, "*", "reset")
 }
 
 func validateCfg(c map[string]interface{}) (bool, error) {
@@ -170,8 +178,8 @@ func validateCfg(c map[string]interface{}) (bool, error) {
 	}
 
 	if value, ok := strMap[common.AUTHMode]; ok {
-		if value != common.DBAuth && value != common.LDAPAuth && value != common.UAAAuth {
-			return false, fmt.Errorf("invalid %s, shoud be one of %s, %s, %s", common.AUTHMode, common.DBAuth, common.LDAPAuth, common.UAAAuth)
+		if value != common.DBAuth && value != common.LDAPAuth && value != common.UAAAuth && value != common.OIDCAuth {
+			return false, fmt.Errorf("invalid %s, shoud be one of %s, %s, %s, %s", common.AUTHMode, common.DBAuth, common.LDAPAuth, common.UAAAuth, common.OIDCAuth)
 		}
 		flag, err := authModeCanBeModified()
 		if err != nil {
@@ -232,6 +240,13 @@ func validateCfg(c map[string]interface{}) (bool, error) {
 			common.LDAPScopeSubtree)
 	}
 	for k, n := range numMap {
+		if (k == common.StoragePerProject || k == common.CountPerProject ||
+			k == common.RobotTokenDuration) && n == common.QuotaUnlimited {
+			continue
+		}
+		if k == common.RobotTokenDuration && n == 0 {
+			return false, fmt.Errorf("invalid %s: %d", k, n)
+		}
 		if n < 0 {
 			return false, fmt.Errorf("invalid %s: %d", k, n)
 		}
@@ -239,6 +254,9 @@ func validateCfg(c map[string]interface{}) (bool, error) {
 			k == common.PostGreSQLPort) && n > 65535 {
 			return false, fmt.Errorf("invalid %s: %d", k, n)
 		}
+		if k == common.PasswordMinLength && (n < 8 || n > models.PasswordMaxLength) {
+			return false, fmt.Errorf("invalid %s: %d, should be between 8 and %d", k, n, models.PasswordMaxLength)
+		}
 	}
 
 	if crt, ok := strMap[common.ProjectCreationRestriction]; ok &&
@@ -0,0 +1,99 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+)
+
+// CVEAllowlistAPI handles the requests on the system level CVE allowlist and the CVE allowlist of a project
+type CVEAllowlistAPI struct {
+	BaseController
+	// the project ID is models.SystemCVEAllowlistProjectID for the system level allowlist
+	projectID int64
+	resource  string
+}
+
+// Prepare validates the project and the permission, everyone authenticated can read the system level
+// allowlist while only the system admin can update it
+func (c *CVEAllowlistAPI) Prepare() {
+	c.BaseController.Prepare()
+
+	if !c.SecurityCtx.IsAuthenticated() {
+		c.HandleUnauthorized()
+		return
+	}
+
+	if len(c.GetStringFromPath(":pid")) == 0 {
+		if !c.Ctx.Input.IsGet() && !c.SecurityCtx.IsSysAdmin() {
+			c.HandleForbidden(c.SecurityCtx.GetUsername())
+			return
+		}
+		c.projectID = models.SystemCVEAllowlistProjectID
+		c.resource = "system"
+		return
+	}
+
+	pid, err := c.GetInt64FromPath(":pid")
+	if err != nil || pid <= 0 {
+		c.HandleBadRequest(fmt.Sprintf("invalid project ID: %s", c.GetStringFromPath(":pid")))
+		return
+	}
+	project, err := c.ProjectMgr.Get(pid)
+	if err != nil {
+		c.ParseAndHandleError(fmt.Sprintf("failed to get project %d", pid), err)
+		return
+	}
+	if project == nil {
+		c.HandleNotFound(fmt.Sprintf("project %d not found", pid))
+		return
+	}
+	if !c.RequireProjectAccess(pid, rbac_project.ActionOfMethod(c.Ctx.Request.Method), rbac_project.ResourceCVEAllowlist) {
+		return
+	}
+	c.projectID = pid
+	c.resource = project.Name
+}
+
+// Get returns the CVE allowlist
+func (c *CVEAllowlistAPI) Get() {
+	list, err := dao.GetCVEAllowlist(c.projectID)
+	if err != nil {
+		c.HandleInternalServerError(fmt.Sprintf("failed to get the CVE allowlist of project %d: %v", c.projectID, err))
+		return
+	}
+	c.Data["json"] = list
+	c.ServeJSON()
+}
+
+// Put replaces the items of the CVE allowlist with the ones in request
+func (c *CVEAllowlistAPI) Put() {
+	list := &models.CVEAllowlist{}
+	c.DecodeJSONReq(list)
+	if err := list.Validate(); err != nil {
+		c.HandleBadRequest(err.Error())
+		return
+	}
+	list.ProjectID = c.projectID
+	if err := dao.SetCVEAllowlist(list); err != nil {
+		c.HandleInternalServerError(fmt.Sprintf("failed to set the CVE allowlist of project %d: %v", c.projectID, err))
+		return
+	}
+	c.recordAuditLog(c.projectID, models.LogResourceTypeCVEAllowlist, c.resource, "update")
+}
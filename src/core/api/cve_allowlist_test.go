@@ -0,0 +1,134 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCVEAllowlistAPI(t *testing.T) {
+	defer dao.SetCVEAllowlist(&models.CVEAllowlist{ProjectID: models.SystemCVEAllowlistProjectID})
+	defer dao.SetCVEAllowlist(&models.CVEAllowlist{ProjectID: 1})
+
+	list := &models.CVEAllowlist{
+		Items: []*models.CVEAllowlistItem{
+			{CVEID: "cve-2019-0001"},
+			{CVEID: "CVE-2019-0002", ExpiresAt: 4102444800},
+		},
+	}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/system/cve_allowlist",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, only the system admin can update the system level allowlist
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/system/cve_allowlist",
+				bodyJSON:   list,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, duplicate items
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    "/api/system/cve_allowlist",
+				bodyJSON: &models.CVEAllowlist{
+					Items: []*models.CVEAllowlistItem{
+						{CVEID: "CVE-2019-0001"},
+						{CVEID: "CVE-2019-0001"},
+					},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/system/cve_allowlist",
+				bodyJSON:   list,
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, project not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/10000/cve_allowlist",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403, the guest can not update the allowlist of the project
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/cve_allowlist",
+				bodyJSON:   list,
+				credential: projGuest,
+			},
+			code: http.StatusForbidden,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/cve_allowlist",
+				bodyJSON:   &models.CVEAllowlist{Items: []*models.CVEAllowlistItem{{CVEID: "CVE-2019-0003"}}},
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	system := &models.CVEAllowlist{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/system/cve_allowlist",
+		credential: nonSysAdmin,
+	}, system)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(system.Items))
+	assert.Equal(t, "CVE-2019-0001", system.Items[0].CVEID)
+	assert.Equal(t, int64(4102444800), system.Items[1].ExpiresAt)
+
+	project := &models.CVEAllowlist{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/cve_allowlist",
+		credential: projGuest,
+	}, project)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), project.ProjectID)
+	require.Equal(t, 1, len(project.Items))
+	assert.Equal(t, "CVE-2019-0003", project.Items[0].CVEID)
+}
@@ -95,6 +95,7 @@ func init() {
 
 	filter.Init()
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.SecurityFilter)
+	beego.InsertFilter("/*", beego.BeforeRouter, filter.ScopedTokenFilter)
 
 	beego.Router("/api/health", &HealthAPI{}, "get:CheckHealth")
 	beego.Router("/api/search/", &SearchAPI{})
@@ -104,8 +105,17 @@ func init() {
 	beego.Router("/api/users", &UserAPI{}, "get:List;post:Post;delete:Delete;put:Put")
 	beego.Router("/api/users/:id([0-9]+)/password", &UserAPI{}, "put:ChangePassword")
 	beego.Router("/api/users/:id/sysadmin", &UserAPI{}, "put:ToggleUserAdminRole")
+	beego.Router("/api/users/:id([0-9]+)/unlock", &UserAPI{}, "put:Unlock")
+	beego.Router("/api/users/:id/cli_secret", &UserAPI{}, "post:GenerateCLISecret")
+	beego.Router("/api/users/:id([0-9]+)/totp", &UserAPI{}, "get:GetTOTP;post:EnrollTOTP;put:EnableTOTP;delete:DisableTOTP")
+	beego.Router("/api/users/:id([0-9]+)/totp/recovery_codes", &UserAPI{}, "post:RegenerateRecoveryCodes")
+	beego.Router("/api/users/:id([0-9]+)/tokens", &PersonalAccessTokenAPI{}, "get:List;post:Post")
+	beego.Router("/api/users/:id([0-9]+)/tokens/:tid([0-9]+)", &PersonalAccessTokenAPI{}, "get:Get;delete:Delete")
 	beego.Router("/api/projects/:id([0-9]+)/logs", &ProjectAPI{}, "get:Logs")
+	beego.Router("/api/projects/:id([0-9]+)/logs/export", &ProjectAPI{}, "get:ExportLogs")
 	beego.Router("/api/projects/:id([0-9]+)/_deletable", &ProjectAPI{}, "get:Deletable")
+	beego.Router("/api/projects/:id([0-9]+)/summary", &ProjectAPI{}, "get:Summary")
+	beego.Router("/api/projects/:id([0-9]+)/quota", &ProjectAPI{}, "put:PutQuota;delete:DeleteQuota")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/?:name", &MetadataAPI{}, "get:Get")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/", &MetadataAPI{}, "post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/:name", &MetadataAPI{}, "put:Put;delete:Delete")
@@ -115,6 +125,7 @@ func init() {
 	beego.Router("/api/users/?:id", &UserAPI{})
 	beego.Router("/api/usergroups/?:ugid([0-9]+)", &UserGroupAPI{})
 	beego.Router("/api/logs", &LogAPI{})
+	beego.Router("/api/logs/export", &LogAPI{}, "get:Export")
 	beego.Router("/api/repositories/*", &RepositoryAPI{}, "put:Put")
 	beego.Router("/api/repositories/*/labels", &RepositoryLabelAPI{}, "get:GetOfRepository;post:AddToRepository")
 	beego.Router("/api/repositories/*/labels/:id([0-9]+", &RepositoryLabelAPI{}, "delete:RemoveFromRepository")
@@ -152,9 +163,32 @@ func init() {
 	beego.Router("/api/system/gc/:id", &GCAPI{}, "get:GetGC")
 	beego.Router("/api/system/gc/:id([0-9]+)/log", &GCAPI{}, "get:GetLog")
 	beego.Router("/api/system/gc/schedule", &GCAPI{}, "get:Get;put:Put;post:Post")
+	beego.Router("/api/system/purgeaudit", &AuditLogPurgeAPI{}, "get:List")
+	beego.Router("/api/system/purgeaudit/:id([0-9]+)", &AuditLogPurgeAPI{}, "get:GetGC")
+	beego.Router("/api/system/purgeaudit/:id([0-9]+)/log", &AuditLogPurgeAPI{}, "get:GetLog")
+	beego.Router("/api/system/purgeaudit/schedule", &AuditLogPurgeAPI{}, "get:Get;put:Put;post:Post")
+	beego.Router("/api/system/cve_allowlist", &CVEAllowlistAPI{}, "get:Get;put:Put")
+	beego.Router("/api/scanners", &ScannerAPI{}, "get:List;post:Post")
+	beego.Router("/api/scanners/:id([0-9]+)", &ScannerAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/scanners/:id([0-9]+)/default", &ScannerAPI{}, "post:SetDefault")
+	beego.Router("/api/scanners/:id([0-9]+)/metadata", &ScannerAPI{}, "get:Metadata")
+	beego.Router("/api/roles", &RoleAPI{}, "get:List;post:Post")
+	beego.Router("/api/roles/:id([0-9]+)", &RoleAPI{}, "get:Get;put:Put;delete:Delete")
 
 	beego.Router("/api/projects/:pid([0-9]+)/robots/", &RobotAPI{}, "post:Post;get:List")
 	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)", &RobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)/token", &RobotAPI{}, "post:RefreshToken")
+	beego.Router("/api/robots", &RobotAPI{}, "post:Post;get:List")
+	beego.Router("/api/robots/:id([0-9]+)", &RobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/robots/:id([0-9]+)/token", &RobotAPI{}, "post:RefreshToken")
+	beego.Router("/api/projects/:pid([0-9]+)/retention", &RetentionAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/retention/executions", &RetentionAPI{}, "post:Run;get:ListExecutions")
+	beego.Router("/api/projects/:pid([0-9]+)/retention/executions/:id([0-9]+)", &RetentionAPI{}, "get:GetExecution")
+	beego.Router("/api/projects/:pid([0-9]+)/cve_allowlist", &CVEAllowlistAPI{}, "get:Get;put:Put")
+	beego.Router("/api/projects/:pid([0-9]+)/scanner", &ProjectScannerAPI{}, "get:Get;put:Put")
+	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies", &WebhookAPI{}, "get:List;post:Post")
+	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/:id([0-9]+)", &WebhookAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/:id([0-9]+)/executions", &WebhookAPI{}, "get:ListExecutions")
 
 	// Charts are controlled under projects
 	chartRepositoryAPIType := &ChartRepositoryAPI{}
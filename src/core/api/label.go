@@ -22,6 +22,7 @@ import (
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/replication"
 	"github.com/goharbor/harbor/src/replication/core"
 	rep_models "github.com/goharbor/harbor/src/replication/models"
@@ -65,11 +66,14 @@ func (l *LabelAPI) Prepare() {
 			return
 		}
 
-		if label.Scope == common.LabelScopeGlobal && !l.SecurityCtx.IsSysAdmin() ||
-			label.Scope == common.LabelScopeProject && !l.SecurityCtx.HasAllPerm(label.ProjectID) {
+		if label.Scope == common.LabelScopeGlobal && !l.SecurityCtx.IsSysAdmin() {
 			l.HandleForbidden(l.SecurityCtx.GetUsername())
 			return
 		}
+		if label.Scope == common.LabelScopeProject &&
+			!l.RequireProjectAccess(label.ProjectID, rbac_project.ActionOfMethod(method), rbac_project.ResourceLabel) {
+			return
+		}
 		l.label = label
 	}
 }
@@ -98,8 +102,7 @@ func (l *LabelAPI) Post() {
 			l.HandleNotFound(fmt.Sprintf("project %d not found", label.ProjectID))
 			return
 		}
-		if !l.SecurityCtx.HasAllPerm(label.ProjectID) {
-			l.HandleForbidden(l.SecurityCtx.GetUsername())
+		if !l.RequireProjectAccess(label.ProjectID, rbac_project.ActionCreate, rbac_project.ResourceLabel) {
 			return
 		}
 	}
@@ -147,15 +150,9 @@ func (l *LabelAPI) Get() {
 		return
 	}
 
-	if label.Scope == common.LabelScopeProject {
-		if !l.SecurityCtx.HasReadPerm(label.ProjectID) {
-			if !l.SecurityCtx.IsAuthenticated() {
-				l.HandleUnauthorized()
-				return
-			}
-			l.HandleForbidden(l.SecurityCtx.GetUsername())
-			return
-		}
+	if label.Scope == common.LabelScopeProject &&
+		!l.RequireProjectAccess(label.ProjectID, rbac_project.ActionRead, rbac_project.ResourceLabel) {
+		return
 	}
 
 	l.Data["json"] = label
@@ -189,12 +186,7 @@ func (l *LabelAPI) List() {
 			return
 		}
 
-		if !l.SecurityCtx.HasReadPerm(projectID) {
-			if !l.SecurityCtx.IsAuthenticated() {
-				l.HandleUnauthorized()
-				return
-			}
-			l.HandleForbidden(l.SecurityCtx.GetUsername())
+		if !l.RequireProjectAccess(projectID, rbac_project.ActionRead, rbac_project.ResourceLabel) {
 			return
 		}
 		query.ProjectID = projectID
@@ -294,11 +286,14 @@ func (l *LabelAPI) ListResources() {
 		return
 	}
 
-	if label.Scope == common.LabelScopeGlobal && !l.SecurityCtx.IsSysAdmin() ||
-		label.Scope == common.LabelScopeProject && !l.SecurityCtx.HasAllPerm(label.ProjectID) {
+	if label.Scope == common.LabelScopeGlobal && !l.SecurityCtx.IsSysAdmin() {
 		l.HandleForbidden(l.SecurityCtx.GetUsername())
 		return
 	}
+	if label.Scope == common.LabelScopeProject &&
+		!l.RequireProjectAccess(label.ProjectID, rbac_project.ActionRead, rbac_project.ResourceReplication) {
+		return
+	}
 
 	result, err := core.GlobalController.GetPolicies(rep_models.QueryParameter{})
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/core/label"
 )
 
@@ -23,20 +24,7 @@ func (lra *LabelResourceAPI) Prepare() {
 }
 
 func (lra *LabelResourceAPI) checkPermissions(project string) bool {
-	if lra.Ctx.Request.Method == http.MethodPost ||
-		lra.Ctx.Request.Method == http.MethodDelete {
-		if lra.SecurityCtx.HasWritePerm(project) {
-			return true
-		}
-	}
-
-	if lra.Ctx.Request.Method == http.MethodGet {
-		if lra.SecurityCtx.HasReadPerm(project) {
-			return true
-		}
-	}
-
-	return false
+	return lra.HasProjectPermission(project, rbac_project.ActionOfMethod(lra.Ctx.Request.Method), rbac_project.ResourceLabelResource)
 }
 
 func (lra *LabelResourceAPI) getLabelsOfResource(rType string, rIDOrName interface{}) {
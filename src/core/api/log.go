@@ -16,10 +16,19 @@ package api
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const (
+	logExportFormatCSV  = "csv"
+	logExportFormatJSON = "json"
+	logExportBatchSize  = 500
 )
 
 // LogAPI handles request api/logs
@@ -43,35 +52,62 @@ func (l *LogAPI) Prepare() {
 // Get returns the recent logs according to parameters
 func (l *LogAPI) Get() {
 	page, size := l.GetPaginationParams()
-	query := &models.LogQueryParam{
-		Username:   l.GetString("username"),
-		Repository: l.GetString("repository"),
-		Tag:        l.GetString("tag"),
-		Operations: l.GetStrings("operation"),
-		Pagination: &models.Pagination{
-			Page: page,
-			Size: size,
-		},
+	query, ok := l.logQuery()
+	if !ok {
+		return
+	}
+	if query == nil {
+		l.SetPaginationHeader(0, page, size)
+		l.Data["json"] = nil
+		l.ServeJSON()
+		return
+	}
+	query.Pagination = &models.Pagination{
+		Page: page,
+		Size: size,
 	}
 
-	timestamp := l.GetString("begin_timestamp")
-	if len(timestamp) > 0 {
-		t, err := utils.ParseTimeStamp(timestamp)
-		if err != nil {
-			l.HandleBadRequest(fmt.Sprintf("invalid begin_timestamp: %s", timestamp))
-			return
-		}
-		query.BeginTime = t
+	total, err := dao.GetTotalOfAccessLogs(query)
+	if err != nil {
+		l.HandleInternalServerError(fmt.Sprintf(
+			"failed to get total of access logs: %v", err))
+		return
 	}
 
-	timestamp = l.GetString("end_timestamp")
-	if len(timestamp) > 0 {
-		t, err := utils.ParseTimeStamp(timestamp)
-		if err != nil {
-			l.HandleBadRequest(fmt.Sprintf("invalid end_timestamp: %s", timestamp))
-			return
-		}
-		query.EndTime = t
+	logs, err := dao.GetAccessLogs(query)
+	if err != nil {
+		l.HandleInternalServerError(fmt.Sprintf(
+			"failed to get access logs: %v", err))
+		return
+	}
+
+	l.SetPaginationHeader(total, page, size)
+
+	l.Data["json"] = logs
+	l.ServeJSON()
+}
+
+// Export streams all the logs matching the parameters in CSV or JSON format
+func (l *LogAPI) Export() {
+	format, ok := l.exportFormat()
+	if !ok {
+		return
+	}
+	query, ok := l.logQuery()
+	if !ok {
+		return
+	}
+	l.exportLogs(query, format)
+}
+
+// logQuery returns the query built from the parameters, the query is limited to
+// the projects of the user if the user isn't system admin, nil is returned if the
+// user has no project
+func (l *LogAPI) logQuery() (*models.LogQueryParam, bool) {
+	query, err := l.parseLogQuery()
+	if err != nil {
+		l.HandleBadRequest(err.Error())
+		return nil, false
 	}
 
 	if !l.isSysAdmin {
@@ -79,14 +115,11 @@ func (l *LogAPI) Get() {
 		if err != nil {
 			l.HandleInternalServerError(fmt.Sprintf(
 				"failed to get projects of user %s: %v", l.username, err))
-			return
+			return nil, false
 		}
 
 		if len(projects) == 0 {
-			l.SetPaginationHeader(0, page, size)
-			l.Data["json"] = nil
-			l.ServeJSON()
-			return
+			return nil, true
 		}
 
 		ids := []int64{}
@@ -95,23 +128,112 @@ func (l *LogAPI) Get() {
 		}
 		query.ProjectIDs = ids
 	}
+	return query, true
+}
 
-	total, err := dao.GetTotalOfAccessLogs(query)
-	if err != nil {
-		l.HandleInternalServerError(fmt.Sprintf(
-			"failed to get total of access logs: %v", err))
-		return
+// parseLogQuery builds the query of the access logs from the parameters of the request
+func (b *BaseController) parseLogQuery() (*models.LogQueryParam, error) {
+	query := &models.LogQueryParam{
+		Username:      b.GetString("username"),
+		Repository:    b.GetString("repository"),
+		Tag:           b.GetString("tag"),
+		Operations:    b.GetStrings("operation"),
+		ResourceTypes: b.GetStrings("resource_type"),
+		Resource:      b.GetString("resource"),
 	}
 
-	logs, err := dao.GetAccessLogs(query)
-	if err != nil {
-		l.HandleInternalServerError(fmt.Sprintf(
-			"failed to get access logs: %v", err))
-		return
+	timestamp := b.GetString("begin_timestamp")
+	if len(timestamp) > 0 {
+		t, err := utils.ParseTimeStamp(timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid begin_timestamp: %s", timestamp)
+		}
+		query.BeginTime = t
 	}
 
-	l.SetPaginationHeader(total, page, size)
+	timestamp = b.GetString("end_timestamp")
+	if len(timestamp) > 0 {
+		t, err := utils.ParseTimeStamp(timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end_timestamp: %s", timestamp)
+		}
+		query.EndTime = t
+	}
+	return query, nil
+}
 
-	l.Data["json"] = logs
-	l.ServeJSON()
+// exportFormat returns the format of the export, "csv" by default
+func (b *BaseController) exportFormat() (string, bool) {
+	format := b.GetString("format", logExportFormatCSV)
+	if format != logExportFormatCSV && format != logExportFormatJSON {
+		b.HandleBadRequest(fmt.Sprintf("unsupported format: %s, only %s and %s are supported",
+			format, logExportFormatCSV, logExportFormatJSON))
+		return "", false
+	}
+	return format, true
+}
+
+// exportLogs streams the logs matching the query to the client, the logs are read from
+// the database in batches so the whole result is never held in memory. Nothing but the
+// header is exported if the query is nil.
+func (b *BaseController) exportLogs(query *models.LogQueryParam, format string) {
+	w := b.Ctx.ResponseWriter
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().UTC().Format("20060102150405"), format)
+	if format == logExportFormatCSV {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	var writer logWriter
+	if format == logExportFormatCSV {
+		writer = newCSVLogWriter(w)
+	} else {
+		writer = newJSONLogWriter(w)
+	}
+	if err := writer.begin(); err != nil {
+		log.Errorf("failed to export access logs: %v", err)
+		return
+	}
+	if query != nil {
+		err := dao.IterateAccessLogs(query, logExportBatchSize, func(logs []models.AccessLog) error {
+			for i := range logs {
+				if err := writer.write(&logs[i]); err != nil {
+					return err
+				}
+			}
+			if f, ok := w.ResponseWriter.(http.Flusher); ok {
+				f.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			// the status code has been sent, the client gets a truncated file
+			log.Errorf("failed to export access logs: %v", err)
+			return
+		}
+	}
+	if err := writer.end(); err != nil {
+		log.Errorf("failed to export access logs: %v", err)
+	}
+}
+
+// recordAuditLog records the operation done by the current user to the resource which isn't
+// in the registry, e.g. the members, the policies and the configurations
+func (b *BaseController) recordAuditLog(projectID int64, resourceType, resource, operation string) {
+	username := b.SecurityCtx.GetUsername()
+	go func() {
+		if err := dao.AddAccessLog(models.AccessLog{
+			Username:     username,
+			ProjectID:    projectID,
+			ResourceType: resourceType,
+			Resource:     resource,
+			Operation:    operation,
+			OpTime:       time.Now(),
+		}); err != nil {
+			log.Errorf("failed to add access log: %v", err)
+		}
+	}()
 }
@@ -14,8 +14,10 @@
 package api
 
 import (
+	"encoding/csv"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -94,3 +96,83 @@ func TestLogGet(t *testing.T) {
 	assert.Equal(t, tag, logs[0].RepoTag)
 	assert.Equal(t, operation, logs[0].Operation)
 }
+
+func TestLogFilterAndExport(t *testing.T) {
+	var projectID int64 = 1
+	username := "user_for_testing_log_export"
+	now := time.Now()
+	for _, resource := range []string{"library/members/alice", "library/members/bob", "library/robot$ci"} {
+		err := dao.AddAccessLog(models.AccessLog{
+			ProjectID:    projectID,
+			Username:     username,
+			Operation:    "create",
+			ResourceType: models.LogResourceTypeMember,
+			Resource:     resource,
+			OpTime:       now,
+		})
+		require.Nil(t, err)
+	}
+	defer dao.GetOrmer().QueryTable(&models.AccessLog{}).
+		Filter("username", username).Delete()
+
+	type query struct {
+		Username     string `url:"username"`
+		ResourceType string `url:"resource_type"`
+		Resource     string `url:"resource"`
+		Format       string `url:"format"`
+	}
+
+	// filter by the resource pattern
+	logs := []*models.AccessLog{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/logs",
+		credential: sysAdmin,
+		queryStruct: query{
+			Username:     username,
+			ResourceType: models.LogResourceTypeMember,
+			Resource:     "library/members/*",
+		},
+	}, &logs)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, models.LogResourceTypeMember, logs[0].ResourceType)
+
+	// unsupported export format
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:      http.MethodGet,
+			url:         "/api/logs/export",
+			credential:  sysAdmin,
+			queryStruct: query{Format: "xml"},
+		},
+		code: http.StatusBadRequest,
+	})
+
+	// export as CSV
+	resp, err := handle(&testingRequest{
+		method:      http.MethodGet,
+		url:         "/api/projects/1/logs/export",
+		credential:  projGuest,
+		queryStruct: query{Username: username},
+	})
+	require.Nil(t, err)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, strings.HasPrefix(resp.Header().Get("Content-Disposition"), "attachment;"))
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, 4, len(records))
+	assert.Equal(t, "log_id", records[0][0])
+	assert.Equal(t, username, records[1][2])
+
+	// export as JSON
+	logs = []*models.AccessLog{}
+	err = handleAndParse(&testingRequest{
+		method:      http.MethodGet,
+		url:         "/api/logs/export",
+		credential:  sysAdmin,
+		queryStruct: query{Username: username, Format: "json"},
+	}, &logs)
+	require.Nil(t, err)
+	assert.Equal(t, 3, len(logs))
+}
@@ -0,0 +1,100 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// logWriter writes the exported access logs
+type logWriter interface {
+	begin() error
+	write(*models.AccessLog) error
+	end() error
+}
+
+var csvLogHeader = []string{"log_id", "op_time", "username", "project_id", "operation",
+	"resource_type", "resource", "repo_name", "repo_tag"}
+
+type csvLogWriter struct {
+	w *csv.Writer
+}
+
+func newCSVLogWriter(w io.Writer) logWriter {
+	return &csvLogWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvLogWriter) begin() error {
+	return c.w.Write(csvLogHeader)
+}
+
+func (c *csvLogWriter) write(l *models.AccessLog) error {
+	// the csv writer is buffered, the data is written out whenever the buffer is full
+	return c.w.Write([]string{
+		strconv.Itoa(l.LogID),
+		l.OpTime.UTC().Format(time.RFC3339),
+		l.Username,
+		strconv.FormatInt(l.ProjectID, 10),
+		l.Operation,
+		l.ResourceType,
+		l.Resource,
+		l.RepoName,
+		l.RepoTag,
+	})
+}
+
+func (c *csvLogWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonLogWriter writes the logs as a JSON array
+type jsonLogWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONLogWriter(w io.Writer) logWriter {
+	return &jsonLogWriter{w: w}
+}
+
+func (j *jsonLogWriter) begin() error {
+	_, err := j.w.Write([]byte("["))
+	return err
+}
+
+func (j *jsonLogWriter) write(l *models.AccessLog) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		data = append([]byte(","), data...)
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonLogWriter) end() error {
+	_, err := j.w.Write([]byte("]"))
+	return err
+}
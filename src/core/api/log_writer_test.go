@@ -0,0 +1,88 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var writerTestLogs = []*models.AccessLog{
+	{
+		LogID:        1,
+		Username:     "admin",
+		ProjectID:    1,
+		Operation:    "update",
+		ResourceType: models.LogResourceTypeConfiguration,
+		Resource:     "auth_mode",
+		OpTime:       time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+	},
+	{
+		LogID:        2,
+		Username:     "admin",
+		ProjectID:    1,
+		Operation:    "push",
+		ResourceType: models.LogResourceTypeRepository,
+		Resource:     "library/hello-world:latest",
+		RepoName:     "library/hello-world",
+		RepoTag:      "latest",
+		OpTime:       time.Date(2019, 1, 2, 3, 4, 6, 0, time.UTC),
+	},
+}
+
+func writeLogs(t *testing.T, w logWriter) {
+	require.Nil(t, w.begin())
+	for _, l := range writerTestLogs {
+		require.Nil(t, w.write(l))
+	}
+	require.Nil(t, w.end())
+}
+
+func TestCSVLogWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeLogs(t, newCSVLogWriter(buf))
+	assert.Equal(t, "log_id,op_time,username,project_id,operation,resource_type,resource,repo_name,repo_tag\n"+
+		"1,2019-01-02T03:04:05Z,admin,1,update,configuration,auth_mode,,\n"+
+		"2,2019-01-02T03:04:06Z,admin,1,push,repository,library/hello-world:latest,library/hello-world,latest\n",
+		buf.String())
+
+	buf.Reset()
+	w := newCSVLogWriter(buf)
+	require.Nil(t, w.begin())
+	require.Nil(t, w.end())
+	assert.Equal(t, "log_id,op_time,username,project_id,operation,resource_type,resource,repo_name,repo_tag\n", buf.String())
+}
+
+func TestJSONLogWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writeLogs(t, newJSONLogWriter(buf))
+	logs := []*models.AccessLog{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &logs))
+	require.Equal(t, 2, len(logs))
+	assert.Equal(t, "auth_mode", logs[0].Resource)
+	assert.Equal(t, "latest", logs[1].RepoTag)
+
+	buf.Reset()
+	w := newJSONLogWriter(buf)
+	require.Nil(t, w.begin())
+	require.Nil(t, w.end())
+	assert.Equal(t, "[]", buf.String())
+}
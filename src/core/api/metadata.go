@@ -15,13 +15,16 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 
+	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/promgr/metamgr"
 )
@@ -74,15 +77,8 @@ func (m *MetadataAPI) Prepare() {
 
 	switch m.Ctx.Request.Method {
 	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete:
-		if !(m.Ctx.Request.Method == http.MethodGet && project.IsPublic()) {
-			if !m.SecurityCtx.IsAuthenticated() {
-				m.HandleUnauthorized()
-				return
-			}
-			if !m.SecurityCtx.HasReadPerm(project.ProjectID) {
-				m.HandleForbidden(m.SecurityCtx.GetUsername())
-				return
-			}
+		if !m.RequireProjectAccess(project.ProjectID, rbac_project.ActionOfMethod(m.Ctx.Request.Method), rbac_project.ResourceMetadata) {
+			return
 		}
 	default:
 		log.Debugf("%s method not allowed", m.Ctx.Request.Method)
@@ -128,6 +124,11 @@ func (m *MetadataAPI) Post() {
 	var metas map[string]string
 	m.DecodeJSONReq(&metas)
 
+	if _, exist := metas[models.ProMetaProxyCacheTarget]; exist {
+		m.HandleBadRequest("the proxy cache target can only be set when creating the project")
+		return
+	}
+
 	ms, err := validateProjectMetadata(metas)
 	if err != nil {
 		m.HandleBadRequest(err.Error())
@@ -155,6 +156,7 @@ func (m *MetadataAPI) Post() {
 		m.HandleInternalServerError(fmt.Sprintf("failed to create metadata for project %d: %v", m.project.ProjectID, err))
 		return
 	}
+	m.recordAuditLog(m.project.ProjectID, models.LogResourceTypeMetadata, m.project.Name+"/"+keys[0].String(), "create")
 
 	m.Ctx.ResponseWriter.WriteHeader(http.StatusCreated)
 }
@@ -170,6 +172,11 @@ func (m *MetadataAPI) Put() {
 		return
 	}
 
+	if m.name == models.ProMetaProxyCacheTarget {
+		m.HandleBadRequest("the proxy cache target can not be changed")
+		return
+	}
+
 	ms, err := validateProjectMetadata(map[string]string{
 		m.name: meta,
 	})
@@ -184,14 +191,20 @@ func (m *MetadataAPI) Put() {
 		m.HandleInternalServerError(fmt.Sprintf("failed to update metadata %s of project %d: %v", m.name, m.project.ProjectID, err))
 		return
 	}
+	m.recordAuditLog(m.project.ProjectID, models.LogResourceTypeMetadata, m.project.Name+"/"+m.name, "update")
 }
 
 // Delete ...
 func (m *MetadataAPI) Delete() {
+	if m.name == models.ProMetaProxyCacheTarget {
+		m.HandleBadRequest("the proxy cache target can not be changed")
+		return
+	}
 	if err := m.metaMgr.Delete(m.project.ProjectID, m.name); err != nil {
 		m.HandleInternalServerError(fmt.Sprintf("failed to delete metadata %s of project %d: %v", m.name, m.project.ProjectID, err))
 		return
 	}
+	m.recordAuditLog(m.project.ProjectID, models.LogResourceTypeMetadata, m.project.Name+"/"+m.name, "delete")
 }
 
 // validate metas and return a new map which contains the valid key/value pairs only
@@ -227,5 +240,62 @@ func validateProjectMetadata(metas map[string]string) (map[string]string, error)
 		}
 	}
 
+	value, exist = metas[models.ProMetaImmutableTags]
+	if exist {
+		rules, err := models.ParseImmutableTagRules(value)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(rules)
+		if err != nil {
+			return nil, err
+		}
+		metas[models.ProMetaImmutableTags] = string(data)
+	}
+
+	value, exist = metas[models.ProMetaScanner]
+	if exist {
+		// 0 means the project uses the default scanner
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid scanner ID %s", value)
+		}
+		if id > 0 {
+			reg, err := dao.GetScannerRegistration(id)
+			if err != nil {
+				return nil, err
+			}
+			if reg == nil {
+				return nil, fmt.Errorf("scanner %d not found", id)
+			}
+		}
+		metas[models.ProMetaScanner] = strconv.FormatInt(id, 10)
+	}
+
+	value, exist = metas[models.ProMetaProxyCacheTarget]
+	if exist {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid proxy cache target ID %s", value)
+		}
+		target, err := dao.GetRepTarget(id)
+		if err != nil {
+			return nil, err
+		}
+		if target == nil {
+			return nil, fmt.Errorf("target %d not found", id)
+		}
+		metas[models.ProMetaProxyCacheTarget] = strconv.FormatInt(id, 10)
+	}
+
+	value, exist = metas[models.ProMetaProxyCacheTTL]
+	if exist {
+		ttl, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid proxy cache TTL %s", value)
+		}
+		metas[models.ProMetaProxyCacheTTL] = strconv.FormatInt(ttl, 10)
+	}
+
 	return metas, nil
 }
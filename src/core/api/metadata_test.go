@@ -60,6 +60,21 @@ func TestValidateProjectMetadata(t *testing.T) {
 	ms, err = validateProjectMetadata(metas)
 	require.Nil(t, err)
 	assert.Equal(t, "high", ms[models.ProMetaSeverity])
+
+	// valid key, invalid value(immutable tag rules)
+	metas = map[string]string{
+		models.ProMetaImmutableTags: `[{"tag":"v[1"}]`,
+	}
+	ms, err = validateProjectMetadata(metas)
+	require.NotNil(t, err)
+
+	// valid key, valid value(immutable tag rules)
+	metas = map[string]string{
+		models.ProMetaImmutableTags: `[ {"repository":"app", "tag":"v*"} ]`,
+	}
+	ms, err = validateProjectMetadata(metas)
+	require.Nil(t, err)
+	assert.Equal(t, `[{"repository":"app","tag":"v*"}]`, ms[models.ProMetaImmutableTags])
 }
 
 func TestMetaAPI(t *testing.T) {
@@ -44,6 +44,8 @@ type GCReq struct {
 	Status     string                 `json:"status"`
 	ID         int64                  `json:"id"`
 	Parameters map[string]interface{} `json:"parameters"`
+	// Name is the name of the job, set by the server, defaults to the GC job
+	Name string `json:"-"`
 }
 
 // ScheduleParam defines the parameter of schedule trigger
@@ -102,8 +104,12 @@ func (gr *GCReq) ToJob() (*models.JobData, error) {
 		return nil, fmt.Errorf("unsupported schedule trigger type: %s", gr.Schedule.Type)
 	}
 
+	name := gr.Name
+	if len(name) == 0 {
+		name = job.ImageGC
+	}
 	jobData := &models.JobData{
-		Name:       job.ImageGC,
+		Name:       name,
 		Parameters: gr.Parameters,
 		Metadata:   metadata,
 		StatusHook: fmt.Sprintf("%s/service/notifications/jobs/adminjob/%d",
@@ -0,0 +1,165 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego/validation"
+	"github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/job/models"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// RetentionPolicyReq holds the request to set the retention policy of a project
+type RetentionPolicyReq struct {
+	Rules *common_models.RetentionRules `json:"rules"`
+	// Optional, the policy is only run manually if the schedule is not set or its type is 'None'
+	Schedule *ScheduleParam `json:"schedule"`
+}
+
+// Valid validates the retention policy request
+func (r *RetentionPolicyReq) Valid(v *validation.Validation) {
+	if r.Rules == nil {
+		v.SetError("rules", "rules is required")
+		return
+	}
+	if err := r.Rules.Validate(); err != nil {
+		v.SetError("rules", err.Error())
+		return
+	}
+	if r.Schedule == nil {
+		return
+	}
+	switch r.Schedule.Type {
+	case ScheduleDaily, ScheduleWeekly:
+		if r.Schedule.Offtime < 0 || r.Schedule.Offtime > 3600*24 {
+			v.SetError("offtime", fmt.Sprintf("Invalid schedule trigger parameter offtime: %d", r.Schedule.Offtime))
+		}
+	case ScheduleNone:
+	default:
+		v.SetError("kind", fmt.Sprintf("Invalid schedule kind: %s", r.Schedule.Type))
+	}
+}
+
+// IsPeriodic returns whether the policy is run periodically
+func (r *RetentionPolicyReq) IsPeriodic() bool {
+	if r.Schedule == nil {
+		return false
+	}
+	return r.Schedule.Type == ScheduleDaily || r.Schedule.Type == ScheduleWeekly
+}
+
+// RetentionPolicyRep holds the response of querying the retention policy
+type RetentionPolicyRep struct {
+	ID           int64                         `json:"id"`
+	ProjectID    int64                         `json:"project_id"`
+	Rules        *common_models.RetentionRules `json:"rules"`
+	Schedule     *ScheduleParam                `json:"schedule"`
+	CreationTime time.Time                     `json:"creation_time"`
+	UpdateTime   time.Time                     `json:"update_time"`
+}
+
+// RetentionExecutionReq holds the request to run the retention policy manually
+type RetentionExecutionReq struct {
+	// Only report the tags which would be deleted without deleting them
+	DryRun bool `json:"dry_run"`
+}
+
+// RetentionExecutionRep holds the response of querying the execution of retention policy
+type RetentionExecutionRep struct {
+	*common_models.RetentionExecution
+	Report *common_models.RetentionReport `json:"report,omitempty"`
+}
+
+// ConvertToRetentionPolicyRep converts the retention policy in database to the response
+func ConvertToRetentionPolicyRep(policy *common_models.RetentionPolicy) (*RetentionPolicyRep, error) {
+	rep := &RetentionPolicyRep{
+		ID:           policy.ID,
+		ProjectID:    policy.ProjectID,
+		Rules:        &common_models.RetentionRules{},
+		CreationTime: policy.CreationTime,
+		UpdateTime:   policy.UpdateTime,
+	}
+	if err := json.Unmarshal([]byte(policy.Rules), rep.Rules); err != nil {
+		return nil, err
+	}
+	if len(policy.Cron) > 0 {
+		rep.Schedule = &ScheduleParam{}
+		if err := json.Unmarshal([]byte(policy.Cron), rep.Schedule); err != nil {
+			return nil, err
+		}
+	}
+	return rep, nil
+}
+
+// ConvertToRetentionExecutionRep converts the retention execution in database to the response
+func ConvertToRetentionExecutionRep(execution *common_models.RetentionExecution) (*RetentionExecutionRep, error) {
+	rep := &RetentionExecutionRep{
+		RetentionExecution: execution,
+	}
+	if len(execution.Report) > 0 {
+		rep.Report = &common_models.RetentionReport{}
+		if err := json.Unmarshal([]byte(execution.Report), rep.Report); err != nil {
+			return nil, err
+		}
+	}
+	return rep, nil
+}
+
+// RetentionJob builds the retention job submitted to job service. The status hook of
+// the periodic job is bound to the policy, as the executions are created by job service,
+// while the status hook of the manual job is bound to the execution.
+func RetentionJob(policy *common_models.RetentionPolicy, schedule *ScheduleParam, executionID int64, dryRun bool) (*models.JobData, error) {
+	rules := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(policy.Rules), &rules); err != nil {
+		return nil, err
+	}
+
+	metadata := &models.JobMetadata{
+		JobKind: job.JobKindGeneric,
+	}
+	hook := fmt.Sprintf("%s/service/notifications/jobs/retention/%d", config.InternalCoreURL(), executionID)
+	if schedule != nil {
+		switch schedule.Type {
+		case ScheduleDaily:
+			h, m, s := utils.ParseOfftime(schedule.Offtime)
+			metadata.Cron = fmt.Sprintf("%d %d %d * * *", s, m, h)
+		case ScheduleWeekly:
+			h, m, s := utils.ParseOfftime(schedule.Offtime)
+			metadata.Cron = fmt.Sprintf("%d %d %d * * %d", s, m, h, schedule.Weekday%7)
+		default:
+			return nil, fmt.Errorf("unsupported schedule trigger type: %s", schedule.Type)
+		}
+		metadata.JobKind = job.JobKindPeriodic
+		metadata.IsUnique = true
+		hook = fmt.Sprintf("%s/service/notifications/jobs/retention/policy/%d", config.InternalCoreURL(), policy.ID)
+	}
+
+	return &models.JobData{
+		Name: job.ImageRetention,
+		Parameters: map[string]interface{}{
+			"project_id": policy.ProjectID,
+			"rules":      rules,
+			"dry_run":    dryRun,
+		},
+		Metadata:   metadata,
+		StatusHook: hook,
+	}, nil
+}
@@ -0,0 +1,77 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/astaxie/beego/validation"
+	common_models "github.com/goharbor/harbor/src/common/models"
+)
+
+// ScannerRegistrationReq holds the request to register a scanner or update the registration
+type ScannerRegistrationReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	// Optional, the auth header is left unchanged when updating the registration if it is absent
+	AuthHeader     *string `json:"auth_header"`
+	SkipCertVerify bool    `json:"skip_cert_verify"`
+	Disabled       bool    `json:"disabled"`
+}
+
+// Valid validates the registration request, the URL of the built-in scanner can't be registered
+func (r *ScannerRegistrationReq) Valid(v *validation.Validation) {
+	reg := &common_models.ScannerRegistration{}
+	r.ApplyTo(reg)
+	if reg.IsBuiltin() {
+		v.SetError("url", "the url of the built-in scanner is reserved")
+		return
+	}
+	if err := reg.Valid(); err != nil {
+		v.SetError("registration", err.Error())
+	}
+}
+
+// ApplyTo sets the properties of the registration with the ones in request
+func (r *ScannerRegistrationReq) ApplyTo(reg *common_models.ScannerRegistration) {
+	reg.Name = r.Name
+	reg.Description = r.Description
+	reg.URL = r.URL
+	reg.SkipCertVerify = r.SkipCertVerify
+	reg.Disabled = r.Disabled
+	if r.AuthHeader != nil {
+		reg.AuthHeader = *r.AuthHeader
+	}
+}
+
+// ScannerRegistrationRep holds the response of querying the scanner registration, the auth
+// header is never returned
+type ScannerRegistrationRep struct {
+	*common_models.ScannerRegistration
+	AuthHeaderSet bool `json:"auth_header_set"`
+}
+
+// ConvertToScannerRegistrationRep converts the scanner registration in database to the response
+func ConvertToScannerRegistrationRep(reg *common_models.ScannerRegistration) *ScannerRegistrationRep {
+	return &ScannerRegistrationRep{
+		ScannerRegistration: reg,
+		AuthHeaderSet:       len(reg.AuthHeader) > 0,
+	}
+}
+
+// ProjectScannerReq holds the request to set the scanner of a project
+type ProjectScannerReq struct {
+	// The ID of the scanner registration, 0 to use the default scanner
+	ScannerID int64 `json:"scanner_id"`
+}
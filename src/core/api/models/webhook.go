@@ -0,0 +1,78 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/astaxie/beego/validation"
+	common_models "github.com/goharbor/harbor/src/common/models"
+)
+
+// WebhookPolicyReq holds the request to create or update a webhook policy
+type WebhookPolicyReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Address     string `json:"address"`
+	// Optional, the auth header and secret are left unchanged when updating the policy if they are absent
+	AuthHeader     *string  `json:"auth_header"`
+	Secret         *string  `json:"secret"`
+	SkipCertVerify bool     `json:"skip_cert_verify"`
+	EventTypes     []string `json:"event_types"`
+	// Optional, the policy is enabled when created if it is absent
+	Enabled *bool `json:"enabled"`
+}
+
+// Valid validates the webhook policy request
+func (r *WebhookPolicyReq) Valid(v *validation.Validation) {
+	policy := &common_models.WebhookPolicy{}
+	r.ApplyTo(policy)
+	if err := policy.Valid(); err != nil {
+		v.SetError("policy", err.Error())
+	}
+}
+
+// ApplyTo sets the properties of the policy with the ones in request
+func (r *WebhookPolicyReq) ApplyTo(policy *common_models.WebhookPolicy) {
+	policy.Name = r.Name
+	policy.Description = r.Description
+	policy.Address = r.Address
+	policy.SkipCertVerify = r.SkipCertVerify
+	policy.EventTypes = r.EventTypes
+	if r.AuthHeader != nil {
+		policy.AuthHeader = *r.AuthHeader
+	}
+	if r.Secret != nil {
+		policy.Secret = *r.Secret
+	}
+	if r.Enabled != nil {
+		policy.Enabled = *r.Enabled
+	}
+}
+
+// WebhookPolicyRep holds the response of querying the webhook policy, the auth header
+// and secret are never returned
+type WebhookPolicyRep struct {
+	*common_models.WebhookPolicy
+	AuthHeaderSet bool `json:"auth_header_set"`
+	SecretSet     bool `json:"secret_set"`
+}
+
+// ConvertToWebhookPolicyRep converts the webhook policy in database to the response
+func ConvertToWebhookPolicyRep(policy *common_models.WebhookPolicy) *WebhookPolicyRep {
+	return &WebhookPolicyRep{
+		WebhookPolicy: policy,
+		AuthHeaderSet: len(policy.AuthHeader) > 0,
+		SecretSet:     len(policy.Secret) > 0,
+	}
+}
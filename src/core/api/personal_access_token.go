@@ -0,0 +1,164 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+)
+
+// PersonalAccessTokenAPI handles requests to /api/users/{}/tokens, the users manage their own
+// tokens and the system admin can list and revoke the tokens of others, the tokens can't be
+// managed with a personal access token
+type PersonalAccessTokenAPI struct {
+	BaseController
+	user  *models.User
+	token *models.PersonalAccessToken
+}
+
+// Prepare ...
+func (p *PersonalAccessTokenAPI) Prepare() {
+	p.BaseController.Prepare()
+	if !p.SecurityCtx.IsAuthenticated() {
+		p.HandleUnauthorized()
+		return
+	}
+	if p.isPersonalAccessToken() {
+		p.HandleForbidden(p.SecurityCtx.GetUsername())
+		return
+	}
+
+	userID, err := p.GetInt64FromPath(":id")
+	if err != nil || userID <= 0 {
+		p.HandleBadRequest(fmt.Sprintf("invalid user ID: %s", p.GetStringFromPath(":id")))
+		return
+	}
+	user, err := dao.GetUser(models.User{UserID: int(userID)})
+	if err != nil {
+		p.HandleInternalServerError(fmt.Sprintf("failed to get user %d: %v", userID, err))
+		return
+	}
+	if user == nil {
+		p.HandleNotFound(fmt.Sprintf("user %d not found", userID))
+		return
+	}
+	p.user = user
+	if user.Username != p.SecurityCtx.GetUsername() && !p.SecurityCtx.IsSysAdmin() {
+		p.HandleForbidden(p.SecurityCtx.GetUsername())
+		return
+	}
+
+	if len(p.GetStringFromPath(":tid")) > 0 {
+		id, err := p.GetInt64FromPath(":tid")
+		if err != nil || id <= 0 {
+			p.HandleBadRequest(fmt.Sprintf("invalid token ID: %s", p.GetStringFromPath(":tid")))
+			return
+		}
+		token, err := dao.GetPersonalAccessToken(id)
+		if err != nil {
+			p.HandleInternalServerError(fmt.Sprintf("failed to get personal access token %d: %v", id, err))
+			return
+		}
+		if token == nil || token.UserID != user.UserID {
+			p.HandleNotFound(fmt.Sprintf("personal access token %d not found", id))
+			return
+		}
+		p.token = token
+	}
+}
+
+// Post creates a personal access token for the current user, the token is only returned in the response
+func (p *PersonalAccessTokenAPI) Post() {
+	// the system admin can't create tokens for others
+	if p.user.Username != p.SecurityCtx.GetUsername() {
+		p.HandleForbidden(p.SecurityCtx.GetUsername())
+		return
+	}
+	var req models.PersonalAccessTokenReq
+	p.DecodeJSONReqAndValidate(&req)
+
+	for _, pid := range models.ProjectIDsOfAccess(req.Access) {
+		exist, err := p.ProjectMgr.Exists(pid)
+		if err != nil {
+			p.ParseAndHandleError(fmt.Sprintf("failed to check the existence of project %d", pid), err)
+			return
+		}
+		if !exist {
+			p.HandleBadRequest(fmt.Sprintf("project %d not found", pid))
+			return
+		}
+	}
+
+	secret := utils.GenerateRandomString()
+	token := &models.PersonalAccessToken{
+		UserID:    p.user.UserID,
+		Name:      req.Name,
+		Salt:      utils.GenerateRandomString(),
+		ExpiresAt: req.ExpiresAt,
+		Access:    req.Access,
+	}
+	token.Token = utils.Encrypt(secret, token.Salt)
+	id, err := dao.AddPersonalAccessToken(token)
+	if err != nil {
+		if err == dao.ErrDupRows {
+			p.HandleConflict(fmt.Sprintf("personal access token %s already exists", req.Name))
+			return
+		}
+		p.HandleInternalServerError(fmt.Sprintf("failed to create personal access token: %v", err))
+		return
+	}
+
+	p.recordAuditLog(0, models.LogResourceTypeToken, p.user.Username+"/"+token.Name, "create")
+
+	p.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+	p.Data["json"] = &models.PersonalAccessTokenRep{
+		ID:        id,
+		Name:      token.Name,
+		Token:     models.FormatPersonalAccessToken(id, secret),
+		ExpiresAt: token.ExpiresAt,
+	}
+	p.ServeJSON()
+}
+
+// List lists the personal access tokens of the user
+func (p *PersonalAccessTokenAPI) List() {
+	tokens, err := dao.ListPersonalAccessTokens(p.user.UserID)
+	if err != nil {
+		p.HandleInternalServerError(fmt.Sprintf("failed to list the personal access tokens of user %d: %v", p.user.UserID, err))
+		return
+	}
+	p.Data["json"] = tokens
+	p.ServeJSON()
+}
+
+// Get gets the personal access token specified by ID
+func (p *PersonalAccessTokenAPI) Get() {
+	p.Data["json"] = p.token
+	p.ServeJSON()
+}
+
+// Delete revokes the personal access token specified by ID
+func (p *PersonalAccessTokenAPI) Delete() {
+	if err := dao.DeletePersonalAccessToken(p.token.ID); err != nil {
+		p.HandleInternalServerError(fmt.Sprintf("failed to delete personal access token %d: %v", p.token.ID, err))
+		return
+	}
+	p.recordAuditLog(0, models.LogResourceTypeToken, p.user.Username+"/"+p.token.Name, "delete")
+}
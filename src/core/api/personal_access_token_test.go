@@ -0,0 +1,271 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalAccessTokenAPI(t *testing.T) {
+	user := models.User{
+		Username: "user_for_testing_token",
+		Email:    "user_for_testing_token@test.com",
+		Password: "Harbor12345",
+	}
+	id, err := dao.Register(user)
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(id))
+	credential := &usrInfo{Name: user.Username, Passwd: user.Password}
+	url := fmt.Sprintf("/api/users/%d/tokens", id)
+	req := &models.PersonalAccessTokenReq{
+		Name:      "ci",
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Access: []*models.ResourceActions{
+			{Name: "/project/1/image", Actions: []string{"pull"}},
+		},
+	}
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, the tokens of others
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 403, the system admin can't create tokens for others
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   req,
+				credential: admin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/users/10000/tokens",
+				credential: admin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 400, no expiration
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   &models.PersonalAccessTokenReq{Name: "ci"},
+				credential: credential,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, project not found
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+				bodyJSON: &models.PersonalAccessTokenReq{
+					Name:      "ci",
+					ExpiresAt: models.PersonalAccessTokenNeverExpire,
+					Access: []*models.ResourceActions{
+						{Name: "/project/10000/image", Actions: []string{"pull"}},
+					},
+				},
+				credential: credential,
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	rep := &models.PersonalAccessTokenRep{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        url,
+		bodyJSON:   req,
+		credential: credential,
+	}, rep))
+	require.NotEmpty(t, rep.Token)
+	tokenCredential := &usrInfo{Name: user.Username, Passwd: rep.Token}
+	tokenURL := fmt.Sprintf("%s/%d", url, rep.ID)
+
+	cases = []*codeCheckingCase{
+		// 409
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				bodyJSON:   req,
+				credential: credential,
+			},
+			code: http.StatusConflict,
+		},
+		// 200, authenticated by the token scoped to the project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1",
+				credential: tokenCredential,
+			},
+			code: http.StatusOK,
+		},
+		// 403, the scoped token can't access the APIs out of projects
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/users/current",
+				credential: tokenCredential,
+			},
+			code: http.StatusForbidden,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	unscoped := &models.PersonalAccessTokenRep{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method: http.MethodPost,
+		url:    url,
+		bodyJSON: &models.PersonalAccessTokenReq{
+			Name:      "all",
+			ExpiresAt: models.PersonalAccessTokenNeverExpire,
+		},
+		credential: credential,
+	}, unscoped))
+	unscopedCredential := &usrInfo{Name: user.Username, Passwd: unscoped.Token}
+
+	cases = []*codeCheckingCase{
+		// 200, authenticated by the unscoped token
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/users/current",
+				credential: unscopedCredential,
+			},
+			code: http.StatusOK,
+		},
+		// 403, a token can't create another one
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+				bodyJSON: &models.PersonalAccessTokenReq{
+					Name:      "another",
+					ExpiresAt: models.PersonalAccessTokenNeverExpire,
+				},
+				credential: unscopedCredential,
+			},
+			code: http.StatusForbidden,
+		},
+		// 403, a token can't list the tokens
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: unscopedCredential,
+			},
+			code: http.StatusForbidden,
+		},
+		// 403, a token can't revoke the tokens
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        tokenURL,
+				credential: unscopedCredential,
+			},
+			code: http.StatusForbidden,
+		},
+		// 403, a token can't change the profile
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    fmt.Sprintf("/api/users/%d", id),
+				bodyJSON: &models.User{
+					Email:    "another_user_for_testing_token@test.com",
+					Realname: "tester",
+				},
+				credential: unscopedCredential,
+			},
+			code: http.StatusForbidden,
+		},
+		// 403, a token can't change the password
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    fmt.Sprintf("/api/users/%d/password", id),
+				bodyJSON: &passwordReq{
+					OldPassword: user.Password,
+					NewPassword: "Harbor123456",
+				},
+				credential: unscopedCredential,
+			},
+			code: http.StatusForbidden,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	tokens := []*models.PersonalAccessToken{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: admin,
+	}, &tokens))
+	require.Len(t, tokens, 2)
+	for _, token := range tokens {
+		assert.False(t, token.LastUsedTime.IsZero())
+	}
+
+	cases = []*codeCheckingCase{
+		// 200, revoked by the system admin
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        tokenURL,
+				credential: admin,
+			},
+			code: http.StatusOK,
+		},
+		// 401, the revoked token can't be used
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        url,
+				credential: tokenCredential,
+			},
+			code: http.StatusUnauthorized,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
@@ -19,13 +19,13 @@ import (
 	"net/http"
 	"regexp"
 
-	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
-	"github.com/goharbor/harbor/src/common/utils"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	errutil "github.com/goharbor/harbor/src/common/utils/error"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/quota"
 
 	"strconv"
 	"time"
@@ -109,6 +109,13 @@ func (p *ProjectAPI) Post() {
 		return
 	}
 
+	// proxy cache projects pull content with the credential of the target,
+	// so only the system admin can create them
+	if _, ok := pro.Metadata[models.ProMetaProxyCacheTarget]; ok && !p.SecurityCtx.IsSysAdmin() {
+		p.RenderError(http.StatusForbidden, "Only system admin can create proxy cache project")
+		return
+	}
+
 	exist, err := p.ProjectMgr.Exists(pro.Name)
 	if err != nil {
 		p.ParseAndHandleError(fmt.Sprintf("failed to check the existence of project %s",
@@ -151,12 +158,14 @@ func (p *ProjectAPI) Post() {
 	go func() {
 		if err = dao.AddAccessLog(
 			models.AccessLog{
-				Username:  p.SecurityCtx.GetUsername(),
-				ProjectID: projectID,
-				RepoName:  pro.Name + "/",
-				RepoTag:   "N/A",
-				Operation: "create",
-				OpTime:    time.Now(),
+				Username:     p.SecurityCtx.GetUsername(),
+				ProjectID:    projectID,
+				RepoName:     pro.Name + "/",
+				RepoTag:      "N/A",
+				Operation:    "create",
+				OpTime:       time.Now(),
+				ResourceType: models.LogResourceTypeProject,
+				Resource:     pro.Name,
 			}); err != nil {
 			log.Errorf("failed to add access log: %v", err)
 		}
@@ -187,16 +196,8 @@ func (p *ProjectAPI) Head() {
 
 // Get ...
 func (p *ProjectAPI) Get() {
-	if !p.project.IsPublic() {
-		if !p.SecurityCtx.IsAuthenticated() {
-			p.HandleUnauthorized()
-			return
-		}
-
-		if !p.SecurityCtx.HasReadPerm(p.project.ProjectID) {
-			p.HandleForbidden(p.SecurityCtx.GetUsername())
-			return
-		}
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionRead) {
+		return
 	}
 
 	p.populateProperties(p.project)
@@ -207,13 +208,7 @@ func (p *ProjectAPI) Get() {
 
 // Delete ...
 func (p *ProjectAPI) Delete() {
-	if !p.SecurityCtx.IsAuthenticated() {
-		p.HandleUnauthorized()
-		return
-	}
-
-	if !p.SecurityCtx.HasAllPerm(p.project.ProjectID) {
-		p.HandleForbidden(p.SecurityCtx.GetUsername())
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionDelete) {
 		return
 	}
 
@@ -232,14 +227,43 @@ func (p *ProjectAPI) Delete() {
 		return
 	}
 
+	policy, err := dao.GetRetentionPolicyByProject(p.project.ProjectID)
+	if err != nil {
+		log.Errorf("failed to get the retention policy of project %d: %v", p.project.ProjectID, err)
+	} else if policy != nil {
+		if err = removeRetentionPolicy(policy); err != nil {
+			log.Errorf("failed to remove the retention policy of project %d: %v", p.project.ProjectID, err)
+		}
+	}
+
+	if err = dao.DeleteProjectQuota(p.project.ProjectID); err != nil {
+		log.Errorf("failed to delete the quota of project %d: %v", p.project.ProjectID, err)
+	}
+
+	webhookPolicies, err := dao.GetWebhookPolicies(p.project.ProjectID)
+	if err != nil {
+		log.Errorf("failed to get the webhook policies of project %d: %v", p.project.ProjectID, err)
+	}
+	for _, policy := range webhookPolicies {
+		if err = dao.DeleteWebhookPolicy(policy.ID); err != nil {
+			log.Errorf("failed to delete the webhook policy %d of project %d: %v", policy.ID, p.project.ProjectID, err)
+		}
+	}
+
+	if err = dao.DeleteProxyCacheTags(p.project.ProjectID); err != nil {
+		log.Errorf("failed to delete the cached tags of project %d: %v", p.project.ProjectID, err)
+	}
+
 	go func() {
 		if err := dao.AddAccessLog(models.AccessLog{
-			Username:  p.SecurityCtx.GetUsername(),
-			ProjectID: p.project.ProjectID,
-			RepoName:  p.project.Name + "/",
-			RepoTag:   "N/A",
-			Operation: "delete",
-			OpTime:    time.Now(),
+			Username:     p.SecurityCtx.GetUsername(),
+			ProjectID:    p.project.ProjectID,
+			RepoName:     p.project.Name + "/",
+			RepoTag:      "N/A",
+			Operation:    "delete",
+			OpTime:       time.Now(),
+			ResourceType: models.LogResourceTypeProject,
+			Resource:     p.project.Name,
 		}); err != nil {
 			log.Errorf("failed to add access log: %v", err)
 		}
@@ -248,13 +272,7 @@ func (p *ProjectAPI) Delete() {
 
 // Deletable ...
 func (p *ProjectAPI) Deletable() {
-	if !p.SecurityCtx.IsAuthenticated() {
-		p.HandleUnauthorized()
-		return
-	}
-
-	if !p.SecurityCtx.HasAllPerm(p.project.ProjectID) {
-		p.HandleForbidden(p.SecurityCtx.GetUsername())
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionDelete) {
 		return
 	}
 
@@ -403,10 +421,7 @@ func (p *ProjectAPI) populateProperties(project *models.Project) {
 			project.Role = roles[0]
 		}
 
-		if project.Role == common.RoleProjectAdmin ||
-			p.SecurityCtx.IsSysAdmin() {
-			project.Togglable = true
-		}
+		project.Togglable = p.HasProjectPermission(project.ProjectID, rbac_project.ActionUpdate)
 	}
 
 	total, err := dao.GetTotalOfRepositories(&models.RepositoryQuery{
@@ -433,19 +448,18 @@ func (p *ProjectAPI) populateProperties(project *models.Project) {
 
 // Put ...
 func (p *ProjectAPI) Put() {
-	if !p.SecurityCtx.IsAuthenticated() {
-		p.HandleUnauthorized()
-		return
-	}
-
-	if !p.SecurityCtx.HasAllPerm(p.project.ProjectID) {
-		p.HandleForbidden(p.SecurityCtx.GetUsername())
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionUpdate) {
 		return
 	}
 
 	var req *models.ProjectRequest
 	p.DecodeJSONReq(&req)
 
+	if _, ok := req.Metadata[models.ProMetaProxyCacheTarget]; ok {
+		p.HandleBadRequest("the proxy cache target can not be changed")
+		return
+	}
+
 	if err := p.ProjectMgr.Update(p.project.ProjectID,
 		&models.Project{
 			Metadata: req.Metadata,
@@ -458,47 +472,20 @@ func (p *ProjectAPI) Put() {
 
 // Logs ...
 func (p *ProjectAPI) Logs() {
-	if !p.SecurityCtx.IsAuthenticated() {
-		p.HandleUnauthorized()
-		return
-	}
-
-	if !p.SecurityCtx.HasReadPerm(p.project.ProjectID) {
-		p.HandleForbidden(p.SecurityCtx.GetUsername())
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionRead, rbac_project.ResourceLog) {
 		return
 	}
 
 	page, size := p.GetPaginationParams()
-	query := &models.LogQueryParam{
-		ProjectIDs: []int64{p.project.ProjectID},
-		Username:   p.GetString("username"),
-		Repository: p.GetString("repository"),
-		Tag:        p.GetString("tag"),
-		Operations: p.GetStrings("operation"),
-		Pagination: &models.Pagination{
-			Page: page,
-			Size: size,
-		},
+	query, err := p.parseLogQuery()
+	if err != nil {
+		p.HandleBadRequest(err.Error())
+		return
 	}
-
-	timestamp := p.GetString("begin_timestamp")
-	if len(timestamp) > 0 {
-		t, err := utils.ParseTimeStamp(timestamp)
-		if err != nil {
-			p.HandleBadRequest(fmt.Sprintf("invalid begin_timestamp: %s", timestamp))
-			return
-		}
-		query.BeginTime = t
-	}
-
-	timestamp = p.GetString("end_timestamp")
-	if len(timestamp) > 0 {
-		t, err := utils.ParseTimeStamp(timestamp)
-		if err != nil {
-			p.HandleBadRequest(fmt.Sprintf("invalid end_timestamp: %s", timestamp))
-			return
-		}
-		query.EndTime = t
+	query.ProjectIDs = []int64{p.project.ProjectID}
+	query.Pagination = &models.Pagination{
+		Page: page,
+		Size: size,
 	}
 
 	total, err := dao.GetTotalOfAccessLogs(query)
@@ -520,6 +507,105 @@ func (p *ProjectAPI) Logs() {
 	p.ServeJSON()
 }
 
+// ExportLogs streams the access logs of the project in CSV or JSON format
+func (p *ProjectAPI) ExportLogs() {
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionRead, rbac_project.ResourceLog) {
+		return
+	}
+
+	format, ok := p.exportFormat()
+	if !ok {
+		return
+	}
+	query, err := p.parseLogQuery()
+	if err != nil {
+		p.HandleBadRequest(err.Error())
+		return
+	}
+	query.ProjectIDs = []int64{p.project.ProjectID}
+	p.exportLogs(query, format)
+}
+
+// Summary returns the quota usage against the hard limits of the project
+func (p *ProjectAPI) Summary() {
+	if !p.RequireProjectAccess(p.project.ProjectID, rbac_project.ActionRead) {
+		return
+	}
+
+	hard, err := quota.GetLimits(p.project.ProjectID)
+	if err != nil {
+		p.HandleInternalServerError(fmt.Sprintf(
+			"failed to get the quota of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+
+	used, err := dao.GetProjectUsage(p.project.ProjectID)
+	if err != nil {
+		p.HandleInternalServerError(fmt.Sprintf(
+			"failed to get the quota usage of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+
+	p.Data["json"] = &models.ProjectSummary{
+		Quota: &models.QuotaSummary{
+			Hard: hard,
+			Used: used,
+		},
+	}
+	p.ServeJSON()
+}
+
+// PutQuota sets the hard limits of the project, only system admin is allowed
+func (p *ProjectAPI) PutQuota() {
+	if !p.SecurityCtx.IsAuthenticated() {
+		p.HandleUnauthorized()
+		return
+	}
+
+	if !p.SecurityCtx.IsSysAdmin() {
+		p.HandleForbidden(p.SecurityCtx.GetUsername())
+		return
+	}
+
+	req := &models.QuotaResources{}
+	p.DecodeJSONReq(req)
+	if err := req.Valid(); err != nil {
+		p.HandleBadRequest(err.Error())
+		return
+	}
+
+	if err := dao.SetProjectQuota(&models.ProjectQuota{
+		ProjectID:    p.project.ProjectID,
+		StorageLimit: req.Storage,
+		CountLimit:   req.Count,
+	}); err != nil {
+		p.HandleInternalServerError(fmt.Sprintf(
+			"failed to set the quota of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+	p.recordAuditLog(p.project.ProjectID, models.LogResourceTypeQuota, p.project.Name, "update")
+}
+
+// DeleteQuota resets the hard limits of the project to the system defaults, only system admin is allowed
+func (p *ProjectAPI) DeleteQuota() {
+	if !p.SecurityCtx.IsAuthenticated() {
+		p.HandleUnauthorized()
+		return
+	}
+
+	if !p.SecurityCtx.IsSysAdmin() {
+		p.HandleForbidden(p.SecurityCtx.GetUsername())
+		return
+	}
+
+	if err := dao.DeleteProjectQuota(p.project.ProjectID); err != nil {
+		p.HandleInternalServerError(fmt.Sprintf(
+			"failed to delete the quota of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+	p.recordAuditLog(p.project.ProjectID, models.LogResourceTypeQuota, p.project.Name, "delete")
+}
+
 // TODO move this to package models
 func validateProjectReq(req *models.ProjectRequest) error {
 	pn := req.Name
@@ -423,3 +423,86 @@ func TestDeletable(t *testing.T) {
 	assert.Equal(t, http.StatusOK, code)
 	assert.False(t, del)
 }
+
+func TestProjectQuota(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/projects/1/summary",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/10000/summary",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403, only system admin can set the quota
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/quota",
+				bodyJSON:   &models.QuotaResources{Storage: 1024, Count: 10},
+				credential: projAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/quota",
+				bodyJSON:   &models.QuotaResources{Storage: -2, Count: 10},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/quota",
+				bodyJSON:   &models.QuotaResources{Storage: 1024, Count: 10},
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	summary := &models.ProjectSummary{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/summary",
+		credential: projGuest,
+	}, summary)
+	require.Nil(t, err)
+	require.NotNil(t, summary.Quota)
+	assert.Equal(t, int64(1024), summary.Quota.Hard.Storage)
+	assert.Equal(t, int64(10), summary.Quota.Hard.Count)
+
+	// reset to the system defaults
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodDelete,
+			url:        "/api/projects/1/quota",
+			credential: sysAdmin,
+		},
+		code: http.StatusOK,
+	})
+	summary = &models.ProjectSummary{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/summary",
+		credential: projGuest,
+	}, summary)
+	require.Nil(t, err)
+	assert.Equal(t, int64(-1), summary.Quota.Hard.Storage)
+	assert.Equal(t, int64(-1), summary.Quota.Hard.Count)
+}
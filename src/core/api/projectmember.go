@@ -22,8 +22,11 @@ import (
 	"strings"
 
 	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/dao/project"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/auth"
 )
@@ -41,7 +44,7 @@ type ProjectMemberAPI struct {
 var ErrDuplicateProjectMember = errors.New("The project member specified already exist")
 
 // ErrInvalidRole ...
-var ErrInvalidRole = errors.New("Failed to update project member, role is not in 1,2,3")
+var ErrInvalidRole = errors.New("Failed to update project member, role not found")
 
 // Prepare validates the URL and parms
 func (pma *ProjectMemberAPI) Prepare() {
@@ -73,9 +76,7 @@ func (pma *ProjectMemberAPI) Prepare() {
 	}
 	pma.project = project
 
-	if !(pma.Ctx.Input.IsGet() && pma.SecurityCtx.HasReadPerm(pid) ||
-		pma.SecurityCtx.HasAllPerm(pid)) {
-		pma.HandleForbidden(pma.SecurityCtx.GetUsername())
+	if !pma.RequireProjectAccess(pid, rbac_project.ActionOfMethod(pma.Ctx.Request.Method), rbac_project.ResourceMember) {
 		return
 	}
 
@@ -130,6 +131,9 @@ func (pma *ProjectMemberAPI) Post() {
 	var request models.MemberReq
 	pma.DecodeJSONReq(&request)
 	request.MemberGroup.LdapGroupDN = strings.TrimSpace(request.MemberGroup.LdapGroupDN)
+	if !pma.requireGrantable(request.Role) {
+		return
+	}
 
 	pmid, err := AddProjectMember(projectID, request)
 	if err == auth.ErrorGroupNotExist || err == auth.ErrorUserNotExist {
@@ -151,6 +155,7 @@ func (pma *ProjectMemberAPI) Post() {
 		pma.HandleInternalServerError(fmt.Sprintf("Failed to add project member, error: %v", err))
 		return
 	}
+	pma.recordAuditLog(projectID, models.LogResourceTypeMember, pma.memberLogResource(pmid), "create")
 	pma.Redirect(http.StatusCreated, strconv.FormatInt(int64(pmid), 10))
 }
 
@@ -160,25 +165,86 @@ func (pma *ProjectMemberAPI) Put() {
 	pmID := pma.id
 	var req models.Member
 	pma.DecodeJSONReq(&req)
-	if req.Role < 1 || req.Role > 3 {
+	valid, err := isValidRole(req.Role)
+	if err != nil {
+		pma.HandleInternalServerError(fmt.Sprintf("failed to get role %d: %v", req.Role, err))
+		return
+	}
+	if !valid {
 		pma.HandleBadRequest(fmt.Sprintf("Invalid role id %v", req.Role))
 		return
 	}
-	err := project.UpdateProjectMemberRole(pmID, req.Role)
+	if !pma.requireGrantable(req.Role) || !pma.requireMemberGrantable(pmID) {
+		return
+	}
+	err = project.UpdateProjectMemberRole(pmID, req.Role)
 	if err != nil {
 		pma.HandleInternalServerError(fmt.Sprintf("Failed to update DB to add project user role, project id: %d, pmid : %d, role id: %d", pid, pmID, req.Role))
 		return
 	}
+	pma.recordAuditLog(pid, models.LogResourceTypeMember, pma.memberLogResource(pmID), "update")
 }
 
 // Delete ...
 func (pma *ProjectMemberAPI) Delete() {
 	pmid := pma.id
+	if !pma.requireMemberGrantable(pmid) {
+		return
+	}
+	resource := pma.memberLogResource(pmid)
 	err := project.DeleteProjectMemberByID(pmid)
 	if err != nil {
 		pma.HandleInternalServerError(fmt.Sprintf("Failed to delete project roles for user, project member id: %d, error: %v", pmid, err))
 		return
 	}
+	pma.recordAuditLog(pma.project.ProjectID, models.LogResourceTypeMember, resource, "delete")
+}
+
+// requireGrantable returns true if the caller has all the policies of the role on the project, otherwise
+// the caller would escalate its privileges by granting the role, it renders 403 and returns false then.
+// The role not found is left to the validation of the member.
+func (pma *ProjectMemberAPI) requireGrantable(roleID int) bool {
+	policies, err := rolePolicies(roleID)
+	if err != nil {
+		pma.HandleInternalServerError(fmt.Sprintf("failed to get the policies of role %d: %v", roleID, err))
+		return false
+	}
+	for _, policy := range policies {
+		if !pma.HasProjectPermission(pma.project.ProjectID, policy.Action, policy.Resource) {
+			pma.HandleForbidden(pma.SecurityCtx.GetUsername())
+			return false
+		}
+	}
+	return true
+}
+
+// requireMemberGrantable returns true if the caller can grant the current role of the member,
+// which is required to change or remove the member
+func (pma *ProjectMemberAPI) requireMemberGrantable(pmid int) bool {
+	members, err := project.GetProjectMember(models.Member{
+		ProjectID: pma.project.ProjectID,
+		ID:        pmid,
+	})
+	if err != nil {
+		pma.HandleInternalServerError(fmt.Sprintf("failed to get project member %d: %v", pmid, err))
+		return false
+	}
+	if len(members) == 0 {
+		return true
+	}
+	return pma.requireGrantable(members[0].Role)
+}
+
+// memberLogResource returns the resource of the member recorded in the access log
+func (pma *ProjectMemberAPI) memberLogResource(pmid int) string {
+	members, err := project.GetProjectMember(models.Member{
+		ProjectID: pma.project.ProjectID,
+		ID:        pmid,
+	})
+	if err != nil || len(members) == 0 {
+		return fmt.Sprintf("%s/members/%d", pma.project.Name, pmid)
+	}
+	return fmt.Sprintf("%s/members/%s", pma.project.Name, members[0].Entityname)
 }
 
 // AddProjectMember ...
@@ -226,9 +292,44 @@ func AddProjectMember(projectID int64, request models.MemberReq) (int, error) {
 		return 0, ErrDuplicateProjectMember
 	}
 
-	if member.Role < 1 || member.Role > 3 {
+	valid, err := isValidRole(member.Role)
+	if err != nil {
+		return 0, err
+	}
+	if !valid {
 		// Return invalid role error
 		return 0, ErrInvalidRole
 	}
 	return project.AddProjectMember(member)
 }
+
+// rolePolicies returns the policies on the subresources of the project of the built-in or custom role,
+// nil is returned if the role is not found
+func rolePolicies(roleID int) ([]*rbac.Policy, error) {
+	if rbac_project.IsBuiltInRole(roleID) {
+		return rbac_project.BuiltInRolePolicies(roleID), nil
+	}
+	if roleID <= 0 {
+		return nil, nil
+	}
+	role, err := dao.GetRoleByID(roleID)
+	if err != nil || role == nil {
+		return nil, err
+	}
+	return role.RBACPolicies(), nil
+}
+
+// isValidRole returns whether the role is a built-in or an existing custom role
+func isValidRole(roleID int) (bool, error) {
+	if rbac_project.IsBuiltInRole(roleID) {
+		return true, nil
+	}
+	if roleID <= 0 {
+		return false, nil
+	}
+	role, err := dao.GetRoleByID(roleID)
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
@@ -33,11 +33,23 @@ import (
 // GCAPI handles request of harbor admin...
 type GCAPI struct {
 	BaseController
+	// the name of the admin job handled by the API
+	jobName string
+	// parameters returns the parameters of the job submitted to jobservice
+	parameters func(gr *models.GCReq) (map[string]interface{}, error)
 }
 
 // Prepare validates the URL and parms, it needs the system admin permission.
 func (gc *GCAPI) Prepare() {
 	gc.BaseController.Prepare()
+	if len(gc.jobName) == 0 {
+		gc.jobName = common_job.ImageGC
+		gc.parameters = func(gr *models.GCReq) (map[string]interface{}, error) {
+			return map[string]interface{}{
+				"redis_url_reg": os.Getenv("_REDIS_URL_REG"),
+			}, nil
+		}
+	}
 	if !gc.SecurityCtx.IsAuthenticated() {
 		gc.HandleUnauthorized()
 		return
@@ -65,9 +77,15 @@ func (gc *GCAPI) Put() {
 		gc.HandleInternalServerError(fmt.Sprintf("Fail to update GC schedule as wrong schedule type: %s.", gr.Schedule.Type))
 		return
 	}
+	if gr.Schedule.Type != models.ScheduleNone {
+		if _, err := gc.parameters(&gr); err != nil {
+			gc.HandleBadRequest(err.Error())
+			return
+		}
+	}
 
 	query := &common_models.AdminJobQuery{
-		Name: common_job.ImageGC,
+		Name: gc.jobName,
 		Kind: common_job.JobKindPeriodic,
 	}
 	jobs, err := dao.GetAdminJobs(query)
@@ -131,7 +149,7 @@ func (gc *GCAPI) GetGC() {
 
 // List ...
 func (gc *GCAPI) List() {
-	jobs, err := dao.GetTop10AdminJobsOfName(common_job.ImageGC)
+	jobs, err := dao.GetTop10AdminJobsOfName(gc.jobName)
 	if err != nil {
 		gc.HandleInternalServerError(fmt.Sprintf("failed to get admin jobs: %v", err))
 		return
@@ -152,7 +170,7 @@ func (gc *GCAPI) List() {
 // Get gets GC schedule ...
 func (gc *GCAPI) Get() {
 	jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
-		Name: common_job.ImageGC,
+		Name: gc.jobName,
 		Kind: common_job.JobKindPeriodic,
 	})
 	if err != nil {
@@ -217,7 +235,7 @@ func (gc *GCAPI) submitJob(gr *models.GCReq) {
 	// cannot post multiple schdule for GC job.
 	if gr.IsPeriodic() {
 		jobs, err := dao.GetAdminJobs(&common_models.AdminJobQuery{
-			Name: common_job.ImageGC,
+			Name: gc.jobName,
 			Kind: common_job.JobKindPeriodic,
 		})
 		if err != nil {
@@ -230,8 +248,14 @@ func (gc *GCAPI) submitJob(gr *models.GCReq) {
 		}
 	}
 
+	params, err := gc.parameters(gr)
+	if err != nil {
+		gc.HandleBadRequest(err.Error())
+		return
+	}
+
 	id, err := dao.AddAdminJob(&common_models.AdminJob{
-		Name: common_job.ImageGC,
+		Name: gc.jobName,
 		Kind: gr.JobKind(),
 		Cron: gr.CronString(),
 	})
@@ -240,9 +264,8 @@ func (gc *GCAPI) submitJob(gr *models.GCReq) {
 		return
 	}
 	gr.ID = id
-	gr.Parameters = map[string]interface{}{
-		"redis_url_reg": os.Getenv("_REDIS_URL_REG"),
-	}
+	gr.Name = gc.jobName
+	gr.Parameters = params
 	job, err := gr.ToJob()
 	if err != nil {
 		gc.HandleInternalServerError(fmt.Sprintf("%v", err))
@@ -250,7 +273,7 @@ func (gc *GCAPI) submitJob(gr *models.GCReq) {
 	}
 
 	// submit job to jobservice
-	log.Debugf("submiting GC admin job to jobservice")
+	log.Debugf("submiting %s admin job to jobservice", gc.jobName)
 	uuid, err := utils_core.GetJobServiceClient().SubmitJob(job)
 	if err != nil {
 		if err := dao.DeleteAdminJob(id); err != nil {
@@ -24,6 +24,7 @@ import (
 	common_http "github.com/goharbor/harbor/src/common/http"
 	common_job "github.com/goharbor/harbor/src/common/job"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	api_models "github.com/goharbor/harbor/src/core/api/models"
 	"github.com/goharbor/harbor/src/core/utils"
@@ -80,8 +81,7 @@ func (ra *RepJobAPI) List() {
 		return
 	}
 
-	if !ra.SecurityCtx.HasAllPerm(policy.ProjectIDs[0]) {
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(policy.ProjectIDs[0], rbac_project.ActionRead, rbac_project.ResourceReplicationJob) {
 		return
 	}
 
@@ -190,8 +190,7 @@ func (ra *RepJobAPI) GetLog() {
 		return
 	}
 
-	if !ra.SecurityCtx.HasAllPerm(policy.ProjectIDs[0]) {
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(policy.ProjectIDs[0], rbac_project.ActionRead, rbac_project.ResourceReplicationJob) {
 		return
 	}
 
@@ -22,6 +22,7 @@ import (
 
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	api_models "github.com/goharbor/harbor/src/core/api/models"
 	"github.com/goharbor/harbor/src/core/promgr"
@@ -63,8 +64,7 @@ func (pa *RepPolicyAPI) Get() {
 		return
 	}
 
-	if !pa.SecurityCtx.HasAllPerm(policy.ProjectIDs[0]) {
-		pa.HandleForbidden(pa.SecurityCtx.GetUsername())
+	if !pa.RequireProjectAccess(policy.ProjectIDs[0], rbac_project.ActionRead, rbac_project.ResourceReplication) {
 		return
 	}
 
@@ -105,7 +105,7 @@ func (pa *RepPolicyAPI) List() {
 	if result != nil {
 		total = result.Total
 		for _, policy := range result.Policies {
-			if !pa.SecurityCtx.HasAllPerm(policy.ProjectIDs[0]) {
+			if !pa.HasProjectPermission(policy.ProjectIDs[0], rbac_project.ActionRead, rbac_project.ResourceReplication) {
 				continue
 			}
 			ply, err := convertFromRepPolicy(pa.ProjectMgr, *policy)
@@ -200,9 +200,19 @@ func (pa *RepPolicyAPI) Post() {
 		}()
 	}
 
+	pa.recordAuditLog(policyLogProjectID(policy), models.LogResourceTypeReplicationPolicy, policy.Name, "create")
 	pa.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
 }
 
+// policyLogProjectID returns the project under which the operation to the replication
+// policy is recorded, the policy is only attached to one project currently
+func policyLogProjectID(policy *api_models.ReplicationPolicy) int64 {
+	if len(policy.Projects) == 0 {
+		return 0
+	}
+	return policy.Projects[0].ProjectID
+}
+
 func exist(name string) (bool, error) {
 	result, err := core.GlobalController.GetPolicies(rep_models.QueryParameter{
 		Name: name,
@@ -311,6 +321,7 @@ func (pa *RepPolicyAPI) Put() {
 			log.Infof("replication signal for policy %d sent", id)
 		}()
 	}
+	pa.recordAuditLog(policyLogProjectID(policy), models.LogResourceTypeReplicationPolicy, policy.Name, "update")
 }
 
 // Delete the replication policy
@@ -346,6 +357,11 @@ func (pa *RepPolicyAPI) Delete() {
 		log.Errorf("failed to delete policy %d: %v", id, err)
 		pa.CustomAbort(http.StatusInternalServerError, "")
 	}
+	var projectID int64
+	if len(policy.ProjectIDs) > 0 {
+		projectID = policy.ProjectIDs[0]
+	}
+	pa.recordAuditLog(projectID, models.LogResourceTypeReplicationPolicy, policy.Name, "delete")
 }
 
 func convertFromRepPolicy(projectMgr promgr.ProjectManager, policy rep_models.ReplicationPolicy) (*api_models.ReplicationPolicy, error) {
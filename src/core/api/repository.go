@@ -30,13 +30,14 @@ import (
 	"github.com/goharbor/harbor/src/common/dao"
 	commonhttp "github.com/goharbor/harbor/src/common/http"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils"
-	"github.com/goharbor/harbor/src/common/utils/clair"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/common/utils/notary"
 	"github.com/goharbor/harbor/src/common/utils/registry"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/notifier"
+	"github.com/goharbor/harbor/src/core/quota"
 	coreutils "github.com/goharbor/harbor/src/core/utils"
 	"github.com/goharbor/harbor/src/replication/event/notification"
 	"github.com/goharbor/harbor/src/replication/event/topic"
@@ -131,12 +132,7 @@ func (ra *RepositoryAPI) Get() {
 		return
 	}
 
-	if !ra.SecurityCtx.HasReadPerm(projectID) {
-		if !ra.SecurityCtx.IsAuthenticated() {
-			ra.HandleUnauthorized()
-			return
-		}
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(projectID, rbac_project.ActionRead, rbac_project.ResourceRepository) {
 		return
 	}
 
@@ -224,6 +220,59 @@ func assembleRepo(c chan *repoResp, index int, repository *models.RepoRecord) {
 	c <- repo
 }
 
+// tagDigestResolver lists the tags of a repository and resolves the digests they point to
+type tagDigestResolver interface {
+	ListTag() ([]string, error)
+	ManifestExist(reference string) (digest string, exist bool, err error)
+}
+
+// immutableTagToDelete returns the immutable tag which would be deleted along with the tags,
+// returns empty string if there is none. The tags are deleted by the digest of their manifest,
+// so the immutable tags sharing the digest with the deleted ones are checked as well.
+func immutableTagToDelete(project *models.Project, repoName string, tags []string, rc tagDigestResolver) (string, error) {
+	if len(project.ImmutableTagRules()) == 0 {
+		return "", nil
+	}
+
+	deleted := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		if project.IsTagImmutable(repoName, t) {
+			return t, nil
+		}
+		deleted[t] = true
+	}
+
+	digests := map[string]bool{}
+	for _, t := range tags {
+		digest, exist, err := rc.ManifestExist(t)
+		if err != nil {
+			return "", err
+		}
+		if exist {
+			digests[digest] = true
+		}
+	}
+
+	all, err := rc.ListTag()
+	if err != nil {
+		return "", err
+	}
+	for _, t := range all {
+		if deleted[t] || !project.IsTagImmutable(repoName, t) {
+			continue
+		}
+		digest, exist, err := rc.ManifestExist(t)
+		if err != nil {
+			return "", err
+		}
+		if exist && digests[digest] {
+			return t, nil
+		}
+	}
+
+	return "", nil
+}
+
 // Delete ...
 func (ra *RepositoryAPI) Delete() {
 	// using :splat to get * part in path
@@ -242,13 +291,11 @@ func (ra *RepositoryAPI) Delete() {
 		return
 	}
 
-	if !ra.SecurityCtx.IsAuthenticated() {
-		ra.HandleUnauthorized()
-		return
+	resource := rbac_project.ResourceRepository
+	if len(ra.GetString(":tag")) > 0 {
+		resource = rbac_project.ResourceRepositoryTag
 	}
-
-	if !ra.SecurityCtx.HasAllPerm(projectName) {
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(projectName, rbac_project.ActionDelete, resource) {
 		return
 	}
 
@@ -283,6 +330,16 @@ func (ra *RepositoryAPI) Delete() {
 		tags = append(tags, tag)
 	}
 
+	immutable, err := immutableTagToDelete(project, repoName, tags, rc)
+	if err != nil {
+		log.Errorf("failed to check the immutable tags of %s: %v", repoName, err)
+		ra.CustomAbort(http.StatusInternalServerError, "internal error")
+	}
+	if len(immutable) > 0 {
+		log.Errorf("Found immutable tag, repository: %s, tag: %s, deletion will be canceled", repoName, immutable)
+		ra.CustomAbort(http.StatusPreconditionFailed, fmt.Sprintf("tag %s is immutable", immutable))
+	}
+
 	if config.WithNotary() {
 		signedTags, err := getSignatures(ra.SecurityCtx.GetUsername(), repoName)
 		if err != nil {
@@ -393,12 +450,7 @@ func (ra *RepositoryAPI) GetTag() {
 		return
 	}
 	project, _ := utils.ParseRepository(repository)
-	if !ra.SecurityCtx.HasReadPerm(project) {
-		if !ra.SecurityCtx.IsAuthenticated() {
-			ra.HandleUnauthorized()
-			return
-		}
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(project, rbac_project.ActionRead, rbac_project.ResourceRepositoryTag) {
 		return
 	}
 
@@ -453,7 +505,8 @@ func (ra *RepositoryAPI) Retag() {
 	}
 
 	// Check whether source image exists
-	exist, _, err := ra.checkExistence(fmt.Sprintf("%s/%s", srcImage.Project, srcImage.Repo), srcImage.Tag)
+	srcRepoName := fmt.Sprintf("%s/%s", srcImage.Project, srcImage.Repo)
+	exist, digest, err := ra.checkExistence(srcRepoName, srcImage.Tag)
 	if err != nil {
 		ra.HandleInternalServerError(fmt.Sprintf("check existence of %s error: %v", request.SrcImage, err))
 		return
@@ -464,23 +517,28 @@ func (ra *RepositoryAPI) Retag() {
 	}
 
 	// Check whether target project exists
-	exist, err = ra.ProjectMgr.Exists(project)
+	targetProject, err := ra.ProjectMgr.Get(project)
 	if err != nil {
-		ra.ParseAndHandleError(fmt.Sprintf("failed to check the existence of project %s", project), err)
+		ra.ParseAndHandleError(fmt.Sprintf("failed to get the project %s", project), err)
 		return
 	}
-	if !exist {
+	if targetProject == nil {
 		ra.HandleNotFound(fmt.Sprintf("project %s not found", project))
 		return
 	}
 
-	// If override not allowed, check whether target tag already exists
-	if !request.Override {
+	// If override not allowed or the target tag is immutable, check whether target tag already exists
+	immutable := targetProject.IsTagImmutable(repoName, request.Tag)
+	if !request.Override || immutable {
 		exist, _, err := ra.checkExistence(repoName, request.Tag)
 		if err != nil {
 			ra.HandleInternalServerError(fmt.Sprintf("check existence of %s:%s error: %v", repoName, request.Tag, err))
 			return
 		}
+		if exist && immutable {
+			ra.HandleStatusPreconditionFailed(fmt.Sprintf("tag '%s' of '%s' is immutable", request.Tag, repoName))
+			return
+		}
 		if exist {
 			ra.HandleConflict(fmt.Sprintf("tag '%s' already existed for '%s'", request.Tag, repoName))
 			return
@@ -488,19 +546,38 @@ func (ra *RepositoryAPI) Retag() {
 	}
 
 	// Check whether user has read permission to source project
-	if !ra.SecurityCtx.HasReadPerm(srcImage.Project) {
+	if !ra.HasProjectPermission(srcImage.Project, rbac_project.ActionPull, rbac_project.ResourceImage) {
 		log.Errorf("user has no read permission to project '%s'", srcImage.Project)
 		ra.HandleForbidden(fmt.Sprintf("%s has no read permission to project %s", ra.SecurityCtx.GetUsername(), srcImage.Project))
 		return
 	}
 
 	// Check whether user has write permission to target project
-	if !ra.SecurityCtx.HasWritePerm(project) {
+	if !ra.HasProjectPermission(project, rbac_project.ActionCreate, rbac_project.ResourceRepositoryTag) {
 		log.Errorf("user has no write permission to project '%s'", project)
 		ra.HandleForbidden(fmt.Sprintf("%s has no write permission to project %s", ra.SecurityCtx.GetUsername(), project))
 		return
 	}
 
+	// Check whether the quota of target project is enough, as the image is pushed to registry directly
+	size, err := quota.ImageSizeOf(srcRepoName, digest)
+	if err != nil {
+		ra.HandleInternalServerError(fmt.Sprintf("failed to get the size of %s: %v", request.SrcImage, err))
+		return
+	}
+	required, err := quota.Required(targetProject.ProjectID, repoName, request.Tag, digest, size)
+	if err == nil {
+		err = quota.Check(targetProject.ProjectID, required)
+	}
+	if err != nil {
+		if quota.IsExceededError(err) {
+			ra.HandleForbidden(err.Error())
+			return
+		}
+		ra.HandleInternalServerError(fmt.Sprintf("failed to check the quota of project %s: %v", project, err))
+		return
+	}
+
 	// Retag the image
 	if err = coreutils.Retag(srcImage, &models.Image{
 		Project: project,
@@ -533,12 +610,7 @@ func (ra *RepositoryAPI) GetTags() {
 		return
 	}
 
-	if !ra.SecurityCtx.HasReadPerm(projectName) {
-		if !ra.SecurityCtx.IsAuthenticated() {
-			ra.HandleUnauthorized()
-			return
-		}
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(projectName, rbac_project.ActionRead, rbac_project.ResourceRepositoryTag) {
 		return
 	}
 
@@ -596,9 +668,10 @@ func assembleTagsInParallel(client *registry.Repository, repository string,
 		}
 	}
 
+	scanEnabled := coreutils.ScanEnabled()
 	c := make(chan *tagResp)
 	for _, tag := range tags {
-		go assembleTag(c, client, repository, tag, config.WithClair(),
+		go assembleTag(c, client, repository, tag, scanEnabled,
 			config.WithNotary(), signatures)
 	}
 	result := []*tagResp{}
@@ -614,7 +687,7 @@ func assembleTagsInParallel(client *registry.Repository, repository string,
 }
 
 func assembleTag(c chan *tagResp, client *registry.Repository,
-	repository, tag string, clairEnabled, notaryEnabled bool,
+	repository, tag string, scanEnabled, notaryEnabled bool,
 	signatures map[string][]notary.Target) {
 	item := &tagResp{}
 	// labels
@@ -636,7 +709,7 @@ func assembleTag(c chan *tagResp, client *registry.Repository,
 	}
 
 	// scan overview
-	if clairEnabled {
+	if scanEnabled {
 		item.ScanOverview = getScanOverview(item.Digest, item.Name)
 	}
 
@@ -741,13 +814,7 @@ func (ra *RepositoryAPI) GetManifests() {
 		return
 	}
 
-	if !ra.SecurityCtx.HasReadPerm(projectName) {
-		if !ra.SecurityCtx.IsAuthenticated() {
-			ra.HandleUnauthorized()
-			return
-		}
-
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(projectName, rbac_project.ActionRead, rbac_project.ResourceRepositoryTag) {
 		return
 	}
 
@@ -866,14 +933,8 @@ func (ra *RepositoryAPI) Put() {
 		return
 	}
 
-	if !ra.SecurityCtx.IsAuthenticated() {
-		ra.HandleUnauthorized()
-		return
-	}
-
 	project, _ := utils.ParseRepository(name)
-	if !ra.SecurityCtx.HasWritePerm(project) {
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(project, rbac_project.ActionUpdate, rbac_project.ResourceRepository) {
 		return
 	}
 
@@ -906,12 +967,7 @@ func (ra *RepositoryAPI) GetSignatures() {
 		return
 	}
 
-	if !ra.SecurityCtx.HasReadPerm(projectName) {
-		if !ra.SecurityCtx.IsAuthenticated() {
-			ra.HandleUnauthorized()
-			return
-		}
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(projectName, rbac_project.ActionRead, rbac_project.ResourceRepositoryTag) {
 		return
 	}
 
@@ -927,8 +983,8 @@ func (ra *RepositoryAPI) GetSignatures() {
 
 // ScanImage handles request POST /api/repository/$repository/tags/$tag/scan to trigger image scan manually.
 func (ra *RepositoryAPI) ScanImage() {
-	if !config.WithClair() {
-		log.Warningf("Harbor is not deployed with Clair, scan is disabled.")
+	if !coreutils.ScanEnabled() {
+		log.Warningf("Harbor is not deployed with Clair and no scanner is registered, scan is disabled.")
 		ra.RenderError(http.StatusServiceUnavailable, "")
 		return
 	}
@@ -945,12 +1001,7 @@ func (ra *RepositoryAPI) ScanImage() {
 		ra.HandleNotFound(fmt.Sprintf("project %s not found", projectName))
 		return
 	}
-	if !ra.SecurityCtx.IsAuthenticated() {
-		ra.HandleUnauthorized()
-		return
-	}
-	if !ra.SecurityCtx.HasAllPerm(projectName) {
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(projectName, rbac_project.ActionCreate, rbac_project.ResourceScan) {
 		return
 	}
 	err = coreutils.TriggerImageScan(repoName, tag)
@@ -961,10 +1012,10 @@ func (ra *RepositoryAPI) ScanImage() {
 	}
 }
 
-// VulnerabilityDetails fetch vulnerability info from clair, transform to Harbor's format and return to client.
+// VulnerabilityDetails fetch vulnerability info from the scanner, transform to Harbor's format and return to client.
 func (ra *RepositoryAPI) VulnerabilityDetails() {
-	if !config.WithClair() {
-		log.Warningf("Harbor is not deployed with Clair, it's not impossible to get vulnerability details.")
+	if !coreutils.ScanEnabled() {
+		log.Warningf("Harbor is not deployed with Clair and no scanner is registered, it's not possible to get vulnerability details.")
 		ra.RenderError(http.StatusServiceUnavailable, "")
 		return
 	}
@@ -980,12 +1031,7 @@ func (ra *RepositoryAPI) VulnerabilityDetails() {
 		return
 	}
 	project, _ := utils.ParseRepository(repository)
-	if !ra.SecurityCtx.HasReadPerm(project) {
-		if !ra.SecurityCtx.IsAuthenticated() {
-			ra.HandleUnauthorized()
-			return
-		}
-		ra.HandleForbidden(ra.SecurityCtx.GetUsername())
+	if !ra.RequireProjectAccess(project, rbac_project.ActionRead, rbac_project.ResourceScan) {
 		return
 	}
 	res := []*models.VulnerabilityItem{}
@@ -994,15 +1040,13 @@ func (ra *RepositoryAPI) VulnerabilityDetails() {
 		ra.HandleInternalServerError(fmt.Sprintf("failed to get the scan overview, error: %v", err))
 		return
 	}
-	if overview != nil && len(overview.DetailsKey) > 0 {
-		clairClient := clair.NewClient(config.ClairEndpoint(), nil)
-		log.Debugf("The key for getting details: %s", overview.DetailsKey)
-		details, err := clairClient.GetResult(overview.DetailsKey)
-		if err != nil {
-			ra.HandleInternalServerError(fmt.Sprintf("Failed to get scan details from Clair, error: %v", err))
-			return
-		}
-		res = transformVulnerabilities(details)
+	report, err := coreutils.GetScanReport(overview)
+	if err != nil {
+		ra.HandleInternalServerError(fmt.Sprintf("Failed to get scan details from the scanner, error: %v", err))
+		return
+	}
+	if report != nil {
+		res = report.Items()
 	}
 	ra.Data["json"] = res
 	ra.ServeJSON()
@@ -1010,8 +1054,8 @@ func (ra *RepositoryAPI) VulnerabilityDetails() {
 
 // ScanAll handles the api to scan all images on Harbor.
 func (ra *RepositoryAPI) ScanAll() {
-	if !config.WithClair() {
-		log.Warningf("Harbor is not deployed with Clair, it's not possible to scan images.")
+	if !coreutils.ScanEnabled() {
+		log.Warningf("Harbor is not deployed with Clair and no scanner is registered, it's not possible to scan images.")
 		ra.RenderError(http.StatusServiceUnavailable, "")
 		return
 	}
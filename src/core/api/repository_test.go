@@ -229,6 +229,58 @@ func TestPopulateAuthor(t *testing.T) {
 	assert.Equal(t, maintainer, detail.Author)
 }
 
+type fakeTagDigestResolver struct {
+	digests map[string]string
+}
+
+func (f *fakeTagDigestResolver) ListTag() ([]string, error) {
+	tags := []string{}
+	for tag := range f.digests {
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (f *fakeTagDigestResolver) ManifestExist(reference string) (string, bool, error) {
+	digest, exist := f.digests[reference]
+	return digest, exist, nil
+}
+
+func TestImmutableTagToDelete(t *testing.T) {
+	rc := &fakeTagDigestResolver{
+		digests: map[string]string{
+			"v1.0":   "sha256:1",
+			"latest": "sha256:1",
+			"dev":    "sha256:2",
+		},
+	}
+	p := &models.Project{
+		Name: "library",
+	}
+
+	// no immutable rules
+	tag, err := immutableTagToDelete(p, "library/app", []string{"latest"}, rc)
+	require.Nil(t, err)
+	assert.Equal(t, "", tag)
+
+	p.SetMetadata(models.ProMetaImmutableTags, `[{"repository":"app","tag":"v*"}]`)
+
+	// the tag to delete is immutable
+	tag, err = immutableTagToDelete(p, "library/app", []string{"v1.0"}, rc)
+	require.Nil(t, err)
+	assert.Equal(t, "v1.0", tag)
+
+	// the tag to delete shares the digest with an immutable tag
+	tag, err = immutableTagToDelete(p, "library/app", []string{"latest"}, rc)
+	require.Nil(t, err)
+	assert.Equal(t, "v1.0", tag)
+
+	// no immutable tag shares the digest
+	tag, err = immutableTagToDelete(p, "library/app", []string{"dev"}, rc)
+	require.Nil(t, err)
+	assert.Equal(t, "", tag)
+}
+
 func TestPutOfRepository(t *testing.T) {
 	u, err := dao.GetUser(models.User{
 		Username: projAdmin.Name,
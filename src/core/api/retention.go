@@ -0,0 +1,329 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_http "github.com/goharbor/harbor/src/common/http"
+	common_job "github.com/goharbor/harbor/src/common/job"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/api/models"
+	utils_core "github.com/goharbor/harbor/src/core/utils"
+)
+
+// RetentionAPI handles the requests on the tag retention policy of a project
+type RetentionAPI struct {
+	BaseController
+	project *common_models.Project
+	policy  *common_models.RetentionPolicy
+}
+
+// Prepare validates the project and the permission, and loads the retention policy of the project
+func (r *RetentionAPI) Prepare() {
+	r.BaseController.Prepare()
+
+	if !r.SecurityCtx.IsAuthenticated() {
+		r.HandleUnauthorized()
+		return
+	}
+
+	pid, err := r.GetInt64FromPath(":pid")
+	if err != nil || pid <= 0 {
+		r.HandleBadRequest(fmt.Sprintf("invalid project ID: %s", r.GetStringFromPath(":pid")))
+		return
+	}
+	project, err := r.ProjectMgr.Get(pid)
+	if err != nil {
+		r.ParseAndHandleError(fmt.Sprintf("failed to get project %d", pid), err)
+		return
+	}
+	if project == nil {
+		r.HandleNotFound(fmt.Sprintf("project %d not found", pid))
+		return
+	}
+	r.project = project
+
+	if !r.RequireProjectAccess(pid, rbac_project.ActionOfMethod(r.Ctx.Request.Method), rbac_project.ResourceRetention) {
+		return
+	}
+
+	policy, err := dao.GetRetentionPolicyByProject(pid)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to get the retention policy of project %d: %v", pid, err))
+		return
+	}
+	r.policy = policy
+}
+
+// Get returns the retention policy of the project
+func (r *RetentionAPI) Get() {
+	if r.policy == nil {
+		r.HandleNotFound(fmt.Sprintf("no retention policy is set for project %d", r.project.ProjectID))
+		return
+	}
+
+	rep, err := models.ConvertToRetentionPolicyRep(r.policy)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to convert the retention policy %d: %v", r.policy.ID, err))
+		return
+	}
+	r.Data["json"] = rep
+	r.ServeJSON()
+}
+
+// Put creates or updates the retention policy of the project, the schedule of the policy
+// is replaced by the one in request
+func (r *RetentionAPI) Put() {
+	req := &models.RetentionPolicyReq{}
+	r.DecodeJSONReqAndValidate(req)
+
+	rules, err := json.Marshal(req.Rules)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to marshal the retention rules: %v", err))
+		return
+	}
+	cron := ""
+	if req.IsPeriodic() {
+		data, err := json.Marshal(req.Schedule)
+		if err != nil {
+			r.HandleInternalServerError(fmt.Sprintf("failed to marshal the schedule: %v", err))
+			return
+		}
+		cron = string(data)
+	}
+
+	operation := "update"
+	policy := r.policy
+	if policy == nil {
+		operation = "create"
+		policy = &common_models.RetentionPolicy{
+			ProjectID: r.project.ProjectID,
+			Rules:     string(rules),
+		}
+		id, err := dao.AddRetentionPolicy(policy)
+		if err != nil {
+			if err == dao.ErrDupRows {
+				r.HandleConflict(fmt.Sprintf("the retention policy of project %d already exists", r.project.ProjectID))
+				return
+			}
+			r.HandleInternalServerError(fmt.Sprintf("failed to add the retention policy: %v", err))
+			return
+		}
+		policy.ID = id
+	} else {
+		if err := stopRetentionSchedule(policy); err != nil {
+			r.HandleInternalServerError(fmt.Sprintf("failed to stop the schedule of retention policy %d: %v", policy.ID, err))
+			return
+		}
+	}
+
+	policy.Rules = string(rules)
+	policy.Cron = cron
+	policy.JobUUID = ""
+	if req.IsPeriodic() {
+		job, err := models.RetentionJob(policy, req.Schedule, 0, false)
+		if err != nil {
+			r.HandleInternalServerError(fmt.Sprintf("failed to build the retention job: %v", err))
+			return
+		}
+		uuid, err := utils_core.GetJobServiceClient().SubmitJob(job)
+		if err != nil {
+			r.HandleInternalServerError(fmt.Sprintf("failed to schedule the retention policy %d: %v", policy.ID, err))
+			return
+		}
+		policy.JobUUID = uuid
+	}
+
+	if err := dao.UpdateRetentionPolicy(policy, "Rules", "Cron", "JobUUID"); err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to update the retention policy %d: %v", policy.ID, err))
+		return
+	}
+	r.recordAuditLog(r.project.ProjectID, common_models.LogResourceTypeRetentionPolicy, r.project.Name, operation)
+}
+
+// Delete removes the retention policy of the project with its schedule and executions
+func (r *RetentionAPI) Delete() {
+	if r.policy == nil {
+		r.HandleNotFound(fmt.Sprintf("no retention policy is set for project %d", r.project.ProjectID))
+		return
+	}
+
+	if err := removeRetentionPolicy(r.policy); err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to delete the retention policy %d: %v", r.policy.ID, err))
+		return
+	}
+	r.recordAuditLog(r.project.ProjectID, common_models.LogResourceTypeRetentionPolicy, r.project.Name, "delete")
+}
+
+// Run runs the retention policy of the project manually
+func (r *RetentionAPI) Run() {
+	if r.policy == nil {
+		r.HandleNotFound(fmt.Sprintf("no retention policy is set for project %d", r.project.ProjectID))
+		return
+	}
+
+	req := &models.RetentionExecutionReq{}
+	r.DecodeJSONReq(req)
+
+	execution := &common_models.RetentionExecution{
+		PolicyID:  r.policy.ID,
+		ProjectID: r.project.ProjectID,
+		DryRun:    req.DryRun,
+		Trigger:   common_models.RetentionTriggerManual,
+	}
+	id, err := dao.AddRetentionExecution(execution)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to add the retention execution: %v", err))
+		return
+	}
+	execution.ID = id
+
+	job, err := models.RetentionJob(r.policy, nil, id, req.DryRun)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to build the retention job: %v", err))
+		return
+	}
+	uuid, err := utils_core.GetJobServiceClient().SubmitJob(job)
+	if err != nil {
+		execution.Status = common_models.JobError
+		if e := dao.UpdateRetentionExecution(execution, "Status"); e != nil {
+			log.Errorf("failed to update the status of retention execution %d: %v", id, e)
+		}
+		r.HandleInternalServerError(fmt.Sprintf("failed to submit the retention job: %v", err))
+		return
+	}
+	execution.JobUUID = uuid
+	if err := dao.UpdateRetentionExecution(execution, "JobUUID"); err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to update the retention execution %d: %v", id, err))
+		return
+	}
+
+	r.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+}
+
+// ListExecutions lists the executions of the retention policy of the project, the latest first
+func (r *RetentionAPI) ListExecutions() {
+	query := &common_models.RetentionExecutionQuery{
+		ProjectID: r.project.ProjectID,
+	}
+	query.Page, query.Size = r.GetPaginationParams()
+
+	total, err := dao.CountRetentionExecutions(query)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to count the retention executions: %v", err))
+		return
+	}
+	executions, err := dao.ListRetentionExecutions(query)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to list the retention executions: %v", err))
+		return
+	}
+
+	r.SetPaginationHeader(total, query.Page, query.Size)
+	r.Data["json"] = executions
+	r.ServeJSON()
+}
+
+// GetExecution returns the execution with the report of what was removed
+func (r *RetentionAPI) GetExecution() {
+	execution := r.getExecution()
+	if execution == nil {
+		return
+	}
+
+	rep, err := models.ConvertToRetentionExecutionRep(execution)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to convert the retention execution %d: %v", execution.ID, err))
+		return
+	}
+	r.Data["json"] = rep
+	r.ServeJSON()
+}
+
+// GetExecutionLog returns the log of the execution
+func (r *RetentionAPI) GetExecutionLog() {
+	execution := r.getExecution()
+	if execution == nil {
+		return
+	}
+	if len(execution.JobUUID) == 0 {
+		r.HandleNotFound(fmt.Sprintf("the log of retention execution %d not found", execution.ID))
+		return
+	}
+
+	logBytes, err := utils_core.GetJobServiceClient().GetJobLog(execution.JobUUID)
+	if err != nil {
+		if httpErr, ok := err.(*common_http.Error); ok {
+			r.RenderError(httpErr.Code, "")
+			log.Errorf("failed to get log of retention execution %d: %d %s",
+				execution.ID, httpErr.Code, httpErr.Message)
+			return
+		}
+		r.HandleInternalServerError(fmt.Sprintf("failed to get job logs, uuid: %s, error: %v", execution.JobUUID, err))
+		return
+	}
+	r.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Length"), strconv.Itoa(len(logBytes)))
+	r.Ctx.ResponseWriter.Header().Set(http.CanonicalHeaderKey("Content-Type"), "text/plain")
+	if _, err = r.Ctx.ResponseWriter.Write(logBytes); err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to write job logs, uuid: %s, error: %v", execution.JobUUID, err))
+	}
+}
+
+// getExecution loads the execution specified in path, nil is returned if the error has been handled
+func (r *RetentionAPI) getExecution() *common_models.RetentionExecution {
+	id, err := r.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		r.HandleBadRequest(fmt.Sprintf("invalid execution ID: %s", r.GetStringFromPath(":id")))
+		return nil
+	}
+	execution, err := dao.GetRetentionExecution(id)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to get the retention execution %d: %v", id, err))
+		return nil
+	}
+	if execution == nil || execution.ProjectID != r.project.ProjectID {
+		r.HandleNotFound(fmt.Sprintf("retention execution %d not found", id))
+		return nil
+	}
+	return execution
+}
+
+// stopRetentionSchedule stops the periodic job of the policy if it is scheduled
+func stopRetentionSchedule(policy *common_models.RetentionPolicy) error {
+	if len(policy.JobUUID) == 0 {
+		return nil
+	}
+	if err := utils_core.GetJobServiceClient().PostAction(policy.JobUUID, common_job.JobActionStop); err != nil {
+		if e, ok := err.(*common_http.Error); !ok || e.Code != http.StatusNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeRetentionPolicy stops the schedule of the policy and deletes it
+func removeRetentionPolicy(policy *common_models.RetentionPolicy) error {
+	if err := stopRetentionSchedule(policy); err != nil {
+		return err
+	}
+	return dao.DeleteRetentionPolicy(policy.ID)
+}
@@ -0,0 +1,178 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/core/api/models"
+)
+
+var retentionPath = "/api/projects/1/retention"
+
+func TestRetentionAPI(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    retentionPath,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404, project not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/10000/retention",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 404, no policy
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        retentionPath,
+				credential: projGuest,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    retentionPath,
+				bodyJSON: &models.RetentionPolicyReq{
+					Rules: &common_models.RetentionRules{KeepLatest: 10},
+				},
+				credential: projDeveloper,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, no rule
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    retentionPath,
+				bodyJSON: &models.RetentionPolicyReq{
+					Rules: &common_models.RetentionRules{},
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, invalid schedule
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    retentionPath,
+				bodyJSON: &models.RetentionPolicyReq{
+					Rules:    &common_models.RetentionRules{KeepLatest: 10},
+					Schedule: &models.ScheduleParam{Type: "Monthly"},
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200, create
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    retentionPath,
+				bodyJSON: &models.RetentionPolicyReq{
+					Rules: &common_models.RetentionRules{KeepLatest: 10},
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 200, update
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    retentionPath,
+				bodyJSON: &models.RetentionPolicyReq{
+					Rules: &common_models.RetentionRules{
+						KeepDays:     7,
+						KeepPatterns: []string{"v*"},
+					},
+					Schedule: &models.ScheduleParam{Type: models.ScheduleNone},
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        retentionPath,
+				credential: projGuest,
+			},
+			code: http.StatusOK,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        retentionPath + "/executions",
+				credential: projGuest,
+			},
+			code: http.StatusOK,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("%s/executions/%d", retentionPath, 10000),
+				credential: projGuest,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        retentionPath,
+				credential: projGuest,
+			},
+			code: http.StatusForbidden,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        retentionPath,
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, run without policy
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        retentionPath + "/executions",
+				bodyJSON:   &models.RetentionExecutionReq{DryRun: true},
+				credential: projAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+}
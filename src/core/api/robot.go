@@ -16,17 +16,23 @@ package api
 
 import (
 	"fmt"
-	"github.com/goharbor/harbor/src/common/dao"
-	"github.com/goharbor/harbor/src/common/models"
 	"net/http"
+	"regexp"
 	"strconv"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/core/config"
 )
 
-// User this prefix to distinguish harbor user,
-// The prefix contains a specific character($), so it cannot be registered as a harbor user.
-const robotPrefix = "robot$"
+var robotNameRe = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[._-][a-zA-Z0-9]+)*$`)
 
-// RobotAPI ...
+// RobotAPI handles the robot accounts of a project, or the system level robot accounts
+// whose access can span multiple projects if no project is specified in the path
 type RobotAPI struct {
 	BaseController
 	project *models.Project
@@ -36,39 +42,49 @@ type RobotAPI struct {
 // Prepare ...
 func (r *RobotAPI) Prepare() {
 	r.BaseController.Prepare()
-	method := r.Ctx.Request.Method
 
 	if !r.SecurityCtx.IsAuthenticated() {
 		r.HandleUnauthorized()
 		return
 	}
 
-	pid, err := r.GetInt64FromPath(":pid")
-	if err != nil || pid <= 0 {
-		var errMsg string
+	if len(r.GetStringFromPath(":pid")) == 0 {
+		if !r.SecurityCtx.IsSysAdmin() {
+			r.HandleForbidden(r.SecurityCtx.GetUsername())
+			return
+		}
+	} else {
+		pid, err := r.GetInt64FromPath(":pid")
+		if err != nil || pid <= 0 {
+			var errMsg string
+			if err != nil {
+				errMsg = "failed to get project ID " + err.Error()
+			} else {
+				errMsg = "invalid project ID: " + fmt.Sprintf("%d", pid)
+			}
+			r.HandleBadRequest(errMsg)
+			return
+		}
+		project, err := r.ProjectMgr.Get(pid)
 		if err != nil {
-			errMsg = "failed to get project ID " + err.Error()
-		} else {
-			errMsg = "invalid project ID: " + fmt.Sprintf("%d", pid)
+			r.ParseAndHandleError(fmt.Sprintf("failed to get project %d", pid), err)
+			return
+		}
+		if project == nil {
+			r.HandleNotFound(fmt.Sprintf("project %d not found", pid))
+			return
+		}
+		r.project = project
+
+		if !r.RequireProjectAccess(pid, rbac_project.ActionOfMethod(r.Ctx.Request.Method), rbac_project.ResourceRobot) {
+			return
 		}
-		r.HandleBadRequest(errMsg)
-		return
-	}
-	project, err := r.ProjectMgr.Get(pid)
-	if err != nil {
-		r.ParseAndHandleError(fmt.Sprintf("failed to get project %d", pid), err)
-		return
-	}
-	if project == nil {
-		r.HandleNotFound(fmt.Sprintf("project %d not found", pid))
-		return
 	}
-	r.project = project
 
-	if method == http.MethodPut || method == http.MethodDelete {
+	if len(r.GetStringFromPath(":id")) > 0 {
 		id, err := r.GetInt64FromPath(":id")
 		if err != nil || id <= 0 {
-			r.HandleBadRequest("invalid robot ID")
+			r.HandleBadRequest(fmt.Sprintf("invalid robot ID: %s", r.GetStringFromPath(":id")))
 			return
 		}
 
@@ -78,36 +94,70 @@ func (r *RobotAPI) Prepare() {
 			return
 		}
 
-		if robot == nil {
+		if robot == nil || robot.ProjectID != r.projectID() {
 			r.HandleNotFound(fmt.Sprintf("robot %d not found", id))
 			return
 		}
 
 		r.robot = robot
 	}
+}
 
-	if !(r.Ctx.Input.IsGet() && r.SecurityCtx.HasReadPerm(pid) ||
-		r.SecurityCtx.HasAllPerm(pid)) {
-		r.HandleForbidden(r.SecurityCtx.GetUsername())
-		return
+// projectID returns the ID of the project the robots belong to, 0 for the system level robots
+func (r *RobotAPI) projectID() int64 {
+	if r.project == nil {
+		return 0
 	}
-
+	return r.project.ProjectID
 }
 
 // Post ...
 func (r *RobotAPI) Post() {
 	var robotReq models.RobotReq
-	r.DecodeJSONReq(&robotReq)
+	r.DecodeJSONReqAndValidate(&robotReq)
+
+	if !robotNameRe.MatchString(robotReq.Name) {
+		r.HandleBadRequest(fmt.Sprintf("invalid robot name: %s", robotReq.Name))
+		return
+	}
+
+	for _, pid := range robotReq.ProjectIDs() {
+		if r.project != nil {
+			if pid != r.project.ProjectID {
+				r.HandleBadRequest(fmt.Sprintf("the access of the robot is limited to the project %d", r.project.ProjectID))
+				return
+			}
+			continue
+		}
+		exist, err := r.ProjectMgr.Exists(pid)
+		if err != nil {
+			r.ParseAndHandleError(fmt.Sprintf("failed to check the existence of project %d", pid), err)
+			return
+		}
+		if !exist {
+			r.HandleBadRequest(fmt.Sprintf("project %d not found", pid))
+			return
+		}
+	}
 
-	createdName := robotPrefix + robotReq.Name
+	expiresAt := robotReq.ExpiresAt
+	if expiresAt == 0 {
+		var err error
+		if expiresAt, err = defaultRobotExpiration(); err != nil {
+			r.HandleInternalServerError(fmt.Sprintf("failed to get the default robot token duration: %v", err))
+			return
+		}
+	}
 
 	robot := models.Robot{
-		Name:        createdName,
+		Name:        common.RobotPrefix + robotReq.Name,
 		Description: robotReq.Description,
-		ProjectID:   r.project.ProjectID,
-		// TODO: use token service to generate token per access information
-		Token: "this is a placeholder",
+		ProjectID:   r.projectID(),
+		Disabled:    robotReq.Disabled,
+		ExpiresAt:   expiresAt,
+		Access:      robotReq.Access,
 	}
+	secret := newRobotToken(&robot)
 
 	id, err := dao.AddRobot(&robot)
 	if err != nil {
@@ -119,9 +169,12 @@ func (r *RobotAPI) Post() {
 		return
 	}
 
+	r.recordAuditLog(robot.ProjectID, models.LogResourceTypeRobot, robot.Name, "create")
+
 	robotRep := models.RobotRep{
-		Name:  robot.Name,
-		Token: robot.Token,
+		Name:      robot.Name,
+		Token:     secret,
+		ExpiresAt: robot.ExpiresAt,
 	}
 
 	r.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
@@ -129,15 +182,16 @@ func (r *RobotAPI) Post() {
 	r.ServeJSON()
 }
 
-// List list all the robots of a project
+// List list all the robots of a project, or the system level robots
 func (r *RobotAPI) List() {
 	query := models.RobotQuery{
-		ProjectID: r.project.ProjectID,
+		ProjectID:   r.projectID(),
+		SystemLevel: r.project == nil,
 	}
 
 	count, err := dao.CountRobot(&query)
 	if err != nil {
-		r.HandleInternalServerError(fmt.Sprintf("failed to list robots on project: %d, %v", r.project.ProjectID, err))
+		r.HandleInternalServerError(fmt.Sprintf("failed to list robots on project: %d, %v", r.projectID(), err))
 		return
 	}
 	query.Page, query.Size = r.GetPaginationParams()
@@ -155,23 +209,7 @@ func (r *RobotAPI) List() {
 
 // Get get robot by id
 func (r *RobotAPI) Get() {
-	id, err := r.GetInt64FromPath(":id")
-	if err != nil || id <= 0 {
-		r.HandleBadRequest(fmt.Sprintf("invalid robot ID: %s", r.GetStringFromPath(":id")))
-		return
-	}
-
-	robot, err := dao.GetRobotByID(id)
-	if err != nil {
-		r.HandleInternalServerError(fmt.Sprintf("failed to get robot %d: %v", id, err))
-		return
-	}
-	if robot == nil {
-		r.HandleNotFound(fmt.Sprintf("robot %d not found", id))
-		return
-	}
-
-	r.Data["json"] = robot
+	r.Data["json"] = r.robot
 	r.ServeJSON()
 }
 
@@ -181,11 +219,35 @@ func (r *RobotAPI) Put() {
 	r.DecodeJSONReqAndValidate(&robotReq)
 	r.robot.Disabled = robotReq.Disabled
 
-	if err := dao.UpdateRobot(r.robot); err != nil {
+	if err := dao.UpdateRobot(r.robot, "Disabled"); err != nil {
 		r.HandleInternalServerError(fmt.Sprintf("failed to update robot %d: %v", r.robot.ID, err))
 		return
 	}
+	r.recordAuditLog(r.robot.ProjectID, models.LogResourceTypeRobot, r.robot.Name, "update")
+}
 
+// RefreshToken rotates the token of the robot account, the previous token is invalidated
+// and the expiration of the new one is reset to the system default
+func (r *RobotAPI) RefreshToken() {
+	expiresAt, err := defaultRobotExpiration()
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to get the default robot token duration: %v", err))
+		return
+	}
+	r.robot.ExpiresAt = expiresAt
+	secret := newRobotToken(r.robot)
+	if err := dao.UpdateRobot(r.robot, "Token", "Salt", "ExpiresAt"); err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to update robot %d: %v", r.robot.ID, err))
+		return
+	}
+	r.recordAuditLog(r.robot.ProjectID, models.LogResourceTypeRobot, r.robot.Name, "refresh_token")
+
+	r.Data["json"] = models.RobotRep{
+		Name:      r.robot.Name,
+		Token:     secret,
+		ExpiresAt: r.robot.ExpiresAt,
+	}
+	r.ServeJSON()
 }
 
 // Delete delete robot by id
@@ -194,4 +256,27 @@ func (r *RobotAPI) Delete() {
 		r.HandleInternalServerError(fmt.Sprintf("failed to delete robot %d: %v", r.robot.ID, err))
 		return
 	}
+	r.recordAuditLog(r.robot.ProjectID, models.LogResourceTypeRobot, r.robot.Name, "delete")
+}
+
+// newRobotToken generates a secret for the robot and stores the salted hash of it
+// as the token, the secret is returned to be handed out only once
+func newRobotToken(robot *models.Robot) string {
+	secret := utils.GenerateRandomString()
+	robot.Salt = utils.GenerateRandomString()
+	robot.Token = utils.Encrypt(secret, robot.Salt)
+	return secret
+}
+
+// defaultRobotExpiration returns the expiration of the robot tokens created now according
+// to the configured duration
+func defaultRobotExpiration() (int64, error) {
+	days, err := config.RobotTokenDuration()
+	if err != nil {
+		return 0, err
+	}
+	if days <= 0 {
+		return models.RobotNeverExpire, nil
+	}
+	return time.Now().AddDate(0, 0, days).Unix(), nil
 }
@@ -16,7 +16,10 @@ package api
 
 import (
 	"fmt"
+	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"net/http"
 	"testing"
 )
@@ -312,3 +315,146 @@ func TestRobotAPIDelete(t *testing.T) {
 
 	runCodeCheckingCases(t, cases...)
 }
+
+func TestSystemRobotAPI(t *testing.T) {
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/robots",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, only system admin is allowed
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/robots",
+				credential: projAdmin4Robot,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid name
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/robots",
+				bodyJSON: &models.RobotReq{
+					Name: "ci,robot",
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, unsupported action
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/robots",
+				bodyJSON: &models.RobotReq{
+					Name: "ci",
+					Access: []*models.ResourceActions{
+						{Name: "/project/1/image", Actions: []string{"delete"}},
+					},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, project not found
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/robots",
+				bodyJSON: &models.RobotReq{
+					Name: "ci",
+					Access: []*models.ResourceActions{
+						{Name: "/project/10000/image", Actions: []string{"pull"}},
+					},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, the access of project robot is limited to the project
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    robotPath,
+				bodyJSON: &models.RobotReq{
+					Name: "ci",
+					Access: []*models.ResourceActions{
+						{Name: "/project/2/image", Actions: []string{"pull"}},
+					},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/robots",
+				bodyJSON: &models.RobotReq{
+					Name: "ci",
+					Access: []*models.ResourceActions{
+						{Name: "/project/1/image", Actions: []string{"pull"}},
+						{Name: "/project/1/helm-repo", Actions: []string{"read"}},
+					},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusCreated,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	robots, err := dao.ListRobots(&models.RobotQuery{
+		SystemLevel: true,
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, len(robots))
+	robot := robots[0]
+	defer dao.DeleteRobot(robot.ID)
+	assert.Equal(t, "robot$ci", robot.Name)
+	assert.True(t, robot.ExpiresAt > 0)
+	assert.Equal(t, 2, len(robot.Access))
+
+	robotPath := fmt.Sprintf("/api/robots/%d", robot.ID)
+	cases = []*codeCheckingCase{
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        robotPath,
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 404, the system robot cannot be got via project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        fmt.Sprintf("/api/projects/1/robots/%d", robot.ID),
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        robotPath + "/token",
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	rotated, err := dao.GetRobotByID(robot.ID)
+	require.Nil(t, err)
+	assert.NotEqual(t, robot.Token, rotated.Token)
+}
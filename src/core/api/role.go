@@ -0,0 +1,147 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+)
+
+// RoleAPI handles the requests on the project roles, all the authenticated users can read the roles
+// to assign them to the project members, only the system admin can manage the custom roles
+type RoleAPI struct {
+	BaseController
+	role *models.Role
+}
+
+// Prepare validates the permission, and loads the role if its ID is in the path
+func (r *RoleAPI) Prepare() {
+	r.BaseController.Prepare()
+
+	if !r.SecurityCtx.IsAuthenticated() {
+		r.HandleUnauthorized()
+		return
+	}
+	if r.Ctx.Request.Method != http.MethodGet && !r.SecurityCtx.IsSysAdmin() {
+		r.HandleForbidden(r.SecurityCtx.GetUsername())
+		return
+	}
+
+	if len(r.GetStringFromPath(":id")) == 0 {
+		return
+	}
+	id, err := r.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		r.HandleBadRequest(fmt.Sprintf("invalid role ID: %s", r.GetStringFromPath(":id")))
+		return
+	}
+	role, err := dao.GetRoleByID(int(id))
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to get the role %d: %v", id, err))
+		return
+	}
+	if role == nil {
+		r.HandleNotFound(fmt.Sprintf("role %d not found", id))
+		return
+	}
+	if r.Ctx.Request.Method != http.MethodGet && rbac_project.IsBuiltInRole(role.RoleID) {
+		r.HandleBadRequest(fmt.Sprintf("the built-in role %s can't be modified", role.Name))
+		return
+	}
+	r.role = role
+}
+
+// List returns the built-in and the custom roles
+func (r *RoleAPI) List() {
+	roles, err := dao.ListRoles()
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to list the roles: %v", err))
+		return
+	}
+	r.Data["json"] = roles
+	r.ServeJSON()
+}
+
+// Get returns the role
+func (r *RoleAPI) Get() {
+	r.Data["json"] = r.role
+	r.ServeJSON()
+}
+
+// Post creates a custom role
+func (r *RoleAPI) Post() {
+	req := &models.RoleReq{}
+	r.DecodeJSONReqAndValidate(req)
+
+	role := &models.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Policies:    req.Policies,
+	}
+	id, err := dao.AddRole(role)
+	if err != nil {
+		if err == dao.ErrDupRows {
+			r.HandleConflict(fmt.Sprintf("role %s already exists", req.Name))
+			return
+		}
+		r.HandleInternalServerError(fmt.Sprintf("failed to add the role: %v", err))
+		return
+	}
+	r.recordAuditLog(0, models.LogResourceTypeRole, role.Name, "create")
+
+	r.Redirect(http.StatusCreated, strconv.Itoa(id))
+}
+
+// Put updates the name, description and policies of the custom role
+func (r *RoleAPI) Put() {
+	req := &models.RoleReq{}
+	r.DecodeJSONReqAndValidate(req)
+
+	r.role.Name = req.Name
+	r.role.Description = req.Description
+	r.role.Policies = req.Policies
+	if err := dao.UpdateRole(r.role); err != nil {
+		if err == dao.ErrDupRows {
+			r.HandleConflict(fmt.Sprintf("role %s already exists", req.Name))
+			return
+		}
+		r.HandleInternalServerError(fmt.Sprintf("failed to update the role %d: %v", r.role.RoleID, err))
+		return
+	}
+	r.recordAuditLog(0, models.LogResourceTypeRole, r.role.Name, "update")
+}
+
+// Delete deletes the custom role, the roles assigned to project members can't be deleted
+func (r *RoleAPI) Delete() {
+	count, err := dao.CountRoleMembers(r.role.RoleID)
+	if err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to count the members of the role %d: %v", r.role.RoleID, err))
+		return
+	}
+	if count > 0 {
+		r.HandleConflict(fmt.Sprintf("the role %s is assigned to %d project member(s)", r.role.Name, count))
+		return
+	}
+	if err := dao.DeleteRole(r.role.RoleID); err != nil {
+		r.HandleInternalServerError(fmt.Sprintf("failed to delete the role %d: %v", r.role.RoleID, err))
+		return
+	}
+	r.recordAuditLog(0, models.LogResourceTypeRole, r.role.Name, "delete")
+}
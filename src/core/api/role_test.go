@@ -0,0 +1,294 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleAPI(t *testing.T) {
+	req := &models.RoleReq{
+		Name: "member_reader",
+		Policies: []*models.RolePolicy{
+			{Resource: project.ResourceSelf, Action: project.ActionRead},
+			{Resource: project.ResourceMember, Action: project.ActionRead},
+		},
+	}
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/roles",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 200, all the authenticated users can list the roles
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/roles",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 403, only the system admin can manage the roles
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/roles",
+				bodyJSON:   req,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, invalid policy
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/roles",
+				bodyJSON: &models.RoleReq{
+					Name:     "invalid",
+					Policies: []*models.RolePolicy{{Resource: project.ResourceMember, Action: project.ActionPush}},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/roles",
+				bodyJSON:   req,
+				credential: sysAdmin,
+			},
+			code: http.StatusCreated,
+		},
+		// 409
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        "/api/roles",
+				bodyJSON:   req,
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+		// 400, the built-in roles can't be modified
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        fmt.Sprintf("/api/roles/%d", common.RoleGuest),
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/roles/10000",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	role, err := dao.GetRoleByName("member_reader")
+	require.Nil(t, err)
+	require.NotNil(t, role)
+	defer dao.DeleteRole(role.RoleID)
+	rolePath := fmt.Sprintf("/api/roles/%d", role.RoleID)
+
+	r := &models.Role{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        rolePath,
+		credential: nonSysAdmin,
+	}, r)
+	require.Nil(t, err)
+	assert.Equal(t, "member_reader", r.Name)
+	assert.False(t, r.BuiltIn)
+	assert.Len(t, r.Policies, 2)
+
+	req.Description = "read the members of the project"
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPut,
+			url:        rolePath,
+			bodyJSON:   req,
+			credential: sysAdmin,
+		},
+		code: http.StatusOK,
+	})
+	role, err = dao.GetRoleByID(role.RoleID)
+	require.Nil(t, err)
+	require.NotNil(t, role)
+	assert.Equal(t, "read the members of the project", role.Description)
+
+	// the custom role is assigned to the user through the project member API and evaluated by rbac
+	userID, err := dao.Register(models.User{
+		Username: "role_api_user",
+		Password: "Harbor12345",
+		Email:    "role_api_user@example.com",
+	})
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(userID))
+	user := &usrInfo{Name: "role_api_user", Passwd: "Harbor12345"}
+
+	cases = []*codeCheckingCase{
+		// 403, not a member of the project
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/members",
+				credential: user,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, role not found
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/projects/1/members",
+				bodyJSON: &models.MemberReq{
+					Role:       10000,
+					MemberUser: models.User{UserID: int(userID)},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/projects/1/members",
+				bodyJSON: &models.MemberReq{
+					Role:       role.RoleID,
+					MemberUser: models.User{UserID: int(userID)},
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusCreated,
+		},
+		// 200, the custom role allows to read the members
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/1/members",
+				credential: user,
+			},
+			code: http.StatusOK,
+		},
+		// 403, but not to add them
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/projects/1/members",
+				bodyJSON: &models.MemberReq{
+					Role:       common.RoleGuest,
+					MemberUser: models.User{Username: "non_admin"},
+				},
+				credential: user,
+			},
+			code: http.StatusForbidden,
+		},
+		// 409, the role is assigned to the member
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        rolePath,
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	// the user can only grant the roles whose policies it has on the project
+	granteeID, err := dao.Register(models.User{
+		Username: "role_api_grantee",
+		Password: "Harbor12345",
+		Email:    "role_api_grantee@example.com",
+	})
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(granteeID))
+	req.Policies = append(req.Policies,
+		&models.RolePolicy{Resource: project.ResourceMember, Action: project.ActionCreate},
+		&models.RolePolicy{Resource: project.ResourceMember, Action: project.ActionUpdate},
+	)
+	cases = []*codeCheckingCase{
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        rolePath,
+				bodyJSON:   req,
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 403, the user can't pull images as the guest
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/projects/1/members",
+				bodyJSON: &models.MemberReq{
+					Role:       common.RoleGuest,
+					MemberUser: models.User{UserID: int(granteeID)},
+				},
+				credential: user,
+			},
+			code: http.StatusForbidden,
+		},
+		// 201, the user has all the policies of its own role
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/projects/1/members",
+				bodyJSON: &models.MemberReq{
+					Role:       role.RoleID,
+					MemberUser: models.User{UserID: int(granteeID)},
+				},
+				credential: user,
+			},
+			code: http.StatusCreated,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	_, err = dao.GetOrmer().Raw(`delete from project_member where entity_id in (?, ?) and entity_type = 'u'`, userID, granteeID).Exec()
+	require.Nil(t, err)
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodDelete,
+			url:        rolePath,
+			credential: sysAdmin,
+		},
+		code: http.StatusOK,
+	})
+}
@@ -17,6 +17,7 @@ package api
 import (
 	"github.com/goharbor/harbor/src/common/dao"
 	common_http "github.com/goharbor/harbor/src/common/http"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/utils"
 
@@ -54,9 +55,10 @@ func (sj *ScanJobAPI) Prepare() {
 		sj.CustomAbort(http.StatusInternalServerError, "Failed to get Job data")
 	}
 	projectName := strings.SplitN(data.Repository, "/", 2)[0]
-	if !sj.SecurityCtx.HasReadPerm(projectName) {
+	if !sj.HasProjectPermission(projectName, rbac_project.ActionRead, rbac_project.ResourceScan) {
 		log.Errorf("User does not have read permission for project: %s", projectName)
 		sj.HandleForbidden(sj.SecurityCtx.GetUsername())
+		return
 	}
 	sj.projectName = projectName
 	sj.jobUUID = data.UUID
@@ -0,0 +1,297 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/scanner"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/goharbor/harbor/src/core/config"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+)
+
+// ScannerAPI handles the requests on the scanner registrations, only the system admin is allowed
+// as the registrations carry the credentials of the scanners
+type ScannerAPI struct {
+	BaseController
+	reg *common_models.ScannerRegistration
+}
+
+// Prepare validates the permission, and loads the registration if its ID is in the path
+func (s *ScannerAPI) Prepare() {
+	s.BaseController.Prepare()
+
+	if !s.SecurityCtx.IsAuthenticated() {
+		s.HandleUnauthorized()
+		return
+	}
+	if !s.SecurityCtx.IsSysAdmin() {
+		s.HandleForbidden(s.SecurityCtx.GetUsername())
+		return
+	}
+
+	if len(s.GetStringFromPath(":id")) == 0 {
+		return
+	}
+	id, err := s.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		s.HandleBadRequest(fmt.Sprintf("invalid scanner ID: %s", s.GetStringFromPath(":id")))
+		return
+	}
+	reg, err := dao.GetScannerRegistration(id)
+	if err != nil {
+		s.HandleInternalServerError(fmt.Sprintf("failed to get the scanner %d: %v", id, err))
+		return
+	}
+	if reg == nil {
+		s.HandleNotFound(fmt.Sprintf("scanner %d not found", id))
+		return
+	}
+	s.reg = reg
+}
+
+// List returns all the scanner registrations
+func (s *ScannerAPI) List() {
+	regs, err := dao.ListScannerRegistrations()
+	if err != nil {
+		s.HandleInternalServerError(fmt.Sprintf("failed to list the scanners: %v", err))
+		return
+	}
+
+	reps := []*models.ScannerRegistrationRep{}
+	for _, reg := range regs {
+		reps = append(reps, models.ConvertToScannerRegistrationRep(reg))
+	}
+	s.Data["json"] = reps
+	s.ServeJSON()
+}
+
+// Post registers a scanner, the scanner must respond to the metadata request to be registered
+func (s *ScannerAPI) Post() {
+	req := &models.ScannerRegistrationReq{}
+	s.DecodeJSONReqAndValidate(req)
+
+	reg := &common_models.ScannerRegistration{}
+	req.ApplyTo(reg)
+	if !s.ping(reg) {
+		return
+	}
+	id, err := dao.AddScannerRegistration(reg)
+	if err != nil {
+		if err == dao.ErrDupRows {
+			s.HandleConflict(fmt.Sprintf("scanner with name %s or url %s already exists", reg.Name, reg.URL))
+			return
+		}
+		s.HandleInternalServerError(fmt.Sprintf("failed to add the scanner: %v", err))
+		return
+	}
+	s.recordAuditLog(0, common_models.LogResourceTypeScanner, reg.Name, "create")
+
+	s.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+}
+
+// Get returns the scanner registration
+func (s *ScannerAPI) Get() {
+	s.Data["json"] = models.ConvertToScannerRegistrationRep(s.reg)
+	s.ServeJSON()
+}
+
+// Put updates the scanner registration, only the name, description and status of the built-in scanner can be updated
+func (s *ScannerAPI) Put() {
+	req := &models.ScannerRegistrationReq{}
+	s.DecodeJSONReq(req)
+
+	if s.reg.IsBuiltin() {
+		req.URL = s.reg.URL
+		req.AuthHeader = nil
+		req.SkipCertVerify = false
+	} else if req.URL == common_models.BuiltinClairScannerURL {
+		s.HandleBadRequest("the url of the built-in scanner is reserved")
+		return
+	}
+	if req.Disabled && s.reg.IsDefault {
+		s.HandleBadRequest("the default scanner can't be disabled")
+		return
+	}
+	req.ApplyTo(s.reg)
+	if err := s.reg.Valid(); err != nil {
+		s.HandleBadRequest(err.Error())
+		return
+	}
+	if !s.reg.IsBuiltin() && !s.reg.Disabled && !s.ping(s.reg) {
+		return
+	}
+	if err := dao.UpdateScannerRegistration(s.reg); err != nil {
+		if err == dao.ErrDupRows {
+			s.HandleConflict(fmt.Sprintf("scanner with name %s or url %s already exists", s.reg.Name, s.reg.URL))
+			return
+		}
+		s.HandleInternalServerError(fmt.Sprintf("failed to update the scanner %d: %v", s.reg.ID, err))
+		return
+	}
+	s.recordAuditLog(0, common_models.LogResourceTypeScanner, s.reg.Name, "update")
+}
+
+// Delete deletes the scanner registration, the built-in scanner, the default scanner and
+// the scanners picked by projects can't be deleted
+func (s *ScannerAPI) Delete() {
+	if s.reg.IsBuiltin() {
+		s.HandleBadRequest("the built-in scanner can't be deleted")
+		return
+	}
+	if s.reg.IsDefault {
+		s.HandleBadRequest("the default scanner can't be deleted")
+		return
+	}
+	metas, err := dao.ListProjectMetadata(common_models.ProMetaScanner, strconv.FormatInt(s.reg.ID, 10))
+	if err != nil {
+		s.HandleInternalServerError(fmt.Sprintf("failed to list the projects using the scanner %d: %v", s.reg.ID, err))
+		return
+	}
+	if len(metas) > 0 {
+		s.HandleConflict(fmt.Sprintf("the scanner %s is used by %d project(s)", s.reg.Name, len(metas)))
+		return
+	}
+	if err := dao.DeleteScannerRegistration(s.reg.ID); err != nil {
+		s.HandleInternalServerError(fmt.Sprintf("failed to delete the scanner %d: %v", s.reg.ID, err))
+		return
+	}
+	s.recordAuditLog(0, common_models.LogResourceTypeScanner, s.reg.Name, "delete")
+}
+
+// SetDefault makes the registration the default scanner, which is used by the projects not picking any scanner
+func (s *ScannerAPI) SetDefault() {
+	if s.reg.Disabled {
+		s.HandleBadRequest(fmt.Sprintf("the scanner %s is disabled", s.reg.Name))
+		return
+	}
+	if err := dao.SetDefaultScannerRegistration(s.reg.ID); err != nil {
+		s.HandleInternalServerError(fmt.Sprintf("failed to set the scanner %d as default: %v", s.reg.ID, err))
+		return
+	}
+	s.recordAuditLog(0, common_models.LogResourceTypeScanner, s.reg.Name, "set_default")
+}
+
+// Metadata returns the metadata reported by the scanner
+func (s *ScannerAPI) Metadata() {
+	adapter, err := scanner.New(s.reg, config.ClairEndpoint())
+	if err != nil {
+		s.HandleBadRequest(err.Error())
+		return
+	}
+	metadata, err := adapter.Metadata()
+	if err != nil {
+		s.HandleInternalServerError(fmt.Sprintf("failed to get the metadata of the scanner %s: %v", s.reg.Name, err))
+		return
+	}
+	s.Data["json"] = metadata
+	s.ServeJSON()
+}
+
+// ping checks whether the scanner responds to the metadata request, it returns false after rendering
+// the error if the scanner isn't reachable
+func (s *ScannerAPI) ping(reg *common_models.ScannerRegistration) bool {
+	adapter, err := scanner.New(reg, config.ClairEndpoint())
+	if err == nil {
+		_, err = adapter.Metadata()
+	}
+	if err != nil {
+		s.HandleBadRequest(fmt.Sprintf("failed to ping the scanner %s: %v", reg.URL, err))
+		return false
+	}
+	return true
+}
+
+// ProjectScannerAPI handles the requests on the scanner picked by a project
+type ProjectScannerAPI struct {
+	BaseController
+	project *common_models.Project
+}
+
+// Prepare validates the project and the permission, the members of the project can read the scanner
+// while only the project admin can change it
+func (p *ProjectScannerAPI) Prepare() {
+	p.BaseController.Prepare()
+
+	if !p.SecurityCtx.IsAuthenticated() {
+		p.HandleUnauthorized()
+		return
+	}
+
+	pid, err := p.GetInt64FromPath(":pid")
+	if err != nil || pid <= 0 {
+		p.HandleBadRequest(fmt.Sprintf("invalid project ID: %s", p.GetStringFromPath(":pid")))
+		return
+	}
+	project, err := p.ProjectMgr.Get(pid)
+	if err != nil {
+		p.ParseAndHandleError(fmt.Sprintf("failed to get project %d", pid), err)
+		return
+	}
+	if project == nil {
+		p.HandleNotFound(fmt.Sprintf("project %d not found", pid))
+		return
+	}
+	if !p.RequireProjectAccess(pid, rbac_project.ActionOfMethod(p.Ctx.Request.Method), rbac_project.ResourceScanner) {
+		return
+	}
+	p.project = project
+}
+
+// Get returns the scanner used by the project
+func (p *ProjectScannerAPI) Get() {
+	reg, err := coreutils.GetProjectScanner(p.project.Name)
+	if err != nil {
+		p.HandleNotFound(err.Error())
+		return
+	}
+	p.Data["json"] = models.ConvertToScannerRegistrationRep(reg)
+	p.ServeJSON()
+}
+
+// Put sets the scanner of the project, the ID 0 makes the project use the default scanner
+func (p *ProjectScannerAPI) Put() {
+	req := &models.ProjectScannerReq{}
+	p.DecodeJSONReq(req)
+
+	metas, err := validateProjectMetadata(map[string]string{
+		common_models.ProMetaScanner: strconv.FormatInt(req.ScannerID, 10),
+	})
+	if err != nil {
+		p.HandleBadRequest(err.Error())
+		return
+	}
+	metaMgr := p.ProjectMgr.GetMetadataManager()
+	if metaMgr == nil {
+		p.RenderError(http.StatusMethodNotAllowed, "")
+		return
+	}
+	if _, exist := p.project.GetMetadata(common_models.ProMetaScanner); exist {
+		err = metaMgr.Update(p.project.ProjectID, metas)
+	} else {
+		err = metaMgr.Add(p.project.ProjectID, metas)
+	}
+	if err != nil {
+		p.HandleInternalServerError(fmt.Sprintf("failed to set the scanner of project %d: %v", p.project.ProjectID, err))
+		return
+	}
+	p.recordAuditLog(p.project.ProjectID, common_models.LogResourceTypeScanner, p.project.Name, "update")
+}
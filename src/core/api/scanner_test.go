@@ -0,0 +1,232 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/scanner"
+	"github.com/goharbor/harbor/src/common/scanner/test"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScannerAPI(t *testing.T) {
+	server := test.NewMockServer(&test.Adapter{})
+	defer server.Close()
+
+	authHeader := "Bearer token"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    "/api/scanners",
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, only the system admin is allowed
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/scanners",
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, the url of the built-in scanner is reserved
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/scanners",
+				bodyJSON: &models.ScannerRegistrationReq{
+					Name: "clair2",
+					URL:  common_models.BuiltinClairScannerURL,
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 400, the scanner isn't reachable
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/scanners",
+				bodyJSON: &models.ScannerRegistrationReq{
+					Name: "mock",
+					URL:  server.URL + "/unreachable",
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    "/api/scanners",
+				bodyJSON: &models.ScannerRegistrationReq{
+					Name:       "mock",
+					URL:        server.URL,
+					AuthHeader: &authHeader,
+				},
+				credential: sysAdmin,
+			},
+			code: http.StatusCreated,
+		},
+		// 404
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/scanners/10000",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	regs := []*models.ScannerRegistrationRep{}
+	err := handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/scanners",
+		credential: sysAdmin,
+	}, &regs)
+	require.Nil(t, err)
+	var id int64
+	for _, reg := range regs {
+		if reg.Name == "mock" {
+			id = reg.ID
+			assert.True(t, reg.AuthHeaderSet)
+			assert.False(t, reg.IsDefault)
+		}
+	}
+	require.NotEqual(t, int64(0), id)
+	defer dao.DeleteScannerRegistration(id)
+	scannerPath := fmt.Sprintf("/api/scanners/%d", id)
+
+	metadata := &scanner.Metadata{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        scannerPath + "/metadata",
+		credential: sysAdmin,
+	}, metadata)
+	require.Nil(t, err)
+	assert.Equal(t, "Mock", metadata.Name)
+
+	// the auth header is left unchanged if it is absent
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method: http.MethodPut,
+			url:    scannerPath,
+			bodyJSON: &models.ScannerRegistrationReq{
+				Name:        "mock",
+				Description: "the mock scanner",
+				URL:         server.URL,
+			},
+			credential: sysAdmin,
+		},
+		code: http.StatusOK,
+	})
+	reg, err := dao.GetScannerRegistration(id)
+	require.Nil(t, err)
+	require.NotNil(t, reg)
+	assert.Equal(t, authHeader, reg.AuthHeader)
+	assert.Equal(t, "the mock scanner", reg.Description)
+
+	// the project picks the scanner, which can't be deleted then
+	cases = []*codeCheckingCase{
+		// 403, only the project admin can change the scanner
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/scanner",
+				bodyJSON:   &models.ProjectScannerReq{ScannerID: id},
+				credential: projGuest,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, scanner not found
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/scanner",
+				bodyJSON:   &models.ProjectScannerReq{ScannerID: 10000},
+				credential: projAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/scanner",
+				bodyJSON:   &models.ProjectScannerReq{ScannerID: id},
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 409, the scanner is used by the project
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        scannerPath,
+				credential: sysAdmin,
+			},
+			code: http.StatusConflict,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	rep := &models.ScannerRegistrationRep{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        "/api/projects/1/scanner",
+		credential: projGuest,
+	}, rep)
+	require.Nil(t, err)
+	assert.Equal(t, id, rep.ID)
+
+	cases = []*codeCheckingCase{
+		// 200, the project uses the default scanner
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        "/api/projects/1/scanner",
+				bodyJSON:   &models.ProjectScannerReq{},
+				credential: projAdmin,
+			},
+			code: http.StatusOK,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        scannerPath,
+				credential: sysAdmin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+	reg, err = dao.GetScannerRegistration(id)
+	require.Nil(t, err)
+	assert.Nil(t, reg)
+}
@@ -19,9 +19,9 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
 	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
@@ -101,9 +101,7 @@ func (s *SearchAPI) Get() {
 				p.Role = roles[0]
 			}
 
-			if p.Role == common.RoleProjectAdmin || isSysAdmin {
-				p.Togglable = true
-			}
+			p.Togglable = s.HasProjectPermission(p.ProjectID, rbac_project.ActionUpdate)
 		}
 
 		total, err := dao.GetTotalOfRepositories(&models.RepositoryQuery{
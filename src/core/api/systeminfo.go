@@ -31,6 +31,7 @@ import (
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/systeminfo"
 	"github.com/goharbor/harbor/src/core/systeminfo/imagestorage"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
 )
 
 // SystemInfoAPI handle requests for getting system info /api/systeminfo
@@ -90,6 +91,7 @@ var (
 type GeneralInfo struct {
 	WithNotary                  bool                             `json:"with_notary"`
 	WithClair                   bool                             `json:"with_clair"`
+	WithScanner                 bool                             `json:"with_scanner"`
 	WithAdmiral                 bool                             `json:"with_admiral"`
 	AdmiralEndpoint             string                           `json:"admiral_endpoint"`
 	AuthMode                    string                           `json:"auth_mode"`
@@ -173,6 +175,7 @@ func (sia *SystemInfoAPI) GetGeneralInfo() {
 		WithAdmiral:                 config.WithAdmiral(),
 		WithNotary:                  config.WithNotary(),
 		WithClair:                   config.WithClair(),
+		WithScanner:                 coreutils.ScanEnabled(),
 		AuthMode:                    utils.SafeCastString(cfg[common.AUTHMode]),
 		ProjectCreationRestrict:     utils.SafeCastString(cfg[common.ProjectCreationRestriction]),
 		SelfRegistration:            utils.SafeCastBool(cfg[common.SelfRegistration]),
@@ -326,6 +326,17 @@ func (t *TargetAPI) Delete() {
 		t.CustomAbort(http.StatusPreconditionFailed, "the target is used by policies, can not be deleted")
 	}
 
+	metas, err := dao.ListProjectMetadata(models.ProMetaProxyCacheTarget, strconv.FormatInt(id, 10))
+	if err != nil {
+		log.Errorf("failed to get proxy cache projects according target %d: %v", id, err)
+		t.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+
+	if len(metas) > 0 {
+		log.Error("the target is used by proxy cache projects, can not be deleted")
+		t.CustomAbort(http.StatusPreconditionFailed, "the target is used by proxy cache projects, can not be deleted")
+	}
+
 	if err = dao.DeleteRepTarget(id); err != nil {
 		log.Errorf("failed to delete target %d: %v", id, err)
 		t.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
@@ -20,12 +20,14 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/auth"
 	"github.com/goharbor/harbor/src/core/config"
 )
 
@@ -44,6 +46,10 @@ type passwordReq struct {
 	NewPassword string `json:"new_password"`
 }
 
+type cliSecretRep struct {
+	Secret string `json:"secret"`
+}
+
 // Prepare validates the URL and parms
 func (ua *UserAPI) Prepare() {
 	ua.BaseController.Prepare()
@@ -118,6 +124,19 @@ func (ua *UserAPI) Get() {
 		u.Password = ""
 		if ua.userID == ua.currentUserID {
 			u.HasAdminRole = ua.SecurityCtx.IsSysAdmin()
+			if ua.AuthMode == common.DBAuth || u.UserID == 1 {
+				policy, err := config.PasswordPolicy()
+				if err != nil {
+					ua.HandleInternalServerError(fmt.Sprintf("failed to get the password policy: %v", err))
+					return
+				}
+				u.PasswordExpired = policy.PasswordExpired(u.PasswordChangedTime, time.Now())
+			}
+			u.TOTPEnrollRequired, err = auth.TOTPEnrollRequired(u)
+			if err != nil {
+				ua.HandleInternalServerError(fmt.Sprintf("failed to check the second factor: %v", err))
+				return
+			}
 		}
 		ua.Data["json"] = u
 		ua.ServeJSON()
@@ -218,9 +237,14 @@ func (ua *UserAPI) Post() {
 		ua.CustomAbort(http.StatusForbidden, "")
 	}
 
+	policy, err := config.PasswordPolicy()
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the password policy: %v", err))
+		return
+	}
 	user := models.User{}
 	ua.DecodeJSONReq(&user)
-	err := validate(user)
+	err = validate(user, policy)
 	if err != nil {
 		log.Warningf("Bad request in Register: %v", err)
 		ua.RenderError(http.StatusBadRequest, "register error:"+err.Error())
@@ -309,7 +333,26 @@ func (ua *UserAPI) ChangePassword() {
 			return
 		}
 	}
-	if user.Password == utils.Encrypt(req.NewPassword, user.Salt) {
+
+	policy, err := config.PasswordPolicy()
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the password policy: %v", err))
+		return
+	}
+	if err = policy.Validate(req.NewPassword); err != nil {
+		ua.HandleBadRequest(err.Error())
+		return
+	}
+	reused, err := dao.IsPasswordReused(ua.userID, req.NewPassword, policy.HistoryCount)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to check the password history of user %d: %v", ua.userID, err))
+		return
+	}
+	if reused {
+		if policy.HistoryCount > 0 {
+			ua.HandleBadRequest(fmt.Sprintf("the new password can not be same with the old one or the %d previous ones", policy.HistoryCount))
+			return
+		}
 		ua.HandleBadRequest("the new password can not be same with the old one")
 		return
 	}
@@ -322,6 +365,36 @@ func (ua *UserAPI) ChangePassword() {
 		ua.HandleInternalServerError(fmt.Sprintf("failed to change password of user %d: %v", ua.userID, err))
 		return
 	}
+	if err = dao.PrunePasswordHistory(ua.userID, policy.HistoryCount); err != nil {
+		log.Errorf("failed to prune the password history of user %d: %v", ua.userID, err)
+	}
+
+	// the user who logged in with the expired password can do other operations after changing it
+	if changePwdOfOwn && ua.Ctx.Input.CruSession != nil {
+		if u, ok := ua.GetSession("user").(models.User); ok && u.PasswordExpired {
+			u.PasswordExpired = false
+			ua.SetSession("user", u)
+		}
+	}
+}
+
+// Unlock handles PUT to /api/users/{}/unlock, it clears the login failures of the user locked
+// by the password policy, only the system admin is allowed
+func (ua *UserAPI) Unlock() {
+	if !ua.IsAdmin {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if err := dao.ResetLoginFailures(ua.userID); err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to unlock user %d: %v", ua.userID, err))
+		return
+	}
+	user, err := dao.GetUser(models.User{UserID: ua.userID})
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get user %d: %v", ua.userID, err))
+		return
+	}
+	ua.recordAuditLog(0, models.LogResourceTypeUser, user.Username, "unlock")
 }
 
 // ToggleUserAdminRole handles PUT api/users/{}/sysadmin
@@ -339,8 +412,53 @@ func (ua *UserAPI) ToggleUserAdminRole() {
 	}
 }
 
+// GenerateCLISecret handles POST api/users/{}/cli_secret, it generates a new secret for the
+// OIDC user or the user with the second factor enabled to login docker CLI, the secret is only
+// returned in the response, it can't be generated with a personal access token
+func (ua *UserAPI) GenerateCLISecret() {
+	if !ua.SecurityCtx.IsAuthenticated() {
+		ua.HandleUnauthorized()
+		return
+	}
+	if ua.isPersonalAccessToken() {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if ua.userID != ua.currentUserID {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if ua.AuthMode != common.OIDCAuth || ua.userID == 1 {
+		ua.generateTOTPCLISecret()
+		return
+	}
+	oidcUser, err := dao.GetOIDCUserByUserID(ua.userID)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get OIDC user %d: %v", ua.userID, err))
+		return
+	}
+	if oidcUser == nil {
+		ua.HandleNotFound(fmt.Sprintf("user %d is not an OIDC user", ua.userID))
+		return
+	}
+	secret := utils.GenerateRandomString()
+	oidcUser.Salt = utils.GenerateRandomString()
+	oidcUser.Secret = utils.Encrypt(secret, oidcUser.Salt)
+	if err = dao.UpdateOIDCUser(oidcUser, "Secret", "Salt"); err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to update CLI secret of user %d: %v", ua.userID, err))
+		return
+	}
+	ua.Data["json"] = &cliSecretRep{Secret: secret}
+	ua.ServeJSON()
+}
+
 // modifiable returns whether the modify is allowed based on current auth mode and context
 func (ua *UserAPI) modifiable() bool {
+	// the profile and the password can't be changed with a personal access token, otherwise a token
+	// could take over the account by changing the email and resetting the password
+	if ua.isPersonalAccessToken() {
+		return false
+	}
 	if ua.AuthMode == common.DBAuth {
 		// When the auth mode is local DB, admin can modify anyone, non-admin can modify himself.
 		return ua.IsAdmin || ua.userID == ua.currentUserID
@@ -352,7 +470,7 @@ func (ua *UserAPI) modifiable() bool {
 }
 
 // validate only validate when user register
-func validate(user models.User) error {
+func validate(user models.User, policy *models.PasswordPolicy) error {
 
 	if isIllegalLength(user.Username, 1, 255) {
 		return fmt.Errorf("username with illegal length")
@@ -360,8 +478,8 @@ func validate(user models.User) error {
 	if isContainIllegalChar(user.Username, []string{",", "~", "#", "$", "%"}) {
 		return fmt.Errorf("username contains illegal characters")
 	}
-	if isIllegalLength(user.Password, 8, 20) {
-		return fmt.Errorf("password with illegal length")
+	if err := policy.Validate(user.Password); err != nil {
+		return err
 	}
 	return commonValidate(user)
 }
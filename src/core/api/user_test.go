@@ -336,7 +336,7 @@ func buildChangeUserPasswordURL(id int) string {
 func TestUsersUpdatePassword(t *testing.T) {
 	fmt.Println("Testing Update User Password")
 	oldPassword := "old_password"
-	newPassword := "new_password"
+	newPassword := "New_passw0rd"
 
 	user01 := models.User{
 		Username: "user01_for_testing_change_password",
@@ -435,6 +435,22 @@ func TestUsersUpdatePassword(t *testing.T) {
 			},
 			code: http.StatusForbidden,
 		},
+		// 400, the new password violates the password policy
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    buildChangeUserPasswordURL(user01.UserID),
+				bodyJSON: &passwordReq{
+					OldPassword: oldPassword,
+					NewPassword: "weak_password",
+				},
+				credential: &usrInfo{
+					Name:   user01.Username,
+					Passwd: user01.Password,
+				},
+			},
+			code: http.StatusBadRequest,
+		},
 		// 200, normal user change own password
 		{
 			request: &testingRequest{
@@ -470,7 +486,7 @@ func TestUsersUpdatePassword(t *testing.T) {
 				method: http.MethodPut,
 				url:    buildChangeUserPasswordURL(user01.UserID),
 				bodyJSON: &passwordReq{
-					NewPassword: "another_new_password",
+					NewPassword: "Another_passw0rd",
 				},
 				credential: admin,
 			},
@@ -481,6 +497,57 @@ func TestUsersUpdatePassword(t *testing.T) {
 	runCodeCheckingCases(t, cases...)
 }
 
+func TestUsersUnlock(t *testing.T) {
+	user := models.User{
+		Username: "user_for_testing_unlock",
+		Email:    "user_for_testing_unlock@test.com",
+		Password: "Harbor12345",
+	}
+	id, err := dao.Register(user)
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(id))
+
+	lockout, err := dao.IncreaseLoginFailures(user.Username, 1, 0)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.True(t, lockout.Locked)
+
+	url := fmt.Sprintf("/api/users/%d/unlock", id)
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPut,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, only the system admin can unlock the users
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				credential: nonSysAdmin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 200
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				credential: admin,
+			},
+			code: http.StatusOK,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	lockout, err = dao.GetUserLockout(user.Username)
+	require.Nil(t, err)
+	assert.Nil(t, lockout)
+}
+
 func TestUsersDelete(t *testing.T) {
 
 	fmt.Println("Testing User Delete")
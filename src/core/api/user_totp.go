@@ -0,0 +1,337 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/totp"
+	"github.com/goharbor/harbor/src/core/auth"
+)
+
+// the issuer shown in the authenticator apps
+const totpIssuer = "Harbor"
+
+type otpReq struct {
+	OTP string `json:"otp"`
+}
+
+type totpEnrollRep struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type recoveryCodesRep struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// GetTOTP handles GET api/users/{}/totp, it returns the second factor status of the user
+func (ua *UserAPI) GetTOTP() {
+	if !ua.SecurityCtx.IsAuthenticated() {
+		ua.HandleUnauthorized()
+		return
+	}
+	if ua.userID != ua.currentUserID && !ua.IsAdmin {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	t, err := auth.GetEnabledTOTP(ua.userID)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	user, err := dao.GetUser(models.User{UserID: ua.userID})
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get user %d: %v", ua.userID, err))
+		return
+	}
+	status := &models.TOTPStatus{}
+	if t != nil {
+		status.Enabled = true
+		status.RecoveryCodesLeft = len(t.RecoveryCodes)
+		status.CLISecretCreated = len(t.CLISecret) > 0
+	} else {
+		status.Required, err = auth.TOTPEnrollRequired(user)
+		if err != nil {
+			ua.HandleInternalServerError(fmt.Sprintf("failed to check the second factor of user %d: %v", ua.userID, err))
+			return
+		}
+	}
+	ua.Data["json"] = status
+	ua.ServeJSON()
+}
+
+// EnrollTOTP handles POST api/users/{}/totp, it generates a new secret for the user to add into the
+// authenticator app, the second factor takes effect after the first code is verified by EnableTOTP
+func (ua *UserAPI) EnrollTOTP() {
+	if !ua.SecurityCtx.IsAuthenticated() {
+		ua.HandleUnauthorized()
+		return
+	}
+	if ua.isPersonalAccessToken() {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if !ua.totpSupported() {
+		ua.HandleStatusPreconditionFailed("the second factor is only supported for the database users")
+		return
+	}
+	if ua.userID != ua.currentUserID {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to generate the TOTP secret: %v", err))
+		return
+	}
+	encrypted, err := auth.EncryptTOTPSecret(secret)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to encrypt the TOTP secret: %v", err))
+		return
+	}
+	if err = dao.SavePendingUserTOTP(ua.userID, encrypted); err != nil {
+		if err == dao.ErrDupRows {
+			ua.HandleConflict("the second factor is already enabled")
+			return
+		}
+		ua.HandleInternalServerError(fmt.Sprintf("failed to save the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	ua.Data["json"] = &totpEnrollRep{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, ua.SecurityCtx.GetUsername(), secret),
+	}
+	ua.ServeJSON()
+}
+
+// EnableTOTP handles PUT api/users/{}/totp, it enables the pending second factor after verifying the code,
+// the recovery codes are only returned in the response
+func (ua *UserAPI) EnableTOTP() {
+	if !ua.SecurityCtx.IsAuthenticated() {
+		ua.HandleUnauthorized()
+		return
+	}
+	if ua.isPersonalAccessToken() {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if ua.userID != ua.currentUserID {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	req := &otpReq{}
+	ua.DecodeJSONReq(req)
+	t, err := dao.GetUserTOTP(ua.userID)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	if t == nil {
+		ua.HandleNotFound("the second factor isn't enrolled")
+		return
+	}
+	if t.Enabled {
+		ua.HandleConflict("the second factor is already enabled")
+		return
+	}
+	ok, err := auth.VerifyOTP(t, req.OTP, false)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to verify the one-time password: %v", err))
+		return
+	}
+	if !ok {
+		ua.HandleBadRequest("invalid one-time password")
+		return
+	}
+	codes, err := ua.resetRecoveryCodes(t)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to generate the recovery codes: %v", err))
+		return
+	}
+	t.Enabled = true
+	if err = dao.UpdateUserTOTP(t, "Enabled", "Salt", "RecoveryCodes"); err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to enable the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	ua.setTOTPEnrollRequired(false)
+	ua.recordAuditLog(0, models.LogResourceTypeUser, ua.SecurityCtx.GetUsername(), "enable_2fa")
+	ua.Data["json"] = &recoveryCodesRep{RecoveryCodes: codes}
+	ua.ServeJSON()
+}
+
+// DisableTOTP handles DELETE api/users/{}/totp, the user must provide a code or a recovery code to disable
+// the own second factor, the system admin can disable the one of others, e.g. who lost the device
+func (ua *UserAPI) DisableTOTP() {
+	if !ua.SecurityCtx.IsAuthenticated() {
+		ua.HandleUnauthorized()
+		return
+	}
+	if ua.isPersonalAccessToken() {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if ua.userID != ua.currentUserID && !ua.IsAdmin {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	t, err := dao.GetUserTOTP(ua.userID)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	if t == nil {
+		ua.HandleNotFound(fmt.Sprintf("user %d doesn't have the second factor", ua.userID))
+		return
+	}
+	if ua.userID == ua.currentUserID && t.Enabled {
+		req := &otpReq{}
+		ua.DecodeJSONReq(req)
+		ok, err := auth.VerifyOTP(t, req.OTP, true)
+		if err != nil {
+			ua.HandleInternalServerError(fmt.Sprintf("failed to verify the one-time password: %v", err))
+			return
+		}
+		if !ok {
+			ua.HandleBadRequest("invalid one-time password")
+			return
+		}
+	}
+	if err = dao.DeleteUserTOTP(ua.userID); err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to disable the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	user, err := dao.GetUser(models.User{UserID: ua.userID})
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get user %d: %v", ua.userID, err))
+		return
+	}
+	if ua.userID == ua.currentUserID {
+		required, err := auth.TOTPEnrollRequired(user)
+		if err != nil {
+			ua.HandleInternalServerError(fmt.Sprintf("failed to check the second factor of user %d: %v", ua.userID, err))
+			return
+		}
+		ua.setTOTPEnrollRequired(required)
+	}
+	ua.recordAuditLog(0, models.LogResourceTypeUser, user.Username, "disable_2fa")
+}
+
+// RegenerateRecoveryCodes handles POST api/users/{}/totp/recovery_codes, it replaces the recovery codes
+// of the user after verifying the code, the new ones are only returned in the response
+func (ua *UserAPI) RegenerateRecoveryCodes() {
+	if !ua.SecurityCtx.IsAuthenticated() {
+		ua.HandleUnauthorized()
+		return
+	}
+	if ua.isPersonalAccessToken() {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	if ua.userID != ua.currentUserID {
+		ua.HandleForbidden(ua.SecurityCtx.GetUsername())
+		return
+	}
+	req := &otpReq{}
+	ua.DecodeJSONReq(req)
+	t, err := auth.GetEnabledTOTP(ua.userID)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	if t == nil {
+		ua.HandleStatusPreconditionFailed("the second factor isn't enabled")
+		return
+	}
+	ok, err := auth.VerifyOTP(t, req.OTP, false)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to verify the one-time password: %v", err))
+		return
+	}
+	if !ok {
+		ua.HandleBadRequest("invalid one-time password")
+		return
+	}
+	codes, err := ua.resetRecoveryCodes(t)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to generate the recovery codes: %v", err))
+		return
+	}
+	if err = dao.UpdateUserTOTP(t, "Salt", "RecoveryCodes"); err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to update the recovery codes of user %d: %v", ua.userID, err))
+		return
+	}
+	ua.Data["json"] = &recoveryCodesRep{RecoveryCodes: codes}
+	ua.ServeJSON()
+}
+
+// generateTOTPCLISecret generates the CLI secret for the user with the second factor enabled, as the
+// password can't be used for docker CLI after enabling it
+func (ua *UserAPI) generateTOTPCLISecret() {
+	t, err := auth.GetEnabledTOTP(ua.userID)
+	if err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to get the second factor of user %d: %v", ua.userID, err))
+		return
+	}
+	if t == nil {
+		ua.HandleStatusPreconditionFailed("the auth mode is not OIDC and the second factor isn't enabled")
+		return
+	}
+	secret := utils.GenerateRandomString()
+	t.CLISalt = utils.GenerateRandomString()
+	t.CLISecret = utils.Encrypt(secret, t.CLISalt)
+	if err = dao.UpdateUserTOTP(t, "CLISecret", "CLISalt"); err != nil {
+		ua.HandleInternalServerError(fmt.Sprintf("failed to update CLI secret of user %d: %v", ua.userID, err))
+		return
+	}
+	ua.Data["json"] = &cliSecretRep{Secret: secret}
+	ua.ServeJSON()
+}
+
+// totpSupported returns whether the user can have the second factor, which is stored in local DB
+func (ua *UserAPI) totpSupported() bool {
+	return ua.AuthMode == common.DBAuth || ua.userID == 1
+}
+
+// resetRecoveryCodes replaces the recovery codes of the second factor with new ones, which are returned
+// in plain text while the salted hashes are kept
+func (ua *UserAPI) resetRecoveryCodes(t *models.UserTOTP) ([]string, error) {
+	codes, err := totp.GenerateRecoveryCodes(models.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	t.Salt = utils.GenerateRandomString()
+	t.RecoveryCodes = make([]string, len(codes))
+	for i, code := range codes {
+		t.RecoveryCodes[i] = utils.Encrypt(code, t.Salt)
+	}
+	return codes, nil
+}
+
+// setTOTPEnrollRequired updates the flag in the session of the current user, which restricts the
+// operations until the second factor is enabled
+func (ua *UserAPI) setTOTPEnrollRequired(required bool) {
+	if ua.Ctx.Input.CruSession == nil {
+		return
+	}
+	if u, ok := ua.GetSession("user").(models.User); ok && u.TOTPEnrollRequired != required {
+		u.TOTPEnrollRequired = required
+		ua.SetSession("user", u)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTOTP(t *testing.T) {
+	user := models.User{
+		Username: "user_for_testing_totp",
+		Email:    "user_for_testing_totp@test.com",
+		Password: "Harbor12345",
+	}
+	id, err := dao.Register(user)
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(id))
+	credential := &usrInfo{Name: user.Username, Passwd: user.Password}
+	url := fmt.Sprintf("/api/users/%d/totp", id)
+
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    url,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 403, the second factor can only be enrolled by the user self
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: admin,
+			},
+			code: http.StatusForbidden,
+		},
+		// 404, not enrolled
+		{
+			request: &testingRequest{
+				method:     http.MethodPut,
+				url:        url,
+				bodyJSON:   &otpReq{OTP: "123456"},
+				credential: credential,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	enrollment := &totpEnrollRep{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodPost,
+		url:        url,
+		credential: credential,
+	}, enrollment))
+	require.NotEmpty(t, enrollment.Secret)
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+
+	// 400, invalid code
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodPut,
+			url:        url,
+			bodyJSON:   &otpReq{OTP: "abcdef"},
+			credential: credential,
+		},
+		code: http.StatusBadRequest,
+	})
+
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	require.Nil(t, err)
+	recovery := &recoveryCodesRep{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodPut,
+		url:        url,
+		bodyJSON:   &otpReq{OTP: code},
+		credential: credential,
+	}, recovery))
+	require.Len(t, recovery.RecoveryCodes, models.RecoveryCodeCount)
+
+	// the password can't be used for basic auth after the second factor is enabled
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodGet,
+			url:        url,
+			credential: credential,
+		},
+		code: http.StatusUnauthorized,
+	})
+
+	// the CLI secret is generated in UI with the session, set it directly here
+	totpUser, err := dao.GetUserTOTP(int(id))
+	require.Nil(t, err)
+	require.NotNil(t, totpUser)
+	totpUser.CLISalt = utils.GenerateRandomString()
+	totpUser.CLISecret = utils.Encrypt("cli-secret", totpUser.CLISalt)
+	require.Nil(t, dao.UpdateUserTOTP(totpUser, "CLISecret", "CLISalt"))
+	credential = &usrInfo{Name: user.Username, Passwd: "cli-secret"}
+
+	status := &models.TOTPStatus{}
+	require.Nil(t, handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        url,
+		credential: credential,
+	}, status))
+	assert.True(t, status.Enabled)
+	assert.True(t, status.CLISecretCreated)
+	assert.Equal(t, models.RecoveryCodeCount, status.RecoveryCodesLeft)
+
+	cases = []*codeCheckingCase{
+		// 409, already enabled
+		{
+			request: &testingRequest{
+				method:     http.MethodPost,
+				url:        url,
+				credential: credential,
+			},
+			code: http.StatusConflict,
+		},
+		// 400, the user must provide a code to disable the own second factor
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        url,
+				bodyJSON:   &otpReq{OTP: "abcde-12345"},
+				credential: credential,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 200, with a recovery code
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        url,
+				bodyJSON:   &otpReq{OTP: recovery.RecoveryCodes[0]},
+				credential: credential,
+			},
+			code: http.StatusOK,
+		},
+		// 404, disabled
+		{
+			request: &testingRequest{
+				method:     http.MethodDelete,
+				url:        url,
+				credential: admin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	totpUser, err = dao.GetUserTOTP(int(id))
+	require.Nil(t, err)
+	assert.Nil(t, totpUser)
+}
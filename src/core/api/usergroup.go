@@ -26,12 +26,14 @@ import (
 	"github.com/goharbor/harbor/src/common/utils/ldap"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/auth"
+	"github.com/goharbor/harbor/src/core/config"
 )
 
 // UserGroupAPI ...
 type UserGroupAPI struct {
 	BaseController
-	id int
+	id       int
+	authMode string
 }
 
 const (
@@ -55,6 +57,11 @@ func (uga *UserGroupAPI) Prepare() {
 		return
 	}
 	uga.id = int(ugid)
+	uga.authMode, err = config.AuthMode()
+	if err != nil {
+		uga.HandleInternalServerError(fmt.Sprintf("failed to get auth mode: %v", err))
+		return
+	}
 	// Common user can create/update, only harbor admin can delete user group.
 	if uga.Ctx.Input.IsDelete() && !uga.SecurityCtx.IsSysAdmin() {
 		uga.HandleForbidden(uga.SecurityCtx.GetUsername())
@@ -68,7 +75,7 @@ func (uga *UserGroupAPI) Get() {
 	uga.Data["json"] = make([]models.UserGroup, 0)
 	if ID == 0 {
 		// user group id not set, return all user group
-		query := models.UserGroup{GroupType: common.LdapGroupType} // Current query LDAP group only
+		query := models.UserGroup{GroupType: uga.groupType()} // Current query the groups of current auth mode only
 		userGroupList, err := group.QueryUserGroup(query)
 		if err != nil {
 			uga.HandleInternalServerError(fmt.Sprintf("Failed to query database for user group list, error: %v", err))
@@ -98,6 +105,10 @@ func (uga *UserGroupAPI) Post() {
 	userGroup := models.UserGroup{}
 	uga.DecodeJSONReq(&userGroup)
 	userGroup.ID = 0
+	if uga.authMode == common.OIDCAuth {
+		uga.addOIDCGroup(userGroup)
+		return
+	}
 	userGroup.GroupType = common.LdapGroupType
 	userGroup.LdapGroupDN = strings.TrimSpace(userGroup.LdapGroupDN)
 	userGroup.GroupName = strings.TrimSpace(userGroup.GroupName)
@@ -138,6 +149,42 @@ func (uga *UserGroupAPI) Post() {
 	uga.Redirect(http.StatusCreated, strconv.FormatInt(int64(groupID), 10))
 }
 
+// addOIDCGroup creates the OIDC group, which is identified by its name
+func (uga *UserGroupAPI) addOIDCGroup(userGroup models.UserGroup) {
+	userGroup.GroupType = common.OIDCGroupType
+	userGroup.LdapGroupDN = ""
+	userGroup.GroupName = strings.TrimSpace(userGroup.GroupName)
+	if len(userGroup.GroupName) == 0 {
+		uga.HandleBadRequest(userNameEmptyMsg)
+		return
+	}
+	// the name is matched with "like" in the query
+	result, err := group.QueryUserGroup(models.UserGroup{GroupType: userGroup.GroupType, GroupName: userGroup.GroupName})
+	if err != nil {
+		uga.HandleInternalServerError(fmt.Sprintf("Error occurred in add user group, error: %v", err))
+		return
+	}
+	for _, g := range result {
+		if g.GroupName == userGroup.GroupName {
+			uga.HandleConflict("Error occurred in add user group, duplicate user group exist.")
+			return
+		}
+	}
+	groupID, err := group.AddUserGroup(userGroup)
+	if err != nil {
+		uga.HandleInternalServerError(fmt.Sprintf("Error occurred in add user group, error: %v", err))
+		return
+	}
+	uga.Redirect(http.StatusCreated, strconv.FormatInt(int64(groupID), 10))
+}
+
+func (uga *UserGroupAPI) groupType() int {
+	if uga.authMode == common.OIDCAuth {
+		return common.OIDCGroupType
+	}
+	return common.LdapGroupType
+}
+
 // Put ... Only support update name
 func (uga *UserGroupAPI) Put() {
 	userGroup := models.UserGroup{}
@@ -148,6 +195,11 @@ func (uga *UserGroupAPI) Put() {
 		uga.HandleBadRequest(userNameEmptyMsg)
 		return
 	}
+	// OIDC groups are identified by the names in the group claim
+	if uga.authMode == common.OIDCAuth {
+		uga.HandleBadRequest("The name of OIDC group can not be changed")
+		return
+	}
 	userGroup.GroupType = common.LdapGroupType
 	log.Debugf("Updated user group %v", userGroup)
 	err := group.UpdateUserGroupName(ID, userGroup.GroupName)
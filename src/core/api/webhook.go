@@ -0,0 +1,214 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	rbac_project "github.com/goharbor/harbor/src/common/rbac/project"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// WebhookAPI handles the requests on the webhook policies of a project and their delivery history
+type WebhookAPI struct {
+	BaseController
+	project *common_models.Project
+	policy  *common_models.WebhookPolicy
+}
+
+// Prepare validates the project and the permission, and loads the webhook policy if its ID is in the path.
+// Only project admin is allowed as the policies carry the credentials of the addresses.
+func (w *WebhookAPI) Prepare() {
+	w.BaseController.Prepare()
+
+	if !w.SecurityCtx.IsAuthenticated() {
+		w.HandleUnauthorized()
+		return
+	}
+
+	pid, err := w.GetInt64FromPath(":pid")
+	if err != nil || pid <= 0 {
+		w.HandleBadRequest(fmt.Sprintf("invalid project ID: %s", w.GetStringFromPath(":pid")))
+		return
+	}
+	project, err := w.ProjectMgr.Get(pid)
+	if err != nil {
+		w.ParseAndHandleError(fmt.Sprintf("failed to get project %d", pid), err)
+		return
+	}
+	if project == nil {
+		w.HandleNotFound(fmt.Sprintf("project %d not found", pid))
+		return
+	}
+	w.project = project
+
+	if !w.RequireProjectAccess(pid, rbac_project.ActionOfMethod(w.Ctx.Request.Method), rbac_project.ResourceWebhook) {
+		return
+	}
+
+	if len(w.GetStringFromPath(":id")) == 0 {
+		return
+	}
+	id, err := w.GetInt64FromPath(":id")
+	if err != nil || id <= 0 {
+		w.HandleBadRequest(fmt.Sprintf("invalid webhook policy ID: %s", w.GetStringFromPath(":id")))
+		return
+	}
+	policy, err := dao.GetWebhookPolicy(id)
+	if err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to get the webhook policy %d: %v", id, err))
+		return
+	}
+	if policy == nil || policy.ProjectID != pid {
+		w.HandleNotFound(fmt.Sprintf("webhook policy %d not found", id))
+		return
+	}
+	w.policy = policy
+}
+
+// List returns the webhook policies of the project
+func (w *WebhookAPI) List() {
+	policies, err := dao.GetWebhookPolicies(w.project.ProjectID)
+	if err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to get the webhook policies of project %d: %v", w.project.ProjectID, err))
+		return
+	}
+
+	reps := []*models.WebhookPolicyRep{}
+	for _, policy := range policies {
+		reps = append(reps, models.ConvertToWebhookPolicyRep(policy))
+	}
+	w.Data["json"] = reps
+	w.ServeJSON()
+}
+
+// Post creates a webhook policy for the project
+func (w *WebhookAPI) Post() {
+	req := &models.WebhookPolicyReq{}
+	w.DecodeJSONReqAndValidate(req)
+	if err := encryptWebhookCredentials(req); err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to encrypt the credentials of the webhook policy: %v", err))
+		return
+	}
+
+	policy := &common_models.WebhookPolicy{
+		ProjectID: w.project.ProjectID,
+		Enabled:   true,
+		Creator:   w.SecurityCtx.GetUsername(),
+	}
+	req.ApplyTo(policy)
+	id, err := dao.AddWebhookPolicy(policy)
+	if err != nil {
+		if err == dao.ErrDupRows {
+			w.HandleConflict(fmt.Sprintf("webhook policy %s already exists", policy.Name))
+			return
+		}
+		w.HandleInternalServerError(fmt.Sprintf("failed to add the webhook policy: %v", err))
+		return
+	}
+	w.recordAuditLog(w.project.ProjectID, common_models.LogResourceTypeWebhookPolicy, policy.Name, "create")
+
+	w.Redirect(http.StatusCreated, strconv.FormatInt(id, 10))
+}
+
+// Get returns the webhook policy
+func (w *WebhookAPI) Get() {
+	w.Data["json"] = models.ConvertToWebhookPolicyRep(w.policy)
+	w.ServeJSON()
+}
+
+// Put updates the webhook policy
+func (w *WebhookAPI) Put() {
+	req := &models.WebhookPolicyReq{}
+	w.DecodeJSONReq(req)
+	if err := encryptWebhookCredentials(req); err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to encrypt the credentials of the webhook policy: %v", err))
+		return
+	}
+
+	req.ApplyTo(w.policy)
+	if err := w.policy.Valid(); err != nil {
+		w.HandleBadRequest(err.Error())
+		return
+	}
+	if err := dao.UpdateWebhookPolicy(w.policy); err != nil {
+		if err == dao.ErrDupRows {
+			w.HandleConflict(fmt.Sprintf("webhook policy %s already exists", w.policy.Name))
+			return
+		}
+		w.HandleInternalServerError(fmt.Sprintf("failed to update the webhook policy %d: %v", w.policy.ID, err))
+		return
+	}
+	w.recordAuditLog(w.project.ProjectID, common_models.LogResourceTypeWebhookPolicy, w.policy.Name, "update")
+}
+
+// Delete deletes the webhook policy with its delivery history
+func (w *WebhookAPI) Delete() {
+	if err := dao.DeleteWebhookPolicy(w.policy.ID); err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to delete the webhook policy %d: %v", w.policy.ID, err))
+		return
+	}
+	w.recordAuditLog(w.project.ProjectID, common_models.LogResourceTypeWebhookPolicy, w.policy.Name, "delete")
+}
+
+// ListExecutions returns the delivery history of the webhook policy, the latest first.
+// The history can be filtered by the event type and status.
+func (w *WebhookAPI) ListExecutions() {
+	query := &common_models.WebhookExecutionQuery{
+		PolicyID:  w.policy.ID,
+		EventType: w.GetString("event_type"),
+		Status:    w.GetString("status"),
+	}
+	query.Page, query.Size = w.GetPaginationParams()
+
+	total, err := dao.CountWebhookExecutions(query)
+	if err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to count the webhook executions: %v", err))
+		return
+	}
+	executions, err := dao.ListWebhookExecutions(query)
+	if err != nil {
+		w.HandleInternalServerError(fmt.Sprintf("failed to list the webhook executions: %v", err))
+		return
+	}
+
+	w.SetPaginationHeader(total, query.Page, query.Size)
+	w.Data["json"] = executions
+	w.ServeJSON()
+}
+
+// encryptWebhookCredentials encrypts the auth header and secret in the request with the secret key,
+// they're stored encrypted and only decrypted by the webhook job when delivering the events
+func encryptWebhookCredentials(req *models.WebhookPolicyReq) error {
+	key, err := config.SecretKey()
+	if err != nil {
+		return err
+	}
+	for _, credential := range []*string{req.AuthHeader, req.Secret} {
+		if credential == nil || len(*credential) == 0 {
+			continue
+		}
+		if *credential, err = utils.ReversibleEncrypt(*credential, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	common_models "github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/core/api/models"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var webhookPath = "/api/projects/1/webhook/policies"
+
+func TestWebhookAPI(t *testing.T) {
+	authHeader := "Bearer token"
+	cases := []*codeCheckingCase{
+		// 401
+		{
+			request: &testingRequest{
+				method: http.MethodGet,
+				url:    webhookPath,
+			},
+			code: http.StatusUnauthorized,
+		},
+		// 404, project not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        "/api/projects/10000/webhook/policies",
+				credential: sysAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+		// 403, only project admin is allowed
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        webhookPath,
+				credential: projDeveloper,
+			},
+			code: http.StatusForbidden,
+		},
+		// 400, no event type
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    webhookPath,
+				bodyJSON: &models.WebhookPolicyReq{
+					Name:    "ci",
+					Address: "http://ci.example.com/hook",
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusBadRequest,
+		},
+		// 201
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    webhookPath,
+				bodyJSON: &models.WebhookPolicyReq{
+					Name:       "ci",
+					Address:    "http://ci.example.com/hook",
+					AuthHeader: &authHeader,
+					EventTypes: []string{common_models.WebhookEventPushImage},
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusCreated,
+		},
+		// 409
+		{
+			request: &testingRequest{
+				method: http.MethodPost,
+				url:    webhookPath,
+				bodyJSON: &models.WebhookPolicyReq{
+					Name:       "ci",
+					Address:    "http://ci.example.com/hook2",
+					EventTypes: []string{common_models.WebhookEventPullImage},
+				},
+				credential: projAdmin,
+			},
+			code: http.StatusConflict,
+		},
+		// 404, policy not found
+		{
+			request: &testingRequest{
+				method:     http.MethodGet,
+				url:        webhookPath + "/10000",
+				credential: projAdmin,
+			},
+			code: http.StatusNotFound,
+		},
+	}
+	runCodeCheckingCases(t, cases...)
+
+	policies, err := dao.GetWebhookPolicies(1)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(policies))
+	id := policies[0].ID
+	defer dao.DeleteWebhookPolicy(id)
+	policyPath := fmt.Sprintf("%s/%d", webhookPath, id)
+
+	// the auth header is never returned
+	rep := &models.WebhookPolicyRep{}
+	err = handleAndParse(&testingRequest{
+		method:     http.MethodGet,
+		url:        policyPath,
+		credential: projAdmin,
+	}, rep)
+	require.Nil(t, err)
+	assert.Equal(t, "ci", rep.Name)
+	assert.True(t, rep.Enabled)
+	assert.True(t, rep.AuthHeaderSet)
+	assert.False(t, rep.SecretSet)
+
+	// the auth header is left unchanged if it is absent
+	disabled := false
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method: http.MethodPut,
+			url:    policyPath,
+			bodyJSON: &models.WebhookPolicyReq{
+				Name:       "ci",
+				Address:    "https://ci.example.com/hook",
+				EventTypes: []string{common_models.WebhookEventPushImage, common_models.WebhookEventScanningFailed},
+				Enabled:    &disabled,
+			},
+			credential: projAdmin,
+		},
+		code: http.StatusOK,
+	})
+	policy, err := dao.GetWebhookPolicy(id)
+	require.Nil(t, err)
+	require.NotNil(t, policy)
+	// the auth header is stored encrypted
+	assert.NotEqual(t, authHeader, policy.AuthHeader)
+	key, err := config.SecretKey()
+	require.Nil(t, err)
+	decrypted, err := utils.ReversibleDecrypt(policy.AuthHeader, key)
+	require.Nil(t, err)
+	assert.Equal(t, authHeader, decrypted)
+	assert.Equal(t, "https://ci.example.com/hook", policy.Address)
+	assert.False(t, policy.Enabled)
+	assert.Equal(t, 2, len(policy.EventTypes))
+
+	_, err = dao.AddWebhookExecution(&common_models.WebhookExecution{
+		PolicyID:  id,
+		EventType: common_models.WebhookEventPushImage,
+		Status:    common_models.JobFinished,
+	})
+	require.Nil(t, err)
+	executions := []*common_models.WebhookExecution{}
+	err = handleAndParse(&testingRequest{
+		method: http.MethodGet,
+		url:    policyPath + "/executions",
+		queryStruct: struct {
+			Status string `url:"status"`
+		}{
+			Status: common_models.JobFinished,
+		},
+		credential: projAdmin,
+	}, &executions)
+	require.Nil(t, err)
+	assert.Equal(t, 1, len(executions))
+
+	runCodeCheckingCases(t, &codeCheckingCase{
+		request: &testingRequest{
+			method:     http.MethodDelete,
+			url:        policyPath,
+			credential: projAdmin,
+		},
+		code: http.StatusOK,
+	})
+	policy, err = dao.GetWebhookPolicy(id)
+	require.Nil(t, err)
+	assert.Nil(t, policy)
+}
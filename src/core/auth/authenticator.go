@@ -76,6 +76,9 @@ type AuthenticateHelper interface {
 	SearchGroup(groupDN string) (*models.UserGroup, error)
 	// Update user information after authenticate, such as OnBoard or sync info etc
 	PostAuthenticate(u *models.User) error
+	// Refresh the information of the user authenticated without the password, e.g. by the personal access
+	// token, such as the groups. ErrAuth is returned if the user is no longer valid in the account repository
+	RefreshUser(u *models.User) error
 }
 
 // DefaultAuthenticateHelper - default AuthenticateHelper implementation
@@ -104,6 +107,11 @@ func (d *DefaultAuthenticateHelper) PostAuthenticate(u *models.User) error {
 	return nil
 }
 
+// RefreshUser - Refresh the information of the user authenticated without the password
+func (d *DefaultAuthenticateHelper) RefreshUser(u *models.User) error {
+	return nil
+}
+
 // OnBoardGroup - OnBoardGroup, it will set the ID of the user group, if altGroupName is not empty, take the altGroupName as groupName in harbor DB.
 func (d *DefaultAuthenticateHelper) OnBoardGroup(u *models.UserGroup, altGroupName string) error {
 	return errors.New("Not supported")
@@ -159,6 +167,23 @@ func Login(m models.AuthModel) (*models.User, error) {
 	return user, err
 }
 
+// Locked returns whether the user whose username or email is the principal is locked by the password policy
+// due to too many login failures, the credentials not verified by Login, e.g. the personal access tokens, must check it
+func Locked(principal string) (bool, error) {
+	policy, err := config.PasswordPolicy()
+	if err != nil {
+		return false, err
+	}
+	if policy.MaxLoginFailures <= 0 {
+		return false, nil
+	}
+	lockout, err := dao.GetUserLockout(principal)
+	if err != nil {
+		return false, err
+	}
+	return policy.Locked(lockout, time.Now()), nil
+}
+
 func getHelper() (AuthenticateHelper, error) {
 	authMode, err := config.AuthMode()
 	if err != nil {
@@ -171,6 +196,19 @@ func getHelper() (AuthenticateHelper, error) {
 	return AuthenticateHelper, nil
 }
 
+// RefreshUser refreshes the information of the user authenticated without the password, e.g. by the
+// personal access token, with the helper of the current auth mode, the superuser is always a database user.
+func RefreshUser(u *models.User) error {
+	if u.UserID == 1 {
+		return nil
+	}
+	helper, err := getHelper()
+	if err != nil {
+		return err
+	}
+	return helper.RefreshUser(u)
+}
+
 // OnBoardUser will check if a user exists in user table, if not insert the user and
 // put the id in the pointer of user model, if it does exist, return the user's profile.
 func OnBoardUser(user *models.User) error {
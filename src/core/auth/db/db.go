@@ -15,9 +15,13 @@
 package db
 
 import (
+	"time"
+
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/auth"
+	"github.com/goharbor/harbor/src/core/config"
 )
 
 // Auth implements Authenticator interface to authenticate user against DB.
@@ -25,15 +29,57 @@ type Auth struct {
 	auth.DefaultAuthenticateHelper
 }
 
-// Authenticate calls dao to authenticate user.
+// Authenticate calls dao to authenticate user, the login failures are persisted to lock the user
+// according to the password policy, and the user is marked if the password is expired.
 func (d *Auth) Authenticate(m models.AuthModel) (*models.User, error) {
+	policy, err := config.PasswordPolicy()
+	if err != nil {
+		return nil, err
+	}
+	var lockout *models.UserLockout
+	if policy.MaxLoginFailures > 0 {
+		lockout, err = dao.GetUserLockout(m.Principal)
+		if err != nil {
+			return nil, err
+		}
+		if policy.Locked(lockout, time.Now()) {
+			return nil, auth.NewErrAuth("the user is locked due to too many login failures")
+		}
+	}
+
 	u, err := dao.LoginByDb(m)
 	if err != nil {
 		return nil, err
 	}
 	if u == nil {
+		if policy.MaxLoginFailures > 0 {
+			// nothing recorded to be discarded if the lockout is nil
+			duration := policy.LockoutDuration
+			if lockout != nil {
+				duration = policy.LockoutDurationOf(lockout.UserID)
+			}
+			l, err := dao.IncreaseLoginFailures(m.Principal, policy.MaxLoginFailures, duration)
+			if err != nil {
+				log.Errorf("failed to record the login failure of %s: %v", m.Principal, err)
+			} else if l != nil && l.Locked {
+				log.Warningf("user %d is locked after %d login failures", l.UserID, l.Failures)
+			}
+		}
 		return nil, auth.NewErrAuth("Invalid credentials")
 	}
+	if lockout != nil {
+		// the failures of the users with the second factor are reset after the code is verified
+		t, err := auth.GetEnabledTOTP(u.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			if err = dao.ResetLoginFailures(u.UserID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	u.PasswordExpired = policy.PasswordExpired(u.PasswordChangedTime, time.Now())
 	return u, nil
 }
 
@@ -79,7 +79,6 @@ func (l *Auth) Authenticate(m models.AuthModel) (*models.User, error) {
 	u.Username = ldapUsers[0].Username
 	u.Email = strings.TrimSpace(ldapUsers[0].Email)
 	u.Realname = ldapUsers[0].Realname
-	userGroups := make([]*models.UserGroup, 0)
 
 	dn := ldapUsers[0].DN
 	if err = ldapSession.Bind(dn, m.Password); err != nil {
@@ -88,11 +87,49 @@ func (l *Auth) Authenticate(m models.AuthModel) (*models.User, error) {
 	}
 
 	// Retrieve ldap related info in login to avoid too many traffic with LDAP server.
+	attachGroups(&u, ldapUsers[0].GroupDNList)
+
+	return &u, nil
+}
+
+// RefreshUser checks the user still exists in LDAP and attaches the groups of it, as the user
+// authenticated without the password doesn't go through Authenticate
+func (l *Auth) RefreshUser(u *models.User) error {
+	ldapSession, err := ldapUtils.LoadSystemLdapConfig()
+	if err != nil {
+		return fmt.Errorf("can not load system ldap config: %v", err)
+	}
+	if err = ldapSession.Open(); err != nil {
+		log.Warningf("ldap connection fail: %v", err)
+		return err
+	}
+	defer ldapSession.Close()
+
+	ldapUsers, err := ldapSession.SearchUser(u.Username)
+	if err != nil {
+		log.Warningf("ldap search fail: %v", err)
+		return err
+	}
+	if len(ldapUsers) != 1 {
+		return auth.NewErrAuth(fmt.Sprintf("found %d entries for user %s", len(ldapUsers), u.Username))
+	}
+	attachGroups(u, ldapUsers[0].GroupDNList)
+	return nil
+}
+
+// attachGroups attaches the user groups onboarded in Harbor by the group DNs of the user,
+// and grants the admin role if the user is a member of the LDAP group admin DN
+func attachGroups(u *models.User, groupDNList []string) {
+	userGroups := make([]*models.UserGroup, 0)
 	// Get group admin dn
 	groupCfg, err := config.LDAPGroupConf()
+	if err != nil {
+		log.Warningf("failed to get the LDAP group configurations: %v", err)
+		groupCfg = &models.LdapGroupConf{}
+	}
 	groupAdminDN := utils.TrimLower(groupCfg.LdapGroupAdminDN)
 	// Attach user group
-	for _, groupDN := range ldapUsers[0].GroupDNList {
+	for _, groupDN := range groupDNList {
 
 		groupDN = utils.TrimLower(groupDN)
 		if len(groupAdminDN) > 0 && groupAdminDN == groupDN {
@@ -113,8 +150,6 @@ func (l *Auth) Authenticate(m models.AuthModel) (*models.User, error) {
 		userGroups = append(userGroups, userGroupList[0])
 	}
 	u.GroupList = userGroups
-
-	return &u, nil
 }
 
 // OnBoardUser will check if a user exists in user table, if not insert the user and
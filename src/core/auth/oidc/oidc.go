@@ -0,0 +1,273 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/dao/group"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	oidc_util "github.com/goharbor/harbor/src/common/utils/oidc"
+	"github.com/goharbor/harbor/src/core/auth"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// ErrUsernameConflict is returned when the username in the ID token is already used by another user
+var ErrUsernameConflict = errors.New("the username is already used by another user")
+
+var (
+	providerLock sync.Mutex
+	provider     *oidc_util.Provider
+)
+
+// Auth is the implementation of AuthenticateHelper for OIDC. The users login Harbor UI
+// through the authorization code flow of the provider, which is handled by the controllers,
+// the helper authenticates the requests of docker CLI with the CLI secret of the user.
+type Auth struct {
+	auth.DefaultAuthenticateHelper
+}
+
+// Authenticate checks the CLI secret of the OIDC user
+func (a *Auth) Authenticate(m models.AuthModel) (*models.User, error) {
+	user, err := dao.GetUser(models.User{Username: m.Principal})
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, auth.NewErrAuth(fmt.Sprintf("user %s not found", m.Principal))
+	}
+	oidcUser, err := dao.GetOIDCUserByUserID(user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if oidcUser == nil || len(oidcUser.Secret) == 0 {
+		return nil, auth.NewErrAuth(fmt.Sprintf("no CLI secret is generated for user %s", m.Principal))
+	}
+	if utils.Encrypt(m.Password, oidcUser.Salt) != oidcUser.Secret {
+		return nil, auth.NewErrAuth("invalid CLI secret")
+	}
+	if err = populateGroups(user, oidcUser.Groups); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RefreshUser attaches the groups of the OIDC user from the latest ID token
+func (a *Auth) RefreshUser(user *models.User) error {
+	oidcUser, err := dao.GetOIDCUserByUserID(user.UserID)
+	if err != nil {
+		return err
+	}
+	if oidcUser == nil {
+		return nil
+	}
+	return populateGroups(user, oidcUser.Groups)
+}
+
+// OnBoardUser inserts the user into the user table if it doesn't exist, the email and real name
+// are filled with placeholders if the provider doesn't return them.
+func (a *Auth) OnBoardUser(user *models.User) error {
+	user.Username = strings.TrimSpace(user.Username)
+	if len(user.Username) == 0 {
+		return fmt.Errorf("The Username is empty")
+	}
+	if len(user.Password) == 0 {
+		user.Password = utils.GenerateRandomString()
+	}
+	if len(user.Realname) == 0 {
+		user.Realname = user.Username
+	}
+	if len(user.Email) == 0 {
+		user.Email = user.Username + "@oidc.placeholder"
+	}
+	user.Comment = "From OIDC"
+	return dao.OnBoardUser(user)
+}
+
+// SearchUser searches the user in Harbor DB, as the provider can not be queried for users
+func (a *Auth) SearchUser(username string) (*models.User, error) {
+	return dao.GetUser(models.User{Username: username})
+}
+
+// OnBoardGroup creates the OIDC group in Harbor DB if it doesn't exist, the group is identified by its name
+func (a *Auth) OnBoardGroup(g *models.UserGroup, altGroupName string) error {
+	if len(altGroupName) > 0 {
+		g.GroupName = altGroupName
+	}
+	g.GroupName = strings.TrimSpace(g.GroupName)
+	if len(g.GroupName) == 0 {
+		return fmt.Errorf("The group name is empty")
+	}
+	g.GroupType = common.OIDCGroupType
+	g.LdapGroupDN = ""
+	return group.OnBoardUserGroup(g, "GroupName", "GroupType")
+}
+
+// SearchGroup returns the OIDC group with the name, the groups of the provider can not be searched,
+// so any name is accepted.
+func (a *Auth) SearchGroup(groupKey string) (*models.UserGroup, error) {
+	if len(strings.TrimSpace(groupKey)) == 0 {
+		return nil, nil
+	}
+	return &models.UserGroup{
+		GroupName: strings.TrimSpace(groupKey),
+		GroupType: common.OIDCGroupType,
+	}, nil
+}
+
+// GetProvider returns the provider built from the current OIDC settings, the provider
+// is cached and rebuilt when the settings change
+func GetProvider() (*oidc_util.Provider, error) {
+	setting, err := config.OIDCSetting()
+	if err != nil {
+		return nil, err
+	}
+	if len(setting.Endpoint) == 0 {
+		return nil, errors.New("the endpoint of OIDC provider is not configured")
+	}
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	if provider == nil || !reflect.DeepEqual(provider.Setting(), *setting) {
+		provider = oidc_util.NewProvider(setting)
+	}
+	return provider, nil
+}
+
+// UserFromClaims returns the Harbor user mapped from the claims of a verified ID token.
+// The user is onboarded on the first login and identified by the subject and issuer afterwards,
+// the groups in the claims are onboarded as OIDC groups and the members of the admin group
+// are granted the system admin role.
+func UserFromClaims(claims *oidc_util.Claims) (*models.User, error) {
+	oidcUser, err := dao.GetOIDCUserBySubIss(claims.SubIss())
+	if err != nil {
+		return nil, err
+	}
+	var user *models.User
+	if oidcUser != nil {
+		user, err = dao.GetUser(models.User{UserID: oidcUser.UserID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if user == nil {
+		// the first login, or the user has been deleted from Harbor
+		user, err = onboard(claims)
+		if err != nil {
+			return nil, err
+		}
+		if oidcUser == nil {
+			oidcUser = &models.OIDCUser{
+				UserID: user.UserID,
+				SubIss: claims.SubIss(),
+				Groups: claims.Groups,
+			}
+			if _, err = dao.AddOIDCUser(oidcUser); err != nil {
+				return nil, err
+			}
+		} else {
+			oidcUser.UserID = user.UserID
+			oidcUser.Secret = ""
+			oidcUser.Salt = ""
+			oidcUser.Groups = claims.Groups
+			if err = dao.UpdateOIDCUser(oidcUser, "UserID", "Secret", "Salt", "Groups"); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		oidcUser.Groups = claims.Groups
+		if err = dao.UpdateOIDCUser(oidcUser, "Groups"); err != nil {
+			return nil, err
+		}
+		syncProfile(user, claims)
+	}
+
+	if err = populateGroups(user, claims.Groups); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func onboard(claims *oidc_util.Claims) (*models.User, error) {
+	exist, err := dao.UserExists(models.User{Username: claims.Username}, "username")
+	if err != nil {
+		return nil, err
+	}
+	if exist {
+		return nil, ErrUsernameConflict
+	}
+	user := &models.User{
+		Username: claims.Username,
+		Email:    claims.Email,
+		Realname: claims.Name,
+	}
+	if err = (&Auth{}).OnBoardUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func syncProfile(user *models.User, claims *oidc_util.Claims) {
+	cols := []string{}
+	if len(claims.Email) > 0 && claims.Email != user.Email {
+		user.Email = claims.Email
+		cols = append(cols, "Email")
+	}
+	if len(claims.Name) > 0 && claims.Name != user.Realname {
+		user.Realname = claims.Name
+		cols = append(cols, "Realname")
+	}
+	if len(cols) == 0 {
+		return
+	}
+	if err := dao.ChangeUserProfile(*user, cols...); err != nil {
+		log.Warningf("Failed to update user profile, user: %s, error: %v", user.Username, err)
+	}
+}
+
+// populateGroups onboards the groups and fills the group list of the user, the user is
+// granted the system admin role if the admin group is one of the groups
+func populateGroups(user *models.User, groups []string) error {
+	setting, err := config.OIDCSetting()
+	if err != nil {
+		return err
+	}
+	a := &Auth{}
+	user.GroupList = []*models.UserGroup{}
+	for _, name := range groups {
+		g := &models.UserGroup{GroupName: name}
+		if err := a.OnBoardGroup(g, ""); err != nil {
+			log.Warningf("Failed to onboard OIDC group %s, error: %v", name, err)
+			continue
+		}
+		user.GroupList = append(user.GroupList, g)
+		if len(setting.AdminGroup) > 0 && name == setting.AdminGroup {
+			user.HasAdminRole = true
+		}
+	}
+	return nil
+}
+
+func init() {
+	auth.Register(common.OIDCAuth, &Auth{})
+}
@@ -0,0 +1,178 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oidc
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	oidc_test "github.com/goharbor/harbor/src/common/utils/oidc/test"
+	"github.com/goharbor/harbor/src/common/utils/test"
+	"github.com/goharbor/harbor/src/core/auth"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mockServer *oidc_test.MockServer
+
+func TestMain(m *testing.M) {
+	test.InitDatabaseFromEnv()
+	mockServer = oidc_test.NewMockServer(&oidc_test.MockServerConfig{
+		ClientID:     "harbor",
+		ClientSecret: "secret",
+	})
+	defer mockServer.Close()
+
+	server, err := test.NewAdminserver(map[string]interface{}{
+		common.AUTHMode:         common.OIDCAuth,
+		common.OIDCEndpoint:     mockServer.URL,
+		common.OIDCClientID:     "harbor",
+		common.OIDCClientSecret: "secret",
+		common.OIDCAdminGroup:   "harbor-admins",
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer server.Close()
+
+	if err := os.Setenv("ADMINSERVER_URL", server.URL); err != nil {
+		panic(err)
+	}
+	if err := config.Init(); err != nil {
+		panic(err)
+	}
+
+	rc := m.Run()
+	clean()
+	os.Exit(rc)
+}
+
+func clean() {
+	o := dao.GetOrmer()
+	o.Raw(`delete from oidc_user where subiss like 'oidc-test-%'`).Exec()
+	o.Raw(`delete from harbor_user where username like 'oidc_test_%'`).Exec()
+	o.Raw(`delete from user_group where group_type = ?`, common.OIDCGroupType).Exec()
+}
+
+func verify(t *testing.T, claims map[string]interface{}) *models.User {
+	p, err := GetProvider()
+	require.Nil(t, err)
+	c, err := p.VerifyToken(context.Background(), mockServer.IDToken(claims))
+	require.Nil(t, err)
+	user, err := UserFromClaims(c)
+	require.Nil(t, err)
+	return user
+}
+
+func TestGetProvider(t *testing.T) {
+	p1, err := GetProvider()
+	require.Nil(t, err)
+	p2, err := GetProvider()
+	require.Nil(t, err)
+	assert.True(t, p1 == p2)
+	assert.Equal(t, mockServer.URL, p1.Setting().Endpoint)
+}
+
+func TestSearchGroup(t *testing.T) {
+	a := &Auth{}
+	g, err := a.SearchGroup(" dev ")
+	require.Nil(t, err)
+	require.NotNil(t, g)
+	assert.Equal(t, "dev", g.GroupName)
+	assert.Equal(t, common.OIDCGroupType, g.GroupType)
+
+	g, err = a.SearchGroup("")
+	require.Nil(t, err)
+	assert.Nil(t, g)
+}
+
+func TestUserFromClaims(t *testing.T) {
+	defer clean()
+
+	// the first login onboards the user and the groups
+	user := verify(t, map[string]interface{}{
+		"sub":                "oidc-test-1",
+		"preferred_username": "oidc_test_alice",
+		"email":              "oidc_test_alice@example.com",
+		"groups":             []string{"dev", "harbor-admins"},
+	})
+	assert.True(t, user.UserID > 0)
+	assert.Equal(t, "oidc_test_alice", user.Username)
+	assert.True(t, user.HasAdminRole)
+	require.Equal(t, 2, len(user.GroupList))
+	assert.Equal(t, "dev", user.GroupList[0].GroupName)
+	assert.Equal(t, common.OIDCGroupType, user.GroupList[0].GroupType)
+	assert.True(t, user.GroupList[0].ID > 0)
+
+	// the following login finds the user by subject and issuer
+	again := verify(t, map[string]interface{}{
+		"sub":                "oidc-test-1",
+		"preferred_username": "oidc_test_alice",
+		"groups":             []string{"dev"},
+	})
+	assert.Equal(t, user.UserID, again.UserID)
+	assert.False(t, again.HasAdminRole)
+	require.Equal(t, 1, len(again.GroupList))
+	assert.Equal(t, user.GroupList[0].ID, again.GroupList[0].ID)
+
+	// another subject with the same username
+	p, err := GetProvider()
+	require.Nil(t, err)
+	c, err := p.VerifyToken(context.Background(), mockServer.IDToken(map[string]interface{}{
+		"sub":                "oidc-test-2",
+		"preferred_username": "oidc_test_alice",
+	}))
+	require.Nil(t, err)
+	_, err = UserFromClaims(c)
+	assert.Equal(t, ErrUsernameConflict, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	defer clean()
+	user := verify(t, map[string]interface{}{
+		"sub":                "oidc-test-3",
+		"preferred_username": "oidc_test_bob",
+		"groups":             []string{"ops"},
+	})
+
+	a := &Auth{}
+	// no CLI secret is generated
+	_, err := a.Authenticate(models.AuthModel{Principal: "oidc_test_bob", Password: "secret"})
+	assert.IsType(t, auth.ErrAuth{}, err)
+
+	oidcUser, err := dao.GetOIDCUserByUserID(user.UserID)
+	require.Nil(t, err)
+	require.NotNil(t, oidcUser)
+	oidcUser.Salt = utils.GenerateRandomString()
+	oidcUser.Secret = utils.Encrypt("cli-secret", oidcUser.Salt)
+	require.Nil(t, dao.UpdateOIDCUser(oidcUser, "Secret", "Salt"))
+
+	u, err := a.Authenticate(models.AuthModel{Principal: "oidc_test_bob", Password: "cli-secret"})
+	require.Nil(t, err)
+	assert.Equal(t, user.UserID, u.UserID)
+	require.Equal(t, 1, len(u.GroupList))
+	assert.Equal(t, "ops", u.GroupList[0].GroupName)
+
+	_, err = a.Authenticate(models.AuthModel{Principal: "oidc_test_bob", Password: "wrong"})
+	assert.IsType(t, auth.ErrAuth{}, err)
+	_, err = a.Authenticate(models.AuthModel{Principal: "oidc_test_nobody", Password: "cli-secret"})
+	assert.IsType(t, auth.ErrAuth{}, err)
+}
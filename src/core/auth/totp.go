@@ -0,0 +1,175 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/totp"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// ErrOTPRequired is returned when the user has the second factor enabled but no code is provided
+var ErrOTPRequired = errors.New("the one-time password is required")
+
+// GetEnabledTOTP returns the second factor of the user, nil is returned if the user doesn't have an enabled one
+func GetEnabledTOTP(userID int) (*models.UserTOTP, error) {
+	t, err := dao.GetUserTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || !t.Enabled {
+		return nil, nil
+	}
+	return t, nil
+}
+
+// TOTPEnrollRequired checks whether the user must enable the second factor before other operations,
+// which is the case for the system admins without it when it's required by the configuration
+func TOTPEnrollRequired(user *models.User) (bool, error) {
+	if !user.HasAdminRole {
+		return false, nil
+	}
+	required, err := config.SysadminTOTPRequired()
+	if err != nil || !required {
+		return false, err
+	}
+	t, err := GetEnabledTOTP(user.UserID)
+	if err != nil {
+		return false, err
+	}
+	return t == nil, nil
+}
+
+// EncryptTOTPSecret encrypts the TOTP secret with the secret key to be stored in DB
+func EncryptTOTPSecret(secret string) (string, error) {
+	key, err := config.SecretKey()
+	if err != nil {
+		return "", err
+	}
+	return utils.ReversibleEncrypt(secret, key)
+}
+
+// VerifyOTP verifies the code against the TOTP secret, the recovery codes are also accepted if allowRecovery
+// is true, each code can only be used once
+func VerifyOTP(t *models.UserTOTP, otp string, allowRecovery bool) (bool, error) {
+	key, err := config.SecretKey()
+	if err != nil {
+		return false, err
+	}
+	secret, err := utils.ReversibleDecrypt(t.Secret, key)
+	if err != nil {
+		return false, err
+	}
+	step, ok, err := totp.Validate(secret, otp, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		return dao.UseTOTPStep(t.UserID, step)
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+	return dao.UseRecoveryCode(t.UserID, totp.NormalizeRecoveryCode(otp))
+}
+
+// VerifyCLISecret checks the secret against the CLI secret of the user with the second factor
+func VerifyCLISecret(t *models.UserTOTP, secret string) bool {
+	return len(t.CLISecret) > 0 && utils.Encrypt(secret, t.CLISalt) == t.CLISecret
+}
+
+// LoginByCLISecret verifies the CLI secret presented for basic auth by the user with the second factor. As the secret
+// replaces the password, the lockout of the password policy applies and the wrong secrets are handled as the failures
+// of the password, they freeze the user for a while and are counted by the password policy.
+func LoginByCLISecret(user *models.User, t *models.UserTOTP, secret string) error {
+	if lock.IsLocked(user.Username) {
+		return NewErrAuth("the user is frozen due to login failure")
+	}
+	policy, err := config.PasswordPolicy()
+	if err != nil {
+		return err
+	}
+	var lockout *models.UserLockout
+	if policy.MaxLoginFailures > 0 {
+		lockout, err = dao.GetUserLockout(user.Username)
+		if err != nil {
+			return err
+		}
+		if policy.Locked(lockout, time.Now()) {
+			return NewErrAuth("the user is locked due to too many login failures")
+		}
+	}
+	if !VerifyCLISecret(t, secret) {
+		if policy.MaxLoginFailures > 0 {
+			l, err := dao.IncreaseLoginFailures(user.Username, policy.MaxLoginFailures, policy.LockoutDurationOf(user.UserID))
+			if err != nil {
+				log.Errorf("failed to record the login failure of %s: %v", user.Username, err)
+			} else if l != nil && l.Locked {
+				log.Warningf("user %d is locked after %d login failures", l.UserID, l.Failures)
+			}
+		}
+		log.Debugf("invalid CLI secret, locking %s, and sleep for %v", user.Username, frozenTime)
+		lock.Lock(user.Username)
+		time.Sleep(frozenTime)
+		return NewErrAuth("invalid CLI secret")
+	}
+	// nothing to reset if the lockout is nil, which avoids writing DB for every request
+	if lockout != nil {
+		return dao.ResetLoginFailures(user.UserID)
+	}
+	return nil
+}
+
+// VerifySecondFactor verifies the one-time password or the recovery code of the user who has passed
+// Login, it does nothing if the user doesn't have the second factor enabled. The failures are handled
+// as the ones of the password, they freeze the user for a while and are counted by the password policy.
+func VerifySecondFactor(user *models.User, otp string) error {
+	t, err := GetEnabledTOTP(user.UserID)
+	if err != nil || t == nil {
+		return err
+	}
+	if len(otp) == 0 {
+		return ErrOTPRequired
+	}
+	policy, err := config.PasswordPolicy()
+	if err != nil {
+		return err
+	}
+	ok, err := VerifyOTP(t, otp, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if policy.MaxLoginFailures > 0 {
+			if _, err := dao.IncreaseLoginFailures(user.Username, policy.MaxLoginFailures, policy.LockoutDurationOf(user.UserID)); err != nil {
+				log.Errorf("failed to record the login failure of %s: %v", user.Username, err)
+			}
+		}
+		log.Debugf("invalid one-time password, locking %s, and sleep for %v", user.Username, frozenTime)
+		lock.Lock(user.Username)
+		time.Sleep(frozenTime)
+		return NewErrAuth("invalid one-time password")
+	}
+	if policy.MaxLoginFailures > 0 {
+		return dao.ResetLoginFailures(user.UserID)
+	}
+	return nil
+}
@@ -268,6 +268,86 @@ func TokenExpiration() (int, error) {
 	return int(utils.SafeCastFloat64(cfg[common.TokenExpiration])), nil
 }
 
+// StoragePerProject returns the default storage quota of the projects in bytes, -1 means unlimited
+func StoragePerProject() (int64, error) {
+	cfg, err := mg.Get()
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := cfg[common.StoragePerProject]; !ok {
+		return common.QuotaUnlimited, nil
+	}
+	return int64(utils.SafeCastFloat64(cfg[common.StoragePerProject])), nil
+}
+
+// CountPerProject returns the default quota of the tag count of the projects, -1 means unlimited
+func CountPerProject() (int64, error) {
+	cfg, err := mg.Get()
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := cfg[common.CountPerProject]; !ok {
+		return common.QuotaUnlimited, nil
+	}
+	return int64(utils.SafeCastFloat64(cfg[common.CountPerProject])), nil
+}
+
+// RobotTokenDuration returns the default duration of the robot tokens in days, -1 means never expire
+func RobotTokenDuration() (int, error) {
+	cfg, err := mg.Get()
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := cfg[common.RobotTokenDuration]; !ok {
+		return common.HarborNumKeysMap[common.RobotTokenDuration], nil
+	}
+	return int(utils.SafeCastFloat64(cfg[common.RobotTokenDuration])), nil
+}
+
+// PasswordPolicy returns the policy on the passwords and the logins of the database users,
+// the defaults are used for the items which are not set, e.g. after upgrading
+func PasswordPolicy() (*models.PasswordPolicy, error) {
+	cfg, err := mg.Get()
+	if err != nil {
+		return nil, err
+	}
+	num := func(key string) int {
+		if _, ok := cfg[key]; !ok {
+			return common.HarborNumKeysMap[key]
+		}
+		return int(utils.SafeCastFloat64(cfg[key]))
+	}
+	boolean := func(key string) bool {
+		if _, ok := cfg[key]; !ok {
+			return common.HarborBoolKeysMap[key]
+		}
+		return utils.SafeCastBool(cfg[key])
+	}
+	return &models.PasswordPolicy{
+		MinLength:        num(common.PasswordMinLength),
+		RequireUppercase: boolean(common.PasswordRequireUppercase),
+		RequireLowercase: boolean(common.PasswordRequireLowercase),
+		RequireNumber:    boolean(common.PasswordRequireNumber),
+		RequireSpecial:   boolean(common.PasswordRequireSpecial),
+		HistoryCount:     num(common.PasswordHistoryCount),
+		MaxAge:           num(common.PasswordMaxAge),
+		MaxLoginFailures: num(common.LoginMaxFailures),
+		LockoutDuration:  num(common.LoginLockoutDuration),
+	}, nil
+}
+
+// SysadminTOTPRequired returns whether the system admins are required to enable the TOTP second factor
+func SysadminTOTPRequired() (bool, error) {
+	cfg, err := mg.Get()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := cfg[common.SysadminTOTPRequired]; !ok {
+		return common.HarborBoolKeysMap[common.SysadminTOTPRequired], nil
+	}
+	return utils.SafeCastBool(cfg[common.SysadminTOTPRequired]), nil
+}
+
 // ExtEndpoint returns the external URL of Harbor: protocol://host:port
 func ExtEndpoint() (string, error) {
 	cfg, err := mg.Get()
@@ -423,6 +503,18 @@ func CoreSecret() string {
 	return os.Getenv("CORE_SECRET")
 }
 
+// ReplicationJobQueue returns the jobservice queue the replication jobs are put into,
+// the default queue is used if it's empty
+func ReplicationJobQueue() string {
+	return os.Getenv("REPLICATION_JOB_QUEUE")
+}
+
+// ScanJobQueue returns the jobservice queue the scan jobs are put into,
+// the default queue is used if it's empty
+func ScanJobQueue() string {
+	return os.Getenv("SCAN_JOB_QUEUE")
+}
+
 // JobserviceSecret returns a secret to mark Jobservice when communicate with
 // other component
 // TODO replace it with method of SecretStore
@@ -535,6 +627,36 @@ func UAASettings() (*models.UAASettings, error) {
 	return us, nil
 }
 
+// OIDCSetting returns the settings to access the OIDC provider, the redirect URL is the
+// callback of Harbor which must be registered in the provider.
+func OIDCSetting() (*models.OIDCSetting, error) {
+	cfg, err := mg.Get()
+	if err != nil {
+		return nil, err
+	}
+	extURL, err := ExtEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	scope := []string{}
+	for _, s := range strings.Split(utils.SafeCastString(cfg[common.OIDCScope]), ",") {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			scope = append(scope, s)
+		}
+	}
+	return &models.OIDCSetting{
+		Name:         utils.SafeCastString(cfg[common.OIDCName]),
+		Endpoint:     strings.TrimSuffix(utils.SafeCastString(cfg[common.OIDCEndpoint]), "/"),
+		ClientID:     utils.SafeCastString(cfg[common.OIDCClientID]),
+		ClientSecret: utils.SafeCastString(cfg[common.OIDCClientSecret]),
+		VerifyCert:   utils.SafeCastBool(cfg[common.OIDCVerifyCert]),
+		Scope:        scope,
+		RedirectURL:  strings.TrimSuffix(extURL, "/") + "/c/oidc/callback",
+		GroupsClaim:  utils.SafeCastString(cfg[common.OIDCGroupsClaim]),
+		AdminGroup:   utils.SafeCastString(cfg[common.OIDCAdminGroup]),
+	}, nil
+}
+
 // ReadOnly returns a bool to indicates if Harbor is in read only mode.
 func ReadOnly() bool {
 	cfg, err := mg.Get()
@@ -90,6 +90,18 @@ func TestConfig(t *testing.T) {
 		t.Fatalf("failed to get token expiration: %v", err)
 	}
 
+	duration, err := RobotTokenDuration()
+	if err != nil {
+		t.Fatalf("failed to get robot token duration: %v", err)
+	}
+	assert.Equal(30, duration)
+
+	required, err := SysadminTOTPRequired()
+	if err != nil {
+		t.Fatalf("failed to get sysadmin TOTP required: %v", err)
+	}
+	assert.False(required)
+
 	if _, err := ExtEndpoint(); err != nil {
 		t.Fatalf("failed to get domain name: %v", err)
 	}
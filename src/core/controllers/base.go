@@ -52,7 +52,12 @@ type messageDetail struct {
 	UUID string
 }
 
-// Login handles login request from UI.
+// otpHeader is set to "required" in the response when the user has the second factor enabled but
+// doesn't provide the code, the UI should ask for it and submit it with the credentials again
+const otpHeader = "X-Harbor-OTP"
+
+// Login handles login request from UI, the one-time password or the recovery code is required as "otp"
+// for the users with the second factor enabled.
 func (cc *CommonController) Login() {
 	principal := cc.GetString("principal")
 	password := cc.GetString("password")
@@ -69,6 +74,21 @@ func (cc *CommonController) Login() {
 	if user == nil {
 		cc.CustomAbort(http.StatusUnauthorized, "")
 	}
+
+	if err = auth.VerifySecondFactor(user, cc.GetString("otp")); err != nil {
+		if err == auth.ErrOTPRequired {
+			cc.Ctx.ResponseWriter.Header().Set(otpHeader, "required")
+			cc.CustomAbort(http.StatusUnauthorized, "")
+		}
+		log.Errorf("Error occurred in verifying the second factor of %s: %v", user.Username, err)
+		cc.CustomAbort(http.StatusUnauthorized, "")
+	}
+	required, err := auth.TOTPEnrollRequired(user)
+	if err != nil {
+		log.Errorf("Error occurred in checking the second factor of %s: %v", user.Username, err)
+		cc.CustomAbort(http.StatusInternalServerError, "Internal error.")
+	}
+	user.TOTPEnrollRequired = required
 	cc.SetSession("user", *user)
 }
 
@@ -213,12 +233,35 @@ func (cc *CommonController) ResetPassword() {
 	password := cc.GetString("password")
 
 	if password != "" {
+		policy, err := config.PasswordPolicy()
+		if err != nil {
+			log.Errorf("Error occurred in getting the password policy: %v", err)
+			cc.CustomAbort(http.StatusInternalServerError, "Internal error.")
+		}
+		if err = policy.Validate(password); err != nil {
+			cc.CustomAbort(http.StatusBadRequest, err.Error())
+		}
+		reused, err := dao.IsPasswordReused(user.UserID, password, policy.HistoryCount)
+		if err != nil {
+			log.Errorf("Error occurred in IsPasswordReused: %v", err)
+			cc.CustomAbort(http.StatusInternalServerError, "Internal error.")
+		}
+		if reused {
+			cc.CustomAbort(http.StatusBadRequest, "the new password can not be same with the old one or the previous ones")
+		}
 		user.Password = password
 		err = dao.ResetUserPassword(*user)
 		if err != nil {
 			log.Errorf("Error occurred in ResetUserPassword: %v", err)
 			cc.CustomAbort(http.StatusInternalServerError, "Internal error.")
 		}
+		if err = dao.PrunePasswordHistory(user.UserID, policy.HistoryCount); err != nil {
+			log.Errorf("Error occurred in PrunePasswordHistory: %v", err)
+		}
+		// the user proves the identity with the email, so the lockout is cleared as well
+		if err = dao.ResetLoginFailures(user.UserID); err != nil {
+			log.Errorf("Error occurred in ResetLoginFailures: %v", err)
+		}
 	} else {
 		cc.CustomAbort(http.StatusBadRequest, "password_is_required")
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	oidc_test "github.com/goharbor/harbor/src/common/utils/oidc/test"
 	"github.com/goharbor/harbor/src/common/utils/test"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/proxy"
@@ -63,6 +64,8 @@ func init() {
 	beego.Router("/c/reset", &CommonController{}, "post:ResetPassword")
 	beego.Router("/c/userExists", &CommonController{}, "post:UserExists")
 	beego.Router("/c/sendEmail", &CommonController{}, "get:SendResetEmail")
+	beego.Router("/c/oidc/login", &OIDCController{}, "get:Login")
+	beego.Router("/c/oidc/callback", &OIDCController{}, "get:Callback")
 	beego.Router("/v2/*", &RegistryProxy{}, "*:Handle")
 }
 
@@ -121,6 +124,55 @@ func TestUserResettable(t *testing.T) {
 	assert.True(isUserResetable(u1))
 }
 
+func TestOIDCLogin(t *testing.T) {
+	assert := assert.New(t)
+	mockServer := oidc_test.NewMockServer(&oidc_test.MockServerConfig{
+		ClientID:     "harbor",
+		ClientSecret: "secret",
+	})
+	defer mockServer.Close()
+	DBAuthAdminsvr, err := test.NewAdminserver(map[string]interface{}{
+		common.AUTHMode: common.DBAuth,
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer DBAuthAdminsvr.Close()
+	OIDCAuthAdminsvr, err := test.NewAdminserver(map[string]interface{}{
+		common.AUTHMode:         common.OIDCAuth,
+		common.OIDCEndpoint:     mockServer.URL,
+		common.OIDCClientID:     "harbor",
+		common.OIDCClientSecret: "secret",
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer OIDCAuthAdminsvr.Close()
+
+	if err := config.InitByURL(DBAuthAdminsvr.URL); err != nil {
+		panic(err)
+	}
+	r, _ := http.NewRequest("GET", "/c/oidc/login", nil)
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusPreconditionFailed, w.Code, "'/c/oidc/login' httpStatusCode should be 412 when the auth mode is not OIDC")
+
+	if err := config.InitByURL(OIDCAuthAdminsvr.URL); err != nil {
+		panic(err)
+	}
+	r, _ = http.NewRequest("GET", "/c/oidc/login", nil)
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusFound, w.Code, "'/c/oidc/login' httpStatusCode should be 302")
+	assert.True(strings.HasPrefix(w.Header().Get("Location"), mockServer.URL+"/auth?"))
+
+	// the callback without the state stored in session is rejected
+	r, _ = http.NewRequest("GET", "/c/oidc/callback?code="+oidc_test.MockCode+"&state=abc", nil)
+	w = httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	assert.Equal(http.StatusBadRequest, w.Code, "'/c/oidc/callback' httpStatusCode should be 400 when the state is invalid")
+}
+
 // TestMain is a sample to run an endpoint test
 func TestAll(t *testing.T) {
 	if err := config.Init(); err != nil {
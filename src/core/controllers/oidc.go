@@ -0,0 +1,109 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/auth/oidc"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+const oidcStateKey = "oidc_state"
+
+// OIDCController handles the authorization code flow of OIDC
+type OIDCController struct {
+	beego.Controller
+}
+
+// Prepare checks the auth mode is OIDC
+func (oc *OIDCController) Prepare() {
+	mode, err := config.AuthMode()
+	if err != nil {
+		log.Errorf("failed to get auth mode: %v", err)
+		oc.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	if mode != common.OIDCAuth {
+		oc.CustomAbort(http.StatusPreconditionFailed, "the auth mode is not OIDC")
+	}
+}
+
+// Login redirects the user to the login page of the provider
+func (oc *OIDCController) Login() {
+	provider, err := oidc.GetProvider()
+	if err != nil {
+		log.Errorf("failed to get OIDC provider: %v", err)
+		oc.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	state := utils.GenerateRandomString()
+	url, err := provider.AuthCodeURL(state)
+	if err != nil {
+		log.Errorf("failed to get the URL of OIDC login page: %v", err)
+		oc.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	oc.SetSession(oidcStateKey, state)
+	oc.Redirect(url, http.StatusFound)
+}
+
+// Callback handles the redirection from the provider, it exchanges the authorization code
+// for the ID token and logs the user in
+func (oc *OIDCController) Callback() {
+	state, ok := oc.GetSession(oidcStateKey).(string)
+	if !ok || len(state) == 0 || state != oc.GetString("state") {
+		oc.CustomAbort(http.StatusBadRequest, "invalid state")
+	}
+	oc.DelSession(oidcStateKey)
+
+	if e := oc.GetString("error"); len(e) > 0 {
+		log.Errorf("OIDC provider returned error: %s, %s", e, oc.GetString("error_description"))
+		oc.CustomAbort(http.StatusUnauthorized, "")
+	}
+	code := oc.GetString("code")
+	if len(code) == 0 {
+		oc.CustomAbort(http.StatusBadRequest, "code is required")
+	}
+
+	provider, err := oidc.GetProvider()
+	if err != nil {
+		log.Errorf("failed to get OIDC provider: %v", err)
+		oc.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	ctx := context.Background()
+	token, err := provider.ExchangeToken(ctx, code)
+	if err != nil {
+		log.Errorf("failed to exchange the authorization code: %v", err)
+		oc.CustomAbort(http.StatusUnauthorized, "")
+	}
+	claims, err := provider.VerifyToken(ctx, token.IDToken)
+	if err != nil {
+		log.Errorf("failed to verify the ID token: %v", err)
+		oc.CustomAbort(http.StatusUnauthorized, "")
+	}
+	user, err := oidc.UserFromClaims(claims)
+	if err == oidc.ErrUsernameConflict {
+		oc.CustomAbort(http.StatusConflict, err.Error())
+	}
+	if err != nil {
+		log.Errorf("failed to login the OIDC user %s: %v", claims.Username, err)
+		oc.CustomAbort(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	oc.SetSession("user", *user)
+	oc.Redirect("/", http.StatusFound)
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+var (
+	changePasswordURL = regexp.MustCompile(`^/api/users/[0-9]+/password$`)
+	totpURL           = regexp.MustCompile(`^/api/users/[0-9]+/totp$`)
+)
+
+// PasswordExpiryFilter returns 403 for the requests of the users who logged in with an expired password,
+// except the ones to change the password, enable the second factor, get the current user and log out.
+func PasswordExpiryFilter(ctx *context.Context) {
+	user, ok := ctx.Input.Session("user").(models.User)
+	if !ok || !user.PasswordExpired {
+		return
+	}
+	if restrictedSessionAllowed(ctx.Request) {
+		return
+	}
+	ctx.ResponseWriter.WriteHeader(http.StatusForbidden)
+	if _, err := ctx.ResponseWriter.Write([]byte("The password is expired, please change it first.")); err != nil {
+		log.Errorf("failed to write response body: %v", err)
+	}
+}
+
+// restrictedSessionAllowed checks whether the request is allowed for the users who must change the password
+// or enable the second factor before other operations, both are allowed as a user may need to do both
+func restrictedSessionAllowed(req *http.Request) bool {
+	path := req.URL.Path
+	switch {
+	case req.Method == http.MethodGet && path == "/api/users/current":
+		return true
+	case req.Method == http.MethodPut && changePasswordURL.MatchString(path):
+		return true
+	case (req.Method == http.MethodGet || req.Method == http.MethodPost || req.Method == http.MethodPut) &&
+		totpURL.MatchString(path):
+		return true
+	case path == "/c/log_out":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictedSessionAllowed(t *testing.T) {
+	cases := []struct {
+		method  string
+		url     string
+		allowed bool
+	}{
+		{http.MethodGet, "/api/users/current", true},
+		{http.MethodPut, "/api/users/3/password", true},
+		{http.MethodGet, "/c/log_out", true},
+		{http.MethodPut, "/api/users/3", false},
+		{http.MethodGet, "/api/users/3/password", false},
+		{http.MethodGet, "/api/users/3/totp", true},
+		{http.MethodPost, "/api/users/3/totp", true},
+		{http.MethodPut, "/api/users/3/totp", true},
+		{http.MethodDelete, "/api/users/3/totp", false},
+		{http.MethodPost, "/api/users/3/totp/recovery_codes", false},
+		{http.MethodGet, "/api/projects", false},
+		{http.MethodGet, "/service/token", false},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.url, nil)
+		assert.Equal(t, c.allowed, restrictedSessionAllowed(req), "%s %s", c.method, c.url)
+	}
+}
+
+func TestPasswordExpiryFilter(t *testing.T) {
+	newSessionContext := func(url string, user models.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		store, err := beego.GlobalSessions.SessionStart(httptest.NewRecorder(), req)
+		require.Nil(t, err)
+		require.Nil(t, store.Set("user", user))
+		req = httptest.NewRequest(http.MethodGet, url, nil)
+		addSessionIDToCookie(req, store.SessionID())
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		PasswordExpiryFilter(ctx)
+		return ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder)
+	}
+
+	user := models.User{UserID: 3, Username: "user"}
+	assert.Equal(t, http.StatusOK, newSessionContext("/api/projects", user).Code)
+
+	user.PasswordExpired = true
+	assert.Equal(t, http.StatusForbidden, newSessionContext("/api/projects", user).Code)
+	assert.Equal(t, http.StatusOK, newSessionContext("/api/users/current", user).Code)
+}
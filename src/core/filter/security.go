@@ -19,15 +19,21 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
+	"time"
 
 	beegoctx "github.com/astaxie/beego/context"
 	"github.com/docker/distribution/reference"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	secstore "github.com/goharbor/harbor/src/common/secret"
 	"github.com/goharbor/harbor/src/common/security"
 	admr "github.com/goharbor/harbor/src/common/security/admiral"
 	"github.com/goharbor/harbor/src/common/security/admiral/authcontext"
 	"github.com/goharbor/harbor/src/common/security/local"
+	"github.com/goharbor/harbor/src/common/security/pat"
+	"github.com/goharbor/harbor/src/common/security/robot"
 	"github.com/goharbor/harbor/src/common/security/secret"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/auth"
@@ -203,6 +209,16 @@ func (b *basicAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 	}
 
 	// standalone
+	if strings.HasPrefix(username, common.RobotPrefix) {
+		return b.robotLogin(ctx, username, password)
+	}
+	// a password in the format of the personal access tokens goes through the normal flow if it doesn't match
+	if _, _, isToken := models.ParsePersonalAccessToken(password); isToken && b.tokenLogin(ctx, username, password) {
+		return true
+	}
+	if ok, handled := b.cliSecretLogin(ctx, username, password); handled {
+		return ok
+	}
 	user, err := auth.Login(models.AuthModel{
 		Principal: username,
 		Password:  password,
@@ -215,11 +231,150 @@ func (b *basicAuthReqCtxModifier) Modify(ctx *beegoctx.Context) bool {
 		log.Debug("basic auth user is nil")
 		return false
 	}
+	if user.PasswordExpired {
+		log.Debugf("the password of %s is expired, it must be changed before using basic auth", username)
+		return false
+	}
+	// the users with the second factor can only use the CLI secret, the system admins required
+	// to have the second factor can't use basic auth before enabling it
+	t, err := auth.GetEnabledTOTP(user.UserID)
+	if err != nil {
+		log.Errorf("failed to get the second factor of %s: %v", username, err)
+		return false
+	}
+	if t != nil {
+		log.Debugf("%s has the second factor enabled, the password can't be used for basic auth", username)
+		return false
+	}
+	required, err := auth.TOTPEnrollRequired(user)
+	if err != nil {
+		log.Errorf("failed to check the second factor of %s: %v", username, err)
+		return false
+	}
+	if required {
+		log.Debugf("%s must enable the second factor before using basic auth", username)
+		return false
+	}
+	log.Debug("using local database project manager")
+	pm := config.GlobalProjectMgr
+	log.Debug("creating local database security context...")
+	securCtx := local.NewSecurityContext(user, pm)
+	setSecurCtxAndPM(ctx.Request, securCtx, pm)
+	return true
+}
+
+func (b *basicAuthReqCtxModifier) robotLogin(ctx *beegoctx.Context, name, secret string) bool {
+	rb, err := dao.LoginByRobot(name, secret)
+	if err != nil {
+		log.Errorf("failed to authenticate robot %s: %v", name, err)
+		return false
+	}
+	if rb == nil {
+		log.Debugf("invalid credentials of robot %s", name)
+		return false
+	}
+	if rb.Disabled {
+		log.Debugf("robot %s is disabled", name)
+		return false
+	}
+	if rb.Expired(time.Now()) {
+		log.Debugf("the token of robot %s is expired", name)
+		return false
+	}
+	log.Debug("using local database project manager")
+	pm := config.GlobalProjectMgr
+	log.Debug("creating robot account security context...")
+	securCtx := robot.NewSecurityContext(rb, pm)
+	setSecurCtxAndPM(ctx.Request, securCtx, pm)
+	return true
+}
+
+// cliSecretLogin authenticates the users with the second factor enabled by the CLI secret, handled
+// is false if the user doesn't exist in DB or doesn't have the second factor enabled
+func (b *basicAuthReqCtxModifier) cliSecretLogin(ctx *beegoctx.Context, username, secret string) (ok bool, handled bool) {
+	user, err := dao.GetUser(models.User{Username: username})
+	if err != nil {
+		log.Errorf("failed to get user %s: %v", username, err)
+		return false, true
+	}
+	if user == nil {
+		return false, false
+	}
+	t, err := auth.GetEnabledTOTP(user.UserID)
+	if err != nil {
+		log.Errorf("failed to get the second factor of %s: %v", username, err)
+		return false, true
+	}
+	if t == nil {
+		return false, false
+	}
+	if err = auth.LoginByCLISecret(user, t, secret); err != nil {
+		log.Errorf("failed to authenticate %s by CLI secret: %v", username, err)
+		return false, true
+	}
 	log.Debug("using local database project manager")
 	pm := config.GlobalProjectMgr
 	log.Debug("creating local database security context...")
 	securCtx := local.NewSecurityContext(user, pm)
 	setSecurCtxAndPM(ctx.Request, securCtx, pm)
+	return true, true
+}
+
+// tokenLogin authenticates the user by the personal access token, the information of the user
+// is refreshed from the account repository as the password isn't verified
+func (b *basicAuthReqCtxModifier) tokenLogin(ctx *beegoctx.Context, username, presented string) bool {
+	user, err := dao.GetUser(models.User{Username: username})
+	if err != nil {
+		log.Errorf("failed to get user %s: %v", username, err)
+		return false
+	}
+	if user == nil {
+		return false
+	}
+	// the token doesn't bypass the lockout due to too many login failures
+	locked, err := auth.Locked(user.Username)
+	if err != nil {
+		log.Errorf("failed to check the lockout of %s: %v", username, err)
+		return false
+	}
+	if locked {
+		log.Debugf("%s is locked due to too many login failures, the personal access token is refused", username)
+		return false
+	}
+	token, err := dao.LoginByPersonalAccessToken(user.UserID, presented)
+	if err != nil {
+		log.Errorf("failed to authenticate %s by personal access token: %v", username, err)
+		return false
+	}
+	if token == nil {
+		log.Debugf("invalid personal access token of %s", username)
+		return false
+	}
+	if token.Expired(time.Now()) {
+		log.Debugf("the personal access token %s of %s is expired", token.Name, username)
+		return false
+	}
+	if err = auth.RefreshUser(user); err != nil {
+		log.Errorf("failed to refresh user %s: %v", username, err)
+		return false
+	}
+	required, err := auth.TOTPEnrollRequired(user)
+	if err != nil {
+		log.Errorf("failed to check the second factor of %s: %v", username, err)
+		return false
+	}
+	if required {
+		log.Debugf("%s must enable the second factor before using personal access tokens", username)
+		return false
+	}
+	if err = dao.TouchPersonalAccessToken(token.ID); err != nil {
+		log.Errorf("failed to update the last used time of personal access token %d: %v", token.ID, err)
+	}
+	log.Debug("using local database project manager")
+	pm := config.GlobalProjectMgr
+	log.Debug("creating personal access token security context...")
+	securCtx := pat.NewSecurityContext(local.NewSecurityContext(user, pm), token, pm)
+	setSecurCtxAndPM(ctx.Request, securCtx, pm)
 	return true
 }
 
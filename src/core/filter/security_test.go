@@ -28,11 +28,14 @@ import (
 	"github.com/astaxie/beego"
 	beegoctx "github.com/astaxie/beego/context"
 	"github.com/astaxie/beego/session"
+	"github.com/goharbor/harbor/src/common"
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 	commonsecret "github.com/goharbor/harbor/src/common/secret"
 	"github.com/goharbor/harbor/src/common/security"
 	"github.com/goharbor/harbor/src/common/security/local"
+	"github.com/goharbor/harbor/src/common/security/robot"
 	"github.com/goharbor/harbor/src/common/security/secret"
 	_ "github.com/goharbor/harbor/src/core/auth/db"
 	_ "github.com/goharbor/harbor/src/core/auth/ldap"
@@ -40,6 +43,7 @@ import (
 	"github.com/goharbor/harbor/src/core/promgr"
 	driver_local "github.com/goharbor/harbor/src/core/promgr/pmsdriver/local"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMain(m *testing.M) {
@@ -146,6 +150,159 @@ func TestBasicAuthReqCtxModifier(t *testing.T) {
 	assert.NotNil(t, projectManager(ctx))
 }
 
+func TestRobotBasicAuthReqCtxModifier(t *testing.T) {
+	rb := &models.Robot{
+		Name:      "robot$filter",
+		Salt:      "salt",
+		Token:     utils.Encrypt("secret", "salt"),
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	}
+	id, err := dao.AddRobot(rb)
+	if err != nil {
+		t.Fatalf("failed to add robot: %v", err)
+	}
+	defer dao.DeleteRobot(id)
+
+	modify := func() *beegoctx.Context {
+		req, err := http.NewRequest(http.MethodGet,
+			"http://127.0.0.1/api/projects/", nil)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", req)
+		}
+		req.SetBasicAuth("robot$filter", "secret")
+		ctx, err := newContext(req)
+		if err != nil {
+			t.Fatalf("failed to crate context: %v", err)
+		}
+		modifier := &basicAuthReqCtxModifier{}
+		if !modifier.Modify(ctx) {
+			return nil
+		}
+		return ctx
+	}
+
+	ctx := modify()
+	if assert.NotNil(t, ctx) {
+		assert.IsType(t, &robot.SecurityContext{}, securityContext(ctx))
+	}
+
+	// expired
+	rb.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+	if err := dao.UpdateRobot(rb, "ExpiresAt"); err != nil {
+		t.Fatalf("failed to update robot: %v", err)
+	}
+	assert.Nil(t, modify())
+
+	// disabled
+	rb.ExpiresAt = models.RobotNeverExpire
+	rb.Disabled = true
+	if err := dao.UpdateRobot(rb, "ExpiresAt", "Disabled"); err != nil {
+		t.Fatalf("failed to update robot: %v", err)
+	}
+	assert.Nil(t, modify())
+}
+
+// basicAuthModify runs the basic auth modifier with the credential, returns whether the context is modified
+func basicAuthModify(t *testing.T, username, password string) bool {
+	req, err := http.NewRequest(http.MethodGet,
+		"http://127.0.0.1/api/projects/", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", req)
+	}
+	req.SetBasicAuth(username, password)
+	ctx, err := newContext(req)
+	if err != nil {
+		t.Fatalf("failed to crate context: %v", err)
+	}
+	modifier := &basicAuthReqCtxModifier{}
+	return modifier.Modify(ctx)
+}
+
+func TestCLISecretBasicAuthLockout(t *testing.T) {
+	user := models.User{
+		Username: "user_for_testing_cli_secret",
+		Email:    "user_for_testing_cli_secret@test.com",
+		Password: "Harbor12345",
+	}
+	id, err := dao.Register(user)
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(id))
+
+	require.Nil(t, dao.SavePendingUserTOTP(int(id), "secret"))
+	defer dao.DeleteUserTOTP(int(id))
+	totp, err := dao.GetUserTOTP(int(id))
+	require.Nil(t, err)
+	totp.Enabled = true
+	totp.CLISalt = utils.GenerateRandomString()
+	totp.CLISecret = utils.Encrypt("cli-secret", totp.CLISalt)
+	require.Nil(t, dao.UpdateUserTOTP(totp, "Enabled", "CLISecret", "CLISalt"))
+
+	require.Nil(t, config.Upload(map[string]interface{}{common.LoginMaxFailures: 2}))
+	defer config.Upload(map[string]interface{}{common.LoginMaxFailures: 0})
+
+	assert.True(t, basicAuthModify(t, user.Username, "cli-secret"))
+
+	// the wrong secrets are counted as login failures
+	assert.False(t, basicAuthModify(t, user.Username, "wrong-secret"))
+	lockout, err := dao.GetUserLockout(user.Username)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.Equal(t, 1, lockout.Failures)
+	assert.False(t, lockout.Locked)
+
+	assert.False(t, basicAuthModify(t, user.Username, "wrong-secret"))
+	lockout, err = dao.GetUserLockout(user.Username)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	assert.True(t, lockout.Locked)
+
+	// the locked user can't login by the CLI secret
+	assert.False(t, basicAuthModify(t, user.Username, "cli-secret"))
+
+	// unlocked
+	require.Nil(t, dao.ResetLoginFailures(int(id)))
+	assert.True(t, basicAuthModify(t, user.Username, "cli-secret"))
+}
+
+func TestPersonalAccessTokenBasicAuthLockout(t *testing.T) {
+	user := models.User{
+		Username: "user_for_testing_token_lockout",
+		Email:    "user_for_testing_token_lockout@test.com",
+		Password: "Harbor12345",
+	}
+	id, err := dao.Register(user)
+	require.Nil(t, err)
+	defer dao.DeleteUser(int(id))
+
+	salt := utils.GenerateRandomString()
+	tokenID, err := dao.AddPersonalAccessToken(&models.PersonalAccessToken{
+		UserID:    int(id),
+		Name:      "ci",
+		Token:     utils.Encrypt("secret", salt),
+		Salt:      salt,
+		ExpiresAt: models.PersonalAccessTokenNeverExpire,
+	})
+	require.Nil(t, err)
+	defer dao.DeletePersonalAccessToken(tokenID)
+	token := models.FormatPersonalAccessToken(tokenID, "secret")
+
+	require.Nil(t, config.Upload(map[string]interface{}{common.LoginMaxFailures: 1}))
+	defer config.Upload(map[string]interface{}{common.LoginMaxFailures: 0})
+
+	assert.True(t, basicAuthModify(t, user.Username, token))
+
+	// the locked user can't use the token
+	lockout, err := dao.IncreaseLoginFailures(user.Username, 1, 0)
+	require.Nil(t, err)
+	require.NotNil(t, lockout)
+	require.True(t, lockout.Locked)
+	assert.False(t, basicAuthModify(t, user.Username, token))
+
+	// unlocked
+	require.Nil(t, dao.ResetLoginFailures(int(id)))
+	assert.True(t, basicAuthModify(t, user.Username, token))
+}
+
 func TestSessionReqCtxModifier(t *testing.T) {
 	user := models.User{
 		Username:     "admin",
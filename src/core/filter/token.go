@@ -0,0 +1,74 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/security/pat"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+var (
+	projectURL        = regexp.MustCompile(`^/api/projects/[0-9]+(/.*)?$`)
+	scopedURLPrefixes = []string{
+		"/v2/",
+		"/service/token",
+		"/api/repositories/",
+		"/api/chartrepo/",
+		"/chartrepo/",
+	}
+)
+
+// ScopedTokenFilter returns 403 for the requests authenticated by the personal access tokens scoped to
+// projects, except the ones to the registry, the token service and the APIs of the resources under projects,
+// as the scope can only limit the permissions on projects.
+func ScopedTokenFilter(ctx *context.Context) {
+	securityCtx, err := GetSecurityContext(ctx.Request)
+	if err != nil {
+		return
+	}
+	tokenCtx, ok := securityCtx.(*pat.SecurityContext)
+	if !ok || !tokenCtx.GetToken().Scoped() {
+		return
+	}
+	if scopedTokenAllowed(ctx.Request) {
+		return
+	}
+	ctx.ResponseWriter.WriteHeader(http.StatusForbidden)
+	if _, err := ctx.ResponseWriter.Write([]byte("The personal access token is scoped to projects, it can't access this resource.")); err != nil {
+		log.Errorf("failed to write response body: %v", err)
+	}
+}
+
+// scopedTokenAllowed checks whether the request accesses the resources under projects
+func scopedTokenAllowed(req *http.Request) bool {
+	path := req.URL.Path
+	if req.Method == http.MethodGet && (path == "/api/projects" || path == "/api/repositories") {
+		return true
+	}
+	if projectURL.MatchString(path) {
+		return true
+	}
+	for _, prefix := range scopedURLPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
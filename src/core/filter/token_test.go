@@ -0,0 +1,56 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/security/local"
+	"github.com/goharbor/harbor/src/common/security/pat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedTokenFilter(t *testing.T) {
+	user := &models.User{UserID: 2, Username: "tester"}
+	scoped := &models.PersonalAccessToken{
+		Name: "ci",
+		Access: []*models.ResourceActions{
+			{Name: "/project/1/image", Actions: []string{"pull"}},
+		},
+	}
+	filter := func(method, url string, token *models.PersonalAccessToken) int {
+		req := httptest.NewRequest(method, url, nil)
+		setSecurCtxAndPM(req, pat.NewSecurityContext(local.NewSecurityContext(user, nil), token, nil), nil)
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		ScopedTokenFilter(ctx)
+		return ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder).Code
+	}
+
+	assert.Equal(t, http.StatusOK, filter(http.MethodGet, "/api/projects/1/members", scoped))
+	assert.Equal(t, http.StatusOK, filter(http.MethodGet, "/api/repositories/library/ubuntu/tags", scoped))
+	assert.Equal(t, http.StatusOK, filter(http.MethodGet, "/service/token", scoped))
+	assert.Equal(t, http.StatusOK, filter(http.MethodGet, "/api/projects", scoped))
+	assert.Equal(t, http.StatusForbidden, filter(http.MethodPost, "/api/projects", scoped))
+	assert.Equal(t, http.StatusForbidden, filter(http.MethodPut, "/api/users/2", scoped))
+	assert.Equal(t, http.StatusForbidden, filter(http.MethodGet, "/api/users/current", scoped))
+
+	unscoped := &models.PersonalAccessToken{Name: "admin"}
+	assert.Equal(t, http.StatusOK, filter(http.MethodPost, "/api/projects", unscoped))
+}
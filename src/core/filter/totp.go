@@ -0,0 +1,40 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+
+	"github.com/astaxie/beego/context"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+// TOTPEnrollFilter returns 403 for the requests of the system admins who logged in without the second factor
+// when it's required, except the ones to enable the second factor, change the password, get the current user
+// and log out.
+func TOTPEnrollFilter(ctx *context.Context) {
+	user, ok := ctx.Input.Session("user").(models.User)
+	if !ok || !user.TOTPEnrollRequired {
+		return
+	}
+	if restrictedSessionAllowed(ctx.Request) {
+		return
+	}
+	ctx.ResponseWriter.WriteHeader(http.StatusForbidden)
+	if _, err := ctx.ResponseWriter.Write([]byte("The second factor is required, please enable it first.")); err != nil {
+		log.Errorf("failed to write response body: %v", err)
+	}
+}
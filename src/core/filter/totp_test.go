@@ -0,0 +1,49 @@
+// Copyright 2018 Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPEnrollFilter(t *testing.T) {
+	newSessionContext := func(method, url string, user models.User) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		store, err := beego.GlobalSessions.SessionStart(httptest.NewRecorder(), req)
+		require.Nil(t, err)
+		require.Nil(t, store.Set("user", user))
+		req = httptest.NewRequest(method, url, nil)
+		addSessionIDToCookie(req, store.SessionID())
+		ctx, err := newContext(req)
+		require.Nil(t, err)
+		TOTPEnrollFilter(ctx)
+		return ctx.ResponseWriter.ResponseWriter.(*httptest.ResponseRecorder)
+	}
+
+	user := models.User{UserID: 1, Username: "admin", HasAdminRole: true}
+	assert.Equal(t, http.StatusOK, newSessionContext(http.MethodGet, "/api/projects", user).Code)
+
+	user.TOTPEnrollRequired = true
+	assert.Equal(t, http.StatusForbidden, newSessionContext(http.MethodGet, "/api/projects", user).Code)
+	assert.Equal(t, http.StatusOK, newSessionContext(http.MethodPost, "/api/users/1/totp", user).Code)
+	assert.Equal(t, http.StatusOK, newSessionContext(http.MethodGet, "/api/users/current", user).Code)
+}
@@ -30,12 +30,15 @@ import (
 	"github.com/goharbor/harbor/src/core/api"
 	_ "github.com/goharbor/harbor/src/core/auth/db"
 	_ "github.com/goharbor/harbor/src/core/auth/ldap"
+	_ "github.com/goharbor/harbor/src/core/auth/oidc"
 	_ "github.com/goharbor/harbor/src/core/auth/uaa"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/filter"
 	"github.com/goharbor/harbor/src/core/notifier"
 	"github.com/goharbor/harbor/src/core/proxy"
+	"github.com/goharbor/harbor/src/core/quota"
 	"github.com/goharbor/harbor/src/core/service/token"
+	"github.com/goharbor/harbor/src/core/webhook"
 	"github.com/goharbor/harbor/src/replication/core"
 	_ "github.com/goharbor/harbor/src/replication/event"
 )
@@ -113,6 +116,9 @@ func main() {
 	if err = notifier.Subscribe(notifier.ScanAllPolicyTopic, &notifier.ScanPolicyNotificationHandler{}); err != nil {
 		log.Errorf("failed to subscribe scan all policy change topic: %v", err)
 	}
+	if err = notifier.Subscribe(notifier.WebhookTopic, &webhook.Handler{}); err != nil {
+		log.Errorf("failed to subscribe webhook topic: %v", err)
+	}
 
 	if config.WithClair() {
 		clairDB, err := config.ClairDB()
@@ -131,6 +137,9 @@ func main() {
 	filter.Init()
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.SecurityFilter)
 	beego.InsertFilter("/*", beego.BeforeRouter, filter.ReadonlyFilter)
+	beego.InsertFilter("/*", beego.BeforeRouter, filter.PasswordExpiryFilter)
+	beego.InsertFilter("/*", beego.BeforeRouter, filter.TOTPEnrollFilter)
+	beego.InsertFilter("/*", beego.BeforeRouter, filter.ScopedTokenFilter)
 	beego.InsertFilter("/api/*", beego.BeforeRouter, filter.MediaTypeFilter("application/json", "multipart/form-data", "application/octet-stream"))
 
 	initRouters()
@@ -150,6 +159,9 @@ func main() {
 		log.Infof("Because SYNC_REGISTRY set false , no need to sync registry \n")
 	}
 
+	// account the tags pushed before the quota is enabled
+	go quota.Backfill()
+
 	log.Info("Init proxy")
 	proxy.Init()
 	// go proxy.StartProxy()
@@ -8,4 +8,7 @@ import (
 const (
 	// ScanAllPolicyTopic is for notifying the change of scanning all policy.
 	ScanAllPolicyTopic = common.ScanAllPolicy
+
+	// WebhookTopic is for publishing the events of projects to the webhook policies.
+	WebhookTopic = "OnWebhookEvent"
 )
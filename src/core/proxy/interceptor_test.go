@@ -15,6 +15,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 )
 
 var endpoint = "10.117.4.142"
@@ -94,6 +95,69 @@ func TestMatchPullManifest(t *testing.T) {
 	assert.Equal("sha256:ca4626b691f57d16ce1576231e4a2e2135554d32e13a85dcff380d51fdd13f6a", tag7)
 }
 
+func TestMatchPushManifest(t *testing.T) {
+	assert := assert.New(t)
+	req1, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/library/ubuntu/manifests/14.04", nil)
+	res1, _, _ := MatchPushManifest(req1)
+	assert.False(res1, "%s %v is not a request to push manifest", req1.Method, req1.URL)
+
+	req2, _ := http.NewRequest("PUT", "http://192.168.0.3:80/v2/library/ubuntu/manifests/14.04", nil)
+	res2, repo2, tag2 := MatchPushManifest(req2)
+	assert.True(res2, "%s %v is a request to push manifest", req2.Method, req2.URL)
+	assert.Equal("library/ubuntu", repo2)
+	assert.Equal("14.04", tag2)
+
+	req3, _ := http.NewRequest("PUT", "https://192.168.0.5/v2/library/ubuntu/blobs/uploads/uuid", nil)
+	res3, _, _ := MatchPushManifest(req3)
+	assert.False(res3, "%s %v is not a request to push manifest", req3.Method, req3.URL)
+}
+
+func TestMatchBlobUpload(t *testing.T) {
+	assert := assert.New(t)
+	req1, _ := http.NewRequest("GET", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/uuid", nil)
+	res1, _ := MatchBlobUpload(req1)
+	assert.False(res1, "%s %v is not a request to upload blob", req1.Method, req1.URL)
+
+	req2, _ := http.NewRequest("POST", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/", nil)
+	res2, repo2 := MatchBlobUpload(req2)
+	assert.True(res2, "%s %v is a request to upload blob", req2.Method, req2.URL)
+	assert.Equal("library/ubuntu", repo2)
+
+	req3, _ := http.NewRequest("PATCH", "http://127.0.0.1:5000/v2/library/team/ubuntu/blobs/uploads/uuid", nil)
+	res3, repo3 := MatchBlobUpload(req3)
+	assert.True(res3, "%s %v is a request to upload blob", req3.Method, req3.URL)
+	assert.Equal("library/team/ubuntu", repo3)
+
+	req4, _ := http.NewRequest("PUT", "http://127.0.0.1:5000/v2/library/ubuntu/manifests/14.04", nil)
+	res4, _ := MatchBlobUpload(req4)
+	assert.False(res4, "%s %v is not a request to upload blob", req4.Method, req4.URL)
+}
+
+func TestBlobRequired(t *testing.T) {
+	assert := assert.New(t)
+	defer func(f func(string, string) (int64, error)) { uploadedSize = f }(uploadedSize)
+	uploadedSize = func(repository, location string) (int64, error) {
+		assert.Equal("library/ubuntu", repository)
+		assert.Equal("/v2/library/ubuntu/blobs/uploads/uuid?_state=state&digest=sha256:abc", location)
+		return 1024, nil
+	}
+	qh := quotaHandler{}
+
+	// the size of the streamed chunk is unknown
+	req1, _ := http.NewRequest("PATCH", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/uuid?_state=state", nil)
+	req1.ContentLength = -1
+	required, ok := qh.blobRequired(httptest.NewRecorder(), req1, "library/ubuntu")
+	assert.True(ok)
+	assert.Equal(int64(0), required.Storage)
+
+	// the upload is completed with the last chunk
+	req2, _ := http.NewRequest("PUT", "http://127.0.0.1:5000/v2/library/ubuntu/blobs/uploads/uuid?_state=state&digest=sha256:abc", nil)
+	req2.ContentLength = 10
+	required, ok = qh.blobRequired(httptest.NewRecorder(), req2, "library/ubuntu")
+	assert.True(ok)
+	assert.Equal(int64(1034), required.Storage)
+}
+
 func TestMatchListRepos(t *testing.T) {
 	assert := assert.New(t)
 	req1, _ := http.NewRequest("POST", "http://127.0.0.1:5000/v2/_catalog", nil)
@@ -150,6 +214,7 @@ func TestPMSPolicyChecker(t *testing.T) {
 			models.ProMetaEnableContentTrust: "true",
 			models.ProMetaPreventVul:         "true",
 			models.ProMetaSeverity:           "low",
+			models.ProMetaImmutableTags:      `[{"repository":"app","tag":"v*"}]`,
 		},
 	})
 	require.Nil(t, err)
@@ -164,6 +229,26 @@ func TestPMSPolicyChecker(t *testing.T) {
 	projectVulnerableEnabled, projectVulnerableSeverity := getPolicyChecker().vulnerablePolicy("project_for_test_get_sev_low")
 	assert.True(t, projectVulnerableEnabled)
 	assert.Equal(t, projectVulnerableSeverity, models.SevLow)
+	assert.True(t, getPolicyChecker().tagImmutable(name, name+"/app", "v1.0.0"))
+	assert.False(t, getPolicyChecker().tagImmutable(name, name+"/app", "latest"))
+
+	require.Nil(t, dao.SetCVEAllowlist(&models.CVEAllowlist{
+		ProjectID: models.SystemCVEAllowlistProjectID,
+		Items:     []*models.CVEAllowlistItem{{CVEID: "CVE-2019-0001"}},
+	}))
+	defer dao.SetCVEAllowlist(&models.CVEAllowlist{ProjectID: models.SystemCVEAllowlistProjectID})
+	require.Nil(t, dao.SetCVEAllowlist(&models.CVEAllowlist{
+		ProjectID: id,
+		Items: []*models.CVEAllowlistItem{
+			{CVEID: "CVE-2019-0002"},
+			{CVEID: "CVE-2019-0003", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+		},
+	}))
+	defer dao.SetCVEAllowlist(&models.CVEAllowlist{ProjectID: id})
+	assert.Equal(t, map[string]bool{
+		"CVE-2019-0001": true,
+		"CVE-2019-0002": true,
+	}, getPolicyChecker().cveAllowlist(name))
 }
 
 func TestMatchNotaryDigest(t *testing.T) {
@@ -1,15 +1,19 @@
 package proxy
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
 
 	"github.com/goharbor/harbor/src/common/dao"
 	"github.com/goharbor/harbor/src/common/models"
 	"github.com/goharbor/harbor/src/common/utils/clair"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/common/utils/notary"
+	"github.com/goharbor/harbor/src/common/utils/registry"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/promgr"
+	"github.com/goharbor/harbor/src/core/quota"
 	coreutils "github.com/goharbor/harbor/src/core/utils"
 
 	"context"
@@ -19,14 +23,16 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type contextKey string
 
 const (
-	manifestURLPattern = `^/v2/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)manifests/([\w][\w.:-]{0,127})`
-	catalogURLPattern  = `/v2/_catalog`
-	imageInfoCtxKey    = contextKey("ImageInfo")
+	manifestURLPattern   = `^/v2/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)manifests/([\w][\w.:-]{0,127})`
+	blobUploadURLPattern = `^/v2/((?:[a-z0-9]+(?:[._-][a-z0-9]+)*/)+)blobs/uploads/`
+	catalogURLPattern    = `/v2/_catalog`
+	imageInfoCtxKey      = contextKey("ImageInfo")
 	// TODO: temp solution, remove after vmware/harbor#2242 is resolved.
 	tokenUsername = "harbor-core"
 )
@@ -37,6 +43,9 @@ var rec *httptest.ResponseRecorder
 // NotaryEndpoint , exported for testing.
 var NotaryEndpoint = ""
 
+// uploadedSize returns the size of the data in the blob upload session, replaced in testing.
+var uploadedSize = quota.UploadedSize
+
 // MatchPullManifest checks if the request looks like a request to pull manifest.  If it is returns the image and tag/sha256 digest as 2nd and 3rd return values
 func MatchPullManifest(req *http.Request) (bool, string, string) {
 	// TODO: add user agent check.
@@ -52,6 +61,33 @@ func MatchPullManifest(req *http.Request) (bool, string, string) {
 	return false, "", ""
 }
 
+// MatchPushManifest checks if the request looks like a request to push manifest.  If it is returns the image and tag/sha256 digest as 2nd and 3rd return values
+func MatchPushManifest(req *http.Request) (bool, string, string) {
+	if req.Method != http.MethodPut {
+		return false, "", ""
+	}
+	re := regexp.MustCompile(manifestURLPattern)
+	s := re.FindStringSubmatch(req.URL.Path)
+	if len(s) == 3 {
+		s[1] = strings.TrimSuffix(s[1], "/")
+		return true, s[1], s[2]
+	}
+	return false, "", ""
+}
+
+// MatchBlobUpload checks if the request looks like a request to upload blob.  If it is returns the repository as 2nd return value
+func MatchBlobUpload(req *http.Request) (bool, string) {
+	if req.Method != http.MethodPost && req.Method != http.MethodPatch && req.Method != http.MethodPut {
+		return false, ""
+	}
+	re := regexp.MustCompile(blobUploadURLPattern)
+	s := re.FindStringSubmatch(req.URL.Path)
+	if len(s) == 2 {
+		return true, strings.TrimSuffix(s[1], "/")
+	}
+	return false, ""
+}
+
 // MatchListRepos checks if the request looks like a request to list repositories.
 func MatchListRepos(req *http.Request) bool {
 	if req.Method != http.MethodGet {
@@ -71,6 +107,10 @@ type policyChecker interface {
 	contentTrustEnabled(name string) bool
 	// vulnerablePolicy  returns whether a project has enabled vulnerable, and the project's severity.
 	vulnerablePolicy(name string) (bool, models.Severity)
+	// tagImmutable returns whether the tag of the repository is immutable according to the rules of the project.
+	tagImmutable(name, repository, tag string) bool
+	// cveAllowlist returns the IDs of the vulnerabilities in the system and project CVE allowlists which are not expired.
+	cveAllowlist(name string) map[string]bool
 }
 
 type pmsPolicyChecker struct {
@@ -94,6 +134,40 @@ func (pc pmsPolicyChecker) vulnerablePolicy(name string) (bool, models.Severity)
 	return project.VulPrevented(), clair.ParseClairSev(project.Severity())
 }
 
+func (pc pmsPolicyChecker) tagImmutable(name, repository, tag string) bool {
+	project, err := pc.pm.Get(name)
+	if err != nil {
+		log.Errorf("Unexpected error when getting the project, error: %v", err)
+		return true
+	}
+	if project == nil {
+		return false
+	}
+	return project.IsTagImmutable(repository, tag)
+}
+
+func (pc pmsPolicyChecker) cveAllowlist(name string) map[string]bool {
+	system, err := dao.GetCVEAllowlist(models.SystemCVEAllowlistProjectID)
+	if err != nil {
+		log.Errorf("Unexpected error when getting the system CVE allowlist, error: %v", err)
+		return map[string]bool{}
+	}
+	project, err := pc.pm.Get(name)
+	if err != nil {
+		log.Errorf("Unexpected error when getting the project, error: %v", err)
+		return map[string]bool{}
+	}
+	if project == nil {
+		return models.ActiveCVEs(time.Now(), system)
+	}
+	list, err := dao.GetCVEAllowlist(project.ProjectID)
+	if err != nil {
+		log.Errorf("Unexpected error when getting the CVE allowlist of project %s, error: %v", name, err)
+		return map[string]bool{}
+	}
+	return models.ActiveCVEs(time.Now(), system, list)
+}
+
 // newPMSPolicyChecker returns an instance of an pmsPolicyChecker
 func newPMSPolicyChecker(pm promgr.ProjectManager) policyChecker {
 	return &pmsPolicyChecker{
@@ -168,6 +242,157 @@ func (rh readonlyHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	rh.next.ServeHTTP(rw, req)
 }
 
+type immutableTagHandler struct {
+	next http.Handler
+}
+
+func (ith immutableTagHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	flag, repository, reference := MatchPushManifest(req)
+	if !flag || isDigest(reference) {
+		ith.next.ServeHTTP(rw, req)
+		return
+	}
+	components := strings.SplitN(repository, "/", 2)
+	if len(components) < 2 || !getPolicyChecker().tagImmutable(components[0], repository, reference) {
+		ith.next.ServeHTTP(rw, req)
+		return
+	}
+
+	// the immutable tag can be pushed for the first time
+	client, err := coreutils.NewRepositoryClientForUI(tokenUsername, repository)
+	if err != nil {
+		log.Errorf("Error creating repository Client: %v", err)
+		http.Error(rw, marshalError("DENIED", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	_, exist, err := client.ManifestExist(reference)
+	if err != nil {
+		log.Errorf("Failed to check the existence of %s:%s, error: %v", repository, reference, err)
+		http.Error(rw, marshalError("DENIED", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return
+	}
+	if exist {
+		log.Warningf("The tag %s:%s is immutable, the push is rejected", repository, reference)
+		http.Error(rw, marshalError("DENIED", fmt.Sprintf("The tag %s:%s is immutable and cannot be overwritten.", repository, reference)), http.StatusForbidden)
+		return
+	}
+	ith.next.ServeHTTP(rw, req)
+}
+
+type quotaHandler struct {
+	next http.Handler
+}
+
+func (qh quotaHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if flag, repository := MatchBlobUpload(req); flag {
+		required, ok := qh.blobRequired(rw, req, repository)
+		if !ok {
+			return
+		}
+		if !qh.check(rw, repository, required) {
+			return
+		}
+	} else if flag, repository, reference := MatchPushManifest(req); flag {
+		required, ok := qh.manifestRequired(rw, req, repository, reference)
+		if !ok {
+			return
+		}
+		if required != nil && !qh.check(rw, repository, required) {
+			return
+		}
+	}
+	qh.next.ServeHTTP(rw, req)
+}
+
+// blobRequired returns the storage required by the blob upload in request. The chunks may be
+// streamed without the content length, so the size of the blob is counted from the data stored
+// in the upload session when the upload is completed by PUT
+func (qh quotaHandler) blobRequired(rw http.ResponseWriter, req *http.Request, repository string) (*models.QuotaResources, bool) {
+	required := &models.QuotaResources{}
+	if req.ContentLength > 0 {
+		required.Storage = req.ContentLength
+	}
+	if req.Method != http.MethodPut {
+		return required, true
+	}
+
+	size, err := uploadedSize(repository, req.URL.RequestURI())
+	if err != nil {
+		log.Errorf("Failed to get the size of the blob uploaded to %s, error: %v", repository, err)
+		http.Error(rw, marshalError("DENIED", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return nil, false
+	}
+	required.Storage += size
+	return required, true
+}
+
+// manifestRequired returns the resources required by pushing the manifest in request
+func (qh quotaHandler) manifestRequired(rw http.ResponseWriter, req *http.Request, repository, reference string) (*models.QuotaResources, bool) {
+	project, err := qh.project(repository)
+	if err != nil {
+		log.Errorf("Failed to get the project of %s, error: %v", repository, err)
+		http.Error(rw, marshalError("DENIED", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return nil, false
+	}
+	if project == nil {
+		return nil, true
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		log.Errorf("Failed to read the manifest of %s:%s, error: %v", repository, reference, err)
+		http.Error(rw, marshalError("MANIFEST_INVALID", fmt.Sprintf("Failed to read the manifest: %v", err)), http.StatusBadRequest)
+		return nil, false
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	manifest, descriptor, err := registry.UnMarshal(req.Header.Get(http.CanonicalHeaderKey("Content-Type")), data)
+	if err != nil {
+		// leave the invalid manifest to registry
+		log.Debugf("Failed to unmarshal the manifest of %s:%s, error: %v", repository, reference, err)
+		return nil, true
+	}
+
+	tag := reference
+	if isDigest(reference) {
+		tag = ""
+	}
+	required, err := quota.Required(project.ProjectID, repository, tag, descriptor.Digest.String(), quota.ImageSize(manifest, data))
+	if err != nil {
+		log.Errorf("Failed to get the quota required by %s:%s, error: %v", repository, reference, err)
+		http.Error(rw, marshalError("DENIED", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+		return nil, false
+	}
+	return required, true
+}
+
+// check writes the error to response and returns false if the quota of the project is exceeded
+func (qh quotaHandler) check(rw http.ResponseWriter, repository string, required *models.QuotaResources) bool {
+	project, err := qh.project(repository)
+	if err == nil && project != nil {
+		err = quota.Check(project.ProjectID, required)
+	}
+	if err == nil {
+		return true
+	}
+	if quota.IsExceededError(err) {
+		log.Warningf("The quota of the project of %s is exceeded: %v", repository, err)
+		http.Error(rw, marshalError("QUOTA_EXCEEDED", fmt.Sprintf("The quota of the project is exceeded, %v", err)), http.StatusForbidden)
+		return false
+	}
+	log.Errorf("Failed to check the quota of the project of %s, error: %v", repository, err)
+	http.Error(rw, marshalError("DENIED", fmt.Sprintf("Failed due to internal Error: %v", err)), http.StatusInternalServerError)
+	return false
+}
+
+func (qh quotaHandler) project(repository string) (*models.Project, error) {
+	components := strings.SplitN(repository, "/", 2)
+	if len(components) < 2 {
+		return nil, nil
+	}
+	return config.GlobalProjectMgr.Get(components[0])
+}
+
 type listReposHandler struct {
 	next http.Handler
 }
@@ -258,7 +483,7 @@ type vulnerableHandler struct {
 
 func (vh vulnerableHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	imgRaw := req.Context().Value(imageInfoCtxKey)
-	if imgRaw == nil || !config.WithClair() {
+	if imgRaw == nil || !coreutils.ScanEnabled() {
 		vh.next.ServeHTTP(rw, req)
 		return
 	}
@@ -285,6 +510,15 @@ func (vh vulnerableHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 		return
 	}
 	imageSev := overview.Sev
+	if imageSev >= int(projectVulnerableSeverity) {
+		// exclude the vulnerabilities in the CVE allowlists
+		imageSev, err = allowlistedSeverity(img.projectName, overview)
+		if err != nil {
+			log.Errorf("failed to get the vulnerabilities of repo: %s, reference: %s, digest: %s. Error: %v", img.repository, img.reference, img.digest, err)
+			http.Error(rw, marshalError("PROJECT_POLICY_VIOLATION", "Failed to get the vulnerabilities of the image."), http.StatusPreconditionFailed)
+			return
+		}
+	}
 	if imageSev >= int(projectVulnerableSeverity) {
 		log.Debugf("the image severity: %q is higher then project setting: %q, failing the response.", models.Severity(imageSev), projectVulnerableSeverity)
 		http.Error(rw, marshalError("PROJECT_POLICY_VIOLATION", fmt.Sprintf("The severity of vulnerability of the image: %q is equal or higher than the threshold in project setting: %q.", models.Severity(imageSev), projectVulnerableSeverity)), http.StatusPreconditionFailed)
@@ -293,6 +527,20 @@ func (vh vulnerableHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	vh.next.ServeHTTP(rw, req)
 }
 
+// allowlistedSeverity returns the severity of the image excluding the vulnerabilities in the CVE allowlists,
+// the severity in the overview is returned if no vulnerability is allowlisted
+func allowlistedSeverity(projectName string, overview *models.ImgScanOverview) (int, error) {
+	ids := getPolicyChecker().cveAllowlist(projectName)
+	if len(ids) == 0 || len(overview.DetailsKey) == 0 {
+		return overview.Sev, nil
+	}
+	report, err := coreutils.GetScanReport(overview)
+	if err != nil {
+		return 0, err
+	}
+	return int(report.MaxSeverity(ids)), nil
+}
+
 func matchNotaryDigest(img imageInfo) (bool, error) {
 	if NotaryEndpoint == "" {
 		NotaryEndpoint = config.InternalNotaryEndpoint()
@@ -0,0 +1,334 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/common/utils/registry/auth"
+	"github.com/goharbor/harbor/src/core/config"
+	tokensvc "github.com/goharbor/harbor/src/core/service/token"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+)
+
+// the media types of the manifests cached from the upstream registry
+var cachedManifestTypes = []string{
+	manifestlist.MediaTypeManifestList,
+	schema2.MediaTypeManifest,
+	schema1.MediaTypeSignedManifest,
+}
+
+var (
+	// localRepository returns the client of the repository in the local registry
+	localRepository = func(repository string) (*registry.Repository, error) {
+		return coreutils.NewRepositoryClientForUI(tokenUsername, repository)
+	}
+	// upstreamRepository returns the client of the repository in the upstream registry
+	upstreamRepository = newUpstreamRepository
+	// verifyToken verifies the bearer token issued by core
+	verifyToken = tokensvc.VerifyToken
+	// the locks serializing the fetches of the same reference
+	fetchLocks = &referenceLocks{locks: map[string]*referenceLock{}}
+)
+
+// referenceLocks holds a lock for each reference being fetched, so fetching a large image
+// doesn't block the fetches of the others
+type referenceLocks struct {
+	lock  sync.Mutex
+	locks map[string]*referenceLock
+}
+
+type referenceLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// acquire locks the reference and returns the function releasing it
+func (r *referenceLocks) acquire(reference string) func() {
+	r.lock.Lock()
+	l, exist := r.locks[reference]
+	if !exist {
+		l = &referenceLock{}
+		r.locks[reference] = l
+	}
+	l.waiters++
+	r.lock.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		r.lock.Lock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(r.locks, reference)
+		}
+		r.lock.Unlock()
+	}
+}
+
+// proxyCacheHandler makes the proxy-cache projects act as pull-through caches of the upstream registries.
+// On pulling a manifest, the manifest missing locally or the tag expired is fetched with the blobs from
+// the upstream registry and pushed to the local registry before the request is served by the local registry.
+// Only the requests carrying a token which grants pulling the repository trigger the fetches, so the anonymous
+// requests can't consume the rate limit of the upstream registries with the stored credentials.
+// The cached content is served when the upstream registry is unavailable, and the pushes are rejected
+// as the content of the proxy-cache projects is owned by the upstream registries.
+type proxyCacheHandler struct {
+	next http.Handler
+}
+
+func (ph proxyCacheHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	repository := ""
+	if flag, repo := MatchBlobUpload(req); flag {
+		repository = repo
+	} else if flag, repo, _ := MatchPushManifest(req); flag {
+		repository = repo
+	}
+	if len(repository) > 0 {
+		if project := proxyCacheProject(repository); project != nil {
+			log.Warningf("The push to %s is rejected as project %s is a proxy cache", repository, project.Name)
+			http.Error(rw, marshalError("DENIED", fmt.Sprintf("Project %s is a proxy cache, pushing is not allowed.", project.Name)), http.StatusForbidden)
+			return
+		}
+		ph.next.ServeHTTP(rw, req)
+		return
+	}
+
+	flag, repository, reference := matchGetManifest(req)
+	if !flag || config.ReadOnly() {
+		ph.next.ServeHTTP(rw, req)
+		return
+	}
+	project := proxyCacheProject(repository)
+	if project == nil {
+		ph.next.ServeHTTP(rw, req)
+		return
+	}
+	// the unauthorized request is refused by the registry without fetching anything
+	if !authorizedToPull(req, repository) {
+		log.Debugf("The request to %s:%s isn't authorized to pull, skip fetching from the upstream registry", repository, reference)
+		ph.next.ServeHTTP(rw, req)
+		return
+	}
+	// the errors are logged and the request is served with the content cached,
+	// which results in a 404 if nothing is cached
+	if err := fetch(project, repository, reference); err != nil {
+		log.Errorf("Failed to fetch %s:%s from the upstream registry of project %s, error: %v", repository, reference, project.Name, err)
+	}
+	ph.next.ServeHTTP(rw, req)
+}
+
+// matchGetManifest checks whether the request gets or heads a manifest, it returns the repository and the tag/digest
+func matchGetManifest(req *http.Request) (bool, string, string) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false, "", ""
+	}
+	s := regexp.MustCompile(manifestURLPattern).FindStringSubmatch(req.URL.Path)
+	if len(s) != 3 {
+		return false, "", ""
+	}
+	return true, strings.TrimSuffix(s[1], "/"), s[2]
+}
+
+// authorizedToPull checks whether the bearer token of the request is issued for the registry and grants pulling the repository
+func authorizedToPull(req *http.Request, repository string) bool {
+	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return false
+	}
+	tk, err := verifyToken(strings.TrimSpace(parts[1]), tokensvc.Registry)
+	if err != nil {
+		log.Debugf("Invalid token to pull %s: %v", repository, err)
+		return false
+	}
+	for _, access := range tk.Claims.Access {
+		if access.Type != "repository" || access.Name != repository {
+			continue
+		}
+		for _, action := range access.Actions {
+			if action == "pull" || action == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// proxyCacheProject returns the project of the repository if it's a proxy cache, otherwise nil
+func proxyCacheProject(repository string) *models.Project {
+	projectName, _ := utils.ParseRepository(repository)
+	if len(projectName) == 0 {
+		return nil
+	}
+	project, err := config.GlobalProjectMgr.Get(projectName)
+	if err != nil {
+		log.Errorf("Failed to get the project %s, error: %v", projectName, err)
+		return nil
+	}
+	if project == nil || !project.IsProxyCache() {
+		return nil
+	}
+	return project
+}
+
+// fetch makes sure the manifest of the reference is cached in the local registry, the tag is revalidated against the
+// upstream registry if it isn't cached or it's expired, the digest is fetched only if it isn't cached
+func fetch(project *models.Project, repository, reference string) error {
+	release := fetchLocks.acquire(repository + ":" + reference)
+	defer release()
+
+	local, err := localRepository(repository)
+	if err != nil {
+		return err
+	}
+	_, cached, err := local.ManifestExist(reference)
+	if err != nil {
+		return err
+	}
+
+	var record *models.ProxyCacheTag
+	if isDigest(reference) {
+		// the content of a digest never changes
+		if cached {
+			return nil
+		}
+	} else {
+		record, err = dao.GetProxyCacheTag(repository, reference)
+		if err != nil {
+			return err
+		}
+		if cached && record != nil && !record.Expired(project.ProxyCacheTTL(), time.Now()) {
+			return nil
+		}
+	}
+
+	upstream, err := upstreamRepository(project, strings.TrimPrefix(repository, project.Name+"/"))
+	if err != nil {
+		return err
+	}
+	digest, exist, err := upstream.ManifestExist(reference)
+	if err != nil || !exist {
+		if cached {
+			log.Warningf("Failed to revalidate %s:%s against the upstream registry, the cached one is served, error: %v", repository, reference, err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%s not found in the upstream registry", reference)
+	}
+
+	if !(cached && record != nil && record.Digest == digest) {
+		log.Infof("Caching %s:%s from the upstream registry of project %s", repository, reference, project.Name)
+		if err := copyManifest(upstream, local, reference); err != nil {
+			return err
+		}
+	}
+	if isDigest(reference) {
+		return nil
+	}
+	return dao.SetProxyCacheTag(&models.ProxyCacheTag{
+		ProjectID:  project.ProjectID,
+		Repository: repository,
+		Tag:        reference,
+		Digest:     digest,
+	})
+}
+
+// copyManifest copies the manifest with the blobs and the manifests it references from the source
+// repository to the destination, the manifest is pushed with the reference
+func copyManifest(src, dst *registry.Repository, reference string) error {
+	_, mediaType, payload, err := src.PullManifest(reference, cachedManifestTypes)
+	if err != nil {
+		return err
+	}
+	manifest, _, err := registry.UnMarshal(mediaType, payload)
+	if err != nil {
+		return err
+	}
+	for _, d := range manifest.References() {
+		switch d.MediaType {
+		case manifestlist.MediaTypeManifestList, schema2.MediaTypeManifest, schema1.MediaTypeSignedManifest, schema1.MediaTypeManifest:
+			// the manifests referenced by the manifest list
+			if err := copyManifest(src, dst, d.Digest.String()); err != nil {
+				return err
+			}
+		default:
+			if err := copyBlob(src, dst, d.Digest.String()); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = dst.PushManifest(reference, mediaType, payload)
+	return err
+}
+
+// copyBlob copies the blob from the source repository to the destination if the destination doesn't have it
+func copyBlob(src, dst *registry.Repository, digest string) error {
+	exist, err := dst.BlobExist(digest)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return nil
+	}
+	size, data, err := src.PullBlob(digest)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+	return dst.PushBlob(digest, size, data)
+}
+
+// newUpstreamRepository returns the client of the repository in the replication target cached by the project
+func newUpstreamRepository(project *models.Project, repository string) (*registry.Repository, error) {
+	target, err := dao.GetRepTarget(project.ProxyCacheTargetID())
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, fmt.Errorf("the replication target %d of project %s not found", project.ProxyCacheTargetID(), project.Name)
+	}
+	password := target.Password
+	if len(password) > 0 {
+		key, err := config.SecretKey()
+		if err != nil {
+			return nil, err
+		}
+		if password, err = utils.ReversibleDecrypt(password, key); err != nil {
+			return nil, err
+		}
+	}
+	transport := registry.GetHTTPTransport(target.Insecure)
+	authorizer := auth.NewStandardTokenAuthorizer(&http.Client{
+		Transport: transport,
+	}, auth.NewBasicAuthCredential(target.Username, password))
+	return registry.NewRepository(repository, target.URL, &http.Client{
+		Transport: registry.NewTransport(transport, authorizer),
+	})
+}
@@ -0,0 +1,248 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/manifest/schema2"
+	regtoken "github.com/docker/distribution/registry/auth/token"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry is an in-memory registry serving the manifests and blobs of a single repository
+type fakeRegistry struct {
+	sync.Mutex
+	manifests map[string][]byte
+	types     map[string]string
+	blobs     map[string][]byte
+	pushed    []string
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{
+		manifests: map[string][]byte{},
+		types:     map[string]string{},
+		blobs:     map[string][]byte{},
+	}
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+	path := req.URL.Path
+	switch {
+	case strings.Contains(path, "/manifests/"):
+		reference := path[strings.LastIndex(path, "/")+1:]
+		switch req.Method {
+		case http.MethodHead, http.MethodGet:
+			payload, ok := f.manifests[reference]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", f.types[reference])
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(payload).String())
+			w.WriteHeader(http.StatusOK)
+			if req.Method == http.MethodGet {
+				w.Write(payload)
+			}
+		case http.MethodPut:
+			payload, _ := ioutil.ReadAll(req.Body)
+			f.manifests[reference] = payload
+			f.types[reference] = req.Header.Get("Content-Type")
+			f.pushed = append(f.pushed, reference)
+			w.WriteHeader(http.StatusCreated)
+		}
+	case strings.HasSuffix(path, "/blobs/uploads/") && req.Method == http.MethodPost:
+		w.Header().Set("Location", "/v2/upload")
+		w.WriteHeader(http.StatusAccepted)
+	case path == "/v2/upload" && req.Method == http.MethodPut:
+		data, _ := ioutil.ReadAll(req.Body)
+		f.blobs[req.URL.Query().Get("digest")] = data
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		data, ok := f.blobs[path[strings.LastIndex(path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeRegistry) addBlob(data string) string {
+	d := digest.FromString(data).String()
+	f.blobs[d] = []byte(data)
+	return d
+}
+
+func TestCopyManifest(t *testing.T) {
+	upstream := newFakeRegistry()
+	config := upstream.addBlob("config")
+	layer := upstream.addBlob("layer")
+	manifest := map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     schema2.MediaTypeManifest,
+		"config": map[string]interface{}{
+			"mediaType": schema2.MediaTypeConfig,
+			"size":      6,
+			"digest":    config,
+		},
+		"layers": []map[string]interface{}{
+			{
+				"mediaType": schema2.MediaTypeLayer,
+				"size":      5,
+				"digest":    layer,
+			},
+		},
+	}
+	payload, err := json.Marshal(manifest)
+	require.Nil(t, err)
+	upstream.manifests["latest"] = payload
+	upstream.types["latest"] = schema2.MediaTypeManifest
+
+	local := newFakeRegistry()
+	upstreamServer := httptest.NewServer(upstream)
+	defer upstreamServer.Close()
+	localServer := httptest.NewServer(local)
+	defer localServer.Close()
+
+	src, err := registry.NewRepository("library/hello-world", upstreamServer.URL, http.DefaultClient)
+	require.Nil(t, err)
+	dst, err := registry.NewRepository("cache/library/hello-world", localServer.URL, http.DefaultClient)
+	require.Nil(t, err)
+
+	require.Nil(t, copyManifest(src, dst, "latest"))
+	assert.Equal(t, []byte("config"), local.blobs[config])
+	assert.Equal(t, []byte("layer"), local.blobs[layer])
+	assert.Equal(t, payload, local.manifests["latest"])
+	assert.Equal(t, schema2.MediaTypeManifest, local.types["latest"])
+
+	// the digest of the cached manifest is the same as the upstream one
+	d1, exist, err := src.ManifestExist("latest")
+	require.Nil(t, err)
+	require.True(t, exist)
+	d2, exist, err := dst.ManifestExist("latest")
+	require.Nil(t, err)
+	require.True(t, exist)
+	assert.Equal(t, d1, d2)
+
+	assert.NotNil(t, copyManifest(src, dst, "unknown"))
+}
+
+func TestMatchGetManifest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodHead, "http://127.0.0.1:5000/v2/cache/library/ubuntu/manifests/14.04", nil)
+	flag, repository, reference := matchGetManifest(req)
+	assert.True(t, flag)
+	assert.Equal(t, "cache/library/ubuntu", repository)
+	assert.Equal(t, "14.04", reference)
+
+	req, _ = http.NewRequest(http.MethodPut, "http://127.0.0.1:5000/v2/cache/library/ubuntu/manifests/14.04", nil)
+	flag, _, _ = matchGetManifest(req)
+	assert.False(t, flag)
+}
+
+func TestAuthorizedToPull(t *testing.T) {
+	verify := verifyToken
+	defer func() {
+		verifyToken = verify
+	}()
+	verifyToken = func(rawToken, service string) (*regtoken.Token, error) {
+		if rawToken != "valid" {
+			return nil, fmt.Errorf("invalid token")
+		}
+		return &regtoken.Token{
+			Claims: &regtoken.ClaimSet{
+				Access: []*regtoken.ResourceActions{
+					{Type: "repository", Name: "cache/library/ubuntu", Actions: []string{"pull"}},
+					{Type: "repository", Name: "cache/library/busybox", Actions: []string{"push"}},
+				},
+			},
+		}, nil
+	}
+
+	cases := []struct {
+		auth       string
+		repository string
+		expected   bool
+	}{
+		{"", "cache/library/ubuntu", false},
+		{"Basic dXNlcjpwYXNz", "cache/library/ubuntu", false},
+		{"Bearer invalid", "cache/library/ubuntu", false},
+		{"Bearer valid", "cache/library/ubuntu", true},
+		{"Bearer valid", "cache/library/busybox", false},
+		{"Bearer valid", "cache/library/alpine", false},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:5000/v2/"+c.repository+"/manifests/latest", nil)
+		if len(c.auth) > 0 {
+			req.Header.Set("Authorization", c.auth)
+		}
+		assert.Equal(t, c.expected, authorizedToPull(req, c.repository), "%s %s", c.auth, c.repository)
+	}
+}
+
+func TestReferenceLocks(t *testing.T) {
+	locks := &referenceLocks{locks: map[string]*referenceLock{}}
+	release := locks.acquire("library/ubuntu:14.04")
+
+	// another reference isn't blocked by the one being fetched
+	acquired := make(chan struct{})
+	go func() {
+		locks.acquire("library/ubuntu:16.04")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the lock of another reference should not be blocked")
+	}
+
+	// the same reference is blocked until it's released
+	acquired = make(chan struct{})
+	go func() {
+		locks.acquire("library/ubuntu:14.04")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("the lock of the same reference should be blocked")
+	case <-time.After(100 * time.Millisecond):
+	}
+	release()
+	<-acquired
+
+	locks.lock.Lock()
+	defer locks.lock.Unlock()
+	assert.Equal(t, 0, len(locks.locks))
+}
@@ -0,0 +1,278 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quota enforces the hard limits of the storage and tag count of projects.
+// The usage is accounted with the push and delete notifications of registry.
+package quota
+
+import (
+	"fmt"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/core/config"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+)
+
+const (
+	// ResourceStorage : the storage of the project in bytes
+	ResourceStorage = "storage"
+	// ResourceCount : the number of tags of the project
+	ResourceCount = "count"
+)
+
+// ExceededError is returned when the quota of the project is exceeded
+type ExceededError struct {
+	Resource string
+	Limit    int64
+	Used     int64
+	Required int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded: %s limit %d, used %d, required %d", e.Resource, e.Limit, e.Used, e.Required)
+}
+
+// IsExceededError returns whether the error is caused by exceeding the quota
+func IsExceededError(err error) bool {
+	_, ok := err.(*ExceededError)
+	return ok
+}
+
+// GetLimits returns the hard limits of the project, the system default quotas are
+// returned if the limits aren't set for the project
+func GetLimits(projectID int64) (*models.QuotaResources, error) {
+	quota, err := dao.GetProjectQuota(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		return &models.QuotaResources{
+			Storage: quota.StorageLimit,
+			Count:   quota.CountLimit,
+		}, nil
+	}
+
+	storage, err := config.StoragePerProject()
+	if err != nil {
+		return nil, err
+	}
+	count, err := config.CountPerProject()
+	if err != nil {
+		return nil, err
+	}
+	return &models.QuotaResources{
+		Storage: storage,
+		Count:   count,
+	}, nil
+}
+
+// Check returns an ExceededError if the project doesn't have enough quota for the
+// resources required
+func Check(projectID int64, required *models.QuotaResources) error {
+	limits, err := GetLimits(projectID)
+	if err != nil {
+		return err
+	}
+	if limits.Storage == common.QuotaUnlimited && limits.Count == common.QuotaUnlimited {
+		return nil
+	}
+
+	usage, err := dao.GetProjectUsage(projectID)
+	if err != nil {
+		return err
+	}
+	return check(limits, usage, required)
+}
+
+func check(limits, usage, required *models.QuotaResources) error {
+	if limits.Storage != common.QuotaUnlimited && usage.Storage+required.Storage > limits.Storage {
+		return &ExceededError{
+			Resource: ResourceStorage,
+			Limit:    limits.Storage,
+			Used:     usage.Storage,
+			Required: required.Storage,
+		}
+	}
+	if limits.Count != common.QuotaUnlimited && usage.Count+required.Count > limits.Count {
+		return &ExceededError{
+			Resource: ResourceCount,
+			Limit:    limits.Count,
+			Used:     usage.Count,
+			Required: required.Count,
+		}
+	}
+	return nil
+}
+
+// Required returns the resources required by pushing the manifest to the tag,
+// leave the tag empty if the manifest is pushed by digest
+func Required(projectID int64, repository, tag, digest string, size int64) (*models.QuotaResources, error) {
+	required := &models.QuotaResources{}
+	if len(tag) > 0 {
+		artifact, err := dao.GetArtifact(repository, tag)
+		if err != nil {
+			return nil, err
+		}
+		if artifact == nil {
+			required.Count = 1
+		}
+	}
+	if !dao.ArtifactDigestExists(projectID, digest) {
+		required.Storage = size
+	}
+	return required, nil
+}
+
+// ImageSize returns the size of the image, which is the sum of the manifest, config and layers
+func ImageSize(manifest distribution.Manifest, payload []byte) int64 {
+	size := int64(len(payload))
+	for _, descriptor := range manifest.References() {
+		size += descriptor.Size
+	}
+	return size
+}
+
+// ImageSizeOf pulls the manifest from registry and returns the size of the image
+func ImageSizeOf(repository, digest string) (int64, error) {
+	client, err := coreutils.NewRepositoryClientForUI("harbor-core", repository)
+	if err != nil {
+		return 0, err
+	}
+	return imageSize(client, digest)
+}
+
+func imageSize(client *registry.Repository, digest string) (int64, error) {
+	accepted := []string{schema1.MediaTypeManifest, schema2.MediaTypeManifest}
+	_, mediaType, payload, err := client.PullManifest(digest, accepted)
+	if err != nil {
+		return 0, err
+	}
+	manifest, _, err := registry.UnMarshal(mediaType, payload)
+	if err != nil {
+		return 0, err
+	}
+	return ImageSize(manifest, payload), nil
+}
+
+// UploadedSize returns the size of the data uploaded to the blob upload session of the repository
+func UploadedSize(repository, location string) (int64, error) {
+	client, err := coreutils.NewRepositoryClientForUI("harbor-core", repository)
+	if err != nil {
+		return 0, err
+	}
+	return client.BlobUploadSize(location)
+}
+
+// OnPush accounts the image pushed to the project
+func OnPush(projectID int64, repository, tag, digest string) error {
+	size, err := ImageSizeOf(repository, digest)
+	if err != nil {
+		return err
+	}
+
+	artifact := &models.Artifact{
+		ProjectID: projectID,
+		Repo:      repository,
+		Tag:       tag,
+		Digest:    digest,
+		Size:      size,
+	}
+	log.Debugf("account the artifact %s:%s, digest: %s, size: %d", repository, tag, digest, artifact.Size)
+	return dao.AddOrUpdateArtifact(artifact)
+}
+
+// Backfill accounts the tags pushed before the quota usage is accounted. The tags already
+// accounted are skipped, so it's safe to run it every time the core starts
+func Backfill() {
+	repositories, err := dao.GetRepositories()
+	if err != nil {
+		log.Errorf("failed to get the repositories to backfill the quota usage: %v", err)
+		return
+	}
+
+	var n int
+	for _, repository := range repositories {
+		added, err := backfill(repository)
+		n += added
+		if err != nil {
+			log.Errorf("failed to backfill the quota usage of %s: %v", repository.Name, err)
+		}
+	}
+	log.Infof("%d artifacts are backfilled into the quota usage", n)
+}
+
+// backfill accounts the tags of the repository which aren't accounted yet, and returns the count of them
+func backfill(repository *models.RepoRecord) (int, error) {
+	client, err := coreutils.NewRepositoryClientForUI("harbor-core", repository.Name)
+	if err != nil {
+		return 0, err
+	}
+	tags, err := client.ListTag()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, tag := range tags {
+		artifact, err := dao.GetArtifact(repository.Name, tag)
+		if err != nil {
+			return n, err
+		}
+		if artifact != nil {
+			continue
+		}
+
+		digest, exist, err := client.ManifestExist(tag)
+		if err != nil {
+			return n, err
+		}
+		if !exist {
+			continue
+		}
+		size, err := imageSize(client, digest)
+		if err != nil {
+			return n, err
+		}
+
+		artifact = &models.Artifact{
+			ProjectID: repository.ProjectID,
+			Repo:      repository.Name,
+			Tag:       tag,
+			Digest:    digest,
+			Size:      size,
+		}
+		if err := dao.AddOrUpdateArtifact(artifact); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// OnDelete releases the quota of the tags referring to the manifest deleted
+func OnDelete(repository, digest string) error {
+	n, err := dao.DeleteArtifactsByDigest(repository, digest)
+	if err != nil {
+		return err
+	}
+	log.Debugf("%d artifacts of %s referring to %s are released", n, repository, digest)
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package quota
+
+import (
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	usage := &models.QuotaResources{Storage: 100, Count: 2}
+	cases := []struct {
+		limits   *models.QuotaResources
+		required *models.QuotaResources
+		resource string
+	}{
+		{
+			limits:   &models.QuotaResources{Storage: common.QuotaUnlimited, Count: common.QuotaUnlimited},
+			required: &models.QuotaResources{Storage: 1 << 40, Count: 1000},
+		},
+		{
+			limits:   &models.QuotaResources{Storage: 200, Count: 3},
+			required: &models.QuotaResources{Storage: 100, Count: 1},
+		},
+		{
+			limits:   &models.QuotaResources{Storage: 200, Count: common.QuotaUnlimited},
+			required: &models.QuotaResources{Storage: 101, Count: 1},
+			resource: ResourceStorage,
+		},
+		{
+			limits:   &models.QuotaResources{Storage: common.QuotaUnlimited, Count: 2},
+			required: &models.QuotaResources{Storage: 0, Count: 1},
+			resource: ResourceCount,
+		},
+		// overwriting the tag doesn't require more count
+		{
+			limits:   &models.QuotaResources{Storage: common.QuotaUnlimited, Count: 2},
+			required: &models.QuotaResources{Storage: 10, Count: 0},
+		},
+	}
+
+	for _, c := range cases {
+		err := check(c.limits, usage, c.required)
+		if len(c.resource) == 0 {
+			assert.Nil(t, err)
+			continue
+		}
+		require.True(t, IsExceededError(err))
+		assert.Equal(t, c.resource, err.(*ExceededError).Resource)
+	}
+}
+
+func TestImageSize(t *testing.T) {
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeConfig,
+			Size:      100,
+			Digest:    "sha256:c",
+		},
+		Layers: []distribution.Descriptor{
+			{MediaType: schema2.MediaTypeLayer, Size: 1000, Digest: "sha256:l1"},
+			{MediaType: schema2.MediaTypeLayer, Size: 2000, Digest: "sha256:l2"},
+		},
+	})
+	require.Nil(t, err)
+	_, payload, err := manifest.Payload()
+	require.Nil(t, err)
+
+	assert.Equal(t, int64(3100+len(payload)), ImageSize(manifest, payload))
+}
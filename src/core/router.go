@@ -37,6 +37,8 @@ func initRouters() {
 		beego.Router("/c/reset", &controllers.CommonController{}, "post:ResetPassword")
 		beego.Router("/c/userExists", &controllers.CommonController{}, "post:UserExists")
 		beego.Router("/c/sendEmail", &controllers.CommonController{}, "get:SendResetEmail")
+		beego.Router("/c/oidc/login", &controllers.OIDCController{}, "get:Login")
+		beego.Router("/c/oidc/callback", &controllers.OIDCController{}, "get:Callback")
 
 		// API:
 		beego.Router("/api/projects/:pid([0-9]+)/members/?:pmid([0-9]+)", &api.ProjectMemberAPI{})
@@ -47,6 +49,12 @@ func initRouters() {
 		beego.Router("/api/users", &api.UserAPI{}, "get:List;post:Post")
 		beego.Router("/api/users/:id([0-9]+)/password", &api.UserAPI{}, "put:ChangePassword")
 		beego.Router("/api/users/:id/sysadmin", &api.UserAPI{}, "put:ToggleUserAdminRole")
+		beego.Router("/api/users/:id([0-9]+)/unlock", &api.UserAPI{}, "put:Unlock")
+		beego.Router("/api/users/:id/cli_secret", &api.UserAPI{}, "post:GenerateCLISecret")
+		beego.Router("/api/users/:id([0-9]+)/totp", &api.UserAPI{}, "get:GetTOTP;post:EnrollTOTP;put:EnableTOTP;delete:DisableTOTP")
+		beego.Router("/api/users/:id([0-9]+)/totp/recovery_codes", &api.UserAPI{}, "post:RegenerateRecoveryCodes")
+		beego.Router("/api/users/:id([0-9]+)/tokens", &api.PersonalAccessTokenAPI{}, "get:List;post:Post")
+		beego.Router("/api/users/:id([0-9]+)/tokens/:tid([0-9]+)", &api.PersonalAccessTokenAPI{}, "get:Get;delete:Delete")
 		beego.Router("/api/usergroups/?:ugid([0-9]+)", &api.UserGroupAPI{})
 		beego.Router("/api/ldap/ping", &api.LdapAPI{}, "post:Ping")
 		beego.Router("/api/ldap/users/search", &api.LdapAPI{}, "get:Search")
@@ -61,13 +69,29 @@ func initRouters() {
 	beego.Router("/api/search", &api.SearchAPI{})
 	beego.Router("/api/projects/", &api.ProjectAPI{}, "get:List;post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/logs", &api.ProjectAPI{}, "get:Logs")
+	beego.Router("/api/projects/:id([0-9]+)/logs/export", &api.ProjectAPI{}, "get:ExportLogs")
 	beego.Router("/api/projects/:id([0-9]+)/_deletable", &api.ProjectAPI{}, "get:Deletable")
+	beego.Router("/api/projects/:id([0-9]+)/summary", &api.ProjectAPI{}, "get:Summary")
+	beego.Router("/api/projects/:id([0-9]+)/quota", &api.ProjectAPI{}, "put:PutQuota;delete:DeleteQuota")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/?:name", &api.MetadataAPI{}, "get:Get")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/", &api.MetadataAPI{}, "post:Post")
 	beego.Router("/api/projects/:id([0-9]+)/metadatas/:name", &api.MetadataAPI{}, "put:Put;delete:Delete")
 
 	beego.Router("/api/projects/:pid([0-9]+)/robots", &api.RobotAPI{}, "post:Post;get:List")
 	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)", &api.RobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/robots/:id([0-9]+)/token", &api.RobotAPI{}, "post:RefreshToken")
+	beego.Router("/api/robots", &api.RobotAPI{}, "post:Post;get:List")
+	beego.Router("/api/robots/:id([0-9]+)", &api.RobotAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/robots/:id([0-9]+)/token", &api.RobotAPI{}, "post:RefreshToken")
+	beego.Router("/api/projects/:pid([0-9]+)/retention", &api.RetentionAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/retention/executions", &api.RetentionAPI{}, "post:Run;get:ListExecutions")
+	beego.Router("/api/projects/:pid([0-9]+)/retention/executions/:id([0-9]+)", &api.RetentionAPI{}, "get:GetExecution")
+	beego.Router("/api/projects/:pid([0-9]+)/retention/executions/:id([0-9]+)/log", &api.RetentionAPI{}, "get:GetExecutionLog")
+	beego.Router("/api/projects/:pid([0-9]+)/cve_allowlist", &api.CVEAllowlistAPI{}, "get:Get;put:Put")
+	beego.Router("/api/projects/:pid([0-9]+)/scanner", &api.ProjectScannerAPI{}, "get:Get;put:Put")
+	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies", &api.WebhookAPI{}, "get:List;post:Post")
+	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/:id([0-9]+)", &api.WebhookAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/projects/:pid([0-9]+)/webhook/policies/:id([0-9]+)/executions", &api.WebhookAPI{}, "get:ListExecutions")
 
 	beego.Router("/api/repositories", &api.RepositoryAPI{}, "get:Get")
 	beego.Router("/api/repositories/scanAll", &api.RepositoryAPI{}, "post:ScanAll")
@@ -92,6 +116,17 @@ func initRouters() {
 	beego.Router("/api/system/gc/:id", &api.GCAPI{}, "get:GetGC")
 	beego.Router("/api/system/gc/:id([0-9]+)/log", &api.GCAPI{}, "get:GetLog")
 	beego.Router("/api/system/gc/schedule", &api.GCAPI{}, "get:Get;put:Put;post:Post")
+	beego.Router("/api/system/purgeaudit", &api.AuditLogPurgeAPI{}, "get:List")
+	beego.Router("/api/system/purgeaudit/:id([0-9]+)", &api.AuditLogPurgeAPI{}, "get:GetGC")
+	beego.Router("/api/system/purgeaudit/:id([0-9]+)/log", &api.AuditLogPurgeAPI{}, "get:GetLog")
+	beego.Router("/api/system/purgeaudit/schedule", &api.AuditLogPurgeAPI{}, "get:Get;put:Put;post:Post")
+	beego.Router("/api/system/cve_allowlist", &api.CVEAllowlistAPI{}, "get:Get;put:Put")
+	beego.Router("/api/scanners", &api.ScannerAPI{}, "get:List;post:Post")
+	beego.Router("/api/scanners/:id([0-9]+)", &api.ScannerAPI{}, "get:Get;put:Put;delete:Delete")
+	beego.Router("/api/scanners/:id([0-9]+)/default", &api.ScannerAPI{}, "post:SetDefault")
+	beego.Router("/api/scanners/:id([0-9]+)/metadata", &api.ScannerAPI{}, "get:Metadata")
+	beego.Router("/api/roles", &api.RoleAPI{}, "get:List;post:Post")
+	beego.Router("/api/roles/:id([0-9]+)", &api.RoleAPI{}, "get:Get;put:Put;delete:Delete")
 
 	beego.Router("/api/policies/replication/:id([0-9]+)", &api.RepPolicyAPI{})
 	beego.Router("/api/policies/replication", &api.RepPolicyAPI{}, "get:List")
@@ -102,6 +137,7 @@ func initRouters() {
 	beego.Router("/api/targets/:id([0-9]+)/policies/", &api.TargetAPI{}, "get:ListPolicies")
 	beego.Router("/api/targets/ping", &api.TargetAPI{}, "post:Ping")
 	beego.Router("/api/logs", &api.LogAPI{})
+	beego.Router("/api/logs/export", &api.LogAPI{}, "get:Export")
 	beego.Router("/api/configs", &api.ConfigAPI{}, "get:GetInternalConfig")
 	beego.Router("/api/configurations", &api.ConfigAPI{})
 	beego.Router("/api/configurations/reset", &api.ConfigAPI{}, "post:Reset")
@@ -124,6 +160,9 @@ func initRouters() {
 	beego.Router("/service/notifications/clair", &clair.Handler{}, "post:Handle")
 	beego.Router("/service/notifications/jobs/scan/:id([0-9]+)", &jobs.Handler{}, "post:HandleScan")
 	beego.Router("/service/notifications/jobs/replication/:id([0-9]+)", &jobs.Handler{}, "post:HandleReplication")
+	beego.Router("/service/notifications/jobs/retention/:id([0-9]+)", &jobs.Handler{}, "post:HandleRetention")
+	beego.Router("/service/notifications/jobs/retention/policy/:id([0-9]+)", &jobs.Handler{}, "post:HandleScheduledRetention")
+	beego.Router("/service/notifications/jobs/webhook/:id([0-9]+)", &jobs.Handler{}, "post:HandleWebhook")
 	beego.Router("/service/notifications/jobs/adminjob/:id([0-9]+)", &admin.Handler{}, "post:HandleAdminJob")
 	beego.Router("/service/token", &token.Handler{})
 
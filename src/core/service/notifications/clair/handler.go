@@ -87,7 +87,16 @@ func (h *Handler) Handle() {
 				log.Errorf("Failed to list scan overview records, error: %v", err)
 				return
 			}
+			builtin, err := builtinScanners()
+			if err != nil {
+				log.Errorf("Failed to list scanner registrations, error: %v", err)
+				return
+			}
 			for _, e := range l {
+				// only the records generated by the built-in Clair are refreshed
+				if !builtin[e.ScannerID] {
+					continue
+				}
 				if err := clair.UpdateScanOverview(e.Digest, e.DetailsKey, config.ClairEndpoint()); err != nil {
 					log.Errorf("Failed to refresh scan overview for image: %s", e.Digest)
 				} else {
@@ -105,3 +114,18 @@ func (h *Handler) Handle() {
 		log.Debugf("Removed notification from Clair, name: %s", ne.Notification.Name)
 	}
 }
+
+// builtinScanners returns the IDs referring to the built-in Clair, 0 is for the records scanned before the scanners are pluggable
+func builtinScanners() (map[int64]bool, error) {
+	regs, err := dao.ListScannerRegistrations()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[int64]bool{0: true}
+	for _, reg := range regs {
+		if reg.IsBuiltin() {
+			ids[reg.ID] = true
+		}
+	}
+	return ids, nil
+}
@@ -21,10 +21,16 @@ import (
 	"github.com/goharbor/harbor/src/common/job"
 	jobmodels "github.com/goharbor/harbor/src/common/job/models"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/api"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/webhook"
 )
 
+// the operator of the webhook events triggered by jobs
+const operatorJobservice = "harbor-jobservice"
+
 var statusMap = map[string]string{
 	job.JobServiceStatusPending:   models.JobPending,
 	job.JobServiceStatusRunning:   models.JobRunning,
@@ -38,8 +44,10 @@ var statusMap = map[string]string{
 // Handler handles reqeust on /service/notifications/jobs/*, which listens to the webhook of jobservice.
 type Handler struct {
 	api.BaseController
-	id     int64
-	status string
+	id      int64
+	uuid    string
+	status  string
+	checkIn string
 }
 
 // Prepare ...
@@ -66,6 +74,8 @@ func (h *Handler) Prepare() {
 		return
 	}
 	h.status = status
+	h.uuid = data.JobID
+	h.checkIn = data.CheckIn
 }
 
 // HandleScan handles the webhook of scan job
@@ -76,6 +86,30 @@ func (h *Handler) HandleScan() {
 		h.HandleInternalServerError(err.Error())
 		return
 	}
+
+	if h.status == models.JobFinished || h.status == models.JobError {
+		h.publishScanEvent()
+	}
+}
+
+func (h *Handler) publishScanEvent() {
+	scanJob, err := dao.GetScanJob(h.id)
+	if err != nil || scanJob == nil {
+		log.Errorf("Failed to get scan job %d, the webhook event is skipped: %v", h.id, err)
+		return
+	}
+	project, err := projectOfRepository(scanJob.Repository)
+	if err != nil || project == nil {
+		log.Errorf("Failed to get the project of %s, the webhook event is skipped: %v", scanJob.Repository, err)
+		return
+	}
+
+	eventType := models.WebhookEventScanningCompleted
+	if h.status == models.JobError {
+		eventType = models.WebhookEventScanningFailed
+	}
+	webhook.Publish(project.ProjectID, webhook.NewImagePayload(eventType, operatorJobservice,
+		scanJob.Repository, scanJob.Tag, scanJob.Digest))
 }
 
 // HandleReplication handles the webhook of replication job
@@ -86,4 +120,129 @@ func (h *Handler) HandleReplication() {
 		h.HandleInternalServerError(err.Error())
 		return
 	}
+
+	if h.status == models.JobFinished {
+		h.publishReplicationEvent()
+	}
+}
+
+func (h *Handler) publishReplicationEvent() {
+	repJob, err := dao.GetRepJob(h.id)
+	if err != nil || repJob == nil {
+		log.Errorf("Failed to get replication job %d, the webhook event is skipped: %v", h.id, err)
+		return
+	}
+	policy, err := dao.GetRepPolicy(repJob.PolicyID)
+	if err != nil || policy == nil {
+		log.Errorf("Failed to get replication policy %d, the webhook event is skipped: %v", repJob.PolicyID, err)
+		return
+	}
+
+	custom := map[string]string{
+		"policy":    policy.Name,
+		"operation": repJob.Operation,
+	}
+	if target, err := dao.GetRepTarget(policy.TargetID); err == nil && target != nil {
+		custom["target"] = target.URL
+	}
+	webhook.Publish(policy.ProjectID, webhook.NewReplicationPayload(operatorJobservice,
+		repJob.Repository, repJob.TagList, custom))
+}
+
+// HandleWebhook handles the webhook of the job delivering the webhook event
+func (h *Handler) HandleWebhook() {
+	log.Debugf("received webhook job status update event: execution-%d, status-%s", h.id, h.status)
+	execution, err := dao.GetWebhookExecution(h.id)
+	if err != nil {
+		log.Errorf("Failed to get webhook execution %d: %v", h.id, err)
+		h.HandleInternalServerError(err.Error())
+		return
+	}
+	if execution == nil {
+		log.Warningf("Webhook execution %d not found, drop the job status update event", h.id)
+		return
+	}
+
+	execution.Status = h.status
+	if err := dao.UpdateWebhookExecution(execution, "Status"); err != nil {
+		log.Errorf("Failed to update webhook execution %d, status: %s: %v", execution.ID, h.status, err)
+		h.HandleInternalServerError(err.Error())
+		return
+	}
+}
+
+func projectOfRepository(repository string) (*models.Project, error) {
+	project, _ := utils.ParseRepository(repository)
+	return config.GlobalProjectMgr.Get(project)
+}
+
+// HandleRetention handles the webhook of retention job run manually
+func (h *Handler) HandleRetention() {
+	log.Debugf("received retention job status update event: execution-%d, status-%s", h.id, h.status)
+	execution, err := dao.GetRetentionExecution(h.id)
+	if err != nil {
+		log.Errorf("Failed to get retention execution %d: %v", h.id, err)
+		h.HandleInternalServerError(err.Error())
+		return
+	}
+	if execution == nil {
+		log.Warningf("Retention execution %d not found, drop the job status update event", h.id)
+		return
+	}
+	h.updateRetentionExecution(execution)
+}
+
+// HandleScheduledRetention handles the webhook of the executions of the periodic retention job,
+// the execution record is created when the first status update event of the execution is received
+func (h *Handler) HandleScheduledRetention() {
+	log.Debugf("received scheduled retention job status update event: policy-%d, job-%s, status-%s", h.id, h.uuid, h.status)
+	policy, err := dao.GetRetentionPolicy(h.id)
+	if err != nil {
+		log.Errorf("Failed to get retention policy %d: %v", h.id, err)
+		h.HandleInternalServerError(err.Error())
+		return
+	}
+	// the events of the periodic job itself are ignored
+	if policy == nil || h.uuid == policy.JobUUID || h.status == models.JobScheduled {
+		return
+	}
+
+	execution, err := dao.GetRetentionExecutionByUUID(h.uuid)
+	if err != nil {
+		log.Errorf("Failed to get retention execution of job %s: %v", h.uuid, err)
+		h.HandleInternalServerError(err.Error())
+		return
+	}
+	if execution == nil {
+		execution = &models.RetentionExecution{
+			PolicyID:  policy.ID,
+			ProjectID: policy.ProjectID,
+			Trigger:   models.RetentionTriggerSchedule,
+			Status:    h.status,
+			JobUUID:   h.uuid,
+		}
+		id, err := dao.AddRetentionExecution(execution)
+		if err != nil {
+			log.Errorf("Failed to add retention execution of job %s: %v", h.uuid, err)
+			h.HandleInternalServerError(err.Error())
+			return
+		}
+		execution.ID = id
+	}
+	h.updateRetentionExecution(execution)
+}
+
+func (h *Handler) updateRetentionExecution(execution *models.RetentionExecution) {
+	props := []string{"Status"}
+	execution.Status = h.status
+	// the report is checked in by the job when it's completed
+	if len(h.checkIn) > 0 {
+		execution.Report = h.checkIn
+		props = append(props, "Report")
+	}
+	if err := dao.UpdateRetentionExecution(execution, props...); err != nil {
+		log.Errorf("Failed to update retention execution %d, status: %s: %v", execution.ID, h.status, err)
+		h.HandleInternalServerError(err.Error())
+		return
+	}
 }
@@ -28,7 +28,9 @@ import (
 	"github.com/goharbor/harbor/src/core/api"
 	"github.com/goharbor/harbor/src/core/config"
 	"github.com/goharbor/harbor/src/core/notifier"
+	"github.com/goharbor/harbor/src/core/quota"
 	coreutils "github.com/goharbor/harbor/src/core/utils"
+	"github.com/goharbor/harbor/src/core/webhook"
 	rep_notification "github.com/goharbor/harbor/src/replication/event/notification"
 	"github.com/goharbor/harbor/src/replication/event/topic"
 )
@@ -78,6 +80,18 @@ func (n *NotificationHandler) Post() {
 			continue
 		}
 
+		// the access log of deletion is recorded by the API, only release the quota and notify the webhooks here
+		if action == "delete" {
+			digest := event.Target.Digest
+			go func() {
+				if err := quota.OnDelete(repository, digest); err != nil {
+					log.Errorf("failed to release the quota of %s@%s: %v", repository, digest, err)
+				}
+			}()
+			webhook.Publish(pro.ProjectID, webhook.NewImagePayload(models.WebhookEventDeleteImage, user, repository, tag, digest))
+			continue
+		}
+
 		go func() {
 			if err := dao.AddAccessLog(models.AccessLog{
 				Username:  user,
@@ -111,6 +125,15 @@ func (n *NotificationHandler) Post() {
 				return
 			}
 
+			digest := event.Target.Digest
+			go func() {
+				if err := quota.OnPush(pro.ProjectID, repository, tag, digest); err != nil {
+					log.Errorf("failed to account the quota usage of %s:%s: %v", repository, tag, err)
+				}
+			}()
+
+			webhook.Publish(pro.ProjectID, webhook.NewImagePayload(models.WebhookEventPushImage, user, repository, tag, digest))
+
 			go func() {
 				image := repository + ":" + tag
 				err := notifier.Publish(topic.ReplicationEventTopicOnPush, rep_notification.OnPushNotification{
@@ -135,6 +158,7 @@ func (n *NotificationHandler) Post() {
 			}
 		}
 		if action == "pull" {
+			webhook.Publish(pro.ProjectID, webhook.NewImagePayload(models.WebhookEventPullImage, user, repository, tag, event.Target.Digest))
 			go func() {
 				log.Debugf("Increase the repository %s pull count.", repository)
 				if err := dao.IncreasePullCount(repository); err != nil {
@@ -152,6 +176,14 @@ func filterEvents(notification *models.Notification) ([]*models.Event, error) {
 		log.Debugf("receive an event: \n----ID: %s \n----target: %s:%s \n----digest: %s \n----action: %s \n----mediatype: %s \n----user-agent: %s", event.ID, event.Target.Repository,
 			event.Target.Tag, event.Target.Digest, event.Action, event.Target.MediaType, event.Request.UserAgent)
 
+		// the media type is absent in the events of deleting manifest
+		if event.Action == "delete" {
+			if checkEvent(&event) {
+				events = append(events, &event)
+			}
+			continue
+		}
+
 		isManifest, err := regexp.MatchString(manifestPattern, event.Target.MediaType)
 		if err != nil {
 			log.Errorf("failed to match the media type against pattern: %v", err)
@@ -181,12 +213,16 @@ func checkEvent(event *models.Event) bool {
 	if strings.ToLower(strings.TrimSpace(event.Request.UserAgent)) == "harbor-registry-client" && event.Action == "push" {
 		return true
 	}
+	// delete manifest via API or by the tag retention job
+	if event.Action == "delete" && len(event.Target.Digest) > 0 {
+		return true
+	}
 	return false
 }
 
 func autoScanEnabled(project *models.Project) bool {
-	if !config.WithClair() {
-		log.Debugf("Auto Scan disabled because Harbor is not deployed with Clair")
+	if !coreutils.ScanEnabled() {
+		log.Debugf("Auto Scan disabled because Harbor is not deployed with Clair and no scanner is registered")
 		return false
 	}
 
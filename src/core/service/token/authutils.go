@@ -122,6 +122,27 @@ func MakeToken(username, service string, access []*token.ResourceActions) (*mode
 	}, nil
 }
 
+// VerifyToken verifies the token is signed by the key of core for the service and isn't expired,
+// the parsed token is returned if it's valid.
+func VerifyToken(rawToken, service string) (*token.Token, error) {
+	pk, err := libtrust.LoadKeyFile(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	tk, err := token.NewToken(rawToken)
+	if err != nil {
+		return nil, err
+	}
+	if err = tk.Verify(token.VerifyOptions{
+		TrustedIssuers:    []string{issuer},
+		AcceptedAudiences: []string{service},
+		TrustedKeys:       map[string]libtrust.PublicKey{pk.KeyID(): pk.PublicKey()},
+	}); err != nil {
+		return nil, err
+	}
+	return tk, nil
+}
+
 func permToActions(p string) []string {
 	res := []string{}
 	if strings.Contains(p, "W") {
@@ -30,6 +30,7 @@ import (
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/rbac"
 	"github.com/goharbor/harbor/src/common/utils/test"
 	"github.com/goharbor/harbor/src/core/config"
 )
@@ -167,6 +168,34 @@ func TestMakeToken(t *testing.T) {
 	assert.Equal(t, claims.Audience, svc, "Audience mismatch")
 }
 
+func TestVerifyToken(t *testing.T) {
+	pk, _ := getKeyAndCertPath()
+	privateKey = pk
+	ra := []*token.ResourceActions{{
+		Type:    "repository",
+		Name:    "library/hello-world",
+		Actions: []string{"pull"},
+	}}
+	svc := "harbor-registry"
+	tokenJSON, err := MakeToken("tester", svc, ra)
+	if err != nil {
+		t.Fatalf("Error while making token: %v", err)
+	}
+
+	tk, err := VerifyToken(tokenJSON.Token, svc)
+	if err != nil {
+		t.Fatalf("Error while verifying the token: %v", err)
+	}
+	assert.Equal(t, "tester", tk.Claims.Subject)
+	assert.Equal(t, *ra[0], *tk.Claims.Access[0])
+
+	_, err = VerifyToken(tokenJSON.Token, "other-service")
+	assert.NotNil(t, err, "the token of another service should be refused")
+
+	_, err = VerifyToken(tokenJSON.Token[:len(tokenJSON.Token)-4]+"abcd", svc)
+	assert.NotNil(t, err, "the token with an invalid signature should be refused")
+}
+
 func TestPermToActions(t *testing.T) {
 	perm1 := "RWM"
 	perm2 := "MRR"
@@ -266,6 +295,9 @@ func (f *fakeSecurityContext) GetMyProjects() ([]*models.Project, error) {
 func (f *fakeSecurityContext) GetProjectRoles(interface{}) []int {
 	return nil
 }
+func (f *fakeSecurityContext) Can(action rbac.Action, resource rbac.Resource) bool {
+	return false
+}
 
 func TestFilterAccess(t *testing.T) {
 	// TODO put initial data in DB to verify repository filter.
@@ -20,6 +20,7 @@ import (
 	"github.com/goharbor/harbor/src/common/job"
 	jobmodels "github.com/goharbor/harbor/src/common/job/models"
 	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
 	"github.com/goharbor/harbor/src/common/utils/log"
 	"github.com/goharbor/harbor/src/core/config"
 
@@ -61,6 +62,7 @@ func scanAll(cron string, c ...job.Client) (string, error) {
 		JobKind:  kind,
 		IsUnique: true,
 		Cron:     cron,
+		Queue:    config.ScanJobQueue(),
 	}
 	id, err := dao.AddAdminJob(&models.AdminJob{
 		Name: job.ImageScanAllJob,
@@ -102,10 +104,15 @@ func TriggerImageScan(repository string, tag string) error {
 		log.Errorf("Failed to get Manifest for %s:%s", repository, tag)
 		return err
 	}
-	return triggerImageScan(repository, tag, digest, GetJobServiceClient())
+	projectName, _ := utils.ParseRepository(repository)
+	reg, err := GetProjectScanner(projectName)
+	if err != nil {
+		return err
+	}
+	return triggerImageScan(repository, tag, digest, reg.ID, GetJobServiceClient())
 }
 
-func triggerImageScan(repository, tag, digest string, client job.Client) error {
+func triggerImageScan(repository, tag, digest string, scannerID int64, client job.Client) error {
 	id, err := dao.AddScanJob(models.ScanJob{
 		Repository: repository,
 		Digest:     digest,
@@ -119,7 +126,7 @@ func triggerImageScan(repository, tag, digest string, client job.Client) error {
 	if err != nil {
 		return err
 	}
-	data, err := buildScanJobData(id, repository, tag, digest)
+	data, err := buildScanJobData(id, repository, tag, digest, scannerID)
 	if err != nil {
 		return err
 	}
@@ -134,12 +141,13 @@ func triggerImageScan(repository, tag, digest string, client job.Client) error {
 	return nil
 }
 
-func buildScanJobData(jobID int64, repository, tag, digest string) (*jobmodels.JobData, error) {
+func buildScanJobData(jobID int64, repository, tag, digest string, scannerID int64) (*jobmodels.JobData, error) {
 	parms := job.ScanJobParms{
 		JobID:      jobID,
 		Repository: repository,
 		Digest:     digest,
 		Tag:        tag,
+		ScannerID:  scannerID,
 	}
 	parmsMap := make(map[string]interface{})
 	b, err := json.Marshal(parms)
@@ -153,6 +161,7 @@ func buildScanJobData(jobID int64, repository, tag, digest string) (*jobmodels.J
 	meta := jobmodels.JobMetadata{
 		JobKind:  job.JobKindGeneric,
 		IsUnique: false,
+		Queue:    config.ScanJobQueue(),
 	}
 
 	data := &jobmodels.JobData{
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/goharbor/harbor/src/common/job"
@@ -17,12 +18,15 @@ type jobDataTestEntry struct {
 
 func TestBuildScanJobData(t *testing.T) {
 	assert := assert.New(t)
+	os.Setenv("SCAN_JOB_QUEUE", "scan")
+	defer os.Unsetenv("SCAN_JOB_QUEUE")
 	testData := []jobDataTestEntry{
 		{input: job.ScanJobParms{
 			JobID:      123,
 			Digest:     "sha256:abcde",
 			Repository: "library/ubuntu",
 			Tag:        "latest",
+			ScannerID:  1,
 		},
 			expect: jobmodels.JobData{
 				Name: job.ImageScanJob,
@@ -31,20 +35,23 @@ func TestBuildScanJobData(t *testing.T) {
 					"repository": "library/ubuntu",
 					"tag":        "latest",
 					"digest":     "sha256:abcde",
+					"scanner_id": 1,
 				},
 				Metadata: &jobmodels.JobMetadata{
 					JobKind:  job.JobKindGeneric,
 					IsUnique: false,
+					Queue:    "scan",
 				},
 				StatusHook: fmt.Sprintf("%s/service/notifications/jobs/scan/%d", config.InternalCoreURL(), 123),
 			},
 		},
 	}
 	for _, d := range testData {
-		r, err := buildScanJobData(d.input.JobID, d.input.Repository, d.input.Tag, d.input.Digest)
+		r, err := buildScanJobData(d.input.JobID, d.input.Repository, d.input.Tag, d.input.Digest, d.input.ScannerID)
 		assert.Nil(err)
 		assert.Equal(d.expect.Name, r.Name)
 		//		assert.Equal(d.expect.Parameters, r.Parameters)
 		assert.Equal(d.expect.StatusHook, r.StatusHook)
+		assert.Equal(d.expect.Metadata, r.Metadata)
 	}
 }
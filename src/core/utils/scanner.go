@@ -0,0 +1,104 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/scanner"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+)
+
+// ScanEnabled returns whether the images can be scanned, which requires the built-in Clair
+// to be installed or at least one external scanner to be registered and enabled
+func ScanEnabled() bool {
+	if config.WithClair() {
+		return true
+	}
+	regs, err := dao.ListScannerRegistrations()
+	if err != nil {
+		log.Errorf("failed to list the scanner registrations: %v", err)
+		return false
+	}
+	for _, reg := range regs {
+		if !reg.IsBuiltin() && !reg.Disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProjectScanner returns the scanner used by the project, which is the scanner picked by
+// the project or the default one if the project doesn't pick any
+func GetProjectScanner(projectName string) (*models.ScannerRegistration, error) {
+	project, err := config.GlobalProjectMgr.Get(projectName)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project %s not found", projectName)
+	}
+
+	var reg *models.ScannerRegistration
+	if id := project.ScannerID(); id > 0 {
+		reg, err = dao.GetScannerRegistration(id)
+	} else {
+		reg, err = dao.GetDefaultScannerRegistration()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if reg == nil {
+		return nil, fmt.Errorf("no scanner is configured for project %s", projectName)
+	}
+	if reg.Disabled {
+		return nil, fmt.Errorf("the scanner %s of project %s is disabled", reg.Name, projectName)
+	}
+	if reg.IsBuiltin() && !config.WithClair() {
+		return nil, fmt.Errorf("the built-in scanner %s is not installed", reg.Name)
+	}
+	return reg, nil
+}
+
+// GetScannerAdapter returns the adapter of the scanner which generated the scan overview,
+// the ID 0 refers to the built-in Clair
+func GetScannerAdapter(scannerID int64) (scanner.Adapter, error) {
+	if scannerID == 0 {
+		return scanner.NewClairAdapter(config.ClairEndpoint()), nil
+	}
+	reg, err := dao.GetScannerRegistration(scannerID)
+	if err != nil {
+		return nil, err
+	}
+	if reg == nil {
+		return nil, fmt.Errorf("scanner %d not found", scannerID)
+	}
+	return scanner.New(reg, config.ClairEndpoint())
+}
+
+// GetScanReport returns the report of the scan overview, nil if the image hasn't been scanned
+func GetScanReport(overview *models.ImgScanOverview) (*scanner.Report, error) {
+	if overview == nil || len(overview.DetailsKey) == 0 {
+		return nil, nil
+	}
+	adapter, err := GetScannerAdapter(overview.ScannerID)
+	if err != nil {
+		return nil, err
+	}
+	return adapter.GetReport(overview.DetailsKey)
+}
@@ -0,0 +1,215 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook delivers the events of projects to the addresses of the webhook policies.
+// The events are published to the webhook topic of notifier, the handler subscribing the topic
+// submits a webhook job to jobservice for each policy subscribing the event type.
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/job"
+	jobmodels "github.com/goharbor/harbor/src/common/job/models"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/goharbor/harbor/src/core/notifier"
+	coreutils "github.com/goharbor/harbor/src/core/utils"
+)
+
+// Publish publishes the event of the project to trigger the webhook policies asynchronously
+func Publish(projectID int64, payload *models.WebhookPayload) {
+	if err := notifier.Publish(notifier.WebhookTopic, models.WebhookEvent{
+		ProjectID: projectID,
+		Payload:   payload,
+	}); err != nil {
+		log.Errorf("failed to publish the %s event of project %d: %v", payload.Type, projectID, err)
+	}
+}
+
+// NewImagePayload returns the payload of the events of an image
+func NewImagePayload(eventType, operator, repository, tag, digest string) *models.WebhookPayload {
+	return newImagePayload(extURL(), eventType, operator, repository, tag, digest)
+}
+
+func newImagePayload(host, eventType, operator, repository, tag, digest string) *models.WebhookPayload {
+	namespace, name := utils.ParseRepository(repository)
+	resource := &models.WebhookResource{
+		Digest: digest,
+		Tag:    tag,
+	}
+	if len(host) > 0 {
+		resource.ResourceURL = fmt.Sprintf("%s/%s:%s", host, repository, tag)
+	}
+	return &models.WebhookPayload{
+		Type:     eventType,
+		OccurAt:  time.Now().Unix(),
+		Operator: operator,
+		EventData: &models.WebhookEventData{
+			Resources: []*models.WebhookResource{resource},
+			Repository: &models.WebhookRepository{
+				Name:         name,
+				Namespace:    namespace,
+				RepoFullName: repository,
+			},
+		},
+	}
+}
+
+// NewReplicationPayload returns the payload of the event that the tags of the repository are replicated,
+// the replication policy, target and operation are carried in the custom attributes
+func NewReplicationPayload(operator, repository string, tags []string, custom map[string]string) *models.WebhookPayload {
+	payload := NewImagePayload(models.WebhookEventReplicationCompleted, operator, repository, "", "")
+	resources := []*models.WebhookResource{}
+	for _, tag := range tags {
+		resources = append(resources, &models.WebhookResource{
+			Tag: tag,
+		})
+	}
+	payload.EventData.Resources = resources
+	payload.EventData.Custom = custom
+	return payload
+}
+
+// NewChartPayload returns the payload of the events of the versions of a chart
+func NewChartPayload(eventType, operator, namespace, chart string, versions ...string) *models.WebhookPayload {
+	return newChartPayload(extURL(), eventType, operator, namespace, chart, versions...)
+}
+
+func newChartPayload(host, eventType, operator, namespace, chart string, versions ...string) *models.WebhookPayload {
+	resources := []*models.WebhookResource{}
+	for _, version := range versions {
+		resource := &models.WebhookResource{
+			Tag: version,
+		}
+		if len(host) > 0 {
+			resource.ResourceURL = fmt.Sprintf("%s/chartrepo/%s/charts/%s-%s.tgz", host, namespace, chart, version)
+		}
+		resources = append(resources, resource)
+	}
+	return &models.WebhookPayload{
+		Type:     eventType,
+		OccurAt:  time.Now().Unix(),
+		Operator: operator,
+		EventData: &models.WebhookEventData{
+			Resources: resources,
+			Repository: &models.WebhookRepository{
+				Name:         chart,
+				Namespace:    namespace,
+				RepoFullName: fmt.Sprintf("%s/%s", namespace, chart),
+			},
+		},
+	}
+}
+
+func extURL() string {
+	url, err := config.ExtURL()
+	if err != nil {
+		log.Warningf("failed to get the external URL, the resource URL is omitted in the payload: %v", err)
+		return ""
+	}
+	return strings.TrimSuffix(url, "/")
+}
+
+// Handler handles the events published to the webhook topic
+type Handler struct{}
+
+// IsStateful implements the interface of notifier.NotificationHandler
+func (h *Handler) IsStateful() bool {
+	return false
+}
+
+// Handle triggers the enabled webhook policies of the project subscribing the event type
+func (h *Handler) Handle(value interface{}) error {
+	event, ok := value.(models.WebhookEvent)
+	if !ok || event.Payload == nil {
+		return errors.New("invalid webhook event")
+	}
+
+	policies, err := dao.GetWebhookPolicies(event.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get the webhook policies of project %d: %v", event.ProjectID, err)
+	}
+	for _, policy := range policies {
+		if !policy.Subscribes(event.Payload.Type) {
+			continue
+		}
+		if _, err := Trigger(policy, event.Payload); err != nil {
+			log.Errorf("failed to trigger the webhook policy %d with the %s event: %v", policy.ID, event.Payload.Type, err)
+		}
+	}
+	return nil
+}
+
+// Trigger records the delivery and submits the webhook job to deliver the payload
+// to the address of the policy, the ID of the delivery is returned
+func Trigger(policy *models.WebhookPolicy, payload *models.WebhookPayload) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	execution := &models.WebhookExecution{
+		PolicyID:  policy.ID,
+		EventType: payload.Type,
+		Payload:   string(data),
+	}
+	id, err := dao.AddWebhookExecution(execution)
+	if err != nil {
+		return 0, err
+	}
+	execution.ID = id
+
+	uuid, err := coreutils.GetJobServiceClient().SubmitJob(newJob(policy, payload.Type, string(data), id))
+	if err != nil {
+		execution.Status = models.JobError
+		if e := dao.UpdateWebhookExecution(execution, "Status"); e != nil {
+			log.Errorf("failed to update the status of webhook execution %d: %v", id, e)
+		}
+		return 0, err
+	}
+
+	execution.JobUUID = uuid
+	if err = dao.UpdateWebhookExecution(execution, "JobUUID"); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// newJob returns the job delivering the payload, the auth header and secret of the policy are passed
+// encrypted as they're stored, and decrypted by the job
+func newJob(policy *models.WebhookPolicy, eventType, payload string, executionID int64) *jobmodels.JobData {
+	return &jobmodels.JobData{
+		Name: job.WebhookJob,
+		Parameters: map[string]interface{}{
+			"address":          policy.Address,
+			"auth_header":      policy.AuthHeader,
+			"secret":           policy.Secret,
+			"skip_cert_verify": policy.SkipCertVerify,
+			"event_type":       eventType,
+			"payload":          payload,
+		},
+		Metadata: &jobmodels.JobMetadata{
+			JobKind: job.JobKindGeneric,
+		},
+		StatusHook: fmt.Sprintf("%s/service/notifications/jobs/webhook/%d", config.InternalCoreURL(), executionID),
+	}
+}
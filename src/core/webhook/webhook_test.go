@@ -0,0 +1,109 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/models"
+	utilstest "github.com/goharbor/harbor/src/common/utils/test"
+	"github.com/goharbor/harbor/src/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	server, err := utilstest.NewAdminserver(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := os.Setenv("ADMINSERVER_URL", server.URL); err != nil {
+		panic(err)
+	}
+	if err := config.Init(); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
+
+func TestNewImagePayload(t *testing.T) {
+	payload := newImagePayload("harbor.example.com", models.WebhookEventPushImage,
+		"admin", "library/team/app", "v1.0", "sha256:digest")
+	assert.Equal(t, models.WebhookEventPushImage, payload.Type)
+	assert.Equal(t, "admin", payload.Operator)
+	assert.NotEqual(t, int64(0), payload.OccurAt)
+	require.NotNil(t, payload.EventData)
+	assert.Equal(t, "library", payload.EventData.Repository.Namespace)
+	assert.Equal(t, "team/app", payload.EventData.Repository.Name)
+	assert.Equal(t, "library/team/app", payload.EventData.Repository.RepoFullName)
+	require.Equal(t, 1, len(payload.EventData.Resources))
+	assert.Equal(t, "v1.0", payload.EventData.Resources[0].Tag)
+	assert.Equal(t, "sha256:digest", payload.EventData.Resources[0].Digest)
+	assert.Equal(t, "harbor.example.com/library/team/app:v1.0", payload.EventData.Resources[0].ResourceURL)
+
+	// the resource URL is omitted if the host is unknown
+	payload = newImagePayload("", models.WebhookEventPullImage, "admin", "library/app", "v1.0", "sha256:digest")
+	assert.Equal(t, "", payload.EventData.Resources[0].ResourceURL)
+}
+
+func TestNewChartPayload(t *testing.T) {
+	payload := newChartPayload("harbor.example.com", models.WebhookEventDeleteChart,
+		"admin", "library", "redis", "1.0.0", "1.0.1")
+	assert.Equal(t, models.WebhookEventDeleteChart, payload.Type)
+	assert.Equal(t, "library/redis", payload.EventData.Repository.RepoFullName)
+	require.Equal(t, 2, len(payload.EventData.Resources))
+	assert.Equal(t, "1.0.1", payload.EventData.Resources[1].Tag)
+	assert.Equal(t, "harbor.example.com/chartrepo/library/charts/redis-1.0.0.tgz", payload.EventData.Resources[0].ResourceURL)
+}
+
+func TestHandleInvalidEvent(t *testing.T) {
+	h := &Handler{}
+	assert.False(t, h.IsStateful())
+	assert.NotNil(t, h.Handle("invalid"))
+	assert.NotNil(t, h.Handle(models.WebhookEvent{ProjectID: 1}))
+}
+
+func TestNewJob(t *testing.T) {
+	policy := &models.WebhookPolicy{
+		ID:             1,
+		Address:        "https://ci.example.com/hook",
+		AuthHeader:     "Bearer token",
+		Secret:         "secret",
+		SkipCertVerify: true,
+	}
+	job := newJob(policy, models.WebhookEventPushImage, `{"type":"pushImage"}`, 10)
+	assert.Equal(t, "https://ci.example.com/hook", job.Parameters["address"])
+	assert.Equal(t, "Bearer token", job.Parameters["auth_header"])
+	assert.Equal(t, "secret", job.Parameters["secret"])
+	assert.Equal(t, true, job.Parameters["skip_cert_verify"])
+	assert.Equal(t, `{"type":"pushImage"}`, job.Parameters["payload"])
+	assert.Contains(t, job.StatusHook, "/service/notifications/jobs/webhook/10")
+}
+
+func TestNewReplicationPayload(t *testing.T) {
+	payload := NewReplicationPayload("admin", "library/app", []string{"v1.0", "v1.1"}, map[string]string{
+		"policy": "sync",
+	})
+	assert.Equal(t, models.WebhookEventReplicationCompleted, payload.Type)
+	assert.Equal(t, "library/app", payload.EventData.Repository.RepoFullName)
+	require.Equal(t, 2, len(payload.EventData.Resources))
+	assert.Equal(t, "v1.1", payload.EventData.Resources[1].Tag)
+	assert.Equal(t, "sync", payload.EventData.Custom["policy"])
+}
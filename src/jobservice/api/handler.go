@@ -15,12 +15,16 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -36,6 +40,12 @@ type Handler interface {
 	// HandleLaunchJobReq is used to handle the job submission request.
 	HandleLaunchJobReq(w http.ResponseWriter, req *http.Request)
 
+	// HandleLaunchJobBatchReq is used to handle the batch job submission request.
+	HandleLaunchJobBatchReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleGetJobBatchReq is used to handle the batch stats query request.
+	HandleGetJobBatchReq(w http.ResponseWriter, req *http.Request)
+
 	// HandleGetJobReq is used to handle the job stats query request.
 	HandleGetJobReq(w http.ResponseWriter, req *http.Request)
 
@@ -47,6 +57,24 @@ type Handler interface {
 
 	// HandleJobLogReq is used to handle the request of getting job logs
 	HandleJobLogReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleGetDeadJobsReq is used to handle the request of listing the dead jobs
+	HandleGetDeadJobsReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleDeadJobsActionReq is used to handle the action requests (retry/purge) of the dead jobs
+	HandleDeadJobsActionReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleJobHookEventsReq is used to handle the request of listing the undelivered hook events of job
+	HandleJobHookEventsReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleHookStatsReq is used to handle the request of getting the hook delivery metrics
+	HandleHookStatsReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleMetricsReq is used to handle the request of exposing the metrics in Prometheus text format
+	HandleMetricsReq(w http.ResponseWriter, req *http.Request)
+
+	// HandleDrainReq is used to handle the request of putting the node into the drain mode
+	HandleDrainReq(w http.ResponseWriter, req *http.Request)
 }
 
 // DefaultHandler is the default request handler which implements the Handler interface.
@@ -96,6 +124,65 @@ func (dh *DefaultHandler) HandleLaunchJobReq(w http.ResponseWriter, req *http.Re
 	dh.handleJSONData(w, req, http.StatusAccepted, jobStats)
 }
 
+// HandleLaunchJobBatchReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleLaunchJobBatchReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.ReadRequestBodyError(err))
+		return
+	}
+
+	// unmarshal data
+	batchReq := models.BatchJobRequest{}
+	if err = json.Unmarshal(data, &batchReq); err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.HandleJSONDataError(err))
+		return
+	}
+
+	// Pass request to the controller for the follow-up.
+	batchStats, err := dh.controller.LaunchJobBatch(batchReq)
+	if err != nil {
+		if errs.IsConflictError(err) {
+			// Conflict error
+			dh.handleError(w, req, http.StatusConflict, err)
+		} else {
+			// General error
+			dh.handleError(w, req, http.StatusInternalServerError, errs.LaunchJobBatchError(err))
+		}
+		return
+	}
+
+	dh.handleJSONData(w, req, http.StatusAccepted, batchStats)
+}
+
+// HandleGetJobBatchReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleGetJobBatchReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	vars := mux.Vars(req)
+	batchID := vars["batch_id"]
+
+	batchStats, err := dh.controller.GetJobBatch(batchID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		backErr := errs.GetBatchStatsError(err)
+		if errs.IsObjectNotFoundError(err) {
+			code = http.StatusNotFound
+			backErr = err
+		}
+		dh.handleError(w, req, code, backErr)
+		return
+	}
+
+	dh.handleJSONData(w, req, http.StatusOK, batchStats)
+}
+
 // HandleGetJobReq is implementation of method defined in interface 'Handler'
 func (dh *DefaultHandler) HandleGetJobReq(w http.ResponseWriter, req *http.Request) {
 	if !dh.preCheck(w, req) {
@@ -233,6 +320,177 @@ func (dh *DefaultHandler) HandleJobLogReq(w http.ResponseWriter, req *http.Reque
 	w.Write(logData)
 }
 
+// HandleGetDeadJobsReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleGetDeadJobsReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	page := uint(1)
+	if p := req.URL.Query().Get("page"); len(p) > 0 {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || v == 0 {
+			dh.handleError(w, req, http.StatusBadRequest, fmt.Errorf("Invalid page number: %s", p))
+			return
+		}
+		page = uint(v)
+	}
+
+	deadJobs, err := dh.controller.GetDeadJobs(page)
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.GetDeadJobsError(err))
+		return
+	}
+
+	dh.handleJSONData(w, req, http.StatusOK, deadJobs)
+}
+
+// HandleDeadJobsActionReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleDeadJobsActionReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.ReadRequestBodyError(err))
+		return
+	}
+
+	// unmarshal data
+	actionReq := models.DeadJobsActionRequest{}
+	if err = json.Unmarshal(data, &actionReq); err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.HandleJSONDataError(err))
+		return
+	}
+
+	if !actionReq.All && len(actionReq.JobIDs) == 0 {
+		dh.handleError(w, req, http.StatusBadRequest, errors.New("either 'job_ids' or 'all' should be specified"))
+		return
+	}
+
+	var res models.DeadJobsActionResult
+	switch actionReq.Action {
+	case opm.CtlCommandRetry:
+		res, err = dh.controller.RetryDeadJobs(actionReq.JobIDs, actionReq.All)
+	case opm.CtlCommandPurge:
+		res, err = dh.controller.PurgeDeadJobs(actionReq.JobIDs, actionReq.All)
+	default:
+		dh.handleError(w, req, http.StatusNotImplemented, errs.UnknownActionNameError(fmt.Errorf("%s", actionReq.Action)))
+		return
+	}
+
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.DeadJobsActionError(err))
+		return
+	}
+
+	dh.handleJSONData(w, req, http.StatusOK, res)
+}
+
+// HandleJobHookEventsReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleJobHookEventsReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	vars := mux.Vars(req)
+	jobID := vars["job_id"]
+
+	events, err := dh.controller.GetJobHookEvents(jobID)
+	if err != nil {
+		code := http.StatusInternalServerError
+		backErr := errs.GetHookEventsError(err)
+		if errs.IsObjectNotFoundError(err) {
+			code = http.StatusNotFound
+			backErr = err
+		}
+		dh.handleError(w, req, code, backErr)
+		return
+	}
+
+	dh.handleJSONData(w, req, http.StatusOK, events)
+}
+
+// HandleHookStatsReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleHookStatsReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	stats, err := dh.controller.GetHookDeliveryStats()
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.GetHookEventsError(err))
+		return
+	}
+
+	dh.handleJSONData(w, req, http.StatusOK, stats)
+}
+
+// HandleMetricsReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleMetricsReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	metrics, err := dh.controller.GetMetrics()
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.GetMetricsError(err))
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	writeMetrics(buf, metrics, time.Now())
+
+	dh.log(req, http.StatusOK, "")
+
+	w.Header().Set(http.CanonicalHeaderKey("content-type"), metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// HandleDrainReq is implementation of method defined in interface 'Handler'
+func (dh *DefaultHandler) HandleDrainReq(w http.ResponseWriter, req *http.Request) {
+	if !dh.preCheck(w, req) {
+		return
+	}
+
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		dh.handleError(w, req, http.StatusInternalServerError, errs.ReadRequestBodyError(err))
+		return
+	}
+
+	// The body is optional
+	drainReq := models.DrainRequest{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err = json.Unmarshal(data, &drainReq); err != nil {
+			dh.handleError(w, req, http.StatusInternalServerError, errs.HandleJSONDataError(err))
+			return
+		}
+	}
+
+	if drainReq.Timeout < 0 {
+		dh.handleError(w, req, http.StatusBadRequest, fmt.Errorf("invalid timeout: %d", drainReq.Timeout))
+		return
+	}
+
+	if err := dh.controller.DrainNode(time.Duration(drainReq.Timeout) * time.Second); err != nil {
+		code := http.StatusInternalServerError
+		backErr := errs.DrainNodeError(err)
+		if errs.IsConflictError(err) {
+			code = http.StatusConflict
+			backErr = err
+		}
+		dh.handleError(w, req, code, backErr)
+		return
+	}
+
+	dh.log(req, http.StatusAccepted, "")
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
 func (dh *DefaultHandler) handleJSONData(w http.ResponseWriter, req *http.Request, code int, object interface{}) {
 	data, err := json.Marshal(object)
 	if err != nil {
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/models"
 )
 
@@ -102,6 +103,66 @@ func TestLaunchJobSucceed(t *testing.T) {
 	ctx.WG.Wait()
 }
 
+func TestLaunchJobBatch(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/jobs/batch", port)
+	resData, err := postReq(url, []byte(`{"jobs": []}`))
+	if e := expectFormatedError(resData, err); e != nil {
+		t.Error(e)
+	}
+
+	res, err := postReq(url, []byte(`{"jobs": [{"name": "fake_job_ok", "metadata": {"kind": "Generic"}}], "status_hook": "http://localhost:9090"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := models.BatchStats{}
+	if err := json.Unmarshal(res, &batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Stats.BatchID != "fake_batch_ok" || len(batch.Stats.JobIDs) != 1 {
+		t.Fatalf("expect batch 'fake_batch_ok' with 1 job but got '%s' with %d jobs\n", batch.Stats.BatchID, len(batch.Stats.JobIDs))
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
+func TestGetJobBatch(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	res, err := getReq(fmt.Sprintf("http://localhost:%d/api/v1/jobs/batch/fake_batch_ok", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := models.BatchStats{}
+	if err := json.Unmarshal(res, &batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Stats.Status != "Success" || batch.Stats.Counts["Success"] != 1 {
+		t.Fatalf("expect batch status 'Success' but got '%s'\n", batch.Stats.Status)
+	}
+
+	resData, err := getReq(fmt.Sprintf("http://localhost:%d/api/v1/jobs/batch/fake_batch", port))
+	if e := expectFormatedError(resData, err); e != nil {
+		t.Fatal(e)
+	}
+	if !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expect '404' but got '%s'", err)
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
 func TestGetJobFailed(t *testing.T) {
 	exportUISecret(fakeSecret)
 
@@ -275,6 +336,169 @@ func TestGetJobLog(t *testing.T) {
 	ctx.WG.Wait()
 }
 
+func TestGetDeadJobs(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	resData, err := getReq(fmt.Sprintf("http://localhost:%d/api/v1/dead_jobs?page=1", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := models.DeadJobList{}
+	if err := json.Unmarshal(resData, &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 1 || list.Jobs[0].LastError != "timeout" {
+		t.Fatalf("expect 1 dead job with error 'timeout' but got %v", list)
+	}
+
+	if _, err := getReq(fmt.Sprintf("http://localhost:%d/api/v1/dead_jobs?page=x", port)); err == nil {
+		t.Fatal("expect error for invalid page but got nil")
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
+func TestDeadJobsAction(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	actionReq, _ := json.Marshal(&models.DeadJobsActionRequest{Action: "retry", All: true})
+	resData, err := postReq(fmt.Sprintf("http://localhost:%d/api/v1/dead_jobs", port), actionReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := models.DeadJobsActionResult{}
+	if err := json.Unmarshal(resData, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Succeeded) != 1 {
+		t.Fatalf("expect 1 job retried but got %d", len(res.Succeeded))
+	}
+
+	actionReq, _ = json.Marshal(&models.DeadJobsActionRequest{Action: "purge"})
+	if _, err := postReq(fmt.Sprintf("http://localhost:%d/api/v1/dead_jobs", port), actionReq); err == nil {
+		t.Fatal("expect error for missing job IDs but got nil")
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
+func TestGetJobHookEvents(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	resData, err := getReq(fmt.Sprintf("http://localhost:%d/api/v1/jobs/fake_job_ok/hooks", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := models.HookEventList{}
+	if err := json.Unmarshal(resData, &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Events) != 1 || list.Events[0].LastError != "timeout" {
+		t.Fatalf("expect 1 undelivered event with error 'timeout' but got %v", list.Events)
+	}
+
+	if _, err := getReq(fmt.Sprintf("http://localhost:%d/api/v1/jobs/not_existing/hooks", port)); err == nil {
+		t.Fatal("expect error for not existing job but got nil")
+	}
+
+	resData, err = getReq(fmt.Sprintf("http://localhost:%d/api/v1/hooks/stats", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := models.HookDeliveryStats{}
+	if err := json.Unmarshal(resData, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.PendingEvents != 1 || stats.Failures != 1 {
+		t.Fatalf("expect 1 pending event and 1 failure but got %v", stats)
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
+func TestMetrics(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	// No secret is required for scraping metrics
+	res, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expect status code 200 but got %d: %s", res.StatusCode, data)
+	}
+
+	for _, line := range []string{
+		`harbor_jobservice_queue_depth{job_name="DEMO",queue="default"} 5`,
+		`harbor_jobservice_job_runs_total{job_name="DEMO",status="success"} 2`,
+		`harbor_jobservice_hook_delivery_failures_total 1`,
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Fatalf("expect line '%s' in metrics but got:\n%s", line, data)
+		}
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
+func TestDrainNode(t *testing.T) {
+	exportUISecret(fakeSecret)
+
+	server, port, ctx := createServer()
+	server.Start()
+	<-time.After(200 * time.Millisecond)
+
+	url := fmt.Sprintf("http://localhost:%d/api/v1/drain", port)
+	if _, err := postReq(url, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := postReq(url, []byte(`{"timeout": 300}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := postReq(url, []byte(`{"timeout": -1}`)); err == nil {
+		t.Fatal("expect error for invalid timeout but got nil")
+	}
+
+	if _, err := postReq(url, []byte(`{"timeout": 7200}`)); err == nil {
+		t.Fatal("expect conflict error but got nil")
+	}
+
+	server.Stop()
+	ctx.WG.Wait()
+}
+
 func expectFormatedError(data []byte, err error) error {
 	if err == nil {
 		return errors.New("expect error but got nil")
@@ -402,6 +626,42 @@ func (fc *fakeController) LaunchJob(req models.JobRequest) (models.JobStats, err
 	return createJobStats(req.Job.Name, req.Job.Metadata.JobKind, req.Job.Metadata.Cron), nil
 }
 
+func (fc *fakeController) LaunchJobBatch(req models.BatchJobRequest) (models.BatchStats, error) {
+	if len(req.Jobs) == 0 {
+		return models.BatchStats{}, errors.New("failed")
+	}
+
+	jobIDs := make([]string, 0, len(req.Jobs))
+	for i := range req.Jobs {
+		jobIDs = append(jobIDs, fmt.Sprintf("fake_ID_%d", i))
+	}
+
+	return models.BatchStats{
+		Stats: &models.BatchStatData{
+			BatchID: "fake_batch_ok",
+			Status:  "Pending",
+			Total:   int64(len(req.Jobs)),
+			JobIDs:  jobIDs,
+		},
+	}, nil
+}
+
+func (fc *fakeController) GetJobBatch(batchID string) (models.BatchStats, error) {
+	if batchID != "fake_batch_ok" {
+		return models.BatchStats{}, errs.NoObjectFoundError(batchID)
+	}
+
+	return models.BatchStats{
+		Stats: &models.BatchStatData{
+			BatchID: batchID,
+			Status:  "Success",
+			Total:   1,
+			Counts:  map[string]int64{"Success": 1},
+			JobIDs:  []string{"fake_ID_0"},
+		},
+	}, nil
+}
+
 func (fc *fakeController) GetJob(jobID string) (models.JobStats, error) {
 	if jobID != "fake_job_ok" {
 		return models.JobStats{}, errors.New("failed")
@@ -452,6 +712,69 @@ func (fc *fakeController) GetJobLogData(jobID string) ([]byte, error) {
 	return nil, errors.New("failed")
 }
 
+func (fc *fakeController) GetDeadJobs(page uint) (models.DeadJobList, error) {
+	return models.DeadJobList{
+		Total: 1,
+		Jobs: []*models.DeadJobData{{
+			JobID:     "fake_dead_job",
+			LastError: "timeout",
+		}},
+	}, nil
+}
+
+func (fc *fakeController) RetryDeadJobs(jobIDs []string, all bool) (models.DeadJobsActionResult, error) {
+	return models.DeadJobsActionResult{
+		Succeeded: []string{"fake_dead_job"},
+	}, nil
+}
+
+func (fc *fakeController) PurgeDeadJobs(jobIDs []string, all bool) (models.DeadJobsActionResult, error) {
+	return models.DeadJobsActionResult{
+		Succeeded: jobIDs,
+	}, nil
+}
+
+func (fc *fakeController) GetJobHookEvents(jobID string) (*models.HookEventList, error) {
+	if jobID != "fake_job_ok" {
+		return nil, errs.NoObjectFoundError(jobID)
+	}
+
+	return &models.HookEventList{
+		JobID: jobID,
+		Events: []*models.HookEvent{{
+			JobID:     jobID,
+			Attempts:  1,
+			LastError: "timeout",
+		}},
+	}, nil
+}
+
+func (fc *fakeController) GetHookDeliveryStats() (models.HookDeliveryStats, error) {
+	return models.HookDeliveryStats{
+		Failures:      1,
+		PendingEvents: 1,
+		PendingJobs:   1,
+	}, nil
+}
+
+func (fc *fakeController) GetMetrics() (*models.JobMetrics, error) {
+	return &models.JobMetrics{
+		Queues: []*models.JobQueueMetrics{{JobName: "DEMO", Queue: "default", Count: 5, Latency: 10}},
+		Runs: &models.JobRunMetrics{
+			Counts: map[string]map[string]int64{"DEMO": {"success": 2}},
+		},
+		Hooks: models.HookDeliveryStats{Failures: 1},
+	}, nil
+}
+
+func (fc *fakeController) DrainNode(timeout time.Duration) error {
+	if timeout > time.Hour {
+		return errs.ConflictError("node is draining")
+	}
+
+	return nil
+}
+
 func createJobStats(name, kind, cron string) models.JobStats {
 	now := time.Now()
 
@@ -0,0 +1,151 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/models"
+)
+
+const (
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+	metricsNamespace   = "harbor_jobservice"
+)
+
+// writeMetrics writes the metrics with the Prometheus text exposition format.
+func writeMetrics(w io.Writer, metrics *models.JobMetrics, now time.Time) {
+	mw := &metricsWriter{w: w}
+
+	mw.header("queue_depth", "gauge", "Number of the jobs waiting in the queue.")
+	for _, q := range metrics.Queues {
+		mw.sample("queue_depth", labels("job_name", q.JobName, "queue", q.Queue), float64(q.Count))
+	}
+
+	mw.header("queue_latency_seconds", "gauge", "How long the oldest job has been waiting in the queue.")
+	for _, q := range metrics.Queues {
+		mw.sample("queue_latency_seconds", labels("job_name", q.JobName, "queue", q.Queue), float64(q.Latency))
+	}
+
+	mw.header("running_jobs", "gauge", "Number of the jobs being run by the workers.")
+	for _, jobName := range sortedKeys(metrics.Running) {
+		mw.sample("running_jobs", labels("job_name", jobName), float64(metrics.Running[jobName]))
+	}
+
+	mw.header("worker_pool_heartbeat_age_seconds", "gauge", "Seconds since the last heartbeat of the worker pool.")
+	for _, p := range metrics.Pools {
+		mw.sample("worker_pool_heartbeat_age_seconds", labels("worker_pool_id", p.WorkerPoolID), math.Max(0, float64(now.Unix()-p.HeartbeatAt)))
+	}
+
+	mw.header("worker_pool_concurrency", "gauge", "Number of the workers in the worker pool.")
+	for _, p := range metrics.Pools {
+		mw.sample("worker_pool_concurrency", labels("worker_pool_id", p.WorkerPoolID), float64(p.Concurrency))
+	}
+
+	if metrics.Runs != nil {
+		mw.header("job_runs_total", "counter", "Number of the finished job runs by the final status.")
+		for _, jobName := range sortedKeys(metrics.Runs.Counts) {
+			counts := metrics.Runs.Counts[jobName]
+			for _, status := range sortedKeys(counts) {
+				mw.sample("job_runs_total", labels("job_name", jobName, "status", status), float64(counts[status]))
+			}
+		}
+
+		mw.header("job_duration_seconds", "histogram", "Duration of the finished job runs.")
+		for _, jobName := range sortedKeys(metrics.Runs.Durations) {
+			h := metrics.Runs.Durations[jobName]
+			for i, bucket := range h.Buckets {
+				mw.sample("job_duration_seconds_bucket", labels("job_name", jobName, "le", formatFloat(bucket)), float64(h.Counts[i]))
+			}
+			mw.sample("job_duration_seconds_bucket", labels("job_name", jobName, "le", "+Inf"), float64(h.Count))
+			mw.sample("job_duration_seconds_sum", labels("job_name", jobName), h.Sum)
+			mw.sample("job_duration_seconds_count", labels("job_name", jobName), float64(h.Count))
+		}
+	}
+
+	mw.header("hook_events_delivered_total", "counter", "Number of the hook events delivered by this node.")
+	mw.sample("hook_events_delivered_total", "", float64(metrics.Hooks.Delivered))
+	mw.header("hook_delivery_failures_total", "counter", "Number of the failed hook delivery attempts by this node.")
+	mw.sample("hook_delivery_failures_total", "", float64(metrics.Hooks.Failures))
+	mw.header("hook_events_expired_total", "counter", "Number of the hook events dropped for expiration by this node.")
+	mw.sample("hook_events_expired_total", "", float64(metrics.Hooks.Expired))
+	mw.header("hook_events_pending", "gauge", "Number of the hook events waiting to be delivered.")
+	mw.sample("hook_events_pending", "", float64(metrics.Hooks.PendingEvents))
+}
+
+type metricsWriter struct {
+	w io.Writer
+}
+
+func (mw *metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(mw.w, "# HELP %s_%s %s\n", metricsNamespace, name, help)
+	fmt.Fprintf(mw.w, "# TYPE %s_%s %s\n", metricsNamespace, name, kind)
+}
+
+func (mw *metricsWriter) sample(name, labels string, value float64) {
+	fmt.Fprintf(mw.w, "%s_%s%s %s\n", metricsNamespace, name, labels, formatFloat(value))
+}
+
+// labels formats the label pairs like {k1="v1",k2="v2"}
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], escapeLabelValue(pairs[i+1])))
+	}
+
+	return fmt.Sprintf("{%s}", strings.Join(parts, ","))
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	switch v := m.(type) {
+	case map[string]int64:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]map[string]int64:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]*models.DurationHistogram:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	default:
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -26,6 +26,8 @@ import (
 const (
 	baseRoute  = "/api"
 	apiVersion = "v1"
+
+	metricsRoute = "/metrics"
 )
 
 // Router defines the related routes for the job service and directs the request
@@ -64,8 +66,9 @@ func NewBaseRouter(handler Handler, authenticator Authenticator) Router {
 // ServeHTTP is the implementation of Router interface.
 func (br *BaseRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	// No auth required for /stats as it is a health check endpoint
+	// and /metrics which is scraped by the monitoring system
 	// Do auth for other services
-	if req.URL.String() != fmt.Sprintf("%s/%s/stats", baseRoute, apiVersion) {
+	if req.URL.String() != fmt.Sprintf("%s/%s/stats", baseRoute, apiVersion) && req.URL.Path != metricsRoute {
 		if err := br.authenticator.DoAuth(req); err != nil {
 			authErr := errs.UnauthorizedError(err)
 			logger.Errorf("Serve http request '%s %s' failed with error: %s", req.Method, req.URL.String(), authErr.Error())
@@ -81,11 +84,21 @@ func (br *BaseRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 // registerRoutes adds routes to the server mux.
 func (br *BaseRouter) registerRoutes() {
+	br.router.HandleFunc(metricsRoute, br.handler.HandleMetricsReq).Methods(http.MethodGet)
+
 	subRouter := br.router.PathPrefix(fmt.Sprintf("%s/%s", baseRoute, apiVersion)).Subrouter()
 
 	subRouter.HandleFunc("/jobs", br.handler.HandleLaunchJobReq).Methods(http.MethodPost)
+	// The batch routes must be registered before the job routes to avoid being matched as a job ID
+	subRouter.HandleFunc("/jobs/batch", br.handler.HandleLaunchJobBatchReq).Methods(http.MethodPost)
+	subRouter.HandleFunc("/jobs/batch/{batch_id}", br.handler.HandleGetJobBatchReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/jobs/{job_id}", br.handler.HandleGetJobReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/jobs/{job_id}", br.handler.HandleJobActionReq).Methods(http.MethodPost)
 	subRouter.HandleFunc("/jobs/{job_id}/log", br.handler.HandleJobLogReq).Methods(http.MethodGet)
+	subRouter.HandleFunc("/jobs/{job_id}/hooks", br.handler.HandleJobHookEventsReq).Methods(http.MethodGet)
 	subRouter.HandleFunc("/stats", br.handler.HandleCheckStatusReq).Methods(http.MethodGet)
+	subRouter.HandleFunc("/dead_jobs", br.handler.HandleGetDeadJobsReq).Methods(http.MethodGet)
+	subRouter.HandleFunc("/dead_jobs", br.handler.HandleDeadJobsActionReq).Methods(http.MethodPost)
+	subRouter.HandleFunc("/hooks/stats", br.handler.HandleHookStatsReq).Methods(http.MethodGet)
+	subRouter.HandleFunc("/drain", br.handler.HandleDrainReq).Methods(http.MethodPost)
 }
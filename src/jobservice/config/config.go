@@ -34,10 +34,12 @@ const (
 	jobServiceHTTPKey            = "JOB_SERVICE_HTTPS_KEY"
 	jobServiceWorkerPoolBackend  = "JOB_SERVICE_POOL_BACKEND"
 	jobServiceWorkers            = "JOB_SERVICE_POOL_WORKERS"
+	jobServiceQueues             = "JOB_SERVICE_POOL_QUEUES"
 	jobServiceRedisURL           = "JOB_SERVICE_POOL_REDIS_URL"
 	jobServiceRedisNamespace     = "JOB_SERVICE_POOL_REDIS_NAMESPACE"
 	jobServiceCoreServerEndpoint = "CORE_URL"
 	jobServiceAuthSecret         = "JOBSERVICE_SECRET"
+	jobServiceDrainTimeout       = "JOB_SERVICE_DRAIN_TIMEOUT"
 
 	// JobServiceProtocolHTTPS points to the 'https' protocol
 	JobServiceProtocolHTTPS = "https"
@@ -47,6 +49,9 @@ const (
 	// JobServicePoolBackendRedis represents redis backend
 	JobServicePoolBackendRedis = "redis"
 
+	// JobServicePoolBackendMemory represents the in-memory backend for the single node deployment
+	JobServicePoolBackendMemory = "memory"
+
 	// secret of UI
 	uiAuthSecret = "CORE_SECRET"
 
@@ -78,6 +83,13 @@ type Configuration struct {
 
 	// Logger configurations
 	LoggerConfigs []*LoggerConfig `yaml:"loggers,omitempty"`
+
+	// Out-of-process plugin jobs
+	PluginConfigs []*PluginConfig `yaml:"plugins,omitempty"`
+
+	// Seconds to wait for the in-flight jobs when draining the node.
+	// If it's set, the node is drained before exiting on SIGTERM.
+	DrainTimeout uint `yaml:"drain_timeout,omitempty"`
 }
 
 // HTTPSConfig keeps additional configurations when using https protocol
@@ -98,6 +110,8 @@ type PoolConfig struct {
 	WorkerCount  uint             `yaml:"workers"`
 	Backend      string           `yaml:"backend"`
 	RedisPoolCfg *RedisPoolConfig `yaml:"redis_pool,omitempty"`
+	// The queues served by this node, only the default queue is served if it's not set
+	Queues []string `yaml:"queues,omitempty"`
 }
 
 // CustomizedSettings keeps the customized settings of logger
@@ -117,6 +131,30 @@ type LoggerConfig struct {
 	Sweeper  *LogSweeperConfig  `yaml:"sweeper"`
 }
 
+// PluginConfig declares an external executable which can be run as a plugin job.
+type PluginConfig struct {
+	// Name for referring the plugin in the job parameters
+	Name string `yaml:"name"`
+
+	// Path of the executable or the container entrypoint
+	Command string `yaml:"command"`
+
+	// Extra arguments appended to the command
+	Args []string `yaml:"args,omitempty"`
+
+	// Environment variables with 'KEY=value' style
+	Env []string `yaml:"env,omitempty"`
+
+	// Working directory of the plugin process
+	WorkDir string `yaml:"work_dir,omitempty"`
+
+	// Max running time in seconds, 0 means no limit
+	Timeout uint `yaml:"timeout,omitempty"`
+
+	// Waiting time in seconds before killing the process after the stop/cancel signal is forwarded
+	GracePeriod uint `yaml:"grace_period,omitempty"`
+}
+
 // Load the configuration options from the specified yaml file.
 // If the yaml file is specified and existing, load configurations from yaml file first;
 // If detecting env variables is specified, load configurations from env variables;
@@ -176,6 +214,17 @@ func GetAdminServerEndpoint() string {
 	return DefaultConfig.AdminServer
 }
 
+// GetPluginConfig returns the configuration of the plugin with the specified name
+func GetPluginConfig(name string) (*PluginConfig, bool) {
+	for _, pc := range DefaultConfig.PluginConfigs {
+		if pc != nil && pc.Name == name {
+			return pc, true
+		}
+	}
+
+	return nil, false
+}
+
 // Load env variables
 func (c *Configuration) loadEnvs() {
 	prot := utils.ReadEnv(jobServiceProtocol)
@@ -233,6 +282,18 @@ func (c *Configuration) loadEnvs() {
 		}
 	}
 
+	if queues := utils.ReadEnv(jobServiceQueues); !utils.IsEmptyStr(queues) {
+		if c.PoolConfig == nil {
+			c.PoolConfig = &PoolConfig{}
+		}
+		c.PoolConfig.Queues = []string{}
+		for _, q := range strings.Split(queues, ",") {
+			if q = strings.TrimSpace(q); len(q) > 0 {
+				c.PoolConfig.Queues = append(c.PoolConfig.Queues, q)
+			}
+		}
+	}
+
 	if c.PoolConfig != nil && c.PoolConfig.Backend == JobServicePoolBackendRedis {
 		redisURL := utils.ReadEnv(jobServiceRedisURL)
 		if !utils.IsEmptyStr(redisURL) {
@@ -251,6 +312,12 @@ func (c *Configuration) loadEnvs() {
 		}
 	}
 
+	if dt := utils.ReadEnv(jobServiceDrainTimeout); !utils.IsEmptyStr(dt) {
+		if timeout, err := strconv.Atoi(dt); err == nil && timeout >= 0 {
+			c.DrainTimeout = uint(timeout)
+		}
+	}
+
 	// admin server
 	if coreServer := utils.ReadEnv(jobServiceCoreServerEndpoint); !utils.IsEmptyStr(coreServer) {
 		c.AdminServer = coreServer
@@ -289,7 +356,8 @@ func (c *Configuration) validate() error {
 		return errors.New("no worker pool is configured")
 	}
 
-	if c.PoolConfig.Backend != JobServicePoolBackendRedis {
+	if c.PoolConfig.Backend != JobServicePoolBackendRedis &&
+		c.PoolConfig.Backend != JobServicePoolBackendMemory {
 		return fmt.Errorf("worker pool backend %s does not support", c.PoolConfig.Backend)
 	}
 
@@ -315,6 +383,17 @@ func (c *Configuration) validate() error {
 		}
 	}
 
+	queues := make(map[string]bool)
+	for _, q := range c.PoolConfig.Queues {
+		if err := utils.ValidateQueueName(q); err != nil {
+			return err
+		}
+		if queues[q] {
+			return fmt.Errorf("queue '%s' is configured more than once", q)
+		}
+		queues[q] = true
+	}
+
 	// Job service loggers
 	if len(c.LoggerConfigs) == 0 {
 		return errors.New("missing logger config of job service")
@@ -329,5 +408,22 @@ func (c *Configuration) validate() error {
 		return fmt.Errorf("invalid admin server endpoint: %s", err)
 	}
 
+	// Plugin jobs
+	pluginNames := make(map[string]bool)
+	for _, pc := range c.PluginConfigs {
+		if pc == nil || utils.IsEmptyStr(pc.Name) {
+			return errors.New("name of plugin is required")
+		}
+
+		if pluginNames[pc.Name] {
+			return fmt.Errorf("plugin '%s' is declared more than once", pc.Name)
+		}
+		pluginNames[pc.Name] = true
+
+		if utils.IsEmptyStr(pc.Command) {
+			return fmt.Errorf("command of plugin '%s' is required", pc.Name)
+		}
+	}
+
 	return nil // valid
 }
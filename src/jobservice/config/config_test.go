@@ -61,6 +61,12 @@ func TestConfigLoadingWithEnv(t *testing.T) {
 	if GetUIAuthSecret() != "core_secret" {
 		t.Errorf("expect auth secret 'core_secret' but got '%s'", GetUIAuthSecret())
 	}
+	if cfg.DrainTimeout != 300 {
+		t.Errorf("expect drain timeout 300 but got %d", cfg.DrainTimeout)
+	}
+	if len(cfg.PoolConfig.Queues) != 2 || cfg.PoolConfig.Queues[1] != "replication" {
+		t.Errorf("expect queues [default replication] but got %v", cfg.PoolConfig.Queues)
+	}
 
 	unsetENV()
 }
@@ -112,6 +118,66 @@ func TestDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestPluginConfig(t *testing.T) {
+	cfg := &Configuration{}
+	if err := cfg.Load("../config_test.yml", false); err != nil {
+		t.Fatalf("Load config from yaml file, expect nil error but got error '%s'\n", err)
+	}
+
+	if len(cfg.PluginConfigs) != 1 {
+		t.Fatalf("expect 1 plugin configured but got %d", len(cfg.PluginConfigs))
+	}
+	if cfg.PluginConfigs[0].Command != "/bin/true" {
+		t.Errorf("expect plugin command '/bin/true' but got '%s'", cfg.PluginConfigs[0].Command)
+	}
+
+	cfg.PluginConfigs = append(cfg.PluginConfigs, &PluginConfig{Name: "housekeeping", Command: "/bin/false"})
+	if err := cfg.validate(); err == nil {
+		t.Errorf("expect non nil error for duplicated plugins but got nil")
+	}
+}
+
+func TestMemoryPoolConfig(t *testing.T) {
+	cfg := &Configuration{}
+	if err := cfg.Load("../config_test.yml", false); err != nil {
+		t.Fatalf("Load config from yaml file, expect nil error but got error '%s'\n", err)
+	}
+
+	// Redis settings are not required by the memory backend
+	cfg.PoolConfig.Backend = JobServicePoolBackendMemory
+	cfg.PoolConfig.RedisPoolCfg = nil
+	if err := cfg.validate(); err != nil {
+		t.Errorf("expect nil error for memory backend but got '%s'", err)
+	}
+
+	cfg.PoolConfig.Backend = "unknown"
+	if err := cfg.validate(); err == nil {
+		t.Errorf("expect non nil error for unknown backend but got nil")
+	}
+}
+
+func TestPoolQueuesConfig(t *testing.T) {
+	cfg := &Configuration{}
+	if err := cfg.Load("../config_test.yml", false); err != nil {
+		t.Fatalf("Load config from yaml file, expect nil error but got error '%s'\n", err)
+	}
+
+	cfg.PoolConfig.Queues = []string{"default", "scan"}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("expect nil error for valid queues but got '%s'", err)
+	}
+
+	cfg.PoolConfig.Queues = []string{"scan", "scan"}
+	if err := cfg.validate(); err == nil {
+		t.Errorf("expect non nil error for duplicated queues but got nil")
+	}
+
+	cfg.PoolConfig.Queues = []string{"scan@near-storage"}
+	if err := cfg.validate(); err == nil {
+		t.Errorf("expect non nil error for invalid queue name but got nil")
+	}
+}
+
 func setENV() {
 	os.Setenv("JOB_SERVICE_PROTOCOL", "https")
 	os.Setenv("JOB_SERVICE_PORT", "8989")
@@ -123,6 +189,8 @@ func setENV() {
 	os.Setenv("JOB_SERVICE_POOL_REDIS_NAMESPACE", "ut_namespace")
 	os.Setenv("JOBSERVICE_SECRET", "js_secret")
 	os.Setenv("CORE_SECRET", "core_secret")
+	os.Setenv("JOB_SERVICE_DRAIN_TIMEOUT", "300")
+	os.Setenv("JOB_SERVICE_POOL_QUEUES", "default, replication")
 }
 
 func unsetENV() {
@@ -131,9 +199,11 @@ func unsetENV() {
 	os.Unsetenv("JOB_SERVICE_HTTPS_CERT")
 	os.Unsetenv("JOB_SERVICE_HTTPS_KEY")
 	os.Unsetenv("JOB_SERVICE_POOL_BACKEND")
+	os.Unsetenv("JOB_SERVICE_POOL_QUEUES")
 	os.Unsetenv("JOB_SERVICE_POOL_WORKERS")
 	os.Unsetenv("JOB_SERVICE_POOL_REDIS_URL")
 	os.Unsetenv("JOB_SERVICE_POOL_REDIS_NAMESPACE")
 	os.Unsetenv("JOBSERVICE_SECRET")
 	os.Unsetenv("CORE_SECRET")
+	os.Unsetenv("JOB_SERVICE_DRAIN_TIMEOUT")
 }
@@ -17,9 +17,11 @@ package core
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/goharbor/harbor/src/jobservice/logger"
 
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/models"
 	"github.com/goharbor/harbor/src/jobservice/pool"
@@ -37,29 +39,28 @@ const (
 type Controller struct {
 	// Refer the backend pool
 	backendPool pool.Interface
+
+	// Pass the drain requests to the bootstrap component
+	drainRequests chan time.Duration
 }
 
 // NewController is constructor of Controller.
 func NewController(backendPool pool.Interface) *Controller {
 	return &Controller{
-		backendPool: backendPool,
+		backendPool:   backendPool,
+		drainRequests: make(chan time.Duration, 1),
 	}
 }
 
+// DrainRequests returns the channel of the drain requests, the value is the deadline of the in-flight jobs.
+// The bootstrap component should listen on it to drain the node.
+func (c *Controller) DrainRequests() <-chan time.Duration {
+	return c.drainRequests
+}
+
 // LaunchJob is implementation of same method in core interface.
 func (c *Controller) LaunchJob(req models.JobRequest) (models.JobStats, error) {
-	if err := validJobReq(req); err != nil {
-		return models.JobStats{}, err
-	}
-
-	// Validate job name
-	jobType, isKnownJob := c.backendPool.IsKnownJob(req.Job.Name)
-	if !isKnownJob {
-		return models.JobStats{}, fmt.Errorf("job with name '%s' is unknown", req.Job.Name)
-	}
-
-	// Validate parameters
-	if err := c.backendPool.ValidateJobParameters(jobType, req.Job.Parameters); err != nil {
+	if err := c.validateJob(req); err != nil {
 		return models.JobStats{}, err
 	}
 
@@ -74,14 +75,16 @@ func (c *Controller) LaunchJob(req models.JobRequest) (models.JobStats, error) {
 			req.Job.Name,
 			req.Job.Parameters,
 			req.Job.Metadata.ScheduleDelay,
-			req.Job.Metadata.IsUnique)
+			req.Job.Metadata.IsUnique,
+			req.Job.Metadata.Queue)
 	case job.JobKindPeriodic:
 		res, err = c.backendPool.PeriodicallyEnqueue(
 			req.Job.Name,
 			req.Job.Parameters,
-			req.Job.Metadata.Cron)
+			req.Job.Metadata.Cron,
+			req.Job.Metadata.Queue)
 	default:
-		res, err = c.backendPool.Enqueue(req.Job.Name, req.Job.Parameters, req.Job.Metadata.IsUnique)
+		res, err = c.backendPool.Enqueue(req.Job.Name, req.Job.Parameters, req.Job.Metadata.IsUnique, req.Job.Metadata.Queue)
 	}
 
 	// Register status hook?
@@ -98,6 +101,52 @@ func (c *Controller) LaunchJob(req models.JobRequest) (models.JobStats, error) {
 	return res, err
 }
 
+// LaunchJobBatch is implementation of same method in core interface.
+func (c *Controller) LaunchJobBatch(req models.BatchJobRequest) (models.BatchStats, error) {
+	if len(req.Jobs) == 0 {
+		return models.BatchStats{}, errors.New("empty batch is not allowed")
+	}
+
+	if !utils.IsEmptyStr(req.StatusHook) && !utils.IsValidURL(req.StatusHook) {
+		return models.BatchStats{}, fmt.Errorf("malformed status hook of batch: %s", req.StatusHook)
+	}
+
+	// Validate all the jobs before enqueuing any of them
+	for i, jd := range req.Jobs {
+		if err := c.validateJob(models.JobRequest{Job: jd}); err != nil {
+			return models.BatchStats{}, fmt.Errorf("job %d of batch: %s", i, err)
+		}
+
+		if jd.Metadata.JobKind != job.JobKindGeneric {
+			return models.BatchStats{}, fmt.Errorf("job %d of batch: only '%s' jobs can be launched in batch", i, job.JobKindGeneric)
+		}
+
+		if !utils.IsEmptyStr(jd.StatusHook) && !utils.IsValidURL(jd.StatusHook) {
+			return models.BatchStats{}, fmt.Errorf("job %d of batch: malformed status hook: %s", i, jd.StatusHook)
+		}
+	}
+
+	res, err := c.backendPool.EnqueueBatch(req.Jobs, req.StatusHook)
+	if err != nil {
+		return models.BatchStats{}, err
+	}
+
+	if !utils.IsEmptyStr(req.StatusHook) {
+		res.Stats.HookStatus = hookActivated
+	}
+
+	return res, nil
+}
+
+// GetJobBatch is implementation of same method in core interface.
+func (c *Controller) GetJobBatch(batchID string) (models.BatchStats, error) {
+	if utils.IsEmptyStr(batchID) {
+		return models.BatchStats{}, errors.New("empty batch ID")
+	}
+
+	return c.backendPool.GetBatchStats(batchID)
+}
+
 // GetJob is implementation of same method in core interface.
 func (c *Controller) GetJob(jobID string) (models.JobStats, error) {
 	if utils.IsEmptyStr(jobID) {
@@ -134,6 +183,48 @@ func (c *Controller) RetryJob(jobID string) error {
 	return c.backendPool.RetryJob(jobID)
 }
 
+// GetDeadJobs is implementation of same method in core interface.
+func (c *Controller) GetDeadJobs(page uint) (models.DeadJobList, error) {
+	jobs, total, err := c.backendPool.DeadJobs(page)
+	if err != nil {
+		return models.DeadJobList{}, err
+	}
+
+	return models.DeadJobList{
+		Total: total,
+		Jobs:  jobs,
+	}, nil
+}
+
+// RetryDeadJobs is implementation of same method in core interface.
+func (c *Controller) RetryDeadJobs(jobIDs []string, all bool) (models.DeadJobsActionResult, error) {
+	return c.applyToDeadJobs(jobIDs, all, c.backendPool.RetryDeadJob)
+}
+
+// PurgeDeadJobs is implementation of same method in core interface.
+func (c *Controller) PurgeDeadJobs(jobIDs []string, all bool) (models.DeadJobsActionResult, error) {
+	return c.applyToDeadJobs(jobIDs, all, c.backendPool.DeleteDeadJob)
+}
+
+// GetJobHookEvents is implementation of same method in core interface.
+func (c *Controller) GetJobHookEvents(jobID string) (*models.HookEventList, error) {
+	if utils.IsEmptyStr(jobID) {
+		return nil, errors.New("empty job ID")
+	}
+
+	return c.backendPool.HookEvents(jobID)
+}
+
+// GetHookDeliveryStats is implementation of same method in core interface.
+func (c *Controller) GetHookDeliveryStats() (models.HookDeliveryStats, error) {
+	return c.backendPool.HookDeliveryStats()
+}
+
+// GetMetrics is implementation of same method in core interface.
+func (c *Controller) GetMetrics() (*models.JobMetrics, error) {
+	return c.backendPool.Metrics()
+}
+
 // GetJobLogData is used to return the log text data for the specified job if exists
 func (c *Controller) GetJobLogData(jobID string) ([]byte, error) {
 	if utils.IsEmptyStr(jobID) {
@@ -148,11 +239,112 @@ func (c *Controller) GetJobLogData(jobID string) ([]byte, error) {
 	return logData, nil
 }
 
+// DrainNode is implementation of same method in core interface.
+func (c *Controller) DrainNode(timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("invalid drain timeout: %s", timeout)
+	}
+
+	if c.backendPool.IsDraining() {
+		return errs.ConflictError("node is draining")
+	}
+
+	select {
+	case c.drainRequests <- timeout:
+		return nil
+	default:
+		// Only one request is accepted
+		return errs.ConflictError("node drain request")
+	}
+}
+
 // CheckStatus is implementation of same method in core interface.
 func (c *Controller) CheckStatus() (models.JobPoolStats, error) {
 	return c.backendPool.Stats()
 }
 
+// Apply the action to each of the specified dead jobs.
+// Failing on some jobs does not stop the action from being applied to the others.
+func (c *Controller) applyToDeadJobs(jobIDs []string, all bool, action func(jobID string, diedAt int64) error) (models.DeadJobsActionResult, error) {
+	if !all && len(jobIDs) == 0 {
+		return models.DeadJobsActionResult{}, errors.New("no dead jobs specified")
+	}
+
+	// The dead jobs are listed once, the time they died at locates them in the dead queue
+	ids, diedAt, err := c.allDeadJobs()
+	if err != nil {
+		return models.DeadJobsActionResult{}, err
+	}
+	if all {
+		jobIDs = ids
+	}
+
+	res := models.DeadJobsActionResult{
+		Succeeded: []string{},
+		Failed:    make(map[string]string),
+	}
+	for _, jobID := range jobIDs {
+		if utils.IsEmptyStr(jobID) {
+			continue
+		}
+
+		dieAt, ok := diedAt[jobID]
+		if !ok {
+			res.Failed[jobID] = fmt.Sprintf("job '%s' is not in the dead queue", jobID)
+			continue
+		}
+
+		if err := action(jobID, dieAt); err != nil {
+			logger.Errorf("Apply action to dead job %s failed with error: %s", jobID, err)
+			res.Failed[jobID] = err.Error()
+			continue
+		}
+
+		res.Succeeded = append(res.Succeeded, jobID)
+	}
+
+	return res, nil
+}
+
+// Collect the IDs of all the dead jobs with the time they died at before doing any changes.
+func (c *Controller) allDeadJobs() ([]string, map[string]int64, error) {
+	ids := []string{}
+	diedAt := make(map[string]int64)
+	for page := uint(1); ; page++ {
+		jobs, total, err := c.backendPool.DeadJobs(page)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, j := range jobs {
+			ids = append(ids, j.JobID)
+			diedAt[j.JobID] = j.DieAt
+		}
+
+		if len(jobs) == 0 || int64(len(ids)) >= total {
+			break
+		}
+	}
+
+	return ids, diedAt, nil
+}
+
+// Validate the job request with the job name and the parameters.
+func (c *Controller) validateJob(req models.JobRequest) error {
+	if err := validJobReq(req); err != nil {
+		return err
+	}
+
+	// Validate job name
+	jobType, isKnownJob := c.backendPool.IsKnownJob(req.Job.Name)
+	if !isKnownJob {
+		return fmt.Errorf("job with name '%s' is unknown", req.Job.Name)
+	}
+
+	// Validate parameters
+	return c.backendPool.ValidateJobParameters(jobType, req.Job.Parameters)
+}
+
 func validJobReq(req models.JobRequest) error {
 	if req.Job == nil {
 		return errors.New("empty job request is not allowed")
@@ -192,5 +384,11 @@ func validJobReq(req models.JobRequest) error {
 		}
 	}
 
+	if !utils.IsEmptyStr(req.Job.Metadata.Queue) {
+		if err := utils.ValidateQueueName(req.Job.Metadata.Queue); err != nil {
+			return fmt.Errorf("'queue' is not correctly set: %s", err)
+		}
+	}
+
 	return nil
 }
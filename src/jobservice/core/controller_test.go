@@ -15,7 +15,9 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/goharbor/harbor/src/jobservice/env"
 	"github.com/goharbor/harbor/src/jobservice/models"
@@ -63,6 +65,25 @@ func TestLaunchGenericJobWithHook(t *testing.T) {
 	}
 }
 
+func TestLaunchGenericJobInQueue(t *testing.T) {
+	pool := &fakePool{}
+	c := NewController(pool)
+	req := createJobReq("Generic", false, false)
+	req.Job.Metadata.Queue = "replication"
+	if _, err := c.LaunchJob(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if pool.queue != "replication" {
+		t.Fatalf("expect job enqueued to queue 'replication' but got '%s'\n", pool.queue)
+	}
+
+	req.Job.Metadata.Queue = "replication@near"
+	if _, err := c.LaunchJob(req); err == nil {
+		t.Fatal("expect non nil error for invalid queue but got nil")
+	}
+}
+
 func TestLaunchScheduledJob(t *testing.T) {
 	pool := &fakePool{}
 	c := NewController(pool)
@@ -135,6 +156,77 @@ func TestJobActions(t *testing.T) {
 	}
 }
 
+func TestDeadJobs(t *testing.T) {
+	pool := &fakePool{}
+	c := NewController(pool)
+
+	list, err := c.GetDeadJobs(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 2 || len(list.Jobs) != 2 {
+		t.Fatalf("expect 2 dead jobs but got %d", list.Total)
+	}
+
+	res, err := c.RetryDeadJobs(nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Succeeded) != 2 {
+		t.Fatalf("expect 2 dead jobs retried but got %d", len(res.Succeeded))
+	}
+
+	res, err = c.PurgeDeadJobs([]string{"fake_dead_ID_1", "fake_dead_ID_2", "fake_ID_not_dead"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Succeeded) != 1 || len(res.Failed) != 2 {
+		t.Fatalf("expect 1 succeeded and 2 failed but got %d and %d", len(res.Succeeded), len(res.Failed))
+	}
+
+	if _, err := c.RetryDeadJobs(nil, false); err == nil {
+		t.Fatal("expect error but got nil")
+	}
+}
+
+func TestHookEvents(t *testing.T) {
+	pool := &fakePool{}
+	c := NewController(pool)
+
+	if _, err := c.GetJobHookEvents(""); err == nil {
+		t.Fatal("expect error for empty job ID but got nil")
+	}
+
+	list, err := c.GetJobHookEvents("fake_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Events) != 1 || list.Events[0].Attempts != 2 {
+		t.Fatalf("expect 1 undelivered event with 2 attempts but got %v", list.Events)
+	}
+
+	stats, err := c.GetHookDeliveryStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.PendingEvents != 1 {
+		t.Fatalf("expect 1 pending event but got %d", stats.PendingEvents)
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	pool := &fakePool{}
+	c := NewController(pool)
+
+	metrics, err := c.GetMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.Queues) != 1 || metrics.Queues[0].Count != 3 {
+		t.Fatalf("expect 1 queue with 3 jobs but got %v", metrics.Queues)
+	}
+}
+
 func TestGetJobLogData(t *testing.T) {
 	pool := &fakePool{}
 	c := NewController(pool)
@@ -162,6 +254,84 @@ func TestCheckStatus(t *testing.T) {
 	}
 }
 
+func TestDrainNode(t *testing.T) {
+	pool := &fakePool{}
+	c := NewController(pool)
+
+	if err := c.DrainNode(-1); err == nil {
+		t.Fatal("expect error for negative timeout but got nil")
+	}
+
+	if err := c.DrainNode(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case timeout := <-c.DrainRequests():
+		if timeout != time.Minute {
+			t.Fatalf("expect drain timeout %s but got %s", time.Minute, timeout)
+		}
+	default:
+		t.Fatal("expect drain request but got nothing")
+	}
+
+	pool.draining = true
+	if err := c.DrainNode(time.Minute); err == nil {
+		t.Fatal("expect conflict error when the node is draining but got nil")
+	}
+}
+
+func TestLaunchJobBatch(t *testing.T) {
+	pool := &fakePool{}
+	c := NewController(pool)
+
+	if _, err := c.LaunchJobBatch(models.BatchJobRequest{}); err == nil {
+		t.Fatal("expect error for empty batch but got nil")
+	}
+
+	req := models.BatchJobRequest{
+		Jobs: []*models.JobData{
+			createJobReq("Generic", false, true).Job,
+			createJobReq("Scheduled", false, false).Job,
+		},
+		StatusHook: "http://localhost:9090",
+	}
+	if _, err := c.LaunchJobBatch(req); err == nil {
+		t.Fatal("expect error for scheduled job in batch but got nil")
+	}
+	if pool.batches != 0 {
+		t.Fatal("expect no jobs enqueued if any job of the batch is invalid")
+	}
+
+	req.Jobs[1] = createJobReq("Generic", true, false).Job
+	res, err := c.LaunchJobBatch(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Stats.BatchID != "fake_batch_ID" {
+		t.Fatalf("expect batch ID 'fake_batch_ID' but got '%s'\n", res.Stats.BatchID)
+	}
+	if len(res.Stats.JobIDs) != 2 {
+		t.Fatalf("expect 2 jobs in batch but got %d\n", len(res.Stats.JobIDs))
+	}
+	if res.Stats.HookStatus != hookActivated {
+		t.Fatalf("expect hook status '%s' but got '%s'\n", hookActivated, res.Stats.HookStatus)
+	}
+
+	if _, err := c.GetJobBatch(""); err == nil {
+		t.Fatal("expect error for empty batch ID but got nil")
+	}
+
+	batch, err := c.GetJobBatch("fake_batch_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Stats.Status != "Running" {
+		t.Fatalf("expect batch status 'Running' but got '%s'\n", batch.Stats.Status)
+	}
+}
+
 func TestInvalidCheck(t *testing.T) {
 	pool := &fakePool{}
 	c := NewController(pool)
@@ -219,7 +389,11 @@ func createJobReq(kind string, isUnique bool, withHook bool) models.JobRequest {
 	return req
 }
 
-type fakePool struct{}
+type fakePool struct {
+	draining bool
+	batches  int
+	queue    string
+}
 
 func (f *fakePool) Start() error {
 	return nil
@@ -233,7 +407,9 @@ func (f *fakePool) RegisterJobs(jobs map[string]interface{}) error {
 	return nil
 }
 
-func (f *fakePool) Enqueue(jobName string, params models.Parameters, isUnique bool) (models.JobStats, error) {
+func (f *fakePool) Enqueue(jobName string, params models.Parameters, isUnique bool, queue string) (models.JobStats, error) {
+	f.queue = queue
+
 	return models.JobStats{
 		Stats: &models.JobStatData{
 			JobID: "fake_ID",
@@ -241,7 +417,33 @@ func (f *fakePool) Enqueue(jobName string, params models.Parameters, isUnique bo
 	}, nil
 }
 
-func (f *fakePool) Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool) (models.JobStats, error) {
+func (f *fakePool) EnqueueBatch(jobs []*models.JobData, hookURL string) (models.BatchStats, error) {
+	f.batches++
+
+	jobIDs := make([]string, 0, len(jobs))
+	for i := range jobs {
+		jobIDs = append(jobIDs, fmt.Sprintf("fake_ID_%d", i))
+	}
+
+	return models.BatchStats{
+		Stats: &models.BatchStatData{
+			BatchID: "fake_batch_ID",
+			Total:   int64(len(jobs)),
+			JobIDs:  jobIDs,
+		},
+	}, nil
+}
+
+func (f *fakePool) GetBatchStats(batchID string) (models.BatchStats, error) {
+	return models.BatchStats{
+		Stats: &models.BatchStatData{
+			BatchID: batchID,
+			Status:  "Running",
+		},
+	}, nil
+}
+
+func (f *fakePool) Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool, queue string) (models.JobStats, error) {
 	return models.JobStats{
 		Stats: &models.JobStatData{
 			JobID: "fake_ID_Scheduled",
@@ -249,7 +451,7 @@ func (f *fakePool) Schedule(jobName string, params models.Parameters, runAfterSe
 	}, nil
 }
 
-func (f *fakePool) PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string) (models.JobStats, error) {
+func (f *fakePool) PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string, queue string) (models.JobStats, error) {
 	return models.JobStats{
 		Stats: &models.JobStatData{
 			JobID: "fake_ID_Periodic",
@@ -296,10 +498,62 @@ func (f *fakePool) RetryJob(jobID string) error {
 	return nil
 }
 
+func (f *fakePool) DeadJobs(page uint) ([]*models.DeadJobData, int64, error) {
+	if page > 1 {
+		return []*models.DeadJobData{}, 2, nil
+	}
+
+	return []*models.DeadJobData{
+		{JobID: "fake_dead_ID_1"},
+		{JobID: "fake_dead_ID_2"},
+	}, 2, nil
+}
+
+func (f *fakePool) RetryDeadJob(jobID string, diedAt int64) error {
+	return nil
+}
+
+func (f *fakePool) DeleteDeadJob(jobID string, diedAt int64) error {
+	if jobID == "fake_dead_ID_2" {
+		return errors.New("failed to delete")
+	}
+
+	return nil
+}
+
 func (f *fakePool) RegisterHook(jobID string, hookURL string) error {
 	return nil
 }
 
+func (f *fakePool) HookEvents(jobID string) (*models.HookEventList, error) {
+	return &models.HookEventList{
+		JobID: jobID,
+		Events: []*models.HookEvent{{
+			JobID:    jobID,
+			Attempts: 2,
+		}},
+	}, nil
+}
+
+func (f *fakePool) HookDeliveryStats() (models.HookDeliveryStats, error) {
+	return models.HookDeliveryStats{PendingEvents: 1, PendingJobs: 1}, nil
+}
+
+func (f *fakePool) Metrics() (*models.JobMetrics, error) {
+	return &models.JobMetrics{
+		Queues: []*models.JobQueueMetrics{{JobName: "fake_job", Count: 3}},
+	}, nil
+}
+
+func (f *fakePool) Drain(timeout time.Duration) (int, error) {
+	f.draining = true
+	return 0, nil
+}
+
+func (f *fakePool) IsDraining() bool {
+	return f.draining
+}
+
 type fakeJob struct{}
 
 func (j *fakeJob) MaxFails() uint {
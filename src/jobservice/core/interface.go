@@ -16,6 +16,8 @@
 package core
 
 import (
+	"time"
+
 	"github.com/goharbor/harbor/src/jobservice/models"
 )
 
@@ -30,6 +32,25 @@ type Interface interface {
 	//  error   : Error returned if failed to launch the specified job.
 	LaunchJob(req models.JobRequest) (models.JobStats, error)
 
+	// LaunchJobBatch is used to handle the batch job submission request.
+	// All the jobs are validated before enqueuing, either all of them or none of them are enqueued.
+	//
+	// req	BatchJobRequest : Batch request contains the generic jobs and the optional status hook of the batch.
+	//
+	// Returns:
+	//	BatchStats: Batch status info with the batch ID and the job IDs returned if the batch is successfully launched.
+	//  error     : Error returned if failed to launch the batch.
+	LaunchJobBatch(req models.BatchJobRequest) (models.BatchStats, error)
+
+	// GetJobBatch is used to handle the batch stats query request.
+	//
+	// batchID	string: ID of batch.
+	//
+	// Returns:
+	//	BatchStats: Aggregated status info of the jobs in the batch if batch exists.
+	//  error     : Error returned if failed to get the specified batch.
+	GetJobBatch(batchID string) (models.BatchStats, error)
+
 	// GetJob is used to handle the job stats query request.
 	//
 	// jobID	string: ID of job.
@@ -63,9 +84,62 @@ type Interface interface {
 	//  error           : error returned if meet any problems
 	CancelJob(jobID string) error
 
+	// GetDeadJobs is used to list the jobs in the dead queue.
+	//
+	// page uint : the page number, 1-based
+	//
+	// Returns:
+	//  DeadJobList : the dead jobs in the page and the total number
+	//  error       : Error returned if failed to get the dead jobs.
+	GetDeadJobs(page uint) (models.DeadJobList, error)
+
+	// RetryDeadJobs is used to re-enqueue the specified or all the dead jobs.
+	//
+	// jobIDs []string : IDs of the dead jobs, ignored if 'all' is set
+	// all bool        : retry all the dead jobs
+	//
+	// Returns:
+	//  DeadJobsActionResult : the IDs of the succeeded jobs and the errors of the failed ones
+	//  error                : Error returned if failed to do the retrying
+	RetryDeadJobs(jobIDs []string, all bool) (models.DeadJobsActionResult, error)
+
+	// PurgeDeadJobs is used to remove the specified or all the dead jobs from the dead queue.
+	//
+	// jobIDs []string : IDs of the dead jobs, ignored if 'all' is set
+	// all bool        : purge all the dead jobs
+	//
+	// Returns:
+	//  DeadJobsActionResult : the IDs of the succeeded jobs and the errors of the failed ones
+	//  error                : Error returned if failed to do the purging
+	PurgeDeadJobs(jobIDs []string, all bool) (models.DeadJobsActionResult, error)
+
+	// GetJobHookEvents is used to list the status change events of the job which
+	// are not delivered to the hook yet.
+	//
+	// jobID string : ID of the job
+	//
+	// Returns:
+	//  *HookEventList : the undelivered events in order
+	//  error          : Error returned if failed to get the events.
+	GetJobHookEvents(jobID string) (*models.HookEventList, error)
+
+	// GetHookDeliveryStats is used to get the metrics of the hook events delivery.
+	GetHookDeliveryStats() (models.HookDeliveryStats, error)
+
+	// GetMetrics is used to collect the metrics of the job service for monitoring.
+	GetMetrics() (*models.JobMetrics, error)
+
 	// CheckStatus is used to handle the job service healthy status checking request.
 	CheckStatus() (models.JobPoolStats, error)
 
 	// GetJobLogData is used to return the log text data for the specified job if exists
 	GetJobLogData(jobID string) ([]byte, error)
+
+	// DrainNode is used to put the node into the drain mode, the node exits after draining.
+	//
+	// timeout time.Duration : the deadline of the in-flight jobs, the configured one is used if it's zero
+	//
+	// Returns:
+	//  error : Error returned if the node can not be drained, e.g: it's already draining
+	DrainNode(timeout time.Duration) error
 }
@@ -53,6 +53,20 @@ const (
 	UnAuthorizedErrorCode
 	// ResourceConflictsErrorCode is code for the error of resource conflicting
 	ResourceConflictsErrorCode
+	// GetDeadJobsErrorCode is code for the error of getting dead jobs
+	GetDeadJobsErrorCode
+	// DeadJobsActionErrorCode is code for the error of applying action to dead jobs
+	DeadJobsActionErrorCode
+	// GetHookEventsErrorCode is code for the error of getting the hook events
+	GetHookEventsErrorCode
+	// GetMetricsErrorCode is code for the error of collecting the metrics
+	GetMetricsErrorCode
+	// DrainNodeErrorCode is code for the error of draining the node
+	DrainNodeErrorCode
+	// LaunchJobBatchErrorCode is code for the error of launching the batch of jobs
+	LaunchJobBatchErrorCode
+	// GetBatchStatsErrorCode is code for the error of getting stats of the batch
+	GetBatchStatsErrorCode
 )
 
 // baseError ...
@@ -135,6 +149,41 @@ func GetJobLogError(err error) error {
 	return New(GetJobLogErrorCode, "Failed to get the job log", err.Error())
 }
 
+// GetDeadJobsError is error for the case of getting dead jobs failed
+func GetDeadJobsError(err error) error {
+	return New(GetDeadJobsErrorCode, "Failed to get the dead jobs", err.Error())
+}
+
+// DeadJobsActionError is error for the case of applying action to dead jobs failed
+func DeadJobsActionError(err error) error {
+	return New(DeadJobsActionErrorCode, "Failed to apply action to the dead jobs", err.Error())
+}
+
+// GetHookEventsError is error for the case of getting the hook events or delivery stats failed
+func GetHookEventsError(err error) error {
+	return New(GetHookEventsErrorCode, "Failed to get the hook events", err.Error())
+}
+
+// GetMetricsError is error for the case of collecting the metrics failed
+func GetMetricsError(err error) error {
+	return New(GetMetricsErrorCode, "Failed to collect the metrics", err.Error())
+}
+
+// DrainNodeError is error for the case of draining the node failed
+func DrainNodeError(err error) error {
+	return New(DrainNodeErrorCode, "Failed to drain the node", err.Error())
+}
+
+// LaunchJobBatchError is error for the case of launching the batch of jobs failed
+func LaunchJobBatchError(err error) error {
+	return New(LaunchJobBatchErrorCode, "Launch job batch failed with error", err.Error())
+}
+
+// GetBatchStatsError is error for the case of getting stats of the batch failed
+func GetBatchStatsError(err error) error {
+	return New(GetBatchStatsErrorCode, "Get batch stats failed with error", err.Error())
+}
+
 // UnauthorizedError is error for the case of unauthorized accessing
 func UnauthorizedError(err error) error {
 	return New(UnAuthorizedErrorCode, "Unauthorized", err.Error())
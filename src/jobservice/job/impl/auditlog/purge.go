@@ -0,0 +1,85 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/jobservice/env"
+)
+
+// parameters of the purge job
+type parameters struct {
+	RetentionDays int  `json:"retention_days"`
+	Archive       bool `json:"archive"`
+}
+
+// Purge removes the access logs older than the retention days, the logs
+// are moved to the archive table instead if archive is set.
+type Purge struct{}
+
+// MaxFails implements the interface in job/Interface
+func (p *Purge) MaxFails() uint {
+	return 1
+}
+
+// ShouldRetry implements the interface in job/Interface
+func (p *Purge) ShouldRetry() bool {
+	return false
+}
+
+// Validate implements the interface in job/Interface
+func (p *Purge) Validate(params map[string]interface{}) error {
+	_, err := parseParams(params)
+	return err
+}
+
+// Run implements the interface in job/Interface
+func (p *Purge) Run(ctx env.JobContext, params map[string]interface{}) error {
+	logger := ctx.GetLogger()
+	ps, err := parseParams(params)
+	if err != nil {
+		logger.Errorf("Invalid parameters: %v", err)
+		return err
+	}
+
+	before := time.Now().AddDate(0, 0, -ps.RetentionDays)
+	logger.Infof("Purging the access logs before %s, archive: %t", before.Format(time.RFC3339), ps.Archive)
+	n, err := dao.PurgeAccessLogsBefore(before, ps.Archive)
+	if err != nil {
+		logger.Errorf("Failed to purge the access logs: %v", err)
+		return err
+	}
+	logger.Infof("%d access logs are purged", n)
+	return nil
+}
+
+func parseParams(params map[string]interface{}) (*parameters, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	p := &parameters{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid parameters of audit log purge job: %v", err)
+	}
+	if p.RetentionDays <= 0 {
+		return nil, fmt.Errorf("invalid retention_days: %d", p.RetentionDays)
+	}
+	return p, nil
+}
@@ -0,0 +1,35 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	p := &Purge{}
+	assert.NotNil(t, p.Validate(map[string]interface{}{}))
+	assert.NotNil(t, p.Validate(map[string]interface{}{"retention_days": 0}))
+	assert.NotNil(t, p.Validate(map[string]interface{}{"retention_days": "30"}))
+	assert.Nil(t, p.Validate(map[string]interface{}{"retention_days": 30}))
+
+	ps, err := parseParams(map[string]interface{}{"retention_days": 7, "archive": true})
+	require.Nil(t, err)
+	assert.Equal(t, 7, ps.RetentionDays)
+	assert.True(t, ps.Archive)
+}
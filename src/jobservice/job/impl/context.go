@@ -134,7 +134,7 @@ func (c *Context) Build(dep env.JobData) (env.JobContext, error) {
 	// Set loggers for job
 	if err := setLoggers(func(lg logger.Interface) {
 		jContext.logger = lg
-	}, dep.ID); err != nil {
+	}, dep); err != nil {
 		return nil, err
 	}
 
@@ -237,11 +237,20 @@ func getDBFromConfig(cfg map[string]interface{}) *models.Database {
 }
 
 // create loggers based on the configurations and set it to the job executing context.
-func setLoggers(setter func(lg logger.Interface), jobID string) error {
+func setLoggers(setter func(lg logger.Interface), dep env.JobData) error {
 	if setter == nil {
 		return errors.New("missing setter func")
 	}
 
+	jobID := dep.ID
+	// Structured fields attached to each log line of the job
+	jobKind, _ := dep.ExtraData["jobKind"].(string)
+	fields := map[string]string{
+		"job_id":   jobID,
+		"job_name": dep.Name,
+		"job_kind": jobKind,
+	}
+
 	// Init job loggers here
 	lOptions := []logger.Option{}
 	for _, lc := range config.DefaultConfig.JobLoggerConfigs {
@@ -268,6 +277,14 @@ func setLoggers(setter func(lg logger.Interface), jobID string) error {
 				fSettings["key"] = jobID
 				lOptions = append(lOptions, logger.BackendOption(lc.Name, lc.Level, fSettings))
 			}
+		} else if logger.IsStructuredLogger(lc.Name) {
+			sSettings := map[string]interface{}{}
+			for k, v := range lc.Settings {
+				// Copy settings
+				sSettings[k] = v
+			}
+			sSettings["fields"] = fields
+			lOptions = append(lOptions, logger.BackendOption(lc.Name, lc.Level, sSettings))
 		} else {
 			lOptions = append(lOptions, logger.BackendOption(lc.Name, lc.Level, lc.Settings))
 		}
@@ -19,4 +19,6 @@ package impl
 const (
 	// KnownJobDemo is name of demo job
 	KnownJobDemo = "DEMO"
+	// KnownJobPlugin is name of the job running the configured external plugins
+	KnownJobPlugin = "PLUGIN"
 )
@@ -0,0 +1,289 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/config"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/opm"
+)
+
+const (
+	// ParamPlugin is the job parameter to specify which configured plugin should be run
+	ParamPlugin = "plugin"
+
+	// ExitCodeCancelled is the exit code for plugin to report the 'cancelled' status (128+SIGINT)
+	ExitCodeCancelled = 130
+	// ExitCodeStopped is the exit code for plugin to report the 'stopped' status (128+SIGTERM)
+	ExitCodeStopped = 143
+
+	opCmdCheckInterval = 2 * time.Second
+	defaultGracePeriod = 10 * time.Second
+	maxLogLineSize     = 1024 * 1024
+)
+
+// Job runs the configured external executable as an out-of-process job.
+// The job parameters are passed to the process with JSON format on stdin,
+// the stdout and stderr of the process are captured into the job logger.
+//
+// Exit code 0 of the process is mapped to the 'success' status, ExitCodeStopped
+// to 'stopped', ExitCodeCancelled to 'cancelled' and any others to 'error'.
+//
+// The stop and cancel commands are forwarded to the process with
+// SIGTERM and SIGINT signals respectively.
+type Job struct{}
+
+// MaxFails implements the interface in job/Interface
+func (j *Job) MaxFails() uint {
+	return 1
+}
+
+// ShouldRetry implements the interface in job/Interface
+func (j *Job) ShouldRetry() bool {
+	return false
+}
+
+// Validate implements the interface in job/Interface
+func (j *Job) Validate(params map[string]interface{}) error {
+	_, err := getPluginConfig(params)
+	return err
+}
+
+// Run implements the interface in job/Interface
+func (j *Job) Run(ctx env.JobContext, params map[string]interface{}) error {
+	log := ctx.GetLogger()
+
+	pc, err := getPluginConfig(params)
+	if err != nil {
+		return err
+	}
+
+	stdin, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(pc.Command, pc.Args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	// a nil env inherits the whole environment of jobservice including the secrets,
+	// only the declared envs are passed to the plugin
+	cmd.Env = append([]string{}, pc.Env...)
+	cmd.Dir = pc.WorkDir
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("start plugin '%s' failed with error: %s", pc.Name, err)
+	}
+	log.Infof("Plugin '%s' is started with pid %d", pc.Name, cmd.Process.Pid)
+
+	// Pipes must be drained before waiting the process
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	go capture(wg, stdout, log.Info)
+	go capture(wg, stderr, log.Error)
+
+	done := make(chan error, 1)
+	go func() {
+		wg.Wait()
+		done <- cmd.Wait()
+	}()
+
+	return watch(ctx, pc, cmd.Process, done)
+}
+
+// watch the running process and forward the op commands to it until it exits.
+func watch(ctx env.JobContext, pc *config.PluginConfig, process *os.Process, done <-chan error) error {
+	log := ctx.GetLogger()
+
+	tk := time.NewTicker(opCmdCheckInterval)
+	defer tk.Stop()
+
+	var timeout <-chan time.Time
+	if pc.Timeout > 0 {
+		timer := time.NewTimer(time.Duration(pc.Timeout) * time.Second)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	gracePeriod := defaultGracePeriod
+	if pc.GracePeriod > 0 {
+		gracePeriod = time.Duration(pc.GracePeriod) * time.Second
+	}
+
+	var (
+		forwarded string // the forwarded op command
+		timedOut  bool   // the process is terminated for timeout
+		kill      <-chan time.Time
+		exiting   = ctx.SystemContext().Done()
+	)
+
+	forward := func(cmd string, sig os.Signal) {
+		if len(forwarded) > 0 || timedOut {
+			return // already forwarded
+		}
+
+		log.Infof("Forward signal '%s' to plugin '%s' for command '%s'", sig, pc.Name, cmd)
+		if err := process.Signal(sig); err != nil {
+			log.Errorf("Forward signal to plugin '%s' failed with error: %s", pc.Name, err)
+		}
+		forwarded = cmd
+		kill = time.After(gracePeriod)
+	}
+
+	for {
+		select {
+		case err := <-done:
+			return exitStatus(pc, err, forwarded, timedOut)
+		case <-tk.C:
+			if cmd, ok := ctx.OPCommand(); ok {
+				if cmd == opm.CtlCommandCancel {
+					forward(cmd, syscall.SIGINT)
+				} else {
+					forward(opm.CtlCommandStop, syscall.SIGTERM)
+				}
+			}
+		case <-exiting:
+			// Job service is exiting
+			forward(opm.CtlCommandStop, syscall.SIGTERM)
+			exiting = nil
+		case <-timeout:
+			if len(forwarded) == 0 {
+				log.Errorf("Plugin '%s' is timeout after running %d seconds", pc.Name, pc.Timeout)
+				timedOut = true
+				if err := process.Signal(syscall.SIGTERM); err != nil {
+					log.Errorf("Terminate plugin '%s' failed with error: %s", pc.Name, err)
+				}
+				kill = time.After(gracePeriod)
+			}
+		case <-kill:
+			log.Warningf("Plugin '%s' is still running after %s, kill it", pc.Name, gracePeriod)
+			if err := process.Kill(); err != nil {
+				log.Errorf("Kill plugin '%s' failed with error: %s", pc.Name, err)
+			}
+			kill = nil
+		}
+	}
+}
+
+// exitStatus translates the exit state of the plugin process to the job error.
+func exitStatus(pc *config.PluginConfig, err error, forwarded string, timedOut bool) error {
+	if timedOut {
+		return fmt.Errorf("plugin '%s' is timeout", pc.Name)
+	}
+
+	switch forwarded {
+	case opm.CtlCommandStop:
+		return errs.JobStoppedError()
+	case opm.CtlCommandCancel:
+		return errs.JobCancelledError()
+	default:
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return err
+	}
+
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+		if ws.Signaled() {
+			switch ws.Signal() {
+			case syscall.SIGTERM:
+				return errs.JobStoppedError()
+			case syscall.SIGINT:
+				return errs.JobCancelledError()
+			default:
+				return fmt.Errorf("plugin '%s' is terminated by signal '%s'", pc.Name, ws.Signal())
+			}
+		}
+
+		switch ws.ExitStatus() {
+		case ExitCodeStopped:
+			return errs.JobStoppedError()
+		case ExitCodeCancelled:
+			return errs.JobCancelledError()
+		default:
+			return fmt.Errorf("plugin '%s' exits with code %d", pc.Name, ws.ExitStatus())
+		}
+	}
+
+	return fmt.Errorf("plugin '%s' exits with error: %s", pc.Name, exitErr)
+}
+
+// capture the output lines of plugin into the job logger.
+func capture(wg *sync.WaitGroup, reader io.Reader, logFunc func(v ...interface{})) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		logFunc(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		logger.Errorf("Capture output of plugin failed with error: %s", err)
+		// Drain the left data to avoid blocking the plugin process
+		io.Copy(ioutil.Discard, reader)
+	}
+}
+
+func getPluginConfig(params map[string]interface{}) (*config.PluginConfig, error) {
+	if params == nil {
+		return nil, errors.New("parameters required for plugin job")
+	}
+
+	v, ok := params[ParamPlugin]
+	if !ok {
+		return nil, fmt.Errorf("missing parameter '%s'", ParamPlugin)
+	}
+
+	name, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("parameter '%s' should be string but got %T", ParamPlugin, v)
+	}
+
+	pc, ok := config.GetPluginConfig(name)
+	if !ok {
+		return nil, fmt.Errorf("plugin '%s' is not configured", name)
+	}
+
+	return pc, nil
+}
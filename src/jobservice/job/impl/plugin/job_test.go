@@ -0,0 +1,162 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/goharbor/harbor/src/jobservice/config"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/logger/backend"
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/opm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	defer setPlugins()()
+
+	j := &Job{}
+	assert.NotNil(t, j.Validate(nil))
+	assert.NotNil(t, j.Validate(map[string]interface{}{ParamPlugin: 1}))
+	assert.NotNil(t, j.Validate(map[string]interface{}{ParamPlugin: "not-existing"}))
+	assert.Nil(t, j.Validate(map[string]interface{}{ParamPlugin: "echo"}))
+}
+
+func TestRunSuccess(t *testing.T) {
+	defer setPlugins()()
+
+	ctx := newFakeContext("")
+	err := (&Job{}).Run(ctx, map[string]interface{}{ParamPlugin: "echo", "image": "library/photon"})
+	require.Nil(t, err)
+	assert.Contains(t, ctx.lines(), `{"image":"library/photon","plugin":"echo"}`)
+}
+
+func TestRunEnv(t *testing.T) {
+	defer setPlugins()()
+
+	require.Nil(t, os.Setenv("PLUGIN_TEST_SECRET", "secret"))
+	defer os.Unsetenv("PLUGIN_TEST_SECRET")
+
+	ctx := newFakeContext("")
+	require.Nil(t, (&Job{}).Run(ctx, map[string]interface{}{ParamPlugin: "env"}))
+	assert.Contains(t, ctx.lines(), "secret=")
+
+	ctx = newFakeContext("")
+	require.Nil(t, (&Job{}).Run(ctx, map[string]interface{}{ParamPlugin: "declared-env"}))
+	assert.Contains(t, ctx.lines(), "secret=declared")
+}
+
+func TestRunExitCodes(t *testing.T) {
+	defer setPlugins()()
+
+	err := (&Job{}).Run(newFakeContext(""), map[string]interface{}{ParamPlugin: "fail"})
+	require.NotNil(t, err)
+	assert.False(t, errs.IsJobStoppedError(err))
+
+	err = (&Job{}).Run(newFakeContext(""), map[string]interface{}{ParamPlugin: "stopped"})
+	assert.True(t, errs.IsJobStoppedError(err))
+}
+
+func TestRunForwardCommand(t *testing.T) {
+	defer setPlugins()()
+
+	err := (&Job{}).Run(newFakeContext(opm.CtlCommandCancel), map[string]interface{}{ParamPlugin: "sleep"})
+	assert.True(t, errs.IsJobCancelledError(err))
+}
+
+func setPlugins() func() {
+	old := config.DefaultConfig.PluginConfigs
+	config.DefaultConfig.PluginConfigs = []*config.PluginConfig{
+		{Name: "echo", Command: "/bin/sh", Args: []string{"-c", "cat; echo; echo done >&2"}},
+		{Name: "env", Command: "/bin/sh", Args: []string{"-c", "echo secret=$PLUGIN_TEST_SECRET"}},
+		{Name: "declared-env", Command: "/bin/sh", Args: []string{"-c", "echo secret=$PLUGIN_TEST_SECRET"},
+			Env: []string{"PLUGIN_TEST_SECRET=declared"}},
+		{Name: "fail", Command: "/bin/sh", Args: []string{"-c", "exit 1"}},
+		{Name: "stopped", Command: "/bin/sh", Args: []string{"-c", "exit 143"}},
+		{Name: "sleep", Command: "/bin/sh", Args: []string{"-c", "exec sleep 30"}, GracePeriod: 1},
+	}
+
+	return func() {
+		config.DefaultConfig.PluginConfigs = old
+	}
+}
+
+type fakeContext struct {
+	logger.Interface
+
+	command string
+	lock    *sync.Mutex
+	logged  []string
+}
+
+func newFakeContext(command string) *fakeContext {
+	return &fakeContext{
+		Interface: backend.NewStdOutputLogger("DEBUG", backend.StdErr, 4),
+		command:   command,
+		lock:      new(sync.Mutex),
+	}
+}
+
+func (fc *fakeContext) Info(v ...interface{}) {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	for _, line := range v {
+		fc.logged = append(fc.logged, line.(string))
+	}
+}
+
+func (fc *fakeContext) lines() []string {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	return fc.logged
+}
+
+func (fc *fakeContext) Build(dep env.JobData) (env.JobContext, error) {
+	return fc, nil
+}
+
+func (fc *fakeContext) Get(prop string) (interface{}, bool) {
+	return nil, false
+}
+
+func (fc *fakeContext) SystemContext() context.Context {
+	return context.Background()
+}
+
+func (fc *fakeContext) Checkin(status string) error {
+	return nil
+}
+
+func (fc *fakeContext) OPCommand() (string, bool) {
+	return fc.command, len(fc.command) > 0
+}
+
+func (fc *fakeContext) GetLogger() logger.Interface {
+	return fc
+}
+
+func (fc *fakeContext) LaunchJob(req models.JobRequest) (models.JobStats, error) {
+	return models.JobStats{}, errors.New("not supported")
+}
@@ -0,0 +1,336 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/utils/registry"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/utils"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/opm"
+)
+
+// parameters of the retention job
+type parameters struct {
+	ProjectID int64                  `json:"project_id"`
+	Rules     *models.RetentionRules `json:"rules"`
+	DryRun    bool                   `json:"dry_run"`
+}
+
+// Job applies the retention rules to the repositories of the project,
+// the tags not retained by the rules are deleted via Harbor's API.
+// The report of the run is checked in as json when the job is completed.
+type Job struct {
+	registryURL          string
+	secret               string
+	tokenServiceEndpoint string
+	harborAPIEndpoint    string
+	coreClient           *http.Client
+	logger               logger.Interface
+}
+
+// MaxFails implements the interface in job/Interface
+func (j *Job) MaxFails() uint {
+	return 1
+}
+
+// ShouldRetry implements the interface in job/Interface
+func (j *Job) ShouldRetry() bool {
+	return false
+}
+
+// Validate implements the interface in job/Interface
+func (j *Job) Validate(params map[string]interface{}) error {
+	p, err := parseParams(params)
+	if err != nil {
+		return err
+	}
+	if p.ProjectID <= 0 {
+		return fmt.Errorf("invalid project_id: %d", p.ProjectID)
+	}
+	if p.Rules == nil {
+		return fmt.Errorf("retention rules are missing")
+	}
+	return p.Rules.Validate()
+}
+
+// Run implements the interface in job/Interface
+func (j *Job) Run(ctx env.JobContext, params map[string]interface{}) error {
+	j.logger = ctx.GetLogger()
+	if err := j.init(ctx); err != nil {
+		j.logger.Errorf("Failed to initialize the job handler, error: %v", err)
+		return err
+	}
+
+	p, err := parseParams(params)
+	if err != nil {
+		return err
+	}
+
+	repos, err := dao.GetRepositories(&models.RepositoryQuery{
+		ProjectIDs: []int64{p.ProjectID},
+	})
+	if err != nil {
+		j.logger.Errorf("Failed to get the repositories of project %d, error: %v", p.ProjectID, err)
+		return err
+	}
+
+	j.logger.Infof("Applying the retention rules to %d repositories of project %d, dry run: %t", len(repos), p.ProjectID, p.DryRun)
+
+	report := &models.RetentionReport{
+		DryRun:  p.DryRun,
+		Deleted: []*models.RetentionCandidate{},
+		Failed:  []*models.RetentionCandidate{},
+	}
+	now := time.Now()
+	for _, repo := range repos {
+		if cmd, ok := ctx.OPCommand(); ok && cmd == opm.CtlCommandStop {
+			j.logger.Info("Exit for receiving stop signal, the repositories not processed are left untouched")
+			j.checkIn(ctx, report)
+			return errs.JobStoppedError()
+		}
+
+		tags, err := j.getTags(repo.Name, p.Rules)
+		if err != nil {
+			j.logger.Errorf("Failed to get the tags of repository %s, error: %v", repo.Name, err)
+			continue
+		}
+
+		retained, deleted := Select(p.Rules, tags, now)
+		report.Repositories++
+		report.Tags += len(tags)
+		report.Retained += len(retained)
+
+		// deleting a tag removes its manifest, so the other tags sharing the digest are gone with it
+		deletedDigests := map[string]string{}
+		for _, tag := range deleted {
+			candidate := &models.RetentionCandidate{
+				Repository: repo.Name,
+				Tag:        tag.Name,
+				PushedAt:   tag.PushedAt,
+				PulledAt:   tag.PulledAt,
+			}
+			if p.DryRun {
+				j.logger.Infof("[Dry run] %s:%s would be deleted", repo.Name, tag.Name)
+				report.Deleted = append(report.Deleted, candidate)
+				continue
+			}
+
+			if other, ok := deletedDigests[tag.Digest]; ok {
+				j.logger.Infof("%s:%s is deleted along with %s:%s", repo.Name, tag.Name, repo.Name, other)
+				report.Deleted = append(report.Deleted, candidate)
+				continue
+			}
+
+			if err := j.deleteTag(repo.Name, tag.Name); err != nil {
+				j.logger.Errorf("Failed to delete %s:%s, error: %v", repo.Name, tag.Name, err)
+				candidate.Error = err.Error()
+				report.Failed = append(report.Failed, candidate)
+				continue
+			}
+			j.logger.Infof("%s:%s is deleted", repo.Name, tag.Name)
+			deletedDigests[tag.Digest] = tag.Name
+			report.Deleted = append(report.Deleted, candidate)
+		}
+	}
+
+	j.logger.Infof("Retention completed: %d repositories, %d tags, %d retained, %d deleted, %d failed",
+		report.Repositories, report.Tags, report.Retained, len(report.Deleted), len(report.Failed))
+	j.checkIn(ctx, report)
+
+	return nil
+}
+
+// getTags returns the tags of the repository with the information required by the rules
+func (j *Job) getTags(repository string, rules *models.RetentionRules) ([]*Tag, error) {
+	client, err := utils.NewRepositoryClientForJobservice(repository, j.registryURL, j.secret, j.tokenServiceEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	names, err := client.ListTag()
+	if err != nil {
+		return nil, err
+	}
+
+	pushed, err := dao.GetLatestAccessTimeOfTags(repository, "push")
+	if err != nil {
+		return nil, err
+	}
+	pulled, err := dao.GetLatestAccessTimeOfTags(repository, "pull")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*Tag, 0, len(names))
+	for _, name := range names {
+		digest, exist, err := client.ManifestExist(name)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			// the tag is deleted after being listed
+			continue
+		}
+		tag := &Tag{
+			Name:   name,
+			Digest: digest,
+		}
+
+		if t, ok := pushed[name]; ok {
+			tag.PushedAt = &t
+		} else if t, err := getCreatedTime(client, name); err == nil {
+			// no push log for the images pushed before the access log is enabled,
+			// use the creation time of the image instead
+			tag.PushedAt = &t
+		} else {
+			j.logger.Warningf("Push time of %s:%s is unknown, the tag will be retained: %v", repository, name, err)
+		}
+		if t, ok := pulled[name]; ok {
+			tag.PulledAt = &t
+		}
+
+		if len(rules.KeepLabels) > 0 {
+			labels, err := dao.GetLabelsOfResource(common.ResourceTypeImage, fmt.Sprintf("%s:%s", repository, name))
+			if err != nil {
+				return nil, err
+			}
+			for _, label := range labels {
+				tag.Labels = append(tag.Labels, label.ID)
+			}
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// deleteTag deletes the tag via Harbor's API to keep the data of Harbor consistent
+func (j *Job) deleteTag(repository, tag string) error {
+	req, err := http.NewRequest(http.MethodDelete,
+		fmt.Sprintf("%s/repositories/%s/tags/%s", j.harborAPIEndpoint, repository, tag), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := j.coreClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response code: %d, data: %s", resp.StatusCode, string(data))
+	}
+	return nil
+}
+
+func (j *Job) checkIn(ctx env.JobContext, report *models.RetentionReport) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		j.logger.Errorf("Failed to marshal the retention report, error: %v", err)
+		return
+	}
+	if err := ctx.Checkin(string(data)); err != nil {
+		j.logger.Errorf("Failed to check in the retention report, error: %v", err)
+	}
+}
+
+func (j *Job) init(ctx env.JobContext) error {
+	if v, err := getAttrFromCtx(ctx, common.RegistryURL); err == nil {
+		j.registryURL = v
+	} else {
+		return err
+	}
+	if v := os.Getenv("JOBSERVICE_SECRET"); len(v) > 0 {
+		j.secret = v
+	} else {
+		return fmt.Errorf("failed to read evnironment variable JOBSERVICE_SECRET")
+	}
+	client, err := utils.GetClient()
+	if err != nil {
+		return err
+	}
+	j.coreClient = client
+	if v, err := getAttrFromCtx(ctx, common.TokenServiceURL); err == nil {
+		j.tokenServiceEndpoint = v
+	} else {
+		return err
+	}
+	if v, err := getAttrFromCtx(ctx, common.CoreURL); err == nil {
+		j.harborAPIEndpoint = strings.TrimSuffix(v, "/") + "/api"
+	} else {
+		return err
+	}
+	return nil
+}
+
+func parseParams(params map[string]interface{}) (*parameters, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	p := &parameters{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid parameters of retention job: %v", err)
+	}
+	return p, nil
+}
+
+// getCreatedTime returns the creation time in the config of the image
+func getCreatedTime(client *registry.Repository, tag string) (time.Time, error) {
+	_, _, payload, err := client.PullManifest(tag, []string{schema2.MediaTypeManifest})
+	if err != nil {
+		return time.Time{}, err
+	}
+	manifest := &schema2.DeserializedManifest{}
+	if err := manifest.UnmarshalJSON(payload); err != nil {
+		return time.Time{}, err
+	}
+
+	_, reader, err := client.PullBlob(manifest.Target().Digest.String())
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer reader.Close()
+
+	config := &struct {
+		Created time.Time `json:"created"`
+	}{}
+	if err := json.NewDecoder(reader).Decode(config); err != nil {
+		return time.Time{}, err
+	}
+	return config.Created, nil
+}
+
+func getAttrFromCtx(ctx env.JobContext, key string) (string, error) {
+	if v, ok := ctx.Get(key); ok && len(v.(string)) > 0 {
+		return v.(string), nil
+	}
+	return "", fmt.Errorf("Failed to get required property: %s", key)
+}
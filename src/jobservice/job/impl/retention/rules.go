@@ -0,0 +1,114 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"path"
+	"sort"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+)
+
+// Tag holds the information of the tag used by the retention rules
+type Tag struct {
+	Name string
+	// digest of the manifest the tag points to
+	Digest string
+	// nil if the push time is unknown
+	PushedAt *time.Time
+	// nil if the tag is never pulled
+	PulledAt *time.Time
+	Labels   []int64
+}
+
+// Select splits the tags of one repository into the ones retained by the rules and the ones to delete.
+// The tags are ordered by the push time with the latest first. The tags whose push time is unknown
+// can't be ordered, they are always retained rather than risking deleting a recent image.
+// As the tags are deleted by the digest of their manifest, the tags sharing the digest with
+// a retained tag are retained as well.
+func Select(rules *models.RetentionRules, tags []*Tag, now time.Time) ([]*Tag, []*Tag) {
+	sorted := make([]*Tag, len(tags))
+	copy(sorted, tags)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		pi, pj := sorted[i].PushedAt, sorted[j].PushedAt
+		if pi == nil || pj == nil {
+			return pi != nil
+		}
+		return pi.After(*pj)
+	})
+
+	labels := make(map[int64]bool, len(rules.KeepLabels))
+	for _, id := range rules.KeepLabels {
+		labels[id] = true
+	}
+	cutoff := now.AddDate(0, 0, -rules.KeepDays)
+
+	retained := []*Tag{}
+	deleted := []*Tag{}
+	for i, tag := range sorted {
+		if tag.PushedAt == nil ||
+			i < rules.KeepLatest ||
+			(rules.KeepDays > 0 && isRecent(tag, cutoff)) ||
+			matchPatterns(tag.Name, rules.KeepPatterns) ||
+			hasLabels(tag.Labels, labels) {
+			retained = append(retained, tag)
+			continue
+		}
+		deleted = append(deleted, tag)
+	}
+
+	digests := make(map[string]bool, len(retained))
+	for _, tag := range retained {
+		if len(tag.Digest) > 0 {
+			digests[tag.Digest] = true
+		}
+	}
+	candidates := deleted
+	deleted = []*Tag{}
+	for _, tag := range candidates {
+		if digests[tag.Digest] {
+			retained = append(retained, tag)
+			continue
+		}
+		deleted = append(deleted, tag)
+	}
+
+	return retained, deleted
+}
+
+func isRecent(tag *Tag, cutoff time.Time) bool {
+	return (tag.PushedAt != nil && tag.PushedAt.After(cutoff)) ||
+		(tag.PulledAt != nil && tag.PulledAt.After(cutoff))
+}
+
+func matchPatterns(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// the patterns are validated when the policy is saved
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLabels(tagLabels []int64, labels map[int64]bool) bool {
+	for _, id := range tagLabels {
+		if labels[id] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func daysAgo(now time.Time, days int) *time.Time {
+	t := now.AddDate(0, 0, -days)
+	return &t
+}
+
+func names(tags []*Tag) []string {
+	ns := []string{}
+	for _, tag := range tags {
+		ns = append(ns, tag.Name)
+	}
+	return ns
+}
+
+func TestSelect(t *testing.T) {
+	now := time.Now()
+	tags := []*Tag{
+		{Name: "ci-1", PushedAt: daysAgo(now, 30)},
+		{Name: "ci-2", PushedAt: daysAgo(now, 20), PulledAt: daysAgo(now, 1)},
+		{Name: "ci-3", PushedAt: daysAgo(now, 10)},
+		{Name: "ci-4", PushedAt: daysAgo(now, 2)},
+		{Name: "v1.0", PushedAt: daysAgo(now, 60)},
+		{Name: "stable", PushedAt: daysAgo(now, 90), Labels: []int64{1}},
+		{Name: "unknown"},
+	}
+
+	cases := []struct {
+		rules    *models.RetentionRules
+		retained []string
+		deleted  []string
+	}{
+		{
+			rules:    &models.RetentionRules{KeepLatest: 2},
+			retained: []string{"ci-4", "ci-3", "unknown"},
+			deleted:  []string{"ci-2", "ci-1", "v1.0", "stable"},
+		},
+		{
+			rules:    &models.RetentionRules{KeepDays: 7},
+			retained: []string{"ci-4", "ci-2", "unknown"},
+			deleted:  []string{"ci-3", "ci-1", "v1.0", "stable"},
+		},
+		{
+			rules:    &models.RetentionRules{KeepPatterns: []string{"v*"}, KeepLabels: []int64{1}},
+			retained: []string{"v1.0", "stable", "unknown"},
+			deleted:  []string{"ci-4", "ci-3", "ci-2", "ci-1"},
+		},
+		{
+			rules:    &models.RetentionRules{KeepLatest: 1, KeepDays: 7, KeepPatterns: []string{"v*"}},
+			retained: []string{"ci-4", "ci-2", "v1.0", "unknown"},
+			deleted:  []string{"ci-3", "ci-1", "stable"},
+		},
+	}
+
+	for _, c := range cases {
+		retained, deleted := Select(c.rules, tags, now)
+		assert.Equal(t, c.retained, names(retained))
+		assert.Equal(t, c.deleted, names(deleted))
+	}
+}
+
+func TestSelectSharedDigest(t *testing.T) {
+	now := time.Now()
+	tags := []*Tag{
+		{Name: "latest", Digest: "sha256:1", PushedAt: daysAgo(now, 1)},
+		{Name: "ci-1", Digest: "sha256:2", PushedAt: daysAgo(now, 20)},
+		{Name: "ci-2", Digest: "sha256:1", PushedAt: daysAgo(now, 30)},
+		{Name: "ci-3", Digest: "sha256:3", PushedAt: daysAgo(now, 40)},
+		{Name: "stable", Digest: "sha256:3", PushedAt: daysAgo(now, 50), Labels: []int64{1}},
+	}
+
+	retained, deleted := Select(&models.RetentionRules{KeepLatest: 1, KeepLabels: []int64{1}}, tags, now)
+	assert.Equal(t, []string{"latest", "stable", "ci-2", "ci-3"}, names(retained))
+	assert.Equal(t, []string{"ci-1"}, names(deleted))
+}
@@ -0,0 +1,211 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/goharbor/harbor/src/common"
+	"github.com/goharbor/harbor/src/common/dao"
+	"github.com/goharbor/harbor/src/common/job"
+	"github.com/goharbor/harbor/src/common/models"
+	"github.com/goharbor/harbor/src/common/scanner"
+	"github.com/goharbor/harbor/src/common/utils/log"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/utils"
+	"github.com/goharbor/harbor/src/jobservice/opm"
+)
+
+var (
+	// the interval to poll the report from the scanner
+	pollInterval = 5 * time.Second
+	// the job fails if the report isn't ready within the timeout
+	reportTimeout = 30 * time.Minute
+)
+
+// Job is the struct to scan Harbor's image with the scanner registered in Harbor
+type Job struct {
+	registryURL   string
+	secret        string
+	tokenEndpoint string
+	clairEndpoint string
+}
+
+// MaxFails implements the interface in job/Interface
+func (j *Job) MaxFails() uint {
+	return 1
+}
+
+// ShouldRetry implements the interface in job/Interface
+func (j *Job) ShouldRetry() bool {
+	return false
+}
+
+// Validate implements the interface in job/Interface
+func (j *Job) Validate(params map[string]interface{}) error {
+	return nil
+}
+
+// Run implements the interface in job/Interface
+func (j *Job) Run(ctx env.JobContext, params map[string]interface{}) error {
+	logger := ctx.GetLogger()
+	if err := j.init(ctx); err != nil {
+		logger.Errorf("Failed to initialize the job, error: %v", err)
+		return err
+	}
+
+	jobParms, err := transformParam(params)
+	if err != nil {
+		logger.Errorf("Failed to prepare parms for scan job, error: %v", err)
+		return err
+	}
+
+	adapter, err := j.adapter(ctx, jobParms.ScannerID)
+	if err != nil {
+		logger.Errorf("Failed to get the adapter of scanner %d, error: %v", jobParms.ScannerID, err)
+		return err
+	}
+
+	token, err := utils.GetTokenForRepo(jobParms.Repository, j.secret, j.tokenEndpoint)
+	if err != nil {
+		logger.Errorf("Failed to get token, error: %v", err)
+		return err
+	}
+	logger.Infof("Scanning image %s:%s with scanner %d", jobParms.Repository, jobParms.Tag, jobParms.ScannerID)
+	reportID, err := adapter.Scan(&scanner.ScanRequest{
+		Registry: scanner.Registry{
+			URL:           j.registryURL,
+			Authorization: fmt.Sprintf("Bearer %s", token),
+		},
+		Artifact: scanner.Artifact{
+			Repository: jobParms.Repository,
+			Tag:        jobParms.Tag,
+			Digest:     jobParms.Digest,
+		},
+	})
+	if err != nil {
+		logger.Errorf("Failed to scan image %s:%s, error: %v", jobParms.Repository, jobParms.Tag, err)
+		return err
+	}
+
+	report, err := waitReport(ctx, adapter, reportID)
+	if err != nil {
+		logger.Errorf("Failed to get the report %s from the scanner, error: %v", reportID, err)
+		return err
+	}
+	compOverview, sev := report.Overview()
+	return dao.UpdateImgScanOverviewByScanner(jobParms.Digest, jobParms.ScannerID, reportID, sev, compOverview)
+}
+
+// adapter returns the adapter of the scanner registration, the built-in Clair is used if the ID is 0
+func (j *Job) adapter(ctx env.JobContext, scannerID int64) (scanner.Adapter, error) {
+	reg := &models.ScannerRegistration{
+		Name: "Clair",
+		URL:  models.BuiltinClairScannerURL,
+	}
+	if scannerID > 0 {
+		r, err := dao.GetScannerRegistration(scannerID)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, fmt.Errorf("scanner %d not found", scannerID)
+		}
+		if r.Disabled {
+			return nil, fmt.Errorf("scanner %s is disabled", r.Name)
+		}
+		reg = r
+	}
+	if reg.IsBuiltin() {
+		loggerImpl, ok := ctx.GetLogger().(*log.Logger)
+		if !ok {
+			loggerImpl = log.DefaultLogger()
+		}
+		if len(j.clairEndpoint) == 0 {
+			return nil, fmt.Errorf("failed to get required property: %s", common.ClairURL)
+		}
+		return scanner.NewClairAdapter(j.clairEndpoint, loggerImpl), nil
+	}
+	return scanner.New(reg, j.clairEndpoint)
+}
+
+// waitReport polls the report until it is ready, the job is stopped or cancelled, or the timeout is reached
+func waitReport(ctx env.JobContext, adapter scanner.Adapter, reportID string) (*scanner.Report, error) {
+	deadline := time.Now().Add(reportTimeout)
+	for {
+		report, err := adapter.GetReport(reportID)
+		if err == nil {
+			return report, nil
+		}
+		if err != scanner.ErrReportNotReady {
+			return nil, err
+		}
+		if cmd, ok := ctx.OPCommand(); ok {
+			if cmd == opm.CtlCommandStop {
+				ctx.GetLogger().Info("Exit for receiving stop signal")
+				return nil, errs.JobStoppedError()
+			}
+			if cmd == opm.CtlCommandCancel {
+				ctx.GetLogger().Info("Exit for receiving cancel signal")
+				return nil, errs.JobCancelledError()
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("the report is not ready after %v", reportTimeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func (j *Job) init(ctx env.JobContext) error {
+	errTpl := "Failed to get required property: %s"
+	if v, ok := ctx.Get(common.RegistryURL); ok && len(v.(string)) > 0 {
+		j.registryURL = v.(string)
+	} else {
+		return fmt.Errorf(errTpl, common.RegistryURL)
+	}
+
+	if v := os.Getenv("JOBSERVICE_SECRET"); len(v) > 0 {
+		j.secret = v
+	} else {
+		return fmt.Errorf(errTpl, "JOBSERVICE_SECRET")
+	}
+	if v, ok := ctx.Get(common.TokenServiceURL); ok && len(v.(string)) > 0 {
+		j.tokenEndpoint = v.(string)
+	} else {
+		return fmt.Errorf(errTpl, common.TokenServiceURL)
+	}
+	// the Clair endpoint is only required by the built-in scanner
+	if v, ok := ctx.Get(common.ClairURL); ok {
+		if s, ok := v.(string); ok {
+			j.clairEndpoint = s
+		}
+	}
+	return nil
+}
+
+func transformParam(params map[string]interface{}) (*job.ScanJobParms, error) {
+	res := job.ScanJobParms{}
+	parmsBytes, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(parmsBytes, &res)
+	return &res, err
+}
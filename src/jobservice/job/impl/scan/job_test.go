@@ -0,0 +1,107 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/scanner"
+	"github.com/goharbor/harbor/src/common/scanner/test"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/logger/backend"
+	"github.com/goharbor/harbor/src/jobservice/opm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeContext struct {
+	env.JobContext
+	command string
+}
+
+func (fc *fakeContext) OPCommand() (string, bool) {
+	return fc.command, len(fc.command) > 0
+}
+
+func (fc *fakeContext) GetLogger() logger.Interface {
+	return backend.NewStdOutputLogger("DEBUG", backend.StdErr, 4)
+}
+
+// pendingAdapter never gets the report ready
+type pendingAdapter struct {
+	scanner.Adapter
+}
+
+func (pa *pendingAdapter) GetReport(id string) (*scanner.Report, error) {
+	return nil, scanner.ErrReportNotReady
+}
+
+func TestWaitReport(t *testing.T) {
+	defer func(interval time.Duration) {
+		pollInterval = interval
+	}(pollInterval)
+	pollInterval = time.Millisecond
+
+	adapter := &test.Adapter{
+		Report: &scanner.Report{Packages: 2},
+	}
+	server := test.NewMockServer(adapter)
+	defer server.Close()
+	a := scanner.NewHTTPAdapter(server.URL, "", false)
+
+	id, err := a.Scan(&scanner.ScanRequest{})
+	require.Nil(t, err)
+	report, err := waitReport(&fakeContext{}, a, id)
+	require.Nil(t, err)
+	assert.Equal(t, 2, report.Packages)
+
+	id, err = a.Scan(&scanner.ScanRequest{})
+	require.Nil(t, err)
+	_, err = waitReport(&fakeContext{command: opm.CtlCommandStop}, a, id)
+	assert.True(t, errs.IsJobStoppedError(err))
+
+	id, err = a.Scan(&scanner.ScanRequest{})
+	require.Nil(t, err)
+	_, err = waitReport(&fakeContext{command: opm.CtlCommandCancel}, a, id)
+	assert.True(t, errs.IsJobCancelledError(err))
+}
+
+func TestWaitReportTimeout(t *testing.T) {
+	defer func(interval, timeout time.Duration) {
+		pollInterval, reportTimeout = interval, timeout
+	}(pollInterval, reportTimeout)
+	pollInterval, reportTimeout = time.Millisecond, 10*time.Millisecond
+
+	_, err := waitReport(&fakeContext{}, &pendingAdapter{}, "report")
+	require.NotNil(t, err)
+	assert.False(t, errs.IsJobStoppedError(err))
+}
+
+func TestTransformParam(t *testing.T) {
+	parms, err := transformParam(map[string]interface{}{
+		"job_int_id": 1,
+		"repository": "library/hello-world",
+		"tag":        "latest",
+		"digest":     "sha256:abc",
+		"scanner_id": 2,
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), parms.JobID)
+	assert.Equal(t, "library/hello-world", parms.Repository)
+	assert.Equal(t, int64(2), parms.ScannerID)
+}
@@ -0,0 +1,183 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/config/encrypt"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+)
+
+const (
+	// HeaderEvent is the header carrying the event type of the payload
+	HeaderEvent = "X-Harbor-Event"
+	// HeaderSignature is the header carrying the HMAC-SHA256 signature of the payload,
+	// in the format of "sha256=<hex digest>", it's absent if the policy has no secret
+	HeaderSignature = "X-Harbor-Signature"
+)
+
+// decrypt decrypts the credentials of the policy, which are encrypted with the secret key by core
+var decrypt = func(ciphertext string) (string, error) {
+	return encrypt.Instance().Decrypt(ciphertext)
+}
+
+// parameters of the webhook job, the auth header and secret are encrypted
+type parameters struct {
+	Address        string `json:"address"`
+	AuthHeader     string `json:"auth_header"`
+	Secret         string `json:"secret"`
+	SkipCertVerify bool   `json:"skip_cert_verify"`
+	EventType      string `json:"event_type"`
+	Payload        string `json:"payload"`
+}
+
+// Job posts the payload of the event to the address of the webhook policy,
+// the delivery is retried by jobservice if the address doesn't respond with 2xx.
+type Job struct {
+	logger logger.Interface
+}
+
+// MaxFails implements the interface in job/Interface
+func (j *Job) MaxFails() uint {
+	return 3
+}
+
+// ShouldRetry implements the interface in job/Interface
+func (j *Job) ShouldRetry() bool {
+	return true
+}
+
+// Validate implements the interface in job/Interface
+func (j *Job) Validate(params map[string]interface{}) error {
+	p, err := parseParams(params)
+	if err != nil {
+		return err
+	}
+	if len(p.Address) == 0 {
+		return fmt.Errorf("address is missing")
+	}
+	if len(p.Payload) == 0 {
+		return fmt.Errorf("payload is missing")
+	}
+	return nil
+}
+
+// Run implements the interface in job/Interface
+func (j *Job) Run(ctx env.JobContext, params map[string]interface{}) error {
+	j.logger = ctx.GetLogger()
+
+	p, err := parseParams(params)
+	if err != nil {
+		return err
+	}
+	if err := p.decryptCredentials(); err != nil {
+		j.logger.Errorf("Failed to decrypt the credentials of the webhook policy: %v", err)
+		return err
+	}
+
+	j.logger.Infof("Delivering the %s event to %s", p.EventType, p.Address)
+	if err := send(newClient(p.SkipCertVerify), p); err != nil {
+		j.logger.Errorf("Failed to deliver the %s event to %s, error: %v", p.EventType, p.Address, err)
+		return err
+	}
+	j.logger.Infof("The %s event is delivered to %s", p.EventType, p.Address)
+	return nil
+}
+
+// Sign returns the HMAC-SHA256 signature of the payload in hex
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func send(client *http.Client, p *parameters) error {
+	payload := []byte(p.Payload)
+	req, err := http.NewRequest(http.MethodPost, p.Address, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, p.EventType)
+	if len(p.AuthHeader) > 0 {
+		req.Header.Set("Authorization", p.AuthHeader)
+	}
+	if len(p.Secret) > 0 {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(p.Secret, payload))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected response code: %d, data: %s", resp.StatusCode, string(data))
+	}
+	return nil
+}
+
+func newClient(skipCertVerify bool) *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: skipCertVerify,
+			},
+		},
+	}
+}
+
+// decryptCredentials replaces the encrypted auth header and secret with the plaintext
+func (p *parameters) decryptCredentials() error {
+	var err error
+	if len(p.AuthHeader) > 0 {
+		if p.AuthHeader, err = decrypt(p.AuthHeader); err != nil {
+			return fmt.Errorf("failed to decrypt the auth header: %v", err)
+		}
+	}
+	if len(p.Secret) > 0 {
+		if p.Secret, err = decrypt(p.Secret); err != nil {
+			return fmt.Errorf("failed to decrypt the secret: %v", err)
+		}
+	}
+	return nil
+}
+
+func parseParams(params map[string]interface{}) (*parameters, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	p := &parameters{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("invalid parameters of webhook job: %v", err)
+	}
+	return p, nil
+}
@@ -0,0 +1,119 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/goharbor/harbor/src/common/config/encrypt"
+	"github.com/goharbor/harbor/src/common/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	j := &Job{}
+	assert.NotNil(t, j.Validate(map[string]interface{}{
+		"payload": `{"type":"pushImage"}`,
+	}))
+	assert.NotNil(t, j.Validate(map[string]interface{}{
+		"address": "http://ci.example.com/hook",
+	}))
+	assert.Nil(t, j.Validate(map[string]interface{}{
+		"address":          "http://ci.example.com/hook",
+		"skip_cert_verify": true,
+		"payload":          `{"type":"pushImage"}`,
+	}))
+}
+
+func TestSend(t *testing.T) {
+	payload := `{"type":"pushImage"}`
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	p := &parameters{
+		Address:    server.URL,
+		AuthHeader: "Bearer token",
+		Secret:     "secret",
+		EventType:  "pushImage",
+		Payload:    payload,
+	}
+	require.Nil(t, send(newClient(false), p))
+	assert.Equal(t, payload, string(body))
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "pushImage", received.Header.Get(HeaderEvent))
+	assert.Equal(t, "sha256="+Sign("secret", []byte(payload)), received.Header.Get(HeaderSignature))
+
+	// no signature without secret
+	p.Secret = ""
+	require.Nil(t, send(newClient(false), p))
+	assert.Equal(t, "", received.Header.Get(HeaderSignature))
+
+	// the delivery fails if the response code isn't 2xx
+	p.AuthHeader = ""
+	assert.NotNil(t, send(newClient(false), p))
+}
+
+func TestSign(t *testing.T) {
+	// echo -n 'hello' | openssl dgst -sha256 -hmac 'secret'
+	assert.Equal(t, "88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b", Sign("secret", []byte("hello")))
+}
+
+func TestDecryptCredentials(t *testing.T) {
+	f, err := ioutil.TempFile("", "webhook-key")
+	require.Nil(t, err)
+	defer os.Remove(f.Name())
+	key := "1234567890123456"
+	_, err = f.WriteString(key)
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+
+	d := decrypt
+	defer func() {
+		decrypt = d
+	}()
+	decrypt = encrypt.NewAESEncryptor(encrypt.NewFileKeyProvider(f.Name())).Decrypt
+
+	authHeader, err := utils.ReversibleEncrypt("Bearer token", key)
+	require.Nil(t, err)
+	secret, err := utils.ReversibleEncrypt("secret", key)
+	require.Nil(t, err)
+
+	p := &parameters{
+		AuthHeader: authHeader,
+		Secret:     secret,
+	}
+	require.Nil(t, p.decryptCredentials())
+	assert.Equal(t, "Bearer token", p.AuthHeader)
+	assert.Equal(t, "secret", p.Secret)
+
+	// the empty ones are left unchanged
+	p = &parameters{}
+	require.Nil(t, p.decryptCredentials())
+	assert.Equal(t, "", p.AuthHeader)
+	assert.Equal(t, "", p.Secret)
+}
@@ -0,0 +1,148 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const defaultFluentdTag = "harbor.jobservice"
+
+// FluentdConfig keeps the options of the fluentd logger.
+type FluentdConfig struct {
+	// tcp(default) or tls
+	Protocol string
+	// host:port of the fluentd forward input
+	Address string
+	// Tag of the events
+	Tag string
+	// Used when protocol is tls
+	TLS *TLSConfig
+	// Structured fields attached to each record
+	Fields Fields
+}
+
+// FluentdLogger is an implementation of logger.Interface.
+// It sends the log records to the fluentd (or fluent-bit) forward input
+// with the 'Message Mode' of the forward protocol.
+type FluentdLogger struct {
+	*leveledLogger
+
+	conn   *remoteConn
+	tag    string
+	fields Fields
+}
+
+// NewFluentdLogger creates a new fluentd logger
+func NewFluentdLogger(level string, config FluentdConfig) (*FluentdLogger, error) {
+	protocol := config.Protocol
+	if len(protocol) == 0 || protocol == ProtocolUDP {
+		// Forward protocol is stream based
+		protocol = ProtocolTCP
+	}
+
+	conn, err := newRemoteConn(protocol, config.Address, config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := config.Tag
+	if len(tag) == 0 {
+		tag = defaultFluentdTag
+	}
+
+	fl := &FluentdLogger{
+		conn:   conn,
+		tag:    tag,
+		fields: config.Fields,
+	}
+	fl.leveledLogger = &leveledLogger{
+		level: parseLevel(level),
+		emit:  fl.send,
+	}
+
+	return fl, nil
+}
+
+// Close the connection to fluentd
+// Implements logger.Closer interface
+func (fl *FluentdLogger) Close() error {
+	return fl.conn.Close()
+}
+
+func (fl *FluentdLogger) send(level log.Level, msg string) {
+	if err := fl.conn.write(fl.encode(level, msg, time.Now())); err != nil {
+		reportError("fluentd", err)
+	}
+}
+
+// encode the event as msgpack array: [tag, time, record]
+func (fl *FluentdLogger) encode(level log.Level, msg string, t time.Time) []byte {
+	record := make(map[string]string, len(fl.fields)+2)
+	for k, v := range fl.fields {
+		record[k] = v
+	}
+	record["level"] = levelName(level)
+	record["message"] = msg
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte(0x93) // fixarray with 3 elements
+	writeMsgpackString(buf, fl.tag)
+	writeMsgpackUint(buf, uint64(t.Unix()))
+	writeMsgpackMap(buf, record)
+
+	return buf.Bytes()
+}
+
+// Only the types used by the forward protocol messages are supported.
+func writeMsgpackString(buf *bytes.Buffer, s string) {
+	l := len(s)
+	switch {
+	case l < 32:
+		buf.WriteByte(0xa0 | byte(l))
+	case l < 1<<8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(l))
+	case l < 1<<16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(l))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(l))
+	}
+	buf.WriteString(s)
+}
+
+func writeMsgpackUint(buf *bytes.Buffer, v uint64) {
+	switch {
+	case v < 1<<7:
+		buf.WriteByte(byte(v))
+	case v < 1<<32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(v))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, v)
+	}
+}
+
+func writeMsgpackMap(buf *bytes.Buffer, m map[string]string) {
+	l := len(m)
+	switch {
+	case l < 16:
+		buf.WriteByte(0x80 | byte(l))
+	case l < 1<<16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(l))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(l))
+	}
+
+	for _, k := range Fields(m).keys() {
+		writeMsgpackString(buf, k)
+		writeMsgpackString(buf, m[k])
+	}
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// Test fluentd logger
+func TestFluentdLogger(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		data, _ := ioutil.ReadAll(conn)
+		received <- data
+	}()
+
+	l, err := NewFluentdLogger("INFO", FluentdConfig{
+		Address: ln.Addr().String(),
+		Tag:     "harbor.job",
+		Fields:  Fields{"job_id": "fake_job_ID"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("TestFluentdLogger")
+	l.Close()
+
+	select {
+	case data := <-received:
+		// [tag, time, {job_id, level, message}]
+		if !bytes.HasPrefix(data, []byte("\x93\xaaharbor.job\xce")) {
+			t.Fatalf("unexpected message header: %q", data)
+		}
+		if !bytes.Contains(data, []byte("\x83\xa6job_id\xabfake_job_ID\xa5level\xa4INFO\xa7message\xb1TestFluentdLogger")) {
+			t.Fatalf("unexpected message record: %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+// Test msgpack string encoding with different lengths
+func TestWriteMsgpackString(t *testing.T) {
+	for l, header := range map[int][]byte{
+		31:    {0xbf},
+		32:    {0xd9, 32},
+		256:   {0xda, 0x01, 0x00},
+		65536: {0xdb, 0x00, 0x01, 0x00, 0x00},
+	} {
+		buf := &bytes.Buffer{}
+		writeMsgpackString(buf, string(make([]byte, l)))
+		if !bytes.HasPrefix(buf.Bytes(), header) || buf.Len() != len(header)+l {
+			t.Fatalf("unexpected encoding of string with length %d: %x", l, buf.Bytes()[:len(header)])
+		}
+	}
+}
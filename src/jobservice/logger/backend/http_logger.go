@@ -0,0 +1,196 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const (
+	defaultHTTPBatchSize     = 100
+	defaultHTTPFlushInterval = 5 * time.Second
+	httpClientTimeout        = 10 * time.Second
+)
+
+// HTTPConfig keeps the options of the http logger.
+type HTTPConfig struct {
+	// URL the log records are posted to
+	Endpoint string
+	// Extra headers like authorization
+	Headers map[string]string
+	// Max number of the records in one request
+	BatchSize int
+	// Interval for flushing the pending records
+	FlushInterval time.Duration
+	// Used when the endpoint is https
+	TLS *TLSConfig
+	// Structured fields attached to each record
+	Fields Fields
+}
+
+// HTTPLogger is an implementation of logger.Interface.
+// It posts the log records to the endpoint in batch as JSON array like:
+//  [{"time": "2018-10-01T10:00:00.000000Z", "level": "INFO", "message": "...", "job_id": "..."}]
+// which can be accepted by most of the log collectors like the http input of Fluentd.
+type HTTPLogger struct {
+	*leveledLogger
+
+	endpoint  string
+	headers   map[string]string
+	fields    Fields
+	batchSize int
+	client    *http.Client
+
+	lock     *sync.Mutex
+	records  []map[string]string
+	stopChan chan struct{}
+	doneChan chan struct{}
+	stopOnce *sync.Once
+}
+
+// NewHTTPLogger creates a new http logger
+func NewHTTPLogger(level string, config HTTPConfig) (*HTTPLogger, error) {
+	if len(config.Endpoint) == 0 {
+		return nil, errors.New("missing endpoint of the http logger")
+	}
+
+	tlsConfig, err := config.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultHTTPBatchSize
+	}
+
+	flushInterval := config.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultHTTPFlushInterval
+	}
+
+	hl := &HTTPLogger{
+		endpoint:  config.Endpoint,
+		headers:   config.Headers,
+		fields:    config.Fields,
+		batchSize: batchSize,
+		client: &http.Client{
+			Timeout: httpClientTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+		lock:     new(sync.Mutex),
+		records:  make([]map[string]string, 0, batchSize),
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+		stopOnce: new(sync.Once),
+	}
+	hl.leveledLogger = &leveledLogger{
+		level: parseLevel(level),
+		emit:  hl.append,
+		flush: func() {
+			hl.flush()
+		},
+	}
+
+	go hl.loop(flushInterval)
+
+	return hl, nil
+}
+
+// Close flushes the pending records and stops the flushing loop
+// Implements logger.Closer interface
+func (hl *HTTPLogger) Close() error {
+	hl.stopOnce.Do(func() {
+		close(hl.stopChan)
+	})
+	<-hl.doneChan
+
+	return hl.flush()
+}
+
+func (hl *HTTPLogger) loop(interval time.Duration) {
+	defer close(hl.doneChan)
+
+	tk := time.NewTicker(interval)
+	defer tk.Stop()
+
+	for {
+		select {
+		case <-tk.C:
+			if err := hl.flush(); err != nil {
+				reportError("http", err)
+			}
+		case <-hl.stopChan:
+			return
+		}
+	}
+}
+
+func (hl *HTTPLogger) append(level log.Level, msg string) {
+	record := make(map[string]string, len(hl.fields)+3)
+	for k, v := range hl.fields {
+		record[k] = v
+	}
+	record["time"] = time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	record["level"] = levelName(level)
+	record["message"] = msg
+
+	hl.lock.Lock()
+	hl.records = append(hl.records, record)
+	full := len(hl.records) >= hl.batchSize
+	hl.lock.Unlock()
+
+	if full {
+		if err := hl.flush(); err != nil {
+			reportError("http", err)
+		}
+	}
+}
+
+// flush sends the pending records, the records are dropped if the sending is failed
+// to avoid exhausting the memory when the endpoint is not available.
+func (hl *HTTPLogger) flush() error {
+	hl.lock.Lock()
+	records := hl.records
+	hl.records = make([]map[string]string, 0, hl.batchSize)
+	hl.lock.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, hl.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range hl.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := hl.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%d records dropped: %s", len(records), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%d records dropped: unexpected status code %d", len(records), res.StatusCode)
+	}
+
+	return nil
+}
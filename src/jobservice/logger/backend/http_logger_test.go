@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// Test http logger
+func TestHTTPLogger(t *testing.T) {
+	var (
+		lock    = &sync.Mutex{}
+		records []map[string]string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		batch := make([]map[string]string, 0)
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		lock.Lock()
+		records = append(records, batch...)
+		lock.Unlock()
+	}))
+	defer ts.Close()
+
+	l, err := NewHTTPLogger("INFO", HTTPConfig{
+		Endpoint:  ts.URL,
+		Headers:   map[string]string{"Authorization": "Bearer token"},
+		BatchSize: 2,
+		Fields:    Fields{"job_id": "fake_job_ID"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("filtered")
+	l.Info("line1")
+	l.Warningf("%s", "line2")
+	l.Error("line3")
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if len(records) != 3 {
+		t.Fatalf("expect 3 records but got %d", len(records))
+	}
+	if records[1]["level"] != "WARNING" || records[1]["message"] != "line2" || records[1]["job_id"] != "fake_job_ID" {
+		t.Fatalf("unexpected record: %v", records[1])
+	}
+}
+
+// Test http logger with missing endpoint
+func TestHTTPLoggerInvalid(t *testing.T) {
+	if _, err := NewHTTPLogger("INFO", HTTPConfig{}); err == nil {
+		t.Fatal("expect error for missing endpoint but got nil")
+	}
+}
@@ -0,0 +1,260 @@
+package backend
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	stdlog "log"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const (
+	// ProtocolUDP represents the UDP transport
+	ProtocolUDP = "udp"
+	// ProtocolTCP represents the TCP transport
+	ProtocolTCP = "tcp"
+	// ProtocolTLS represents the TCP transport with TLS
+	ProtocolTLS = "tls"
+
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// Fields are the structured fields attached to each log line like job ID, name and kind.
+type Fields map[string]string
+
+// keys returns the sorted field names to keep the output stable.
+func (f Fields) keys() []string {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// TLSConfig keeps the options for connecting the remote log collector with TLS.
+type TLSConfig struct {
+	CAFile             string
+	InsecureSkipVerify bool
+}
+
+func (tc *TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{}
+	if tc == nil {
+		return config, nil
+	}
+
+	config.InsecureSkipVerify = tc.InsecureSkipVerify
+	if len(tc.CAFile) > 0 {
+		pem, err := ioutil.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA file %s", tc.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// leveledLogger provides the leveled logging methods of logger.Interface,
+// the filtered log messages are passed to the emit func.
+type leveledLogger struct {
+	level log.Level
+	emit  func(level log.Level, msg string)
+	// flush the pending messages before exiting for the fatal error
+	flush func()
+}
+
+// Debug ...
+func (ll *leveledLogger) Debug(v ...interface{}) {
+	ll.log(log.DebugLevel, fmt.Sprint(v...))
+}
+
+// Debugf with format
+func (ll *leveledLogger) Debugf(format string, v ...interface{}) {
+	ll.log(log.DebugLevel, fmt.Sprintf(format, v...))
+}
+
+// Info ...
+func (ll *leveledLogger) Info(v ...interface{}) {
+	ll.log(log.InfoLevel, fmt.Sprint(v...))
+}
+
+// Infof with format
+func (ll *leveledLogger) Infof(format string, v ...interface{}) {
+	ll.log(log.InfoLevel, fmt.Sprintf(format, v...))
+}
+
+// Warning ...
+func (ll *leveledLogger) Warning(v ...interface{}) {
+	ll.log(log.WarningLevel, fmt.Sprint(v...))
+}
+
+// Warningf with format
+func (ll *leveledLogger) Warningf(format string, v ...interface{}) {
+	ll.log(log.WarningLevel, fmt.Sprintf(format, v...))
+}
+
+// Error ...
+func (ll *leveledLogger) Error(v ...interface{}) {
+	ll.log(log.ErrorLevel, fmt.Sprint(v...))
+}
+
+// Errorf with format
+func (ll *leveledLogger) Errorf(format string, v ...interface{}) {
+	ll.log(log.ErrorLevel, fmt.Sprintf(format, v...))
+}
+
+// Fatal error
+func (ll *leveledLogger) Fatal(v ...interface{}) {
+	ll.log(log.FatalLevel, fmt.Sprint(v...))
+	ll.exit()
+}
+
+// Fatalf error
+func (ll *leveledLogger) Fatalf(format string, v ...interface{}) {
+	ll.log(log.FatalLevel, fmt.Sprintf(format, v...))
+	ll.exit()
+}
+
+func (ll *leveledLogger) log(level log.Level, msg string) {
+	if level >= ll.level {
+		ll.emit(level, msg)
+	}
+}
+
+// exit in the same way of the other loggers
+func (ll *leveledLogger) exit() {
+	if ll.flush != nil {
+		ll.flush()
+	}
+	os.Exit(1)
+}
+
+// remoteConn keeps the connection to the remote log collector and
+// reconnects it when the writing is failed.
+type remoteConn struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+
+	lock *sync.Mutex
+	conn net.Conn
+}
+
+func newRemoteConn(protocol, address string, tc *TLSConfig) (*remoteConn, error) {
+	if len(address) == 0 {
+		return nil, errors.New("missing address of the remote log collector")
+	}
+
+	rc := &remoteConn{
+		network: protocol,
+		address: address,
+		lock:    new(sync.Mutex),
+	}
+
+	switch protocol {
+	case "", ProtocolUDP:
+		rc.network = ProtocolUDP
+	case ProtocolTCP:
+	case ProtocolTLS:
+		config, err := tc.build()
+		if err != nil {
+			return nil, err
+		}
+		rc.tlsConfig = config
+	default:
+		return nil, fmt.Errorf("unsupported protocol '%s' of the remote log collector", protocol)
+	}
+
+	return rc, nil
+}
+
+// write the data, try to reconnect once if failed.
+func (rc *remoteConn) write(data []byte) error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if rc.conn == nil {
+			if rc.conn, err = rc.dial(); err != nil {
+				continue
+			}
+		}
+
+		rc.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err = rc.conn.Write(data); err == nil {
+			return nil
+		}
+
+		rc.conn.Close()
+		rc.conn = nil
+	}
+
+	return err
+}
+
+func (rc *remoteConn) dial() (net.Conn, error) {
+	if rc.network == ProtocolTLS {
+		return tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", rc.address, rc.tlsConfig)
+	}
+
+	return net.DialTimeout(rc.network, rc.address, dialTimeout)
+}
+
+func (rc *remoteConn) isStream() bool {
+	return rc.network != ProtocolUDP
+}
+
+// Close the connection
+func (rc *remoteConn) Close() error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if rc.conn == nil {
+		return nil
+	}
+
+	err := rc.conn.Close()
+	rc.conn = nil
+
+	return err
+}
+
+// reportError reports the failure of sending logs to the std error output,
+// the job service logger is not used as it may be the failed one.
+func reportError(name string, err error) {
+	stdlog.Printf("%s logger: failed to send log to the remote collector: %s", name, err)
+}
+
+func levelName(level log.Level) string {
+	switch level {
+	case log.DebugLevel:
+		return "DEBUG"
+	case log.InfoLevel:
+		return "INFO"
+	case log.WarningLevel:
+		return "WARNING"
+	case log.ErrorLevel:
+		return "ERROR"
+	case log.FatalLevel:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
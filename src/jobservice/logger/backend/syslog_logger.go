@@ -0,0 +1,191 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goharbor/harbor/src/common/utils/log"
+)
+
+const (
+	defaultSyslogAppName  = "jobservice"
+	defaultSyslogFacility = "local0"
+	// The SD-ID of the structured data element with the IANA example enterprise number
+	defaultSyslogSDID = "job@32473"
+	nilValue          = "-"
+)
+
+var syslogFacilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// SyslogConfig keeps the options of the syslog logger.
+type SyslogConfig struct {
+	// udp(default), tcp or tls
+	Protocol string
+	// host:port of the syslog server
+	Address string
+	// Facility name like 'local0'
+	Facility string
+	// APP-NAME of the message, 'jobservice' by default
+	AppName string
+	// SD-ID of the structured data element keeping the fields
+	SDID string
+	// Used when protocol is tls
+	TLS *TLSConfig
+	// Structured fields attached to each message
+	Fields Fields
+}
+
+// SyslogLogger is an implementation of logger.Interface.
+// It sends the log to the syslog server with the RFC5424 format,
+// the structured fields are kept in the STRUCTURED-DATA part.
+type SyslogLogger struct {
+	*leveledLogger
+
+	conn     *remoteConn
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	sd       string
+}
+
+// NewSyslogLogger creates a new syslog logger
+func NewSyslogLogger(level string, config SyslogConfig) (*SyslogLogger, error) {
+	conn, err := newRemoteConn(config.Protocol, config.Address, config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	facilityName := config.Facility
+	if len(facilityName) == 0 {
+		facilityName = defaultSyslogFacility
+	}
+	facility, ok := syslogFacilities[strings.ToLower(facilityName)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility '%s'", facilityName)
+	}
+
+	appName := config.AppName
+	if len(appName) == 0 {
+		appName = defaultSyslogAppName
+	}
+
+	sdID := config.SDID
+	if len(sdID) == 0 {
+		sdID = defaultSyslogSDID
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || len(hostname) == 0 {
+		hostname = nilValue
+	}
+
+	sl := &SyslogLogger{
+		conn:     conn,
+		facility: facility,
+		hostname: hostname,
+		appName:  appName,
+		procID:   fmt.Sprintf("%d", os.Getpid()),
+		sd:       structuredData(sdID, config.Fields),
+	}
+	sl.leveledLogger = &leveledLogger{
+		level: parseLevel(level),
+		emit:  sl.send,
+	}
+
+	return sl, nil
+}
+
+// Close the connection to the syslog server
+// Implements logger.Closer interface
+func (sl *SyslogLogger) Close() error {
+	return sl.conn.Close()
+}
+
+func (sl *SyslogLogger) send(level log.Level, msg string) {
+	data := sl.format(level, msg, time.Now())
+	if sl.conn.isStream() {
+		// Octet counting framing defined in RFC5425/RFC6587
+		data = append([]byte(fmt.Sprintf("%d ", len(data))), data...)
+	}
+
+	if err := sl.conn.write(data); err != nil {
+		reportError("syslog", err)
+	}
+}
+
+// format the message as: <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (sl *SyslogLogger) format(level log.Level, msg string, t time.Time) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<%d>1 %s %s %s %s %s %s %s",
+		sl.facility*8+severity(level),
+		t.Format("2006-01-02T15:04:05.000000Z07:00"),
+		sl.hostname,
+		sl.appName,
+		sl.procID,
+		levelName(level),
+		sl.sd,
+		strings.TrimRight(msg, "\n"),
+	)
+
+	return buf.Bytes()
+}
+
+func structuredData(sdID string, fields Fields) string {
+	if len(fields) == 0 {
+		return nilValue
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "[%s", sdID)
+	for _, k := range fields.keys() {
+		fmt.Fprintf(buf, ` %s="%s"`, k, sdParamEscaper.Replace(fields[k]))
+	}
+	buf.WriteString("]")
+
+	return buf.String()
+}
+
+// severity maps the log level to the syslog severity
+func severity(level log.Level) int {
+	switch level {
+	case log.DebugLevel:
+		return 7
+	case log.InfoLevel:
+		return 6
+	case log.WarningLevel:
+		return 4
+	case log.ErrorLevel:
+		return 3
+	case log.FatalLevel:
+		return 2
+	default:
+		return 6
+	}
+}
@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test syslog logger over udp
+func TestSyslogLoggerUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	l, err := NewSyslogLogger("INFO", SyslogConfig{
+		Address: pc.LocalAddr().String(),
+		Fields:  Fields{"job_id": "fake_job_ID", "job_name": "DEMO", "job_kind": "Generic"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Debug("filtered")
+	l.Errorf("%s", "TestSyslogLogger")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := string(buf[:n])
+	// local0(16)*8 + error(3)
+	if !strings.HasPrefix(msg, "<131>1 ") {
+		t.Fatalf("expect message with priority 131 but got: %s", msg)
+	}
+	if !strings.Contains(msg, ` ERROR [job@32473 job_id="fake_job_ID" job_kind="Generic" job_name="DEMO"] TestSyslogLogger`) {
+		t.Fatalf("expect structured data in message but got: %s", msg)
+	}
+}
+
+// Test syslog logger over tcp with octet counting framing
+func TestSyslogLoggerTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadString(']')
+		received <- line
+	}()
+
+	l, err := NewSyslogLogger("DEBUG", SyslogConfig{
+		Protocol: ProtocolTCP,
+		Address:  ln.Addr().String(),
+		Facility: "user",
+		Fields:   Fields{"job_id": `quoted"]`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Info("TestSyslogLogger")
+
+	select {
+	case line := <-received:
+		if !strings.Contains(line, `<14>1 `) || !strings.Contains(line, `job_id="quoted\"\]`) {
+			t.Fatalf("unexpected message: %s", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+// Test syslog logger with invalid settings
+func TestSyslogLoggerInvalid(t *testing.T) {
+	if _, err := NewSyslogLogger("INFO", SyslogConfig{}); err == nil {
+		t.Fatal("expect error for missing address but got nil")
+	}
+	if _, err := NewSyslogLogger("INFO", SyslogConfig{Address: "localhost:514", Protocol: "http"}); err == nil {
+		t.Fatal("expect error for unsupported protocol but got nil")
+	}
+	if _, err := NewSyslogLogger("INFO", SyslogConfig{Address: "localhost:514", Facility: "unknown"}); err == nil {
+		t.Fatal("expect error for unknown facility but got nil")
+	}
+}
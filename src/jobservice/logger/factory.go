@@ -2,7 +2,9 @@ package logger
 
 import (
 	"errors"
+	"fmt"
 	"path"
+	"time"
 
 	"github.com/goharbor/harbor/src/jobservice/logger/backend"
 )
@@ -87,3 +89,129 @@ func DBFactory(options ...OptionItem) (Interface, error) {
 
 	return backend.NewDBLogger(key, level, depth)
 }
+
+// SyslogFactory is factory of syslog logger
+func SyslogFactory(options ...OptionItem) (Interface, error) {
+	var (
+		level  string
+		config = backend.SyslogConfig{}
+		tc     = &backend.TLSConfig{}
+	)
+	for _, op := range options {
+		switch op.Field() {
+		case "level":
+			level = op.String()
+		case "protocol":
+			config.Protocol = op.String()
+		case "address":
+			config.Address = op.String()
+		case "facility":
+			config.Facility = op.String()
+		case "app_name":
+			config.AppName = op.String()
+		case "sd_id":
+			config.SDID = op.String()
+		case "ca_file":
+			tc.CAFile = op.String()
+		case "insecure_skip_verify":
+			tc.InsecureSkipVerify = op.Bool()
+		case "fields":
+			config.Fields = fieldsOption(op)
+		default:
+		}
+	}
+	config.TLS = tc
+
+	return backend.NewSyslogLogger(level, config)
+}
+
+// HTTPFactory is factory of http logger
+func HTTPFactory(options ...OptionItem) (Interface, error) {
+	var (
+		level  string
+		config = backend.HTTPConfig{}
+		tc     = &backend.TLSConfig{}
+	)
+	for _, op := range options {
+		switch op.Field() {
+		case "level":
+			level = op.String()
+		case "endpoint":
+			config.Endpoint = op.String()
+		case "headers":
+			config.Headers = stringMapOption(op)
+		case "batch_size":
+			config.BatchSize = op.Int()
+		case "flush_interval":
+			config.FlushInterval = time.Duration(op.Int()) * time.Second
+		case "ca_file":
+			tc.CAFile = op.String()
+		case "insecure_skip_verify":
+			tc.InsecureSkipVerify = op.Bool()
+		case "fields":
+			config.Fields = fieldsOption(op)
+		default:
+		}
+	}
+	config.TLS = tc
+
+	return backend.NewHTTPLogger(level, config)
+}
+
+// FluentdFactory is factory of fluentd forward logger
+func FluentdFactory(options ...OptionItem) (Interface, error) {
+	var (
+		level  string
+		config = backend.FluentdConfig{}
+		tc     = &backend.TLSConfig{}
+	)
+	for _, op := range options {
+		switch op.Field() {
+		case "level":
+			level = op.String()
+		case "protocol":
+			config.Protocol = op.String()
+		case "address":
+			config.Address = op.String()
+		case "tag":
+			config.Tag = op.String()
+		case "ca_file":
+			tc.CAFile = op.String()
+		case "insecure_skip_verify":
+			tc.InsecureSkipVerify = op.Bool()
+		case "fields":
+			config.Fields = fieldsOption(op)
+		default:
+		}
+	}
+	config.TLS = tc
+
+	return backend.NewFluentdLogger(level, config)
+}
+
+func fieldsOption(op OptionItem) backend.Fields {
+	return backend.Fields(stringMapOption(op))
+}
+
+// stringMapOption converts the option value to string map,
+// the map decoded from yaml is keyed with interface{}.
+func stringMapOption(op OptionItem) map[string]string {
+	res := make(map[string]string)
+	switch v := op.Raw().(type) {
+	case map[string]string:
+		for k, val := range v {
+			res[k] = val
+		}
+	case map[string]interface{}:
+		for k, val := range v {
+			res[k] = fmt.Sprintf("%v", val)
+		}
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			res[fmt.Sprintf("%v", k)] = fmt.Sprintf("%v", val)
+		}
+	default:
+	}
+
+	return res
+}
@@ -72,3 +72,33 @@ func TestDBFactoryErr1(t *testing.T) {
 	_, err := DBFactory(ois...)
 	require.NotNil(t, err)
 }
+
+// TestSyslogFactory
+func TestSyslogFactory(t *testing.T) {
+	ois := make([]OptionItem, 0)
+	ois = append(ois, OptionItem{"level", "DEBUG"})
+	ois = append(ois, OptionItem{"protocol", "udp"})
+	ois = append(ois, OptionItem{"address", "localhost:514"})
+	ois = append(ois, OptionItem{"fields", map[string]string{"job_id": "fake_job_ID"}})
+
+	l, err := SyslogFactory(ois...)
+	require.Nil(t, err)
+	require.Equal(t, LoggerNameSyslog, GetLoggerName(l))
+}
+
+// TestHTTPFactory
+func TestHTTPFactory(t *testing.T) {
+	ois := make([]OptionItem, 0)
+	ois = append(ois, OptionItem{"level", "DEBUG"})
+	ois = append(ois, OptionItem{"endpoint", "http://localhost:9880/harbor.job"})
+	ois = append(ois, OptionItem{"headers", map[interface{}]interface{}{"Authorization": "Bearer token"}})
+	ois = append(ois, OptionItem{"batch_size", 10})
+
+	l, err := HTTPFactory(ois...)
+	require.Nil(t, err)
+	require.Equal(t, LoggerNameHTTP, GetLoggerName(l))
+
+	if closer, ok := l.(Closer); ok {
+		closer.Close()
+	}
+}
@@ -2,6 +2,7 @@ package getter
 
 import (
 	"errors"
+	"github.com/astaxie/beego/orm"
 	"github.com/goharbor/harbor/src/common/dao"
 )
 
@@ -15,12 +16,16 @@ func NewDBGetter() *DBGetter {
 }
 
 // Retrieve implements @Interface.Retrieve
+// The log archived by the sweeper is retrieved from the archive table transparently.
 func (dbg *DBGetter) Retrieve(logID string) ([]byte, error) {
 	if len(logID) == 0 {
 		return nil, errors.New("empty log identify")
 	}
 
 	jobLog, err := dao.GetJobLog(logID)
+	if err == orm.ErrNoRows {
+		jobLog, err = dao.GetArchivedJobLog(logID)
+	}
 	if err != nil {
 		return nil, err
 	}
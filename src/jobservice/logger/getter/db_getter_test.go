@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestMain(m *testing.M) {
@@ -74,3 +75,30 @@ func TestDBGetterError(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, 1, count)
 }
+
+// TestDBGetterArchived
+func TestDBGetterArchived(t *testing.T) {
+	uuid := "uuid_for_unit_test_getter_archived"
+	l, err := backend.NewDBLogger(uuid, "DEBUG", 4)
+	require.Nil(t, err)
+
+	l.Debug("JobLog Debug: TestDBGetterArchived")
+	l.Close()
+
+	// Make the log outdated for archiving
+	_, err = dao.GetOrmer().Raw(`update job_log set creation_time = ? where job_uuid = ?`, time.Now().Add(-48*time.Hour), uuid).Exec()
+	require.Nil(t, err)
+
+	sweeper.PrepareDBSweep()
+	dbSweeper := sweeper.NewArchivingDBSweeper(7, 1)
+	count, err := dbSweeper.Sweep()
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+
+	dbGetter := NewDBGetter()
+	_, err = dbGetter.Retrieve(uuid)
+	require.Nil(t, err)
+
+	_, err = dao.DeleteArchivedJobLogsBefore(time.Now().Add(time.Minute))
+	require.Nil(t, err)
+}
@@ -1,9 +1,11 @@
 package getter
 
 import (
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path"
 
 	"github.com/goharbor/harbor/src/jobservice/errs"
@@ -22,6 +24,7 @@ func NewFileGetter(baseDir string) *FileGetter {
 }
 
 // Retrieve implements @Interface.Retrieve
+// The log file compressed by the sweeper is decompressed transparently.
 func (fg *FileGetter) Retrieve(logID string) ([]byte, error) {
 	if len(logID) == 0 {
 		return nil, errors.New("empty log identify")
@@ -29,9 +32,30 @@ func (fg *FileGetter) Retrieve(logID string) ([]byte, error) {
 
 	fPath := path.Join(fg.baseDir, fmt.Sprintf("%s.log", logID))
 
-	if !utils.FileExists(fPath) {
-		return nil, errs.NoObjectFoundError(logID)
+	if utils.FileExists(fPath) {
+		return ioutil.ReadFile(fPath)
 	}
 
-	return ioutil.ReadFile(fPath)
+	gzPath := fmt.Sprintf("%s.gz", fPath)
+	if utils.FileExists(gzPath) {
+		return readCompressedFile(gzPath)
+	}
+
+	return nil, errs.NoObjectFoundError(logID)
+}
+
+func readCompressedFile(filePath string) ([]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return ioutil.ReadAll(zr)
 }
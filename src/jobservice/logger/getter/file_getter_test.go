@@ -1,6 +1,7 @@
 package getter
 
 import (
+	"compress/gzip"
 	"io/ioutil"
 	"os"
 	"path"
@@ -39,3 +40,37 @@ func TestLogDataGetter(t *testing.T) {
 		t.Errorf("expect reading 5 bytes but got %d bytes", len(data))
 	}
 }
+
+// Test reading the compressed log data
+func TestLogDataGetterCompressed(t *testing.T) {
+	fakeLog := path.Join(os.TempDir(), "TestLogDataGetterCompressed.log.gz")
+	f, err := os.Create(fakeLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Remove(fakeLog); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	fg := NewFileGetter(os.TempDir())
+	data, err := fg.Retrieve("TestLogDataGetterCompressed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "hello" {
+		t.Errorf("expect reading 'hello' but got '%s'", data)
+	}
+}
@@ -13,6 +13,12 @@ const (
 	LoggerNameStdOutput = "STD_OUTPUT"
 	// LoggerNameDB is the unique name of the DB logger.
 	LoggerNameDB = "DB"
+	// LoggerNameSyslog is the unique name of the syslog logger.
+	LoggerNameSyslog = "SYSLOG"
+	// LoggerNameHTTP is the unique name of the http logger.
+	LoggerNameHTTP = "HTTP"
+	// LoggerNameFluentd is the unique name of the fluentd forward logger.
+	LoggerNameFluentd = "FLUENTD"
 )
 
 // Declaration is used to declare a supported logger.
@@ -36,6 +42,17 @@ var knownLoggers = map[string]*Declaration{
 	LoggerNameStdOutput: {StdFactory, nil, nil, true},
 	// DB logger
 	LoggerNameDB: {DBFactory, DBSweeperFactory, DBGetterFactory, false},
+	// Syslog logger
+	LoggerNameSyslog: {SyslogFactory, nil, nil, false},
+	// Http logger
+	LoggerNameHTTP: {HTTPFactory, nil, nil, false},
+	// Fluentd forward logger
+	LoggerNameFluentd: {FluentdFactory, nil, nil, false},
+}
+
+// IsStructuredLogger checks if the logger with the name supports the structured fields.
+func IsStructuredLogger(name string) bool {
+	return name == LoggerNameSyslog || name == LoggerNameHTTP || name == LoggerNameFluentd
 }
 
 // IsKnownLogger checks if the logger is supported with name.
@@ -102,6 +119,12 @@ func GetLoggerName(l Interface) string {
 		name = LoggerNameStdOutput
 	case *backend.FileLogger:
 		name = LoggerNameFile
+	case *backend.SyslogLogger:
+		name = LoggerNameSyslog
+	case *backend.HTTPLogger:
+		name = LoggerNameHTTP
+	case *backend.FluentdLogger:
+		name = LoggerNameFluentd
 	default:
 		name = reflect.TypeOf(l).String()
 	}
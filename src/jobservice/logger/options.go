@@ -1,5 +1,7 @@
 package logger
 
+import "strconv"
+
 // options keep settings for loggers/sweepers
 // Indexed by the logger unique name
 type options struct {
@@ -93,6 +95,23 @@ func (o *OptionItem) String() string {
 	return o.val.(string)
 }
 
+// Bool returns the boolean value of option, the string form like "true" is parsed,
+// false is returned if the value is not a valid boolean
+func (o *OptionItem) Bool() bool {
+	switch v := o.val.(type) {
+	case bool:
+		return v
+	case string:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false
+		}
+		return b
+	default:
+		return false
+	}
+}
+
 // Raw returns the raw value
 func (o *OptionItem) Raw() interface{} {
 	return o.val
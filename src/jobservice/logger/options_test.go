@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOptionItemBool
+func TestOptionItemBool(t *testing.T) {
+	cases := []struct {
+		val      interface{}
+		expected bool
+	}{
+		{nil, false},
+		{true, true},
+		{false, false},
+		{"true", true},
+		{"TRUE", true},
+		{"false", false},
+		{"invalid", false},
+		{1, false},
+	}
+	for _, c := range cases {
+		o := &OptionItem{"insecure_skip_verify", c.val}
+		assert.Equal(t, c.expected, o.Bool(), "%v", c.val)
+	}
+}
@@ -10,8 +10,12 @@ var dbInit = make(chan int, 1)
 var isDBInit = false
 
 // DBSweeper is used to sweep the DB logs
+//
+// If archiveAfter is set, the logs older than archiveAfter days are moved to
+// the archive table, and the archived logs older than duration days are removed.
 type DBSweeper struct {
-	duration int
+	duration     int
+	archiveAfter int
 }
 
 // NewDBSweeper is constructor of DBSweeper
@@ -21,6 +25,14 @@ func NewDBSweeper(duration int) *DBSweeper {
 	}
 }
 
+// NewArchivingDBSweeper is constructor of DBSweeper with archival mode enabled
+func NewArchivingDBSweeper(duration int, archiveAfter int) *DBSweeper {
+	return &DBSweeper{
+		duration:     duration,
+		archiveAfter: archiveAfter,
+	}
+}
+
 // Sweep logs
 func (dbs *DBSweeper) Sweep() (int, error) {
 	// DB initialization not completed, waiting
@@ -34,6 +46,20 @@ func (dbs *DBSweeper) Sweep() (int, error) {
 		return 0, fmt.Errorf("sweep logs in DB failed before %s with error: %s", before, err)
 	}
 
+	if dbs.archiveAfter > 0 {
+		archived, err := dao.ArchiveJobLogsBefore(time.Now().Add(time.Duration(dbs.archiveAfter) * oneDay * -1))
+		if err != nil {
+			return int(count), fmt.Errorf("archive logs in DB failed with error: %s", err)
+		}
+
+		deleted, err := dao.DeleteArchivedJobLogsBefore(before)
+		if err != nil {
+			return int(count + archived), fmt.Errorf("sweep archived logs in DB failed before %s with error: %s", before, err)
+		}
+
+		count += archived + deleted
+	}
+
 	return int(count), nil
 }
 
@@ -1,7 +1,9 @@
 package sweeper
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -11,12 +13,21 @@ import (
 
 const (
 	oneDay = 24 * time.Hour
+
+	// CompressedLogExt is the extension appended to the compressed log files
+	CompressedLogExt = ".gz"
 )
 
 // FileSweeper is used to sweep the file logs
+//
+// If archiveAfter is set, the log files older than archiveAfter days are compressed
+// in place with gzip. The log files (compressed or not) older than duration days are
+// removed, or moved to the archiveDir if it's set.
 type FileSweeper struct {
-	duration int
-	workDir  string
+	duration     int
+	workDir      string
+	archiveAfter int
+	archiveDir   string
 }
 
 // NewFileSweeper is constructor of FileSweeper
@@ -27,6 +38,16 @@ func NewFileSweeper(workDir string, duration int) *FileSweeper {
 	}
 }
 
+// NewArchivingFileSweeper is constructor of FileSweeper with archival mode enabled
+func NewArchivingFileSweeper(workDir string, duration int, archiveAfter int, archiveDir string) *FileSweeper {
+	return &FileSweeper{
+		workDir:      workDir,
+		duration:     duration,
+		archiveAfter: archiveAfter,
+		archiveDir:   archiveDir,
+	}
+}
+
 // Sweep logs
 func (fs *FileSweeper) Sweep() (int, error) {
 	cleared := 0
@@ -41,14 +62,45 @@ func (fs *FileSweeper) Sweep() (int, error) {
 		return 0, nil
 	}
 
+	if len(fs.archiveDir) > 0 {
+		if err := os.MkdirAll(fs.archiveDir, os.ModePerm); err != nil {
+			return 0, fmt.Errorf("create log archive dir '%s' error: %s", fs.archiveDir, err)
+		}
+	}
+
 	// Start to sweep log files
 	// Record all errors
 	errs := make([]string, 0)
 	for _, logFile := range logFiles {
-		if logFile.ModTime().Add(time.Duration(fs.duration) * oneDay).Before(time.Now()) {
-			logFilePath := path.Join(fs.workDir, logFile.Name())
-			if err := os.Remove(logFilePath); err != nil {
-				errs = append(errs, fmt.Sprintf("remove log file '%s' error: %s", logFilePath, err))
+		if logFile.IsDir() {
+			continue
+		}
+
+		logFilePath := path.Join(fs.workDir, logFile.Name())
+
+		if isOutdated(logFile.ModTime(), fs.duration) {
+			if len(fs.archiveDir) > 0 {
+				archivedPath := path.Join(fs.archiveDir, logFile.Name())
+				if err := moveFile(logFilePath, archivedPath); err != nil {
+					errs = append(errs, fmt.Sprintf("move log file '%s' to '%s' error: %s", logFilePath, archivedPath, err))
+					continue // go on for next one
+				}
+			} else {
+				if err := os.Remove(logFilePath); err != nil {
+					errs = append(errs, fmt.Sprintf("remove log file '%s' error: %s", logFilePath, err))
+					continue // go on for next one
+				}
+			}
+
+			cleared++
+			continue
+		}
+
+		if fs.archiveAfter > 0 &&
+			!strings.HasSuffix(logFile.Name(), CompressedLogExt) &&
+			isOutdated(logFile.ModTime(), fs.archiveAfter) {
+			if err := compressFile(logFilePath, logFile.ModTime()); err != nil {
+				errs = append(errs, fmt.Sprintf("compress log file '%s' error: %s", logFilePath, err))
 				continue // go on for next one
 			}
 
@@ -68,3 +120,85 @@ func (fs *FileSweeper) Sweep() (int, error) {
 func (fs *FileSweeper) Duration() int {
 	return fs.duration
 }
+
+func isOutdated(modTime time.Time, days int) bool {
+	return modTime.Add(time.Duration(days) * oneDay).Before(time.Now())
+}
+
+// compressFile compresses the file to a new file with the gzip extension and removes the original one.
+// The modification time is kept to make sure the compressed file is swept on schedule.
+func compressFile(filePath string, modTime time.Time) (err error) {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	gzPath := filePath + CompressedLogExt
+	dst, err := os.OpenFile(gzPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// Do not leave the broken compressed file
+			_ = os.Remove(gzPath)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chtimes(gzPath, modTime, modTime); err != nil {
+		return err
+	}
+
+	return os.Remove(filePath)
+}
+
+// moveFile moves the file to the target path, copy is used if the rename
+// is not working across the file systems.
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chtimes(to, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+
+	return os.Remove(from)
+}
@@ -43,3 +43,65 @@ func TestFileSweeper(t *testing.T) {
 		t.Errorf("expect count 1 but got %d", count)
 	}
 }
+
+// Test file sweeper with the archival mode
+func TestArchivingFileSweeper(t *testing.T) {
+	workDir := path.Join(os.TempDir(), "job_logs_archiving")
+	archiveDir := path.Join(os.TempDir(), "job_logs_archived")
+	if err := os.Mkdir(workDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(workDir); err != nil {
+			t.Error(err)
+		}
+		if err := os.RemoveAll(archiveDir); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	writeLog := func(name string, days int64) string {
+		logFile := path.Join(workDir, name)
+		if err := ioutil.WriteFile(logFile, []byte("hello"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Unix(time.Now().Unix()-days*24*3600, 0)
+		if err := os.Chtimes(logFile, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		return logFile
+	}
+
+	fresh := writeLog("fresh.log", 0)
+	toCompress := writeLog("to_compress.log", 3)
+	toArchive := writeLog("to_archive.log.gz", 6)
+
+	fs := NewArchivingFileSweeper(workDir, 5, 2, archiveDir)
+	count, err := fs.Sweep()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expect count 2 but got %d", count)
+	}
+
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("expect fresh log kept but got error: %s", err)
+	}
+	if _, err := os.Stat(toCompress); !os.IsNotExist(err) {
+		t.Errorf("expect compressed log removed but got error: %v", err)
+	}
+	info, err := os.Stat(toCompress + CompressedLogExt)
+	if err != nil {
+		t.Fatalf("expect compressed log existing but got error: %s", err)
+	}
+	if time.Since(info.ModTime()) < 2*oneDay {
+		t.Errorf("expect modification time of compressed log kept but got %s", info.ModTime())
+	}
+	if _, err := os.Stat(toArchive); !os.IsNotExist(err) {
+		t.Errorf("expect outdated log moved but got error: %v", err)
+	}
+	if _, err := os.Stat(path.Join(archiveDir, "to_archive.log.gz")); err != nil {
+		t.Errorf("expect outdated log existing in archive dir but got error: %s", err)
+	}
+}
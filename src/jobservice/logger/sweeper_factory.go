@@ -2,6 +2,7 @@ package logger
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/goharbor/harbor/src/jobservice/logger/sweeper"
 )
@@ -11,7 +12,7 @@ type SweeperFactory func(options ...OptionItem) (sweeper.Interface, error)
 
 // FileSweeperFactory creates file sweeper.
 func FileSweeperFactory(options ...OptionItem) (sweeper.Interface, error) {
-	var workDir, duration = "", 1
+	var workDir, archiveDir, duration, archiveAfter = "", "", 1, 0
 	for _, op := range options {
 		switch op.Field() {
 		case "work_dir":
@@ -20,6 +21,10 @@ func FileSweeperFactory(options ...OptionItem) (sweeper.Interface, error) {
 			if op.Int() > 0 {
 				duration = op.Int()
 			}
+		case "archive_after":
+			archiveAfter = op.Int()
+		case "archive_dir":
+			archiveDir = op.String()
 		default:
 		}
 	}
@@ -28,21 +33,56 @@ func FileSweeperFactory(options ...OptionItem) (sweeper.Interface, error) {
 		return nil, errors.New("missing required option 'work_dir'")
 	}
 
+	if err := validateArchiveAfter(archiveAfter, duration); err != nil {
+		return nil, err
+	}
+
+	if archiveAfter > 0 || len(archiveDir) > 0 {
+		if archiveDir == workDir {
+			return nil, errors.New("option 'archive_dir' should not be same with 'work_dir'")
+		}
+
+		return sweeper.NewArchivingFileSweeper(workDir, duration, archiveAfter, archiveDir), nil
+	}
+
 	return sweeper.NewFileSweeper(workDir, duration), nil
 }
 
 // DBSweeperFactory creates DB sweeper.
 func DBSweeperFactory(options ...OptionItem) (sweeper.Interface, error) {
-	var duration = 1
+	var duration, archiveAfter = 1, 0
 	for _, op := range options {
 		switch op.Field() {
 		case "duration":
 			if op.Int() > 0 {
 				duration = op.Int()
 			}
+		case "archive_after":
+			archiveAfter = op.Int()
 		default:
 		}
 	}
 
+	if err := validateArchiveAfter(archiveAfter, duration); err != nil {
+		return nil, err
+	}
+
+	if archiveAfter > 0 {
+		return sweeper.NewArchivingDBSweeper(duration, archiveAfter), nil
+	}
+
 	return sweeper.NewDBSweeper(duration), nil
 }
+
+// validateArchiveAfter checks the archival threshold which should be earlier than the sweeping one.
+func validateArchiveAfter(archiveAfter int, duration int) error {
+	if archiveAfter < 0 {
+		return fmt.Errorf("invalid option 'archive_after': %d", archiveAfter)
+	}
+
+	if archiveAfter > 0 && archiveAfter >= duration {
+		return fmt.Errorf("option 'archive_after' (%d days) should be less than the sweeper duration (%d days)", archiveAfter, duration)
+	}
+
+	return nil
+}
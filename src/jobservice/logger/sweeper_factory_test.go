@@ -32,3 +32,39 @@ func TestDBSweeperFactory(t *testing.T) {
 	_, err := DBSweeperFactory(ois...)
 	require.Nil(t, err)
 }
+
+// TestArchivingSweeperFactory
+func TestArchivingSweeperFactory(t *testing.T) {
+	ois := make([]OptionItem, 0)
+	ois = append(ois, OptionItem{"work_dir", "/tmp"})
+	ois = append(ois, OptionItem{"duration", 365})
+	ois = append(ois, OptionItem{"archive_after", 7})
+	ois = append(ois, OptionItem{"archive_dir", "/tmp/archived"})
+
+	_, err := FileSweeperFactory(ois...)
+	require.Nil(t, err)
+
+	_, err = DBSweeperFactory(ois...)
+	require.Nil(t, err)
+}
+
+// TestArchivingSweeperFactoryErr
+func TestArchivingSweeperFactoryErr(t *testing.T) {
+	ois := make([]OptionItem, 0)
+	ois = append(ois, OptionItem{"work_dir", "/tmp"})
+	ois = append(ois, OptionItem{"duration", 7})
+	ois = append(ois, OptionItem{"archive_after", 7})
+
+	_, err := FileSweeperFactory(ois...)
+	require.NotNil(t, err)
+
+	_, err = DBSweeperFactory(ois...)
+	require.NotNil(t, err)
+
+	ois = make([]OptionItem, 0)
+	ois = append(ois, OptionItem{"work_dir", "/tmp"})
+	ois = append(ois, OptionItem{"archive_dir", "/tmp"})
+
+	_, err = FileSweeperFactory(ois...)
+	require.NotNil(t, err)
+}
@@ -30,12 +30,21 @@ type JobData struct {
 	StatusHook string       `json:"status_hook"`
 }
 
+// BatchJobRequest is the request of launching a batch of jobs.
+// The jobs are enqueued atomically, either all of them or none of them are enqueued.
+// The status hook is called only once when all the jobs of the batch are completed.
+type BatchJobRequest struct {
+	Jobs       []*JobData `json:"jobs"`
+	StatusHook string     `json:"status_hook"`
+}
+
 // JobMetadata stores the metadata of job.
 type JobMetadata struct {
 	JobKind       string `json:"kind"`
 	ScheduleDelay uint64 `json:"schedule_delay,omitempty"`
 	Cron          string `json:"cron_spec,omitempty"`
 	IsUnique      bool   `json:"unique"`
+	Queue         string `json:"queue,omitempty"` // the default queue is used if it's not set
 }
 
 // JobStats keeps the result of job launching.
@@ -62,6 +71,26 @@ type JobStatData struct {
 	Executions           []string `json:"executions,omitempty"`      // For the jobs like periodic jobs, which may execute multiple times
 	UpstreamJobID        string   `json:"upstream_job_id,omitempty"` // Ref the upstream job if existing
 	IsMultipleExecutions bool     `json:"multiple_executions"`       // Indicate if the job has subsequent executions
+	BatchID              string   `json:"batch_id,omitempty"`        // Ref the batch if the job is launched in batch
+	Queue                string   `json:"queue,omitempty"`           // The queue where the job is put
+}
+
+// BatchStats keeps the result of batch launching.
+type BatchStats struct {
+	Stats *BatchStatData `json:"batch"`
+}
+
+// BatchStatData keeps the aggregated stats of the jobs in the batch.
+type BatchStatData struct {
+	BatchID     string           `json:"id"`
+	Status      string           `json:"status"`
+	Total       int64            `json:"total"`
+	Counts      map[string]int64 `json:"counts"` // key is the job status
+	JobIDs      []string         `json:"job_ids"`
+	RefLink     string           `json:"ref_link,omitempty"`
+	EnqueueTime int64            `json:"enqueue_time"`
+	UpdateTime  int64            `json:"update_time"`
+	HookStatus  string           `json:"hook_status,omitempty"`
 }
 
 // JobPoolStats represents the healthy and status of all the running worker pools.
@@ -75,6 +104,7 @@ type JobPoolStatsData struct {
 	StartedAt    int64    `json:"started_at"`
 	HeartbeatAt  int64    `json:"heartbeat_at"`
 	JobNames     []string `json:"job_names"`
+	Queues       []string `json:"queues"`
 	Concurrency  uint     `json:"concurrency"`
 	Status       string   `json:"status"`
 }
@@ -84,12 +114,51 @@ type JobActionRequest struct {
 	Action string `json:"action"`
 }
 
+// DeadJobData keeps the info of the failed job which is put into the dead queue.
+// The job parameters are not exposed as they may contain credentials.
+type DeadJobData struct {
+	JobID     string `json:"id"`
+	JobName   string `json:"name"`
+	Fails     int64  `json:"fails"`
+	LastError string `json:"last_error"`
+	FailedAt  int64  `json:"failed_at"`
+	DieAt     int64  `json:"die_at"`
+}
+
+// DeadJobList keeps one page of the dead jobs.
+type DeadJobList struct {
+	Total int64          `json:"total"`
+	Jobs  []*DeadJobData `json:"jobs"`
+}
+
+// DeadJobsActionRequest defines for triggering action like retry/purge on the dead jobs.
+// If 'all' is set, the action will be applied to all the dead jobs and 'job_ids' is ignored.
+type DeadJobsActionRequest struct {
+	Action string   `json:"action"`
+	JobIDs []string `json:"job_ids,omitempty"`
+	All    bool     `json:"all,omitempty"`
+}
+
+// DeadJobsActionResult keeps the result of the action applied to the dead jobs.
+type DeadJobsActionResult struct {
+	Succeeded []string          `json:"succeeded"`
+	Failed    map[string]string `json:"failed,omitempty"` // key is job ID and value is the error
+}
+
+// DrainRequest defines for putting the node into the drain mode.
+// Timeout is the seconds to wait for the in-flight jobs, the configured one is used if it's not set.
+type DrainRequest struct {
+	Timeout int64 `json:"timeout,omitempty"`
+}
+
 // JobStatusChange is designed for reporting the status change via hook.
 type JobStatusChange struct {
 	JobID    string       `json:"job_id"`
 	Status   string       `json:"status"`
 	CheckIn  string       `json:"check_in,omitempty"`
 	Metadata *JobStatData `json:"metadata,omitempty"`
+	// Only for the completion event of the batch, 'JobID' is the ID of the batch then
+	Batch *BatchStatData `json:"batch,omitempty"`
 }
 
 // Message is designed for sub/pub messages
@@ -97,3 +166,66 @@ type Message struct {
 	Event string
 	Data  interface{} // generic format
 }
+
+// HookEvent keeps the status change event which is pending to be delivered to the hook.
+type HookEvent struct {
+	ID            string           `json:"id"`
+	JobID         string           `json:"job_id"`
+	HookURL       string           `json:"hook_url"`
+	Payload       *JobStatusChange `json:"payload"`
+	Attempts      uint             `json:"attempts"`
+	CreatedAt     int64            `json:"created_at"`
+	LastAttemptAt int64            `json:"last_attempt_at,omitempty"`
+	LastError     string           `json:"last_error,omitempty"`
+}
+
+// HookEventList keeps the undelivered hook events of the job.
+// NextAttemptAt is the time of next delivery attempt of the first event in the list.
+type HookEventList struct {
+	JobID         string       `json:"job_id"`
+	NextAttemptAt int64        `json:"next_attempt_at,omitempty"`
+	Events        []*HookEvent `json:"events"`
+}
+
+// HookDeliveryStats keeps the metrics of the hook event delivery.
+// The counters are accumulated by the current node since it is started,
+// the pending numbers are shared by all the nodes.
+type HookDeliveryStats struct {
+	Delivered     uint64 `json:"delivered"`
+	Failures      uint64 `json:"failures"`
+	Expired       uint64 `json:"expired"`
+	PendingEvents int64  `json:"pending_events"`
+	PendingJobs   int64  `json:"pending_jobs"`
+}
+
+// JobMetrics keeps the metrics of the job service for monitoring.
+type JobMetrics struct {
+	Queues  []*JobQueueMetrics  `json:"queues"`
+	Running map[string]int64    `json:"running"` // key is job name
+	Pools   []*JobPoolStatsData `json:"worker_pools"`
+	Runs    *JobRunMetrics      `json:"runs"`
+	Hooks   HookDeliveryStats   `json:"hooks"`
+}
+
+// JobQueueMetrics keeps the depth and the latency (in seconds) of the job queue.
+type JobQueueMetrics struct {
+	JobName string `json:"job_name"`
+	Queue   string `json:"queue"`
+	Count   int64  `json:"count"`
+	Latency int64  `json:"latency"`
+}
+
+// JobRunMetrics keeps the accumulated metrics of the finished job runs.
+type JobRunMetrics struct {
+	Counts    map[string]map[string]int64   `json:"counts"`    // job name -> final status -> count
+	Durations map[string]*DurationHistogram `json:"durations"` // key is job name
+}
+
+// DurationHistogram keeps the distribution of the durations (in seconds).
+// Counts are cumulative and mapped to the upper bounds in Buckets one by one.
+type DurationHistogram struct {
+	Buckets []float64 `json:"buckets"`
+	Counts  []int64   `json:"counts"`
+	Sum     float64   `json:"sum"`
+	Count   int64     `json:"count"`
+}
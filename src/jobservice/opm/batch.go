@@ -0,0 +1,61 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opm
+
+import (
+	"github.com/goharbor/harbor/src/jobservice/job"
+)
+
+// The job statuses counted in the batch stats
+var batchCountedStatuses = []string{
+	job.JobStatusPending,
+	job.JobStatusRunning,
+	job.JobStatusStopped,
+	job.JobStatusCancelled,
+	job.JobStatusError,
+	job.JobStatusSuccess,
+}
+
+// batchStatus aggregates the status of the batch from the status counts of its jobs.
+// The batch is 'Pending' before any job is picked up and 'Running' until all the jobs are
+// completed, then it's 'Success' if all the jobs are successful, otherwise it's 'Error'.
+// The failed jobs which will be retried, counted by retrying, are not completed yet.
+func batchStatus(counts map[string]int64, retrying, total int64) string {
+	if isBatchCompleted(counts, retrying, total) {
+		if counts[job.JobStatusSuccess] == total {
+			return job.JobStatusSuccess
+		}
+
+		return job.JobStatusError
+	}
+
+	if counts[job.JobStatusPending] >= total {
+		return job.JobStatusPending
+	}
+
+	return job.JobStatusRunning
+}
+
+// isBatchCompleted checks if all the jobs of the batch are in the final status,
+// the failed jobs which will be retried are excluded.
+func isBatchCompleted(counts map[string]int64, retrying, total int64) bool {
+	var completed int64
+	for _, status := range observedStatuses {
+		completed += counts[status]
+	}
+	completed -= retrying
+
+	return total > 0 && completed >= total
+}
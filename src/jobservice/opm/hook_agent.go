@@ -0,0 +1,473 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/utils"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	hookDeliveryInterval   = 2 * time.Second
+	hookDeliveryLease      = 5 * time.Minute
+	maxEventsPerDelivery   = 20
+	maxConcurrentDelivery  = 10
+	hookRetryBaseInterval  = 5 * time.Second
+	hookRetryMaxInterval   = 10 * time.Minute
+	defaultHookEventMaxAge = 24 * time.Hour
+)
+
+// HookEventMaxAge is the max age of the hook event. The event which is not delivered
+// within the age will be dropped.
+var HookEventMaxAge = defaultHookEventMaxAge
+
+// Claim the job for delivering its hook events if the delivery is due.
+// KEYS[1]: the scheduling zset, ARGV[1]: job ID, ARGV[2]: now, ARGV[3]: lease deadline
+var claimScript = redis.NewScript(1, `
+local score = redis.call('zscore', KEYS[1], ARGV[1])
+if score and tonumber(score) <= tonumber(ARGV[2]) then
+	redis.call('zadd', KEYS[1], ARGV[3], ARGV[1])
+	return 1
+end
+return 0
+`)
+
+// Release the claimed job if no more events left, otherwise make the delivery due at once.
+// KEYS[1]: the event list, KEYS[2]: the scheduling zset, ARGV[1]: job ID, ARGV[2]: now
+var releaseScript = redis.NewScript(2, `
+if redis.call('llen', KEYS[1]) == 0 then
+	return redis.call('zrem', KEYS[2], ARGV[1])
+end
+redis.call('zadd', KEYS[2], ARGV[2], ARGV[1])
+return 0
+`)
+
+// HookAgent delivers the job status change events to the registered hooks.
+// The events are persisted before delivering and retried with exponential backoff
+// until they're successfully delivered or expired.
+type HookAgent interface {
+	// Start the delivery loop
+	Start()
+
+	// Stop the delivery loop
+	Stop()
+
+	// Submit the status change event of the job to be delivered to the hook
+	//
+	// hookURL string                  : the hook url
+	// change *models.JobStatusChange  : the status change event
+	//
+	// Returns:
+	//  error if the event is failed to be persisted
+	Submit(hookURL string, change *models.JobStatusChange) error
+
+	// Events returns the undelivered hook events of the specified job
+	//
+	// jobID string : ID of the job
+	//
+	// Returns:
+	//  the undelivered events in order
+	//  error if meet any problems
+	Events(jobID string) (*models.HookEventList, error)
+
+	// Stats returns the metrics of the hook events delivery
+	//
+	// Returns:
+	//  the delivery stats
+	//  error if meet any problems
+	Stats() (models.HookDeliveryStats, error)
+}
+
+// RedisHookAgent implements HookAgent based on redis.
+//
+// The events of each job are kept in a redis list to be delivered in order. The jobs
+// with undelivered events are kept in a sorted set scored by the next delivery time.
+type RedisHookAgent struct {
+	namespace string
+	redisPool *redis.Pool
+	context   context.Context
+	client    *HookClient
+	trigger   chan struct{}
+	stopChan  chan struct{}
+	stopOnce  *sync.Once
+	doneChan  chan struct{}
+
+	delivered uint64
+	failures  uint64
+	expired   uint64
+}
+
+// NewRedisHookAgent is constructor of RedisHookAgent
+func NewRedisHookAgent(ctx context.Context, namespace string, redisPool *redis.Pool) *RedisHookAgent {
+	return &RedisHookAgent{
+		namespace: namespace,
+		redisPool: redisPool,
+		context:   ctx,
+		client:    DefaultHookClient,
+		trigger:   make(chan struct{}, 1),
+		stopChan:  make(chan struct{}),
+		stopOnce:  new(sync.Once),
+		doneChan:  make(chan struct{}),
+	}
+}
+
+// Start is implementation of same method in HookAgent interface.
+func (ra *RedisHookAgent) Start() {
+	go ra.loop()
+	logger.Info("Redis hook agent is started")
+}
+
+// Stop is implementation of same method in HookAgent interface.
+// The loop may have already exited on the cancellation of the context, and Stop may be
+// called more than once, so the stop signal is a channel closed once and the done channel
+// is closed by the loop on exiting.
+func (ra *RedisHookAgent) Stop() {
+	ra.stopOnce.Do(func() {
+		close(ra.stopChan)
+	})
+	<-ra.doneChan
+}
+
+// Submit is implementation of same method in HookAgent interface.
+func (ra *RedisHookAgent) Submit(hookURL string, change *models.JobStatusChange) error {
+	if change == nil {
+		return errors.New("nil status change event")
+	}
+
+	event := &models.HookEvent{
+		ID:        utils.MakeIdentifier(),
+		JobID:     change.JobID,
+		HookURL:   hookURL,
+		Payload:   change,
+		CreatedAt: time.Now().Unix(),
+	}
+	rawJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("RPUSH", utils.KeyHookEvents(ra.namespace, event.JobID), rawJSON); err != nil {
+		return err
+	}
+	// NX: do not disturb the schedule of the job which has pending events
+	if err := conn.Send("ZADD", utils.KeyHookEventJobs(ra.namespace), "NX", event.CreatedAt, event.JobID); err != nil {
+		return err
+	}
+	if err := conn.Send("INCR", utils.KeyHookEventsCount(ra.namespace)); err != nil {
+		return err
+	}
+	if _, err := conn.Do("EXEC"); err != nil {
+		return err
+	}
+
+	// Deliver as soon as possible
+	select {
+	case ra.trigger <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Events is implementation of same method in HookAgent interface.
+func (ra *RedisHookAgent) Events(jobID string) (*models.HookEventList, error) {
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	values, err := redis.Values(conn.Do("LRANGE", utils.KeyHookEvents(ra.namespace, jobID), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.HookEventList{
+		JobID:  jobID,
+		Events: make([]*models.HookEvent, 0, len(values)),
+	}
+	for _, v := range values {
+		event := &models.HookEvent{}
+		if err := json.Unmarshal(v.([]byte), event); err != nil {
+			logger.Errorf("Malformed hook event of job %s: %s", jobID, err)
+			continue
+		}
+		list.Events = append(list.Events, event)
+	}
+
+	if len(list.Events) > 0 {
+		next, err := redis.Int64(conn.Do("ZSCORE", utils.KeyHookEventJobs(ra.namespace), jobID))
+		if err != nil && err != redis.ErrNil {
+			return nil, err
+		}
+		list.NextAttemptAt = next
+	}
+
+	return list, nil
+}
+
+// Stats is implementation of same method in HookAgent interface.
+func (ra *RedisHookAgent) Stats() (models.HookDeliveryStats, error) {
+	stats := models.HookDeliveryStats{
+		Delivered: atomic.LoadUint64(&ra.delivered),
+		Failures:  atomic.LoadUint64(&ra.failures),
+		Expired:   atomic.LoadUint64(&ra.expired),
+	}
+
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	pendingEvents, err := redis.Int64(conn.Do("GET", utils.KeyHookEventsCount(ra.namespace)))
+	if err != nil && err != redis.ErrNil {
+		return stats, err
+	}
+	pendingJobs, err := redis.Int64(conn.Do("ZCARD", utils.KeyHookEventJobs(ra.namespace)))
+	if err != nil {
+		return stats, err
+	}
+
+	stats.PendingEvents = pendingEvents
+	stats.PendingJobs = pendingJobs
+
+	return stats, nil
+}
+
+func (ra *RedisHookAgent) loop() {
+	tk := time.NewTicker(hookDeliveryInterval)
+	defer func() {
+		tk.Stop()
+		logger.Info("Redis hook agent is stopped")
+		close(ra.doneChan)
+	}()
+
+	for {
+		select {
+		case <-tk.C:
+			ra.deliverDue()
+		case <-ra.trigger:
+			ra.deliverDue()
+		case <-ra.stopChan:
+			return
+		case <-ra.context.Done():
+			return
+		}
+	}
+}
+
+// deliverDue claims the jobs whose delivery is due and delivers their events.
+func (ra *RedisHookAgent) deliverDue() {
+	jobIDs, err := ra.dueJobs()
+	if err != nil {
+		logger.Errorf("Failed to get the jobs with due hook events: %s", err)
+		return
+	}
+
+	wg := &sync.WaitGroup{}
+	for _, jobID := range jobIDs {
+		claimed, err := ra.claim(jobID)
+		if err != nil {
+			logger.Errorf("Failed to claim the hook events of job %s: %s", jobID, err)
+			continue
+		}
+		if !claimed {
+			// Claimed by other nodes
+			continue
+		}
+
+		wg.Add(1)
+		go func(jobID string) {
+			defer wg.Done()
+			ra.deliverJobEvents(jobID)
+		}(jobID)
+	}
+	wg.Wait()
+}
+
+// deliverJobEvents delivers the events of the claimed job in order until one of them fails.
+func (ra *RedisHookAgent) deliverJobEvents(jobID string) {
+	for i := 0; i < maxEventsPerDelivery; i++ {
+		select {
+		case <-ra.context.Done():
+			return
+		default:
+		}
+
+		event, err := ra.head(jobID)
+		if err != nil {
+			if err == redis.ErrNil {
+				break // no more events
+			}
+			logger.Errorf("Failed to get the hook event of job %s: %s", jobID, err)
+			return // retry after the lease is expired
+		}
+
+		if event == nil {
+			// Malformed event
+			if err := ra.pop(jobID); err != nil {
+				logger.Errorf("Failed to drop malformed hook event of job %s: %s", jobID, err)
+				return
+			}
+			continue
+		}
+
+		if time.Since(time.Unix(event.CreatedAt, 0)) > HookEventMaxAge {
+			logger.Errorf("Hook event %s of job %s is expired after %d attempts, last error: %s", event.ID, jobID, event.Attempts, event.LastError)
+			if err := ra.pop(jobID); err != nil {
+				logger.Errorf("Failed to drop expired hook event of job %s: %s", jobID, err)
+				return
+			}
+			atomic.AddUint64(&ra.expired, 1)
+			continue
+		}
+
+		if err := ra.client.ReportStatus(event.HookURL, *event.Payload); err != nil {
+			atomic.AddUint64(&ra.failures, 1)
+			event.Attempts++
+			event.LastAttemptAt = time.Now().Unix()
+			event.LastError = err.Error()
+			logger.Warningf("Failed to deliver hook event %s of job %s (%d attempts): %s", event.ID, jobID, event.Attempts, err)
+
+			if err := ra.reschedule(event); err != nil {
+				logger.Errorf("Failed to reschedule hook event %s of job %s: %s", event.ID, jobID, err)
+			}
+			return
+		}
+
+		if err := ra.pop(jobID); err != nil {
+			logger.Errorf("Failed to remove delivered hook event %s of job %s: %s", event.ID, jobID, err)
+			return
+		}
+		atomic.AddUint64(&ra.delivered, 1)
+		logger.Debugf("Hook event %s of job %s is delivered", event.ID, jobID)
+	}
+
+	if err := ra.release(jobID); err != nil {
+		logger.Errorf("Failed to release the hook events of job %s: %s", jobID, err)
+	}
+}
+
+func (ra *RedisHookAgent) dueJobs() ([]string, error) {
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	return redis.Strings(conn.Do("ZRANGEBYSCORE", utils.KeyHookEventJobs(ra.namespace), "-inf", time.Now().Unix(), "LIMIT", 0, maxConcurrentDelivery))
+}
+
+func (ra *RedisHookAgent) claim(jobID string) (bool, error) {
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	now := time.Now()
+	return redis.Bool(claimScript.Do(conn, utils.KeyHookEventJobs(ra.namespace), jobID, now.Unix(), now.Add(hookDeliveryLease).Unix()))
+}
+
+func (ra *RedisHookAgent) release(jobID string) error {
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	_, err := releaseScript.Do(conn, utils.KeyHookEvents(ra.namespace, jobID), utils.KeyHookEventJobs(ra.namespace), jobID, time.Now().Unix())
+	return err
+}
+
+// head returns the first event of the job, nil event is returned if it's malformed.
+func (ra *RedisHookAgent) head(jobID string) (*models.HookEvent, error) {
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	rawJSON, err := redis.Bytes(conn.Do("LINDEX", utils.KeyHookEvents(ra.namespace, jobID), 0))
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.HookEvent{}
+	if err := json.Unmarshal(rawJSON, event); err != nil || event.Payload == nil {
+		logger.Errorf("Malformed hook event of job %s: %s", jobID, rawJSON)
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+func (ra *RedisHookAgent) pop(jobID string) error {
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("LPOP", utils.KeyHookEvents(ra.namespace, jobID)); err != nil {
+		return err
+	}
+	if err := conn.Send("DECR", utils.KeyHookEventsCount(ra.namespace)); err != nil {
+		return err
+	}
+	_, err := conn.Do("EXEC")
+
+	return err
+}
+
+// reschedule updates the failed event and delays the delivery of the job events.
+func (ra *RedisHookAgent) reschedule(event *models.HookEvent) error {
+	rawJSON, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	conn := ra.redisPool.Get()
+	defer conn.Close()
+
+	next := time.Now().Add(hookRetryInterval(event.Attempts)).Unix()
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("LSET", utils.KeyHookEvents(ra.namespace, event.JobID), 0, rawJSON); err != nil {
+		return err
+	}
+	if err := conn.Send("ZADD", utils.KeyHookEventJobs(ra.namespace), next, event.JobID); err != nil {
+		return err
+	}
+	_, err = conn.Do("EXEC")
+
+	return err
+}
+
+// hookRetryInterval returns the exponential backoff interval with a little jitter.
+func hookRetryInterval(attempts uint) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	interval := hookRetryMaxInterval
+	if attempts < 8 {
+		if d := hookRetryBaseInterval << (attempts - 1); d < hookRetryMaxInterval {
+			interval = d
+		}
+	}
+
+	return interval + time.Duration(rand.Intn(5))*time.Second
+}
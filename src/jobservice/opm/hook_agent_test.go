@@ -0,0 +1,149 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/utils"
+)
+
+func TestHookRetryInterval(t *testing.T) {
+	if d := hookRetryInterval(1); d < hookRetryBaseInterval || d >= hookRetryBaseInterval+5*time.Second {
+		t.Fatalf("expect interval around %s but got %s", hookRetryBaseInterval, d)
+	}
+	if d := hookRetryInterval(3); d < 4*hookRetryBaseInterval {
+		t.Fatalf("expect interval not less than %s but got %s", 4*hookRetryBaseInterval, d)
+	}
+	if d := hookRetryInterval(100); d < hookRetryMaxInterval || d >= hookRetryMaxInterval+5*time.Second {
+		t.Fatalf("expect interval around %s but got %s", hookRetryMaxInterval, d)
+	}
+}
+
+func TestHookAgentDelivery(t *testing.T) {
+	var received int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+	}))
+	defer ts.Close()
+
+	agent := NewRedisHookAgent(context.Background(), testingNamespace, redisPool)
+	agent.Start()
+	defer agent.Stop()
+
+	jobID := "fake_hook_job_ID"
+	defer clearHookEvents(jobID)
+
+	for _, status := range []string{"running", "success"} {
+		if err := agent.Submit(ts.URL, &models.JobStatusChange{JobID: jobID, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	<-time.After(hookDeliveryInterval + time.Second)
+
+	if n := atomic.LoadInt32(&received); n != 2 {
+		t.Fatalf("expect 2 events delivered but got %d", n)
+	}
+
+	list, err := agent.Events(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Events) != 0 {
+		t.Fatalf("expect no undelivered events but got %d", len(list.Events))
+	}
+}
+
+func TestHookAgentRetry(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	agent := NewRedisHookAgent(context.Background(), testingNamespace, redisPool)
+	agent.Start()
+	defer agent.Stop()
+
+	jobID := "fake_hook_job_ID_failed"
+	defer clearHookEvents(jobID)
+
+	for _, status := range []string{"running", "error"} {
+		if err := agent.Submit(ts.URL, &models.JobStatusChange{JobID: jobID, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	<-time.After(hookDeliveryInterval + time.Second)
+
+	list, err := agent.Events(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Events) != 2 {
+		t.Fatalf("expect 2 undelivered events but got %d", len(list.Events))
+	}
+	// Only the first one is attempted to keep the order
+	if list.Events[0].Attempts != 1 || list.Events[1].Attempts != 0 {
+		t.Fatalf("expect attempts [1 0] but got [%d %d]", list.Events[0].Attempts, list.Events[1].Attempts)
+	}
+	if list.NextAttemptAt <= time.Now().Unix() {
+		t.Fatalf("expect next attempt in future but got %d", list.NextAttemptAt)
+	}
+
+	stats, err := agent.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Failures != 1 {
+		t.Fatalf("expect 1 failure but got %d", stats.Failures)
+	}
+}
+
+func clearHookEvents(jobID string) {
+	conn := redisPool.Get()
+	defer conn.Close()
+
+	conn.Do("DEL", utils.KeyHookEvents(testingNamespace, jobID))
+	conn.Do("ZREM", utils.KeyHookEventJobs(testingNamespace), jobID)
+	conn.Do("DEL", utils.KeyHookEventsCount(testingNamespace))
+}
+
+func TestHookAgentStopAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	agent := NewRedisHookAgent(ctx, testingNamespace, redisPool)
+	agent.Start()
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		agent.Stop()
+		// stopping again doesn't block
+		agent.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the hook agent to be stopped after the context is cancelled")
+	}
+}
@@ -14,7 +14,11 @@
 
 package opm
 
-import "github.com/goharbor/harbor/src/jobservice/models"
+import (
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/models"
+)
 
 // Range for list scope defining
 type Range int
@@ -41,6 +45,36 @@ type JobStatsManager interface {
 	//  error           : error if meet any problems
 	Retrieve(jobID string) (models.JobStats, error)
 
+	// SaveBatch persists the stats of the batch and the jobs in it.
+	// Sync method as the jobs of the batch should not be enqueued before the stats are saved
+	//
+	// batch models.BatchStats     : the stats of the batch
+	// jobs []models.JobStats      : the stats of the jobs in the batch
+	// jobHooks map[string]string : the status hooks of the jobs keyed by the job IDs, optional
+	// hookURL string              : the hook url called when all the jobs are completed, optional
+	//
+	// Returns:
+	//  error if meet any problems
+	SaveBatch(batch models.BatchStats, jobs []models.JobStats, jobHooks map[string]string, hookURL string) error
+
+	// RetrieveBatch gets the aggregated stats of the batch
+	//
+	// batchID string : ID of the batch
+	//
+	// Returns:
+	//  models.BatchStats : batch stats data
+	//  error             : error if meet any problems
+	RetrieveBatch(batchID string) (models.BatchStats, error)
+
+	// DeleteBatch removes the stats of the batch and the jobs in it,
+	// it's used to roll back the batch failed to be enqueued.
+	//
+	// batchID string : ID of the batch
+	//
+	// Returns:
+	//  error if meet any problems
+	DeleteBatch(batchID string) error
+
 	// Update the properties of the job stats
 	//
 	// jobID string                  : ID of the being retried job
@@ -54,6 +88,14 @@ type JobStatsManager interface {
 	// Async method to retry
 	SetJobStatus(jobID string, status string)
 
+	// SetJobFailed marks the status of job to 'Error' with whether it will be retried by the
+	// backend pool, the job launched in batch is only counted as completed if it won't be retried.
+	// Async method to retry
+	//
+	// jobID string  : ID of the failed job
+	// retrying bool : whether the job will be retried
+	SetJobFailed(jobID string, retrying bool)
+
 	// Send command fro the specified job
 	//
 	// jobID string   : ID of the being retried job
@@ -134,4 +176,35 @@ type JobStatsManager interface {
 	//  the ID list of the executions if no error occurred
 	//  or a non-nil error is returned
 	GetExecutions(upstreamJobID string, ranges ...Range) ([]string, error)
+
+	// HookEvents returns the status change events of the job which are not delivered to the hook yet.
+	//
+	// jobID string : ID of job
+	//
+	// Returns:
+	//  the undelivered events in order
+	//  non-nil error if meet any problems
+	HookEvents(jobID string) (*models.HookEventList, error)
+
+	// HookDeliveryStats returns the metrics of the hook events delivery.
+	//
+	// Returns:
+	//  the delivery stats
+	//  non-nil error if meet any problems
+	HookDeliveryStats() (models.HookDeliveryStats, error)
+
+	// Observe the finished run of job for the metrics
+	// Async method to retry
+	//
+	// jobName string          : name of the job
+	// status string           : the final status of the run
+	// duration time.Duration  : how long the job runs
+	Observe(jobName string, status string, duration time.Duration)
+
+	// JobRunMetrics returns the accumulated metrics of the finished job runs.
+	//
+	// Returns:
+	//  the run counts and duration histograms by job name
+	//  non-nil error if meet any problems
+	JobRunMetrics() (*models.JobRunMetrics, error)
 }
@@ -0,0 +1,299 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/utils"
+)
+
+// memHookEvents keeps the undelivered events of one job.
+type memHookEvents struct {
+	events        []*models.HookEvent
+	nextAttemptAt int64
+	// the events are being delivered
+	claimed bool
+}
+
+// MemHookAgent implements HookAgent in memory.
+// The events are lost if the process exits, it's only for the single node deployment
+// without redis like the development environment.
+type MemHookAgent struct {
+	context  context.Context
+	client   *HookClient
+	lock     *sync.Mutex
+	jobs     map[string]*memHookEvents
+	trigger  chan struct{}
+	stopChan chan struct{}
+	stopOnce *sync.Once
+	doneChan chan struct{}
+
+	delivered uint64
+	failures  uint64
+	expired   uint64
+}
+
+// NewMemHookAgent is constructor of MemHookAgent
+func NewMemHookAgent(ctx context.Context) *MemHookAgent {
+	return &MemHookAgent{
+		context:  ctx,
+		client:   DefaultHookClient,
+		lock:     new(sync.Mutex),
+		jobs:     make(map[string]*memHookEvents),
+		trigger:  make(chan struct{}, 1),
+		stopChan: make(chan struct{}),
+		stopOnce: new(sync.Once),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// Start is implementation of same method in HookAgent interface.
+func (ma *MemHookAgent) Start() {
+	go ma.loop()
+	logger.Info("Memory hook agent is started")
+}
+
+// Stop is implementation of same method in HookAgent interface.
+// The loop may have already exited on the cancellation of the context, and Stop may be
+// called more than once, so the stop signal is a channel closed once and the done channel
+// is closed by the loop on exiting.
+func (ma *MemHookAgent) Stop() {
+	ma.stopOnce.Do(func() {
+		close(ma.stopChan)
+	})
+	<-ma.doneChan
+}
+
+// Submit is implementation of same method in HookAgent interface.
+func (ma *MemHookAgent) Submit(hookURL string, change *models.JobStatusChange) error {
+	if change == nil {
+		return errors.New("nil status change event")
+	}
+
+	event := &models.HookEvent{
+		ID:        utils.MakeIdentifier(),
+		JobID:     change.JobID,
+		HookURL:   hookURL,
+		Payload:   change,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	ma.lock.Lock()
+	je, ok := ma.jobs[event.JobID]
+	if !ok {
+		je = &memHookEvents{
+			nextAttemptAt: event.CreatedAt,
+		}
+		ma.jobs[event.JobID] = je
+	}
+	je.events = append(je.events, event)
+	ma.lock.Unlock()
+
+	// Deliver as soon as possible
+	select {
+	case ma.trigger <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Events is implementation of same method in HookAgent interface.
+func (ma *MemHookAgent) Events(jobID string) (*models.HookEventList, error) {
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+
+	list := &models.HookEventList{
+		JobID:  jobID,
+		Events: make([]*models.HookEvent, 0),
+	}
+
+	if je, ok := ma.jobs[jobID]; ok {
+		for _, event := range je.events {
+			copied := *event
+			list.Events = append(list.Events, &copied)
+		}
+		list.NextAttemptAt = je.nextAttemptAt
+	}
+
+	return list, nil
+}
+
+// Stats is implementation of same method in HookAgent interface.
+func (ma *MemHookAgent) Stats() (models.HookDeliveryStats, error) {
+	stats := models.HookDeliveryStats{
+		Delivered: atomic.LoadUint64(&ma.delivered),
+		Failures:  atomic.LoadUint64(&ma.failures),
+		Expired:   atomic.LoadUint64(&ma.expired),
+	}
+
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+
+	for _, je := range ma.jobs {
+		stats.PendingEvents += int64(len(je.events))
+	}
+	stats.PendingJobs = int64(len(ma.jobs))
+
+	return stats, nil
+}
+
+func (ma *MemHookAgent) loop() {
+	tk := time.NewTicker(hookDeliveryInterval)
+	defer func() {
+		tk.Stop()
+		logger.Info("Memory hook agent is stopped")
+		close(ma.doneChan)
+	}()
+
+	for {
+		select {
+		case <-tk.C:
+			ma.deliverDue()
+		case <-ma.trigger:
+			ma.deliverDue()
+		case <-ma.stopChan:
+			return
+		case <-ma.context.Done():
+			return
+		}
+	}
+}
+
+// deliverDue claims the jobs whose delivery is due and delivers their events.
+func (ma *MemHookAgent) deliverDue() {
+	now := time.Now().Unix()
+	jobIDs := make([]string, 0, maxConcurrentDelivery)
+
+	ma.lock.Lock()
+	for jobID, je := range ma.jobs {
+		if len(jobIDs) >= maxConcurrentDelivery {
+			break
+		}
+		if !je.claimed && je.nextAttemptAt <= now {
+			je.claimed = true
+			jobIDs = append(jobIDs, jobID)
+		}
+	}
+	ma.lock.Unlock()
+
+	wg := &sync.WaitGroup{}
+	for _, jobID := range jobIDs {
+		wg.Add(1)
+		go func(jobID string) {
+			defer wg.Done()
+			ma.deliverJobEvents(jobID)
+		}(jobID)
+	}
+	wg.Wait()
+}
+
+// deliverJobEvents delivers the events of the claimed job in order until one of them fails.
+func (ma *MemHookAgent) deliverJobEvents(jobID string) {
+	defer ma.release(jobID)
+
+	for i := 0; i < maxEventsPerDelivery; i++ {
+		select {
+		case <-ma.context.Done():
+			return
+		default:
+		}
+
+		event := ma.head(jobID)
+		if event == nil {
+			return // no more events
+		}
+
+		if time.Since(time.Unix(event.CreatedAt, 0)) > HookEventMaxAge {
+			logger.Errorf("Hook event %s of job %s is expired after %d attempts, last error: %s", event.ID, jobID, event.Attempts, event.LastError)
+			ma.pop(jobID)
+			atomic.AddUint64(&ma.expired, 1)
+			continue
+		}
+
+		if err := ma.client.ReportStatus(event.HookURL, *event.Payload); err != nil {
+			atomic.AddUint64(&ma.failures, 1)
+			logger.Warningf("Failed to deliver hook event %s of job %s (%d attempts): %s", event.ID, jobID, event.Attempts+1, err)
+			ma.reschedule(jobID, err)
+			return
+		}
+
+		ma.pop(jobID)
+		atomic.AddUint64(&ma.delivered, 1)
+		logger.Debugf("Hook event %s of job %s is delivered", event.ID, jobID)
+	}
+}
+
+// head returns the first event of the job, nil is returned if no events left.
+func (ma *MemHookAgent) head(jobID string) *models.HookEvent {
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+
+	je, ok := ma.jobs[jobID]
+	if !ok || len(je.events) == 0 {
+		return nil
+	}
+
+	return je.events[0]
+}
+
+func (ma *MemHookAgent) pop(jobID string) {
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+
+	if je, ok := ma.jobs[jobID]; ok && len(je.events) > 0 {
+		je.events = je.events[1:]
+	}
+}
+
+// reschedule updates the failed event and delays the delivery of the job events.
+func (ma *MemHookAgent) reschedule(jobID string, err error) {
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+
+	je, ok := ma.jobs[jobID]
+	if !ok || len(je.events) == 0 {
+		return
+	}
+
+	event := je.events[0]
+	event.Attempts++
+	event.LastAttemptAt = time.Now().Unix()
+	event.LastError = err.Error()
+	je.nextAttemptAt = time.Now().Add(hookRetryInterval(event.Attempts)).Unix()
+}
+
+// release the claimed job and remove it if no more events left.
+func (ma *MemHookAgent) release(jobID string) {
+	ma.lock.Lock()
+	defer ma.lock.Unlock()
+
+	je, ok := ma.jobs[jobID]
+	if !ok {
+		return
+	}
+
+	je.claimed = false
+	if len(je.events) == 0 {
+		delete(ma.jobs, jobID)
+	}
+}
@@ -0,0 +1,786 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/utils"
+)
+
+const memStatsSweepInterval = 10 * time.Minute
+
+// memJobStats keeps the stats of one job in memory.
+type memJobStats struct {
+	stats   models.JobStatData
+	hookURL string
+	// the job is failed and will be retried
+	retrying bool
+	// 0 means never expired
+	expireAt int64
+}
+
+// memBatch keeps the stats of one batch in memory.
+type memBatch struct {
+	stats   models.BatchStatData
+	hookURL string
+	// the count of the failed jobs which will be retried
+	retrying  int64
+	completed bool
+	expireAt  int64
+}
+
+// memExecution is the execution linked to the upstream job.
+type memExecution struct {
+	jobID string
+	score int64
+}
+
+// memDurations keeps the histogram of the job durations,
+// the bucket counts are not cumulative.
+type memDurations struct {
+	buckets []int64
+	sum     float64
+	count   int64
+}
+
+// MemJobStatsManager implements JobStatsManager in memory.
+// All the data are lost if the process exits, it's only for the single node deployment
+// without redis like the development environment and the integration testing.
+type MemJobStatsManager struct {
+	context    context.Context
+	lock       *sync.RWMutex
+	jobs       map[string]*memJobStats
+	batches    map[string]*memBatch
+	executions map[string][]*memExecution
+	runs       map[string]map[string]int64
+	durations  map[string]*memDurations
+	isRunning  *atomic.Value
+	stopChan   chan struct{}
+	doneChan   chan struct{}
+	opCommands *oPCommands // maintain the OP commands
+	hookAgent  HookAgent   // deliver the status change events to the hooks
+}
+
+// NewMemJobStatsManager is constructor of MemJobStatsManager
+func NewMemJobStatsManager(ctx context.Context) JobStatsManager {
+	isRunning := &atomic.Value{}
+	isRunning.Store(false)
+
+	return &MemJobStatsManager{
+		context:    ctx,
+		lock:       new(sync.RWMutex),
+		jobs:       make(map[string]*memJobStats),
+		batches:    make(map[string]*memBatch),
+		executions: make(map[string][]*memExecution),
+		runs:       make(map[string]map[string]int64),
+		durations:  make(map[string]*memDurations),
+		isRunning:  isRunning,
+		stopChan:   make(chan struct{}, 1),
+		doneChan:   make(chan struct{}, 1),
+		// Commands are never fired to other nodes, no redis pool is required
+		opCommands: newOPCommands(ctx, "", nil),
+		hookAgent:  NewMemHookAgent(ctx),
+	}
+}
+
+// Start is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) Start() {
+	if mjs.isRunning.Load().(bool) {
+		return
+	}
+	go mjs.loop()
+	mjs.opCommands.Start()
+	mjs.hookAgent.Start()
+	mjs.isRunning.Store(true)
+
+	logger.Info("Memory job stats manager is started")
+}
+
+// Shutdown is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) Shutdown() {
+	defer func() {
+		mjs.isRunning.Store(false)
+	}()
+
+	if !(mjs.isRunning.Load().(bool)) {
+		return
+	}
+
+	mjs.opCommands.Stop()
+	mjs.hookAgent.Stop()
+	mjs.stopChan <- struct{}{}
+	<-mjs.doneChan
+}
+
+// Save is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) Save(jobStats models.JobStats) {
+	if jobStats.Stats == nil || utils.IsEmptyStr(jobStats.Stats.JobID) {
+		return
+	}
+
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	js, ok := mjs.jobs[jobStats.Stats.JobID]
+	if !ok {
+		js = &memJobStats{}
+		mjs.jobs[jobStats.Stats.JobID] = js
+	}
+
+	// The executions are kept separately
+	js.stats = *jobStats.Stats
+	js.stats.Executions = nil
+
+	// Same expiring policy with the redis implementation
+	js.expireAt = 0
+	if js.stats.JobKind != job.JobKindPeriodic {
+		expireAt := time.Now().Unix() + jobStatsDataExpireTime
+		if js.stats.JobKind == job.JobKindScheduled && js.stats.RunAt > time.Now().Unix() {
+			expireAt += js.stats.RunAt - time.Now().Unix()
+		}
+		js.expireAt = expireAt
+	}
+}
+
+// SaveBatch is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) SaveBatch(batch models.BatchStats, jobs []models.JobStats, jobHooks map[string]string, hookURL string) error {
+	if batch.Stats == nil || utils.IsEmptyStr(batch.Stats.BatchID) {
+		return errors.New("malformed batch stats object")
+	}
+
+	for _, jobStats := range jobs {
+		if jobStats.Stats == nil || utils.IsEmptyStr(jobStats.Stats.JobID) {
+			return errors.New("malformed job stats object")
+		}
+	}
+
+	for _, jobStats := range jobs {
+		mjs.Save(jobStats)
+	}
+
+	mb := &memBatch{
+		stats:    *batch.Stats,
+		hookURL:  hookURL,
+		expireAt: time.Now().Unix() + jobStatsDataExpireTime,
+	}
+	mb.stats.Counts = make(map[string]int64)
+	for status, count := range batch.Stats.Counts {
+		mb.stats.Counts[status] = count
+	}
+	mb.stats.JobIDs = make([]string, 0, len(jobs))
+	for _, jobStats := range jobs {
+		mb.stats.JobIDs = append(mb.stats.JobIDs, jobStats.Stats.JobID)
+	}
+
+	mjs.lock.Lock()
+	for jobID, jobHook := range jobHooks {
+		if js, ok := mjs.jobs[jobID]; ok {
+			js.hookURL = jobHook
+		}
+	}
+	mjs.batches[batch.Stats.BatchID] = mb
+	mjs.lock.Unlock()
+
+	return nil
+}
+
+// RetrieveBatch is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) RetrieveBatch(batchID string) (models.BatchStats, error) {
+	if utils.IsEmptyStr(batchID) {
+		return models.BatchStats{}, errors.New("empty batch ID")
+	}
+
+	mjs.lock.RLock()
+	defer mjs.lock.RUnlock()
+
+	mb, ok := mjs.batches[batchID]
+	if !ok {
+		return models.BatchStats{}, errs.NoObjectFoundError(fmt.Sprintf("batch '%s'", batchID))
+	}
+
+	return models.BatchStats{Stats: mb.copyStats()}, nil
+}
+
+// DeleteBatch is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) DeleteBatch(batchID string) error {
+	if utils.IsEmptyStr(batchID) {
+		return errors.New("empty batch ID")
+	}
+
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	if mb, ok := mjs.batches[batchID]; ok {
+		for _, jobID := range mb.stats.JobIDs {
+			delete(mjs.jobs, jobID)
+		}
+		delete(mjs.batches, batchID)
+	}
+
+	return nil
+}
+
+// Retrieve is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) Retrieve(jobID string) (models.JobStats, error) {
+	if utils.IsEmptyStr(jobID) {
+		return models.JobStats{}, errors.New("empty job ID")
+	}
+
+	mjs.lock.RLock()
+	js, ok := mjs.jobs[jobID]
+	if !ok {
+		mjs.lock.RUnlock()
+		return models.JobStats{}, errs.NoObjectFoundError(fmt.Sprintf("job '%s'", jobID))
+	}
+	stats := js.stats
+	mjs.lock.RUnlock()
+
+	if stats.IsMultipleExecutions {
+		executions, err := mjs.GetExecutions(jobID)
+		if err != nil {
+			return models.JobStats{}, err
+		}
+
+		stats.Executions = executions
+	}
+
+	return models.JobStats{Stats: &stats}, nil
+}
+
+// Update is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) Update(jobID string, fieldAndValues ...interface{}) error {
+	if len(jobID) == 0 {
+		return errors.New("no updating job")
+	}
+
+	if len(fieldAndValues) == 0 || len(fieldAndValues)%2 != 0 {
+		return errors.New("filed and its value should be pair")
+	}
+
+	mjs.lock.Lock()
+	completedBatch, err := mjs.update(jobID, fieldAndValues...)
+	mjs.lock.Unlock()
+
+	if len(completedBatch) > 0 {
+		// All the jobs of the batch are completed
+		mjs.reportBatchStatus(completedBatch)
+	}
+
+	return err
+}
+
+// update the job stats, the lock should be held by the caller.
+// The ID of the batch is returned if all the jobs of the batch are completed by this updating.
+func (mjs *MemJobStatsManager) update(jobID string, fieldAndValues ...interface{}) (string, error) {
+	js, ok := mjs.jobs[jobID]
+	if !ok {
+		return "", errs.NoObjectFoundError(fmt.Sprintf("job '%s'", jobID))
+	}
+
+	oldStatus := js.stats.Status
+	oldRetrying := js.retrying
+	for i := 0; i < len(fieldAndValues); i += 2 {
+		field, ok := fieldAndValues[i].(string)
+		if !ok {
+			return "", fmt.Errorf("malformed field name: %v", fieldAndValues[i])
+		}
+
+		value := fieldAndValues[i+1]
+		switch field {
+		case "status":
+			js.stats.Status = fmt.Sprintf("%v", value)
+		case "check_in":
+			js.stats.CheckIn = fmt.Sprintf("%v", value)
+		case "upstream_job_id":
+			js.stats.UpstreamJobID = fmt.Sprintf("%v", value)
+		case "retrying":
+			v, ok := value.(bool)
+			if !ok {
+				return "", fmt.Errorf("malformed value of field %s: %v", field, value)
+			}
+			js.retrying = v
+		case "multiple_executions":
+			v, ok := value.(bool)
+			if !ok {
+				return "", fmt.Errorf("malformed value of field %s: %v", field, value)
+			}
+			js.stats.IsMultipleExecutions = v
+		case "check_in_at", "die_at", "run_at":
+			v, ok := toInt64(value)
+			if !ok {
+				return "", fmt.Errorf("malformed value of field %s: %v", field, value)
+			}
+			switch field {
+			case "check_in_at":
+				js.stats.CheckInAt = v
+			case "die_at":
+				js.stats.DieAt = v
+			default:
+				js.stats.RunAt = v
+			}
+		default:
+			// Ignore the unknown fields like the redis implementation does when reading
+		}
+	}
+	js.stats.UpdateTime = time.Now().Unix()
+	// only the failed job may be retried
+	if js.stats.Status != job.JobStatusError {
+		js.retrying = false
+	}
+
+	return mjs.countBatchStatus(js.stats.BatchID, oldStatus, js.stats.Status, oldRetrying, js.retrying), nil
+}
+
+// countBatchStatus moves the job from the old status to the new one in the status counts of the batch,
+// the lock should be held by the caller.
+// The ID of the batch is returned if all the jobs of the batch are completed by this moving.
+func (mjs *MemJobStatsManager) countBatchStatus(batchID string, oldStatus, newStatus string, oldRetrying, retrying bool) string {
+	if utils.IsEmptyStr(batchID) || (oldStatus == newStatus && oldRetrying == retrying) {
+		return ""
+	}
+
+	mb, ok := mjs.batches[batchID]
+	if !ok {
+		return ""
+	}
+
+	if !utils.IsEmptyStr(oldStatus) {
+		mb.stats.Counts[oldStatus]--
+	}
+	mb.stats.Counts[newStatus]++
+	if oldRetrying {
+		mb.retrying--
+	}
+	if retrying {
+		mb.retrying++
+	}
+	mb.stats.UpdateTime = time.Now().Unix()
+
+	if !mb.completed && isBatchCompleted(mb.stats.Counts, mb.retrying, mb.stats.Total) {
+		mb.completed = true
+		return batchID
+	}
+
+	return ""
+}
+
+// SetJobStatus is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) SetJobStatus(jobID string, status string) {
+	if utils.IsEmptyStr(jobID) || utils.IsEmptyStr(status) {
+		return
+	}
+
+	args := []interface{}{"status", status}
+	if status == job.JobStatusSuccess {
+		// make sure the 'die_at' is reset in case it's a retrying job
+		args = append(args, "die_at", 0)
+	}
+
+	if err := mjs.Update(jobID, args...); err != nil {
+		logger.Errorf("Failed to update status of job %s: %s", jobID, err)
+	}
+
+	mjs.reportStatus(jobID, status, "")
+}
+
+// SetJobFailed is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) SetJobFailed(jobID string, retrying bool) {
+	if utils.IsEmptyStr(jobID) {
+		return
+	}
+
+	if err := mjs.Update(jobID, "status", job.JobStatusError, "retrying", retrying); err != nil {
+		logger.Errorf("Failed to update status of job %s: %s", jobID, err)
+	}
+
+	mjs.reportStatus(jobID, job.JobStatusError, "")
+}
+
+// SendCommand is implementation of same method in JobStatsManager interface.
+// There is only one node, the command is directly put into the maintaining list.
+func (mjs *MemJobStatsManager) SendCommand(jobID string, command string, isCached bool) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	if command != CtlCommandStop && command != CtlCommandCancel {
+		return errors.New("unknown command")
+	}
+
+	return mjs.opCommands.Push(jobID, command)
+}
+
+// CtlCommand is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) CtlCommand(jobID string) (string, error) {
+	if utils.IsEmptyStr(jobID) {
+		return "", errors.New("empty job ID")
+	}
+
+	c, ok := mjs.opCommands.Pop(jobID)
+	if !ok {
+		return "", fmt.Errorf("no OP command fired to job %s", jobID)
+	}
+
+	return c, nil
+}
+
+// CheckIn is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) CheckIn(jobID string, message string) {
+	if utils.IsEmptyStr(jobID) || utils.IsEmptyStr(message) {
+		return
+	}
+
+	if err := mjs.Update(jobID, "check_in", message, "check_in_at", time.Now().Unix()); err != nil {
+		logger.Errorf("Failed to check in message for job %s: %s", jobID, err)
+	}
+
+	mjs.reportStatus(jobID, job.JobStatusRunning, message)
+}
+
+// DieAt is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) DieAt(jobID string, dieAt int64) {
+	if utils.IsEmptyStr(jobID) || dieAt == 0 {
+		return
+	}
+
+	if err := mjs.Update(jobID, "die_at", dieAt); err != nil {
+		logger.Errorf("Failed to mark die time of job %s: %s", jobID, err)
+	}
+}
+
+// RegisterHook is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) RegisterHook(jobID string, hookURL string, isCached bool) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	if !utils.IsValidURL(hookURL) {
+		return errors.New("invalid hook url")
+	}
+
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	js, ok := mjs.jobs[jobID]
+	if !ok {
+		return errs.NoObjectFoundError(fmt.Sprintf("job '%s'", jobID))
+	}
+	js.hookURL = hookURL
+
+	return nil
+}
+
+// GetHook is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) GetHook(jobID string) (string, error) {
+	if utils.IsEmptyStr(jobID) {
+		return "", errors.New("empty job ID")
+	}
+
+	mjs.lock.RLock()
+	defer mjs.lock.RUnlock()
+
+	js, ok := mjs.jobs[jobID]
+	if !ok || utils.IsEmptyStr(js.hookURL) {
+		return "", fmt.Errorf("no registered web hook found for job '%s'", jobID)
+	}
+
+	return js.hookURL, nil
+}
+
+// ExpirePeriodicJobStats is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) ExpirePeriodicJobStats(jobID string) error {
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	if js, ok := mjs.jobs[jobID]; ok {
+		js.expireAt = time.Now().Unix() + jobStatsDataExpireTime
+	}
+
+	return nil
+}
+
+// AttachExecution is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) AttachExecution(upstreamJobID string, executions ...string) error {
+	if len(upstreamJobID) == 0 {
+		return errors.New("empty upstream job ID is not allowed")
+	}
+
+	if len(executions) == 0 {
+		return errors.New("no executions existing to persist")
+	}
+
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	baseScore := time.Now().Unix()
+	for index, execution := range executions {
+		mjs.executions[upstreamJobID] = append(mjs.executions[upstreamJobID], &memExecution{
+			jobID: execution,
+			score: baseScore + int64(index),
+		})
+	}
+
+	return nil
+}
+
+// GetExecutions is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) GetExecutions(upstreamJobID string, ranges ...Range) ([]string, error) {
+	if len(upstreamJobID) == 0 {
+		return nil, errors.New("no upstream ID specified")
+	}
+
+	mjs.lock.RLock()
+	defer mjs.lock.RUnlock()
+
+	executions := make([]*memExecution, len(mjs.executions[upstreamJobID]))
+	copy(executions, mjs.executions[upstreamJobID])
+	sort.SliceStable(executions, func(i, j int) bool {
+		return executions[i].score < executions[j].score
+	})
+
+	ids := make([]string, 0, len(executions))
+	for _, e := range executions {
+		if len(ranges) >= 1 && e.score < int64(ranges[0]) {
+			continue
+		}
+		if len(ranges) > 1 && e.score > int64(ranges[1]) {
+			continue
+		}
+		ids = append(ids, e.jobID)
+	}
+
+	return ids, nil
+}
+
+// HookEvents is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) HookEvents(jobID string) (*models.HookEventList, error) {
+	if utils.IsEmptyStr(jobID) {
+		return nil, errors.New("empty job ID")
+	}
+
+	return mjs.hookAgent.Events(jobID)
+}
+
+// HookDeliveryStats is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) HookDeliveryStats() (models.HookDeliveryStats, error) {
+	return mjs.hookAgent.Stats()
+}
+
+// Observe is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) Observe(jobName string, status string, duration time.Duration) {
+	if utils.IsEmptyStr(jobName) || utils.IsEmptyStr(status) {
+		return
+	}
+
+	seconds := duration.Seconds()
+
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	counts, ok := mjs.runs[jobName]
+	if !ok {
+		counts = make(map[string]int64)
+		mjs.runs[jobName] = counts
+	}
+	counts[status]++
+
+	d, ok := mjs.durations[jobName]
+	if !ok {
+		d = &memDurations{
+			buckets: make([]int64, len(DurationBuckets)),
+		}
+		mjs.durations[jobName] = d
+	}
+	for i, bucket := range DurationBuckets {
+		if seconds <= bucket {
+			d.buckets[i]++
+			break
+		}
+	}
+	d.sum += seconds
+	d.count++
+}
+
+// JobRunMetrics is implementation of same method in JobStatsManager interface.
+func (mjs *MemJobStatsManager) JobRunMetrics() (*models.JobRunMetrics, error) {
+	mjs.lock.RLock()
+	defer mjs.lock.RUnlock()
+
+	metrics := &models.JobRunMetrics{
+		Counts:    make(map[string]map[string]int64),
+		Durations: make(map[string]*models.DurationHistogram),
+	}
+
+	for jobName, counts := range mjs.runs {
+		metrics.Counts[jobName] = make(map[string]int64)
+		for status, count := range counts {
+			metrics.Counts[jobName][status] = count
+		}
+	}
+
+	for jobName, d := range mjs.durations {
+		histogram := &models.DurationHistogram{
+			Buckets: DurationBuckets,
+			Counts:  make([]int64, len(DurationBuckets)),
+			Sum:     d.sum,
+			Count:   d.count,
+		}
+		// Accumulate the counts of buckets
+		var cumulative int64
+		for i := range DurationBuckets {
+			cumulative += d.buckets[i]
+			histogram.Counts[i] = cumulative
+		}
+
+		metrics.Durations[jobName] = histogram
+	}
+
+	return metrics, nil
+}
+
+// reportBatchStatus submits the completion event of the batch to the hook agent if the hook is registered.
+func (mjs *MemJobStatsManager) reportBatchStatus(batchID string) {
+	mjs.lock.RLock()
+	mb, ok := mjs.batches[batchID]
+	if !ok || utils.IsEmptyStr(mb.hookURL) {
+		mjs.lock.RUnlock()
+		logger.Warningf("no status hook found for batch %s\n, abandon status reporting", batchID)
+		return
+	}
+	hookURL := mb.hookURL
+	stats := mb.copyStats()
+	mjs.lock.RUnlock()
+
+	// The events of the batch are kept with the batch ID
+	reportingStatus := &models.JobStatusChange{
+		JobID:  batchID,
+		Status: stats.Status,
+		Batch:  stats,
+	}
+	if err := mjs.hookAgent.Submit(hookURL, reportingStatus); err != nil {
+		logger.Errorf("Failed to submit status change event of batch %s: %s", batchID, err)
+	}
+}
+
+// reportStatus submits the status change event to the hook agent if the hook is registered.
+func (mjs *MemJobStatsManager) reportStatus(jobID string, status, checkIn string) {
+	mjs.lock.RLock()
+	js, ok := mjs.jobs[jobID]
+	if !ok || utils.IsEmptyStr(js.hookURL) {
+		mjs.lock.RUnlock()
+		logger.Warningf("no status hook found for job %s\n, abandon status reporting", jobID)
+		return
+	}
+	hookURL := js.hookURL
+	stats := js.stats
+	mjs.lock.RUnlock()
+
+	stats.CheckIn = checkIn
+	stats.Status = status
+
+	reportingStatus := &models.JobStatusChange{
+		JobID:    jobID,
+		Status:   status,
+		CheckIn:  checkIn,
+		Metadata: &stats,
+	}
+	if err := mjs.hookAgent.Submit(hookURL, reportingStatus); err != nil {
+		logger.Errorf("Failed to submit status change event of job %s: %s", jobID, err)
+	}
+}
+
+func (mjs *MemJobStatsManager) loop() {
+	tk := time.NewTicker(memStatsSweepInterval)
+	defer func() {
+		tk.Stop()
+		mjs.isRunning.Store(false)
+		logger.Info("Memory job stats manager is stopped")
+		mjs.doneChan <- struct{}{}
+	}()
+
+	for {
+		select {
+		case <-tk.C:
+			mjs.sweep()
+		case <-mjs.stopChan:
+			return
+		case <-mjs.context.Done():
+			return
+		}
+	}
+}
+
+// sweep clears the expired job stats to release the memory.
+func (mjs *MemJobStatsManager) sweep() {
+	now := time.Now().Unix()
+
+	mjs.lock.Lock()
+	defer mjs.lock.Unlock()
+
+	for jobID, js := range mjs.jobs {
+		if js.expireAt > 0 && js.expireAt <= now {
+			delete(mjs.jobs, jobID)
+			delete(mjs.executions, jobID)
+		}
+	}
+
+	for batchID, mb := range mjs.batches {
+		if mb.expireAt <= now {
+			delete(mjs.batches, batchID)
+		}
+	}
+}
+
+// copyStats returns the copy of the batch stats with the aggregated status.
+func (mb *memBatch) copyStats() *models.BatchStatData {
+	stats := mb.stats
+	stats.Counts = make(map[string]int64)
+	for _, status := range batchCountedStatuses {
+		stats.Counts[status] = mb.stats.Counts[status]
+	}
+	stats.JobIDs = append([]string{}, mb.stats.JobIDs...)
+	stats.Status = batchStatus(stats.Counts, mb.retrying, stats.Total)
+	stats.RefLink = fmt.Sprintf("/api/v1/jobs/batch/%s", stats.BatchID)
+
+	return &stats
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), true
+	case uint64:
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package opm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/models"
+)
+
+func TestMemJobStats(t *testing.T) {
+	mgr := NewMemJobStatsManager(context.Background())
+	mgr.Start()
+	defer mgr.Shutdown()
+
+	if _, err := mgr.Retrieve("fake_job_ID"); !errs.IsObjectNotFoundError(err) {
+		t.Fatalf("expect object not found error but got %v", err)
+	}
+
+	mgr.Save(createFakeStats())
+	mgr.SetJobStatus("fake_job_ID", job.JobStatusRunning)
+	mgr.CheckIn("fake_job_ID", "checkin")
+	mgr.DieAt("fake_job_ID", 1000)
+	if err := mgr.Update("fake_job_ID", "multiple_executions", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := mgr.AttachExecution("fake_job_ID", "fake_sub_job_ID_1", "fake_sub_job_ID_2"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := mgr.Retrieve("fake_job_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stats.Status != job.JobStatusRunning {
+		t.Errorf("expect job status %s but got %s", job.JobStatusRunning, stats.Stats.Status)
+	}
+	if stats.Stats.CheckIn != "checkin" || stats.Stats.CheckInAt == 0 {
+		t.Errorf("expect check in message 'checkin' but got '%s'", stats.Stats.CheckIn)
+	}
+	if stats.Stats.DieAt != 1000 {
+		t.Errorf("expect die at 1000 but got %d", stats.Stats.DieAt)
+	}
+	if len(stats.Stats.Executions) != 2 || stats.Stats.Executions[0] != "fake_sub_job_ID_1" {
+		t.Errorf("expect executions [fake_sub_job_ID_1 fake_sub_job_ID_2] but got %v", stats.Stats.Executions)
+	}
+
+	// The die time is reset when the job succeeds
+	mgr.SetJobStatus("fake_job_ID", job.JobStatusSuccess)
+	stats, err = mgr.Retrieve("fake_job_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stats.DieAt != 0 {
+		t.Errorf("expect die at reset but got %d", stats.Stats.DieAt)
+	}
+
+	if err := mgr.SendCommand("fake_job_ID", CtlCommandStop, false); err != nil {
+		t.Fatal(err)
+	}
+	if cmd, err := mgr.CtlCommand("fake_job_ID"); err != nil || cmd != CtlCommandStop {
+		t.Errorf("expect command %s but got '%s' with error %v", CtlCommandStop, cmd, err)
+	}
+	if _, err := mgr.CtlCommand("fake_job_ID"); err == nil {
+		t.Errorf("expect non nil error as the command is popped but got nil")
+	}
+}
+
+func TestMemJobStatsHookReporting(t *testing.T) {
+	lock := new(sync.Mutex)
+	received := make([]string, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		change := &models.JobStatusChange{}
+		if err := json.NewDecoder(r.Body).Decode(change); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		lock.Lock()
+		received = append(received, change.Status)
+		lock.Unlock()
+	}))
+	defer ts.Close()
+
+	mgr := NewMemJobStatsManager(context.Background())
+	mgr.Start()
+	defer mgr.Shutdown()
+
+	jobStats := createFakeStats()
+	jobStats.Stats.JobID = "fake_hook_job_ID"
+	mgr.Save(jobStats)
+	if err := mgr.RegisterHook("fake_hook_job_ID", ts.URL, false); err != nil {
+		t.Fatal(err)
+	}
+	if hookURL, err := mgr.GetHook("fake_hook_job_ID"); err != nil || hookURL != ts.URL {
+		t.Fatalf("expect hook %s but got '%s' with error %v", ts.URL, hookURL, err)
+	}
+
+	mgr.SetJobStatus("fake_hook_job_ID", job.JobStatusRunning)
+	mgr.SetJobStatus("fake_hook_job_ID", job.JobStatusSuccess)
+
+	deadline := time.Now().Add(hookDeliveryInterval + 3*time.Second)
+	for {
+		lock.Lock()
+		n := len(received)
+		lock.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expect 2 events delivered but got %d", n)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if received[0] != job.JobStatusRunning || received[1] != job.JobStatusSuccess {
+		t.Errorf("expect events delivered in order but got %v", received)
+	}
+
+	stats, err := mgr.HookDeliveryStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Delivered != 2 || stats.PendingEvents != 0 {
+		t.Errorf("expect 2 delivered and no pending events but got %+v", stats)
+	}
+}
+
+func TestMemJobStatsBatch(t *testing.T) {
+	lock := new(sync.Mutex)
+	received := make([]*models.JobStatusChange, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		change := &models.JobStatusChange{}
+		if err := json.NewDecoder(r.Body).Decode(change); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		lock.Lock()
+		received = append(received, change)
+		lock.Unlock()
+	}))
+	defer ts.Close()
+
+	mgr := NewMemJobStatsManager(context.Background())
+	mgr.Start()
+	defer mgr.Shutdown()
+
+	batch := models.BatchStats{
+		Stats: &models.BatchStatData{
+			BatchID: "fake_batch_ID",
+			Total:   2,
+			Counts:  map[string]int64{job.JobStatusPending: 2},
+		},
+	}
+	jobs := []models.JobStats{createFakeStats(), createFakeStats()}
+	jobs[0].Stats.JobID = "fake_batch_job_ID_1"
+	jobs[1].Stats.JobID = "fake_batch_job_ID_2"
+	for _, j := range jobs {
+		j.Stats.BatchID = "fake_batch_ID"
+	}
+	jobHooks := map[string]string{"fake_batch_job_ID_2": "http://localhost:9090"}
+	if err := mgr.SaveBatch(batch, jobs, jobHooks, ts.URL); err != nil {
+		t.Fatal(err)
+	}
+	if hookURL, err := mgr.GetHook("fake_batch_job_ID_2"); err != nil || hookURL != "http://localhost:9090" {
+		t.Fatalf("expect hook of job fake_batch_job_ID_2 registered with the batch but got %s: %v", hookURL, err)
+	}
+
+	mgr.SetJobStatus("fake_batch_job_ID_1", job.JobStatusRunning)
+	res, err := mgr.RetrieveBatch("fake_batch_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusRunning || res.Stats.Counts[job.JobStatusPending] != 1 {
+		t.Fatalf("expect running batch with 1 pending job but got %+v", res.Stats)
+	}
+
+	mgr.SetJobStatus("fake_batch_job_ID_1", job.JobStatusSuccess)
+	mgr.SetJobStatus("fake_batch_job_ID_2", job.JobStatusRunning)
+	mgr.SetJobFailed("fake_batch_job_ID_2", true)
+	res, err = mgr.RetrieveBatch("fake_batch_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusRunning {
+		t.Fatalf("expect running batch with 1 job to be retried but got %+v", res.Stats)
+	}
+
+	mgr.SetJobStatus("fake_batch_job_ID_2", job.JobStatusRunning)
+	mgr.SetJobFailed("fake_batch_job_ID_2", false)
+	res, err = mgr.RetrieveBatch("fake_batch_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusError || res.Stats.Counts[job.JobStatusSuccess] != 1 || res.Stats.Counts[job.JobStatusError] != 1 {
+		t.Fatalf("expect failed batch with 1 successful and 1 failed job but got %+v", res.Stats)
+	}
+
+	// The batch hook is called only once
+	deadline := time.Now().Add(hookDeliveryInterval + 3*time.Second)
+	for {
+		lock.Lock()
+		n := len(received)
+		lock.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expect 1 batch event delivered but got %d", n)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	lock.Lock()
+	if received[0].JobID != "fake_batch_ID" || received[0].Batch == nil || received[0].Batch.Total != 2 {
+		t.Errorf("expect completion event of batch fake_batch_ID but got %+v", received[0])
+	}
+	lock.Unlock()
+
+	if err := mgr.DeleteBatch("fake_batch_ID"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.RetrieveBatch("fake_batch_ID"); !errs.IsObjectNotFoundError(err) {
+		t.Errorf("expect not found error for deleted batch but got %v", err)
+	}
+	if _, err := mgr.Retrieve("fake_batch_job_ID_1"); !errs.IsObjectNotFoundError(err) {
+		t.Errorf("expect not found error for job of deleted batch but got %v", err)
+	}
+}
+
+func TestMemHookAgentRetry(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	agent := NewMemHookAgent(context.Background())
+	agent.Start()
+	defer agent.Stop()
+
+	jobID := "fake_hook_job_ID_failed"
+	for _, status := range []string{"running", "error"} {
+		if err := agent.Submit(ts.URL, &models.JobStatusChange{JobID: jobID, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	<-time.After(hookDeliveryInterval + time.Second)
+
+	list, err := agent.Events(jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Events) != 2 {
+		t.Fatalf("expect 2 undelivered events but got %d", len(list.Events))
+	}
+	// Only the first one is attempted to keep the order
+	if list.Events[0].Attempts != 1 || list.Events[1].Attempts != 0 {
+		t.Fatalf("expect attempts [1 0] but got [%d %d]", list.Events[0].Attempts, list.Events[1].Attempts)
+	}
+	if list.NextAttemptAt <= time.Now().Unix() {
+		t.Fatalf("expect next attempt in future but got %d", list.NextAttemptAt)
+	}
+
+	stats, err := agent.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Failures != 1 || stats.PendingEvents != 2 || stats.PendingJobs != 1 {
+		t.Fatalf("expect 1 failure and 2 pending events of 1 job but got %+v", stats)
+	}
+}
+
+func TestMemJobRunMetrics(t *testing.T) {
+	mgr := NewMemJobStatsManager(context.Background())
+
+	mgr.Observe("fake_job", job.JobStatusSuccess, 3*time.Second)
+	mgr.Observe("fake_job", job.JobStatusSuccess, 20*time.Second)
+	mgr.Observe("fake_job", job.JobStatusError, 2*time.Hour)
+
+	metrics, err := mgr.JobRunMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metrics.Counts["fake_job"][job.JobStatusSuccess] != 2 || metrics.Counts["fake_job"][job.JobStatusError] != 1 {
+		t.Errorf("expect 2 success and 1 error runs but got %v", metrics.Counts["fake_job"])
+	}
+
+	h := metrics.Durations["fake_job"]
+	if h == nil {
+		t.Fatal("expect duration histogram of fake_job but got nil")
+	}
+	// Buckets: 1, 5, 15, 30, ...
+	if h.Counts[0] != 0 || h.Counts[1] != 1 || h.Counts[3] != 2 || h.Counts[len(h.Counts)-1] != 3 {
+		t.Errorf("expect cumulative bucket counts but got %v", h.Counts)
+	}
+	if h.Count != 3 || h.Sum != 7223 {
+		t.Errorf("expect count 3 and sum 7223 but got %d and %f", h.Count, h.Sum)
+	}
+}
+
+func TestMemJobStatsShutdownAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mgr := NewMemJobStatsManager(ctx)
+	mgr.Start()
+	cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		mgr.Shutdown()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the job stats manager to be shut down after the context is cancelled")
+	}
+}
+
+func TestMemHookAgentStopAfterCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	agent := NewMemHookAgent(ctx)
+	agent.Start()
+	cancel()
+	<-time.After(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		agent.Stop()
+		// stopping again doesn't block
+		agent.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expect the hook agent to be stopped after the context is cancelled")
+	}
+}
@@ -43,6 +43,8 @@ const (
 	opReportStatus         = "report_status"
 	opPersistExecutions    = "persist_executions"
 	opUpdateStats          = "update_job_stats"
+	opObserveRun           = "observe_job_run"
+	opReportBatchStatus    = "report_batch_status"
 	maxFails               = 3
 	jobStatsDataExpireTime = 60 * 60 * 24 * 5 // 5 days
 
@@ -52,11 +54,66 @@ const (
 	CtlCommandCancel = "cancel"
 	// CtlCommandRetry : command retry
 	CtlCommandRetry = "retry"
+	// CtlCommandPurge : command purge, only for dead jobs
+	CtlCommandPurge = "purge"
 
 	// EventRegisterStatusHook is event name of registering hook
 	EventRegisterStatusHook = "register_hook"
 )
 
+// Update the status with the other properties of the job, the status counts of the batch
+// are updated at the same time if the job is launched in batch.
+// The failed job which will be retried has the property 'retrying' set to 1, such jobs are
+// counted by the 'retrying' field of the batch and they're not completed.
+// KEYS[1]: key of the job stats
+// ARGV[1]: key prefix of the batch stats, ARGV[2]: status, ARGV[3]: update time
+// ARGV[4:]: the other properties and values
+// Returns the batch ID if all the jobs of the batch are completed by this updating
+var updateJobStatusScript = redis.NewScript(1, `
+local old = redis.call('hget', KEYS[1], 'status')
+local oldRetrying = old == 'Error' and redis.call('hget', KEYS[1], 'retrying') == '1'
+local batch = redis.call('hget', KEYS[1], 'batch_id')
+redis.call('hmset', KEYS[1], 'status', ARGV[2], 'update_time', ARGV[3], unpack(ARGV, 4))
+local retrying = ARGV[2] == 'Error' and redis.call('hget', KEYS[1], 'retrying') == '1'
+if not batch or batch == '' or (old == ARGV[2] and oldRetrying == retrying) then
+	return ''
+end
+local key = ARGV[1] .. batch
+if redis.call('exists', key) == 0 then
+	return ''
+end
+if old then
+	redis.call('hincrby', key, old, -1)
+end
+redis.call('hincrby', key, ARGV[2], 1)
+if oldRetrying then
+	redis.call('hincrby', key, 'retrying', -1)
+end
+if retrying then
+	redis.call('hincrby', key, 'retrying', 1)
+end
+redis.call('hset', key, 'update_time', ARGV[3])
+local completed = -tonumber(redis.call('hget', key, 'retrying') or '0')
+for _, status in ipairs({'Success', 'Error', 'Stopped', 'Cancelled'}) do
+	completed = completed + tonumber(redis.call('hget', key, status) or '0')
+end
+if completed >= tonumber(redis.call('hget', key, 'total')) and redis.call('hsetnx', key, 'completed', 1) == 1 then
+	return batch
+end
+return ''
+`)
+
+// DurationBuckets are the upper bounds (in seconds) of the job duration histogram buckets.
+var DurationBuckets = []float64{1, 5, 15, 30, 60, 300, 900, 1800, 3600, 7200}
+
+// The final status of the job runs to be counted
+var observedStatuses = []string{
+	job.JobStatusSuccess,
+	job.JobStatusError,
+	job.JobStatusStopped,
+	job.JobStatusCancelled,
+}
+
 type queueItem struct {
 	Op    string
 	Fails uint
@@ -83,6 +140,7 @@ type RedisJobStatsManager struct {
 	isRunning   *atomic.Value
 	hookStore   *HookStore  // cache the hook here to avoid requesting backend
 	opCommands  *oPCommands // maintain the OP commands
+	hookAgent   HookAgent   // deliver the status change events to the hooks
 }
 
 // NewRedisJobStatsManager is constructor of RedisJobStatsManager
@@ -100,6 +158,7 @@ func NewRedisJobStatsManager(ctx context.Context, namespace string, redisPool *r
 		hookStore:   NewHookStore(),
 		isRunning:   isRunning,
 		opCommands:  newOPCommands(ctx, namespace, redisPool),
+		hookAgent:   NewRedisHookAgent(ctx, namespace, redisPool),
 	}
 }
 
@@ -110,6 +169,7 @@ func (rjs *RedisJobStatsManager) Start() {
 	}
 	go rjs.loop()
 	rjs.opCommands.Start()
+	rjs.hookAgent.Start()
 	rjs.isRunning.Store(true)
 
 	logger.Info("Redis job stats manager is started")
@@ -126,6 +186,7 @@ func (rjs *RedisJobStatsManager) Shutdown() {
 	}
 
 	rjs.opCommands.Stop()
+	rjs.hookAgent.Stop()
 	rjs.stopChan <- struct{}{}
 	<-rjs.doneChan
 }
@@ -141,6 +202,110 @@ func (rjs *RedisJobStatsManager) Save(jobStats models.JobStats) {
 	rjs.processChan <- item
 }
 
+// SaveBatch is implementation of same method in JobStatsManager interface.
+// Sync method
+func (rjs *RedisJobStatsManager) SaveBatch(batch models.BatchStats, jobs []models.JobStats, jobHooks map[string]string, hookURL string) error {
+	if batch.Stats == nil || utils.IsEmptyStr(batch.Stats.BatchID) {
+		return errors.New("malformed batch stats object")
+	}
+
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	// All or nothing
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+
+	jobIDs := []interface{}{utils.KeyBatchJobs(rjs.namespace, batch.Stats.BatchID)}
+	for _, jobStats := range jobs {
+		if err := rjs.sendJobStats(conn, jobStats); err != nil {
+			return err
+		}
+		// The hooks are saved with the stats to be ready before the jobs are running,
+		// the other nodes will load them from the backend as they're not cached there.
+		if jobHook, ok := jobHooks[jobStats.Stats.JobID]; ok {
+			key := utils.KeyJobStats(rjs.namespace, jobStats.Stats.JobID)
+			if err := conn.Send("HSET", key, "status_hook", jobHook); err != nil {
+				return err
+			}
+		}
+		jobIDs = append(jobIDs, jobStats.Stats.JobID)
+	}
+
+	key := utils.KeyBatchStats(rjs.namespace, batch.Stats.BatchID)
+	args := []interface{}{
+		key,
+		"id", batch.Stats.BatchID,
+		"total", batch.Stats.Total,
+		"enqueue_time", batch.Stats.EnqueueTime,
+		"update_time", batch.Stats.UpdateTime,
+		"status_hook", hookURL,
+	}
+	for status, count := range batch.Stats.Counts {
+		args = append(args, status, count)
+	}
+	if err := conn.Send("HMSET", args...); err != nil {
+		return err
+	}
+	if err := conn.Send("EXPIRE", key, jobStatsDataExpireTime); err != nil {
+		return err
+	}
+
+	if len(jobIDs) > 1 {
+		if err := conn.Send("RPUSH", jobIDs...); err != nil {
+			return err
+		}
+		if err := conn.Send("EXPIRE", jobIDs[0], jobStatsDataExpireTime); err != nil {
+			return err
+		}
+	}
+
+	_, err := conn.Do("EXEC")
+
+	return err
+}
+
+// RetrieveBatch is implementation of same method in JobStatsManager interface.
+// Sync method
+func (rjs *RedisJobStatsManager) RetrieveBatch(batchID string) (models.BatchStats, error) {
+	if utils.IsEmptyStr(batchID) {
+		return models.BatchStats{}, errors.New("empty batch ID")
+	}
+
+	res, _, err := rjs.getBatchStats(batchID)
+	if err != nil {
+		return models.BatchStats{}, err
+	}
+
+	return res, nil
+}
+
+// DeleteBatch is implementation of same method in JobStatsManager interface.
+func (rjs *RedisJobStatsManager) DeleteBatch(batchID string) error {
+	if utils.IsEmptyStr(batchID) {
+		return errors.New("empty batch ID")
+	}
+
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	jobsKey := utils.KeyBatchJobs(rjs.namespace, batchID)
+	jobIDs, err := redis.Strings(conn.Do("LRANGE", jobsKey, 0, -1))
+	if err != nil {
+		return err
+	}
+
+	keys := []interface{}{utils.KeyBatchStats(rjs.namespace, batchID), jobsKey}
+	for _, jobID := range jobIDs {
+		keys = append(keys, utils.KeyJobStats(rjs.namespace, jobID))
+	}
+
+	_, err = conn.Do("DEL", keys...)
+
+	return err
+}
+
 // Retrieve is implementation of same method in JobStatsManager interface.
 // Sync method
 func (rjs *RedisJobStatsManager) Retrieve(jobID string) (models.JobStats, error) {
@@ -183,6 +348,23 @@ func (rjs *RedisJobStatsManager) SetJobStatus(jobID string, status string) {
 	rjs.submitStatusReportingItem(jobID, status, "")
 }
 
+// SetJobFailed is implementation of same method in JobStatsManager interface.
+func (rjs *RedisJobStatsManager) SetJobFailed(jobID string, retrying bool) {
+	if utils.IsEmptyStr(jobID) {
+		return
+	}
+
+	item := &queueItem{
+		Op:   opUpdateStatus,
+		Data: []string{jobID, job.JobStatusError, strconv.FormatBool(retrying)},
+	}
+
+	rjs.processChan <- item
+
+	// Report status at the same time
+	rjs.submitStatusReportingItem(jobID, job.JobStatusError, "")
+}
+
 func (rjs *RedisJobStatsManager) loop() {
 	controlChan := make(chan struct{})
 
@@ -473,6 +655,25 @@ func (rjs *RedisJobStatsManager) submitStatusReportingItem(jobID string, status,
 	}()
 }
 
+func (rjs *RedisJobStatsManager) reportBatchStatus(batchID string) error {
+	batchStats, hookURL, err := rjs.getBatchStats(batchID)
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsValidURL(hookURL) {
+		logger.Warningf("no status hook found for batch %s\n, abandon status reporting", batchID)
+		return nil
+	}
+
+	// The events of the batch are kept with the batch ID
+	return rjs.hookAgent.Submit(hookURL, &models.JobStatusChange{
+		JobID:  batchID,
+		Status: batchStats.Stats.Status,
+		Batch:  batchStats.Stats,
+	})
+}
+
 func (rjs *RedisJobStatsManager) reportStatus(jobID string, hookURL, status, checkIn string) error {
 	reportingStatus := models.JobStatusChange{
 		JobID:   jobID,
@@ -494,10 +695,21 @@ func (rjs *RedisJobStatsManager) reportStatus(jobID string, hookURL, status, che
 		reportingStatus.Metadata = jobStats.Stats
 	}
 
-	return DefaultHookClient.ReportStatus(hookURL, reportingStatus)
+	// Persist the event, the delivery is retried by the hook agent until it's expired
+	return rjs.hookAgent.Submit(hookURL, &reportingStatus)
 }
 
 func (rjs *RedisJobStatsManager) updateJobStats(jobID string, fieldAndValues ...interface{}) error {
+	// The status changing should be counted into the batch if the job is launched in batch
+	for i := 0; i+1 < len(fieldAndValues); i += 2 {
+		if field, ok := fieldAndValues[i].(string); ok && field == "status" {
+			others := make([]interface{}, 0, len(fieldAndValues)-2)
+			others = append(others, fieldAndValues[:i]...)
+			others = append(others, fieldAndValues[i+2:]...)
+			return rjs.setJobStatus(jobID, fmt.Sprintf("%v", fieldAndValues[i+1]), others...)
+		}
+	}
+
 	conn := rjs.redisPool.Get()
 	defer conn.Close()
 
@@ -513,13 +725,49 @@ func (rjs *RedisJobStatsManager) updateJobStats(jobID string, fieldAndValues ...
 	return err
 }
 
-func (rjs *RedisJobStatsManager) updateJobStatus(jobID string, status string) error {
-	args := make([]interface{}, 0, 4)
+func (rjs *RedisJobStatsManager) setJobStatus(jobID string, status string, fieldAndValues ...interface{}) error {
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	args := make([]interface{}, 0, len(fieldAndValues)+4)
+	args = append(args,
+		utils.KeyJobStats(rjs.namespace, jobID),
+		utils.KeyBatchStats(rjs.namespace, ""),
+		status,
+		time.Now().Unix(),
+	)
+	args = append(args, fieldAndValues...)
+
+	batchID, err := redis.String(updateJobStatusScript.Do(conn, args...))
+	if err != nil {
+		return err
+	}
+
+	if !utils.IsEmptyStr(batchID) {
+		// All the jobs of the batch are completed
+		rjs.processChan <- &queueItem{
+			Op:   opReportBatchStatus,
+			Data: batchID,
+		}
+	}
+
+	return nil
+}
+
+func (rjs *RedisJobStatsManager) updateJobStatus(jobID string, status string, retrying bool) error {
+	args := make([]interface{}, 0, 6)
 	args = append(args, "status", status)
 	if status == job.JobStatusSuccess {
 		// make sure the 'die_at' is reset in case it's a retrying job
 		args = append(args, "die_at", 0)
 	}
+	if status == job.JobStatusError {
+		flag := 0
+		if retrying {
+			flag = 1
+		}
+		args = append(args, "retrying", flag)
+	}
 
 	return rjs.updateJobStats(jobID, args...)
 }
@@ -627,6 +875,9 @@ func (rjs *RedisJobStatsManager) getJobStats(jobID string) (models.JobStats, err
 		case "upstream_job_id":
 			res.Stats.UpstreamJobID = value
 			break
+		case "batch_id":
+			res.Stats.BatchID = value
+			break
 		case "multiple_executions":
 			v, err := strconv.ParseBool(value)
 			if err != nil {
@@ -643,13 +894,22 @@ func (rjs *RedisJobStatsManager) getJobStats(jobID string) (models.JobStats, err
 }
 
 func (rjs *RedisJobStatsManager) saveJobStats(jobStats models.JobStats) error {
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	if err := rjs.sendJobStats(conn, jobStats); err != nil {
+		return err
+	}
+
+	return conn.Flush()
+}
+
+// sendJobStats writes the commands of saving the job stats to the connection without flushing.
+func (rjs *RedisJobStatsManager) sendJobStats(conn redis.Conn, jobStats models.JobStats) error {
 	if jobStats.Stats == nil {
 		return errors.New("malformed job stats object")
 	}
 
-	conn := rjs.redisPool.Get()
-	defer conn.Close()
-
 	key := utils.KeyJobStats(rjs.namespace, jobStats.Stats.JobID)
 	args := make([]interface{}, 0)
 	args = append(args, key)
@@ -680,7 +940,13 @@ func (rjs *RedisJobStatsManager) saveJobStats(jobStats models.JobStats) error {
 		args = append(args, "upstream_job_id", jobStats.Stats.UpstreamJobID)
 	}
 
-	conn.Send("HMSET", args...)
+	if len(jobStats.Stats.BatchID) > 0 {
+		args = append(args, "batch_id", jobStats.Stats.BatchID)
+	}
+
+	if err := conn.Send("HMSET", args...); err != nil {
+		return err
+	}
 	// If job kind is periodic job, expire time should not be set
 	// If job kind is scheduled job, expire time should be runAt+1day
 	if jobStats.Stats.JobKind != job.JobKindPeriodic {
@@ -693,10 +959,47 @@ func (rjs *RedisJobStatsManager) saveJobStats(jobStats models.JobStats) error {
 			}
 		}
 		expireTime += rand.Int63n(30) // Avoid lots of keys being expired at the same time
-		conn.Send("EXPIRE", key, expireTime)
+		return conn.Send("EXPIRE", key, expireTime)
 	}
 
-	return conn.Flush()
+	return nil
+}
+
+// getBatchStats returns the stats of the batch with the registered hook url.
+func (rjs *RedisJobStatsManager) getBatchStats(batchID string) (models.BatchStats, string, error) {
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	vals, err := redis.StringMap(conn.Do("HGETALL", utils.KeyBatchStats(rjs.namespace, batchID)))
+	if err != nil {
+		return models.BatchStats{}, "", err
+	}
+
+	if len(vals) == 0 {
+		return models.BatchStats{}, "", errs.NoObjectFoundError(fmt.Sprintf("batch '%s'", batchID))
+	}
+
+	jobIDs, err := redis.Strings(conn.Do("LRANGE", utils.KeyBatchJobs(rjs.namespace, batchID), 0, -1))
+	if err != nil {
+		return models.BatchStats{}, "", err
+	}
+
+	stats := &models.BatchStatData{
+		BatchID: batchID,
+		Counts:  make(map[string]int64),
+		JobIDs:  jobIDs,
+		RefLink: fmt.Sprintf("/api/v1/jobs/batch/%s", batchID),
+	}
+	stats.Total, _ = strconv.ParseInt(vals["total"], 10, 64)
+	stats.EnqueueTime, _ = strconv.ParseInt(vals["enqueue_time"], 10, 64)
+	stats.UpdateTime, _ = strconv.ParseInt(vals["update_time"], 10, 64)
+	for _, status := range batchCountedStatuses {
+		stats.Counts[status], _ = strconv.ParseInt(vals[status], 10, 64)
+	}
+	retrying, _ := strconv.ParseInt(vals["retrying"], 10, 64)
+	stats.Status = batchStatus(stats.Counts, retrying, stats.Total)
+
+	return models.BatchStats{Stats: stats}, vals["status_hook"], nil
 }
 
 func (rjs *RedisJobStatsManager) saveExecutions(upstreamJobID string, executions []string) error {
@@ -737,7 +1040,8 @@ func (rjs *RedisJobStatsManager) process(item *queueItem) error {
 		return rjs.saveJobStats(jobStats)
 	case opUpdateStatus:
 		data := item.Data.([]string)
-		return rjs.updateJobStatus(data[0], data[1])
+		retrying := len(data) > 2 && data[2] == "true"
+		return rjs.updateJobStatus(data[0], data[1], retrying)
 	case opCheckIn:
 		data := item.Data.([]string)
 		return rjs.checkIn(data[0], data[1])
@@ -753,6 +1057,11 @@ func (rjs *RedisJobStatsManager) process(item *queueItem) error {
 	case opUpdateStats:
 		data := item.Data.([]interface{})
 		return rjs.updateJobStats(data[0].(string), data[1:]...)
+	case opObserveRun:
+		data := item.Data.([]interface{})
+		return rjs.observe(data[0].(string), data[1].(string), data[2].(float64))
+	case opReportBatchStatus:
+		return rjs.reportBatchStatus(item.Data.(string))
 	default:
 		break
 	}
@@ -760,6 +1069,120 @@ func (rjs *RedisJobStatsManager) process(item *queueItem) error {
 	return nil
 }
 
+// HookEvents is implementation of same method in JobStatsManager interface.
+func (rjs *RedisJobStatsManager) HookEvents(jobID string) (*models.HookEventList, error) {
+	if utils.IsEmptyStr(jobID) {
+		return nil, errors.New("empty job ID")
+	}
+
+	return rjs.hookAgent.Events(jobID)
+}
+
+// HookDeliveryStats is implementation of same method in JobStatsManager interface.
+func (rjs *RedisJobStatsManager) HookDeliveryStats() (models.HookDeliveryStats, error) {
+	return rjs.hookAgent.Stats()
+}
+
+// Observe is implementation of same method in JobStatsManager interface.
+func (rjs *RedisJobStatsManager) Observe(jobName string, status string, duration time.Duration) {
+	if utils.IsEmptyStr(jobName) || utils.IsEmptyStr(status) {
+		return
+	}
+
+	item := &queueItem{
+		Op:   opObserveRun,
+		Data: []interface{}{jobName, status, duration.Seconds()},
+	}
+
+	rjs.processChan <- item
+}
+
+// JobRunMetrics is implementation of same method in JobStatsManager interface.
+func (rjs *RedisJobStatsManager) JobRunMetrics() (*models.JobRunMetrics, error) {
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	metrics := &models.JobRunMetrics{
+		Counts:    make(map[string]map[string]int64),
+		Durations: make(map[string]*models.DurationHistogram),
+	}
+
+	for _, status := range observedStatuses {
+		counts, err := redis.Int64Map(conn.Do("HGETALL", utils.KeyJobRunsCounter(rjs.namespace, status)))
+		if err != nil {
+			return nil, err
+		}
+
+		for jobName, count := range counts {
+			if _, ok := metrics.Counts[jobName]; !ok {
+				metrics.Counts[jobName] = make(map[string]int64)
+			}
+			metrics.Counts[jobName][status] = count
+		}
+	}
+
+	for jobName := range metrics.Counts {
+		values, err := redis.StringMap(conn.Do("HGETALL", utils.KeyJobDurations(rjs.namespace, jobName)))
+		if err != nil {
+			return nil, err
+		}
+
+		histogram := &models.DurationHistogram{
+			Buckets: DurationBuckets,
+			Counts:  make([]int64, len(DurationBuckets)),
+		}
+		// Accumulate the counts of buckets
+		var cumulative int64
+		for i, bucket := range DurationBuckets {
+			v, _ := strconv.ParseInt(values[bucketField(bucket)], 10, 64)
+			cumulative += v
+			histogram.Counts[i] = cumulative
+		}
+		histogram.Sum, _ = strconv.ParseFloat(values["sum"], 64)
+		histogram.Count, _ = strconv.ParseInt(values["count"], 10, 64)
+
+		metrics.Durations[jobName] = histogram
+	}
+
+	return metrics, nil
+}
+
+// observe counts the job run and puts its duration into the histogram bucket.
+// The bucket counts are not cumulative when persisting.
+func (rjs *RedisJobStatsManager) observe(jobName string, status string, seconds float64) error {
+	conn := rjs.redisPool.Get()
+	defer conn.Close()
+
+	durationsKey := utils.KeyJobDurations(rjs.namespace, jobName)
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := conn.Send("HINCRBY", utils.KeyJobRunsCounter(rjs.namespace, status), jobName, 1); err != nil {
+		return err
+	}
+	for _, bucket := range DurationBuckets {
+		if seconds <= bucket {
+			if err := conn.Send("HINCRBY", durationsKey, bucketField(bucket), 1); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if err := conn.Send("HINCRBYFLOAT", durationsKey, "sum", seconds); err != nil {
+		return err
+	}
+	if err := conn.Send("HINCRBY", durationsKey, "count", 1); err != nil {
+		return err
+	}
+	_, err := conn.Do("EXEC")
+
+	return err
+}
+
+func bucketField(bucket float64) string {
+	return fmt.Sprintf("le:%s", strconv.FormatFloat(bucket, 'g', -1, 64))
+}
+
 // HookData keeps the hook url info
 type HookData struct {
 	JobID   string `json:"job_id"`
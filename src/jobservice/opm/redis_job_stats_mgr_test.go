@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/models"
 	"github.com/goharbor/harbor/src/jobservice/utils"
@@ -279,6 +280,78 @@ func TestUpdateJobStats(t *testing.T) {
 	}
 }
 
+func TestBatchStats(t *testing.T) {
+	mgr := createStatsManager(redisPool)
+	mgr.Start()
+	defer mgr.Shutdown()
+	<-time.After(200 * time.Millisecond)
+
+	batch := models.BatchStats{
+		Stats: &models.BatchStatData{
+			BatchID: "fake_batch_ID",
+			Total:   2,
+			Counts:  map[string]int64{job.JobStatusPending: 2},
+		},
+	}
+	jobs := []models.JobStats{createFakeStats(), createFakeStats()}
+	jobs[0].Stats.JobID = "fake_batch_job_ID_1"
+	jobs[1].Stats.JobID = "fake_batch_job_ID_2"
+	for _, j := range jobs {
+		j.Stats.JobKind = job.JobKindGeneric
+		j.Stats.BatchID = "fake_batch_ID"
+	}
+	jobHooks := map[string]string{"fake_batch_job_ID_2": "http://localhost:9090"}
+	if err := mgr.SaveBatch(batch, jobs, jobHooks, ""); err != nil {
+		t.Fatal(err)
+	}
+	if hookURL, err := mgr.GetHook("fake_batch_job_ID_2"); err != nil || hookURL != "http://localhost:9090" {
+		t.Fatalf("expect hook of job fake_batch_job_ID_2 registered with the batch but got %s: %v", hookURL, err)
+	}
+
+	mgr.SetJobStatus("fake_batch_job_ID_1", job.JobStatusRunning)
+	mgr.SetJobStatus("fake_batch_job_ID_2", job.JobStatusRunning)
+	<-time.After(200 * time.Millisecond)
+	mgr.SetJobStatus("fake_batch_job_ID_1", job.JobStatusSuccess)
+	mgr.SetJobFailed("fake_batch_job_ID_2", true)
+	<-time.After(200 * time.Millisecond)
+
+	res, err := mgr.RetrieveBatch("fake_batch_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusRunning {
+		t.Fatalf("expect running batch with 1 job to be retried but got %+v", res.Stats)
+	}
+
+	mgr.SetJobStatus("fake_batch_job_ID_2", job.JobStatusRunning)
+	<-time.After(200 * time.Millisecond)
+	mgr.Update("fake_batch_job_ID_2", "status", job.JobStatusSuccess)
+	<-time.After(200 * time.Millisecond)
+
+	res, err = mgr.RetrieveBatch("fake_batch_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusSuccess || res.Stats.Counts[job.JobStatusSuccess] != 2 || len(res.Stats.JobIDs) != 2 {
+		t.Fatalf("expect successful batch with 2 jobs but got %+v", res.Stats)
+	}
+
+	stats, err := mgr.Retrieve("fake_batch_job_ID_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stats.BatchID != "fake_batch_ID" {
+		t.Fatalf("expect batch ID 'fake_batch_ID' but got '%s'", stats.Stats.BatchID)
+	}
+
+	if err := mgr.DeleteBatch("fake_batch_ID"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.RetrieveBatch("fake_batch_ID"); !errs.IsObjectNotFoundError(err) {
+		t.Fatalf("expect not found error for deleted batch but got %v", err)
+	}
+}
+
 func getRedisHost() string {
 	redisHost := os.Getenv(testingRedisHost)
 	if redisHost == "" {
@@ -157,7 +157,7 @@ func (pe *periodicEnqueuer) enqueue() error {
 
 			// Create an execution (job) based on the periodic job template (policy)
 			job := &work.Job{
-				Name: pl.JobName,
+				Name: utils.QueuedJobName(pl.JobName, pl.Queue),
 				ID:   scheduledExecutionID,
 
 				// This is technically wrong, but this lets the bytes be identical for the same periodic job instance.
@@ -190,7 +190,7 @@ func (pe *periodicEnqueuer) enqueue() error {
 			logger.Infof("Schedule job %s:%s for policy %s at %d by enqueuer %s", job.Name, job.ID, pl.PolicyID, epoch, pe.identity)
 
 			// Try to save the stats of new scheduled execution (job).
-			pe.createExecution(pl.PolicyID, pl.JobName, scheduledExecutionID, epoch)
+			pe.createExecution(pl, scheduledExecutionID, epoch)
 
 			// Get web hook from the periodic job (policy)
 			webHookURL, err := pe.statsManager.GetHook(pl.PolicyID)
@@ -220,18 +220,19 @@ func (pe *periodicEnqueuer) enqueue() error {
 	return nil
 }
 
-func (pe *periodicEnqueuer) createExecution(upstreamJobID, upstreamJobName, executionID string, runAt int64) {
+func (pe *periodicEnqueuer) createExecution(pl *PeriodicJobPolicy, executionID string, runAt int64) {
 	execution := models.JobStats{
 		Stats: &models.JobStatData{
 			JobID:         executionID,
-			JobName:       upstreamJobName,
+			JobName:       pl.JobName,
+			Queue:         utils.NormalizeQueueName(pl.Queue),
 			Status:        job.JobStatusPending,
 			JobKind:       job.JobKindScheduled,
 			EnqueueTime:   time.Now().Unix(),
 			UpdateTime:    time.Now().Unix(),
 			RefLink:       fmt.Sprintf("/api/v1/jobs/%s", executionID),
 			RunAt:         runAt,
-			UpstreamJobID: upstreamJobID,
+			UpstreamJobID: pl.PolicyID,
 		},
 	}
 
@@ -23,12 +23,13 @@ type Interface interface {
 	// jobName string           : The name of periodical job
 	// params models.Parameters : The parameters required by the periodical job
 	// cronSpec string          : The periodical settings with cron format
+	// queue string             : The queue the executions are enqueued to, empty for the default queue
 	//
 	// Returns:
 	//  The uuid of the cron job policy
 	//  The latest next trigger time
 	//  error if failed to schedule
-	Schedule(jobName string, params models.Parameters, cronSpec string) (string, int64, error)
+	Schedule(jobName string, params models.Parameters, cronSpec string, queue string) (string, int64, error)
 
 	// Unschedule the specified cron job policy.
 	//
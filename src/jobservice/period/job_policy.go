@@ -36,6 +36,7 @@ type PeriodicJobPolicy struct {
 	JobName       string                 `json:"job_name"`
 	JobParameters map[string]interface{} `json:"job_params"`
 	CronSpec      string                 `json:"cron_spec"`
+	Queue         string                 `json:"queue,omitempty"`
 }
 
 // Serialize the policy to raw data.
@@ -89,7 +89,7 @@ func (rps *RedisPeriodicScheduler) Start() {
 }
 
 // Schedule is implementation of the same method in period.Interface
-func (rps *RedisPeriodicScheduler) Schedule(jobName string, params models.Parameters, cronSpec string) (string, int64, error) {
+func (rps *RedisPeriodicScheduler) Schedule(jobName string, params models.Parameters, cronSpec string, queue string) (string, int64, error) {
 	if utils.IsEmptyStr(jobName) {
 		return "", 0, errors.New("empty job name is not allowed")
 	}
@@ -111,6 +111,10 @@ func (rps *RedisPeriodicScheduler) Schedule(jobName string, params models.Parame
 		JobParameters: params,
 		CronSpec:      cronSpec,
 	}
+	// Keep the policies of the default queue same with the existing ones
+	if utils.NormalizeQueueName(queue) != utils.DefaultQueue {
+		jobPolicy.Queue = queue
+	}
 	// Serialize data
 	rawJSON, err := jobPolicy.Serialize()
 	if err != nil {
@@ -36,7 +36,7 @@ func TestScheduler(t *testing.T) {
 	scheduler := myPeriodicScheduler(statsManager)
 	params := make(map[string]interface{})
 	params["image"] = "testing:v1"
-	id, runAt, err := scheduler.Schedule("fake_job", params, "5 * * * * *")
+	id, runAt, err := scheduler.Schedule("fake_job", params, "5 * * * * *", "")
 	if err != nil {
 		t.Fatal(err)
 	}
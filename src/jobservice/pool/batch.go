@@ -0,0 +1,110 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/utils"
+)
+
+// newBatch creates the generic jobs of the batch with their stats.
+func newBatch(jobs []*models.JobData) (models.BatchStats, []*work.Job, []models.JobStats) {
+	now := time.Now().Unix()
+	batch := &models.BatchStatData{
+		BatchID:     utils.MakeIdentifier(),
+		Status:      job.JobStatusPending,
+		Total:       int64(len(jobs)),
+		Counts:      map[string]int64{job.JobStatusPending: int64(len(jobs))},
+		JobIDs:      make([]string, 0, len(jobs)),
+		EnqueueTime: now,
+		UpdateTime:  now,
+	}
+	batch.RefLink = fmt.Sprintf("/api/v1/jobs/batch/%s", batch.BatchID)
+
+	works := make([]*work.Job, 0, len(jobs))
+	stats := make([]models.JobStats, 0, len(jobs))
+	for _, jd := range jobs {
+		isUnique, queue := false, ""
+		if jd.Metadata != nil {
+			isUnique, queue = jd.Metadata.IsUnique, jd.Metadata.Queue
+		}
+		j := &work.Job{
+			Name:       utils.QueuedJobName(jd.Name, queue),
+			ID:         utils.MakeIdentifier(),
+			EnqueuedAt: now,
+			Args:       jd.Parameters,
+			Unique:     isUnique,
+		}
+
+		res := generateResult(j, job.JobKindGeneric, isUnique)
+		res.Stats.BatchID = batch.BatchID
+
+		works = append(works, j)
+		stats = append(stats, res)
+		batch.JobIDs = append(batch.JobIDs, j.ID)
+	}
+
+	return models.BatchStats{Stats: batch}, works, stats
+}
+
+// batchHooks maps the IDs of the jobs in the batch to their status hooks if they're set.
+func batchHooks(jobs []*models.JobData, batch models.BatchStats) map[string]string {
+	hooks := make(map[string]string)
+	for i, jd := range jobs {
+		if utils.IsEmptyStr(jd.StatusHook) {
+			continue
+		}
+
+		hooks[batch.Stats.JobIDs[i]] = jd.StatusHook
+	}
+
+	return hooks
+}
+
+// uniqueBatch sets the unique flags of the unique jobs in the batch.
+// If any of the jobs is duplicated, the flags already set are removed.
+func uniqueBatch(deDuplicator DeDuplicator, works []*work.Job) error {
+	for i, j := range works {
+		if !j.Unique {
+			continue
+		}
+
+		if err := deDuplicator.Unique(j.Name, j.Args); err != nil {
+			releaseBatchUniqueSigns(deDuplicator, works[:i])
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseBatchUniqueSigns removes the unique flags of the jobs in the batch which are failed to be enqueued.
+func releaseBatchUniqueSigns(deDuplicator DeDuplicator, works []*work.Job) {
+	for _, j := range works {
+		if !j.Unique {
+			continue
+		}
+
+		if err := deDuplicator.DelUniqueSign(j.Name, j.Args); err != nil {
+			logger.Errorf("delete job unique sign error: %s", err)
+		}
+	}
+}
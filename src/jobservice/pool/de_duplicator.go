@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/goharbor/harbor/src/jobservice/errs"
 	"github.com/goharbor/harbor/src/jobservice/models"
@@ -107,6 +108,53 @@ func (rdd *RedisDeDuplicator) DelUniqueSign(jobName string, params models.Parame
 	return nil
 }
 
+// MemDeDuplicator implement the DeDuplicator interface in memory.
+type MemDeDuplicator struct {
+	lock  *sync.Mutex
+	signs map[string]bool
+}
+
+// NewMemDeDuplicator is constructor of MemDeDuplicator
+func NewMemDeDuplicator() *MemDeDuplicator {
+	return &MemDeDuplicator{
+		lock:  new(sync.Mutex),
+		signs: make(map[string]bool),
+	}
+}
+
+// Unique checks if the job is unique and set unique flag if it is not set yet.
+func (mdd *MemDeDuplicator) Unique(jobName string, params models.Parameters) error {
+	uniqueKey, err := redisKeyUniqueJob("", jobName, params)
+	if err != nil {
+		return fmt.Errorf("unique job error: %s", err)
+	}
+
+	mdd.lock.Lock()
+	defer mdd.lock.Unlock()
+
+	if mdd.signs[uniqueKey] {
+		return errs.ConflictError(uniqueKey)
+	}
+	mdd.signs[uniqueKey] = true
+
+	return nil
+}
+
+// DelUniqueSign delete the job unique sign
+func (mdd *MemDeDuplicator) DelUniqueSign(jobName string, params models.Parameters) error {
+	uniqueKey, err := redisKeyUniqueJob("", jobName, params)
+	if err != nil {
+		return fmt.Errorf("delete unique job error: %s", err)
+	}
+
+	mdd.lock.Lock()
+	defer mdd.lock.Unlock()
+
+	delete(mdd.signs, uniqueKey)
+
+	return nil
+}
+
 // Same key with upstream framework
 func redisKeyUniqueJob(namespace, jobName string, args map[string]interface{}) (string, error) {
 	var buf bytes.Buffer
@@ -14,7 +14,11 @@
 
 package pool
 
-import "github.com/goharbor/harbor/src/jobservice/models"
+import (
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/models"
+)
 
 // Interface for worker pool.
 // More like a driver to transparent the lower queue.
@@ -47,11 +51,32 @@ type Interface interface {
 	// jobName string           : the name of enqueuing job
 	// params models.Parameters : parameters of enqueuing job
 	// isUnique bool            : specify if duplicated job will be discarded
+	// queue string             : the queue the job is put into, empty for the default queue
 	//
 	// Returns:
 	//  models.JobStats: the stats of enqueuing job if succeed
 	//  error          : if failed to enqueue
-	Enqueue(jobName string, params models.Parameters, isUnique bool) (models.JobStats, error)
+	Enqueue(jobName string, params models.Parameters, isUnique bool, queue string) (models.JobStats, error)
+
+	// Enqueue a batch of generic jobs atomically, either all the jobs or none of them are enqueued.
+	//
+	// jobs []*models.JobData : the jobs of the batch, the job kinds are ignored and each job goes to its own queue,
+	//                          the status hooks of the jobs are registered with the batch
+	// hookURL string         : the hook url called when all the jobs are completed, optional
+	//
+	// Returns:
+	//  models.BatchStats: the stats of the batch with the IDs of the jobs in order if succeed
+	//  error            : if failed to enqueue
+	EnqueueBatch(jobs []*models.JobData, hookURL string) (models.BatchStats, error)
+
+	// Get the aggregated stats of the specified batch
+	//
+	// batchID string : ID of the batch
+	//
+	// Returns:
+	//  models.BatchStats : batch stats data
+	//  error             : error returned if meet any problems
+	GetBatchStats(batchID string) (models.BatchStats, error)
 
 	// Schedule job to run after the specified interval (seconds).
 	//
@@ -59,22 +84,24 @@ type Interface interface {
 	// runAfterSeconds uint64   : the waiting interval with seconds
 	// params models.Parameters : parameters of enqueuing job
 	// isUnique bool            : specify if duplicated job will be discarded
+	// queue string             : the queue the job is put into, empty for the default queue
 	//
 	// Returns:
 	//  models.JobStats: the stats of enqueuing job if succeed
 	//  error          : if failed to enqueue
-	Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool) (models.JobStats, error)
+	Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool, queue string) (models.JobStats, error)
 
 	// Schedule the job periodically running.
 	//
 	// jobName string           : the name of enqueuing job
 	// params models.Parameters : parameters of enqueuing job
 	// cronSetting string       : the periodic duration with cron style like '0 * * * * *'
+	// queue string             : the queue the executions are put into, empty for the default queue
 	//
 	// Returns:
 	//  models.JobStats: the stats of enqueuing job if succeed
 	//  error          : if failed to enqueue
-	PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string) (models.JobStats, error)
+	PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string, queue string) (models.JobStats, error)
 
 	// Return the status info of the pool.
 	//
@@ -135,6 +162,34 @@ type Interface interface {
 	//  error           : error returned if meet any problems
 	RetryJob(jobID string) error
 
+	// Get the jobs in the dead queue
+	//
+	// page uint : the page number of the dead jobs list, 1-based and each page has 20 items
+	//
+	// Returns:
+	//  []*models.DeadJobData : the dead jobs in the page
+	//  int64                 : the total number of the dead jobs
+	//  error                 : error returned if meet any problems
+	DeadJobs(page uint) ([]*models.DeadJobData, int64, error)
+
+	// Retry the job in the dead queue
+	//
+	// jobID string  : ID of the dead job
+	// diedAt int64  : the time the job died at, which locates the job in the dead queue without searching it
+	//
+	// Return:
+	//  error           : error returned if meet any problems
+	RetryDeadJob(jobID string, diedAt int64) error
+
+	// Delete the job from the dead queue
+	//
+	// jobID string  : ID of the dead job
+	// diedAt int64  : the time the job died at, which locates the job in the dead queue without searching it
+	//
+	// Return:
+	//  error           : error returned if meet any problems
+	DeleteDeadJob(jobID string, diedAt int64) error
+
 	// Register hook
 	//
 	// jobID string   : ID of job
@@ -143,4 +198,44 @@ type Interface interface {
 	// Return:
 	//  error        : error returned if meet any problems
 	RegisterHook(jobID string, hookURL string) error
+
+	// Get the status change events of the job which are not delivered to the hook yet
+	//
+	// jobID string : ID of job
+	//
+	// Return:
+	//  *models.HookEventList : the undelivered events
+	//  error                 : error returned if meet any problems
+	HookEvents(jobID string) (*models.HookEventList, error)
+
+	// Get the metrics of the hook events delivery
+	//
+	// Return:
+	//  models.HookDeliveryStats : the delivery stats
+	//  error                    : error returned if meet any problems
+	HookDeliveryStats() (models.HookDeliveryStats, error)
+
+	// Get the metrics of the queues, running jobs, worker pools, finished runs and hook delivery
+	//
+	// Return:
+	//  *models.JobMetrics : the metrics data
+	//  error              : error returned if meet any problems
+	Metrics() (*models.JobMetrics, error)
+
+	// Drain the pool, block until the draining is completed.
+	// The pool stops pulling new jobs and waits for the in-flight jobs until the timeout,
+	// then the unfinished jobs are put back into the queue to be run by the other nodes.
+	//
+	// timeout time.Duration : the deadline of the in-flight jobs
+	//
+	// Returns:
+	//  int   : the number of the unfinished jobs which are re-queued
+	//  error : error returned if meet any problems
+	Drain(timeout time.Duration) (int, error)
+
+	// Check if the pool is draining
+	//
+	// Return:
+	//  bool : true if the pool is draining
+	IsDraining() bool
 }
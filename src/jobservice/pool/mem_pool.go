@@ -13,3 +13,1045 @@
 // limitations under the License.
 
 package pool
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/logger"
+	"github.com/goharbor/harbor/src/jobservice/models"
+	"github.com/goharbor/harbor/src/jobservice/opm"
+	"github.com/goharbor/harbor/src/jobservice/period"
+	"github.com/goharbor/harbor/src/jobservice/utils"
+	"github.com/robfig/cron"
+)
+
+const (
+	// Interval for moving the due scheduled jobs into the queue
+	memPoolTickInterval = time.Second
+
+	// Consistent with the backend worker pool
+	defaultMaxFails = 4
+)
+
+// memJobHandler runs the registered job.
+type memJobHandler struct {
+	runner   *RedisJob
+	maxFails uint
+}
+
+// memScheduledJob is the job waiting to be run at the specified time,
+// including the scheduled jobs and the failed jobs waiting for retrying.
+type memScheduledJob struct {
+	runAt int64
+	job   *work.Job
+}
+
+// memDeadJob is the job failed after using up the allowed failures.
+type memDeadJob struct {
+	diedAt int64
+	job    *work.Job
+}
+
+// memPeriodicPolicy keeps the periodic policy with its next run time.
+type memPeriodicPolicy struct {
+	policy   *period.PeriodicJobPolicy
+	schedule cron.Schedule
+	nextRun  time.Time
+}
+
+// MemPool is the pool implementation which queues and runs the jobs in memory.
+// The queued jobs and the job stats are lost if the process exits, it's designed
+// for the single node deployment without redis like the development environment
+// and the integration testing.
+type MemPool struct {
+	poolID       string
+	context      *env.Context
+	workerCount  uint
+	statsManager opm.JobStatsManager
+	deDuplicator DeDuplicator
+	startedAt    int64
+
+	// no need to sync as write once and then only read
+	// key is name of known job
+	// value is the type of known job
+	knownJobs map[string]interface{}
+	handlers  map[string]*memJobHandler
+	// the queues served by this pool
+	queues []string
+
+	lock      *sync.Mutex
+	cond      *sync.Cond
+	stopped   bool
+	draining  bool
+	queue     []*work.Job
+	scheduled []*memScheduledJob
+	dead      []*memDeadJob
+	policies  map[string]*memPeriodicPolicy
+	// key is ID of the running job
+	running map[string]*work.Job
+	workers *sync.WaitGroup
+	// IDs of the jobs re-queued by draining
+	requeued *sync.Map
+}
+
+// NewMemPool is constructor of MemPool.
+// The pool only accepts the jobs of the specified queues, the default queue is served if no queue is specified.
+func NewMemPool(ctx *env.Context, workerCount uint, queues ...string) *MemPool {
+	lock := new(sync.Mutex)
+	if len(queues) == 0 {
+		queues = []string{utils.DefaultQueue}
+	}
+
+	return &MemPool{
+		poolID:       utils.MakeIdentifier(),
+		context:      ctx,
+		workerCount:  workerCount,
+		statsManager: opm.NewMemJobStatsManager(ctx.SystemContext),
+		deDuplicator: NewMemDeDuplicator(),
+		knownJobs:    make(map[string]interface{}),
+		handlers:     make(map[string]*memJobHandler),
+		queues:       queues,
+		lock:         lock,
+		cond:         sync.NewCond(lock),
+		queue:        make([]*work.Job, 0),
+		scheduled:    make([]*memScheduledJob, 0),
+		dead:         make([]*memDeadJob, 0),
+		policies:     make(map[string]*memPeriodicPolicy),
+		running:      make(map[string]*work.Job),
+		workers:      new(sync.WaitGroup),
+		requeued:     new(sync.Map),
+	}
+}
+
+// Start to serve
+// Unblock action
+func (mp *MemPool) Start() error {
+	if mp.context == nil || mp.context.SystemContext == nil {
+		// report and exit
+		return errors.New("Memory worker pool can not start as it's not correctly configured")
+	}
+
+	if mp.workerCount == 0 {
+		return errors.New("Memory worker pool can not start without workers")
+	}
+
+	mp.startedAt = time.Now().Unix()
+	mp.statsManager.Start()
+
+	for i := uint(0); i < mp.workerCount; i++ {
+		mp.workers.Add(1)
+		go func() {
+			defer mp.workers.Done()
+			mp.work()
+		}()
+	}
+
+	mp.context.WG.Add(1)
+	go func() {
+		defer func() {
+			mp.context.WG.Done()
+			logger.Infof("Memory worker pool is stopped")
+		}()
+
+		logger.Infof("Memory worker pool is started")
+
+		tk := time.NewTicker(memPoolTickInterval)
+		defer tk.Stop()
+
+		for {
+			select {
+			case <-tk.C:
+				mp.enqueueDue(time.Now())
+			case <-mp.context.SystemContext.Done():
+				mp.lock.Lock()
+				mp.stopped = true
+				mp.cond.Broadcast()
+				mp.lock.Unlock()
+
+				// Wait for the running jobs
+				mp.workers.Wait()
+				mp.statsManager.Shutdown()
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// RegisterJob is used to register the job to the pool.
+// j is the type of job
+func (mp *MemPool) RegisterJob(name string, j interface{}) error {
+	if utils.IsEmptyStr(name) || j == nil {
+		return errors.New("job can not be registered with empty name or nil interface")
+	}
+
+	// j must be job.Interface
+	if _, ok := j.(job.Interface); !ok {
+		return errors.New("job must implement the job.Interface")
+	}
+
+	// 1:1 constraint
+	if jInList, ok := mp.knownJobs[name]; ok {
+		return fmt.Errorf("Job name %s has been already registered with %s", name, reflect.TypeOf(jInList).String())
+	}
+
+	// Same job implementation can be only registered with one name
+	for jName, jInList := range mp.knownJobs {
+		jobImpl := reflect.TypeOf(j).String()
+		if reflect.TypeOf(jInList).String() == jobImpl {
+			return fmt.Errorf("Job %s has been already registered with name %s", jobImpl, jName)
+		}
+	}
+
+	// Get more info from j
+	theJ := Wrap(j)
+
+	mp.handlers[name] = &memJobHandler{
+		runner:   mp.newRunner(j),
+		maxFails: theJ.MaxFails(),
+	}
+	mp.knownJobs[name] = j // keep the name of registered jobs as known jobs for future validation
+
+	logger.Infof("Register job %s with name %s", reflect.TypeOf(j).String(), name)
+
+	return nil
+}
+
+// RegisterJobs is used to register multiple jobs to pool.
+func (mp *MemPool) RegisterJobs(jobs map[string]interface{}) error {
+	if jobs == nil || len(jobs) == 0 {
+		return nil
+	}
+
+	for name, j := range jobs {
+		if err := mp.RegisterJob(name, j); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Enqueue job
+func (mp *MemPool) Enqueue(jobName string, params models.Parameters, isUnique bool, queue string) (models.JobStats, error) {
+	if err := mp.checkQueue(queue); err != nil {
+		return models.JobStats{}, err
+	}
+
+	queuedName := utils.QueuedJobName(jobName, queue)
+	if isUnique {
+		if err := mp.deDuplicator.Unique(queuedName, params); err != nil {
+			return models.JobStats{}, err
+		}
+	}
+
+	j := newMemJob(queuedName, params, isUnique)
+	res := generateResult(j, job.JobKindGeneric, isUnique)
+	// Save the stats before the job is picked up by the workers to avoid losing the status updating
+	mp.statsManager.Save(res)
+
+	mp.lock.Lock()
+	mp.queue = append(mp.queue, j)
+	mp.cond.Signal()
+	mp.lock.Unlock()
+
+	return res, nil
+}
+
+// EnqueueBatch enqueues the jobs of the batch atomically
+func (mp *MemPool) EnqueueBatch(jobs []*models.JobData, hookURL string) (models.BatchStats, error) {
+	if len(jobs) == 0 {
+		return models.BatchStats{}, errors.New("empty batch")
+	}
+
+	for _, jd := range jobs {
+		if jd.Metadata == nil {
+			continue
+		}
+		if err := mp.checkQueue(jd.Metadata.Queue); err != nil {
+			return models.BatchStats{}, err
+		}
+	}
+
+	batch, works, stats := newBatch(jobs)
+	if err := uniqueBatch(mp.deDuplicator, works); err != nil {
+		return models.BatchStats{}, err
+	}
+
+	// Save the stats before the jobs are picked up by the workers to avoid losing the status updating
+	if err := mp.statsManager.SaveBatch(batch, stats, batchHooks(jobs, batch), hookURL); err != nil {
+		releaseBatchUniqueSigns(mp.deDuplicator, works)
+		return models.BatchStats{}, err
+	}
+
+	mp.lock.Lock()
+	mp.queue = append(mp.queue, works...)
+	mp.cond.Broadcast()
+	mp.lock.Unlock()
+
+	return batch, nil
+}
+
+// GetBatchStats return the aggregated stats of the specified batch.
+func (mp *MemPool) GetBatchStats(batchID string) (models.BatchStats, error) {
+	if utils.IsEmptyStr(batchID) {
+		return models.BatchStats{}, errors.New("empty batch ID")
+	}
+
+	return mp.statsManager.RetrieveBatch(batchID)
+}
+
+// Schedule job
+func (mp *MemPool) Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool, queue string) (models.JobStats, error) {
+	if err := mp.checkQueue(queue); err != nil {
+		return models.JobStats{}, err
+	}
+
+	queuedName := utils.QueuedJobName(jobName, queue)
+	if isUnique {
+		if err := mp.deDuplicator.Unique(queuedName, params); err != nil {
+			return models.JobStats{}, err
+		}
+	}
+
+	j := newMemJob(queuedName, params, isUnique)
+	runAt := j.EnqueuedAt + int64(runAfterSeconds)
+
+	res := generateResult(j, job.JobKindScheduled, isUnique)
+	res.Stats.RunAt = runAt
+	mp.statsManager.Save(res)
+
+	mp.lock.Lock()
+	mp.scheduled = append(mp.scheduled, &memScheduledJob{
+		runAt: runAt,
+		job:   j,
+	})
+	mp.lock.Unlock()
+
+	return res, nil
+}
+
+// PeriodicallyEnqueue job
+func (mp *MemPool) PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string, queue string) (models.JobStats, error) {
+	if err := mp.checkQueue(queue); err != nil {
+		return models.JobStats{}, err
+	}
+
+	if utils.IsEmptyStr(cronSetting) {
+		return models.JobStats{}, errors.New("cron spec is not set")
+	}
+
+	schedule, err := cron.Parse(cronSetting)
+	if err != nil {
+		return models.JobStats{}, err
+	}
+
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	queue = utils.NormalizeQueueName(queue)
+
+	// Same policy can not be scheduled twice
+	for id, p := range mp.policies {
+		if p.policy.JobName == jobName &&
+			p.policy.Queue == queue &&
+			p.policy.CronSpec == cronSetting &&
+			reflect.DeepEqual(p.policy.JobParameters, map[string]interface{}(params)) {
+			return models.JobStats{}, errs.ConflictError(id)
+		}
+	}
+
+	id, _ := utils.MakePeriodicPolicyUUID()
+	nextRun := schedule.Next(time.Now())
+
+	res := models.JobStats{
+		Stats: &models.JobStatData{
+			JobID:                id,
+			JobName:              jobName,
+			Queue:                queue,
+			Status:               job.JobStatusPending,
+			JobKind:              job.JobKindPeriodic,
+			CronSpec:             cronSetting,
+			EnqueueTime:          time.Now().Unix(),
+			UpdateTime:           time.Now().Unix(),
+			RefLink:              fmt.Sprintf("/api/v1/jobs/%s", id),
+			RunAt:                nextRun.Unix(),
+			IsMultipleExecutions: true, // True for periodic job
+		},
+	}
+	mp.statsManager.Save(res)
+
+	mp.policies[id] = &memPeriodicPolicy{
+		policy: &period.PeriodicJobPolicy{
+			PolicyID:      id,
+			JobName:       jobName,
+			JobParameters: params,
+			CronSpec:      cronSetting,
+			Queue:         queue,
+		},
+		schedule: schedule,
+		nextRun:  nextRun,
+	}
+
+	return res, nil
+}
+
+// GetJobStats return the job stats of the specified enqueued job.
+func (mp *MemPool) GetJobStats(jobID string) (models.JobStats, error) {
+	if utils.IsEmptyStr(jobID) {
+		return models.JobStats{}, errors.New("empty job ID")
+	}
+
+	return mp.statsManager.Retrieve(jobID)
+}
+
+// Stats of pool
+func (mp *MemPool) Stats() (models.JobPoolStats, error) {
+	return models.JobPoolStats{
+		Pools: []*models.JobPoolStatsData{mp.poolStats()},
+	}, nil
+}
+
+// StopJob will stop the job
+func (mp *MemPool) StopJob(jobID string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	theJob, err := mp.statsManager.Retrieve(jobID)
+	if err != nil {
+		return err
+	}
+
+	switch theJob.Stats.JobKind {
+	case job.JobKindGeneric:
+		// Only running job can be stopped
+		if theJob.Stats.Status != job.JobStatusRunning {
+			return fmt.Errorf("job '%s' is not a running job", jobID)
+		}
+	case job.JobKindScheduled:
+		// we need to delete the scheduled job in the queue if it is not running yet
+		// otherwise, stop it.
+		if theJob.Stats.Status == job.JobStatusPending {
+			if !mp.removePendingJob(jobID) {
+				return fmt.Errorf("job '%s' is not found in the queue", jobID)
+			}
+
+			// Update the job status to 'stopped'
+			mp.statsManager.SetJobStatus(jobID, job.JobStatusStopped)
+
+			logger.Debugf("Scheduled job which plan to run at %d '%s' is stopped", theJob.Stats.RunAt, jobID)
+
+			return nil
+		}
+	case job.JobKindPeriodic:
+		// firstly delete the periodic job policy
+		mp.lock.Lock()
+		_, ok := mp.policies[jobID]
+		delete(mp.policies, jobID)
+		mp.lock.Unlock()
+
+		if !ok {
+			return fmt.Errorf("periodic job policy %s is not found", jobID)
+		}
+
+		logger.Infof("Periodic job policy %s is removed", jobID)
+
+		// secondly we need try to stop the executions of this periodic job, a try best action
+		if err := mp.stopExecutionsOfPeriodicPolicy(jobID); err != nil {
+			// only logged
+			logger.Errorf("Errors happened when stopping jobs of periodic policy %s: %s", jobID, err)
+		}
+
+		// thirdly expire the job stats of this periodic job if exists
+		if err := mp.statsManager.ExpirePeriodicJobStats(jobID); err != nil {
+			// only logged
+			logger.Errorf("Expire the stats of job %s failed with error: %s\n", jobID, err)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("Job kind %s is not supported", theJob.Stats.JobKind)
+	}
+
+	// Check if the job has 'running' instance
+	if theJob.Stats.Status == job.JobStatusRunning {
+		// Send 'stop' ctl command to the running instance
+		if err := mp.statsManager.SendCommand(jobID, opm.CtlCommandStop, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CancelJob will cancel the job
+func (mp *MemPool) CancelJob(jobID string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	theJob, err := mp.statsManager.Retrieve(jobID)
+	if err != nil {
+		return err
+	}
+
+	switch theJob.Stats.JobKind {
+	case job.JobKindGeneric:
+		if theJob.Stats.Status != job.JobStatusRunning {
+			return fmt.Errorf("only running job can be cancelled, job '%s' seems not running now", theJob.Stats.JobID)
+		}
+
+		// Send 'cancel' ctl command to the running instance
+		if err := mp.statsManager.SendCommand(jobID, opm.CtlCommandCancel, false); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("job kind '%s' does not support 'cancel' operation", theJob.Stats.JobKind)
+	}
+
+	return nil
+}
+
+// RetryJob retry the job
+func (mp *MemPool) RetryJob(jobID string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	dj, err := mp.removeDeadJob(jobID)
+	if err != nil {
+		return err
+	}
+
+	// Give it a fresh start
+	dj.job.Fails = 0
+
+	mp.lock.Lock()
+	mp.queue = append(mp.queue, dj.job)
+	mp.cond.Signal()
+	mp.lock.Unlock()
+
+	return nil
+}
+
+// DeadJobs returns the jobs in the dead queue
+func (mp *MemPool) DeadJobs(page uint) ([]*models.DeadJobData, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	total := int64(len(mp.dead))
+	start := int64(page-1) * deadJobsPageSize
+	jobs := make([]*models.DeadJobData, 0, deadJobsPageSize)
+	for i := start; i < total && i < start+deadJobsPageSize; i++ {
+		dj := mp.dead[i]
+		jobName, _ := utils.ParseQueuedJobName(dj.job.Name)
+		jobs = append(jobs, &models.DeadJobData{
+			JobID:     dj.job.ID,
+			JobName:   jobName,
+			Fails:     dj.job.Fails,
+			LastError: dj.job.LastErr,
+			FailedAt:  dj.job.FailedAt,
+			DieAt:     dj.diedAt,
+		})
+	}
+
+	return jobs, total, nil
+}
+
+// RetryDeadJob retries the job in the dead queue, the dead jobs are located by their IDs in memory
+func (mp *MemPool) RetryDeadJob(jobID string, diedAt int64) error {
+	return mp.RetryJob(jobID)
+}
+
+// DeleteDeadJob removes the job from the dead queue, the dead jobs are located by their IDs in memory
+func (mp *MemPool) DeleteDeadJob(jobID string, diedAt int64) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	_, err := mp.removeDeadJob(jobID)
+
+	return err
+}
+
+// IsKnownJob ...
+func (mp *MemPool) IsKnownJob(name string) (interface{}, bool) {
+	v, ok := mp.knownJobs[name]
+	return v, ok
+}
+
+// ValidateJobParameters ...
+func (mp *MemPool) ValidateJobParameters(jobType interface{}, params map[string]interface{}) error {
+	if jobType == nil {
+		return errors.New("nil job type")
+	}
+
+	theJ := Wrap(jobType)
+	return theJ.Validate(params)
+}
+
+// RegisterHook registers status hook url
+// sync method
+func (mp *MemPool) RegisterHook(jobID string, hookURL string) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	if !utils.IsValidURL(hookURL) {
+		return errors.New("invalid hook url")
+	}
+
+	return mp.statsManager.RegisterHook(jobID, hookURL, false)
+}
+
+// HookEvents is implementation of the same method in the pool.Interface
+func (mp *MemPool) HookEvents(jobID string) (*models.HookEventList, error) {
+	if utils.IsEmptyStr(jobID) {
+		return nil, errors.New("empty job ID")
+	}
+
+	// Make sure the job is existing
+	if _, err := mp.statsManager.Retrieve(jobID); err != nil {
+		return nil, err
+	}
+
+	return mp.statsManager.HookEvents(jobID)
+}
+
+// HookDeliveryStats is implementation of the same method in the pool.Interface
+func (mp *MemPool) HookDeliveryStats() (models.HookDeliveryStats, error) {
+	return mp.statsManager.HookDeliveryStats()
+}
+
+// Metrics is implementation of the same method in the pool.Interface
+func (mp *MemPool) Metrics() (*models.JobMetrics, error) {
+	runs, err := mp.statsManager.JobRunMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := mp.statsManager.HookDeliveryStats()
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &models.JobMetrics{
+		Queues:  make([]*models.JobQueueMetrics, 0),
+		Running: make(map[string]int64),
+		Pools:   []*models.JobPoolStatsData{mp.poolStats()},
+		Runs:    runs,
+		Hooks:   hooks,
+	}
+
+	now := time.Now().Unix()
+	queues := make(map[string]*models.JobQueueMetrics)
+
+	mp.lock.Lock()
+	for _, j := range mp.queue {
+		q, ok := queues[j.Name]
+		if !ok {
+			jobName, queue := utils.ParseQueuedJobName(j.Name)
+			q = &models.JobQueueMetrics{JobName: jobName, Queue: queue}
+			queues[j.Name] = q
+			metrics.Queues = append(metrics.Queues, q)
+		}
+		q.Count++
+		// Latency of the oldest job in the queue
+		if latency := now - j.EnqueuedAt; latency > q.Latency {
+			q.Latency = latency
+		}
+	}
+	for _, j := range mp.running {
+		jobName, _ := utils.ParseQueuedJobName(j.Name)
+		metrics.Running[jobName]++
+	}
+	mp.lock.Unlock()
+
+	sort.Slice(metrics.Queues, func(i, j int) bool {
+		if metrics.Queues[i].JobName == metrics.Queues[j].JobName {
+			return metrics.Queues[i].Queue < metrics.Queues[j].Queue
+		}
+		return metrics.Queues[i].JobName < metrics.Queues[j].JobName
+	})
+
+	return metrics, nil
+}
+
+// Drain is implementation of the same method in the pool.Interface
+// The re-queued jobs are kept in the queue of this pool as no other nodes share it,
+// they're lost if the process exits.
+func (mp *MemPool) Drain(timeout time.Duration) (int, error) {
+	mp.lock.Lock()
+	if mp.draining {
+		mp.lock.Unlock()
+		return 0, errors.New("the pool is already draining")
+	}
+	mp.draining = true
+	mp.cond.Broadcast()
+	mp.lock.Unlock()
+
+	logger.Infof("Draining the memory worker pool, waiting %s for the in-flight jobs", timeout)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mp.workers.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		logger.Infof("All the in-flight jobs are completed, memory worker pool is drained")
+		return 0, nil
+	case <-timer.C:
+	}
+
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	requeued := make([]*work.Job, 0, len(mp.running))
+	for id, j := range mp.running {
+		mp.requeued.Store(id, true)
+		requeued = append(requeued, j)
+		if err := mp.statsManager.Update(id, "status", job.JobStatusPending); err != nil {
+			// Only logged
+			logger.Errorf("Failed to reset the status of the re-queued job %s: %s", id, err)
+		}
+		delete(mp.running, id)
+	}
+	mp.queue = append(requeued, mp.queue...)
+
+	logger.Infof("Memory worker pool is drained with %d unfinished jobs re-queued", len(requeued))
+
+	return len(requeued), nil
+}
+
+// IsDraining is implementation of the same method in the pool.Interface
+func (mp *MemPool) IsDraining() bool {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	return mp.draining
+}
+
+func (mp *MemPool) newRunner(j interface{}) *RedisJob {
+	runner := NewRedisJob(j, mp.context, mp.statsManager, mp.deDuplicator)
+	runner.requeued = mp.requeued
+
+	return runner
+}
+
+// work picks up the queued jobs and runs them until the pool is stopped.
+func (mp *MemPool) work() {
+	for {
+		mp.lock.Lock()
+		for len(mp.queue) == 0 && !mp.stopped && !mp.draining {
+			mp.cond.Wait()
+		}
+		if mp.stopped || mp.draining {
+			mp.lock.Unlock()
+			return
+		}
+
+		j := mp.queue[0]
+		mp.queue = mp.queue[1:]
+		mp.running[j.ID] = j
+		mp.lock.Unlock()
+
+		mp.run(j)
+
+		mp.lock.Lock()
+		delete(mp.running, j.ID)
+		mp.lock.Unlock()
+	}
+}
+
+// run the job and put it into the retry queue or dead queue if it's failed.
+func (mp *MemPool) run(j *work.Job) {
+	jobName, _ := utils.ParseQueuedJobName(j.Name)
+	handler, ok := mp.handlers[jobName]
+	if !ok {
+		mp.fail(j, fmt.Errorf("no handler registered for job %s", jobName), 1)
+		return
+	}
+
+	logger.Infof("Job incoming: %s:%s", j.Name, j.ID)
+
+	if err := handler.runner.Run(j); err != nil {
+		maxFails := handler.maxFails
+		if maxFails == 0 {
+			maxFails = defaultMaxFails
+		}
+
+		mp.fail(j, err, maxFails)
+	}
+}
+
+func (mp *MemPool) fail(j *work.Job, err error, maxFails uint) {
+	now := time.Now().Unix()
+	j.Fails++
+	j.LastErr = err.Error()
+	j.FailedAt = now
+
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	if j.Fails < int64(maxFails) {
+		mp.scheduled = append(mp.scheduled, &memScheduledJob{
+			runAt: now + retryBackoff(j.Fails),
+			job:   j,
+		})
+
+		return
+	}
+
+	mp.dead = append(mp.dead, &memDeadJob{
+		diedAt: now,
+		job:    j,
+	})
+}
+
+// enqueueDue moves the due scheduled jobs into the queue and creates the executions of the due periodic policies.
+func (mp *MemPool) enqueueDue(now time.Time) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	waiting := make([]*memScheduledJob, 0, len(mp.scheduled))
+	for _, sj := range mp.scheduled {
+		if sj.runAt <= now.Unix() {
+			mp.queue = append(mp.queue, sj.job)
+		} else {
+			waiting = append(waiting, sj)
+		}
+	}
+	mp.scheduled = waiting
+
+	for _, p := range mp.policies {
+		if p.nextRun.After(now) {
+			continue
+		}
+
+		mp.createExecution(p.policy, p.nextRun.Unix())
+		// Skip the missed runs
+		p.nextRun = p.schedule.Next(now)
+	}
+
+	if len(mp.queue) > 0 {
+		mp.cond.Broadcast()
+	}
+}
+
+// createExecution creates an execution (job) based on the periodic job template (policy).
+// The caller should hold the lock.
+func (mp *MemPool) createExecution(pl *period.PeriodicJobPolicy, runAt int64) {
+	executionID := utils.MakeIdentifier()
+	j := &work.Job{
+		Name:       utils.QueuedJobName(pl.JobName, pl.Queue),
+		ID:         executionID,
+		EnqueuedAt: time.Now().Unix(),
+		Args:       pl.JobParameters, // Pass parameters to scheduled job here
+	}
+
+	mp.statsManager.Save(models.JobStats{
+		Stats: &models.JobStatData{
+			JobID:         executionID,
+			JobName:       pl.JobName,
+			Queue:         utils.NormalizeQueueName(pl.Queue),
+			Status:        job.JobStatusPending,
+			JobKind:       job.JobKindScheduled,
+			EnqueueTime:   time.Now().Unix(),
+			UpdateTime:    time.Now().Unix(),
+			RefLink:       fmt.Sprintf("/api/v1/jobs/%s", executionID),
+			RunAt:         runAt,
+			UpstreamJobID: pl.PolicyID,
+		},
+	})
+
+	// Get web hook from the periodic job (policy)
+	if webHookURL, err := mp.statsManager.GetHook(pl.PolicyID); err == nil {
+		// Register hook for the execution
+		if err := mp.statsManager.RegisterHook(executionID, webHookURL, false); err != nil {
+			// Just logged
+			logger.Errorf("Failed to register web hook '%s' for periodic job (execution) '%s' with error: %s", webHookURL, executionID, err)
+		}
+	}
+
+	// Link the upstream job (policy) with the created execution
+	if err := mp.statsManager.AttachExecution(pl.PolicyID, executionID); err != nil {
+		// Just logged it
+		logger.Errorf("Link upstream job with executions failed: %s", err)
+	}
+
+	if err := mp.statsManager.Update(pl.PolicyID, "status", job.JobStatusScheduled); err != nil {
+		logger.Errorf("Failed to update status of periodic job %s: %s", pl.PolicyID, err)
+	}
+
+	mp.queue = append(mp.queue, j)
+
+	logger.Infof("Schedule job %s:%s for policy %s at %d", j.Name, j.ID, pl.PolicyID, runAt)
+}
+
+// A try best method to stop the executions of one periodic job
+func (mp *MemPool) stopExecutionsOfPeriodicPolicy(policyID string) error {
+	ids, err := mp.statsManager.GetExecutions(policyID)
+	if err != nil {
+		return err
+	}
+
+	multiErrs := []string{}
+	for _, id := range ids {
+		subJob, err := mp.statsManager.Retrieve(id)
+		if err != nil {
+			multiErrs = append(multiErrs, err.Error())
+			continue // going on
+		}
+
+		switch subJob.Stats.Status {
+		case job.JobStatusRunning:
+			// Send 'stop' ctl command to the running instance
+			if err := mp.statsManager.SendCommand(id, opm.CtlCommandStop, false); err != nil {
+				multiErrs = append(multiErrs, err.Error())
+				continue
+			}
+
+			logger.Debugf("Stop running job %s for periodic job policy %s", id, policyID)
+		case job.JobStatusPending:
+			if mp.removePendingJob(id) {
+				mp.statsManager.SetJobStatus(id, job.JobStatusStopped)
+				logger.Debugf("Delete pending job %s for periodic job policy %s", id, policyID)
+			}
+		default:
+		}
+	}
+
+	if len(multiErrs) > 0 {
+		return errors.New(strings.Join(multiErrs, "\n"))
+	}
+
+	return nil
+}
+
+// removePendingJob removes the job which is not running yet from the queue or the scheduled list.
+func (mp *MemPool) removePendingJob(jobID string) bool {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+
+	for i, j := range mp.queue {
+		if j.ID == jobID {
+			mp.queue = append(mp.queue[:i], mp.queue[i+1:]...)
+			mp.releaseUniqueSign(j)
+			return true
+		}
+	}
+
+	for i, sj := range mp.scheduled {
+		if sj.job.ID == jobID {
+			mp.scheduled = append(mp.scheduled[:i], mp.scheduled[i+1:]...)
+			mp.releaseUniqueSign(sj.job)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (mp *MemPool) removeDeadJob(jobID string) (*memDeadJob, error) {
+	mp.lock.Lock()
+	for i, dj := range mp.dead {
+		if dj.job.ID == jobID {
+			mp.dead = append(mp.dead[:i], mp.dead[i+1:]...)
+			mp.lock.Unlock()
+			return dj, nil
+		}
+	}
+	mp.lock.Unlock()
+
+	if _, err := mp.statsManager.Retrieve(jobID); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("job '%s' is not a retryable job", jobID)
+}
+
+// The unique sign is removed by the job wrapper after running,
+// remove it here for the jobs which will never run.
+func (mp *MemPool) releaseUniqueSign(j *work.Job) {
+	if !j.Unique {
+		return
+	}
+
+	if err := mp.deDuplicator.DelUniqueSign(j.Name, j.Args); err != nil {
+		logger.Errorf("delete job unique sign error: %s", err)
+	}
+}
+
+func (mp *MemPool) poolStats() *models.JobPoolStatsData {
+	status := workerPoolStatusHealthy
+	if mp.IsDraining() {
+		status = workerPoolStatusDraining
+	}
+
+	jobNames := make([]string, 0, len(mp.knownJobs))
+	for name := range mp.knownJobs {
+		jobNames = append(jobNames, name)
+	}
+	sort.Strings(jobNames)
+
+	return &models.JobPoolStatsData{
+		WorkerPoolID: mp.poolID,
+		StartedAt:    mp.startedAt,
+		// The pool is in the same process, always alive
+		HeartbeatAt: time.Now().Unix(),
+		JobNames:    jobNames,
+		Queues:      mp.queues,
+		Concurrency: mp.workerCount,
+		Status:      status,
+	}
+}
+
+// checkQueue checks if the queue is served by this pool as the jobs can not be handed over to other nodes.
+func (mp *MemPool) checkQueue(queue string) error {
+	queue = utils.NormalizeQueueName(queue)
+	for _, q := range mp.queues {
+		if q == queue {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("queue '%s' is not served by this node", queue)
+}
+
+func newMemJob(jobName string, params models.Parameters, isUnique bool) *work.Job {
+	return &work.Job{
+		Name:       jobName,
+		ID:         utils.MakeIdentifier(),
+		EnqueuedAt: time.Now().Unix(),
+		Args:       params,
+		Unique:     isUnique,
+	}
+}
+
+// retryBackoff returns the seconds to wait before retrying the failed job,
+// same with the backend worker pool.
+func retryBackoff(fails int64) int64 {
+	return (fails * fails * fails * fails) + 15 + (rand.Int63n(30) * (fails + 1))
+}
@@ -0,0 +1,442 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goharbor/harbor/src/jobservice/env"
+	"github.com/goharbor/harbor/src/jobservice/errs"
+	"github.com/goharbor/harbor/src/jobservice/job"
+	"github.com/goharbor/harbor/src/jobservice/models"
+)
+
+func TestMemPoolEnqueueJob(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t)
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+	stats, err := wp.Enqueue("fake_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, stats.Stats.JobID, job.JobStatusSuccess)
+
+	stats, err = wp.Enqueue("fake_unique_job", params, true, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, stats.Stats.JobID, job.JobStatusSuccess)
+
+	// The unique sign is removed after running
+	if _, err := wp.Enqueue("fake_unique_job", params, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	scheduled, err := wp.Schedule("fake_job", params, 1, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scheduled.Stats.RunAt == 0 || scheduled.Stats.JobKind != job.JobKindScheduled {
+		t.Fatalf("expect scheduled job with run time but got %+v", scheduled.Stats)
+	}
+	waitForMemJobStatus(t, wp, scheduled.Stats.JobID, job.JobStatusSuccess)
+
+	metrics, err := wp.Metrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Runs.Counts["fake_job"][job.JobStatusSuccess] != 2 {
+		t.Errorf("expect 2 successful runs of fake_job but got %v", metrics.Runs.Counts["fake_job"])
+	}
+
+	poolStats, err := wp.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(poolStats.Pools) != 1 || poolStats.Pools[0].Concurrency != 3 {
+		t.Errorf("expect 1 pool with 3 workers but got %+v", poolStats.Pools)
+	}
+}
+
+func TestMemPoolQueues(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t, "default", "scan")
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+	stats, err := wp.Enqueue("fake_unique_job", params, true, "scan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stats.JobName != "fake_unique_job" || stats.Stats.Queue != "scan" {
+		t.Fatalf("expect job fake_unique_job in queue scan but got %+v", stats.Stats)
+	}
+	waitForMemJobStatus(t, wp, stats.Stats.JobID, job.JobStatusSuccess)
+
+	if _, err := wp.Enqueue("fake_job", params, false, "replication"); err == nil {
+		t.Fatal("expect non nil error for the queue not served but got nil")
+	}
+
+	metrics, err := wp.Metrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Runs.Counts["fake_unique_job"][job.JobStatusSuccess] != 1 {
+		t.Errorf("expect 1 successful run of fake_unique_job but got %v", metrics.Runs.Counts["fake_unique_job"])
+	}
+
+	poolStats, err := wp.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queues := poolStats.Pools[0].Queues; len(queues) != 2 || queues[1] != "scan" {
+		t.Errorf("expect pool serving queues [default scan] but got %v", queues)
+	}
+}
+
+func TestMemPoolEnqueueBatch(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t)
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+	jobs := []*models.JobData{
+		{Name: "fake_job", Parameters: params},
+		{Name: "fake_job", Parameters: params},
+		{Name: "fake_unique_job", Parameters: params, Metadata: &models.JobMetadata{IsUnique: true}},
+	}
+	batch, err := wp.EnqueueBatch(jobs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Stats.Total != 3 || len(batch.Stats.JobIDs) != 3 {
+		t.Fatalf("expect batch with 3 jobs but got %+v", batch.Stats)
+	}
+
+	for _, jobID := range batch.Stats.JobIDs {
+		waitForMemJobStatus(t, wp, jobID, job.JobStatusSuccess)
+	}
+
+	res, err := wp.GetBatchStats(batch.Stats.BatchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusSuccess || res.Stats.Counts[job.JobStatusSuccess] != 3 {
+		t.Fatalf("expect successful batch with 3 jobs but got %+v", res.Stats)
+	}
+
+	// Nothing is enqueued if any job of the batch is duplicated
+	if _, err := wp.Enqueue("fake_long_run_job", params, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	_, err = wp.EnqueueBatch([]*models.JobData{
+		{Name: "fake_unique_job", Parameters: params, Metadata: &models.JobMetadata{IsUnique: true}},
+		{Name: "fake_long_run_job", Parameters: params, Metadata: &models.JobMetadata{IsUnique: true}},
+	}, "")
+	if !errs.IsConflictError(err) {
+		t.Fatalf("expect conflict error but got %v", err)
+	}
+
+	// The unique sign of the first job is released
+	if err := wp.deDuplicator.Unique("fake_unique_job", params); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemPoolStopAndCancelJob(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t)
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+
+	// Stop generic job
+	genericJob, err := wp.Enqueue("fake_long_run_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, genericJob.Stats.JobID, job.JobStatusRunning)
+	if err := wp.StopJob(genericJob.Stats.JobID); err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, genericJob.Stats.JobID, job.JobStatusStopped)
+
+	// Stop scheduled job
+	scheduledJob, err := wp.Schedule("fake_long_run_job", params, 120, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wp.StopJob(scheduledJob.Stats.JobID); err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, scheduledJob.Stats.JobID, job.JobStatusStopped)
+
+	// Cancel generic job and retry it from the dead queue
+	cancelledJob, err := wp.Enqueue("fake_long_run_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, cancelledJob.Stats.JobID, job.JobStatusRunning)
+	if err := wp.CancelJob(cancelledJob.Stats.JobID); err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, cancelledJob.Stats.JobID, job.JobStatusCancelled)
+
+	if err := wp.RetryJob(cancelledJob.Stats.JobID); err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, cancelledJob.Stats.JobID, job.JobStatusRunning)
+}
+
+func TestMemPoolDeadJobs(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t)
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+	failedJob, err := wp.Enqueue("fake_failed_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, failedJob.Stats.JobID, job.JobStatusError)
+	waitForMemDeadJobs(t, wp, 1)
+
+	deadJobs, _, err := wp.DeadJobs(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deadJobs[0].JobID != failedJob.Stats.JobID || deadJobs[0].LastError != "fake job failed" {
+		t.Errorf("expect dead job %s failed with 'fake job failed' but got %+v", failedJob.Stats.JobID, deadJobs[0])
+	}
+
+	// Retry and die again
+	if err := wp.RetryJob(failedJob.Stats.JobID); err != nil {
+		t.Fatal(err)
+	}
+	waitForMemDeadJobs(t, wp, 1)
+
+	if err := wp.DeleteDeadJob(failedJob.Stats.JobID, 0); err != nil {
+		t.Fatal(err)
+	}
+	waitForMemDeadJobs(t, wp, 0)
+
+	if err := wp.RetryJob(failedJob.Stats.JobID); err == nil {
+		t.Errorf("expect non nil error when retrying the purged job but got nil")
+	}
+	if err := wp.DeleteDeadJob("unknown_job_id", 0); !errs.IsObjectNotFoundError(err) {
+		t.Errorf("expect object not found error but got %v", err)
+	}
+}
+
+func TestMemPoolPeriodicJob(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t)
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+	periodicJob, err := wp.PeriodicallyEnqueue("fake_job", params, "* * * * * *", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := wp.PeriodicallyEnqueue("fake_job", params, "* * * * * *", ""); !errs.IsConflictError(err) {
+		t.Errorf("expect conflict error for duplicated periodic job but got %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats, err := wp.GetJobStats(periodicJob.Stats.JobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats.Stats.Executions) > 0 {
+			execution, err := wp.GetJobStats(stats.Stats.Executions[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if execution.Stats.UpstreamJobID != periodicJob.Stats.JobID {
+				t.Errorf("expect upstream job %s but got %s", periodicJob.Stats.JobID, execution.Stats.UpstreamJobID)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expect executions of periodic job but got none")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := wp.StopJob(periodicJob.Stats.JobID); err != nil {
+		t.Fatal(err)
+	}
+	if err := wp.StopJob(periodicJob.Stats.JobID); err == nil {
+		t.Errorf("expect non nil error when stopping the removed periodic job but got nil")
+	}
+}
+
+func TestMemPoolDrain(t *testing.T) {
+	wp, envCtx, cancel := createMemWorkerPool(t)
+	defer func() {
+		cancel()
+		envCtx.WG.Wait()
+	}()
+
+	params := map[string]interface{}{"name": "testing:v1"}
+	longRunJob, err := wp.Enqueue("fake_long_run_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForMemJobStatus(t, wp, longRunJob.Stats.JobID, job.JobStatusRunning)
+
+	count, err := wp.Drain(1 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expect 1 job re-queued but got %d", count)
+	}
+	if _, err := wp.Drain(1 * time.Second); err == nil {
+		t.Errorf("expect non nil error when draining twice but got nil")
+	}
+
+	stats, err := wp.GetJobStats(longRunJob.Stats.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stats.Status != job.JobStatusPending {
+		t.Errorf("expect re-queued job pending but got %s", stats.Stats.Status)
+	}
+
+	// No more jobs are picked up
+	newJob, err := wp.Enqueue("fake_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-time.After(500 * time.Millisecond)
+	if stats, err := wp.GetJobStats(newJob.Stats.JobID); err != nil || stats.Stats.Status != job.JobStatusPending {
+		t.Errorf("expect new job pending but got %+v with error %v", stats.Stats, err)
+	}
+
+	poolStats, err := wp.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poolStats.Pools[0].Status != workerPoolStatusDraining {
+		t.Errorf("expect pool status %s but got %s", workerPoolStatusDraining, poolStats.Pools[0].Status)
+	}
+
+	metrics, err := wp.Metrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics.Queues) != 2 || len(metrics.Running) != 0 {
+		t.Errorf("expect 2 queued and no running jobs but got %+v and %v", metrics.Queues, metrics.Running)
+	}
+}
+
+func createMemWorkerPool(t *testing.T, queues ...string) (*MemPool, *env.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	envCtx := &env.Context{
+		SystemContext: ctx,
+		WG:            new(sync.WaitGroup),
+		ErrorChan:     make(chan error, 1),
+		JobContext:    newContext(ctx),
+	}
+
+	wp := NewMemPool(envCtx, 3, queues...)
+	if err := wp.RegisterJobs(map[string]interface{}{
+		"fake_job":          (*fakeJob)(nil),
+		"fake_unique_job":   (*fakeUniqueJob)(nil),
+		"fake_long_run_job": (*fakeRunnableJob)(nil),
+		"fake_failed_job":   (*fakeFailedJob)(nil),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wp.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return wp, envCtx, cancel
+}
+
+func waitForMemJobStatus(t *testing.T, wp *MemPool, jobID string, status string) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats, err := wp.GetJobStats(jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Stats.Status == status {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expect status of job %s to be %s but got %s", jobID, status, stats.Stats.Status)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func waitForMemDeadJobs(t *testing.T, wp *MemPool, count int64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, total, err := wp.DeadJobs(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total == count {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expect %d dead jobs but got %d", count, total)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+type fakeFailedJob struct{}
+
+func (j *fakeFailedJob) MaxFails() uint {
+	return 1
+}
+
+func (j *fakeFailedJob) ShouldRetry() bool {
+	return true
+}
+
+func (j *fakeFailedJob) Validate(params map[string]interface{}) error {
+	return nil
+}
+
+func (j *fakeFailedJob) Run(ctx env.JobContext, params map[string]interface{}) error {
+	return errors.New("fake job failed")
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/goharbor/harbor/src/jobservice/job/impl"
@@ -38,6 +39,8 @@ type RedisJob struct {
 	context      *env.Context        // context
 	statsManager opm.JobStatsManager // job stats manager
 	deDuplicator DeDuplicator        // handle unique job
+	// IDs of the jobs re-queued by draining the pool, their results are abandoned
+	requeued *sync.Map
 }
 
 // NewRedisJob is constructor of RedisJob
@@ -58,6 +61,9 @@ func (rj *RedisJob) Run(j *work.Job) error {
 		runningJob         job.Interface
 		err                error
 		execContext        env.JobContext
+		startAt            time.Time
+		// The name of the backend job carries the queue, keep it to retry the job in the same queue
+		jobName, _ = utils.ParseQueuedJobName(j.Name)
 	)
 
 	defer func() {
@@ -69,8 +75,14 @@ func (rj *RedisJob) Run(j *work.Job) error {
 		// log error
 		logger.Errorf("Job '%s:%s' exit with error: %s\n", j.Name, j.ID, err)
 
-		if buildContextFailed || rj.shouldDisableRetry(runningJob, j, cancelled) {
+		disableRetry := buildContextFailed || rj.shouldDisableRetry(runningJob, j, cancelled)
+		if disableRetry {
 			j.Fails = 10000000000 // Make it big enough to avoid retrying
+		}
+
+		// Mark the die time for the job which is going to be put into the dead queue,
+		// then it can be retried later.
+		if disableRetry || rj.isExhausted(runningJob, j) {
 			now := time.Now().Unix()
 			go func() {
 				timer := time.NewTimer(2 * time.Second) // make sure the failed job is already put into the dead queue
@@ -93,7 +105,7 @@ func (rj *RedisJob) Run(j *work.Job) error {
 			logger.Errorf("Runtime error happened when executing job %s:%s: %s", j.Name, j.ID, buf[0:size])
 
 			// record runtime error status
-			rj.jobFailed(j.ID)
+			rj.jobFailed(j.ID, rj.willRetry(runningJob, j, buildContextFailed))
 		}
 	}()
 
@@ -118,6 +130,11 @@ func (rj *RedisJob) Run(j *work.Job) error {
 
 	if j.Unique {
 		defer func() {
+			// Keep the sign for the re-queued job
+			if rj.isRequeued(j.ID) {
+				return
+			}
+
 			if err := rj.deDuplicator.DelUniqueSign(j.Name, j.Args); err != nil {
 				logger.Errorf("delete job unique sign error: %s", err)
 			}
@@ -126,38 +143,60 @@ func (rj *RedisJob) Run(j *work.Job) error {
 
 	// Start to run
 	rj.jobRunning(j.ID)
+	startAt = time.Now()
 
 	// Inject data
 	err = runningJob.Run(execContext, j.Args)
 
+	// The job has been handed over to the other nodes
+	if rj.isRequeued(j.ID) {
+		logger.Warningf("Job '%s:%s' has been re-queued by draining, abandon the result: %v", j.Name, j.ID, err)
+		err = nil
+		return nil
+	}
+
 	// update the proper status
 	if err == nil {
 		rj.jobSucceed(j.ID)
+		rj.statsManager.Observe(jobName, job.JobStatusSuccess, time.Since(startAt))
 		return nil
 	}
 
 	if errs.IsJobStoppedError(err) {
 		rj.jobStopped(j.ID)
+		rj.statsManager.Observe(jobName, job.JobStatusStopped, time.Since(startAt))
 		return nil // no need to put it into the dead queue for resume
 	}
 
 	if errs.IsJobCancelledError(err) {
 		rj.jobCancelled(j.ID)
+		rj.statsManager.Observe(jobName, job.JobStatusCancelled, time.Since(startAt))
 		cancelled = true
 		return err // need to resume
 	}
 
+	rj.statsManager.Observe(jobName, job.JobStatusError, time.Since(startAt))
+
 FAILED:
-	rj.jobFailed(j.ID)
+	rj.jobFailed(j.ID, rj.willRetry(runningJob, j, buildContextFailed))
 	return err
 }
 
+func (rj *RedisJob) isRequeued(jobID string) bool {
+	if rj.requeued == nil {
+		return false
+	}
+
+	_, ok := rj.requeued.Load(jobID)
+	return ok
+}
+
 func (rj *RedisJob) jobRunning(jobID string) {
 	rj.statsManager.SetJobStatus(jobID, job.JobStatusRunning)
 }
 
-func (rj *RedisJob) jobFailed(jobID string) {
-	rj.statsManager.SetJobStatus(jobID, job.JobStatusError)
+func (rj *RedisJob) jobFailed(jobID string, retrying bool) {
+	rj.statsManager.SetJobFailed(jobID, retrying)
 }
 
 func (rj *RedisJob) jobStopped(jobID string) {
@@ -173,10 +212,12 @@ func (rj *RedisJob) jobSucceed(jobID string) {
 }
 
 func (rj *RedisJob) buildContext(j *work.Job) (env.JobContext, error) {
+	jobName, _ := utils.ParseQueuedJobName(j.Name)
+
 	// Build job execution context
 	jData := env.JobData{
 		ID:        j.ID,
-		Name:      j.Name,
+		Name:      jobName,
 		Args:      j.Args,
 		ExtraData: make(map[string]interface{}),
 	}
@@ -193,6 +234,11 @@ func (rj *RedisJob) buildContext(j *work.Job) (env.JobContext, error) {
 
 	jData.ExtraData["opCommandFunc"] = checkOPCmdFuncFactory(j.ID)
 
+	// Kind of the job is attached to the job logs
+	if jobStats, err := rj.statsManager.Retrieve(j.ID); err == nil {
+		jData.ExtraData["jobKind"] = jobStats.Stats.JobKind
+	}
+
 	checkInFuncFactory := func(jobID string) job.CheckInFunc {
 		return func(message string) {
 			rj.statsManager.CheckIn(jobID, message)
@@ -209,11 +255,11 @@ func (rj *RedisJob) buildContext(j *work.Job) (env.JobContext, error) {
 				return models.JobStats{}, errors.New("no launch job func provided")
 			}
 
-			jobName := ""
+			subJobName := ""
 			if jobReq.Job != nil {
-				jobName = jobReq.Job.Name
+				subJobName = jobReq.Job.Name
 			}
-			if j.Name == jobName {
+			if jobName == subJobName {
 				return models.JobStats{}, errors.New("infinite job creating loop may exist")
 			}
 
@@ -247,6 +293,26 @@ func (rj *RedisJob) buildContext(j *work.Job) (env.JobContext, error) {
 	return rj.context.JobContext.Build(jData)
 }
 
+// willRetry checks if the failed job will be retried by the backend pool,
+// the cancelled jobs are never retried so they're not covered here.
+func (rj *RedisJob) willRetry(j job.Interface, wj *work.Job, buildContextFailed bool) bool {
+	if buildContextFailed || j == nil {
+		return false
+	}
+
+	return !rj.shouldDisableRetry(j, wj, false) && !rj.isExhausted(j, wj)
+}
+
+// isExhausted checks if the job has used up the allowed failures.
+func (rj *RedisJob) isExhausted(j job.Interface, wj *work.Job) bool {
+	maxFails := j.MaxFails()
+	if maxFails == 0 {
+		maxFails = 4 // Consistent with backend worker pool
+	}
+
+	return wj.Fails+1 >= int64(maxFails) // as the fail is not returned to backend pool yet
+}
+
 func (rj *RedisJob) shouldDisableRetry(j job.Interface, wj *work.Job, cancelled bool) bool {
 	maxFails := j.MaxFails()
 	if maxFails == 0 {
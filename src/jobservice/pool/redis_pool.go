@@ -18,8 +18,11 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gocraft/work"
@@ -38,15 +41,43 @@ var (
 )
 
 const (
-	workerPoolStatusHealthy = "Healthy"
-	workerPoolStatusDead    = "Dead"
+	workerPoolStatusHealthy  = "Healthy"
+	workerPoolStatusDead     = "Dead"
+	workerPoolStatusDraining = "Draining"
 
 	// Copy from period.enqueuer
 	periodicEnqueuerHorizon = 4 * time.Minute
 
 	pingRedisMaxTimes = 10
+
+	// Page size of the dead jobs list, fixed by the backend pool
+	deadJobsPageSize = 20
+
+	// Keep the draining mark a while after the deadline in case the node exits slowly
+	drainingMarkExtraTTL = 60
 )
 
+// Move the in progress jobs of the worker pool back to the job queue and release the locks.
+// The moved jobs are returned.
+//
+// KEYS[1] = the in progress queue of the pool
+// KEYS[2] = the job queue
+// KEYS[3] = the lock of the job queue
+// KEYS[4] = the lock info of the job queue
+// ARGV[1] = the worker pool ID
+var redisLuaRequeueInProgressJobs = `
+local moved = {}
+while true do
+  local res = redis.call('rpoplpush', KEYS[1], KEYS[2])
+  if not res then
+    break
+  end
+  redis.call('decr', KEYS[3])
+  redis.call('hincrby', KEYS[4], ARGV[1], -1)
+  table.insert(moved, res)
+end
+return moved`
+
 // GoCraftWorkPool is the pool implementation based on gocraft/work powered by redis.
 type GoCraftWorkPool struct {
 	namespace     string
@@ -61,10 +92,19 @@ type GoCraftWorkPool struct {
 	messageServer *MessageServer
 	deDuplicator  DeDuplicator
 
+	// 1 if the pool is draining
+	draining int32
+	// 1 if the underlying pool is stopped
+	poolStopped int32
+	// IDs of the jobs re-queued by draining
+	requeued *sync.Map
+
 	// no need to sync as write once and then only read
 	// key is name of known job
 	// value is the type of known job
 	knownJobs map[string]interface{}
+	// the queues served by this node
+	queues []string
 }
 
 // RedisPoolContext ...
@@ -72,7 +112,12 @@ type GoCraftWorkPool struct {
 type RedisPoolContext struct{}
 
 // NewGoCraftWorkPool is constructor of goCraftWorkPool.
-func NewGoCraftWorkPool(ctx *env.Context, namespace string, workerCount uint, redisPool *redis.Pool) *GoCraftWorkPool {
+// The pool only pulls the jobs of the specified queues, the default queue is served if no queue is specified.
+func NewGoCraftWorkPool(ctx *env.Context, namespace string, workerCount uint, redisPool *redis.Pool, queues ...string) *GoCraftWorkPool {
+	if len(queues) == 0 {
+		queues = []string{utils.DefaultQueue}
+	}
+
 	pool := work.NewWorkerPool(RedisPoolContext{}, workerCount, namespace, redisPool)
 	enqueuer := work.NewEnqueuer(namespace, redisPool)
 	client := work.NewClient(namespace, redisPool)
@@ -94,6 +139,8 @@ func NewGoCraftWorkPool(ctx *env.Context, namespace string, workerCount uint, re
 		knownJobs:     make(map[string]interface{}),
 		messageServer: msgServer,
 		deDuplicator:  deDepulicator,
+		requeued:      new(sync.Map),
+		queues:        queues,
 	}
 }
 
@@ -208,7 +255,7 @@ func (gcwp *GoCraftWorkPool) Start() error {
 		case <-done:
 		}
 
-		gcwp.pool.Stop()
+		gcwp.stopPool()
 	}()
 
 	return nil
@@ -240,19 +287,23 @@ func (gcwp *GoCraftWorkPool) RegisterJob(name string, j interface{}) error {
 	}
 
 	redisJob := NewRedisJob(j, gcwp.context, gcwp.statsManager, gcwp.deDuplicator)
+	redisJob.requeued = gcwp.requeued
 
 	// Get more info from j
 	theJ := Wrap(j)
 
-	gcwp.pool.JobWithOptions(name,
-		work.JobOptions{MaxFails: theJ.MaxFails()},
-		func(job *work.Job) error {
-			return redisJob.Run(job)
-		}, // Use generic handler to handle as we do not accept context with this way.
-	)
+	// Each queue of the job is a separate backend job queue
+	for _, queue := range gcwp.queues {
+		gcwp.pool.JobWithOptions(utils.QueuedJobName(name, queue),
+			work.JobOptions{MaxFails: theJ.MaxFails()},
+			func(job *work.Job) error {
+				return redisJob.Run(job)
+			}, // Use generic handler to handle as we do not accept context with this way.
+		)
+	}
 	gcwp.knownJobs[name] = j // keep the name of registered jobs as known jobs for future validation
 
-	logger.Infof("Register job %s with name %s", reflect.TypeOf(j).String(), name)
+	logger.Infof("Register job %s with name %s in queues %v", reflect.TypeOf(j).String(), name, gcwp.queues)
 
 	return nil
 }
@@ -273,10 +324,11 @@ func (gcwp *GoCraftWorkPool) RegisterJobs(jobs map[string]interface{}) error {
 }
 
 // Enqueue job
-func (gcwp *GoCraftWorkPool) Enqueue(jobName string, params models.Parameters, isUnique bool) (models.JobStats, error) {
+func (gcwp *GoCraftWorkPool) Enqueue(jobName string, params models.Parameters, isUnique bool, queue string) (models.JobStats, error) {
 	var (
-		j   *work.Job
-		err error
+		j          *work.Job
+		err        error
+		queuedName = utils.QueuedJobName(jobName, queue)
 	)
 
 	// As the job is declared to be unique,
@@ -284,16 +336,16 @@ func (gcwp *GoCraftWorkPool) Enqueue(jobName string, params models.Parameters, i
 	// if no duplicated job existing (including the running jobs),
 	// set the unique flag.
 	if isUnique {
-		if err = gcwp.deDuplicator.Unique(jobName, params); err != nil {
+		if err = gcwp.deDuplicator.Unique(queuedName, params); err != nil {
 			return models.JobStats{}, err
 		}
 
-		if j, err = gcwp.enqueuer.EnqueueUnique(jobName, params); err != nil {
+		if j, err = gcwp.enqueuer.EnqueueUnique(queuedName, params); err != nil {
 			return models.JobStats{}, err
 		}
 	} else {
 		// Enqueue job
-		if j, err = gcwp.enqueuer.Enqueue(jobName, params); err != nil {
+		if j, err = gcwp.enqueuer.Enqueue(queuedName, params); err != nil {
 			return models.JobStats{}, err
 		}
 	}
@@ -311,11 +363,51 @@ func (gcwp *GoCraftWorkPool) Enqueue(jobName string, params models.Parameters, i
 	return res, nil
 }
 
+// EnqueueBatch enqueues the jobs of the batch atomically
+func (gcwp *GoCraftWorkPool) EnqueueBatch(jobs []*models.JobData, hookURL string) (models.BatchStats, error) {
+	if len(jobs) == 0 {
+		return models.BatchStats{}, errors.New("empty batch")
+	}
+
+	batch, works, stats := newBatch(jobs)
+	if err := uniqueBatch(gcwp.deDuplicator, works); err != nil {
+		return models.BatchStats{}, err
+	}
+
+	// The stats must be ready before the jobs are picked up to count the status changes into the batch
+	if err := gcwp.statsManager.SaveBatch(batch, stats, batchHooks(jobs, batch), hookURL); err != nil {
+		releaseBatchUniqueSigns(gcwp.deDuplicator, works)
+		return models.BatchStats{}, err
+	}
+
+	if err := gcwp.pushBatch(works); err != nil {
+		// Roll back
+		if err := gcwp.statsManager.DeleteBatch(batch.Stats.BatchID); err != nil {
+			logger.Errorf("Failed to delete stats of the batch %s: %s", batch.Stats.BatchID, err)
+		}
+		releaseBatchUniqueSigns(gcwp.deDuplicator, works)
+
+		return models.BatchStats{}, err
+	}
+
+	return batch, nil
+}
+
+// GetBatchStats return the aggregated stats of the specified batch.
+func (gcwp *GoCraftWorkPool) GetBatchStats(batchID string) (models.BatchStats, error) {
+	if utils.IsEmptyStr(batchID) {
+		return models.BatchStats{}, errors.New("empty batch ID")
+	}
+
+	return gcwp.statsManager.RetrieveBatch(batchID)
+}
+
 // Schedule job
-func (gcwp *GoCraftWorkPool) Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool) (models.JobStats, error) {
+func (gcwp *GoCraftWorkPool) Schedule(jobName string, params models.Parameters, runAfterSeconds uint64, isUnique bool, queue string) (models.JobStats, error) {
 	var (
-		j   *work.ScheduledJob
-		err error
+		j          *work.ScheduledJob
+		err        error
+		queuedName = utils.QueuedJobName(jobName, queue)
 	)
 
 	// As the job is declared to be unique,
@@ -323,16 +415,16 @@ func (gcwp *GoCraftWorkPool) Schedule(jobName string, params models.Parameters,
 	// if no duplicated job existing (including the running jobs),
 	// set the unique flag.
 	if isUnique {
-		if err = gcwp.deDuplicator.Unique(jobName, params); err != nil {
+		if err = gcwp.deDuplicator.Unique(queuedName, params); err != nil {
 			return models.JobStats{}, err
 		}
 
-		if j, err = gcwp.enqueuer.EnqueueUniqueIn(jobName, int64(runAfterSeconds), params); err != nil {
+		if j, err = gcwp.enqueuer.EnqueueUniqueIn(queuedName, int64(runAfterSeconds), params); err != nil {
 			return models.JobStats{}, err
 		}
 	} else {
 		// Enqueue job in
-		if j, err = gcwp.enqueuer.EnqueueIn(jobName, int64(runAfterSeconds), params); err != nil {
+		if j, err = gcwp.enqueuer.EnqueueIn(queuedName, int64(runAfterSeconds), params); err != nil {
 			return models.JobStats{}, err
 		}
 	}
@@ -353,8 +445,8 @@ func (gcwp *GoCraftWorkPool) Schedule(jobName string, params models.Parameters,
 }
 
 // PeriodicallyEnqueue job
-func (gcwp *GoCraftWorkPool) PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string) (models.JobStats, error) {
-	id, nextRun, err := gcwp.scheduler.Schedule(jobName, params, cronSetting)
+func (gcwp *GoCraftWorkPool) PeriodicallyEnqueue(jobName string, params models.Parameters, cronSetting string, queue string) (models.JobStats, error) {
+	id, nextRun, err := gcwp.scheduler.Schedule(jobName, params, cronSetting, queue)
 	if err != nil {
 		return models.JobStats{}, err
 	}
@@ -363,6 +455,7 @@ func (gcwp *GoCraftWorkPool) PeriodicallyEnqueue(jobName string, params models.P
 		Stats: &models.JobStatData{
 			JobID:                id,
 			JobName:              jobName,
+			Queue:                utils.NormalizeQueueName(queue),
 			Status:               job.JobStatusPending,
 			JobKind:              job.JobKindPeriodic,
 			CronSpec:             cronSetting,
@@ -406,12 +499,16 @@ func (gcwp *GoCraftWorkPool) Stats() (models.JobPoolStats, error) {
 		wPoolStatus := workerPoolStatusHealthy
 		if time.Unix(hb.HeartbeatAt, 0).Add(workerPoolDeadTime).Before(time.Now()) {
 			wPoolStatus = workerPoolStatusDead
+		} else if gcwp.isDrainingPool(hb.WorkerPoolID) {
+			wPoolStatus = workerPoolStatusDraining
 		}
+		jobNames, queues := utils.SplitQueuedJobNames(hb.JobNames)
 		stat := &models.JobPoolStatsData{
 			WorkerPoolID: hb.WorkerPoolID,
 			StartedAt:    hb.StartedAt,
 			HeartbeatAt:  hb.HeartbeatAt,
-			JobNames:     hb.JobNames,
+			JobNames:     jobNames,
+			Queues:       queues,
 			Concurrency:  hb.Concurrency,
 			Status:       wPoolStatus,
 		}
@@ -530,16 +627,89 @@ func (gcwp *GoCraftWorkPool) RetryJob(jobID string) error {
 		return errors.New("empty job ID")
 	}
 
-	theJob, err := gcwp.statsManager.Retrieve(jobID)
+	dieAt, err := gcwp.getDieAt(jobID)
 	if err != nil {
 		return err
 	}
 
-	if theJob.Stats.DieAt == 0 {
-		return fmt.Errorf("job '%s' is not a retryable job", jobID)
+	return gcwp.client.RetryDeadJob(dieAt, jobID)
+}
+
+// DeadJobs returns the jobs in the dead queue
+func (gcwp *GoCraftWorkPool) DeadJobs(page uint) ([]*models.DeadJobData, int64, error) {
+	deadJobs, total, err := gcwp.client.DeadJobs(page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make([]*models.DeadJobData, 0, len(deadJobs))
+	for _, dj := range deadJobs {
+		if dj.Job == nil {
+			continue
+		}
+
+		jobName, _ := utils.ParseQueuedJobName(dj.Name)
+		jobs = append(jobs, &models.DeadJobData{
+			JobID:     dj.ID,
+			JobName:   jobName,
+			Fails:     dj.Fails,
+			LastError: dj.LastErr,
+			FailedAt:  dj.FailedAt,
+			DieAt:     dj.DiedAt,
+		})
+	}
+
+	return jobs, total, nil
+}
+
+// RetryDeadJob retries the job in the dead queue
+func (gcwp *GoCraftWorkPool) RetryDeadJob(jobID string, diedAt int64) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	return gcwp.client.RetryDeadJob(diedAt, jobID)
+}
+
+// DeleteDeadJob removes the job from the dead queue
+func (gcwp *GoCraftWorkPool) DeleteDeadJob(jobID string, diedAt int64) error {
+	if utils.IsEmptyStr(jobID) {
+		return errors.New("empty job ID")
+	}
+
+	return gcwp.client.DeleteDeadJob(diedAt, jobID)
+}
+
+// Get the time when the job was put into the dead queue.
+// Try the job stats first, and then search the dead queue if the time is not marked.
+func (gcwp *GoCraftWorkPool) getDieAt(jobID string) (int64, error) {
+	theJob, statsErr := gcwp.statsManager.Retrieve(jobID)
+	if statsErr == nil && theJob.Stats.DieAt > 0 {
+		return theJob.Stats.DieAt, nil
+	}
+
+	for page := uint(1); ; page++ {
+		deadJobs, total, err := gcwp.client.DeadJobs(page)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, dj := range deadJobs {
+			if dj.Job != nil && dj.ID == jobID {
+				return dj.DiedAt, nil
+			}
+		}
+
+		if len(deadJobs) == 0 || int64(page)*deadJobsPageSize >= total {
+			break
+		}
+	}
+
+	if statsErr != nil {
+		return 0, statsErr
 	}
 
-	return gcwp.client.RetryDeadJob(theJob.Stats.DieAt, jobID)
+	return 0, fmt.Errorf("job '%s' is not a retryable job", jobID)
 }
 
 // IsKnownJob ...
@@ -572,6 +742,266 @@ func (gcwp *GoCraftWorkPool) RegisterHook(jobID string, hookURL string) error {
 	return gcwp.statsManager.RegisterHook(jobID, hookURL, false)
 }
 
+// HookEvents is implementation of the same method in the pool.Interface
+func (gcwp *GoCraftWorkPool) HookEvents(jobID string) (*models.HookEventList, error) {
+	if utils.IsEmptyStr(jobID) {
+		return nil, errors.New("empty job ID")
+	}
+
+	// Make sure the job is existing
+	if _, err := gcwp.statsManager.Retrieve(jobID); err != nil {
+		return nil, err
+	}
+
+	return gcwp.statsManager.HookEvents(jobID)
+}
+
+// HookDeliveryStats is implementation of the same method in the pool.Interface
+func (gcwp *GoCraftWorkPool) HookDeliveryStats() (models.HookDeliveryStats, error) {
+	return gcwp.statsManager.HookDeliveryStats()
+}
+
+// Metrics is implementation of the same method in the pool.Interface
+func (gcwp *GoCraftWorkPool) Metrics() (*models.JobMetrics, error) {
+	queues, err := gcwp.client.Queues()
+	if err != nil {
+		return nil, err
+	}
+
+	observations, err := gcwp.client.WorkerObservations()
+	if err != nil {
+		return nil, err
+	}
+
+	hbs, err := gcwp.client.WorkerPoolHeartbeats()
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := gcwp.statsManager.JobRunMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := gcwp.statsManager.HookDeliveryStats()
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &models.JobMetrics{
+		Queues:  make([]*models.JobQueueMetrics, 0, len(queues)),
+		Running: make(map[string]int64),
+		Pools:   make([]*models.JobPoolStatsData, 0, len(hbs)),
+		Runs:    runs,
+		Hooks:   hooks,
+	}
+
+	for _, q := range queues {
+		jobName, queue := utils.ParseQueuedJobName(q.JobName)
+		metrics.Queues = append(metrics.Queues, &models.JobQueueMetrics{
+			JobName: jobName,
+			Queue:   queue,
+			Count:   q.Count,
+			Latency: q.Latency,
+		})
+	}
+
+	for _, ob := range observations {
+		if ob.IsBusy {
+			jobName, _ := utils.ParseQueuedJobName(ob.JobName)
+			metrics.Running[jobName]++
+		}
+	}
+
+	for _, hb := range hbs {
+		if hb.HeartbeatAt == 0 {
+			continue // invalid ones
+		}
+
+		jobNames, queues := utils.SplitQueuedJobNames(hb.JobNames)
+		metrics.Pools = append(metrics.Pools, &models.JobPoolStatsData{
+			WorkerPoolID: hb.WorkerPoolID,
+			StartedAt:    hb.StartedAt,
+			HeartbeatAt:  hb.HeartbeatAt,
+			JobNames:     jobNames,
+			Queues:       queues,
+			Concurrency:  hb.Concurrency,
+		})
+	}
+
+	return metrics, nil
+}
+
+// Drain is implementation of the same method in the pool.Interface
+func (gcwp *GoCraftWorkPool) Drain(timeout time.Duration) (int, error) {
+	if !atomic.CompareAndSwapInt32(&gcwp.draining, 0, 1) {
+		return 0, errors.New("the pool is already draining")
+	}
+
+	// Find the pool to mark it as draining and re-queue its in progress jobs later
+	poolID, err := gcwp.workerPoolID()
+	if err != nil {
+		logger.Errorf("Failed to find the worker pool of this node: %s", err)
+	} else {
+		if err := gcwp.markDraining(poolID, timeout); err != nil {
+			// Only logged
+			logger.Errorf("Failed to mark the worker pool %s as draining: %s", poolID, err)
+		}
+	}
+
+	logger.Infof("Draining the worker pool %s, waiting %s for the in-flight jobs", poolID, timeout)
+
+	// Stop pulling new jobs and wait for the in-flight jobs
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gcwp.stopPool()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		logger.Infof("All the in-flight jobs are completed, worker pool %s is drained", poolID)
+		return 0, nil
+	case <-timer.C:
+	}
+
+	if len(poolID) == 0 {
+		return 0, errors.New("unfinished jobs can not be re-queued as the worker pool is unknown")
+	}
+
+	count, err := gcwp.requeueInProgressJobs(poolID)
+	if err != nil {
+		return count, err
+	}
+
+	logger.Infof("Worker pool %s is drained with %d unfinished jobs re-queued", poolID, count)
+
+	return count, nil
+}
+
+// IsDraining is implementation of the same method in the pool.Interface
+func (gcwp *GoCraftWorkPool) IsDraining() bool {
+	return atomic.LoadInt32(&gcwp.draining) == 1
+}
+
+// stopPool stops the underlying pool only once
+func (gcwp *GoCraftWorkPool) stopPool() {
+	if atomic.CompareAndSwapInt32(&gcwp.poolStopped, 0, 1) {
+		gcwp.pool.Stop()
+	}
+}
+
+// workerPoolID finds the ID of the underlying pool of this node from the heartbeats.
+func (gcwp *GoCraftWorkPool) workerPoolID() (string, error) {
+	host, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	pid := os.Getpid()
+
+	hbs, err := gcwp.client.WorkerPoolHeartbeats()
+	if err != nil {
+		return "", err
+	}
+
+	var found *work.WorkerPoolHeartbeat
+	for _, hb := range hbs {
+		if hb.Host != host || hb.Pid != pid {
+			continue
+		}
+
+		// The stale heartbeat of the previous process may have the same host and pid in container
+		if found == nil || hb.StartedAt > found.StartedAt {
+			found = hb
+		}
+	}
+
+	if found == nil {
+		return "", fmt.Errorf("no heartbeat found for host %s with pid %d", host, pid)
+	}
+
+	return found.WorkerPoolID, nil
+}
+
+func (gcwp *GoCraftWorkPool) markDraining(poolID string, timeout time.Duration) error {
+	conn := gcwp.redisPool.Get()
+	defer conn.Close()
+
+	ttl := int64(timeout.Seconds()) + drainingMarkExtraTTL
+	_, err := conn.Do("SET", utils.KeyDrainingPool(gcwp.namespace, poolID), time.Now().Unix(), "EX", ttl)
+
+	return err
+}
+
+func (gcwp *GoCraftWorkPool) isDrainingPool(poolID string) bool {
+	conn := gcwp.redisPool.Get()
+	defer conn.Close()
+
+	exists, err := redis.Bool(conn.Do("EXISTS", utils.KeyDrainingPool(gcwp.namespace, poolID)))
+	if err != nil {
+		logger.Errorf("Failed to check if the worker pool %s is draining: %s", poolID, err)
+		return false
+	}
+
+	return exists
+}
+
+// requeueInProgressJobs puts the in progress jobs of the worker pool back into the job queues.
+// The results of these jobs are abandoned if they're completed later.
+func (gcwp *GoCraftWorkPool) requeueInProgressJobs(poolID string) (int, error) {
+	conn := gcwp.redisPool.Get()
+	defer conn.Close()
+
+	script := redis.NewScript(4, redisLuaRequeueInProgressJobs)
+	count := 0
+	jobNames := make([]string, 0, len(gcwp.knownJobs)*len(gcwp.queues))
+	for name := range gcwp.knownJobs {
+		for _, queue := range gcwp.queues {
+			jobNames = append(jobNames, utils.QueuedJobName(name, queue))
+		}
+	}
+
+	for _, jobName := range jobNames {
+		values, err := redis.Values(script.Do(conn,
+			utils.RedisKeyJobsInProgress(gcwp.namespace, poolID, jobName),
+			utils.RedisKeyJobs(gcwp.namespace, jobName),
+			utils.RedisKeyJobsLock(gcwp.namespace, jobName),
+			utils.RedisKeyJobsLockInfo(gcwp.namespace, jobName),
+			poolID,
+		))
+		if err != nil {
+			return count, fmt.Errorf("re-queue in progress jobs of %s error: %s", jobName, err)
+		}
+
+		for _, v := range values {
+			rawJSON, ok := v.([]byte)
+			if !ok {
+				continue
+			}
+
+			j, err := utils.DeSerializeJob(rawJSON)
+			if err != nil {
+				logger.Errorf("Failed to decode the re-queued job: %s", err)
+				continue
+			}
+
+			gcwp.requeued.Store(j.ID, true)
+			if err := gcwp.statsManager.Update(j.ID, "status", job.JobStatusPending); err != nil {
+				// Only logged
+				logger.Errorf("Failed to reset the status of the re-queued job %s: %s", j.ID, err)
+			}
+
+			logger.Infof("Job '%s:%s' is re-queued", j.Name, j.ID)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
 // A try best method to delete the scheduled jobs of one periodic job
 func (gcwp *GoCraftWorkPool) deleteScheduledJobsOfPeriodicPolicy(policyID string) error {
 	// Check the scope of [-periodicEnqueuerHorizon, -1]
@@ -718,16 +1148,54 @@ func (gcwp *GoCraftWorkPool) ping() error {
 	return fmt.Errorf("connect to redis server timeout: %s", err.Error())
 }
 
+// pushBatch pushes the jobs into the queues in one transaction, same with the way the upstream enqueuer does.
+func (gcwp *GoCraftWorkPool) pushBatch(works []*work.Job) error {
+	conn := gcwp.redisPool.Get()
+	defer conn.Close()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+
+	knownJobs := []interface{}{utils.RedisKeyKnownJobs(gcwp.namespace)}
+	names := make(map[string]bool)
+	for _, j := range works {
+		rawJSON, err := utils.SerializeJob(j)
+		if err != nil {
+			return err
+		}
+
+		if err := conn.Send("LPUSH", utils.RedisKeyJobs(gcwp.namespace, j.Name), rawJSON); err != nil {
+			return err
+		}
+
+		if !names[j.Name] {
+			names[j.Name] = true
+			knownJobs = append(knownJobs, j.Name)
+		}
+	}
+
+	if err := conn.Send("SADD", knownJobs...); err != nil {
+		return err
+	}
+
+	_, err := conn.Do("EXEC")
+
+	return err
+}
+
 // generate the job stats data
 func generateResult(j *work.Job, jobKind string, isUnique bool) models.JobStats {
 	if j == nil {
 		return models.JobStats{}
 	}
 
+	jobName, queue := utils.ParseQueuedJobName(j.Name)
 	return models.JobStats{
 		Stats: &models.JobStatData{
 			JobID:       j.ID,
-			JobName:     j.Name,
+			JobName:     jobName,
+			Queue:       queue,
 			JobKind:     jobKind,
 			IsUnique:    isUnique,
 			Status:      job.JobStatusPending,
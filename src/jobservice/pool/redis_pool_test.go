@@ -86,7 +86,7 @@ func TestEnqueueJob(t *testing.T) {
 
 	params := make(map[string]interface{})
 	params["name"] = "testing:v1"
-	stats, err := wp.Enqueue("fake_job", params, false)
+	stats, err := wp.Enqueue("fake_job", params, false, "")
 	if err != nil {
 		t.Error(err)
 	}
@@ -95,7 +95,7 @@ func TestEnqueueJob(t *testing.T) {
 	}
 
 	runAt := time.Now().Unix() + 20
-	stats, err = wp.Schedule("fake_job", params, 20, false)
+	stats, err = wp.Schedule("fake_job", params, 20, false, "")
 	if err != nil {
 		t.Error(err)
 	}
@@ -104,7 +104,7 @@ func TestEnqueueJob(t *testing.T) {
 		t.Errorf("expect returned 'RunAt' should be >= '%d' but seems not", runAt)
 	}
 
-	stats, err = wp.Enqueue("fake_unique_job", params, true)
+	stats, err = wp.Enqueue("fake_unique_job", params, true, "")
 	if err != nil {
 		t.Error(err)
 	}
@@ -116,6 +116,48 @@ func TestEnqueueJob(t *testing.T) {
 	sysCtx.WG.Wait()
 }
 
+func TestEnqueueBatch(t *testing.T) {
+	wp, sysCtx, cancel := createRedisWorkerPool()
+	defer func() {
+		if err := tests.ClearAll(tests.GiveMeTestNamespace(), redisPool.Get()); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer cancel()
+
+	if err := wp.RegisterJob("fake_job", (*fakeJob)(nil)); err != nil {
+		t.Error(err)
+	}
+
+	go wp.Start()
+	time.Sleep(1 * time.Second)
+
+	params := make(map[string]interface{})
+	params["name"] = "testing:v1"
+	batch, err := wp.EnqueueBatch([]*models.JobData{
+		{Name: "fake_job", Parameters: params},
+		{Name: "fake_job", Parameters: params},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Stats.JobIDs) != 2 {
+		t.Fatalf("expect 2 jobs in batch but got %d", len(batch.Stats.JobIDs))
+	}
+
+	<-time.After(5 * time.Second)
+	res, err := wp.GetBatchStats(batch.Stats.BatchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stats.Status != job.JobStatusSuccess {
+		t.Errorf("expect batch status '%s' but got '%s'", job.JobStatusSuccess, res.Stats.Status)
+	}
+
+	cancel()
+	sysCtx.WG.Wait()
+}
+
 func TestEnqueuePeriodicJob(t *testing.T) {
 	wp, _, cancel := createRedisWorkerPool()
 	defer func() {
@@ -134,7 +176,7 @@ func TestEnqueuePeriodicJob(t *testing.T) {
 
 	params := make(map[string]interface{})
 	params["name"] = "testing:v1"
-	jobStats, err := wp.PeriodicallyEnqueue("fake_job", params, "10 * * * * *")
+	jobStats, err := wp.PeriodicallyEnqueue("fake_job", params, "10 * * * * *", "")
 	if err != nil {
 		t.Error(err)
 	}
@@ -195,7 +237,7 @@ func TestStopJob(t *testing.T) {
 	params := make(map[string]interface{})
 	params["name"] = "testing:v1"
 
-	genericJob, err := wp.Enqueue("fake_long_run_job", params, false)
+	genericJob, err := wp.Enqueue("fake_long_run_job", params, false, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -212,7 +254,7 @@ func TestStopJob(t *testing.T) {
 	}
 
 	// Stop scheduled job
-	scheduledJob, err := wp.Schedule("fake_long_run_job", params, 120, false)
+	scheduledJob, err := wp.Schedule("fake_long_run_job", params, 120, false, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -222,6 +264,70 @@ func TestStopJob(t *testing.T) {
 	}
 }
 
+func TestDrainPool(t *testing.T) {
+	wp, _, cancel := createRedisWorkerPool()
+	defer func() {
+		if err := tests.ClearAll(tests.GiveMeTestNamespace(), redisPool.Get()); err != nil {
+			t.Error(err)
+		}
+	}()
+	defer cancel()
+
+	if err := wp.RegisterJob("fake_long_run_job", (*fakeRunnableJob)(nil)); err != nil {
+		t.Error(err)
+	}
+
+	go wp.Start()
+	time.Sleep(1 * time.Second)
+
+	params := make(map[string]interface{})
+	params["name"] = "testing:v1"
+
+	genericJob, err := wp.Enqueue("fake_long_run_job", params, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	count, err := wp.Drain(1 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expect 1 job re-queued but got %d", count)
+	}
+	if !wp.IsDraining() {
+		t.Fatal("expect pool is draining but not")
+	}
+	if _, err := wp.Drain(1 * time.Second); err == nil {
+		t.Fatal("expect non nil error when draining twice but got nil")
+	}
+
+	stats, err := wp.GetJobStats(genericJob.Stats.JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stats.Status != job.JobStatusPending {
+		t.Fatalf("expect re-queued job pending but got %s", stats.Stats.Status)
+	}
+
+	queues, err := wp.client.Queues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queues) == 0 || queues[0].Count != 1 {
+		t.Fatalf("expect 1 job in the queue but got %+v", queues)
+	}
+
+	poolStats, err := wp.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poolStats.Pools[0].Status != workerPoolStatusDraining {
+		t.Fatalf("expect pool status %s but got %s", workerPoolStatusDraining, poolStats.Pools[0].Status)
+	}
+}
+
 func TestCancelJob(t *testing.T) {
 	wp, _, cancel := createRedisWorkerPool()
 	defer func() {
@@ -242,7 +348,7 @@ func TestCancelJob(t *testing.T) {
 	params := make(map[string]interface{})
 	params["name"] = "testing:v1"
 
-	genericJob, err := wp.Enqueue("fake_long_run_job", params, false)
+	genericJob, err := wp.Enqueue("fake_long_run_job", params, false, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -296,7 +402,7 @@ func TestCancelJob(t *testing.T) {
 
 	params := make(map[string]interface{})
 	params["name"] = "testing:v1"
-	res, err := wp.Enqueue("fake_runnable_job", params, false)
+	res, err := wp.Enqueue("fake_runnable_job", params, false, "")
 	if err != nil {
 		t.Fatal(err)
 	}
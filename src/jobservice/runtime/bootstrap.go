@@ -30,9 +30,13 @@ import (
 	"github.com/goharbor/harbor/src/jobservice/env"
 	jsjob "github.com/goharbor/harbor/src/jobservice/job"
 	"github.com/goharbor/harbor/src/jobservice/job/impl"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/auditlog"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/gc"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/plugin"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/replication"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/retention"
 	"github.com/goharbor/harbor/src/jobservice/job/impl/scan"
+	"github.com/goharbor/harbor/src/jobservice/job/impl/webhook"
 	"github.com/goharbor/harbor/src/jobservice/logger"
 	"github.com/goharbor/harbor/src/jobservice/models"
 	"github.com/goharbor/harbor/src/jobservice/pool"
@@ -45,6 +49,8 @@ const (
 	healthCheckPeriod     = time.Minute
 	dialReadTimeout       = healthCheckPeriod + 10*time.Second
 	dialWriteTimeout      = 10 * time.Second
+	// Used if the drain timeout is neither requested nor configured
+	defaultDrainTimeout = 10 * time.Minute
 )
 
 // JobService ...
@@ -85,14 +91,17 @@ func (bs *Bootstrap) LoadAndRun(ctx context.Context, cancel context.CancelFunc)
 		backendPool pool.Interface
 		wpErr       error
 	)
-	if config.DefaultConfig.PoolConfig.Backend == config.JobServicePoolBackendRedis {
+	switch config.DefaultConfig.PoolConfig.Backend {
+	case config.JobServicePoolBackendRedis:
 		backendPool, wpErr = bs.loadAndRunRedisWorkerPool(rootContext, config.DefaultConfig)
-		if wpErr != nil {
-			logger.Fatalf("Failed to load and run worker pool: %s\n", wpErr.Error())
-		}
-	} else {
+	case config.JobServicePoolBackendMemory:
+		backendPool, wpErr = bs.loadAndRunMemWorkerPool(rootContext, config.DefaultConfig)
+	default:
 		logger.Fatalf("Worker pool backend '%s' is not supported", config.DefaultConfig.PoolConfig.Backend)
 	}
+	if wpErr != nil {
+		logger.Fatalf("Failed to load and run worker pool: %s\n", wpErr.Error())
+	}
 
 	// Initialize controller
 	ctl := core.NewController(backendPool)
@@ -112,7 +121,13 @@ func (bs *Bootstrap) LoadAndRun(ctx context.Context, cancel context.CancelFunc)
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, os.Interrupt, syscall.SIGTERM, os.Kill)
 	select {
-	case <-sig:
+	case s := <-sig:
+		// Drain the node before exiting if it's enabled, e.g: rolling upgrade
+		if s == syscall.SIGTERM && config.DefaultConfig.DrainTimeout > 0 {
+			bs.drain(backendPool, 0, sig)
+		}
+	case timeout := <-ctl.DrainRequests():
+		bs.drain(backendPool, timeout, sig)
 	case err = <-rootContext.ErrorChan:
 	}
 
@@ -148,6 +163,38 @@ func (bs *Bootstrap) LoadAndRun(ctx context.Context, cancel context.CancelFunc)
 	logger.Infof("Server gracefully exit")
 }
 
+// Drain the worker pool of this node, block until the draining is completed
+// or interrupted by another signal.
+func (bs *Bootstrap) drain(backendPool pool.Interface, timeout time.Duration, sig <-chan os.Signal) {
+	if timeout == 0 {
+		timeout = defaultDrainTimeout
+		if config.DefaultConfig.DrainTimeout > 0 {
+			timeout = time.Duration(config.DefaultConfig.DrainTimeout) * time.Second
+		}
+	}
+
+	logger.Infof("Node is draining, waiting %s for the in-flight jobs", timeout)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		count, err := backendPool.Drain(timeout)
+		if err != nil {
+			logger.Errorf("Failed to drain the node: %s", err)
+			return
+		}
+
+		logger.Infof("Node is drained with %d unfinished jobs re-queued", count)
+	}()
+
+	select {
+	case <-done:
+	case s := <-sig:
+		logger.Warningf("Draining is interrupted by signal %s", s)
+	}
+}
+
 // Load and run the API server.
 func (bs *Bootstrap) loadAndRunAPIServer(ctx *env.Context, cfg *config.Configuration, ctl *core.Controller) *api.Server {
 	// Initialized API server
@@ -197,28 +244,56 @@ func (bs *Bootstrap) loadAndRunRedisWorkerPool(ctx *env.Context, cfg *config.Con
 	redisWorkerPool := pool.NewGoCraftWorkPool(ctx,
 		fmt.Sprintf("{%s}", cfg.PoolConfig.RedisPoolCfg.Namespace),
 		cfg.PoolConfig.WorkerCount,
-		redisPool)
+		redisPool,
+		cfg.PoolConfig.Queues...)
 	// Register jobs here
-	if err := redisWorkerPool.RegisterJob(impl.KnownJobDemo, (*impl.DemoJob)(nil)); err != nil {
+	if err := bs.registerJobs(redisWorkerPool); err != nil {
 		// exit
 		return nil, err
 	}
-	if err := redisWorkerPool.RegisterJobs(
-		map[string]interface{}{
-			job.ImageScanJob:    (*scan.ClairJob)(nil),
-			job.ImageScanAllJob: (*scan.All)(nil),
-			job.ImageTransfer:   (*replication.Transfer)(nil),
-			job.ImageDelete:     (*replication.Deleter)(nil),
-			job.ImageReplicate:  (*replication.Replicator)(nil),
-			job.ImageGC:         (*gc.GarbageCollector)(nil),
-		}); err != nil {
+
+	if err := redisWorkerPool.Start(); err != nil {
+		return nil, err
+	}
+
+	return redisWorkerPool, nil
+}
+
+// Load and run the in-memory worker pool
+func (bs *Bootstrap) loadAndRunMemWorkerPool(ctx *env.Context, cfg *config.Configuration) (pool.Interface, error) {
+	memWorkerPool := pool.NewMemPool(ctx, cfg.PoolConfig.WorkerCount, cfg.PoolConfig.Queues...)
+	// Register jobs here
+	if err := bs.registerJobs(memWorkerPool); err != nil {
 		// exit
 		return nil, err
 	}
 
-	if err := redisWorkerPool.Start(); err != nil {
+	if err := memWorkerPool.Start(); err != nil {
 		return nil, err
 	}
 
-	return redisWorkerPool, nil
+	logger.Warning("The in-memory worker pool is used, the jobs and their stats will be lost once the job service exits")
+
+	return memWorkerPool, nil
+}
+
+// Register the known jobs to the worker pool
+func (bs *Bootstrap) registerJobs(workerPool pool.Interface) error {
+	if err := workerPool.RegisterJob(impl.KnownJobDemo, (*impl.DemoJob)(nil)); err != nil {
+		return err
+	}
+
+	return workerPool.RegisterJobs(
+		map[string]interface{}{
+			job.ImageScanJob:    (*scan.Job)(nil),
+			job.ImageScanAllJob: (*scan.All)(nil),
+			job.ImageTransfer:   (*replication.Transfer)(nil),
+			job.ImageDelete:     (*replication.Deleter)(nil),
+			job.ImageReplicate:  (*replication.Replicator)(nil),
+			job.ImageGC:         (*gc.GarbageCollector)(nil),
+			job.ImageRetention:  (*retention.Job)(nil),
+			job.WebhookJob:      (*webhook.Job)(nil),
+			job.AuditLogPurge:   (*auditlog.Purge)(nil),
+			impl.KnownJobPlugin: (*plugin.Job)(nil),
+		})
 }
@@ -61,6 +61,31 @@ func RedisKeyDead(namespace string) string {
 	return RedisNamespacePrefix(namespace) + "dead"
 }
 
+// RedisKeyJobs returns key of the job queue.
+func RedisKeyJobs(namespace, jobName string) string {
+	return RedisNamespacePrefix(namespace) + "jobs:" + jobName
+}
+
+// RedisKeyKnownJobs returns key of the names of the known jobs.
+func RedisKeyKnownJobs(namespace string) string {
+	return RedisNamespacePrefix(namespace) + "known_jobs"
+}
+
+// RedisKeyJobsInProgress returns key of the in progress jobs of the worker pool.
+func RedisKeyJobsInProgress(namespace, poolID, jobName string) string {
+	return fmt.Sprintf("%s:%s:inprogress", RedisKeyJobs(namespace, jobName), poolID)
+}
+
+// RedisKeyJobsLock returns key of the concurrency lock of the job queue.
+func RedisKeyJobsLock(namespace, jobName string) string {
+	return RedisKeyJobs(namespace, jobName) + ":lock"
+}
+
+// RedisKeyJobsLockInfo returns key of the concurrency lock info of the job queue.
+func RedisKeyJobsLockInfo(namespace, jobName string) string {
+	return RedisKeyJobs(namespace, jobName) + ":lock_info"
+}
+
 // SerializeJob encodes work.Job to json data.
 func SerializeJob(job *work.Job) ([]byte, error) {
 	return json.Marshal(job)
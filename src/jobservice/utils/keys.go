@@ -87,3 +87,43 @@ func KeyJobCtlCommands(namespace string, jobID string) string {
 func KeyUpstreamJobAndExecutions(namespace, upstreamJobID string) string {
 	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "executions", upstreamJobID)
 }
+
+// KeyHookEvents returns the key of the list for keeping undelivered hook events of the job.
+func KeyHookEvents(namespace, jobID string) string {
+	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "hook_events", jobID)
+}
+
+// KeyHookEventJobs returns the key of the sorted set for scheduling the hook events delivery.
+func KeyHookEventJobs(namespace string) string {
+	return fmt.Sprintf("%s%s", KeyNamespacePrefix(namespace), "hook_event_jobs")
+}
+
+// KeyHookEventsCount returns the key of the counter of undelivered hook events.
+func KeyHookEventsCount(namespace string) string {
+	return fmt.Sprintf("%s%s", KeyNamespacePrefix(namespace), "hook_events_count")
+}
+
+// KeyJobRunsCounter returns the key of the counter of the job runs finished with the status.
+func KeyJobRunsCounter(namespace, status string) string {
+	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "metrics:job_runs", status)
+}
+
+// KeyJobDurations returns the key of the duration histogram of the job.
+func KeyJobDurations(namespace, jobName string) string {
+	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "metrics:job_durations", jobName)
+}
+
+// KeyDrainingPool returns the key for marking the worker pool is draining.
+func KeyDrainingPool(namespace, poolID string) string {
+	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "draining_pools", poolID)
+}
+
+// KeyBatchStats returns the key of the aggregated stats of the batch.
+func KeyBatchStats(namespace, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "batch_stats", batchID)
+}
+
+// KeyBatchJobs returns the key of the list for keeping the IDs of the jobs in the batch.
+func KeyBatchJobs(namespace, batchID string) string {
+	return fmt.Sprintf("%s%s:%s", KeyNamespacePrefix(namespace), "batch_jobs", batchID)
+}
@@ -0,0 +1,90 @@
+// Copyright Project Harbor Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultQueue is the queue of the jobs submitted without specifying the queue.
+const DefaultQueue = "default"
+
+// Separate the job name and the queue name in the name of the backend job queue
+const queueSeparator = "@"
+
+var queueNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// ValidateQueueName checks if the queue name is well-formed.
+func ValidateQueueName(queue string) error {
+	if !queueNamePattern.MatchString(queue) {
+		return fmt.Errorf("invalid queue name '%s', only letters, digits, '_' and '-' are allowed", queue)
+	}
+
+	return nil
+}
+
+// NormalizeQueueName returns the default queue for the empty queue name.
+func NormalizeQueueName(queue string) string {
+	if IsEmptyStr(queue) {
+		return DefaultQueue
+	}
+
+	return queue
+}
+
+// QueuedJobName returns the name of the backend job queue for the job in the specified queue.
+// The jobs in the default queue keep using the job name to be compatible with the existing queued jobs.
+func QueuedJobName(jobName, queue string) string {
+	if NormalizeQueueName(queue) == DefaultQueue {
+		return jobName
+	}
+
+	return jobName + queueSeparator + queue
+}
+
+// ParseQueuedJobName splits the name of the backend job queue into the job name and the queue name.
+func ParseQueuedJobName(name string) (string, string) {
+	if i := strings.LastIndex(name, queueSeparator); i > 0 {
+		return name[:i], name[i+1:]
+	}
+
+	return name, DefaultQueue
+}
+
+// SplitQueuedJobNames returns the distinct job names and queue names in the names of the backend job queues.
+func SplitQueuedJobNames(names []string) ([]string, []string) {
+	jobNames := make(map[string]bool)
+	queues := make(map[string]bool)
+	for _, name := range names {
+		jobName, queue := ParseQueuedJobName(name)
+		jobNames[jobName] = true
+		queues[queue] = true
+	}
+
+	return sortedKeys(jobNames), sortedKeys(queues)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
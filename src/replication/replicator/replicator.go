@@ -88,6 +88,7 @@ func (d *DefaultReplicator) Replicate(replication *Replication) error {
 			job := &job_models.JobData{
 				Metadata: &job_models.JobMetadata{
 					JobKind: common_job.JobKindGeneric,
+					Queue:   config.ReplicationJobQueue(),
 				},
 				StatusHook: fmt.Sprintf("%s/service/notifications/jobs/replication/%d",
 					config.InternalCoreURL(), id),